@@ -0,0 +1,149 @@
+// Package apierror defines the typed errors returned by the API and renders them in a single
+// JSON envelope:
+//
+//	{"success": false, "code": "NOT_FOUND", "message": "User not found", "error": "User not found"}
+//
+// "error" repeats the message for clients written against the older {"error": ...} responses.
+// Optional "details" carry structured context and "request_id" matches the X-Request-ID header.
+package apierror
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code is a stable, machine-readable error identifier
+type Code string
+
+const (
+	CodeValidation      Code = "VALIDATION_ERROR"
+	CodeUnauthorized    Code = "UNAUTHORIZED"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodePhoneUnverified Code = "PHONE_NOT_VERIFIED"
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeConflict        Code = "CONFLICT"
+	CodeUnprocessable   Code = "UNPROCESSABLE"
+	CodePayloadTooLarge Code = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedType Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRateLimited     Code = "RATE_LIMITED"
+	CodeAccountLocked   Code = "ACCOUNT_LOCKED"
+	CodeInternal        Code = "INTERNAL_ERROR"
+	CodeUnavailable     Code = "SERVICE_UNAVAILABLE"
+)
+
+// Error is an API error with its HTTP status. Cause is logged but never sent to the client.
+type Error struct {
+	Status  int
+	Code    Code
+	Message string
+	Details interface{}
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithDetails attaches structured context rendered under "details"
+func (e *Error) WithDetails(details interface{}) *Error {
+	e.Details = details
+	return e
+}
+
+// New creates an error with an explicit status and code
+func New(status int, code Code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Validation reports a malformed or invalid request (400)
+func Validation(message string) *Error {
+	return New(http.StatusBadRequest, CodeValidation, message)
+}
+
+// Unauthorized reports missing or invalid credentials (401)
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, CodeUnauthorized, message)
+}
+
+// Forbidden reports an authenticated caller without access (403)
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, CodeForbidden, message)
+}
+
+// NotFound reports a missing resource (404)
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, CodeNotFound, message)
+}
+
+// Conflict reports a request that clashes with the current state (409)
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, CodeConflict, message)
+}
+
+// Unprocessable reports a well-formed request that cannot be applied (422)
+func Unprocessable(message string) *Error {
+	return New(http.StatusUnprocessableEntity, CodeUnprocessable, message)
+}
+
+// Internal reports a server-side failure (500); cause is logged, not returned
+func Internal(message string, cause error) *Error {
+	err := New(http.StatusInternalServerError, CodeInternal, message)
+	err.Cause = cause
+	return err
+}
+
+// Unavailable reports a dependency that is down (503); cause is logged, not returned
+func Unavailable(message string, cause error) *Error {
+	err := New(http.StatusServiceUnavailable, CodeUnavailable, message)
+	err.Cause = cause
+	return err
+}
+
+// From returns err as an *Error, treating anything else as an internal error
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return Internal("Internal server error", err)
+}
+
+// Abort writes err as the response and stops the handler chain. The error is also attached to
+// the context so middleware can see why the request failed.
+func Abort(c *gin.Context, err error) {
+	apiErr := From(err)
+	_ = c.Error(apiErr)
+	Render(c, apiErr)
+	c.Abort()
+}
+
+// Render writes the error envelope; server errors are logged with their cause
+func Render(c *gin.Context, err *Error) {
+	if err.Status >= http.StatusInternalServerError {
+		log.Printf("❌ %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+
+	body := gin.H{
+		"success": false,
+		"code":    err.Code,
+		"message": err.Message,
+		"error":   err.Message,
+	}
+	if err.Details != nil {
+		body["details"] = err.Details
+	}
+	if requestID := c.GetString("request_id"); requestID != "" {
+		body["request_id"] = requestID
+	}
+	c.JSON(err.Status, body)
+}
@@ -0,0 +1,112 @@
+// Package cache is a read-through cache for rarely changing, frequently read data such as the
+// service catalog. Values are stored as JSON in Redis when REDIS_URL is set, or in process memory
+// otherwise. The in-memory store is per instance, so entries there are only bounded by their TTL
+// when another instance performs the write.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"repair-service-server/config"
+)
+
+// Store holds cached values until they expire or are invalidated
+type Store interface {
+	// Get returns the value for key and whether it was present
+	Get(key string) ([]byte, bool, error)
+	// Set stores value under key for ttl
+	Set(key string, value []byte, ttl time.Duration) error
+	// DeletePrefix removes every key starting with prefix
+	DeletePrefix(prefix string) error
+	// Ping checks that the store is reachable
+	Ping(ctx context.Context) error
+	// Name identifies the backend in logs and health checks
+	Name() string
+}
+
+var (
+	mu    sync.RWMutex
+	store Store = NewMemoryStore()
+)
+
+// Init selects the cache backend: Redis when REDIS_URL is set, in-memory otherwise. A Redis
+// connection failure falls back to memory so the API keeps serving.
+func Init() {
+	url := config.AppConfig.RedisURL
+	if url == "" {
+		log.Printf("📦 Cache: using in-memory store")
+		return
+	}
+
+	redis, err := NewRedisStore(url)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		err = redis.Ping(ctx)
+		cancel()
+	}
+	if err != nil {
+		log.Printf("⚠️ Cache: Redis unavailable (%v), falling back to in-memory store", err)
+		return
+	}
+
+	SetStore(redis)
+	log.Printf("📦 Cache: using Redis store")
+}
+
+// Default returns the active store
+func Default() Store {
+	mu.RLock()
+	defer mu.RUnlock()
+	return store
+}
+
+// SetStore replaces the active store
+func SetStore(s Store) {
+	mu.Lock()
+	store = s
+	mu.Unlock()
+}
+
+// GetOrLoad returns the cached value for key, calling load and caching its result on a miss.
+// Cache errors are logged and treated as misses so a broken cache never fails a request.
+func GetOrLoad[T any](key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	s := Default()
+
+	if data, ok, err := s.Get(key); err != nil {
+		log.Printf("⚠️ Cache get %s failed: %v", key, err)
+	} else if ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("⚠️ Cache encode %s failed: %v", key, err)
+		return value, nil
+	}
+	if err := s.Set(key, data, ttl); err != nil {
+		log.Printf("⚠️ Cache set %s failed: %v", key, err)
+	}
+	return value, nil
+}
+
+// Invalidate removes every key under the given prefixes
+func Invalidate(prefixes ...string) {
+	s := Default()
+	for _, prefix := range prefixes {
+		if err := s.DeletePrefix(prefix); err != nil {
+			log.Printf("⚠️ Cache invalidate %s failed: %v", prefix, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// redisPoolSize is the number of idle connections kept open to Redis
+const redisPoolSize = 8
+
+// redisTimeout bounds every Redis round trip so a slow cache degrades to a miss
+const redisTimeout = 500 * time.Millisecond
+
+// RedisStore is a Store backed by Redis through a redigo connection pool
+type RedisStore struct {
+	pool *redis.Pool
+}
+
+// NewRedisStore creates a store from a redis://[user:password@]host:port[/db] URL. Nothing is
+// dialed until the first command.
+func NewRedisStore(rawURL string) (*RedisStore, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("unsupported REDIS_URL scheme %q", u.Scheme)
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if _, err := strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL database %q", db)
+		}
+	}
+
+	return &RedisStore{pool: &redis.Pool{
+		MaxIdle:     redisPoolSize,
+		IdleTimeout: 5 * time.Minute,
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialURLContext(ctx, rawURL,
+				redis.DialConnectTimeout(redisTimeout),
+				redis.DialReadTimeout(redisTimeout),
+				redis.DialWriteTimeout(redisTimeout),
+			)
+		},
+	}}, nil
+}
+
+func (s *RedisStore) Get(key string) ([]byte, bool, error) {
+	value, err := redis.Bytes(s.Do("GET", key))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(key string, value []byte, ttl time.Duration) error {
+	_, err := s.Do("SET", key, value, "PX", ttl.Milliseconds())
+	return err
+}
+
+// DeletePrefix scans for matching keys rather than using KEYS so Redis is never blocked
+func (s *RedisStore) DeletePrefix(prefix string) error {
+	conn := s.pool.Get()
+	defer conn.Close()
+
+	cursor := 0
+	for {
+		reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", escapeGlob(prefix)+"*", "COUNT", 100))
+		if err != nil {
+			return err
+		}
+		var keys []string
+		if _, err := redis.Scan(reply, &cursor, &keys); err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if _, err := conn.Do("DEL", redis.Args{}.AddFlat(keys)...); err != nil {
+				return err
+			}
+		}
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+func (s *RedisStore) Ping(ctx context.Context) error {
+	conn, err := s.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoContext(conn, ctx, "PING")
+	return err
+}
+
+func (s *RedisStore) Name() string {
+	return "redis"
+}
+
+// Do runs one command on a pooled connection and returns the reply as redigo decodes it: bulk
+// strings are []byte, integers int64 and arrays []interface{}. The pool drops connections that
+// failed.
+func (s *RedisStore) Do(command string, args ...interface{}) (interface{}, error) {
+	conn := s.pool.Get()
+	defer conn.Close()
+	return conn.Do(command, args...)
+}
+
+// escapeGlob escapes the characters SCAN MATCH treats as patterns
+func escapeGlob(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(s)
+}
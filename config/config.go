@@ -1,15 +1,28 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
+// defaultJWTSecret is the placeholder secret; it is refused in release mode
+const defaultJWTSecret = "your-super-secret-jwt-key-change-this-in-production"
+
+// Config is read from the environment once at startup; changing it needs a restart. Settings
+// that can change while the server runs are Tunables, read through Current.
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Phone    PhoneConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Phone      PhoneConfig
+	Cloudinary CloudinaryConfig
+	RedisURL   string // Empty uses the in-memory cache and rate limiter
 }
 
 type ServerConfig struct {
@@ -17,62 +30,266 @@ type ServerConfig struct {
 	GinMode string
 }
 
+// DatabaseConfig is the Postgres connection. URL comes from DB_URL, or is built from the DB_HOST,
+// DB_PORT, DB_USER, DB_PASSWORD, DB_NAME and DB_SSL_MODE parts when only those are set.
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	URL string
 }
 
 type JWTConfig struct {
-	Secret       string
-	ExpiryHours  int
+	Secret      string
+	ExpiryHours int
 }
 
 type PhoneConfig struct {
 	DefaultCountryCode string
 }
 
+// CloudinaryConfig holds the media storage credentials; all three are set or none are
+type CloudinaryConfig struct {
+	CloudName       string
+	APIKey          string
+	APISecret       string
+	NotificationURL string // Public URL of the Cloudinary webhook, sent with signed video uploads
+}
+
+// Configured reports whether media storage is set up
+func (c CloudinaryConfig) Configured() bool {
+	return c.CloudName != "" && c.APIKey != "" && c.APISecret != ""
+}
+
+// Tunables are the settings that can be changed without a restart, by editing the .env file or
+// sending SIGHUP (see Watcher)
+type Tunables struct {
+	Dispatch      DispatchConfig
+	RateLimits    map[string]int // Requests per window by rate limit budget name; unlisted budgets keep their built-in limit
+	CatalogTTL    time.Duration
+	WorkerRankTTL time.Duration
+	// ImpersonationTTL is the lifetime of admin impersonation tokens, capped at an hour where used
+	ImpersonationTTL time.Duration
+}
+
+type DispatchConfig struct {
+	DefaultBroadcastRadiusKm float64 // Used for requests outside any zone with its own radius
+	MaxBroadcastRadiusKm     float64
+}
+
+// RateLimit returns the configured limit of a rate limit budget, or fallback when it is not
+// overridden
+func (t *Tunables) RateLimit(budget string, fallback int) int {
+	if limit, ok := t.RateLimits[budget]; ok {
+		return limit
+	}
+	return fallback
+}
+
 var AppConfig *Config
 
-func Load() {
-	AppConfig = &Config{
+// tunables is read on every request, so it is swapped atomically rather than locked
+var tunables atomic.Pointer[Tunables]
+
+// Current returns the tunables in effect
+func Current() *Tunables {
+	if t := tunables.Load(); t != nil {
+		return t
+	}
+	t := defaultTunables()
+	return &t
+}
+
+// Load reads and validates the configuration from the environment. The returned error lists
+// every invalid setting.
+func Load() error {
+	cfg, t, err := read()
+	if err != nil {
+		return err
+	}
+	AppConfig = cfg
+	tunables.Store(t)
+	return nil
+}
+
+// read builds the configuration from the environment and validates it
+func read() (*Config, *Tunables, error) {
+	r := &reader{}
+	cfg := &Config{
 		Server: ServerConfig{
-			Port:    getEnv("PORT", "8080"),
-			GinMode: getEnv("GIN_MODE", "debug"),
+			Port:    r.str("PORT", "8080"),
+			GinMode: r.str("GIN_MODE", "debug"),
 		},
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "password"),
-			Name:     getEnv("DB_NAME", "repair_service_db"),
-			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
+			URL: databaseURL(),
 		},
 		JWT: JWTConfig{
-			Secret:      getEnv("JWT_SECRET", "your-super-secret-jwt-key-change-this-in-production"),
-			ExpiryHours: getEnvAsInt("JWT_EXPIRY_HOURS", 24),
+			Secret:      r.str("JWT_SECRET", defaultJWTSecret),
+			ExpiryHours: r.integer("JWT_EXPIRY_HOURS", 24),
 		},
 		Phone: PhoneConfig{
-			DefaultCountryCode: getEnv("DEFAULT_COUNTRY_CODE", "+222"),
+			DefaultCountryCode: r.str("DEFAULT_COUNTRY_CODE", "+222"),
+		},
+		Cloudinary: CloudinaryConfig{
+			CloudName:       os.Getenv("CLOUDINARY_CLOUD_NAME"),
+			APIKey:          os.Getenv("CLOUDINARY_API_KEY"),
+			APISecret:       os.Getenv("CLOUDINARY_API_SECRET"),
+			NotificationURL: os.Getenv("CLOUDINARY_NOTIFICATION_URL"),
 		},
+		RedisURL: os.Getenv("REDIS_URL"),
+	}
+	t := &Tunables{
+		Dispatch: DispatchConfig{
+			DefaultBroadcastRadiusKm: r.float("BROADCAST_RADIUS_KM", 10),
+			MaxBroadcastRadiusKm:     r.float("MAX_BROADCAST_RADIUS_KM", 50),
+		},
+		RateLimits:       r.rateLimits("RATE_LIMITS"),
+		CatalogTTL:       time.Duration(r.integer("CATALOG_CACHE_TTL_SECONDS", 300)) * time.Second,
+		WorkerRankTTL:    time.Duration(r.integer("WORKER_RANK_CACHE_TTL_SECONDS", 300)) * time.Second,
+		ImpersonationTTL: time.Duration(r.integer("IMPERSONATION_TTL_MINUTES", 15)) * time.Minute,
+	}
+	cfg.validate(r)
+	t.validate(r)
+	if len(r.errs) > 0 {
+		return nil, nil, fmt.Errorf("invalid configuration:\n  %w", errors.Join(r.errs...))
 	}
+	return cfg, t, nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// validate checks the values that parsed but cannot work
+func (c *Config) validate(r *reader) {
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		r.fail("PORT must be a port number, got %q", c.Server.Port)
+	}
+	switch c.Server.GinMode {
+	case "debug", "release", "test":
+	default:
+		r.fail("GIN_MODE must be debug, release or test, got %q", c.Server.GinMode)
+	}
+	if c.Database.URL == "" {
+		r.fail("DB_URL is required (or DB_HOST with DB_USER, DB_PASSWORD and DB_NAME)")
+	}
+	if c.Server.GinMode == "release" && (c.JWT.Secret == defaultJWTSecret || len(c.JWT.Secret) < 32) {
+		r.fail("JWT_SECRET must be set to a secret of at least 32 characters in release mode")
+	}
+	if c.JWT.ExpiryHours <= 0 {
+		r.fail("JWT_EXPIRY_HOURS must be positive")
+	}
+	if !strings.HasPrefix(c.Phone.DefaultCountryCode, "+") {
+		r.fail("DEFAULT_COUNTRY_CODE must start with +, got %q", c.Phone.DefaultCountryCode)
+	}
+	cld := c.Cloudinary
+	if (cld.CloudName != "" || cld.APIKey != "" || cld.APISecret != "") && !cld.Configured() {
+		r.fail("CLOUDINARY_CLOUD_NAME, CLOUDINARY_API_KEY and CLOUDINARY_API_SECRET must be set together")
+	}
+	if c.RedisURL != "" && !strings.HasPrefix(c.RedisURL, "redis://") {
+		r.fail("REDIS_URL must be a redis:// URL")
 	}
-	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+// validate checks the tunables, on startup and on every reload
+func (t *Tunables) validate(r *reader) {
+	d := t.Dispatch
+	if d.DefaultBroadcastRadiusKm <= 0 || d.MaxBroadcastRadiusKm <= 0 {
+		r.fail("BROADCAST_RADIUS_KM and MAX_BROADCAST_RADIUS_KM must be positive")
+	} else if d.DefaultBroadcastRadiusKm > d.MaxBroadcastRadiusKm {
+		r.fail("BROADCAST_RADIUS_KM (%g) cannot exceed MAX_BROADCAST_RADIUS_KM (%g)", d.DefaultBroadcastRadiusKm, d.MaxBroadcastRadiusKm)
+	}
+	if t.CatalogTTL <= 0 || t.WorkerRankTTL <= 0 {
+		r.fail("CATALOG_CACHE_TTL_SECONDS and WORKER_RANK_CACHE_TTL_SECONDS must be positive")
+	}
+	if t.ImpersonationTTL <= 0 {
+		r.fail("IMPERSONATION_TTL_MINUTES must be positive")
+	}
+}
+
+// defaultTunables are the tunables with every variable unset
+func defaultTunables() Tunables {
+	return Tunables{
+		Dispatch:         DispatchConfig{DefaultBroadcastRadiusKm: 10, MaxBroadcastRadiusKm: 50},
+		CatalogTTL:       5 * time.Minute,
+		WorkerRankTTL:    5 * time.Minute,
+		ImpersonationTTL: 15 * time.Minute,
+	}
+}
+
+// databaseURL returns DB_URL, or builds one from the DB_* parts
+func databaseURL() string {
+	if dbURL := os.Getenv("DB_URL"); dbURL != "" {
+		return dbURL
+	}
+	host := os.Getenv("DB_HOST")
+	if host == "" {
+		return ""
+	}
+	u := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(getEnv("DB_USER", "postgres"), os.Getenv("DB_PASSWORD")),
+		Host:     host + ":" + getEnv("DB_PORT", "5432"),
+		Path:     "/" + getEnv("DB_NAME", "repair_service_db"),
+		RawQuery: "sslmode=" + getEnv("DB_SSL_MODE", "disable"),
+	}
+	return u.String()
+}
+
+// reader reads typed variables, collecting an error for each one that does not parse
+type reader struct {
+	errs []error
+}
+
+func (r *reader) fail(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Errorf(format, args...))
+}
+
+func (r *reader) str(key, defaultValue string) string {
+	return getEnv(key, defaultValue)
+}
+
+func (r *reader) integer(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		r.fail("%s must be a whole number, got %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+func (r *reader) float(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		r.fail("%s must be a number, got %q", key, value)
+		return defaultValue
+	}
+	return parsed
+}
+
+// rateLimits parses budget=limit pairs such as "auth=20,otp=3"
+func (r *reader) rateLimits(key string) map[string]int {
+	limits := map[string]int{}
+	value := os.Getenv(key)
+	if value == "" {
+		return limits
+	}
+	for _, pair := range strings.Split(value, ",") {
+		name, limit, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		parsed, err := strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || name == "" || err != nil || parsed <= 0 {
+			r.fail("%s entries must look like budget=limit with a positive limit, got %q", key, pair)
+			continue
 		}
+		limits[strings.TrimSpace(name)] = parsed
+	}
+	return limits
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
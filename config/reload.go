@@ -0,0 +1,103 @@
+package config
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Reload reads envFile again and swaps in its tunables. Invalid values leave the tunables in
+// effect unchanged. Settings that need a restart are only reported when they changed.
+func Reload(envFile string) error {
+	if _, err := os.Stat(envFile); err == nil {
+		// Overload, unlike Load, replaces variables already set from the previous read
+		if err := godotenv.Overload(envFile); err != nil {
+			return err
+		}
+	}
+	cfg, t, err := read()
+	if err != nil {
+		return err
+	}
+	if AppConfig != nil && !reflect.DeepEqual(*cfg, *AppConfig) {
+		log.Printf("⚠️ Config: server, database, JWT, Cloudinary or Redis settings changed; restart to apply them")
+	}
+	tunables.Store(t)
+	return nil
+}
+
+// Watcher reloads the tunables on SIGHUP and whenever the env file's modification time changes
+type Watcher struct {
+	envFile  string
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewWatcher creates a watcher for envFile, checked every interval
+func NewWatcher(envFile string, interval time.Duration) *Watcher {
+	return &Watcher{
+		envFile:  envFile,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start starts watching
+func (w *Watcher) Start() {
+	log.Printf("🔧 Config: reloading tunables on SIGHUP and changes to %s", w.envFile)
+	go w.run()
+}
+
+// Stop stops watching
+func (w *Watcher) Stop() {
+	close(w.stopChan)
+}
+
+func (w *Watcher) run() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	modified := w.modTime()
+	for {
+		select {
+		case <-hangup:
+			modified = w.modTime()
+			w.reload("SIGHUP")
+		case <-ticker.C:
+			if current := w.modTime(); !current.Equal(modified) {
+				modified = current
+				w.reload(w.envFile + " changed")
+			}
+		case <-w.stopChan:
+			return
+		}
+	}
+}
+
+func (w *Watcher) reload(reason string) {
+	if err := Reload(w.envFile); err != nil {
+		log.Printf("❌ Config reload (%s) rejected, keeping current tunables: %v", reason, err)
+		return
+	}
+	t := Current()
+	log.Printf("🔧 Config reloaded (%s): broadcast radius %gkm (max %gkm), %d rate limit overrides",
+		reason, t.Dispatch.DefaultBroadcastRadiusKm, t.Dispatch.MaxBroadcastRadiusKm, len(t.RateLimits))
+}
+
+// modTime returns when the env file last changed, or the zero time when it does not exist
+func (w *Watcher) modTime() time.Time {
+	info, err := os.Stat(w.envFile)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,143 @@
+// Package duration represents job durations as whole minutes. Customers used to type the
+// estimated duration of a request as free text; columns now hold integer minutes, and during the
+// transition Minutes still reads the legacy text, such as "90m", "2 hours" or "3 jours", from JSON.
+package duration
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Minutes is a duration in whole minutes. Zero means no duration was given; it is stored as NULL
+// and written to JSON as null.
+type Minutes int
+
+// Of converts d to minutes, rounding to the nearest minute
+func Of(d time.Duration) Minutes {
+	return Minutes(math.Round(d.Minutes()))
+}
+
+// legacyPattern is a number of minutes, or of the unit that follows it. migrations/sql/0058
+// converts stored text with the same patterns, units and rounding; keep them in step.
+var legacyPattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?) *(m|min|mins|minute|minutes|h|hr|hrs|hour|hours|heure|heures|d|day|days|j|jour|jours)?$`)
+
+// legacyHoursMinutesPattern is hours and minutes written together, such as "1h30m"
+var legacyHoursMinutesPattern = regexp.MustCompile(`^([0-9]+)h([0-9]+)m$`)
+
+// legacyUnits is the number of minutes in each unit legacyPattern accepts
+var legacyUnits = map[string]int64{
+	"": 1, "m": 1, "min": 1, "mins": 1, "minute": 1, "minutes": 1,
+	"h": 60, "hr": 60, "hrs": 60, "hour": 60, "hours": 60, "heure": 60, "heures": 60,
+	"d": 1440, "day": 1440, "days": 1440, "j": 1440, "jour": 1440, "jours": 1440,
+}
+
+// Parse reads a legacy duration text: a number with an optional unit ("90", "45 min",
+// "1.5 hours", "3 jours"), plain numbers being minutes as analytics always read them, or hours
+// and minutes ("1h30m"). Fractions are rounded to the nearest minute, halves up. ok is false for
+// free text that does not name a duration and for durations that round to zero or do not fit in
+// an integer column.
+func Parse(s string) (Minutes, bool) {
+	s = strings.ToLower(strings.Trim(s, " \t\r\n"))
+
+	minutes := new(big.Rat)
+	if match := legacyPattern.FindStringSubmatch(s); match != nil {
+		if _, ok := minutes.SetString(match[1]); !ok {
+			return 0, false
+		}
+		minutes.Mul(minutes, new(big.Rat).SetInt64(legacyUnits[match[2]]))
+	} else if match := legacyHoursMinutesPattern.FindStringSubmatch(s); match != nil {
+		hours, _ := new(big.Rat).SetString(match[1])
+		minutes.SetString(match[2])
+		minutes.Add(minutes, hours.Mul(hours, big.NewRat(60, 1)))
+	} else {
+		return 0, false
+	}
+
+	// Round half up: floor(minutes + 1/2)
+	minutes.Add(minutes, big.NewRat(1, 2))
+	rounded := new(big.Int).Quo(minutes.Num(), minutes.Denom())
+	if rounded.Sign() <= 0 || rounded.Cmp(big.NewInt(math.MaxInt32)) > 0 {
+		return 0, false
+	}
+	return Minutes(rounded.Int64()), true
+}
+
+// Duration returns m as a time.Duration
+func (m Minutes) Duration() time.Duration {
+	return time.Duration(m) * time.Minute
+}
+
+// Hours returns m in hours, for analytics
+func (m Minutes) Hours() float64 {
+	return float64(m) / 60
+}
+
+// MarshalJSON writes the number of minutes, or null when none was given
+func (m Minutes) MarshalJSON() ([]byte, error) {
+	if m == 0 {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Itoa(int(m))), nil
+}
+
+// UnmarshalJSON reads a number of minutes or, from clients not updated yet, a legacy duration
+// text. Text that does not name a duration is dropped rather than rejected, as it used to be
+// accepted.
+func (m *Minutes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*m = 0
+		return nil
+	}
+	if strings.HasPrefix(string(data), `"`) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*m, _ = Parse(text)
+		return nil
+	}
+	n, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("duration: %s is not a number of minutes", data)
+	}
+	n = math.Round(n)
+	if n > math.MaxInt32 || n < math.MinInt32 {
+		return fmt.Errorf("duration: %s minutes is out of range", data)
+	}
+	*m = Minutes(n)
+	return nil
+}
+
+// Scan reads an integer column, NULL being zero
+func (m *Minutes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Minutes(v)
+	case []byte:
+		n, err := strconv.Atoi(string(v))
+		if err != nil {
+			return err
+		}
+		*m = Minutes(n)
+	default:
+		return fmt.Errorf("duration: cannot scan %T", src)
+	}
+	return nil
+}
+
+// Value writes the minutes, or NULL for zero
+func (m Minutes) Value() (driver.Value, error) {
+	if m == 0 {
+		return nil, nil
+	}
+	return int64(m), nil
+}
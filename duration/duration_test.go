@@ -0,0 +1,87 @@
+package duration
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want Minutes
+		ok   bool
+	}{
+		{"90", 90, true},
+		{" 90\t\n", 90, true},
+		{"45 min", 45, true},
+		{"45min", 45, true},
+		{"45 Minutes", 45, true},
+		{"2 hours", 120, true},
+		{"2 HEURES", 120, true},
+		{"1.5 h", 90, true},
+		{"3 jours", 4320, true},
+		{"1 d", 1440, true},
+		{"1h30m", 90, true},
+		{"0h5m", 5, true},
+
+		// Fractions round to the nearest minute, halves up
+		{"1.5", 2, true},
+		{"2.5", 3, true},
+		{"2.49", 2, true},
+		{"0.5", 1, true},
+		{"0.01 h", 1, true},
+		{"0.001 h", 0, false},
+
+		// The largest durations that fit in an integer column, and the smallest that do not
+		{"2147483647", 2147483647, true},
+		{"2147483647.4", 2147483647, true},
+		{"2147483647.5", 0, false},
+		{"1491308 jours", 2147483520, true},
+		{"1491309 jours", 0, false},
+		{"99999999999999999999999 min", 0, false},
+
+		{"0", 0, false},
+		{"", 0, false},
+		{"environ 2 heures", 0, false},
+		{"2 semaines", 0, false},
+		{"-5", 0, false},
+		{"+5", 0, false},
+		{".5", 0, false},
+		{"1e3", 0, false},
+		{"1h 30m", 0, false},
+		{"90s", 0, false},
+		{"1.5h30m", 0, false},
+		{"２ h", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %d, %v, want %d, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    Minutes
+		wantErr bool
+	}{
+		{`90`, 90, false},
+		{`89.5`, 90, false},
+		{`null`, 0, false},
+		{`"1h30m"`, 90, false},
+		{`"whenever"`, 0, false},
+		{`2147483647`, 2147483647, false},
+		{`2147483648`, 0, true},
+		{`1e20`, 0, true},
+		{`true`, 0, true},
+	}
+	for _, tt := range tests {
+		var m Minutes
+		err := json.Unmarshal([]byte(tt.json), &m)
+		if (err != nil) != tt.wantErr || m != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d, error %v", tt.json, m, err, tt.want, tt.wantErr)
+		}
+	}
+}
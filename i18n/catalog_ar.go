@@ -0,0 +1,196 @@
+package i18n
+
+// ar is the Arabic catalog
+var ar = map[string]string{
+	// Validation errors
+	"validation.invalid_request":  "بيانات الطلب غير صالحة",
+	"validation.empty_body":       "نص الطلب فارغ",
+	"validation.malformed_body":   "نص الطلب ليس JSON صالحًا",
+	"validation.type":             "يجب أن يكون {field} من النوع {param}",
+	"validation.required":         "{field} مطلوب",
+	"validation.min":              "يجب ألا يقل {field} عن {param}",
+	"validation.max":              "يجب ألا يزيد {field} عن {param}",
+	"validation.len":              "يجب أن يكون طول {field} {param}",
+	"validation.gt":               "يجب أن يكون {field} أكبر من {param}",
+	"validation.gte":              "يجب أن يكون {field} أكبر من أو يساوي {param}",
+	"validation.lt":               "يجب أن يكون {field} أقل من {param}",
+	"validation.lte":              "يجب أن يكون {field} أقل من أو يساوي {param}",
+	"validation.oneof":            "يجب أن يكون {field} إحدى القيم: {param}",
+	"validation.latitude":         "يجب أن يكون {field} خط عرض صالحًا",
+	"validation.longitude":        "يجب أن يكون {field} خط طول صالحًا",
+	"validation.phone":            "يجب أن يكون {field} رقمًا موريتانيًا (+222XXXXXXXX)",
+	"validation.priority":         "يجب أن يكون {field} إحدى القيم: low, medium, high, urgent",
+	"validation.duration":         "يجب أن تكون {field} بين 15 دقيقة و7 أيام",
+	"validation.future":           "يجب أن يكون {field} تاريخًا مستقبليًا بصيغة ISO 8601",
+	"validation.otp_invalid":      "{field} غير صحيح أو منتهي الصلاحية",
+	"validation.time_of_day":      "يجب أن يكون {field} وقتًا بصيغة HH:MM",
+	"validation.timezone":         "يجب أن يكون {field} منطقة زمنية مثل Africa/Nouakchott",
+	"validation.urgent_disabled":  "الخدمة العاجلة غير متوفرة في هذه الفئة",
+	"validation.unknown_category": "يجب أن يحتوي {field} على فئات نشطة فقط",
+	"validation.default":          "{field} غير صالح",
+
+	// Service request status notifications
+	"notification.status.accepted.title":    "تم قبول الطلب",
+	"notification.status.accepted.body":     "تم قبول طلب خدمتك والمهني في الطريق!",
+	"notification.status.en_route.title":    "المهني في الطريق",
+	"notification.status.en_route.body":     "المهني في طريقه إليك. تابع وصوله في التطبيق.",
+	"notification.status.arrived.title":     "وصل المهني",
+	"notification.status.arrived.body":      "وصل المهني إلى موقعك.",
+	"notification.status.in_progress.title": "بدأ العمل",
+	"notification.status.in_progress.body":  "بدأ المهني العمل على طلبك.",
+	"notification.status.completed.title":   "اكتملت الخدمة",
+	"notification.status.completed.body":    "تم إكمال طلب خدمتك. يرجى تقييم تجربتك.",
+	"notification.status.cancelled.title":   "تم إلغاء الخدمة",
+	"notification.status.cancelled.body":    "تم إلغاء طلب خدمتك.",
+	"notification.status.default.title":     "تحديث الخدمة",
+	"notification.status.default.body":      "تم تحديث حالة طلب خدمتك.",
+
+	// Other notifications
+	"notification.job_offer.title":          "عرض عمل جديد",
+	"notification.job_offer.body":           "{title}",
+	"notification.new_job.title":            "عمل جديد بالقرب منك",
+	"notification.new_job.body":             "{title} · على بعد {distance} كم",
+	"notification.new_jobs.title":           "{count} أعمال جديدة بالقرب منك",
+	"notification.new_jobs.body":            "افتح التطبيق لرؤيتها قبل أن يأخذها غيرك.",
+	"notification.urgent_job.title":         "🚨 عمل عاجل بالقرب منك",
+	"notification.urgent_job.body":          "{title} · على بعد {distance} كم",
+	"notification.review_reply.title":       "رد المهني على تقييمك",
+	"notification.review_reply.body":        "{reply}",
+	"notification.chat.message.title":       "{name}",
+	"notification.chat.message.body":        "{preview}",
+	"notification.chat.summary.title":       "{name}",
+	"notification.chat.summary.body":        "{count} رسائل جديدة. آخرها: {preview}",
+	"notification.line_item_added.title":    "قطعة بانتظار موافقتك",
+	"notification.line_item_added.body":     "تمت إضافة {description} ({quantity} × {unit_price|money} = {total|money}). يرجى قبولها أو رفضها.",
+	"notification.line_item_approved.title": "تمت الموافقة على القطعة",
+	"notification.line_item_approved.body":  "تمت الموافقة على {description} ({total|money}).",
+	"notification.line_item_rejected.title": "تم رفض القطعة",
+	"notification.line_item_rejected.body":  "تم رفض {description} ({total|money}). {reason}",
+	"notification.tip_received.title":       "تلقيت إكرامية",
+	"notification.tip_received.body":        "منحك أحد العملاء إكرامية بقيمة {amount|money} مقابل \"{title}\".",
+	"notification.feedback_customer.title":  "رأيك يهمنا",
+	"notification.feedback_customer.body":   "اكتملت خدمتك الأولى! شاركنا رأيك لمساعدتنا على التحسن.",
+	"notification.feedback_worker.title":    "ساعدنا في تحسين تجربتك",
+	"notification.feedback_worker.body":     "اكتملت مهمتك الأولى! شاركنا رأيك لمساعدتنا على تحسين تجربتك.",
+	"notification.login_lockout.title":      "تم إيقاف تسجيل الدخول مؤقتًا",
+	"notification.login_lockout.body":       "أوقفنا تسجيل الدخول إلى حسابك لمدة {minutes} دقيقة بعد عدة محاولات بكلمة مرور خاطئة. إذا لم تكن أنت، فغيّر كلمة المرور.",
+	"notification.price_updated.title":      "تم تحديث السعر",
+	"notification.price_updated.body":       "أصبح السعر النهائي لـ \"{title}\" {price|money}",
+	"notification.refund_issued.title":      "تم الاسترداد",
+	"notification.refund_issued.body":       "تم استرداد {amount|money} مقابل \"{title}\"",
+
+	// Organization notifications; {organization} is its name
+	"notification.organization_invite.title": "دعوة إلى مؤسسة",
+	"notification.organization_invite.body":  "تمت دعوتك للانضمام إلى {organization}. اقبل الدعوة لحجز إصلاحات تُفوتر على المؤسسة.",
+
+	// Safety notifications and the SMS to an emergency contact; {location} is a map link or empty
+	"notification.sos_raised.title":    "تم إيقاف المهمة للمراجعة الأمنية",
+	"notification.sos_raised.body":     "تم إطلاق نداء استغاثة على \"{title}\". المهمة معلقة حتى يراجعها فريقنا.",
+	"notification.sos_resumed.title":   "استؤنفت المهمة",
+	"notification.sos_resumed.body":    "راجع فريقنا نداء الاستغاثة على \"{title}\" ويمكن متابعة المهمة.",
+	"notification.sos_cancelled.title": "أُلغيت المهمة بعد المراجعة الأمنية",
+	"notification.sos_cancelled.body":  "راجع فريقنا نداء الاستغاثة على \"{title}\" وألغى المهمة.",
+	"sms.sos_contact":                  "نداء استغاثة من {name}: طلب المساعدة أثناء إصلاح منزلي في {address}. {location}",
+
+	// No-show notifications
+	"notification.worker_no_show.title":  "نبحث لك عن فني آخر",
+	"notification.worker_no_show.body":   "لم يحضر الفني لطلب \"{title}\". نعرضه الآن على فنيين آخرين.",
+	"notification.no_show_penalty.title": "تمت إعادة إسناد المهمة",
+	"notification.no_show_penalty.body":  "لم تنطلق أو تبدأ \"{title}\" في الوقت المحدد، فأُسندت إلى فني آخر. هذا يخفض درجة موثوقيتك.",
+
+	// Reliability notifications; {score} is out of 100
+	"notification.worker_cancelled.title":       "نبحث لك عن فني آخر",
+	"notification.worker_cancelled.body":        "اضطر الفني إلى التخلي عن \"{title}\". نعرضه الآن على فنيين آخرين.",
+	"notification.reliability_warning.title":    "درجة موثوقيتك تنخفض",
+	"notification.reliability_warning.body":     "درجة موثوقيتك الآن {score}. المهام الفائتة والملغاة والمتأخرة تخفضها، والدرجة المنخفضة تحد من الطلبات التي تراها.",
+	"notification.reliability_reduced.title":    "سترى الطلبات الجديدة متأخرًا",
+	"notification.reliability_reduced.body":     "درجة موثوقيتك الآن {score}، لذا تصلك الطلبات الجديدة بعد الفنيين الآخرين. ستتحسن مع انتهاء صلاحية الحوادث القديمة.",
+	"notification.reliability_suspended.title":  "تم تعليق حسابك",
+	"notification.reliability_suspended.body":   "لا يمكنك قبول الطلبات حتى {until}. تواصل مع الدعم إذا كنت تعتقد أن هذا خطأ.",
+	"notification.reliability_reinstated.title": "تم رفع التعليق عن حسابك",
+	"notification.reliability_reinstated.body":  "يمكنك أن تصبح متاحًا وتقبل الطلبات مجددًا.",
+
+	// Worker onboarding notifications
+	"notification.onboarding.documents_pending.title": "يرجى إعادة رفع وثائقك",
+	"notification.onboarding.documents_pending.body":  "تعذر اعتماد بطاقة هويتك. {note}",
+	"notification.onboarding.under_review.title":      "ملفك قيد المراجعة",
+	"notification.onboarding.under_review.body":       "يراجع فريقنا ملفك. {note}",
+	"notification.onboarding.training_required.title": "تم اعتماد الوثائق",
+	"notification.onboarding.training_required.body":  "تم اعتماد وثائقك. أكمل التدريب لتبدأ في تلقي المهام. {note}",
+	"notification.onboarding.active.title":            "مرحبًا بك",
+	"notification.onboarding.active.body":             "اكتمل تسجيلك. يمكنك الآن تلقي المهام.",
+
+	// AI assistant replies
+	"ai.unavailable":         "المساعد الذكي غير متاح حاليًا. يرجى التواصل مع الدعم.",
+	"ai.busy":                "مساعدنا مشغول جدًا الآن. حاول مرة أخرى بعد بضع دقائق، أو أنشئ طلبًا مباشرة من صفحة الخدمات.",
+	"ai.busy_worker":         "مساعدنا مشغول جدًا الآن. {worker} مهني متاح في مجال {category} بالقرب منك.",
+	"ai.rate_limited":        "رسائل كثيرة جدًا. يرجى المحاولة لاحقًا.",
+	"ai.failed":              "تعذرت معالجة طلبك. يرجى المحاولة مرة أخرى.",
+	"ai.voice_unreadable":    "لم أفهم هذه الرسالة الصوتية. حاول مرة أخرى أو اكتب رسالتك.",
+	"ai.photo_unreadable":    "تعذرت قراءة هذه الصورة. أرسل صورة JPEG أو PNG لا يتجاوز حجمها 8 ميغابايت.",
+	"ai.estimated_cost":      "التكلفة التقديرية: {price_min}–{price_max} {currency}.",
+	"ai.accept_category":     "اقبل لإرسال هذا الطلب إلى مهنيي {category} لدينا.",
+	"ai.accept_draft":        "{title} ({category}). اقبل لإرسال هذا الطلب إلى مهنيينا.",
+	"ai.sign_in_to_book":     "يرجى تسجيل الدخول للحجز عبر المساعد.",
+	"ai.worker_unavailable":  "عذرًا، هذا المهني لم يعد متاحًا. سأجد لك مهنيًا آخر.",
+	"ai.worker_busy":         "عذرًا، هذا المهني مشغول حاليًا. سأجد لك مهنيًا آخر متاحًا.",
+	"ai.declined":            "حسنًا، سأجد لك خيارات أخرى.",
+	"ai.booking_failed":      "تعذر إنشاء طلب الخدمة.",
+	"ai.no_default_address":  "أضف عنوانًا افتراضيًا للحجز عبر المساعد.",
+	"ai.outside_area":        "هذه الخدمة غير متاحة في عنوانك.",
+	"ai.verify_phone":        "يرجى تأكيد رقم هاتفك قبل الحجز.",
+	"ai.too_many_requests":   "لقد أنشأت طلبات كثيرة مؤخرًا. يرجى المحاولة بعد بضع دقائق.",
+	"ai.already_booked":      "تم إرسال هذا الطلب بالفعل.",
+	"ai.booked":              "تم! أُرسل طلبك إلى المهنيين. ننتظر تأكيدهم.",
+	"ai.booked_scheduled":    "تم! طلبك مجدول في {scheduled_for}.",
+	"ai.worker_accepted":     "قبل المهني طلبك وهو في الطريق.",
+	"ai.request_ended":       "رفض المهني أو انتهت صلاحية الطلب. أبحث لك عن خيارات أخرى.",
+	"ai.request_title":       "طلب عبر المساعد",
+	"ai.request_description": "خدمة مطلوبة عبر المساعد الذكي",
+
+	// Emails
+	"email.footer":                       "تلقيت هذه الرسالة لأنها مرتبطة بحسابك في Repair Service.",
+	"email.greeting":                     "مرحبًا {name}،",
+	"email.code_expires":                 "تنتهي صلاحيته بعد {expires_in_minutes} دقيقة.",
+	"email.verification.subject":         "أكّد عنوان بريدك الإلكتروني",
+	"email.verification.code":            "رمز التأكيد الخاص بك هو {code}. تنتهي صلاحيته بعد {expires_in_minutes} دقيقة.",
+	"email.verification.code_label":      "رمز التأكيد الخاص بك هو:",
+	"email.verification.ignore":          "إذا لم تضف هذا العنوان إلى حسابك في Repair Service، فتجاهل هذه الرسالة.",
+	"email.password_reset.subject":       "إعادة تعيين كلمة المرور",
+	"email.password_reset.code":          "رمز إعادة تعيين كلمة المرور هو {code}. تنتهي صلاحيته بعد {expires_in_minutes} دقيقة.",
+	"email.password_reset.code_label":    "رمز إعادة تعيين كلمة المرور هو:",
+	"email.password_reset.ignore":        "إذا لم تطلب إعادة تعيين كلمة المرور، فتجاهل هذه الرسالة؛ ستبقى كلمة المرور كما هي.",
+	"email.request_confirmation.subject": "استلمنا طلبك رقم {request_id}",
+	"email.request_confirmation.heading": "استلمنا طلبك",
+	"email.request_confirmation.body":    "استلمنا طلب الخدمة \"{title}\" (رقم {request_id}) ونبحث عن مهني بالقرب من {address}.",
+	"email.request_confirmation.budget":  "الميزانية: {budget|money}",
+	"email.request_confirmation.next":    "سنخبرك فور قبول أحد المهنيين له.",
+	"email.receipt.subject":              "إيصال الطلب رقم {request_id}",
+	"email.receipt.body":                 "أكمل {worker_name} \"{title}\" (رقم {request_id}) بتاريخ {completed_at|date}.",
+	"email.receipt.service":              "الخدمة",
+	"email.receipt.worker":               "المهني",
+	"email.receipt.completed":            "تاريخ الإكمال",
+	"email.receipt.labour":               "اليد العاملة",
+	"email.receipt.amount":               "المبلغ",
+	"email.receipt.thanks":               "شكرًا لاستخدامك Repair Service. يمكنك تقييم الخدمة في التطبيق.",
+	"email.earnings.subject":             "أرباحك من {week_start|date} إلى {week_end|date}",
+	"email.earnings.heading":             "أرباحك الأسبوعية",
+	"email.earnings.intro":               "إليك أسبوعك من {week_start|date} إلى {week_end|date}:",
+	"email.earnings.jobs":                "المهام المكتملة",
+	"email.earnings.hours":               "ساعات العمل",
+	"email.earnings.earnings":            "الأرباح",
+	"email.earnings.tips":                "الإكراميات",
+
+	// Chat quick replies
+	"chat.quick_reply.worker.on_my_way":         "أنا في الطريق.",
+	"chat.quick_reply.worker.arriving_soon":     "سأصل خلال دقائق.",
+	"chat.quick_reply.worker.running_late":      "عذرًا، سأتأخر قليلًا.",
+	"chat.quick_reply.worker.share_location":    "يرجى مشاركة موقعك بالضبط.",
+	"chat.quick_reply.worker.outside":           "أنا أمام الباب.",
+	"chat.quick_reply.worker.job_done":          "انتهى العمل. يرجى التحقق والتأكيد.",
+	"chat.quick_reply.customer.when_arriving":   "متى ستصل؟",
+	"chat.quick_reply.customer.location_shared": "شاركت موقعي بالضبط.",
+	"chat.quick_reply.customer.call_on_arrival": "يرجى الاتصال بي عند وصولك.",
+	"chat.quick_reply.customer.at_home":         "أنا في المنزل، يمكنك الدخول.",
+	"chat.quick_reply.customer.thanks":          "شكرًا!",
+}
@@ -0,0 +1,196 @@
+package i18n
+
+// en is the English catalog. It holds every key, so other catalogs fall back to it.
+var en = map[string]string{
+	// Validation errors; {field} is the JSON field name and {param} the rule's parameter
+	"validation.invalid_request":  "Invalid request data",
+	"validation.empty_body":       "Request body is empty",
+	"validation.malformed_body":   "Request body is not valid JSON",
+	"validation.type":             "{field} must be of type {param}",
+	"validation.required":         "{field} is required",
+	"validation.min":              "{field} must be at least {param}",
+	"validation.max":              "{field} must be at most {param}",
+	"validation.len":              "{field} must have length {param}",
+	"validation.gt":               "{field} must be greater than {param}",
+	"validation.gte":              "{field} must be greater than or equal to {param}",
+	"validation.lt":               "{field} must be less than {param}",
+	"validation.lte":              "{field} must be less than or equal to {param}",
+	"validation.oneof":            "{field} must be one of: {param}",
+	"validation.latitude":         "{field} must be a valid latitude",
+	"validation.longitude":        "{field} must be a valid longitude",
+	"validation.phone":            "{field} must be a Mauritanian number (+222XXXXXXXX)",
+	"validation.priority":         "{field} must be one of: low, medium, high, urgent",
+	"validation.duration":         "{field} must be between 15 minutes and 7 days",
+	"validation.future":           "{field} must be a future ISO 8601 time",
+	"validation.otp_invalid":      "{field} is incorrect or has expired",
+	"validation.time_of_day":      "{field} must be a time in HH:MM format",
+	"validation.timezone":         "{field} must be a time zone such as Africa/Nouakchott",
+	"validation.urgent_disabled":  "Urgent service is not available in this category",
+	"validation.unknown_category": "{field} must only list active categories",
+	"validation.default":          "{field} is invalid",
+
+	// Service request status notifications
+	"notification.status.accepted.title":    "Service Request Accepted",
+	"notification.status.accepted.body":     "A professional has accepted your service request and is on the way!",
+	"notification.status.en_route.title":    "Professional On The Way",
+	"notification.status.en_route.body":     "Your service professional is on the way. Track their arrival in the app.",
+	"notification.status.arrived.title":     "Professional Arrived",
+	"notification.status.arrived.body":      "Your service professional has arrived at your location.",
+	"notification.status.in_progress.title": "Work Started",
+	"notification.status.in_progress.body":  "Your service professional has started working on your request.",
+	"notification.status.completed.title":   "Service Completed",
+	"notification.status.completed.body":    "Your service request has been completed. Please rate your experience.",
+	"notification.status.cancelled.title":   "Service Cancelled",
+	"notification.status.cancelled.body":    "Your service request has been cancelled.",
+	"notification.status.default.title":     "Service Update",
+	"notification.status.default.body":      "Your service request status has been updated.",
+
+	// Other notifications
+	"notification.job_offer.title":          "New job offer",
+	"notification.job_offer.body":           "{title}",
+	"notification.new_job.title":            "New job near you",
+	"notification.new_job.body":             "{title} · {distance} km away",
+	"notification.new_jobs.title":           "{count} new jobs near you",
+	"notification.new_jobs.body":            "Open the app to see them before someone else takes them.",
+	"notification.urgent_job.title":         "🚨 Urgent job near you",
+	"notification.urgent_job.body":          "{title} · {distance} km away",
+	"notification.review_reply.title":       "Your worker replied",
+	"notification.review_reply.body":        "{reply}",
+	"notification.chat.message.title":       "{name}",
+	"notification.chat.message.body":        "{preview}",
+	"notification.chat.summary.title":       "{name}",
+	"notification.chat.summary.body":        "{count} new messages. Latest: {preview}",
+	"notification.line_item_added.title":    "Approve a part",
+	"notification.line_item_added.body":     "Added {description} ({quantity} × {unit_price|money} = {total|money}). Please approve or reject it.",
+	"notification.line_item_approved.title": "Part approved",
+	"notification.line_item_approved.body":  "Approved {description} ({total|money}).",
+	"notification.line_item_rejected.title": "Part rejected",
+	"notification.line_item_rejected.body":  "Rejected {description} ({total|money}). {reason}",
+	"notification.tip_received.title":       "You received a tip",
+	"notification.tip_received.body":        "A customer tipped you {amount|money} for \"{title}\".",
+	"notification.feedback_customer.title":  "We value your feedback",
+	"notification.feedback_customer.body":   "Your first service is complete! Please share your feedback to help us improve.",
+	"notification.feedback_worker.title":    "Help Us Improve Your Experience",
+	"notification.feedback_worker.body":     "Your first job is complete! Please share your feedback to help us enhance your experience.",
+	"notification.login_lockout.title":      "Sign-in temporarily locked",
+	"notification.login_lockout.body":       "We blocked sign-ins to your account for {minutes} minutes after several incorrect passwords. If this wasn't you, change your password.",
+	"notification.price_updated.title":      "Price updated",
+	"notification.price_updated.body":       "The final price of \"{title}\" was updated to {price|money}",
+	"notification.refund_issued.title":      "Refund issued",
+	"notification.refund_issued.body":       "A refund of {amount|money} was issued for \"{title}\"",
+
+	// Organization notifications; {organization} is its name
+	"notification.organization_invite.title": "Organization invitation",
+	"notification.organization_invite.body":  "You were invited to join {organization}. Accept to book repairs billed to it.",
+
+	// Safety notifications and the SMS to an emergency contact; {location} is a map link or empty
+	"notification.sos_raised.title":    "Job paused for a safety review",
+	"notification.sos_raised.body":     "An SOS was raised on \"{title}\". The job is on hold while our team reviews it.",
+	"notification.sos_resumed.title":   "Job resumed",
+	"notification.sos_resumed.body":    "Our team reviewed the SOS on \"{title}\" and the job can continue.",
+	"notification.sos_cancelled.title": "Job cancelled after a safety review",
+	"notification.sos_cancelled.body":  "Our team reviewed the SOS on \"{title}\" and cancelled the job.",
+	"sms.sos_contact":                  "SOS from {name}: they asked for help during a home repair at {address}. {location}",
+
+	// No-show notifications
+	"notification.worker_no_show.title":  "Finding you another worker",
+	"notification.worker_no_show.body":   "Your worker did not turn up for \"{title}\". We are offering it to other workers now.",
+	"notification.no_show_penalty.title": "Job reassigned",
+	"notification.no_show_penalty.body":  "You did not set off or start \"{title}\" in time, so it was given to another worker. This lowers your reliability score.",
+
+	// Reliability notifications; {score} is out of 100
+	"notification.worker_cancelled.title":       "Finding you another worker",
+	"notification.worker_cancelled.body":        "Your worker had to give back \"{title}\". We are offering it to other workers now.",
+	"notification.reliability_warning.title":    "Your reliability score is dropping",
+	"notification.reliability_warning.body":     "Your reliability score is now {score}. Missed, cancelled and late jobs lower it, and a low score limits the jobs you see.",
+	"notification.reliability_reduced.title":    "You now see new jobs later",
+	"notification.reliability_reduced.body":     "Your reliability score is now {score}, so new jobs reach you after other workers. It recovers as older incidents expire.",
+	"notification.reliability_suspended.title":  "Your account is suspended",
+	"notification.reliability_suspended.body":   "You cannot take jobs until {until}. Contact support if you think this is a mistake.",
+	"notification.reliability_reinstated.title": "Your suspension has been lifted",
+	"notification.reliability_reinstated.body":  "You can go available and take jobs again.",
+
+	// Worker onboarding notifications; {note} is the admin's note
+	"notification.onboarding.documents_pending.title": "Please upload your documents again",
+	"notification.onboarding.documents_pending.body":  "We could not approve your ID card. {note}",
+	"notification.onboarding.under_review.title":      "Your profile is under review",
+	"notification.onboarding.under_review.body":       "Our team is reviewing your profile. {note}",
+	"notification.onboarding.training_required.title": "Documents approved",
+	"notification.onboarding.training_required.body":  "Your documents were approved. Complete the training to start receiving jobs. {note}",
+	"notification.onboarding.active.title":            "Welcome aboard",
+	"notification.onboarding.active.body":             "Your onboarding is complete. You can now receive jobs.",
+
+	// AI assistant replies written by the server rather than the model
+	"ai.unavailable":         "AI service is currently unavailable. Please contact support.",
+	"ai.busy":                "Our assistant is very busy right now. Please try again in a few minutes, or create a request directly from the services page.",
+	"ai.busy_worker":         "Our assistant is very busy right now. {worker} is an available {category} professional near you.",
+	"ai.rate_limited":        "Too many messages. Please try again later.",
+	"ai.failed":              "Failed to process your request. Please try again.",
+	"ai.voice_unreadable":    "I could not understand this voice message. Please try again or type your message.",
+	"ai.photo_unreadable":    "I could not read this photo. Please send a JPEG or PNG image of at most 8MB.",
+	"ai.estimated_cost":      "Estimated cost: {price_min}–{price_max} {currency}.",
+	"ai.accept_category":     "Accept to send this request to our {category} professionals.",
+	"ai.accept_draft":        "{title} ({category}). Accept to send this request to our professionals.",
+	"ai.sign_in_to_book":     "Please sign in to book through the assistant.",
+	"ai.worker_unavailable":  "Sorry, this professional is no longer available. I will find you another professional.",
+	"ai.worker_busy":         "Sorry, this professional is currently busy. I will find you another available professional.",
+	"ai.declined":            "Okay, I will find you other options.",
+	"ai.booking_failed":      "Failed to create the service request.",
+	"ai.no_default_address":  "Add a default address to book through the assistant.",
+	"ai.outside_area":        "This service is not available at your address.",
+	"ai.verify_phone":        "Please verify your phone number before booking.",
+	"ai.too_many_requests":   "You have created too many requests recently. Please try again in a few minutes.",
+	"ai.already_booked":      "This request was already sent.",
+	"ai.booked":              "Done! Your request was sent to our professionals. We are waiting for their confirmation.",
+	"ai.booked_scheduled":    "Done! Your request is scheduled for {scheduled_for}.",
+	"ai.worker_accepted":     "The professional accepted your request and is on the way.",
+	"ai.request_ended":       "The professional declined or the request expired. I am looking for other options for you.",
+	"ai.request_title":       "Request via the assistant",
+	"ai.request_description": "Service requested through the AI assistant",
+
+	// Emails
+	"email.footer":                       "You received this email because it is linked to your Repair Service account.",
+	"email.greeting":                     "Hello {name},",
+	"email.code_expires":                 "It expires in {expires_in_minutes} minutes.",
+	"email.verification.subject":         "Confirm your email address",
+	"email.verification.code":            "Your confirmation code is {code}. It expires in {expires_in_minutes} minutes.",
+	"email.verification.code_label":      "Your confirmation code is:",
+	"email.verification.ignore":          "If you did not add this address to your Repair Service account, ignore this email.",
+	"email.password_reset.subject":       "Reset your password",
+	"email.password_reset.code":          "Your password reset code is {code}. It expires in {expires_in_minutes} minutes.",
+	"email.password_reset.code_label":    "Your password reset code is:",
+	"email.password_reset.ignore":        "If you did not ask to reset your password, ignore this email; your password stays unchanged.",
+	"email.request_confirmation.subject": "We received your request #{request_id}",
+	"email.request_confirmation.heading": "We received your request",
+	"email.request_confirmation.body":    "We received your service request \"{title}\" (#{request_id}) and are looking for a worker near {address}.",
+	"email.request_confirmation.budget":  "Budget: {budget|money}",
+	"email.request_confirmation.next":    "You will be notified as soon as a worker accepts it.",
+	"email.receipt.subject":              "Receipt for request #{request_id}",
+	"email.receipt.body":                 "{worker_name} completed \"{title}\" (#{request_id}) on {completed_at|date}.",
+	"email.receipt.service":              "Service",
+	"email.receipt.worker":               "Worker",
+	"email.receipt.completed":            "Completed",
+	"email.receipt.labour":               "Labour",
+	"email.receipt.amount":               "Amount",
+	"email.receipt.thanks":               "Thank you for using Repair Service. You can rate the service in the app.",
+	"email.earnings.subject":             "Your earnings for {week_start|date} - {week_end|date}",
+	"email.earnings.heading":             "Your weekly earnings",
+	"email.earnings.intro":               "Here is your week from {week_start|date} to {week_end|date}:",
+	"email.earnings.jobs":                "Jobs completed",
+	"email.earnings.hours":               "Hours worked",
+	"email.earnings.earnings":            "Earnings",
+	"email.earnings.tips":                "Tips",
+
+	// Chat quick replies, offered by role
+	"chat.quick_reply.worker.on_my_way":         "I'm on my way.",
+	"chat.quick_reply.worker.arriving_soon":     "I'll be there in a few minutes.",
+	"chat.quick_reply.worker.running_late":      "Sorry, I'm running a little late.",
+	"chat.quick_reply.worker.share_location":    "Please share your exact location.",
+	"chat.quick_reply.worker.outside":           "I'm outside.",
+	"chat.quick_reply.worker.job_done":          "The job is done. Please check it and confirm.",
+	"chat.quick_reply.customer.when_arriving":   "When will you arrive?",
+	"chat.quick_reply.customer.location_shared": "I've shared my exact location.",
+	"chat.quick_reply.customer.call_on_arrival": "Please call me when you arrive.",
+	"chat.quick_reply.customer.at_home":         "I'm at home, you can come in.",
+	"chat.quick_reply.customer.thanks":          "Thank you!",
+}
@@ -0,0 +1,196 @@
+package i18n
+
+// fr is the French catalog
+var fr = map[string]string{
+	// Validation errors
+	"validation.invalid_request":  "Données de la requête invalides",
+	"validation.empty_body":       "Le corps de la requête est vide",
+	"validation.malformed_body":   "Le corps de la requête n'est pas un JSON valide",
+	"validation.type":             "{field} doit être de type {param}",
+	"validation.required":         "{field} est obligatoire",
+	"validation.min":              "{field} doit être au moins {param}",
+	"validation.max":              "{field} doit être au plus {param}",
+	"validation.len":              "{field} doit avoir une longueur de {param}",
+	"validation.gt":               "{field} doit être supérieur à {param}",
+	"validation.gte":              "{field} doit être supérieur ou égal à {param}",
+	"validation.lt":               "{field} doit être inférieur à {param}",
+	"validation.lte":              "{field} doit être inférieur ou égal à {param}",
+	"validation.oneof":            "{field} doit être l'une des valeurs : {param}",
+	"validation.latitude":         "{field} doit être une latitude valide",
+	"validation.longitude":        "{field} doit être une longitude valide",
+	"validation.phone":            "{field} doit être un numéro mauritanien (+222XXXXXXXX)",
+	"validation.priority":         "{field} doit être l'une des valeurs : low, medium, high, urgent",
+	"validation.duration":         "{field} doit être comprise entre 15 minutes et 7 jours",
+	"validation.future":           "{field} doit être une date ISO 8601 dans le futur",
+	"validation.otp_invalid":      "{field} est incorrect ou a expiré",
+	"validation.time_of_day":      "{field} doit être une heure au format HH:MM",
+	"validation.timezone":         "{field} doit être un fuseau horaire comme Africa/Nouakchott",
+	"validation.urgent_disabled":  "Le service urgent n'est pas disponible dans cette catégorie",
+	"validation.unknown_category": "{field} ne doit contenir que des catégories actives",
+	"validation.default":          "{field} est invalide",
+
+	// Service request status notifications
+	"notification.status.accepted.title":    "Demande acceptée",
+	"notification.status.accepted.body":     "Un professionnel a accepté votre demande et arrive !",
+	"notification.status.en_route.title":    "Professionnel en route",
+	"notification.status.en_route.body":     "Votre professionnel est en route. Suivez son arrivée dans l'application.",
+	"notification.status.arrived.title":     "Professionnel arrivé",
+	"notification.status.arrived.body":      "Votre professionnel est arrivé à votre adresse.",
+	"notification.status.in_progress.title": "Travaux commencés",
+	"notification.status.in_progress.body":  "Votre professionnel a commencé à travailler sur votre demande.",
+	"notification.status.completed.title":   "Service terminé",
+	"notification.status.completed.body":    "Votre demande est terminée. Merci d'évaluer votre expérience.",
+	"notification.status.cancelled.title":   "Service annulé",
+	"notification.status.cancelled.body":    "Votre demande de service a été annulée.",
+	"notification.status.default.title":     "Mise à jour du service",
+	"notification.status.default.body":      "Le statut de votre demande a été mis à jour.",
+
+	// Other notifications
+	"notification.job_offer.title":          "Nouvelle offre de travail",
+	"notification.job_offer.body":           "{title}",
+	"notification.new_job.title":            "Nouveau travail près de vous",
+	"notification.new_job.body":             "{title} · à {distance} km",
+	"notification.new_jobs.title":           "{count} nouveaux travaux près de vous",
+	"notification.new_jobs.body":            "Ouvrez l'application pour les voir avant qu'un autre ne les prenne.",
+	"notification.urgent_job.title":         "🚨 Travail urgent près de vous",
+	"notification.urgent_job.body":          "{title} · à {distance} km",
+	"notification.review_reply.title":       "Votre professionnel a répondu",
+	"notification.review_reply.body":        "{reply}",
+	"notification.chat.message.title":       "{name}",
+	"notification.chat.message.body":        "{preview}",
+	"notification.chat.summary.title":       "{name}",
+	"notification.chat.summary.body":        "{count} nouveaux messages. Dernier : {preview}",
+	"notification.line_item_added.title":    "Pièce à approuver",
+	"notification.line_item_added.body":     "Ajout de {description} ({quantity} × {unit_price|money} = {total|money}). Merci de l'approuver ou de la refuser.",
+	"notification.line_item_approved.title": "Pièce approuvée",
+	"notification.line_item_approved.body":  "{description} approuvé ({total|money}).",
+	"notification.line_item_rejected.title": "Pièce refusée",
+	"notification.line_item_rejected.body":  "{description} refusé ({total|money}). {reason}",
+	"notification.tip_received.title":       "Vous avez reçu un pourboire",
+	"notification.tip_received.body":        "Un client vous a laissé un pourboire de {amount|money} pour « {title} ».",
+	"notification.feedback_customer.title":  "Votre avis compte",
+	"notification.feedback_customer.body":   "Votre premier service est terminé ! Partagez votre avis pour nous aider à nous améliorer.",
+	"notification.feedback_worker.title":    "Aidez-nous à améliorer votre expérience",
+	"notification.feedback_worker.body":     "Votre première mission est terminée ! Partagez votre avis pour nous aider à améliorer votre expérience.",
+	"notification.login_lockout.title":      "Connexion temporairement bloquée",
+	"notification.login_lockout.body":       "Nous avons bloqué les connexions à votre compte pendant {minutes} minutes après plusieurs mots de passe incorrects. Si ce n'était pas vous, changez votre mot de passe.",
+	"notification.price_updated.title":      "Prix mis à jour",
+	"notification.price_updated.body":       "Le prix final de « {title} » est maintenant de {price|money}",
+	"notification.refund_issued.title":      "Remboursement effectué",
+	"notification.refund_issued.body":       "Un remboursement de {amount|money} a été effectué pour « {title} »",
+
+	// Organization notifications; {organization} is its name
+	"notification.organization_invite.title": "Invitation d'organisation",
+	"notification.organization_invite.body":  "Vous avez été invité à rejoindre {organization}. Acceptez pour réserver des réparations facturées à l'organisation.",
+
+	// Safety notifications and the SMS to an emergency contact; {location} is a map link or empty
+	"notification.sos_raised.title":    "Intervention suspendue pour vérification",
+	"notification.sos_raised.body":     "Une alerte SOS a été déclenchée sur « {title} ». L'intervention est suspendue le temps que notre équipe l'examine.",
+	"notification.sos_resumed.title":   "Intervention reprise",
+	"notification.sos_resumed.body":    "Notre équipe a examiné l'alerte SOS sur « {title} » et l'intervention peut reprendre.",
+	"notification.sos_cancelled.title": "Intervention annulée après vérification",
+	"notification.sos_cancelled.body":  "Notre équipe a examiné l'alerte SOS sur « {title} » et a annulé l'intervention.",
+	"sms.sos_contact":                  "SOS de {name} : demande d'aide pendant une réparation à domicile à {address}. {location}",
+
+	// No-show notifications
+	"notification.worker_no_show.title":  "Nous vous cherchons un autre technicien",
+	"notification.worker_no_show.body":   "Votre technicien ne s'est pas présenté pour « {title} ». Nous la proposons maintenant à d'autres techniciens.",
+	"notification.no_show_penalty.title": "Intervention réattribuée",
+	"notification.no_show_penalty.body":  "Vous n'êtes pas parti ou n'avez pas commencé « {title} » à temps, elle a donc été confiée à un autre technicien. Votre score de fiabilité baisse.",
+
+	// Reliability notifications; {score} is out of 100
+	"notification.worker_cancelled.title":       "Nous vous cherchons un autre technicien",
+	"notification.worker_cancelled.body":        "Votre technicien a dû renoncer à « {title} ». Nous la proposons maintenant à d'autres techniciens.",
+	"notification.reliability_warning.title":    "Votre score de fiabilité baisse",
+	"notification.reliability_warning.body":     "Votre score de fiabilité est maintenant de {score}. Les interventions manquées, annulées ou en retard le font baisser, et un score faible limite les demandes que vous voyez.",
+	"notification.reliability_reduced.title":    "Vous voyez désormais les demandes plus tard",
+	"notification.reliability_reduced.body":     "Votre score de fiabilité est maintenant de {score}, les nouvelles demandes vous parviennent donc après les autres techniciens. Il remonte à mesure que les anciens incidents expirent.",
+	"notification.reliability_suspended.title":  "Votre compte est suspendu",
+	"notification.reliability_suspended.body":   "Vous ne pouvez pas accepter de demandes avant le {until}. Contactez le support si vous pensez qu'il s'agit d'une erreur.",
+	"notification.reliability_reinstated.title": "Votre suspension a été levée",
+	"notification.reliability_reinstated.body":  "Vous pouvez de nouveau vous rendre disponible et accepter des demandes.",
+
+	// Worker onboarding notifications
+	"notification.onboarding.documents_pending.title": "Merci de renvoyer vos documents",
+	"notification.onboarding.documents_pending.body":  "Nous n'avons pas pu valider votre carte d'identité. {note}",
+	"notification.onboarding.under_review.title":      "Votre profil est en cours de vérification",
+	"notification.onboarding.under_review.body":       "Notre équipe vérifie votre profil. {note}",
+	"notification.onboarding.training_required.title": "Documents validés",
+	"notification.onboarding.training_required.body":  "Vos documents ont été validés. Suivez la formation pour commencer à recevoir des missions. {note}",
+	"notification.onboarding.active.title":            "Bienvenue",
+	"notification.onboarding.active.body":             "Votre inscription est terminée. Vous pouvez maintenant recevoir des missions.",
+
+	// AI assistant replies
+	"ai.unavailable":         "L'assistant IA est indisponible pour le moment. Veuillez contacter le support.",
+	"ai.busy":                "Notre assistant est très sollicité en ce moment. Réessayez dans quelques minutes, ou créez une demande directement depuis la page des services.",
+	"ai.busy_worker":         "Notre assistant est très sollicité en ce moment. {worker} est un professionnel {category} disponible près de chez vous.",
+	"ai.rate_limited":        "Trop de messages. Veuillez réessayer plus tard.",
+	"ai.failed":              "Impossible de traiter votre demande. Veuillez réessayer.",
+	"ai.voice_unreadable":    "Je n'ai pas compris ce message vocal. Réessayez ou écrivez votre message.",
+	"ai.photo_unreadable":    "Je n'ai pas pu lire cette photo. Envoyez une image JPEG ou PNG de 8 Mo maximum.",
+	"ai.estimated_cost":      "Coût estimé : {price_min}–{price_max} {currency}.",
+	"ai.accept_category":     "Acceptez pour envoyer cette demande à nos professionnels {category}.",
+	"ai.accept_draft":        "{title} ({category}). Acceptez pour envoyer cette demande à nos professionnels.",
+	"ai.sign_in_to_book":     "Veuillez vous connecter pour réserver via l'assistant.",
+	"ai.worker_unavailable":  "Désolé, ce professionnel n'est plus disponible. Je vais vous trouver un autre professionnel.",
+	"ai.worker_busy":         "Désolé, ce professionnel est actuellement occupé. Je vais vous trouver un autre professionnel disponible.",
+	"ai.declined":            "D'accord, je vais vous trouver d'autres options.",
+	"ai.booking_failed":      "Erreur lors de la création de la demande de service",
+	"ai.no_default_address":  "Ajoutez une adresse par défaut pour réserver via l'assistant.",
+	"ai.outside_area":        "Ce service n'est pas disponible à votre adresse.",
+	"ai.verify_phone":        "Veuillez vérifier votre numéro de téléphone avant de réserver.",
+	"ai.too_many_requests":   "Vous avez créé trop de demandes récemment. Réessayez dans quelques minutes.",
+	"ai.already_booked":      "Cette demande a déjà été envoyée.",
+	"ai.booked":              "Parfait ! Votre demande a été envoyée aux professionnels. Nous attendons leur confirmation.",
+	"ai.booked_scheduled":    "Parfait ! Votre demande est planifiée pour le {scheduled_for}.",
+	"ai.worker_accepted":     "Le professionnel a accepté votre demande et est en route.",
+	"ai.request_ended":       "Le professionnel a refusé ou la demande a expiré. Je cherche d'autres options pour vous.",
+	"ai.request_title":       "Demande via IA",
+	"ai.request_description": "Service demandé via l'assistant IA",
+
+	// Emails
+	"email.footer":                       "Vous recevez cet e-mail car il est associé à votre compte Repair Service.",
+	"email.greeting":                     "Bonjour {name},",
+	"email.code_expires":                 "Il expire dans {expires_in_minutes} minutes.",
+	"email.verification.subject":         "Confirmez votre adresse e-mail",
+	"email.verification.code":            "Votre code de confirmation est {code}. Il expire dans {expires_in_minutes} minutes.",
+	"email.verification.code_label":      "Votre code de confirmation est :",
+	"email.verification.ignore":          "Si vous n'avez pas ajouté cette adresse à votre compte Repair Service, ignorez cet e-mail.",
+	"email.password_reset.subject":       "Réinitialisez votre mot de passe",
+	"email.password_reset.code":          "Votre code de réinitialisation est {code}. Il expire dans {expires_in_minutes} minutes.",
+	"email.password_reset.code_label":    "Votre code de réinitialisation est :",
+	"email.password_reset.ignore":        "Si vous n'avez pas demandé à réinitialiser votre mot de passe, ignorez cet e-mail ; votre mot de passe reste inchangé.",
+	"email.request_confirmation.subject": "Nous avons reçu votre demande n° {request_id}",
+	"email.request_confirmation.heading": "Nous avons reçu votre demande",
+	"email.request_confirmation.body":    "Nous avons reçu votre demande « {title} » (n° {request_id}) et cherchons un professionnel près de {address}.",
+	"email.request_confirmation.budget":  "Budget : {budget|money}",
+	"email.request_confirmation.next":    "Vous serez prévenu dès qu'un professionnel l'acceptera.",
+	"email.receipt.subject":              "Reçu de la demande n° {request_id}",
+	"email.receipt.body":                 "{worker_name} a terminé « {title} » (n° {request_id}) le {completed_at|date}.",
+	"email.receipt.service":              "Service",
+	"email.receipt.worker":               "Professionnel",
+	"email.receipt.completed":            "Terminé le",
+	"email.receipt.labour":               "Main-d'œuvre",
+	"email.receipt.amount":               "Montant",
+	"email.receipt.thanks":               "Merci d'avoir utilisé Repair Service. Vous pouvez évaluer le service dans l'application.",
+	"email.earnings.subject":             "Vos gains du {week_start|date} au {week_end|date}",
+	"email.earnings.heading":             "Vos gains de la semaine",
+	"email.earnings.intro":               "Voici votre semaine du {week_start|date} au {week_end|date} :",
+	"email.earnings.jobs":                "Missions terminées",
+	"email.earnings.hours":               "Heures travaillées",
+	"email.earnings.earnings":            "Gains",
+	"email.earnings.tips":                "Pourboires",
+
+	// Chat quick replies
+	"chat.quick_reply.worker.on_my_way":         "Je suis en route.",
+	"chat.quick_reply.worker.arriving_soon":     "J'arrive dans quelques minutes.",
+	"chat.quick_reply.worker.running_late":      "Désolé, j'ai un peu de retard.",
+	"chat.quick_reply.worker.share_location":    "Merci de partager votre position exacte.",
+	"chat.quick_reply.worker.outside":           "Je suis devant chez vous.",
+	"chat.quick_reply.worker.job_done":          "Le travail est terminé. Merci de vérifier et de confirmer.",
+	"chat.quick_reply.customer.when_arriving":   "Quand arriverez-vous ?",
+	"chat.quick_reply.customer.location_shared": "J'ai partagé ma position exacte.",
+	"chat.quick_reply.customer.call_on_arrival": "Merci de m'appeler en arrivant.",
+	"chat.quick_reply.customer.at_home":         "Je suis à la maison, vous pouvez entrer.",
+	"chat.quick_reply.customer.thanks":          "Merci !",
+}
@@ -0,0 +1,228 @@
+// Package i18n holds the message catalogs for every string the server writes for people, such
+// as validation errors, notifications, emails and assistant replies, and picks the language to
+// write them in.
+//
+// Messages are looked up by key and may contain {name} placeholders, filled from vars. A
+// placeholder can name a format: {amount|money} renders an amount in the "currency" variable,
+// or ouguiya, with the language's digit grouping, and {at|date} a time or RFC 3339 timestamp as
+// a date.
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// Supported languages. Default is used when neither the user nor the request names one of them.
+const (
+	English = "en"
+	French  = "fr"
+	Arabic  = "ar"
+
+	Default = English
+)
+
+// Languages lists the supported languages, for validation rules such as oneof
+var Languages = []string{English, French, Arabic}
+
+// DefaultCurrency is the currency of amounts formatted without one: the Mauritanian ouguiya
+const DefaultCurrency = "MRU"
+
+// Vars fills a message's placeholders
+type Vars = map[string]interface{}
+
+// catalogs holds the messages of every supported language
+var catalogs = map[string]map[string]string{
+	English: en,
+	French:  fr,
+	Arabic:  ar,
+}
+
+// placeholder matches {name} and {name|format}
+var placeholder = regexp.MustCompile(`\{(\w+)(?:\|(\w+))?\}`)
+
+// Normalize returns the supported language for a tag such as "fr-FR" or "AR", or "" if none
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i > 0 {
+		tag = tag[:i]
+	}
+	if _, ok := catalogs[tag]; ok {
+		return tag
+	}
+	return ""
+}
+
+// Language returns the supported language for tag, or Default
+func Language(tag string) string {
+	if lang := Normalize(tag); lang != "" {
+		return lang
+	}
+	return Default
+}
+
+// Negotiate returns the first supported language in an Accept-Language header, or ""
+func Negotiate(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		if lang := Normalize(strings.SplitN(part, ";", 2)[0]); lang != "" {
+			return lang
+		}
+	}
+	return ""
+}
+
+// Locale picks the language to answer a request in: the signed-in user's preferred language,
+// then Accept-Language, then Default
+func Locale(c *gin.Context) string {
+	return LocaleOr(c, Default)
+}
+
+// LocaleOr is Locale with a different last resort than Default
+func LocaleOr(c *gin.Context, fallback string) string {
+	if user, ok := c.Get("user"); ok {
+		if u, isUser := user.(models.User); isUser {
+			if lang := Normalize(u.PreferredLanguage); lang != "" {
+				return lang
+			}
+		}
+	}
+	if lang := Negotiate(c.GetHeader("Accept-Language")); lang != "" {
+		return lang
+	}
+	return fallback
+}
+
+// Dir returns the text direction of lang, "rtl" or "ltr"
+func Dir(lang string) string {
+	if lang == Arabic {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// Has reports whether key is in the English catalog, which holds every key
+func Has(key string) bool {
+	_, ok := en[key]
+	return ok
+}
+
+// T renders message key in lang, falling back to English for messages not yet translated and
+// to the key itself for unknown keys
+func T(lang, key string, vars Vars) string {
+	message, ok := catalogs[lang][key]
+	if !ok {
+		message, ok = en[key]
+	}
+	if !ok {
+		return key
+	}
+	if !strings.Contains(message, "{") {
+		return message
+	}
+	return strings.TrimSpace(placeholder.ReplaceAllStringFunc(message, func(match string) string {
+		parts := placeholder.FindStringSubmatch(match)
+		value, ok := vars[parts[1]]
+		if !ok {
+			return ""
+		}
+		switch parts[2] {
+		case "money":
+			currency, _ := vars["currency"].(string)
+			return Money(lang, value, currency)
+		case "date":
+			return Date(lang, value)
+		default:
+			return fmt.Sprint(value)
+		}
+	}))
+}
+
+// Money formats an amount in currency with lang's digit grouping and decimal mark, or in ouguiya
+// when currency is empty; missing amounts render as a dash. Messages take the currency from their
+// "currency" variable.
+func Money(lang string, v interface{}, currency string) string {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	var amount money.Amount
+	switch a := v.(type) {
+	case money.Amount:
+		amount = a
+	case *money.Amount:
+		if a == nil {
+			return "-"
+		}
+		amount = *a
+	case float64:
+		amount = money.FromFloat(a)
+	case *float64:
+		if a == nil {
+			return "-"
+		}
+		amount = money.FromFloat(*a)
+	case int:
+		amount = money.Amount(a * money.Scale)
+	default:
+		return "-"
+	}
+	return Amount(lang, amount) + " " + currency
+}
+
+// Amount writes an amount with lang's thousands separator and decimal mark, such as 1,250.50 in
+// English, 1 250,50 in French and 1٬250٫50 in Arabic
+func Amount(lang string, amount money.Amount) string {
+	group, decimal := ",", "."
+	switch lang {
+	case French:
+		group, decimal = "\u202f", ","
+	case Arabic:
+		group, decimal = "٬", "٫"
+	}
+	text := amount.String()
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	units, cents, _ := strings.Cut(text, ".")
+	var grouped strings.Builder
+	for i, digit := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			grouped.WriteString(group)
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + grouped.String() + decimal + cents
+}
+
+// Date formats a time or RFC 3339 timestamp as a calendar date in lang
+func Date(lang string, v interface{}) string {
+	var t time.Time
+	switch at := v.(type) {
+	case time.Time:
+		t = at
+	case *time.Time:
+		if at == nil {
+			return "-"
+		}
+		t = *at
+	case string:
+		parsed, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return at
+		}
+		t = parsed
+	default:
+		return fmt.Sprint(v)
+	}
+	if lang == English {
+		return t.Format("2 Jan 2006")
+	}
+	return t.Format("02/01/2006")
+}
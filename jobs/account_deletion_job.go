@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// accountDeletionBatch caps how many accounts are anonymized per run
+const accountDeletionBatch = 100
+
+// AccountDeletionJob anonymizes accounts whose deletion grace period has ended
+type AccountDeletionJob struct {
+	stopChan chan bool
+}
+
+// NewAccountDeletionJob creates a new account deletion job
+func NewAccountDeletionJob() *AccountDeletionJob {
+	return &AccountDeletionJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the account deletion job
+func (j *AccountDeletionJob) Start() {
+	go j.run()
+	log.Println("🚀 Account deletion job started")
+}
+
+// Stop stops the account deletion job
+func (j *AccountDeletionJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Account deletion job stopped")
+}
+
+// run executes the account deletion job
+func (j *AccountDeletionJob) run() {
+	j.anonymizeDue()
+	beat("account_deletion", time.Hour)
+
+	ticker := time.NewTicker(1 * time.Hour) // Check every hour
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.anonymizeDue()
+			beat("account_deletion", time.Hour)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
+
+// anonymizeDue anonymizes every account past its scheduled deletion. A failure is retried on
+// the next run.
+func (j *AccountDeletionJob) anonymizeDue() {
+	accounts := services.NewAccountService()
+
+	ids, err := accounts.DueForAnonymization(accountDeletionBatch)
+	if err != nil {
+		log.Printf("❌ Failed to load accounts due for deletion: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		if err := accounts.Anonymize(id); err != nil {
+			log.Printf("❌ Failed to anonymize user %d: %v", id, err)
+			continue
+		}
+		log.Printf("🗑️ Anonymized user %d", id)
+	}
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// AIContextJob keeps the workers and categories the AI assistant prompts with up to date, so
+// prompts do not query them on every message
+type AIContextJob struct {
+	stopChan chan bool
+}
+
+// NewAIContextJob creates a new AI context job
+func NewAIContextJob() *AIContextJob {
+	return &AIContextJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the AI context job
+func (j *AIContextJob) Start() {
+	go j.run()
+	log.Println("🚀 AI context job started")
+}
+
+// Stop stops the AI context job
+func (j *AIContextJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 AI context job stopped")
+}
+
+// run executes the AI context job
+func (j *AIContextJob) run() {
+	j.refresh()
+
+	ticker := time.NewTicker(services.AIContextRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.refresh()
+		case <-j.stopChan:
+			return
+		}
+	}
+}
+
+// refresh reloads the AI context snapshot
+func (j *AIContextJob) refresh() {
+	if err := services.RefreshAIContext(); err != nil {
+		log.Printf("❌ Failed to refresh AI context: %v", err)
+		return
+	}
+	beat("ai_context", services.AIContextRefreshInterval)
+}
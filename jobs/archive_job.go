@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// archiveHour is the local hour at which the nightly archival runs
+const archiveHour = 4
+
+// ArchiveJob archives completed and cancelled requests, with their chats, once they are older than
+// the retention window
+type ArchiveJob struct {
+	stopChan chan bool
+}
+
+// NewArchiveJob creates a new archive job
+func NewArchiveJob() *ArchiveJob {
+	return &ArchiveJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the archive job
+func (j *ArchiveJob) Start() {
+	go j.run()
+	log.Println("🚀 Archive job started")
+}
+
+// Stop stops the archive job
+func (j *ArchiveJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Archive job stopped")
+}
+
+// run executes the archive job
+func (j *ArchiveJob) run() {
+	beat("archive", 24*time.Hour)
+
+	for {
+		timer := time.NewTimer(time.Until(nextDailyRun(time.Now(), archiveHour)))
+		select {
+		case <-timer.C:
+			j.archive()
+			beat("archive", 24*time.Hour)
+		case <-j.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// archive archives every request past the retention window. Requests left over after a failure
+// are picked up on the next run.
+func (j *ArchiveJob) archive() {
+	cutoff := time.Now().AddDate(0, 0, -services.ArchiveRetentionDays())
+	count, err := services.NewArchiveService().ArchiveBefore(cutoff)
+	if err != nil {
+		log.Printf("❌ Failed to archive requests after %d: %v", count, err)
+		return
+	}
+	log.Printf("📦 Archived %d requests last updated before %s", count, cutoff.Format("2006-01-02"))
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// CallSessionJob closes masked-call sessions once their job is over so the proxy numbers are recycled
+type CallSessionJob struct {
+	stopChan chan bool
+	process  func()
+}
+
+// NewCallSessionJob creates a new call session job; process is called on every tick
+func NewCallSessionJob(process func()) *CallSessionJob {
+	return &CallSessionJob{
+		stopChan: make(chan bool),
+		process:  process,
+	}
+}
+
+// Start begins the call session job
+func (j *CallSessionJob) Start() {
+	go j.run()
+	log.Println("🚀 Call session job started")
+}
+
+// Stop stops the call session job
+func (j *CallSessionJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Call session job stopped")
+}
+
+// run executes the call session job
+func (j *CallSessionJob) run() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+	beat("call_session", 1*time.Minute)
+
+	for {
+		select {
+		case <-ticker.C:
+			j.process()
+			beat("call_session", 1*time.Minute)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// DemandAggregationJob periodically rebuilds the worker demand heatmap
+type DemandAggregationJob struct {
+	stopChan chan bool
+}
+
+// NewDemandAggregationJob creates a new demand aggregation job
+func NewDemandAggregationJob() *DemandAggregationJob {
+	return &DemandAggregationJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the demand aggregation job
+func (j *DemandAggregationJob) Start() {
+	go j.run()
+	log.Println("🚀 Demand aggregation job started")
+}
+
+// Stop stops the demand aggregation job
+func (j *DemandAggregationJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Demand aggregation job stopped")
+}
+
+// run executes the demand aggregation job
+func (j *DemandAggregationJob) run() {
+	// Build once on startup so the endpoint has data right away
+	j.rebuild()
+	beat("demand_aggregation", time.Hour)
+
+	ticker := time.NewTicker(1 * time.Hour) // Rebuild every hour
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.rebuild()
+			beat("demand_aggregation", time.Hour)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
+
+// rebuild recomputes the heatmap cells
+func (j *DemandAggregationJob) rebuild() {
+	if err := services.NewDemandService().RebuildHeatmap(); err != nil {
+		log.Printf("❌ Failed to rebuild demand heatmap: %v", err)
+	}
+}
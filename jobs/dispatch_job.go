@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// DispatchJob cascades auto-assignment offers that were not accepted in time
+type DispatchJob struct {
+	stopChan chan bool
+	process  func()
+}
+
+// NewDispatchJob creates a new dispatch job; process is called on every tick
+func NewDispatchJob(process func()) *DispatchJob {
+	return &DispatchJob{
+		stopChan: make(chan bool),
+		process:  process,
+	}
+}
+
+// Start begins the dispatch job
+func (j *DispatchJob) Start() {
+	go j.run()
+	log.Println("🚀 Dispatch job started")
+}
+
+// Stop stops the dispatch job
+func (j *DispatchJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Dispatch job stopped")
+}
+
+// run executes the dispatch job
+func (j *DispatchJob) run() {
+	ticker := time.NewTicker(10 * time.Second) // Offers expire after 45 seconds
+	defer ticker.Stop()
+	beat("dispatch", 10*time.Second)
+
+	for {
+		select {
+		case <-ticker.C:
+			j.process()
+			beat("dispatch", 10*time.Second)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
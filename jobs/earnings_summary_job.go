@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// earningsSummaryBatch caps how many workers are loaded at a time
+const earningsSummaryBatch = 100
+
+// EarningsSummaryJob emails workers their earnings for the previous week. It checks hourly and
+// each worker is sent a week once, so a restart or several instances never send duplicates.
+type EarningsSummaryJob struct {
+	stopChan chan bool
+}
+
+// NewEarningsSummaryJob creates a new earnings summary job
+func NewEarningsSummaryJob() *EarningsSummaryJob {
+	return &EarningsSummaryJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the earnings summary job
+func (j *EarningsSummaryJob) Start() {
+	go j.run()
+	log.Println("🚀 Earnings summary job started")
+}
+
+// Stop stops the earnings summary job
+func (j *EarningsSummaryJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Earnings summary job stopped")
+}
+
+// run executes the earnings summary job
+func (j *EarningsSummaryJob) run() {
+	j.sendDue()
+	beat("earnings_summary", time.Hour)
+
+	ticker := time.NewTicker(1 * time.Hour) // Check every hour
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.sendDue()
+			beat("earnings_summary", time.Hour)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
+
+// sendDue queues last week's summary for every worker who has not had it yet
+func (j *EarningsSummaryJob) sendDue() {
+	summaries := services.NewEarningsSummaryService()
+	start, end := services.LastWeek(time.Now())
+
+	queued := 0
+	for {
+		workers, err := summaries.DueWorkers(end, earningsSummaryBatch)
+		if err != nil {
+			log.Printf("❌ Failed to load workers due an earnings summary: %v", err)
+			return
+		}
+
+		for _, worker := range workers {
+			if err := summaries.Enqueue(worker, start, end); err != nil {
+				// Stop rather than reloading the same worker forever; the next run retries
+				log.Printf("❌ Failed to queue earnings summary for worker %d: %v", worker.ID, err)
+				return
+			}
+			queued++
+		}
+		if len(workers) < earningsSummaryBatch {
+			break
+		}
+	}
+
+	if queued > 0 {
+		log.Printf("📧 Queued %d weekly earnings summaries", queued)
+	}
+}
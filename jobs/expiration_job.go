@@ -5,17 +5,21 @@ import (
 	"time"
 	"repair-service-server/database"
 	"repair-service-server/models"
+	"repair-service-server/services"
 )
 
 // ExpirationJob handles expired service requests
 type ExpirationJob struct {
-	stopChan chan bool
+	stopChan  chan bool
+	lastPurge time.Time
+	onExpired func(models.CustomerServiceRequest)
 }
 
-// NewExpirationJob creates a new expiration job
-func NewExpirationJob() *ExpirationJob {
+// NewExpirationJob creates a new expiration job; onExpired is called for every request it expires
+func NewExpirationJob(onExpired func(models.CustomerServiceRequest)) *ExpirationJob {
 	return &ExpirationJob{
-		stopChan: make(chan bool),
+		stopChan:  make(chan bool),
+		onExpired: onExpired,
 	}
 }
 
@@ -35,11 +39,22 @@ func (j *ExpirationJob) Stop() {
 func (j *ExpirationJob) run() {
 	ticker := time.NewTicker(30 * time.Second) // Check every 30 seconds
 	defer ticker.Stop()
+	beat("expiration", 30*time.Second)
 
 	for {
 		select {
 		case <-ticker.C:
 			j.checkExpiredRequests()
+			if time.Since(j.lastPurge) > time.Hour {
+				j.lastPurge = time.Now()
+				j.purgeExpiredIdempotencyKeys()
+				j.purgeExpiredPhoneVerifications()
+				j.purgeExpiredEmailVerifications()
+				j.purgeStaleLoginThrottles()
+				j.purgeExpiredNotifications()
+				j.purgeStaleUploadSessions()
+			}
+			beat("expiration", 30*time.Second)
 		case <-j.stopChan:
 			return
 		}
@@ -80,11 +95,89 @@ func (j *ExpirationJob) expireRequest(request models.CustomerServiceRequest) {
 	}
 
 	log.Printf("✅ Request %d expired successfully", request.ID)
+	if j.onExpired != nil {
+		j.onExpired(request)
+	}
 	
 	// TODO: Send notification to customer about expired request
 	// TODO: Send notification to workers that the request is no longer available
 }
 
+// purgeExpiredIdempotencyKeys deletes stored responses whose replay window has passed
+func (j *ExpirationJob) purgeExpiredIdempotencyKeys() {
+	result := database.DB.Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		log.Printf("❌ Error purging idempotency keys: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🧹 Purged %d expired idempotency keys", result.RowsAffected)
+	}
+}
+
+// purgeExpiredPhoneVerifications deletes SMS codes that expired more than a day ago. The day of
+// history keeps the hourly send limit accurate.
+func (j *ExpirationJob) purgeExpiredPhoneVerifications() {
+	result := database.DB.Where("expires_at < ?", time.Now().Add(-24*time.Hour)).Delete(&models.PhoneVerification{})
+	if result.Error != nil {
+		log.Printf("❌ Error purging phone verifications: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🧹 Purged %d expired phone verifications", result.RowsAffected)
+	}
+}
+
+// purgeExpiredEmailVerifications deletes emailed codes that expired more than a day ago, for the
+// same reason as SMS codes
+func (j *ExpirationJob) purgeExpiredEmailVerifications() {
+	result := database.DB.Where("expires_at < ?", time.Now().Add(-24*time.Hour)).Delete(&models.EmailVerification{})
+	if result.Error != nil {
+		log.Printf("❌ Error purging email verifications: %v", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("🧹 Purged %d expired email verifications", result.RowsAffected)
+	}
+}
+
+// purgeStaleLoginThrottles deletes sign-in failure counters that no longer delay or lock anyone
+func (j *ExpirationJob) purgeStaleLoginThrottles() {
+	purged, err := services.NewLoginGuardService().PurgeStale()
+	if err != nil {
+		log.Printf("❌ Error purging login throttles: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("🧹 Purged %d stale login throttles", purged)
+	}
+}
+
+// purgeExpiredNotifications deletes in-app notifications past the retention period
+func (j *ExpirationJob) purgeExpiredNotifications() {
+	purged, err := services.NewNotificationInboxService().PurgeExpired()
+	if err != nil {
+		log.Printf("❌ Error purging expired notifications: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("🧹 Purged %d expired notifications", purged)
+	}
+}
+
+// purgeStaleUploadSessions expires resumable uploads that were never completed and deletes what
+// was uploaded for them
+func (j *ExpirationJob) purgeStaleUploadSessions() {
+	expired, err := services.NewUploadService().ExpireStale(time.Now())
+	if err != nil {
+		log.Printf("❌ Error expiring upload sessions: %v", err)
+		return
+	}
+	if expired > 0 {
+		log.Printf("🧹 Expired %d unfinished upload sessions", expired)
+	}
+}
+
 // GetExpiredRequests returns all expired requests for testing/debugging
 func (j *ExpirationJob) GetExpiredRequests() ([]models.CustomerServiceRequest, error) {
 	var requests []models.CustomerServiceRequest
@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// HeartbeatStatus reports when a background job last ran and whether that is recent enough
+type HeartbeatStatus struct {
+	Name     string    `json:"name"`
+	LastBeat time.Time `json:"last_beat"`
+	Interval string    `json:"interval"`
+	Healthy  bool      `json:"healthy"`
+}
+
+type heartbeat struct {
+	last     time.Time
+	interval time.Duration
+}
+
+var (
+	heartbeatsMu sync.RWMutex
+	heartbeats   = make(map[string]heartbeat)
+)
+
+// beat records that a job is alive; interval is how often the job is expected to beat
+func beat(name string, interval time.Duration) {
+	heartbeatsMu.Lock()
+	heartbeats[name] = heartbeat{last: time.Now(), interval: interval}
+	heartbeatsMu.Unlock()
+}
+
+// Heartbeats returns the status of every started job. A job is unhealthy once it has
+// missed two consecutive beats.
+func Heartbeats() []HeartbeatStatus {
+	heartbeatsMu.RLock()
+	defer heartbeatsMu.RUnlock()
+
+	statuses := make([]HeartbeatStatus, 0, len(heartbeats))
+	for name, hb := range heartbeats {
+		statuses = append(statuses, HeartbeatStatus{
+			Name:     name,
+			LastBeat: hb.last,
+			Interval: hb.interval.String(),
+			Healthy:  time.Since(hb.last) <= 2*hb.interval+time.Minute,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
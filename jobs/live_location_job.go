@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// LiveLocationJob ends live chat locations once their sharing time is over
+type LiveLocationJob struct {
+	stopChan chan bool
+	process  func()
+}
+
+// NewLiveLocationJob creates a new live location job; process is called on every tick
+func NewLiveLocationJob(process func()) *LiveLocationJob {
+	return &LiveLocationJob{
+		stopChan: make(chan bool),
+		process:  process,
+	}
+}
+
+// Start begins the live location job
+func (j *LiveLocationJob) Start() {
+	go j.run()
+	log.Println("🚀 Call session job started")
+}
+
+// Stop stops the live location job
+func (j *LiveLocationJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Call session job stopped")
+}
+
+// run executes the live location job
+func (j *LiveLocationJob) run() {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+	beat("live_location", 1*time.Minute)
+
+	for {
+		select {
+		case <-ticker.C:
+			j.process()
+			beat("live_location", 1*time.Minute)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// loyaltyRecomputeHour is the local hour at which the nightly loyalty recompute runs
+const loyaltyRecomputeHour = 3
+
+// LoyaltyJob recomputes every customer's loyalty tier every night, so points older than 12 months
+// stop counting even without a new completed request
+type LoyaltyJob struct {
+	stopChan chan bool
+}
+
+// NewLoyaltyJob creates a new loyalty job
+func NewLoyaltyJob() *LoyaltyJob {
+	return &LoyaltyJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the loyalty job
+func (j *LoyaltyJob) Start() {
+	go j.run()
+	log.Println("🚀 Loyalty job started")
+}
+
+// Stop stops the loyalty job
+func (j *LoyaltyJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Loyalty job stopped")
+}
+
+// run executes the loyalty job
+func (j *LoyaltyJob) run() {
+	beat("loyalty", 24*time.Hour)
+
+	for {
+		timer := time.NewTimer(time.Until(nextDailyRun(time.Now(), loyaltyRecomputeHour)))
+		select {
+		case <-timer.C:
+			j.recompute()
+			beat("loyalty", 24*time.Hour)
+		case <-j.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// recompute recomputes every customer's points, tier and streak
+func (j *LoyaltyJob) recompute() {
+	count, err := services.NewLoyaltyService().RecomputeAll()
+	if err != nil {
+		log.Printf("❌ Failed to recompute loyalty after %d customers: %v", count, err)
+		return
+	}
+	log.Printf("🏅 Loyalty recomputed for %d customers", count)
+}
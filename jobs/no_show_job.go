@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// NoShowJob takes requests away from accepted workers who never turned up and puts them back up
+type NoShowJob struct {
+	stopChan chan bool
+	process  func()
+}
+
+// NewNoShowJob creates a new no-show job; process is called on every tick
+func NewNoShowJob(process func()) *NoShowJob {
+	return &NoShowJob{
+		stopChan: make(chan bool),
+		process:  process,
+	}
+}
+
+// Start begins the no-show job
+func (j *NoShowJob) Start() {
+	go j.run()
+	log.Println("🚀 No-show job started")
+}
+
+// Stop stops the no-show job
+func (j *NoShowJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 No-show job stopped")
+}
+
+// run executes the no-show job
+func (j *NoShowJob) run() {
+	ticker := time.NewTicker(1 * time.Minute) // Windows are set in minutes
+	defer ticker.Stop()
+	beat("no_show", 1*time.Minute)
+
+	for {
+		select {
+		case <-ticker.C:
+			j.process()
+			beat("no_show", 1*time.Minute)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"log"
+	"time"
+)
+
+// OutboxJob delivers side effects recorded in the outbox table
+type OutboxJob struct {
+	stopChan chan bool
+	process  func()
+}
+
+// NewOutboxJob creates a new outbox job; process is called on every tick
+func NewOutboxJob(process func()) *OutboxJob {
+	return &OutboxJob{
+		stopChan: make(chan bool),
+		process:  process,
+	}
+}
+
+// Start begins the outbox job
+func (j *OutboxJob) Start() {
+	go j.run()
+	log.Println("🚀 Outbox job started")
+}
+
+// Stop stops the outbox job
+func (j *OutboxJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Outbox job stopped")
+}
+
+// run executes the outbox job
+func (j *OutboxJob) run() {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	beat("outbox", 5*time.Second)
+
+	for {
+		select {
+		case <-ticker.C:
+			j.process()
+			beat("outbox", 5*time.Second)
+		case <-j.stopChan:
+			return
+		}
+	}
+}
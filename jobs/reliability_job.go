@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// reliabilityRecomputeHour is the local hour at which the nightly reliability recompute runs
+const reliabilityRecomputeHour = 5
+
+// ReliabilityJob rescores workers every night, so events older than the scoring window stop
+// counting even without a new one
+type ReliabilityJob struct {
+	stopChan chan bool
+}
+
+// NewReliabilityJob creates a new reliability job
+func NewReliabilityJob() *ReliabilityJob {
+	return &ReliabilityJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the reliability job
+func (j *ReliabilityJob) Start() {
+	go j.run()
+	log.Println("🚀 Reliability job started")
+}
+
+// Stop stops the reliability job
+func (j *ReliabilityJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Reliability job stopped")
+}
+
+// run executes the reliability job
+func (j *ReliabilityJob) run() {
+	beat("reliability", 24*time.Hour)
+
+	for {
+		timer := time.NewTimer(time.Until(nextDailyRun(time.Now(), reliabilityRecomputeHour)))
+		select {
+		case <-timer.C:
+			j.recompute()
+			beat("reliability", 24*time.Hour)
+		case <-j.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// recompute rescores every worker with reliability events
+func (j *ReliabilityJob) recompute() {
+	count, err := services.NewReliabilityService().RecomputeAll()
+	if err != nil {
+		log.Printf("❌ Failed to recompute reliability after %d workers: %v", count, err)
+		return
+	}
+	log.Printf("📉 Reliability recomputed for %d workers", count)
+}
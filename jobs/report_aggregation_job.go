@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/services"
+)
+
+// reportAggregationHour is the local hour at which the nightly aggregation runs
+const reportAggregationHour = 2
+
+// ReportAggregationJob rebuilds the pre-aggregated platform report metrics and customer spend every
+// night
+type ReportAggregationJob struct {
+	stopChan chan bool
+}
+
+// NewReportAggregationJob creates a new report aggregation job
+func NewReportAggregationJob() *ReportAggregationJob {
+	return &ReportAggregationJob{
+		stopChan: make(chan bool),
+	}
+}
+
+// Start begins the report aggregation job
+func (j *ReportAggregationJob) Start() {
+	go j.run()
+	log.Println("🚀 Report aggregation job started")
+}
+
+// Stop stops the report aggregation job
+func (j *ReportAggregationJob) Stop() {
+	j.stopChan <- true
+	log.Println("🛑 Report aggregation job stopped")
+}
+
+// run executes the report aggregation job
+func (j *ReportAggregationJob) run() {
+	// Catch up on startup in case the server was down overnight
+	j.aggregate()
+	beat("report_aggregation", 24*time.Hour)
+
+	for {
+		timer := time.NewTimer(time.Until(nextReportRun(time.Now())))
+		select {
+		case <-timer.C:
+			j.aggregate()
+			beat("report_aggregation", 24*time.Hour)
+		case <-j.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// aggregate recomputes the trailing window of daily metrics, including today so far, and the spend
+// of customers whose service history changed in that window
+func (j *ReportAggregationJob) aggregate() {
+	now := time.Now()
+	from := now.AddDate(0, 0, -services.ReportReaggregateDays())
+
+	if err := services.NewReportService().AggregateRange(from, now); err != nil {
+		log.Printf("❌ Failed to aggregate platform reports: %v", err)
+		return
+	}
+	log.Printf("📊 Platform reports aggregated from %s to %s", from.Format("2006-01-02"), now.Format("2006-01-02"))
+
+	// Customer spend changes after completion too, when tips and refunds are added
+	count, err := services.NewCustomerAnalyticsService().AggregateUpdatedSince(from)
+	if err != nil {
+		log.Printf("❌ Failed to aggregate customer spend: %v", err)
+		return
+	}
+	log.Printf("📊 Spend aggregated for %d customers", count)
+}
+
+// nextReportRun returns the next occurrence of reportAggregationHour after now
+func nextReportRun(now time.Time) time.Time {
+	return nextDailyRun(now, reportAggregationHour)
+}
+
+// nextDailyRun returns the next occurrence of hour o'clock after now
+func nextDailyRun(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
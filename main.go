@@ -1,22 +1,30 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"strconv"
 	"time"
+	_ "time/tzdata" // Quiet hours use IANA time zones even where the host has no zoneinfo
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
+	"repair-service-server/apierror"
 	"repair-service-server/config"
+	"repair-service-server/cache"
 	"repair-service-server/database"
 	"repair-service-server/jobs"
 	"repair-service-server/middleware"
+	"repair-service-server/migrations"
 	"repair-service-server/models"
+	"repair-service-server/repository"
 	"repair-service-server/routes"
+	"repair-service-server/seed"
 	"repair-service-server/services"
+	"repair-service-server/validation"
 	ws "repair-service-server/websocket"
 )
 
@@ -51,48 +59,147 @@ func main() {
 		log.Println("No .env file found, using system environment variables")
 	}
 
-	// Load configuration
-	config.Load()
+	// Load and validate configuration, listing every invalid setting
+	configErr := config.Load()
 
-	// Initialize database
+	// `server migrate ...` manages the schema and exits without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if configErr != nil && migrations.NeedsDatabase(os.Args[2:]) {
+			log.Fatal(configErr)
+		}
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+	if configErr != nil {
+		log.Fatal(configErr)
+	}
+
+	// `server seed ...` fills the database with synthetic load test data and exits
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		runSeedCommand(os.Args[2:])
+		return
+	}
+
+	// Initialize database and apply pending migrations
 	if err := database.Initialize(); err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
-	// Register models for auto-migration
-	database.DB.AutoMigrate(
-		&models.User{},
-		&models.ServiceCategory{},
-		&models.ServiceOption{},
-		&models.WorkerProfile{},
-		&models.CustomerServiceRequest{},
-		&models.Service{},
-		&models.Address{},
-		// Chat models
-		&models.ChatRoom{},
-		&models.ChatMessage{},
-		&models.ChatNotification{},
-		&models.UserDeviceToken{},
-		// Rating and service history models
-		&models.WorkerRating{},
-		&models.ServiceHistory{},
-		// Worker analytics models
-		&models.WorkerStats{},
-		&models.WorkerDailyStats{},
-		&models.WorkerMonthlyStats{},
-		// Security models
-		&models.RefreshToken{},
-		// Notification models
-		&models.Notification{},
-		&models.PushToken{},
-		// Feedback models
-		&models.Feedback{},
-	)
+	// Catalog cache: Redis when REDIS_URL is set, in-memory otherwise
+	cache.Init()
+
+	// Custom binding rules (phone, priority, duration) and localized validation messages
+	validation.Init()
 
 	// Set Gin mode
-	if os.Getenv("GIN_MODE") == "release" {
-		gin.SetMode(gin.ReleaseMode)
+	gin.SetMode(config.AppConfig.Server.GinMode)
+
+	// Repositories injected into handlers
+	repos := repository.NewGormRepositories(database.DB)
+	router := newRouter(repos)
+
+	port := config.AppConfig.Server.Port
+
+	// Reload broadcast radius, rate limits and cache TTLs on SIGHUP or when .env changes
+	configWatcher := config.NewWatcher(".env", 10*time.Second)
+	configWatcher.Start()
+	defer configWatcher.Stop()
+
+	// Start background jobs
+	expirationJob := jobs.NewExpirationJob(routes.OnRequestExpired)
+	expirationJob.Start()
+	defer expirationJob.Stop()
+
+	// Start auto-dispatch offer cascade job
+	dispatchJob := jobs.NewDispatchJob(routes.ProcessExpiredDispatchOffers)
+	dispatchJob.Start()
+	defer dispatchJob.Stop()
+
+	// Start the no-show job to take requests away from workers who never turned up
+	noShowJob := jobs.NewNoShowJob(routes.ProcessWorkerNoShows)
+	noShowJob.Start()
+	defer noShowJob.Stop()
+
+	// Start the call session job to recycle proxy numbers once jobs are over
+	callSessionJob := jobs.NewCallSessionJob(routes.ProcessFinishedCallSessions)
+	callSessionJob.Start()
+	defer callSessionJob.Stop()
+
+	// Start the live location job to end chat location shares once their time is over
+	liveLocationJob := jobs.NewLiveLocationJob(routes.ProcessExpiredLiveLocations)
+	liveLocationJob.Start()
+	defer liveLocationJob.Stop()
+
+	// Start demand heatmap aggregation job
+	demandJob := jobs.NewDemandAggregationJob()
+	demandJob.Start()
+	defer demandJob.Stop()
+
+	// Start outbox delivery job for side effects of transactional writes
+	outboxJob := jobs.NewOutboxJob(routes.ProcessOutbox)
+	outboxJob.Start()
+	defer outboxJob.Stop()
+
+	// Start nightly platform report aggregation job
+	reportJob := jobs.NewReportAggregationJob()
+	reportJob.Start()
+	defer reportJob.Stop()
+
+	// Start account anonymization job for deletions past their grace period
+	accountDeletionJob := jobs.NewAccountDeletionJob()
+	accountDeletionJob.Start()
+	defer accountDeletionJob.Stop()
+
+	// Start AI assistant context refresh job
+	aiContextJob := jobs.NewAIContextJob()
+	aiContextJob.Start()
+	defer aiContextJob.Stop()
+
+	// Start weekly earnings email job for workers
+	earningsSummaryJob := jobs.NewEarningsSummaryJob()
+	earningsSummaryJob.Start()
+	defer earningsSummaryJob.Stop()
+
+	// Start nightly customer loyalty recompute job
+	loyaltyJob := jobs.NewLoyaltyJob()
+	loyaltyJob.Start()
+	defer loyaltyJob.Stop()
+
+	// Start nightly archival of old completed and cancelled requests
+	archiveJob := jobs.NewArchiveJob()
+	archiveJob.Start()
+	defer archiveJob.Stop()
+
+	// Start nightly reliability rescoring of workers
+	reliabilityJob := jobs.NewReliabilityJob()
+	reliabilityJob.Start()
+	defer reliabilityJob.Stop()
+
+	// Start token cleanup job
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour) // Run daily
+		defer ticker.Stop()
+		
+		for {
+			select {
+			case <-ticker.C:
+				jwtService := services.NewJWTService()
+				if err := jwtService.CleanupExpiredTokens(); err != nil {
+					log.Printf("❌ Token cleanup failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	log.Printf("Server starting on port %s", port)
+	if err := router.Run("0.0.0.0:" + port); err != nil {
+		log.Fatal("Failed to start server:", err)
 	}
+}
+
+// newRouter builds the API: middleware, WebSocket hubs and every route, with handlers using repos
+func newRouter(repos *repository.Repositories) *gin.Engine {
+	adminHandler := routes.NewAdminHandler(repos.Users, repos.Requests)
 
 	// Create router
 	router := gin.New()
@@ -120,9 +227,13 @@ func main() {
 	// Audit logging
 	router.Use(middleware.AuditLogMiddleware())
 
+	// Response compression
+	router.Use(middleware.Compress())
+
 	// Global middleware
 	router.Use(middleware.Logger())
 	router.Use(middleware.Recovery())
+	router.Use(middleware.ErrorHandler())
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -133,17 +244,25 @@ func main() {
 		})
 	})
 
+	// Liveness and readiness probes
+	router.GET("/healthz", routes.Liveness)
+	router.GET("/readyz", routes.Readiness)
+
 	// AI Chat WebSocket endpoint
 	aiChatHandler := ws.NewAIChatHandler()
-	router.GET("/api/v1/ws/ai-chat", aiChatHandler.HandleAIChat)
+	aiChatHandler.SetRequestCreator(routes.CreateServiceRequestFromDraft)
+	router.GET("/api/v1/ws/ai-chat", middleware.OptionalWebSocketAuthMiddleware(), aiChatHandler.HandleAIChat)
 
 	// Worker WebSocket endpoint for notifications
 	workerHandler := ws.NewWorkerHandler()
 	router.GET("/api/v1/ws/worker", workerHandler.HandleWorker)
 
 
-	// Initialize chat hub and routes
+	// Initialize chat hub and routes. The broker records what the hubs send so clients that
+	// cannot use WebSocket can follow it as Server-Sent Events.
+	eventBroker := ws.NewBroker()
 	globalChatHub = ws.NewHub()
+	globalChatHub.Events = eventBroker
 	go globalChatHub.Run()
 	
 	// Initialize service request broadcast channel
@@ -200,14 +319,23 @@ func main() {
 	}()
 
 	routes.InitChatHub()
-	routes.ChatRoutes(router, globalChatHub)
+	routes.ChatRoutes(router, globalChatHub, repos)
+
+	// Live operations stream for the admin dashboard
+	opsHub := ws.NewOpsHub()
+	go opsHub.RunPresence(globalChatHub, 15*time.Second)
+	routes.SetOpsHub(opsHub)
+
+	// Live request updates for customers, over WebSocket or Server-Sent Events
+	routes.SetRequestHub(ws.NewRequestHub(eventBroker))
+	router.GET("/api/v1/service-requests/:id/live", middleware.WebSocketAuthMiddleware(), routes.HandleRequestStream)
+	router.GET("/api/v1/service-requests/:id/events", middleware.WebSocketAuthMiddleware(), routes.HandleRequestEventStream)
 
 	// API routes
 	api := router.Group("/api/v1")
 	{
-		// Auth routes (no authentication required) - with strict rate limiting
+		// Auth routes (no authentication required) - RateLimitMiddleware applies the strict auth budget
 		authRoutes := api.Group("/auth")
-		authRoutes.Use(middleware.AuthRateLimitMiddleware()) // Stricter rate limiting for auth
 		routes.RegisterSecureAuthRoutes(authRoutes) // Use secure auth routes
 
 		// Service routes (public)
@@ -218,12 +346,33 @@ func main() {
 		routes.RegisterCategoryRoutes(api)
 		routes.RegisterServiceOptionRoutes(api) // Add this line
 
+		// Search across services and workers (public)
+		routes.RegisterSearchRoutes(api)
+
+		// Worker profiles customers can browse before booking (public)
+		routes.RegisterPublicWorkerRoutes(api)
+
+		// Regions with their currency, time zone and default language (public)
+		routes.RegisterRegionRoutes(api)
+
+		// Locations behind address links customers send workers, authenticated by the link token
+		routes.RegisterSharedLocationRoutes(api)
+
+		// Call events from the call masking provider, authenticated by its signature
+		api.POST("/webhooks/calls", routes.CallWebhook)
+
+		// Upload notifications from Cloudinary, authenticated by its signature
+		api.POST("/webhooks/cloudinary", routes.CloudinaryWebhook)
+
 		// Note: Rating and service history routes are now protected and require authentication
 
 		// Protected routes
 		protected := api.Group("")
 		protected.Use(middleware.AuthMiddleware())
 		{
+			// Several read-only calls in one round trip, each run through the router as the caller
+			protected.POST("/batch", routes.NewBatchHandler(router).Batch)
+
 			// Debug route to test protected group
 			protected.GET("/debug", func(c *gin.Context) {
 				c.JSON(http.StatusOK, gin.H{
@@ -285,29 +434,34 @@ func main() {
 				workerID := c.Param("id")
 				workerIDInt, err := strconv.Atoi(workerID)
 				if err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worker ID"})
+					apierror.Abort(c, apierror.Validation("Invalid worker ID"))
 					return
 				}
 				
 				// Get worker profile
 				var workerProfile models.WorkerProfile
 				if err := database.DB.First(&workerProfile, workerIDInt).Error; err != nil {
-					c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+					apierror.Abort(c, apierror.NotFound("Worker not found"))
 					return
 				}
 				
 				// Get all requests for this worker
 				var requests []models.CustomerServiceRequest
 				if err := database.DB.Where("assigned_worker_id = ?", workerIDInt).Find(&requests).Error; err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch requests"})
+					apierror.Abort(c, apierror.Internal("Failed to fetch requests", err))
 					return
 				}
 				
-				// Get available requests in worker's category
+				// Get available requests in worker's categories
+				categoryIDs, err := services.NewCategoryService().WorkerCategoryIDs(workerProfile)
+				if err != nil {
+					apierror.Abort(c, apierror.Internal("Failed to load worker categories", err))
+					return
+				}
 				var availableRequests []models.CustomerServiceRequest
-				if err := database.DB.Where("category_id = ? AND status = ? AND assigned_worker_id IS NULL", 
-					workerProfile.CategoryID, "broadcast").Find(&availableRequests).Error; err != nil {
-					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch available requests"})
+				if err := database.DB.Scopes(services.RequestInCategoriesScope(categoryIDs)).
+					Where("status = ? AND assigned_worker_id IS NULL", "broadcast").Find(&availableRequests).Error; err != nil {
+					apierror.Abort(c, apierror.Internal("Failed to fetch available requests", nil))
 					return
 				}
 				
@@ -317,6 +471,7 @@ func main() {
 						"id": workerProfile.ID,
 						"user_id": workerProfile.UserID,
 						"category_id": workerProfile.CategoryID,
+						"category_ids": categoryIDs,
 						"is_available": workerProfile.IsAvailable,
 						"current_lat": workerProfile.CurrentLat,
 						"current_lng": workerProfile.CurrentLng,
@@ -330,12 +485,38 @@ func main() {
 			routes.RegisterWorkerRoutes(protected)
 			
 			// Worker service request routes (protected)
+			protected.GET("/worker/home", routes.GetWorkerHome)
 			protected.GET("/worker/available-requests", routes.GetAvailableServiceRequests)
 			protected.GET("/worker/scheduled-requests", routes.GetScheduledServiceRequests)
 			protected.GET("/worker/active-requests", routes.GetWorkerActiveRequests)
+			protected.GET("/worker/demand-heatmap", routes.GetDemandHeatmap)
 			protected.POST("/worker/requests/:id/respond", routes.RespondToServiceRequest)
+			protected.GET("/worker/requests/:id/navigation", routes.GetRequestNavigation)
+			protected.POST("/worker/requests/:id/en-route", routes.MarkEnRoute)
+			protected.POST("/worker/requests/:id/arrived", routes.MarkArrived)
 			protected.POST("/worker/requests/:id/start", routes.StartServiceRequest)
 			protected.POST("/worker/requests/:id/complete", routes.CompleteServiceRequest)
+			protected.POST("/worker/requests/:id/timer/start", routes.StartWorkTimer)
+			protected.POST("/worker/requests/:id/timer/pause", routes.PauseWorkTimer)
+			protected.POST("/worker/requests/:id/timer/resume", routes.ResumeWorkTimer)
+			protected.POST("/worker/requests/:id/timer/stop", routes.StopWorkTimer)
+			protected.POST("/worker/requests/:id/line-items", routes.AddLineItem)
+			protected.DELETE("/worker/requests/:id/line-items/:itemId", routes.DeleteLineItem)
+			protected.POST("/worker/requests/:id/rate-customer", routes.RateCustomer)
+			protected.POST("/worker/requests/:id/cancel", routes.CancelAssignedRequest)
+			protected.GET("/worker/reliability", routes.GetWorkerReliability)
+			
+			// AI photo diagnosis (protected)
+			protected.POST("/ai/diagnose", routes.DiagnosePhoto)
+			protected.GET("/ai/diagnoses/:id", routes.GetDiagnosis)
+			
+			// Worker schedule and time-off routes (protected)
+			routes.RegisterWorkerScheduleRoutes(protected)
+			
+			// Extra categories a worker serves (protected)
+			routes.RegisterWorkerCategoryRoutes(protected)
+			routes.RegisterWorkerOnboardingRoutes(protected)
+			routes.RegisterWorkerEarningsRoutes(protected)
 			
 			// Rating routes (protected - require authentication)
 			routes.RegisterRatingRoutes(protected)
@@ -343,11 +524,25 @@ func main() {
 			// Service history routes (protected - require authentication)
 			routes.RegisterServiceHistoryRoutes(protected)
 			
+			// Customer loyalty routes (protected)
+			routes.RegisterLoyaltyRoutes(protected)
+			routes.RegisterCustomerAnalyticsRoutes(protected)
+			
+			// Organization accounts (protected)
+			routes.RegisterOrganizationRoutes(protected)
+			
 			// Worker analytics routes (protected - require authentication)
-			routes.RegisterWorkerAnalyticsRoutes(protected)
+			routes.RegisterWorkerAnalyticsRoutes(protected, repos)
 
 			// Worker media upload routes (protected)
 			routes.RegisterWorkerMediaRoutes(protected)
+			routes.RegisterWorkerPortfolioRoutes(protected)
+
+			// Resumable video uploads straight to Cloudinary (protected)
+			routes.RegisterUploadRoutes(protected)
+
+			// Signed links to private uploads such as ID cards and receipts (protected)
+			routes.RegisterMediaRoutes(protected)
 			
 			// Service request routes already registered above
 			
@@ -362,8 +557,12 @@ func main() {
 				c.JSON(200, gin.H{"message": "Notification routes working!"})
 			})
 			notifications.GET("/unread-count", routes.GetUnreadCount)
+			notifications.GET("/preferences", routes.GetNotificationPreferences)
+			notifications.PUT("/preferences", routes.UpdateNotificationPreferences)
 			notifications.POST("/mark-read/:id", routes.MarkNotificationAsRead)
 			notifications.POST("/mark-all-read", routes.MarkAllNotificationsAsRead)
+			notifications.POST("/delete", routes.DeleteNotifications)
+			notifications.DELETE("/:id", routes.DeleteNotification)
 			
 			// Campaign notifications
 			notifications.POST("/send-campaign", routes.SendCampaignNotification)
@@ -394,22 +593,85 @@ func main() {
 			// Admin dashboard
 			adminRoutes.GET("/dashboard/stats", routes.GetDashboardStats)
 
+			// Admin audit logs
+			adminRoutes.GET("/audit-logs", routes.GetAuditLogs)
+
+			// Platform settings
+			adminRoutes.GET("/settings", routes.GetPlatformSettings)
+			adminRoutes.PUT("/settings", routes.UpdatePlatformSettings)
+
+			// Admin reports
+			adminRoutes.GET("/reports/timeseries", routes.GetReportTimeSeries)
+			adminRoutes.GET("/reports/breakdown", routes.GetReportBreakdown)
+			adminRoutes.GET("/reports/decline-reasons", routes.GetDeclineReasonReport)
+			adminRoutes.POST("/reports/rebuild", routes.RebuildReports)
+			adminRoutes.GET("/reports/sms", routes.GetSMSUsage)
+
+			// Admin media
+			adminRoutes.POST("/media/migrate-private", routes.MigratePrivateMedia)
+
+			// Admin live operations
+			adminRoutes.GET("/ops/live", routes.GetOpsLiveSnapshot)
+			adminRoutes.GET("/ops/ws", routes.HandleAdminOpsWebSocket)
+
 			// Admin user management
-			adminRoutes.GET("/users", routes.GetAllUsers)
-			adminRoutes.GET("/users/:id", routes.GetUserById)
-			adminRoutes.PATCH("/users/:id/status", routes.UpdateUserStatus)
-			adminRoutes.DELETE("/users/:id", routes.DeleteUser)
+			adminRoutes.GET("/users", adminHandler.GetAllUsers)
+			adminRoutes.GET("/users/:id", adminHandler.GetUserById)
+			adminRoutes.PATCH("/users/:id/status", adminHandler.UpdateUserStatus)
+			adminRoutes.POST("/users/:id/unlock", adminHandler.UnlockUser)
+			adminRoutes.DELETE("/users/:id", adminHandler.DeleteUser)
+			adminRoutes.POST("/users/:id/impersonate", routes.ImpersonateUser)
+			adminRoutes.GET("/users/:id/customer-ratings", routes.GetCustomerRatingsForAdmin)
+
+			// Admin review moderation
+			adminRoutes.GET("/reviews/flagged", routes.GetFlaggedReviews)
+			adminRoutes.POST("/reviews/:id/hide", routes.HideReview)
+			adminRoutes.POST("/reviews/:id/restore", routes.RestoreReview)
+
+			// Admin content moderation
+			adminRoutes.GET("/moderation/terms", routes.GetModerationTerms)
+			adminRoutes.POST("/moderation/terms", routes.AddModerationTerm)
+			adminRoutes.DELETE("/moderation/terms/:id", routes.DeleteModerationTerm)
+			adminRoutes.GET("/moderation/violations", routes.GetContentViolations)
+			adminRoutes.POST("/moderation/violations/:id/review", routes.ReviewContentViolation)
+
+			// Admin abuse reports
+			adminRoutes.GET("/abuse-reports", routes.GetAbuseReports)
+			adminRoutes.POST("/abuse-reports/:id/resolve", routes.ResolveAbuseReport)
 
 			// Admin worker management
 			adminRoutes.GET("/workers", routes.GetAllWorkers)
 			adminRoutes.GET("/workers/:id", routes.GetWorkerById)
 			adminRoutes.GET("/workers/:id/stats", routes.GetWorkerStatsForAdmin)
 			adminRoutes.PATCH("/workers/:id/verify", routes.VerifyWorker)
+			adminRoutes.PATCH("/workers/:id/categories/:categoryId/verify", routes.VerifyWorkerCategory)
+			adminRoutes.PATCH("/workers/:id/onboarding", routes.TransitionWorkerOnboarding)
 			adminRoutes.PATCH("/workers/:id/availability", routes.UpdateWorkerAvailability)
+			adminRoutes.PATCH("/workers/:id/capacity", routes.UpdateWorkerCapacity)
+			adminRoutes.GET("/workers/:id/reliability", routes.GetWorkerReliabilityForAdmin)
+			adminRoutes.POST("/workers/:id/reliability/suspend", routes.SuspendWorker)
+			adminRoutes.POST("/workers/:id/reliability/reinstate", routes.ReinstateWorker)
+			adminRoutes.POST("/workers/:id/reliability/events/:eventId/waive", routes.WaiveReliabilityEvent)
 
 			// Admin service request management
-			adminRoutes.GET("/service-requests", routes.GetAllServiceRequests)
-			adminRoutes.GET("/service-requests/:id", routes.GetServiceRequestById)
+			adminRoutes.GET("/service-requests", adminHandler.GetAllServiceRequests)
+			adminRoutes.GET("/service-requests/:id", adminHandler.GetServiceRequestById)
+			adminRoutes.POST("/service-requests/:id/codes/override", routes.OverrideJobCode)
+
+			// Admin archive of old requests and chats
+			adminRoutes.GET("/archive/service-requests", routes.GetArchivedServiceRequests)
+			adminRoutes.GET("/archive/service-requests/:id", routes.GetArchivedServiceRequest)
+			adminRoutes.POST("/archive/service-requests/:id/restore", routes.RestoreArchivedServiceRequest)
+
+			// Admin review of SOS raised during jobs
+			adminRoutes.GET("/safety-incidents", routes.GetSafetyIncidents)
+			adminRoutes.GET("/safety-incidents/:id", routes.GetSafetyIncident)
+			adminRoutes.POST("/safety-incidents/:id/resolve", routes.ResolveSafetyIncident)
+
+			// Admin service history adjustments and refunds
+			adminRoutes.GET("/service-history/:id/adjustments", routes.GetServiceHistoryAdjustments)
+			adminRoutes.PATCH("/service-history/:id/price", routes.AdjustServiceHistoryPrice)
+			adminRoutes.POST("/service-history/:id/refund", middleware.Idempotency(), routes.RefundServiceHistory)
 
 			// Admin services management
 			adminRoutes.GET("/services", routes.GetAllServices)
@@ -434,38 +696,46 @@ func main() {
 			adminRoutes.GET("/feedback/stats", routes.GetFeedbackStats)
 			adminRoutes.GET("/feedback/:id", routes.GetFeedbackById)
 			adminRoutes.DELETE("/feedback/:id", routes.DeleteFeedback)
+
+			// Admin service zones
+			adminRoutes.GET("/zones", routes.GetAllServiceZones)
+			adminRoutes.GET("/zones/:id", routes.GetServiceZoneById)
+			adminRoutes.POST("/zones", routes.CreateServiceZone)
+			adminRoutes.PUT("/zones/:id", routes.UpdateServiceZone)
+			adminRoutes.DELETE("/zones/:id", routes.DeleteServiceZone)
+
+			// Admin regions
+			adminRoutes.GET("/regions", routes.GetAllRegions)
+			adminRoutes.GET("/regions/:id", routes.GetRegionById)
+			adminRoutes.POST("/regions", routes.CreateRegion)
+			adminRoutes.PUT("/regions/:id", routes.UpdateRegion)
+			adminRoutes.DELETE("/regions/:id", routes.DeleteRegion)
 		}
 	}
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	return router
+}
 
-	// Start background jobs
-	expirationJob := jobs.NewExpirationJob()
-	expirationJob.Start()
-	defer expirationJob.Stop()
+// runSeedCommand applies pending migrations, then generates synthetic data
+func runSeedCommand(args []string) {
+	if err := database.Initialize(); err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	if err := seed.RunCLI(database.DB, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-	// Start token cleanup job
-	go func() {
-		ticker := time.NewTicker(24 * time.Hour) // Run daily
-		defer ticker.Stop()
-		
-		for {
-			select {
-			case <-ticker.C:
-				jwtService := services.NewJWTService()
-				if err := jwtService.CleanupExpiredTokens(); err != nil {
-					log.Printf("❌ Token cleanup failed: %v", err)
-				}
-			}
+// runMigrateCommand runs a migrate subcommand and exits non-zero on failure
+func runMigrateCommand(args []string) {
+	if migrations.NeedsDatabase(args) {
+		if err := database.Connect(); err != nil {
+			log.Fatal("Failed to connect to database:", err)
 		}
-	}()
-
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run("0.0.0.0:" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	}
+	if err := migrations.RunCLI(database.DB, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,605 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"gorm.io/gorm"
+
+	"repair-service-server/cache"
+	"repair-service-server/config"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/repository"
+	"repair-service-server/routes"
+	"repair-service-server/services"
+	"repair-service-server/testdb"
+	"repair-service-server/utils"
+	"repair-service-server/validation"
+	ws "repair-service-server/websocket"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Main(m))
+}
+
+// lifecycleServer is the full router on a migrated database, with helpers to call it as a user
+type lifecycleServer struct {
+	t      *testing.T
+	db     *gorm.DB
+	server *httptest.Server
+}
+
+func newLifecycleServer(t *testing.T) *lifecycleServer {
+	t.Helper()
+	db := testdb.Install(t)
+
+	// The database URL is only checked for presence; the handlers use database.DB
+	t.Setenv("DB_URL", "postgres://unused")
+	t.Setenv("GIN_MODE", "test")
+	if err := config.Load(); err != nil {
+		t.Fatal(err)
+	}
+	cache.Init()
+	validation.Init()
+
+	server := httptest.NewServer(newRouter(repository.NewGormRepositories(db)))
+	t.Cleanup(server.Close)
+	return &lifecycleServer{t: t, db: db, server: server}
+}
+
+// token signs in as the user
+func (s *lifecycleServer) token(user models.User) string {
+	s.t.Helper()
+	token, err := utils.GenerateToken(user.ID, string(user.Role), user.TokenVersion)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return token
+}
+
+// call sends body as JSON as the user, fails the test unless the response has status want, and
+// decodes the response into out when given
+func (s *lifecycleServer) call(user models.User, method, path string, body interface{}, want int, out interface{}) {
+	s.t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	req, err := http.NewRequest(method, s.server.URL+path, bytes.NewReader(payload))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token(user))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		s.t.Fatalf("%s %s: failed to decode the response: %v", method, path, err)
+	}
+	if resp.StatusCode != want {
+		s.t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.StatusCode, want, raw)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			s.t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+}
+
+// dial opens a WebSocket as the user
+func (s *lifecycleServer) dial(user models.User, path string) *websocket.Conn {
+	s.t.Helper()
+	url := "ws" + strings.TrimPrefix(s.server.URL, "http") + path + "?token=" + s.token(user)
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		s.t.Fatalf("failed to open %s: %v (status %d)", path, err, status)
+	}
+	s.t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// waitForHubMessage reads the worker's chat socket until a message of the given type arrives.
+// Messages queued together come in one frame, one per line.
+func waitForHubMessage(t *testing.T, conn *websocket.Conn, messageType string) map[string]interface{} {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	for {
+		_, frame, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("no %s message received: %v", messageType, err)
+		}
+		for _, line := range bytes.Split(frame, []byte{'\n'}) {
+			var message struct {
+				Type string                 `json:"type"`
+				Data map[string]interface{} `json:"data"`
+			}
+			if err := json.Unmarshal(line, &message); err != nil {
+				t.Fatalf("undecodable hub message %s: %v", line, err)
+			}
+			if message.Type == messageType {
+				return message.Data
+			}
+		}
+	}
+}
+
+// readRequestEvents reads a request's live stream until the server closes it and returns the
+// event of each status change in order
+func readRequestEvents(conn *websocket.Conn) (<-chan []string, func() error) {
+	events := make(chan []string, 1)
+	var readErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var received []string
+		conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+		for {
+			var event ws.RequestEvent
+			if err := conn.ReadJSON(&event); err != nil {
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					readErr = err
+				}
+				events <- received
+				return
+			}
+			if data, ok := event.Data.(map[string]interface{}); ok && event.Type == "status_changed" {
+				received = append(received, fmt.Sprint(data["event"]))
+			}
+		}
+	}()
+	return events, func() error {
+		<-done
+		return readErr
+	}
+}
+
+// seedLifecycle creates a category, a customer with a verified phone and an active, available
+// worker of the category standing a few hundred meters from the job in Nouakchott
+func seedLifecycle(t *testing.T, db *gorm.DB) (models.ServiceCategory, models.User, models.User, models.WorkerProfile) {
+	t.Helper()
+	now := time.Now()
+	category := models.ServiceCategory{Name: "Plomberie test", IsActive: true}
+	customer := models.User{
+		FullName:        "Aminetou Customer",
+		PhoneNumber:     "+22222000001",
+		PasswordHash:    "unused",
+		Role:            models.RoleCustomer,
+		IsActive:        true,
+		PhoneVerifiedAt: &now,
+	}
+	workerUser := models.User{
+		FullName:        "Sidi Worker",
+		PhoneNumber:     "+22222000002",
+		PasswordHash:    "unused",
+		Role:            models.RoleWorker,
+		IsActive:        true,
+		PhoneVerifiedAt: &now,
+	}
+	for _, record := range []interface{}{&category, &customer, &workerUser} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lat, lng := 18.0810, -15.9660
+	worker := models.WorkerProfile{
+		UserID:            workerUser.ID,
+		CategoryID:        category.ID,
+		PhoneNumber:       workerUser.PhoneNumber,
+		Country:           "Mauritanie",
+		State:             "Nouakchott",
+		City:              "Nouakchott",
+		PostalCode:        "00000",
+		IsAvailable:       true,
+		CurrentLat:        &lat,
+		CurrentLng:        &lng,
+		MaxConcurrentJobs: 1,
+		IsVerified:        true,
+		OnboardingStatus:  models.OnboardingActive,
+	}
+	if err := db.Create(&worker).Error; err != nil {
+		t.Fatal(err)
+	}
+	return category, customer, workerUser, worker
+}
+
+// TestRequestLifecycle runs a request from creation to rating through the HTTP API, checking
+// the worker hears about it over the chat socket and the customer follows it on the live stream
+func TestRequestLifecycle(t *testing.T) {
+	s := newLifecycleServer(t)
+	category, customer, workerUser, worker := seedLifecycle(t, s.db)
+
+	// The worker is online before the request is made
+	workerConn := s.dial(workerUser, "/api/v1/chat/ws")
+	deadline := time.Now().Add(5 * time.Second)
+	for !GetGlobalChatHub().IsUserConnected(workerUser.ID) {
+		if time.Now().After(deadline) {
+			t.Fatal("worker socket was not registered with the hub")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	var created struct {
+		ServiceRequest struct {
+			ID uint `json:"id"`
+		} `json:"service_request"`
+	}
+	s.call(customer, http.MethodPost, "/api/v1/service-requests/", map[string]interface{}{
+		"category_id":      category.ID,
+		"title":            "Fuite sous l'évier",
+		"description":      "L'eau coule sous l'évier de la cuisine",
+		"budget":           3000,
+		"location_lat":     18.0799,
+		"location_lng":     -15.9653,
+		"location_address": "Tevragh Zeina, rue 42-150",
+		"location_city":    "Nouakchott",
+	}, http.StatusCreated, &created)
+	requestID := created.ServiceRequest.ID
+	if requestID == 0 {
+		t.Fatal("created request has no ID")
+	}
+
+	broadcast := waitForHubMessage(t, workerConn, "new_request")
+	if broadcast["request_id"] != float64(requestID) {
+		t.Fatalf("worker was told about request %v, want %d", broadcast["request_id"], requestID)
+	}
+
+	// The customer follows the request from here on
+	events, streamErr := readRequestEvents(s.dial(customer, fmt.Sprintf("/api/v1/service-requests/%d/live", requestID)))
+
+	s.call(workerUser, http.MethodPost, fmt.Sprintf("/api/v1/worker/requests/%d/respond", requestID),
+		map[string]interface{}{"response": "accept"}, http.StatusOK, nil)
+
+	var codes struct {
+		Data struct {
+			StartCode      string `json:"start_code"`
+			CompletionCode string `json:"completion_code"`
+		} `json:"data"`
+	}
+	s.call(customer, http.MethodGet, fmt.Sprintf("/api/v1/service-requests/%d/codes", requestID), nil, http.StatusOK, &codes)
+
+	// A wrong code does not start the job
+	s.call(workerUser, http.MethodPost, fmt.Sprintf("/api/v1/worker/requests/%d/start", requestID),
+		map[string]interface{}{"start_code": "not-the-code"}, http.StatusBadRequest, nil)
+	s.call(workerUser, http.MethodPost, fmt.Sprintf("/api/v1/worker/requests/%d/start", requestID),
+		map[string]interface{}{"start_code": codes.Data.StartCode, "agreed_price": 3500}, http.StatusOK, nil)
+	s.call(workerUser, http.MethodPost, fmt.Sprintf("/api/v1/worker/requests/%d/complete", requestID),
+		map[string]interface{}{"completion_code": codes.Data.CompletionCode}, http.StatusOK, nil)
+
+	s.call(customer, http.MethodPost, "/api/v1/ratings/", map[string]interface{}{
+		"service_request_id": requestID,
+		"stars":              5,
+		"comment":            "Rapide et propre",
+		"service_quality":    5,
+		"professionalism":    5,
+		"punctuality":        4,
+		"communication":      5,
+	}, http.StatusCreated, nil)
+
+	// The live stream ends once the request is completed
+	received := <-events
+	if err := streamErr(); err != nil {
+		t.Errorf("live stream ended with %v", err)
+	}
+	want := []string{"request_accepted", "request_started", "request_completed"}
+	next := 0
+	for _, event := range received {
+		if next < len(want) && event == want[next] {
+			next++
+		}
+	}
+	if next != len(want) {
+		t.Errorf("live stream events = %v, want %v in order", received, want)
+	}
+
+	var request models.CustomerServiceRequest
+	if err := s.db.First(&request, requestID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if request.Status != models.RequestStatusCompleted || request.AssignedWorkerID == nil || *request.AssignedWorkerID != worker.ID {
+		t.Errorf("request status %s assigned to %v, want completed by worker %d", request.Status, request.AssignedWorkerID, worker.ID)
+	}
+	if request.AcceptedAt == nil || request.StartedAt == nil || request.CompletedAt == nil {
+		t.Errorf("request accepted_at %v started_at %v completed_at %v, want all set", request.AcceptedAt, request.StartedAt, request.CompletedAt)
+	}
+
+	var history models.ServiceHistory
+	if err := s.db.Where("service_request_id = ?", requestID).First(&history).Error; err != nil {
+		t.Fatalf("no service history: %v", err)
+	}
+	if history.WorkerID != worker.ID || history.FinalPrice == nil || *history.FinalPrice != money.FromFloat(3500) {
+		t.Errorf("history worker %d final price %v, want worker %d charging the agreed 3500", history.WorkerID, history.FinalPrice, worker.ID)
+	}
+
+	// Analytics are delivered through the outbox, which the background job would process next
+	routes.ProcessOutbox()
+	var completion models.OutboxEvent
+	if err := s.db.Where("event_type = ? AND aggregate_id = ?", models.OutboxEventJobCompletionAnalytics, requestID).First(&completion).Error; err != nil {
+		t.Fatalf("no job completion event: %v", err)
+	}
+	if completion.Status != models.OutboxStatusProcessed {
+		t.Errorf("job completion event is %s after processing the outbox (last error %q), want processed", completion.Status, completion.LastError)
+	}
+	var stats models.WorkerStats
+	if err := s.db.Where("worker_id = ?", worker.ID).First(&stats).Error; err != nil {
+		t.Fatalf("no worker stats after processing the outbox: %v", err)
+	}
+	if stats.TotalJobsCompleted != 1 || stats.TotalEarnings != money.FromFloat(3500) {
+		t.Errorf("worker stats = %d jobs earning %s, want 1 job earning 3500", stats.TotalJobsCompleted, stats.TotalEarnings)
+	}
+
+	var rating models.WorkerRating
+	if err := s.db.Where("service_request_id = ?", requestID).First(&rating).Error; err != nil {
+		t.Fatalf("no rating: %v", err)
+	}
+	if rating.WorkerID != worker.ID || rating.CustomerID != customer.ID || rating.Stars != 5 {
+		t.Errorf("rating = worker %d customer %d stars %d, want worker %d customer %d stars 5",
+			rating.WorkerID, rating.CustomerID, rating.Stars, worker.ID, customer.ID)
+	}
+	if err := s.db.First(&worker, worker.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if worker.TotalReviews != 1 || worker.Rating != 5 {
+		t.Errorf("worker has %d reviews rated %.2f, want 1 rated 5", worker.TotalReviews, worker.Rating)
+	}
+}
+
+// statusWith sends a GET to path with the bearer token and returns the response status
+func (s *lifecycleServer) statusWith(token, path string) int {
+	s.t.Helper()
+	req, err := http.NewRequest(http.MethodGet, s.server.URL+path, nil)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// TestRevokedSessionsSignOut checks access tokens stop working once their session, or every
+// session of the user, is revoked
+func TestRevokedSessionsSignOut(t *testing.T) {
+	s := newLifecycleServer(t)
+	_, customer, _, _ := seedLifecycle(t, s.db)
+	jwtService := services.NewJWTService()
+	sessionService := services.NewSessionService()
+
+	signIn := func(deviceID string) string {
+		t.Helper()
+		pair, err := jwtService.GenerateTokenPair(customer.ID, deviceID, "test", "127.0.0.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pair.AccessToken
+	}
+	expect := func(token, name string, want int) {
+		t.Helper()
+		if got := s.statusWith(token, "/api/v1/auth/sessions"); got != want {
+			t.Errorf("%s token: status %d, want %d", name, got, want)
+		}
+	}
+
+	phone, tablet := signIn("phone"), signIn("tablet")
+	legacy := s.token(customer) // Issued outside any session
+	expect(phone, "phone", http.StatusOK)
+	expect(tablet, "tablet", http.StatusOK)
+	expect(legacy, "sessionless", http.StatusOK)
+
+	var session models.RefreshToken
+	if err := s.db.Where("user_id = ? AND device_id = ?", customer.ID, "phone").First(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Revoke(customer.ID, session.ID); err != nil {
+		t.Fatal(err)
+	}
+	expect(phone, "revoked phone", http.StatusUnauthorized)
+	expect(tablet, "tablet", http.StatusOK)
+
+	if _, err := sessionService.RevokeAll(customer.ID); err != nil {
+		t.Fatal(err)
+	}
+	expect(tablet, "tablet after signing out everywhere", http.StatusUnauthorized)
+	expect(legacy, "sessionless after signing out everywhere", http.StatusUnauthorized)
+	expect(signIn("phone"), "new phone", http.StatusOK)
+}
+
+// TestAIDraftBookingChecks checks assistant bookings need a verified phone number and book each
+// accepted proposal only once
+func TestAIDraftBookingChecks(t *testing.T) {
+	s := newLifecycleServer(t)
+	category, customer, _, _ := seedLifecycle(t, s.db)
+	address := models.Address{
+		UserID:         customer.ID,
+		AddressDetails: "Tevragh Zeina, rue 42-150",
+		City:           "Nouakchott",
+		Latitude:       18.0799,
+		Longitude:      -15.9653,
+		IsDefault:      true,
+	}
+	if err := s.db.Create(&address).Error; err != nil {
+		t.Fatal(err)
+	}
+	draft := services.ServiceRequestDraft{
+		CategoryID:  category.ID,
+		Title:       "Fuite sous l'évier",
+		Description: "L'eau coule sous l'évier de la cuisine",
+		Priority:    "medium",
+	}
+
+	if err := s.db.Model(&customer).Update("phone_verified_at", nil).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := routes.CreateServiceRequestFromDraft(customer.ID, draft); !errors.Is(err, services.ErrPhoneUnverified) {
+		t.Fatalf("booking with an unverified phone: %v, want ErrPhoneUnverified", err)
+	}
+
+	if err := s.db.Model(&customer).Update("phone_verified_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+	booked, err := routes.CreateServiceRequestFromDraft(customer.ID, draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := routes.CreateServiceRequestFromDraft(customer.ID, draft); !errors.Is(err, services.ErrDraftAlreadyBooked) {
+		t.Fatalf("accepting the proposal again: %v, want ErrDraftAlreadyBooked", err)
+	}
+
+	var requests int64
+	if err := s.db.Model(&models.CustomerServiceRequest{}).Where("customer_id = ?", customer.ID).Count(&requests).Error; err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Errorf("%d requests booked, want only request %d", requests, booked.ID)
+	}
+}
+
+// TestConcurrentAcceptsAssignOneWorker checks that when several workers accept the same request
+// at once, exactly one is assigned and the others are told it is gone
+func TestConcurrentAcceptsAssignOneWorker(t *testing.T) {
+	s := newLifecycleServer(t)
+	category, customer, firstUser, first := seedLifecycle(t, s.db)
+
+	workerUsers := []models.User{firstUser}
+	for i := 1; i < 5; i++ {
+		user := firstUser
+		user.ID = 0
+		user.PhoneNumber = fmt.Sprintf("+2222200010%d", i)
+		if err := s.db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		worker := first
+		worker.ID = 0
+		worker.UserID = user.ID
+		worker.PhoneNumber = user.PhoneNumber
+		if err := s.db.Create(&worker).Error; err != nil {
+			t.Fatal(err)
+		}
+		workerUsers = append(workerUsers, user)
+	}
+
+	lat, lng := 18.0799, -15.9653
+	request := models.CustomerServiceRequest{
+		CustomerID:      customer.ID,
+		CategoryID:      category.ID,
+		Title:           "Fuite sous l'évier",
+		Description:     "L'eau coule sous l'évier de la cuisine",
+		Priority:        "medium",
+		LocationAddress: "Tevragh Zeina, rue 42-150",
+		LocationCity:    "Nouakchott",
+		LocationLat:     &lat,
+		LocationLng:     &lng,
+		Status:          models.RequestStatusBroadcast,
+	}
+	if err := s.db.Create(&request).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := make(chan int, len(workerUsers))
+	start := make(chan struct{})
+	for _, user := range workerUsers {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/api/v1/worker/requests/%d/respond", s.server.URL, request.ID),
+			strings.NewReader(`{"response": "accept"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+s.token(user))
+		go func() {
+			<-start
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+	close(start)
+
+	accepted := 0
+	for range workerUsers {
+		switch status := <-statuses; status {
+		case http.StatusOK:
+			accepted++
+		case http.StatusConflict, http.StatusBadRequest:
+			// Lost the race, either at the claim or already at the availability check
+		default:
+			t.Errorf("accept answered with status %d", status)
+		}
+	}
+	if accepted != 1 {
+		t.Errorf("%d workers accepted the request, want exactly 1", accepted)
+	}
+
+	var responses int64
+	if err := s.db.Model(&models.WorkerResponse{}).Where("service_request_id = ? AND response = ?", request.ID, "accept").Count(&responses).Error; err != nil {
+		t.Fatal(err)
+	}
+	if responses != 1 {
+		t.Errorf("%d accept responses recorded, want only the winner's", responses)
+	}
+}
+
+func TestImpersonationBlocksWrites(t *testing.T) {
+	t.Setenv("DB_URL", "postgres://unused")
+	t.Setenv("GIN_MODE", "test")
+	if err := config.Load(); err != nil {
+		t.Fatal(err)
+	}
+	routes := map[string]bool{}
+	for _, route := range newRouter(repository.NewGormRepositories(nil)).Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+
+	// Every allowed write must still be routed, so a rename cannot leave a dead entry behind
+	for _, allowed := range middleware.ImpersonationAllowedWrites() {
+		if !routes[allowed] {
+			t.Errorf("impersonation allows %s, which is not a route", allowed)
+		}
+	}
+
+	for _, blocked := range []string{
+		"POST /api/v1/notifications/delete",
+		"PUT /api/v1/auth/emergency-contact",
+		"POST /api/v1/auth/change-password",
+		"POST /api/v1/organizations/:id/members",
+		"PUT /api/v1/organizations/:id/members/:userId",
+		"DELETE /api/v1/organizations/:id/members/:userId",
+		"POST /api/v1/addresses/:id/share",
+		"DELETE /api/v1/auth/sessions",
+	} {
+		if !routes[blocked] {
+			t.Errorf("%s is not a route", blocked)
+			continue
+		}
+		method, path, _ := strings.Cut(blocked, " ")
+		if middleware.ImpersonationAllowed(method, path) {
+			t.Errorf("impersonation tokens may call %s", blocked)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package mediaurl builds resized variants of stored Cloudinary images and video posters, so list
+// screens can load a small thumbnail instead of the full-resolution file. Variants are plain
+// delivery URLs with a transformation inserted; Cloudinary renders and caches each one on first
+// request.
+package mediaurl
+
+import (
+	"path"
+	"strings"
+)
+
+// Transformations applied to each variant. f_auto and q_auto let Cloudinary pick the format and
+// quality the requesting device handles best.
+const (
+	thumbTransformation  = "c_fill,g_auto,w_150,h_150,f_auto,q_auto"
+	mediumTransformation = "c_limit,w_600,h_600,f_auto,q_auto"
+	fullTransformation   = "c_limit,w_1600,h_1600,f_auto,q_auto"
+	// posterOffset takes video posters from the first second, past fade-ins
+	posterOffset = "so_1"
+)
+
+// Variants are the sizes an image is offered in
+type Variants struct {
+	Thumb  string `json:"thumb"`  // 150x150 crop, for lists and avatars
+	Medium string `json:"medium"` // Fits 600x600, for cards and detail headers
+	Full   string `json:"full"`   // Fits 1600x1600, for full-screen viewing
+}
+
+// Image returns the variants of a stored image. URLs that are not public Cloudinary image URLs,
+// such as links saved by older clients, are returned as they are for every variant.
+func Image(url string) *Variants {
+	if url == "" {
+		return nil
+	}
+	prefix, rest, ok := strings.Cut(url, "/image/upload/")
+	if !ok {
+		return &Variants{Thumb: url, Medium: url, Full: url}
+	}
+	build := func(transformation string) string {
+		return prefix + "/image/upload/" + transformation + "/" + rest
+	}
+	return &Variants{
+		Thumb:  build(thumbTransformation),
+		Medium: build(mediumTransformation),
+		Full:   build(fullTransformation),
+	}
+}
+
+// ImagePtr is Image for optional fields
+func ImagePtr(url *string) *Variants {
+	if url == nil {
+		return nil
+	}
+	return Image(*url)
+}
+
+// VideoPoster returns still images of a stored video at each size, as JPEGs. Full stays the
+// video itself. Nil when the URL is not a public Cloudinary video URL.
+func VideoPoster(url string) *Variants {
+	prefix, rest, ok := strings.Cut(url, "/video/upload/")
+	if !ok {
+		return nil
+	}
+	still := strings.TrimSuffix(rest, path.Ext(rest)) + ".jpg"
+	build := func(transformation string) string {
+		// Without f_auto, which would pick a video format for a video asset
+		return prefix + "/video/upload/" + posterOffset + "," + strings.Replace(transformation, ",f_auto", "", 1) + "/" + still
+	}
+	return &Variants{
+		Thumb:  build(thumbTransformation),
+		Medium: build(mediumTransformation),
+		Full:   url,
+	}
+}
@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// Context keys used to pass audit details from handlers to AuditLogMiddleware
+const (
+	auditEntityTypeKey = "audit_entity_type"
+	auditEntityIDKey   = "audit_entity_id"
+	auditBeforeKey     = "audit_before"
+	auditAfterKey      = "audit_after"
+)
+
+// RequestIDHeader carries the request ID, accepted from the client or generated per request
+const RequestIDHeader = "X-Request-ID"
+
+// RecordAuditChange attaches the entity a handler mutated, with its state before and after,
+// to the current request's audit entry. Only fields that differ are persisted.
+func RecordAuditChange(c *gin.Context, entityType string, entityID interface{}, before, after interface{}) {
+	c.Set(auditEntityTypeKey, entityType)
+	c.Set(auditEntityIDKey, toAuditString(entityID))
+	c.Set(auditBeforeKey, before)
+	c.Set(auditAfterKey, after)
+}
+
+// requestID returns the incoming X-Request-ID or a newly generated one
+func requestID(c *gin.Context) string {
+	if id := c.GetHeader(RequestIDHeader); id != "" && len(id) <= 64 {
+		return id
+	}
+	id, err := GenerateSecureToken(16)
+	if err != nil {
+		return ""
+	}
+	return id
+}
+
+// shouldPersistAudit decides which requests are stored: every mutation, every admin request
+// and every request made with an impersonation token
+func shouldPersistAudit(c *gin.Context) bool {
+	if c.GetUint("impersonator_id") != 0 {
+		return true
+	}
+	if strings.HasPrefix(c.Request.URL.Path, "/api/v1/admin/") && !strings.HasPrefix(c.Request.URL.Path, "/api/v1/admin/auth/") {
+		return true
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// persistAudit stores the audit entry for a finished request in the background
+func persistAudit(c *gin.Context, reqID string, duration time.Duration) {
+	if database.DB == nil || !shouldPersistAudit(c) {
+		return
+	}
+
+	entry := models.AuditLog{
+		RequestID:  reqID,
+		Method:     c.Request.Method,
+		Route:      c.FullPath(),
+		Path:       c.Request.URL.Path,
+		StatusCode: c.Writer.Status(),
+		IPAddress:  c.ClientIP(),
+		UserAgent:  truncate(c.Request.UserAgent(), 500),
+		DurationMs: duration.Milliseconds(),
+	}
+
+	if actorID := c.GetUint("user_id"); actorID != 0 {
+		entry.ActorID = &actorID
+	}
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(models.User); ok {
+			entry.ActorRole = string(u.Role)
+		}
+	}
+	if impersonatorID := c.GetUint("impersonator_id"); impersonatorID != 0 {
+		entry.ImpersonatorID = &impersonatorID
+	}
+
+	entry.EntityType = c.GetString(auditEntityTypeKey)
+	entry.EntityID = c.GetString(auditEntityIDKey)
+	if entry.EntityType == "" {
+		entry.EntityType, entry.EntityID = entityFromRoute(c)
+	}
+
+	before, _ := c.Get(auditBeforeKey)
+	after, _ := c.Get(auditAfterKey)
+	entry.Before, entry.After = auditDiff(before, after)
+
+	go func() {
+		if err := database.DB.Create(&entry).Error; err != nil {
+			log.Printf("❌ Failed to persist audit log for %s %s: %v", entry.Method, entry.Path, err)
+		}
+	}()
+}
+
+// entityFromRoute infers the entity from the route pattern, e.g. /api/v1/admin/users/:id -> users, <id>
+func entityFromRoute(c *gin.Context) (string, string) {
+	route := strings.TrimPrefix(c.FullPath(), "/api/v1/")
+	route = strings.TrimPrefix(route, "admin/")
+	parts := strings.Split(strings.Trim(route, "/"), "/")
+	if len(parts) == 0 || parts[0] == "" {
+		return "", ""
+	}
+	for _, p := range c.Params {
+		if p.Key == "id" || strings.HasSuffix(p.Key, "Id") || strings.HasSuffix(p.Key, "_id") {
+			return parts[0], p.Value
+		}
+	}
+	return parts[0], ""
+}
+
+// auditDiff reduces before/after to the top-level JSON fields that changed
+func auditDiff(before, after interface{}) (map[string]interface{}, map[string]interface{}) {
+	b := toAuditMap(before)
+	a := toAuditMap(after)
+	if b == nil || a == nil {
+		return b, a
+	}
+
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+	for key, value := range a {
+		if old, ok := b[key]; !ok || !reflect.DeepEqual(old, value) {
+			changedBefore[key] = b[key]
+			changedAfter[key] = value
+		}
+	}
+	for key, value := range b {
+		if _, ok := a[key]; !ok {
+			changedBefore[key] = value
+		}
+	}
+	return changedBefore, changedAfter
+}
+
+// toAuditMap converts any JSON-serializable value into a generic map
+func toAuditMap(v interface{}) map[string]interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil
+	}
+	// Timestamps change on every save and only add noise to the diff
+	delete(m, "updated_at")
+	return m
+}
+
+func toAuditString(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(string(data), `"`)
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
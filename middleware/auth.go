@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 
+	"repair-service-server/apierror"
 	"repair-service-server/config"
 	"repair-service-server/database"
 	"repair-service-server/models"
@@ -32,22 +33,14 @@ func AuthMiddleware() gin.HandlerFunc {
 		
 		if authHeader == "" {
 			log.Printf("🔍 AuthMiddleware: No Authorization header")
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Authorization header required",
-				"message": "Please provide a valid token",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Please provide a valid token"))
 			return
 		}
 
 		// Check if the header starts with "Bearer "
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid token format",
-				"message": "Token must be in format: Bearer <token>",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Token must be in format: Bearer <token>"))
 			return
 		}
 
@@ -70,11 +63,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		if err != nil {
 			log.Printf("🔍 AuthMiddleware: Token parsing error: %v", err)
 			log.Printf("🔍 AuthMiddleware: Token string: %s", tokenString)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid token",
-				"message": "Token is invalid or expired",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Token is invalid or expired"))
 			return
 		}
 
@@ -82,34 +71,31 @@ func AuthMiddleware() gin.HandlerFunc {
 		claims, ok := token.Claims.(*Claims)
 		if !ok || !token.Valid {
 			log.Printf("🔍 AuthMiddleware: Token validation failed - ok: %v, valid: %v", ok, token.Valid)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid token claims",
-				"message": "Token claims are invalid",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Token claims are invalid"))
 			return
 		}
 
 		log.Printf("🔍 AuthMiddleware: Token claims extracted - UserID: %d", claims.UserID)
 
+		if !guardImpersonation(c, claims) {
+			return
+		}
+
 		// Get user from database
 		var user models.User
 		if err := database.DB.First(&user, claims.UserID).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "User not found",
-				"message": "User associated with token not found",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("User associated with token not found"))
 			return
 		}
 
 		// Check if user is active
 		if !user.IsActive {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "User inactive",
-				"message": "User account is deactivated",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("User account is deactivated"))
+			return
+		}
+
+		if SessionRevoked(claims, user) {
+			apierror.Abort(c, apierror.Unauthorized("Session has been signed out"))
 			return
 		}
 
@@ -123,6 +109,38 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// SessionRevoked reports whether the token was signed out: the user has since moved to a new token
+// version, or the session it was issued under has been revoked
+func SessionRevoked(claims *Claims, user models.User) bool {
+	if claims.TokenVersion != user.TokenVersion {
+		return true
+	}
+	if claims.SessionID == 0 {
+		return false
+	}
+	var active int64
+	if err := database.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND user_id = ? AND is_revoked = ?", claims.SessionID, user.ID, false).
+		Count(&active).Error; err != nil {
+		log.Printf("⚠️ Failed to check session %d of user %d: %v", claims.SessionID, user.ID, err)
+		return true
+	}
+	return active == 0
+}
+
+// RequireVerifiedPhone rejects users who have not confirmed their phone number by SMS code.
+// It must run after AuthMiddleware.
+func RequireVerifiedPhone() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := c.Get("user")
+		if u, isUser := user.(models.User); !ok || !isUser || !u.IsPhoneVerified() {
+			apierror.Abort(c, apierror.New(http.StatusForbidden, apierror.CodePhoneUnverified, "Please verify your phone number first"))
+			return
+		}
+		c.Next()
+	}
+}
+
 // OptionalAuthMiddleware is like AuthMiddleware but doesn't require authentication
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -159,7 +177,10 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if user.IsActive {
+		if user.IsActive && !SessionRevoked(claims, user) {
+			if !guardImpersonation(c, claims) {
+				return
+			}
 			c.Set("user", user)
 			c.Set("user_id", user.ID)
 		}
@@ -177,11 +198,7 @@ func WebSocketAuthMiddleware() gin.HandlerFunc {
 		tokenString := c.Query("token")
 		if tokenString == "" {
 			log.Printf("🔌 WebSocketAuthMiddleware: No token in query parameters")
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Token required",
-				"message": "Please provide a valid token in query parameters",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Please provide a valid token in query parameters"))
 			return
 		}
 
@@ -194,11 +211,7 @@ func WebSocketAuthMiddleware() gin.HandlerFunc {
 
 		if err != nil {
 			log.Printf("🔌 WebSocketAuthMiddleware: Token parsing error: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid token",
-				"message": "Token is invalid or expired",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Token is invalid or expired"))
 			return
 		}
 
@@ -206,34 +219,31 @@ func WebSocketAuthMiddleware() gin.HandlerFunc {
 		claims, ok := token.Claims.(*Claims)
 		if !ok || !token.Valid {
 			log.Printf("🔌 WebSocketAuthMiddleware: Token validation failed - ok: %v, valid: %v", ok, token.Valid)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid token claims",
-				"message": "Token claims are invalid",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Token claims are invalid"))
 			return
 		}
 
 		log.Printf("🔌 WebSocketAuthMiddleware: Token claims extracted - UserID: %d", claims.UserID)
 
+		if !guardImpersonation(c, claims) {
+			return
+		}
+
 		// Get user from database
 		var user models.User
 		if err := database.DB.First(&user, claims.UserID).Error; err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "User not found",
-				"message": "User associated with token not found",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("User associated with token not found"))
 			return
 		}
 
 		// Check if user is active
 		if !user.IsActive {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "User inactive",
-				"message": "User account is deactivated",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("User account is deactivated"))
+			return
+		}
+
+		if SessionRevoked(claims, user) {
+			apierror.Abort(c, apierror.Unauthorized("Session has been signed out"))
 			return
 		}
 
@@ -247,3 +257,15 @@ func WebSocketAuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalWebSocketAuthMiddleware authenticates WebSocket connections that pass a token and
+// lets anonymous ones through without a user. An invalid token is still rejected.
+func OptionalWebSocketAuthMiddleware() gin.HandlerFunc {
+	authenticate := WebSocketAuthMiddleware()
+	return func(c *gin.Context) {
+		if c.Query("token") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
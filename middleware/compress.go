@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// minCompressSize is the smallest response worth compressing; below it gzip's framing outweighs
+// the savings
+const minCompressSize = 1024
+
+// gzipWriter holds back the start of a response until it is known to be worth compressing, then
+// sends the rest through gzip
+type gzipWriter struct {
+	gin.ResponseWriter
+	status  int
+	size    int
+	written bool
+	started bool
+	pending bytes.Buffer
+	gz      *gzip.Writer
+}
+
+func (w *gzipWriter) WriteHeader(code int) {
+	if code > 0 && !w.started {
+		w.status = code
+	}
+}
+
+func (w *gzipWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *gzipWriter) Write(b []byte) (int, error) {
+	w.written = true
+	w.size += len(b)
+	if w.started {
+		if w.gz != nil {
+			return w.gz.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+	w.pending.Write(b)
+	if w.pending.Len() >= minCompressSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipWriter) Status() int {
+	return w.status
+}
+
+func (w *gzipWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.size
+}
+
+func (w *gzipWriter) Written() bool {
+	return w.written
+}
+
+// Flush sends what was written so far, so streamed responses such as CSV exports keep streaming
+func (w *gzipWriter) Flush() {
+	if !w.started {
+		w.start(w.pending.Len() > 0)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start sends the status and headers, choosing whether the body is compressed, and then the
+// body written so far
+func (w *gzipWriter) start(compress bool) error {
+	w.started = true
+	header := w.ResponseWriter.Header()
+	if compress && compressible(w.status, header) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.pending.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(w.pending.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.pending.Bytes())
+	}
+	w.pending.Reset()
+	return err
+}
+
+// finish sends a response too small to compress and ends the gzip stream of a compressed one
+func (w *gzipWriter) finish() {
+	if !w.started {
+		w.start(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
+
+// compressible reports whether a response's body benefits from gzip. Media and archives are
+// already compressed.
+func compressible(status int, header http.Header) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	// Event streams are left alone so each event reaches the client as soon as it is flushed
+	for _, prefix := range []string{"image/", "audio/", "video/", "application/zip", "application/gzip", "text/event-stream"} {
+		if strings.HasPrefix(contentType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Compress gzips responses of at least 1KB for clients that accept it. Responses that are
+// already compressed, HEAD requests and WebSocket upgrades are passed through untouched.
+func Compress() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" || !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &gzipWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		defer func() {
+			c.Writer = original
+			writer.finish()
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// conditionalWriter holds back the response so its ETag can be computed before anything is sent
+type conditionalWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *conditionalWriter) WriteHeader(code int) {
+	if code > 0 {
+		w.status = code
+	}
+}
+
+func (w *conditionalWriter) WriteHeaderNow() {}
+
+func (w *conditionalWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *conditionalWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *conditionalWriter) Status() int {
+	return w.status
+}
+
+func (w *conditionalWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *conditionalWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// ConditionalGET answers GET and HEAD requests with 304 Not Modified when the client already has
+// the current response. A 200 response gets a weak ETag hashed from its body unless the handler
+// set one, and If-None-Match is checked against it. If-Modified-Since is only used when the
+// request has no If-None-Match and the handler set Last-Modified.
+func ConditionalGET() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &conditionalWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = original
+
+		header := original.Header()
+		if writer.status == http.StatusOK && !c.IsAborted() {
+			if header.Get("ETag") == "" {
+				sum := sha256.Sum256(writer.body.Bytes())
+				header.Set("ETag", `W/"`+hex.EncodeToString(sum[:16])+`"`)
+			}
+			if header.Get("Cache-Control") == "" {
+				// Clients may keep the response but must revalidate it before each use
+				header.Set("Cache-Control", "private, no-cache")
+			}
+			if requestIsFresh(c.Request, header.Get("ETag"), header.Get("Last-Modified")) {
+				writeNotModified(original)
+				return
+			}
+		}
+
+		original.WriteHeader(writer.status)
+		if c.Request.Method != http.MethodHead {
+			original.Write(writer.body.Bytes())
+		}
+	}
+}
+
+// NotModified lets a handler answer a conditional request before loading its response, from a
+// validator it can compute cheaply. It sets the ETag, and Last-Modified when not zero, and
+// returns true after answering 304 when the client's copy is current. Otherwise the handler
+// carries on and the headers are sent with its response.
+func NotModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	header := c.Writer.Header()
+	header.Set("ETag", etag)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", "private, no-cache")
+	}
+	if !requestIsFresh(c.Request, etag, header.Get("Last-Modified")) {
+		return false
+	}
+	c.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// WeakETag builds a weak ETag from the parts that identify one version of a response
+func WeakETag(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// requestIsFresh reports whether the client's cached copy matches etag or lastModified
+func requestIsFresh(r *http.Request, etag, lastModified string) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		return etagMatches(match, etag)
+	}
+	since := r.Header.Get("If-Modified-Since")
+	if since == "" || lastModified == "" {
+		return false
+	}
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(sinceTime)
+}
+
+// etagMatches compares an If-None-Match list with etag using the weak comparison
+func etagMatches(list, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified sends a 304 without the body and the headers that describe it
+func writeNotModified(w gin.ResponseWriter) {
+	header := w.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	w.WriteHeaderNow()
+}
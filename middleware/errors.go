@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+)
+
+// ErrorHandler renders errors that handlers attached with c.Error without writing a response,
+// so every failure reaches the client in the apierror envelope
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+		apierror.Render(c, apierror.From(c.Errors.Last().Err))
+	}
+}
@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// IdempotencyKeyHeader is the client-chosen key that identifies retries of the same request
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotencyTTL is how long a stored response is replayed for its key
+const IdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLength matches the idempotency_keys.key column
+const maxIdempotencyKeyLength = 255
+
+// idempotencyWriter captures the response body alongside writing it to the client
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency stores the response to an authenticated request carrying an Idempotency-Key
+// header and replays it for retries with the same key. A retry while the first request is still
+// running gets 409, and reusing a key for a different request gets 422. Server errors are not
+// stored so the client can retry them. Must run after the authentication middleware.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		userID := c.GetUint("user_id")
+		if key == "" || userID == 0 || database.DB == nil {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			apierror.Abort(c, apierror.Validation("Idempotency-Key must be at most 255 characters"))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation("Failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+		record := models.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			RequestHash: hex.EncodeToString(hash.Sum(nil)),
+			ExpiresAt:   time.Now().Add(IdempotencyTTL),
+		}
+
+		// Claim the key; an expired claim is removed first so the key can be reused
+		database.DB.Where("user_id = ? AND key = ? AND expires_at < ?", userID, key, time.Now()).Delete(&models.IdempotencyKey{})
+		result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+		if result.Error != nil {
+			// Without the store the request still runs, just without replay protection
+			log.Printf("⚠️ Failed to store idempotency key for user %d: %v", userID, result.Error)
+			c.Next()
+			return
+		}
+		if result.RowsAffected == 0 {
+			replayIdempotentResponse(c, userID, key, record.RequestHash)
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		completed := false
+		defer func() {
+			// A panicking or failed handler releases the key so the retry runs again
+			if !completed {
+				database.DB.Delete(&models.IdempotencyKey{}, record.ID)
+			}
+		}()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		now := time.Now()
+		if err := database.DB.Model(&record).Updates(map[string]interface{}{
+			"status_code":  status,
+			"content_type": c.Writer.Header().Get("Content-Type"),
+			"response":     writer.body.Bytes(),
+			"completed_at": now,
+		}).Error; err != nil {
+			log.Printf("⚠️ Failed to save idempotent response for key %s: %v", key, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// replayIdempotentResponse answers a request whose key was already claimed
+func replayIdempotentResponse(c *gin.Context, userID uint, key, requestHash string) {
+	var existing models.IdempotencyKey
+	if err := database.DB.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error; err != nil {
+		// The claim was released between our insert and this read; ask the client to retry
+		c.Header("Retry-After", "1")
+		apierror.Abort(c, apierror.Conflict("A request with this Idempotency-Key is in progress"))
+		return
+	}
+
+	if existing.RequestHash != requestHash {
+		apierror.Abort(c, apierror.Unprocessable("Idempotency-Key was already used for a different request"))
+		return
+	}
+
+	if existing.CompletedAt == nil {
+		c.Header("Retry-After", "1")
+		apierror.Abort(c, apierror.Conflict("A request with this Idempotency-Key is in progress"))
+		return
+	}
+
+	log.Printf("🔁 Replaying response for Idempotency-Key %s (user %d)", key, userID)
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(existing.StatusCode, existing.ContentType, existing.Response)
+	c.Abort()
+}
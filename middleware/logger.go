@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
 )
 
 // Logger returns a gin.HandlerFunc for logging requests
@@ -24,7 +26,9 @@ func Logger() gin.HandlerFunc {
 	})
 }
 
-// Recovery returns a gin.HandlerFunc for panic recovery
+// Recovery returns a gin.HandlerFunc for panic recovery that answers in the apierror envelope
 func Recovery() gin.HandlerFunc {
-	return gin.Recovery()
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		apierror.Abort(c, apierror.Internal("Internal server error", fmt.Errorf("panic: %v", recovered)))
+	})
 }
\ No newline at end of file
@@ -0,0 +1,286 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"repair-service-server/apierror"
+	"repair-service-server/cache"
+	"repair-service-server/config"
+)
+
+// RateLimitBudget is a sliding-window allowance of Limit requests per Window
+type RateLimitBudget struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+	// PerRoute gives every route its own allowance instead of sharing one across the budget
+	PerRoute bool
+}
+
+// Rate limit budgets, counted per user when authenticated and per IP otherwise
+var (
+	BudgetDefault       = RateLimitBudget{Name: "default", Limit: 20, Window: time.Minute, PerRoute: true}
+	BudgetAuth          = RateLimitBudget{Name: "auth", Limit: 10, Window: time.Minute}
+	BudgetOTP           = RateLimitBudget{Name: "otp", Limit: 5, Window: 10 * time.Minute}
+	BudgetChatSend      = RateLimitBudget{Name: "chat_send", Limit: 30, Window: time.Minute}
+	BudgetAIChat        = RateLimitBudget{Name: "ai_chat", Limit: 10, Window: time.Minute}
+	BudgetAIChatDaily   = RateLimitBudget{Name: "ai_chat_daily", Limit: 200, Window: 24 * time.Hour}
+	BudgetRequestCreate = RateLimitBudget{Name: "request_create", Limit: 5, Window: 10 * time.Minute}
+	BudgetWebSocket     = RateLimitBudget{Name: "websocket", Limit: 60, Window: time.Minute, PerRoute: true}
+	BudgetWorkerRead    = RateLimitBudget{Name: "worker_read", Limit: 60, Window: time.Minute}
+	BudgetLocation      = RateLimitBudget{Name: "location", Limit: 30, Window: time.Minute}
+)
+
+// RateLimitResult is the outcome of counting one request against a budget
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the oldest counted request leaves the window
+	Reset time.Duration
+}
+
+// budgetForRoute picks the budget for a request from its method and route pattern
+func budgetForRoute(method, path string) RateLimitBudget {
+	switch {
+	case method == http.MethodPost && (path == "/api/v1/auth/otp/send" || path == "/api/v1/auth/phone/send-code" || path == "/api/v1/auth/phone/change"):
+		return BudgetOTP
+	case method == http.MethodPost && (strings.HasPrefix(path, "/api/v1/auth") || strings.HasPrefix(path, "/api/v1/admin/auth")):
+		return BudgetAuth
+	case method == http.MethodPost && (path == "/api/v1/chat/rooms/:id/messages" || path == "/api/v1/chat/rooms/:id/voice-messages"):
+		return BudgetChatSend
+	case method == http.MethodPost && (path == "/api/v1/service-requests/" || path == "/api/v1/service-requests/urgent" || path == "/api/v1/service-requests/scheduled"):
+		return BudgetRequestCreate
+	case strings.HasPrefix(path, "/api/v1/chat/ws") || strings.HasPrefix(path, "/api/v1/ws/"):
+		return BudgetWebSocket
+	case method == http.MethodGet && strings.HasPrefix(path, "/api/v1/worker"):
+		return BudgetWorkerRead
+	case strings.HasPrefix(path, "/api/v1/location"):
+		return BudgetLocation
+	}
+	return BudgetDefault
+}
+
+// RateLimitMiddleware enforces the route's budget and reports it in RateLimit-* headers
+func RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+		if path == "" {
+			// Unmatched routes 404 without reaching a handler
+			c.Next()
+			return
+		}
+
+		budget := budgetForRoute(c.Request.Method, path)
+		identity := RateLimitIdentity(c)
+		result := AllowRequest(budget, identity, path)
+
+		setRateLimitHeaders(c, budget, result)
+		if !result.Allowed {
+			log.Printf("🚫 Rate limit %s exceeded for %s %s by %s", budget.Name, c.Request.Method, path, identity)
+			AbortRateLimited(c, result)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitIdentity keys limits on the authenticated user, falling back to the client IP.
+// The limiter runs before AuthMiddleware, so a valid bearer token is read here directly.
+func RateLimitIdentity(c *gin.Context) string {
+	if userID := c.GetUint("user_id"); userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+
+	if tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "); tokenString != "" && tokenString != c.GetHeader("Authorization") {
+		claims := &Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			return []byte(config.AppConfig.JWT.Secret), nil
+		})
+		if err == nil && token.Valid && claims.UserID != 0 {
+			return fmt.Sprintf("user:%d", claims.UserID)
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+// AllowRequest counts one request by identity against budget; route is only used for per-route budgets.
+// Redis is used when the cache runs on it so limits hold across instances; if Redis fails the
+// request is counted in memory instead of being rejected.
+func AllowRequest(budget RateLimitBudget, identity, route string) RateLimitResult {
+	budget.Limit = config.Current().RateLimit(budget.Name, budget.Limit)
+	key := "ratelimit:" + budget.Name + ":" + identity
+	if budget.PerRoute {
+		key += ":" + route
+	}
+
+	if redis, ok := cache.Default().(*cache.RedisStore); ok {
+		result, err := allowRedis(redis, key, budget)
+		if err == nil {
+			return result
+		}
+		log.Printf("⚠️ Redis rate limiter failed, counting in memory: %v", err)
+	}
+	return memoryLimiter.allow(key, budget)
+}
+
+// AllowAIPrompt counts one prompt to the AI provider against both the per-minute and daily AI
+// budgets of identity, returning the first budget that refused it
+func AllowAIPrompt(identity string) RateLimitResult {
+	if result := AllowRequest(BudgetAIChatDaily, identity, ""); !result.Allowed {
+		return result
+	}
+	return AllowRequest(BudgetAIChat, identity, "")
+}
+
+// AbortRateLimited rejects a request refused by a budget checked inside a handler
+func AbortRateLimited(c *gin.Context, result RateLimitResult) {
+	retryAfter := ceilSeconds(result.Reset)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "Too many requests. Please try again later.").WithDetails(gin.H{"retry_after": retryAfter}))
+}
+
+func setRateLimitHeaders(c *gin.Context, budget RateLimitBudget, result RateLimitResult) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.Limit, ceilSeconds(budget.Window)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// slidingWindowScript keeps one sorted-set entry per counted request, scored by its time in ms.
+// Returns {allowed, count, ms until the oldest entry expires}.
+const slidingWindowScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now, ARGV[4])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+local reset = window
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, count, reset}
+`
+
+var rateLimitSeq atomic.Uint64
+
+func allowRedis(redis *cache.RedisStore, key string, budget RateLimitBudget) (RateLimitResult, error) {
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixNano(), rateLimitSeq.Add(1))
+
+	reply, err := redis.Do("EVAL", slidingWindowScript, 1, key,
+		now.UnixMilli(),
+		budget.Window.Milliseconds(),
+		budget.Limit,
+		member,
+	)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	count, _ := values[1].(int64)
+	resetMs, _ := values[2].(int64)
+
+	return RateLimitResult{
+		Allowed:   allowed == 1,
+		Limit:     budget.Limit,
+		Remaining: max(budget.Limit-int(count), 0),
+		Reset:     time.Duration(resetMs) * time.Millisecond,
+	}, nil
+}
+
+// windowLimiter is the in-process sliding-window log used without Redis
+type windowLimiter struct {
+	mu        sync.Mutex
+	logs      map[string]*windowLog
+	lastSweep time.Time
+}
+
+// windowLog is one key's counted requests, kept for the window of the budget they count against
+type windowLog struct {
+	hits   []time.Time
+	window time.Duration
+}
+
+var memoryLimiter = &windowLimiter{logs: make(map[string]*windowLog), lastSweep: time.Now()}
+
+func (l *windowLimiter) allow(key string, budget RateLimitBudget) RateLimitResult {
+	now := time.Now()
+	cutoff := now.Add(-budget.Window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.logs[key]
+	if !ok {
+		entry = &windowLog{}
+		l.logs[key] = entry
+	}
+	entry.window = budget.Window
+
+	hits := entry.hits
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	hits = hits[i:]
+
+	allowed := len(hits) < budget.Limit
+	if allowed {
+		hits = append(hits, now)
+	}
+	entry.hits = hits
+
+	reset := budget.Window
+	if len(hits) > 0 {
+		reset = hits[0].Add(budget.Window).Sub(now)
+	}
+
+	// Drop keys whose last request has left their budget's window, so the map does not grow with
+	// every client ever seen while daily budgets keep their count
+	if now.Sub(l.lastSweep) > time.Minute {
+		l.lastSweep = now
+		for k, e := range l.logs {
+			if len(e.hits) == 0 || now.Sub(e.hits[len(e.hits)-1]) > e.window {
+				delete(l.logs, k)
+			}
+		}
+	}
+
+	return RateLimitResult{
+		Allowed:   allowed,
+		Limit:     budget.Limit,
+		Remaining: budget.Limit - len(hits),
+		Reset:     reset,
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowLimiterKeepsDailyBudgets(t *testing.T) {
+	limiter := &windowLimiter{logs: make(map[string]*windowLog), lastSweep: time.Now()}
+	daily := RateLimitBudget{Name: "daily", Limit: 2, Window: 24 * time.Hour}
+	minute := RateLimitBudget{Name: "minute", Limit: 2, Window: time.Minute}
+
+	// Both keys last counted a request two hours ago
+	twoHoursAgo := time.Now().Add(-2 * time.Hour)
+	limiter.logs["daily"] = &windowLog{hits: []time.Time{twoHoursAgo, twoHoursAgo}, window: daily.Window}
+	limiter.logs["minute"] = &windowLog{hits: []time.Time{twoHoursAgo}, window: minute.Window}
+	limiter.lastSweep = time.Now().Add(-2 * time.Minute)
+
+	if result := limiter.allow("other", minute); !result.Allowed {
+		t.Fatal("first request of a new key was refused")
+	}
+	if _, ok := limiter.logs["minute"]; ok {
+		t.Error("idle per-minute key survived the sweep")
+	}
+	if result := limiter.allow("daily", daily); result.Allowed {
+		t.Error("daily budget was reset by the sweep, want the two requests of two hours ago still counted")
+	}
+}
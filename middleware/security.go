@@ -1,135 +1,17 @@
 package middleware
 
 import (
+	"repair-service-server/apierror"
 	"crypto/rand"
 	"encoding/hex"
 	"log"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/time/rate"
 )
 
-// RateLimiter stores rate limiters for different IPs
-type RateLimiter struct {
-	limiters map[string]*rate.Limiter
-	lastSeen map[string]time.Time
-	mutex    sync.RWMutex
-}
-
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter() *RateLimiter {
-	return &RateLimiter{
-		limiters: make(map[string]*rate.Limiter),
-		lastSeen: make(map[string]time.Time),
-	}
-}
-
-// GetLimiter returns a rate limiter for the given IP
-func (rl *RateLimiter) GetLimiter(ip string) *rate.Limiter {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
-
-	limiter, exists := rl.limiters[ip]
-	if !exists {
-		// Allow 10 requests per minute, burst of 20
-		limiter = rate.NewLimiter(rate.Every(time.Minute/10), 20)
-		rl.limiters[ip] = limiter
-	}
-	// Update last seen time for this IP
-	rl.lastSeen[ip] = time.Now()
-
-	return limiter
-}
-
-// Cleanup removes old limiters to prevent memory leaks
-func (rl *RateLimiter) Cleanup() {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
-
-	// Remove limiters that have been idle for more than 1 hour
-	now := time.Now()
-	for ip, t := range rl.lastSeen {
-		if now.Sub(t) > time.Hour {
-			delete(rl.limiters, ip)
-			delete(rl.lastSeen, ip)
-		}
-	}
-}
-
-var globalRateLimiter = NewRateLimiter()
-
-// RateLimitMiddleware implements rate limiting
-func RateLimitMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		path := c.FullPath()
-		clientIP := c.ClientIP()
-		key := path + "|" + clientIP
-
-		// Relax limits for WebSocket endpoint and worker reads to avoid 429
-		var lim rate.Limit
-		var burst int
-		if strings.HasPrefix(path, "/api/v1/chat/ws") {
-			// WebSocket upgrade - allow higher burst
-			lim = rate.Every(time.Second) // ~1 req/sec
-			burst = 5
-		} else if c.Request.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/worker") {
-			// Worker polling/reads
-			lim = rate.Every(time.Second)
-			burst = 3
-		} else if strings.HasPrefix(path, "/api/v1/location") {
-			// Location updates can be frequent - moderate limits
-			lim = rate.Every(2 * time.Second)
-			burst = 2
-		} else {
-			// Default limits
-			lim = rate.Every(time.Minute / 10) // 10 req/min
-			burst = 20
-		}
-
-		limiter := globalRateLimiter.GetLimiterWithConfig(key, lim, burst)
-
-		if !limiter.Allow() {
-			log.Printf("🚫 Rate limit exceeded for %s %s from %s", c.Request.Method, path, clientIP)
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "Rate limit exceeded",
-				"message": "Too many requests. Please try again later.",
-				"retry_after": 60,
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
-// AuthRateLimitMiddleware implements stricter rate limiting for auth endpoints
-func AuthRateLimitMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		clientIP := c.ClientIP()
-		
-		// Create a stricter limiter for auth endpoints
-		limiter := rate.NewLimiter(rate.Every(time.Minute/5), 5) // 5 requests per minute, burst of 5
-
-		if !limiter.Allow() {
-			log.Printf("🚫 Auth rate limit exceeded for IP: %s", clientIP)
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "Authentication rate limit exceeded",
-				"message": "Too many authentication attempts. Please try again later.",
-				"retry_after": 300,
-			})
-			c.Abort()
-			return
-		}
-
-		c.Next()
-	}
-}
-
 // SecurityHeadersMiddleware adds security headers
 func SecurityHeadersMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -179,8 +61,8 @@ func CORSMiddleware() gin.HandlerFunc {
 		}
 		
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, HEAD, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Length, Content-Type, Authorization, Accept, User-Agent, X-Requested-With")
-		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Type")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Length, Content-Type, Authorization, Accept, User-Agent, X-Requested-With, X-Request-ID")
+		c.Header("Access-Control-Expose-Headers", "Content-Length, Content-Type, Content-Disposition, X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Header("Access-Control-Max-Age", "86400")
 		
@@ -199,11 +81,7 @@ func InputValidationMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Validate request size
 		if c.Request.ContentLength > 10*1024*1024 { // 10MB limit
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
-				"error":   "Request too large",
-				"message": "Request body exceeds maximum size limit",
-			})
-			c.Abort()
+			apierror.Abort(c, apierror.New(http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Request body exceeds maximum size limit"))
 			return
 		}
 		
@@ -213,11 +91,7 @@ func InputValidationMiddleware() gin.HandlerFunc {
 			if !strings.Contains(contentType, "application/json") && 
 			   !strings.Contains(contentType, "multipart/form-data") &&
 			   !strings.Contains(contentType, "application/x-www-form-urlencoded") {
-				c.JSON(http.StatusUnsupportedMediaType, gin.H{
-					"error":   "Invalid content type",
-					"message": "Content-Type must be application/json, multipart/form-data, or application/x-www-form-urlencoded",
-				})
-				c.Abort()
+				apierror.Abort(c, apierror.New(http.StatusUnsupportedMediaType, apierror.CodeUnsupportedType, "Content-Type must be application/json, multipart/form-data, or application/x-www-form-urlencoded"))
 				return
 			}
 		}
@@ -226,10 +100,13 @@ func InputValidationMiddleware() gin.HandlerFunc {
 	}
 }
 
-// AuditLogMiddleware logs security events
+// AuditLogMiddleware logs security events and persists audit entries for mutations, admin and impersonated requests
 func AuditLogMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
+		reqID := requestID(c)
+		c.Set("request_id", reqID)
+		c.Header(RequestIDHeader, reqID)
 		
 		// Log the request
 		log.Printf("🔍 AUDIT: %s %s from %s", c.Request.Method, c.Request.URL.Path, c.ClientIP())
@@ -245,6 +122,8 @@ func AuditLogMiddleware() gin.HandlerFunc {
 		} else {
 			log.Printf("✅ AUDIT: %s %s returned %d in %v", c.Request.Method, c.Request.URL.Path, status, duration)
 		}
+
+		persistAudit(c, reqID, duration)
 	}
 }
 
@@ -347,17 +226,3 @@ func ValidatePasswordStrength(password string) (bool, []string) {
 	
 	return len(errors) == 0, errors
 }
-
-// GetLimiterWithConfig returns a limiter for a composite key with dynamic limits
-func (rl *RateLimiter) GetLimiterWithConfig(key string, limit rate.Limit, burst int) *rate.Limiter {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
-
-	limiter, exists := rl.limiters[key]
-	if !exists {
-		limiter = rate.NewLimiter(limit, burst)
-		rl.limiters[key] = limiter
-	}
-	rl.lastSeen[key] = time.Now()
-	return limiter
-}
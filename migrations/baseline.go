@@ -0,0 +1,59 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlCapture is a gorm logger that records every statement instead of printing it
+type sqlCapture struct {
+	logger.Interface
+	statements []string
+}
+
+func (l *sqlCapture) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	l.statements = append(l.statements, sql)
+}
+
+// GenerateBaseline renders the CREATE statements for the given models without touching a
+// database, returning the up and down SQL of a baseline migration.
+func GenerateBaseline(values []interface{}) (string, string, error) {
+	capture := &sqlCapture{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               capture,
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open dry-run session: %w", err)
+	}
+
+	if err := db.Migrator().CreateTable(values...); err != nil {
+		return "", "", fmt.Errorf("failed to render schema: %w", err)
+	}
+
+	var up strings.Builder
+	up.WriteString("-- Baseline schema generated from the gorm models by `server migrate baseline`\n\n")
+	for _, stmt := range capture.statements {
+		up.WriteString(stmt)
+		up.WriteString(";\n\n")
+	}
+
+	var down strings.Builder
+	for i := len(values) - 1; i >= 0; i-- {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(values[i]); err != nil {
+			return "", "", err
+		}
+		fmt.Fprintf(&down, "DROP TABLE IF EXISTS %q CASCADE;\n", stmt.Schema.Table)
+	}
+
+	return up.String(), down.String(), nil
+}
@@ -0,0 +1,153 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Usage describes the migrate subcommand
+const Usage = `usage: server migrate <command>
+
+commands:
+  up               apply all pending migrations
+  down [steps]     revert the last applied migration, or the last <steps>
+  status           list migrations and whether they are applied
+  force <version>  record <version> as applied after fixing a failed migration by hand
+  create <name>    write an empty up/down migration pair to ` + Dir + `
+  baseline         regenerate the baseline migration from the gorm models`
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// NeedsDatabase reports whether the subcommand connects to the database
+func NeedsDatabase(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "up", "down", "status", "force":
+		return true
+	}
+	return false
+}
+
+// RunCLI executes a migrate subcommand; db may be nil when NeedsDatabase is false
+func RunCLI(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New(Usage)
+	}
+
+	switch args[0] {
+	case "up":
+		m, err := New(db)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+		applied, err := m.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migration(s) applied\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		m, err := New(db)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+		reverted, err := m.Down(steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migration(s) reverted\n", reverted)
+
+	case "status":
+		m, err := New(db)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Dirty {
+				state = "dirty"
+			} else if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, state)
+		}
+
+	case "force":
+		if len(args) < 2 {
+			return errors.New("usage: server migrate force <version>")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 1 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		m, err := New(db)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+		if err := m.Force(version); err != nil {
+			return err
+		}
+		fmt.Printf("version forced to %d\n", version)
+
+	case "create":
+		if len(args) < 2 || !migrationName.MatchString(args[1]) {
+			return errors.New("usage: server migrate create <name> (lowercase letters, digits and underscores)")
+		}
+		version, err := NextVersion()
+		if err != nil {
+			return err
+		}
+		up := fmt.Sprintf("-- %s\n", args[1])
+		return writeMigration(version, args[1], up, "")
+
+	case "baseline":
+		up, down, err := GenerateBaseline(Models())
+		if err != nil {
+			return err
+		}
+		return writeMigration(baselineVersion, "baseline", up, down)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", args[0], Usage)
+	}
+	return nil
+}
+
+// writeMigration writes an up/down pair into Dir; the binary must be rebuilt to embed it
+func writeMigration(version int, name, up, down string) error {
+	if err := os.MkdirAll(Dir, 0o755); err != nil {
+		return err
+	}
+	for direction, content := range map[string]string{"up": up, "down": down} {
+		file := filepath.Join(Dir, fmt.Sprintf("%04d_%s.%s.sql", version, name, direction))
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("wrote", file)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/duration"
+	"repair-service-server/migrations"
+	"repair-service-server/testdb"
+)
+
+// durationMigration is the migration turning estimated durations from text into minutes
+const durationMigration = 58
+
+// legacyDurations are estimated durations as customers typed them before durationMigration
+var legacyDurations = []string{
+	"90", " 90\t\n", "45 min", "45min", "45 Minutes", "2 hours", "2 HEURES", "1.5 h", "3 jours",
+	"1 d", "1h30m", "0h5m", "1.5", "2.5", "2.49", "0.5", "0.01 h", "0.001 h", "2147483647",
+	"2147483647.4", "2147483647.5", "1491308 jours", "1491309 jours", "99999999999999999999999 min",
+	"0", "", "environ 2 heures", "2 semaines", "-5", "+5", ".5", "1e3", "1h 30m", "90s",
+	"1.5h30m", "２ h",
+}
+
+// migrateTo applies the migrations before version to an empty database and returns the one at it
+func migrateTo(t *testing.T, db *gorm.DB, version int) migrations.Migration {
+	t.Helper()
+	all, err := migrations.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range all {
+		if m.Version == version {
+			return m
+		}
+		if err := db.Exec(m.Up).Error; err != nil {
+			t.Fatalf("migration %d_%s: %v", m.Version, m.Name, err)
+		}
+	}
+	t.Fatalf("no migration %d", version)
+	return migrations.Migration{}
+}
+
+func TestEstimatedDurationMigrationMatchesParse(t *testing.T) {
+	db := testdb.OpenEmpty(t)
+	migration := migrateTo(t, db, durationMigration)
+
+	var customerID, categoryID uint
+	if err := db.Raw(`INSERT INTO "users" ("full_name", "phone_number", "password_hash") VALUES ('Legacy Customer', '+22220000000', 'unused') RETURNING "id"`).Scan(&customerID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Raw(`INSERT INTO "service_categories" ("name") VALUES ('Legacy') RETURNING "id"`).Scan(&categoryID).Error; err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[uint]string, len(legacyDurations))
+	for _, text := range legacyDurations {
+		var id uint
+		if err := db.Raw(`INSERT INTO "customer_service_requests" ("customer_id", "category_id", "title", "priority", "location_address", "location_city", "estimated_duration")
+			VALUES (?, ?, 'Legacy request', 'normal', 'Tevragh Zeina', 'Nouakchott', ?) RETURNING "id"`,
+			customerID, categoryID, text).Scan(&id).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[id] = text
+	}
+
+	if err := db.Exec(migration.Up).Error; err != nil {
+		t.Fatalf("migrating up: %v", err)
+	}
+	type row struct {
+		ID                uint
+		EstimatedDuration *int
+	}
+	var rows []row
+	if err := db.Raw(`SELECT "id", "estimated_duration" FROM "customer_service_requests"`).Scan(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(legacyDurations) {
+		t.Fatalf("got %d requests after migrating, want %d", len(rows), len(legacyDurations))
+	}
+	for _, r := range rows {
+		text := ids[r.ID]
+		want, ok := duration.Parse(text)
+		switch {
+		case !ok && r.EstimatedDuration != nil:
+			t.Errorf("%q migrated to %d, want NULL as Parse rejects it", text, *r.EstimatedDuration)
+		case ok && (r.EstimatedDuration == nil || *r.EstimatedDuration != int(want)):
+			t.Errorf("%q migrated to %v, want %d as Parse reads it", text, r.EstimatedDuration, want)
+		}
+	}
+
+	var leftover int64
+	if err := db.Raw(`SELECT count(*) FROM pg_proc WHERE proname = 'legacy_duration_minutes'`).Scan(&leftover).Error; err != nil {
+		t.Fatal(err)
+	}
+	if leftover != 0 {
+		t.Error("the migration left its conversion function behind")
+	}
+
+	// Reverting writes minutes back as text that reads the same
+	if err := db.Exec(migration.Down).Error; err != nil {
+		t.Fatalf("migrating down: %v", err)
+	}
+	type textRow struct {
+		ID                uint
+		EstimatedDuration *string
+	}
+	var reverted []textRow
+	if err := db.Raw(`SELECT "id", "estimated_duration" FROM "customer_service_requests"`).Scan(&reverted).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range reverted {
+		want, ok := duration.Parse(ids[r.ID])
+		if !ok {
+			if r.EstimatedDuration != nil {
+				t.Errorf("%q reverted to %q, want NULL", ids[r.ID], *r.EstimatedDuration)
+			}
+			continue
+		}
+		if r.EstimatedDuration == nil {
+			t.Errorf("%q reverted to NULL, want %d minutes", ids[r.ID], want)
+			continue
+		}
+		if got, _ := duration.Parse(*r.EstimatedDuration); got != want {
+			t.Errorf("%q reverted to %q, which reads as %d minutes, want %d", ids[r.ID], *r.EstimatedDuration, got, want)
+		}
+	}
+}
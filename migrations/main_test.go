@@ -0,0 +1,12 @@
+package migrations_test
+
+import (
+	"os"
+	"testing"
+
+	"repair-service-server/testdb"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(testdb.Main(m))
+}
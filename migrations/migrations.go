@@ -0,0 +1,346 @@
+// Package migrations applies the versioned SQL migrations in migrations/sql to the database
+// with golang-migrate.
+//
+// Each migration is a pair of files named <version>_<name>.up.sql and <version>_<name>.down.sql.
+// The current version is recorded in the schema_migrations table.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// Dir is the migrations directory relative to the repository root, used by the CLI to write files
+const Dir = "migrations/sql"
+
+// baselineVersion is the migration holding the schema that AutoMigrate used to create
+const baselineVersion = 1
+
+// versionTable is where golang-migrate records the current version
+const versionTable = "schema_migrations"
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration has been applied. Dirty marks the current version when
+// it failed part way and has to be fixed by hand, then forced.
+type Status struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+	Dirty   bool   `json:"dirty"`
+}
+
+// Load reads the embedded migrations, sorted by version
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(sqlFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		version, name, direction, err := parseFilename(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		content, err := sqlFiles.ReadFile(path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// parseFilename splits 0001_name.up.sql into its version, name and direction
+func parseFilename(filename string) (int, string, string, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+	direction := path.Ext(base)
+	if direction != ".up" && direction != ".down" {
+		return 0, "", "", fmt.Errorf("migration %s must end in .up.sql or .down.sql", filename)
+	}
+	base = strings.TrimSuffix(base, direction)
+
+	versionStr, name, ok := strings.Cut(base, "_")
+	if !ok {
+		return 0, "", "", fmt.Errorf("migration %s must be named <version>_<name>", filename)
+	}
+	version, err := strconv.Atoi(versionStr)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("migration %s has an invalid version", filename)
+	}
+	return version, name, strings.TrimPrefix(direction, "."), nil
+}
+
+// Migrator applies and reverts migrations against a database
+type Migrator struct {
+	db         *gorm.DB
+	migrate    *migrate.Migrate
+	migrations []Migration
+}
+
+// New creates a migrator for the embedded migrations. It holds its own connection to the
+// database of db, released by Close.
+func New(db *gorm.DB) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := adoptLegacyVersions(db); err != nil {
+		return nil, fmt.Errorf("failed to convert schema_migrations: %w", err)
+	}
+
+	dialector, ok := db.Dialector.(*postgres.Dialector)
+	if !ok || dialector.DSN == "" {
+		return nil, errors.New("migrations need a PostgreSQL connection string")
+	}
+	conn, err := sql.Open("pgx", dialector.DSN)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := pgxmigrate.WithInstance(conn, &pgxmigrate.Config{MigrationsTable: versionTable})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	source, err := iofs.New(sqlFiles, "sql")
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+	runner, err := migrate.NewWithInstance("iofs", source, "pgx", driver)
+	if err != nil {
+		source.Close()
+		driver.Close()
+		return nil, err
+	}
+	return &Migrator{db: db, migrate: runner, migrations: migrations}, nil
+}
+
+// Close releases the migrator's connection
+func (m *Migrator) Close() error {
+	sourceErr, databaseErr := m.migrate.Close()
+	return errors.Join(sourceErr, databaseErr)
+}
+
+// Up applies every pending migration in order and returns how many ran
+func (m *Migrator) Up() (int, error) {
+	if err := m.adoptExistingSchema(); err != nil {
+		return 0, err
+	}
+
+	before, err := m.version()
+	if err != nil {
+		return 0, err
+	}
+	upErr := m.migrate.Up()
+	if errors.Is(upErr, migrate.ErrNoChange) {
+		upErr = nil
+	}
+	after, err := m.version()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, migration := range m.migrations {
+		if migration.Version > before && migration.Version <= after {
+			applied++
+			log.Printf("✅ Applied migration %d_%s", migration.Version, migration.Name)
+		}
+	}
+	if upErr != nil {
+		return applied, fmt.Errorf("migration failed: %w", upErr)
+	}
+	return applied, nil
+}
+
+// Down reverts the most recent steps applied migrations and returns how many were reverted
+func (m *Migrator) Down(steps int) (int, error) {
+	current, err := m.version()
+	if err != nil {
+		return 0, err
+	}
+
+	// Every migration being reverted needs a down script, checked before any is run
+	var reverting []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(reverting) < steps; i-- {
+		migration := m.migrations[i]
+		if migration.Version > current {
+			continue
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return 0, fmt.Errorf("migration %d_%s is irreversible", migration.Version, migration.Name)
+		}
+		reverting = append(reverting, migration)
+	}
+	if len(reverting) == 0 {
+		return 0, nil
+	}
+
+	if err := m.migrate.Steps(-len(reverting)); err != nil {
+		return 0, err
+	}
+	for _, migration := range reverting {
+		log.Printf("↩️ Reverted migration %d_%s", migration.Version, migration.Name)
+	}
+	return len(reverting), nil
+}
+
+// Force records version as the current one and clears the dirty flag, once a failed migration
+// has been fixed by hand
+func (m *Migrator) Force(version int) error {
+	return m.migrate.Force(version)
+}
+
+// Status lists every known migration and whether it is applied
+func (m *Migrator) Status() ([]Status, error) {
+	current, dirty, err := m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		current, dirty, err = 0, false, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		failed := dirty && migration.Version == int(current)
+		statuses = append(statuses, Status{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Version <= int(current) && !failed,
+			Dirty:   failed,
+		})
+	}
+	return statuses, nil
+}
+
+// version returns the current version, 0 when no migration has been applied
+func (m *Migrator) version() (int, error) {
+	version, _, err := m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, nil
+	}
+	return int(version), err
+}
+
+// adoptExistingSchema marks the baseline as applied on databases that were created by
+// AutoMigrate before versioned migrations existed, so it is not run against existing tables.
+func (m *Migrator) adoptExistingSchema() error {
+	current, err := m.version()
+	if err != nil {
+		return err
+	}
+	if current > 0 || !m.db.Migrator().HasTable("users") {
+		return nil
+	}
+
+	baseline, ok := m.find(baselineVersion)
+	if !ok {
+		return nil
+	}
+	log.Printf("📋 Existing schema found, marking baseline migration %d_%s as applied", baseline.Version, baseline.Name)
+	return m.migrate.Force(baseline.Version)
+}
+
+// adoptLegacyVersions rewrites the schema_migrations table of the in-house runner used before
+// golang-migrate, which listed every applied version, into golang-migrate's single current version
+func adoptLegacyVersions(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if !tx.Migrator().HasTable(versionTable) {
+			return nil
+		}
+		// Instances booting together queue here, and the ones after the first find the table converted
+		if err := tx.Exec(fmt.Sprintf("LOCK TABLE %q IN ACCESS EXCLUSIVE MODE", versionTable)).Error; err != nil {
+			return err
+		}
+		if !tx.Migrator().HasColumn(versionTable, "applied_at") {
+			return nil
+		}
+
+		var latest int
+		if err := tx.Raw(fmt.Sprintf("SELECT COALESCE(MAX(version), 0) FROM %q", versionTable)).Scan(&latest).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf("DROP TABLE %q", versionTable)).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(fmt.Sprintf("CREATE TABLE %q (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)", versionTable)).Error; err != nil {
+			return err
+		}
+		if latest == 0 {
+			return nil
+		}
+		log.Printf("📋 Converting schema_migrations to golang-migrate at version %d", latest)
+		return tx.Exec(fmt.Sprintf("INSERT INTO %q (version, dirty) VALUES (?, false)", versionTable), latest).Error
+	})
+}
+
+func (m *Migrator) find(version int) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// NextVersion returns the version number for a new migration
+func NextVersion() (int, error) {
+	migrations, err := Load()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 1, nil
+	}
+	return migrations[len(migrations)-1].Version + 1, nil
+}
@@ -0,0 +1,63 @@
+package migrations_test
+
+import (
+	"testing"
+
+	"repair-service-server/migrations"
+	"repair-service-server/testdb"
+)
+
+// legacyVersion is how far the in-house runner had migrated the database in TestLegacyVersionsAdopted
+const legacyVersion = 3
+
+func TestLegacyVersionsAdopted(t *testing.T) {
+	db := testdb.OpenEmpty(t)
+	all, err := migrations.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The previous runner listed every applied version with its name and time
+	if err := db.Exec(`CREATE TABLE "schema_migrations" ("version" bigint PRIMARY KEY, "name" varchar(255) NOT NULL, "applied_at" timestamptz NOT NULL)`).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range all[:legacyVersion] {
+		if err := db.Exec(m.Up).Error; err != nil {
+			t.Fatalf("migration %d_%s: %v", m.Version, m.Name, err)
+		}
+		if err := db.Exec(`INSERT INTO "schema_migrations" VALUES (?, ?, now())`, m.Version, m.Name).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer migrator.Close()
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := len(all) - legacyVersion; applied != want {
+		t.Errorf("applied %d migrations, want the %d after version %d", applied, want, legacyVersion)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if !s.Applied || s.Dirty {
+			t.Errorf("migration %d_%s: applied %t, dirty %t after up", s.Version, s.Name, s.Applied, s.Dirty)
+		}
+	}
+
+	// The last migration reverts and applies again
+	if reverted, err := migrator.Down(1); err != nil || reverted != 1 {
+		t.Fatalf("down reverted %d migrations: %v", reverted, err)
+	}
+	if applied, err := migrator.Up(); err != nil || applied != 1 {
+		t.Fatalf("up applied %d migrations after down: %v", applied, err)
+	}
+}
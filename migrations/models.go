@@ -0,0 +1,53 @@
+package migrations
+
+import "repair-service-server/models"
+
+// Models returns every persisted model in dependency order. It is the source for generating
+// the schema baseline; later schema changes are written as SQL migrations, not derived from it.
+func Models() []interface{} {
+	return []interface{}{
+		&models.User{},
+		&models.Address{},
+		&models.ServiceCategory{},
+		&models.ServiceOption{},
+		&models.Service{},
+		&models.ServiceZone{},
+		&models.WorkerProfile{},
+		&models.CustomerServiceRequest{},
+		&models.WorkerResponse{},
+		// Chat models
+		&models.ChatRoom{},
+		&models.ChatMessage{},
+		&models.ChatNotification{},
+		&models.UserDeviceToken{},
+		// Rating and service history models
+		&models.WorkerRating{},
+		&models.ServiceHistory{},
+		&models.ServiceAdjustment{},
+		// Worker analytics models
+		&models.WorkerStats{},
+		&models.WorkerDailyStats{},
+		&models.WorkerMonthlyStats{},
+		&models.WorkerJobTracking{},
+		// Security models
+		&models.RefreshToken{},
+		// Notification models
+		&models.Notification{},
+		&models.PushToken{},
+		// Feedback models
+		&models.Feedback{},
+		// Worker schedule models
+		&models.WorkerScheduleSlot{},
+		&models.WorkerTimeOff{},
+		// Auto-dispatch models
+		&models.DispatchOffer{},
+		// Demand heatmap models
+		&models.DemandHeatmapCell{},
+		// Platform report models
+		&models.PlatformDailyMetric{},
+		// Audit models
+		&models.AuditLog{},
+		// Transactional outbox
+		&models.OutboxEvent{},
+	}
+}
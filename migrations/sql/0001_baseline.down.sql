@@ -0,0 +1,30 @@
+DROP TABLE IF EXISTS "outbox_events" CASCADE;
+DROP TABLE IF EXISTS "audit_logs" CASCADE;
+DROP TABLE IF EXISTS "platform_daily_metrics" CASCADE;
+DROP TABLE IF EXISTS "demand_heatmap_cells" CASCADE;
+DROP TABLE IF EXISTS "dispatch_offers" CASCADE;
+DROP TABLE IF EXISTS "worker_time_off" CASCADE;
+DROP TABLE IF EXISTS "worker_schedule_slots" CASCADE;
+DROP TABLE IF EXISTS "feedback" CASCADE;
+DROP TABLE IF EXISTS "push_tokens" CASCADE;
+DROP TABLE IF EXISTS "notifications" CASCADE;
+DROP TABLE IF EXISTS "refresh_tokens" CASCADE;
+DROP TABLE IF EXISTS "worker_monthly_stats" CASCADE;
+DROP TABLE IF EXISTS "worker_daily_stats" CASCADE;
+DROP TABLE IF EXISTS "worker_stats" CASCADE;
+DROP TABLE IF EXISTS "service_adjustments" CASCADE;
+DROP TABLE IF EXISTS "service_histories" CASCADE;
+DROP TABLE IF EXISTS "worker_ratings" CASCADE;
+DROP TABLE IF EXISTS "user_device_tokens" CASCADE;
+DROP TABLE IF EXISTS "chat_notifications" CASCADE;
+DROP TABLE IF EXISTS "chat_messages" CASCADE;
+DROP TABLE IF EXISTS "chat_rooms" CASCADE;
+DROP TABLE IF EXISTS "worker_responses" CASCADE;
+DROP TABLE IF EXISTS "customer_service_requests" CASCADE;
+DROP TABLE IF EXISTS "worker_profiles" CASCADE;
+DROP TABLE IF EXISTS "service_zones" CASCADE;
+DROP TABLE IF EXISTS "services" CASCADE;
+DROP TABLE IF EXISTS "service_options" CASCADE;
+DROP TABLE IF EXISTS "service_categories" CASCADE;
+DROP TABLE IF EXISTS "addresses" CASCADE;
+DROP TABLE IF EXISTS "users" CASCADE;
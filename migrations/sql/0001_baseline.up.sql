@@ -0,0 +1,174 @@
+-- Baseline schema generated from the gorm models by `server migrate baseline`
+
+CREATE TABLE "users" ("id" bigserial,"full_name" varchar(255) NOT NULL,"phone_number" varchar(20) NOT NULL,"password_hash" varchar(255) NOT NULL,"role" varchar(20) NOT NULL DEFAULT 'customer',"profile_picture_url" varchar(255),"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "chk_users_role" CHECK (role IN ('customer','worker','admin')));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_phone_number" ON "users" ("phone_number");
+
+CREATE TABLE "addresses" ("id" bigserial,"user_id" bigint NOT NULL,"label" varchar(50),"address_details" text NOT NULL,"city" varchar(50) NOT NULL DEFAULT 'Nouakchott',"latitude" decimal(10,8) NOT NULL,"longitude" decimal(11,8) NOT NULL,"is_default" boolean DEFAULT false,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_users_addresses" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+
+CREATE TABLE "service_categories" ("id" bigserial,"name" varchar(100) NOT NULL UNIQUE,"description" text,"icon" varchar(255),"color" varchar(20),"is_active" boolean DEFAULT true,"is_new" boolean DEFAULT false,"sort_order" bigint DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_service_categories_deleted_at" ON "service_categories" ("deleted_at");
+
+CREATE TABLE "service_options" ("id" bigserial,"category_id" bigint NOT NULL,"title" text NOT NULL,"description" text NOT NULL,"image_url" text,"price" decimal NOT NULL,"duration" bigint NOT NULL,"features" json,"is_active" boolean DEFAULT true,"sort_order" bigint DEFAULT 0,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_service_options_category" FOREIGN KEY ("category_id") REFERENCES "service_categories"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_service_options_deleted_at" ON "service_options" ("deleted_at");
+
+CREATE TABLE "services" ("id" bigserial,"category_id" bigint NOT NULL,"name" varchar(200) NOT NULL,"description" text,"price" decimal(10,2),"image_url" varchar(255) NOT NULL,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,"name_ar" varchar(200) NOT NULL,"description_ar" varchar(500) NOT NULL,"base_price" decimal(10,2),"price_unit" varchar(50),"guarantee" varchar(100),"policies" varchar(500),"deleted_at" timestamptz,"duration" bigint,PRIMARY KEY ("id"),CONSTRAINT "fk_services_category" FOREIGN KEY ("category_id") REFERENCES "service_categories"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_services_deleted_at" ON "services" ("deleted_at");
+
+CREATE TABLE "service_zones" ("id" bigserial,"name" varchar(100) NOT NULL,"city" varchar(100) NOT NULL,"polygon" json,"broadcast_radius_km" decimal(6,2) DEFAULT 10,"surge_multiplier" decimal(4,2) DEFAULT 1,"supported_category_ids" json,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_service_zones_deleted_at" ON "service_zones" ("deleted_at");
+
+CREATE INDEX IF NOT EXISTS "idx_service_zones_city" ON "service_zones" ("city");
+
+CREATE TABLE "worker_profiles" ("id" bigserial,"user_id" bigint NOT NULL,"category_id" bigint NOT NULL,"phone_number" varchar(20) NOT NULL,"country" varchar(100) NOT NULL,"state" varchar(100) NOT NULL,"city" varchar(100) NOT NULL,"postal_code" varchar(20) NOT NULL,"address" text,"experience" text,"skills" text,"hourly_rate" decimal(10,2) DEFAULT 2500,"profile_photo" varchar(500),"id_card_photo" varchar(500),"id_card_back_photo" varchar(500),"is_available" boolean DEFAULT false,"current_lat" decimal(10,8),"current_lng" decimal(11,8),"last_location_update" timestamptz,"location_accuracy" decimal(5,2),"active_requests" bigint DEFAULT 0,"max_concurrent_jobs" bigint DEFAULT 1,"completed_jobs" bigint DEFAULT 0,"rating" decimal(3,2) DEFAULT 0,"total_reviews" bigint DEFAULT 0,"is_verified" boolean DEFAULT false,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_profiles_category" FOREIGN KEY ("category_id") REFERENCES "service_categories"("id"),CONSTRAINT "fk_worker_profiles_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_profiles_deleted_at" ON "worker_profiles" ("deleted_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_worker_profiles_user_id" ON "worker_profiles" ("user_id");
+
+CREATE TABLE "customer_service_requests" ("id" bigserial,"customer_id" bigint NOT NULL,"category_id" bigint NOT NULL,"service_option_id" bigint,"title" varchar(200) NOT NULL,"description" text,"priority" varchar(20) NOT NULL,"budget" decimal(10,2),"estimated_duration" varchar(100),"location_address" text NOT NULL,"location_city" varchar(100) NOT NULL,"geocoded_address" text,"service_zone_id" bigint,"surge_multiplier" decimal(4,2) DEFAULT 1,"location_lat" decimal(10,8),"location_lng" decimal(11,8),"status" varchar(20) NOT NULL DEFAULT 'broadcast',"dispatch_mode" varchar(20) NOT NULL DEFAULT 'broadcast',"assigned_worker_id" bigint,"started_at" timestamptz,"completed_at" timestamptz,"expires_at" timestamptz,"scheduled_for" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_customer_service_requests_customer" FOREIGN KEY ("customer_id") REFERENCES "users"("id"),CONSTRAINT "fk_customer_service_requests_category" FOREIGN KEY ("category_id") REFERENCES "service_categories"("id"),CONSTRAINT "fk_customer_service_requests_service_option" FOREIGN KEY ("service_option_id") REFERENCES "service_options"("id"),CONSTRAINT "fk_customer_service_requests_service_zone" FOREIGN KEY ("service_zone_id") REFERENCES "service_zones"("id"),CONSTRAINT "fk_customer_service_requests_assigned_worker" FOREIGN KEY ("assigned_worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_customer_service_requests_deleted_at" ON "customer_service_requests" ("deleted_at");
+
+CREATE INDEX IF NOT EXISTS "idx_customer_service_requests_service_zone_id" ON "customer_service_requests" ("service_zone_id");
+
+CREATE TABLE "worker_responses" ("id" bigserial,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"response" varchar(20) NOT NULL,"message" text,"proposed_price" decimal(10,2),"proposed_time" timestamptz,"distance" decimal(5,2),"eta" timestamptz,"responded_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_responses_service_request" FOREIGN KEY ("service_request_id") REFERENCES "customer_service_requests"("id"),CONSTRAINT "fk_worker_responses_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE TABLE "chat_rooms" ("id" bigserial,"customer_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"service_request_id" bigint NOT NULL,"last_message_at" timestamptz,"last_message_text" text,"unread_count" bigint DEFAULT 0,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_chat_rooms_customer" FOREIGN KEY ("customer_id") REFERENCES "users"("id"),CONSTRAINT "fk_chat_rooms_worker" FOREIGN KEY ("worker_id") REFERENCES "users"("id"),CONSTRAINT "fk_chat_rooms_service_request" FOREIGN KEY ("service_request_id") REFERENCES "customer_service_requests"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_chat_rooms_deleted_at" ON "chat_rooms" ("deleted_at");
+
+CREATE TABLE "chat_messages" ("id" bigserial,"chat_room_id" bigint NOT NULL,"sender_id" bigint NOT NULL,"sender_type" text NOT NULL,"content" text NOT NULL,"message_text" text NOT NULL,"message_type" text DEFAULT 'text',"audio_url" text,"duration" bigint,"is_read" boolean DEFAULT false,"read_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_chat_messages_deleted_at" ON "chat_messages" ("deleted_at");
+
+CREATE TABLE "chat_notifications" ("id" bigserial,"user_id" bigint NOT NULL,"chat_room_id" bigint NOT NULL,"message_id" bigint NOT NULL,"title" text NOT NULL,"body" text NOT NULL,"type" text DEFAULT 'chat',"is_read" boolean DEFAULT false,"read_at" timestamptz,"device_token" text,"platform" text,"sent_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_chat_notifications_deleted_at" ON "chat_notifications" ("deleted_at");
+
+CREATE TABLE "user_device_tokens" ("id" bigserial,"user_id" bigint NOT NULL,"platform" text NOT NULL,"device_token" text NOT NULL,"device_info" text,"is_active" boolean DEFAULT true,"last_used_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_user_device_tokens_deleted_at" ON "user_device_tokens" ("deleted_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_platform" ON "user_device_tokens" ("user_id","platform");
+
+CREATE TABLE "worker_ratings" ("id" bigserial,"customer_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"service_request_id" bigint NOT NULL,"stars" bigint NOT NULL,"comment" text,"service_quality" bigint,"professionalism" bigint,"punctuality" bigint,"communication" bigint,"is_anonymous" boolean DEFAULT false,"is_verified" boolean DEFAULT false,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_ratings_customer" FOREIGN KEY ("customer_id") REFERENCES "users"("id"),CONSTRAINT "fk_worker_ratings_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"),CONSTRAINT "fk_worker_ratings_service_request" FOREIGN KEY ("service_request_id") REFERENCES "customer_service_requests"("id"),CONSTRAINT "chk_worker_ratings_communication" CHECK (communication >= 1 AND communication <= 5),CONSTRAINT "chk_worker_ratings_professionalism" CHECK (professionalism >= 1 AND professionalism <= 5),CONSTRAINT "chk_worker_ratings_stars" CHECK (stars >= 1 AND stars <= 5),CONSTRAINT "chk_worker_ratings_service_quality" CHECK (service_quality >= 1 AND service_quality <= 5),CONSTRAINT "chk_worker_ratings_punctuality" CHECK (punctuality >= 1 AND punctuality <= 5));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_ratings_deleted_at" ON "worker_ratings" ("deleted_at");
+
+CREATE TABLE "service_histories" ("id" bigserial,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"customer_id" bigint NOT NULL,"category_id" bigint NOT NULL,"service_option_id" bigint,"title" varchar(200) NOT NULL,"description" text,"priority" varchar(20) NOT NULL,"budget" decimal(10,2),"estimated_duration" varchar(100),"actual_duration" bigint,"location_address" text NOT NULL,"location_city" varchar(100) NOT NULL,"location_lat" decimal(10,8),"location_lng" decimal(11,8),"request_created_at" timestamptz,"assigned_at" timestamptz,"started_at" timestamptz,"completed_at" timestamptz,"agreed_price" decimal(10,2),"final_price" decimal(10,2),"payment_status" varchar(20) DEFAULT 'pending',"refunded_amount" decimal(10,2) DEFAULT 0,"refund_reason" text,"customer_satisfaction" bigint,"work_quality" bigint,"worker_notes" text,"customer_notes" text,"is_disputed" boolean DEFAULT false,"dispute_reason" text,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_service_histories_category" FOREIGN KEY ("category_id") REFERENCES "service_categories"("id"),CONSTRAINT "fk_service_histories_service_option" FOREIGN KEY ("service_option_id") REFERENCES "service_options"("id"),CONSTRAINT "fk_service_histories_service_request" FOREIGN KEY ("service_request_id") REFERENCES "customer_service_requests"("id"),CONSTRAINT "fk_service_histories_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"),CONSTRAINT "fk_service_histories_customer" FOREIGN KEY ("customer_id") REFERENCES "users"("id"),CONSTRAINT "chk_service_histories_work_quality" CHECK (work_quality >= 1 AND work_quality <= 5),CONSTRAINT "chk_service_histories_customer_satisfaction" CHECK (customer_satisfaction >= 1 AND customer_satisfaction <= 5));
+
+CREATE INDEX IF NOT EXISTS "idx_service_histories_deleted_at" ON "service_histories" ("deleted_at");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_service_histories_service_request_id" ON "service_histories" ("service_request_id");
+
+CREATE TABLE "service_adjustments" ("id" bigserial,"service_history_id" bigint NOT NULL,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"customer_id" bigint NOT NULL,"admin_id" bigint NOT NULL,"type" varchar(30) NOT NULL,"previous_price" decimal(10,2),"new_price" decimal(10,2),"amount" decimal(10,2) NOT NULL,"earnings_delta" decimal(10,2) NOT NULL,"reason" text NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_service_adjustments_customer_id" ON "service_adjustments" ("customer_id");
+
+CREATE INDEX IF NOT EXISTS "idx_service_adjustments_worker_id" ON "service_adjustments" ("worker_id");
+
+CREATE INDEX IF NOT EXISTS "idx_service_adjustments_service_request_id" ON "service_adjustments" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_service_adjustments_service_history_id" ON "service_adjustments" ("service_history_id");
+
+CREATE TABLE "worker_stats" ("id" bigserial,"worker_id" bigint NOT NULL,"total_jobs_received" bigint DEFAULT 0,"total_jobs_responded" bigint DEFAULT 0,"total_jobs_completed" bigint DEFAULT 0,"total_jobs_declined" bigint DEFAULT 0,"total_earnings" decimal DEFAULT 0,"total_work_hours" decimal DEFAULT 0,"monthly_jobs_received" bigint DEFAULT 0,"monthly_jobs_responded" bigint DEFAULT 0,"monthly_jobs_completed" bigint DEFAULT 0,"monthly_jobs_declined" bigint DEFAULT 0,"monthly_earnings" decimal DEFAULT 0,"monthly_work_hours" decimal DEFAULT 0,"daily_jobs_received" bigint DEFAULT 0,"daily_jobs_responded" bigint DEFAULT 0,"daily_jobs_completed" bigint DEFAULT 0,"daily_jobs_declined" bigint DEFAULT 0,"daily_earnings" decimal DEFAULT 0,"daily_work_hours" decimal DEFAULT 0,"response_rate" decimal DEFAULT 0,"completion_rate" decimal DEFAULT 0,"average_response_time" decimal DEFAULT 0,"average_job_duration" decimal DEFAULT 0,"average_earnings_per_job" decimal DEFAULT 0,"average_rating" decimal DEFAULT 0,"total_ratings" bigint DEFAULT 0,"last_job_received" timestamptz,"last_job_responded" timestamptz,"last_job_completed" timestamptz,"last_earning" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_stats_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_stats_deleted_at" ON "worker_stats" ("deleted_at");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_stats_worker_id" ON "worker_stats" ("worker_id");
+
+CREATE TABLE "worker_daily_stats" ("id" bigserial,"worker_id" bigint NOT NULL,"date" timestamptz NOT NULL,"jobs_received" bigint,"jobs_responded" bigint,"jobs_completed" bigint,"jobs_declined" bigint,"earnings" decimal,"work_hours" decimal,"average_rating" decimal,"total_response_time" decimal,"jobs_with_response" bigint,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_daily_stats_date" ON "worker_daily_stats" ("date");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_daily_stats_worker_id" ON "worker_daily_stats" ("worker_id");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_daily_stats_deleted_at" ON "worker_daily_stats" ("deleted_at");
+
+CREATE TABLE "worker_monthly_stats" ("id" bigserial,"worker_id" bigint NOT NULL,"year" bigint NOT NULL,"month" bigint NOT NULL,"jobs_received" bigint,"jobs_responded" bigint,"jobs_completed" bigint,"jobs_declined" bigint,"earnings" decimal,"work_hours" decimal,"average_rating" decimal,"response_rate" decimal,"completion_rate" decimal,"average_response_time" decimal,"average_job_duration" decimal,"average_earnings_per_job" decimal,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_monthly_stats_deleted_at" ON "worker_monthly_stats" ("deleted_at");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_monthly_stats_month" ON "worker_monthly_stats" ("month");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_monthly_stats_year" ON "worker_monthly_stats" ("year");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_monthly_stats_worker_id" ON "worker_monthly_stats" ("worker_id");
+
+CREATE TABLE "refresh_tokens" ("id" bigserial,"token" varchar(255) NOT NULL,"user_id" bigint NOT NULL,"expires_at" timestamptz NOT NULL,"is_revoked" boolean DEFAULT false,"created_at" timestamptz,"updated_at" timestamptz,"device_id" varchar(255),"user_agent" varchar(500),"ip_address" varchar(45),PRIMARY KEY ("id"),CONSTRAINT "fk_refresh_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_is_revoked" ON "refresh_tokens" ("is_revoked");
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_expires_at" ON "refresh_tokens" ("expires_at");
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token" ON "refresh_tokens" ("token");
+
+CREATE TABLE "notifications" ("id" bigserial,"user_id" bigint NOT NULL,"title" text NOT NULL,"body" text NOT NULL,"type" text NOT NULL,"data" text,"read" boolean DEFAULT false,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_notifications_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_notifications_deleted_at" ON "notifications" ("deleted_at");
+
+CREATE TABLE "push_tokens" ("id" bigserial,"user_id" bigint NOT NULL,"token" text NOT NULL UNIQUE,"platform" text NOT NULL,"device_id" text,"active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_push_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_push_tokens_deleted_at" ON "push_tokens" ("deleted_at");
+
+CREATE TABLE "feedback" ("id" bigserial,"user_id" bigint NOT NULL,"worker_id" bigint,"service_request_id" bigint,"rating" bigint,"comment" text,"app_version" varchar(50),"device_model" varchar(100),"os" varchar(50),"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "chk_feedback_rating" CHECK (rating >= 1 AND rating <= 5));
+
+CREATE INDEX IF NOT EXISTS "idx_feedback_deleted_at" ON "feedback" ("deleted_at");
+
+CREATE TABLE "worker_schedule_slots" ("id" bigserial,"worker_id" bigint NOT NULL,"day_of_week" bigint NOT NULL,"start_time" varchar(5) NOT NULL,"end_time" varchar(5) NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "chk_worker_schedule_slots_day_of_week" CHECK (day_of_week >= 0 AND day_of_week <= 6));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_schedule_slots_worker_id" ON "worker_schedule_slots" ("worker_id");
+
+CREATE TABLE "worker_time_off" ("id" bigserial,"worker_id" bigint NOT NULL,"starts_at" timestamptz NOT NULL,"ends_at" timestamptz NOT NULL,"reason" varchar(255),"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_time_off_ends_at" ON "worker_time_off" ("ends_at");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_time_off_starts_at" ON "worker_time_off" ("starts_at");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_time_off_worker_id" ON "worker_time_off" ("worker_id");
+
+CREATE TABLE "dispatch_offers" ("id" bigserial,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"rank" bigint NOT NULL,"score" decimal(6,4),"distance" decimal(8,2),"status" varchar(20) NOT NULL DEFAULT 'pending',"expires_at" timestamptz NOT NULL,"responded_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_dispatch_offers_expires_at" ON "dispatch_offers" ("expires_at");
+
+CREATE INDEX IF NOT EXISTS "idx_dispatch_offers_status" ON "dispatch_offers" ("status");
+
+CREATE INDEX IF NOT EXISTS "idx_dispatch_offers_worker_id" ON "dispatch_offers" ("worker_id");
+
+CREATE INDEX IF NOT EXISTS "idx_dispatch_offers_service_request_id" ON "dispatch_offers" ("service_request_id");
+
+CREATE TABLE "demand_heatmap_cells" ("id" bigserial,"category_id" bigint NOT NULL,"geohash" varchar(12) NOT NULL,"hour_of_day" bigint NOT NULL,"request_count" bigint NOT NULL DEFAULT 0,"center_lat" decimal(10,8),"center_lng" decimal(11,8),"weeks_covered" bigint NOT NULL,"computed_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_heatmap_cell" ON "demand_heatmap_cells" ("category_id","geohash","hour_of_day");
+
+CREATE TABLE "platform_daily_metrics" ("id" bigserial,"date" date NOT NULL,"category_id" bigint NOT NULL,"city" varchar(100) NOT NULL,"requests_created" bigint,"requests_accepted" bigint,"requests_completed" bigint,"requests_cancelled" bigint,"total_accept_minutes" decimal,"gmv" decimal(12,2),"refunds" decimal(12,2),"computed_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_platform_metric" ON "platform_daily_metrics" ("date","category_id","city");
+
+CREATE TABLE "audit_logs" ("id" bigserial,"request_id" varchar(64),"actor_id" bigint,"actor_role" varchar(20),"impersonator_id" bigint,"method" varchar(10) NOT NULL,"route" varchar(255),"path" varchar(500),"status_code" bigint,"entity_type" varchar(50),"entity_id" varchar(50),"before" json,"after" json,"ip_address" varchar(45),"user_agent" varchar(500),"duration_ms" bigint,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_request_id" ON "audit_logs" ("request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_entity" ON "audit_logs" ("entity_type","entity_id");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_route" ON "audit_logs" ("route");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_impersonator_id" ON "audit_logs" ("impersonator_id");
+
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+
+CREATE TABLE "outbox_events" ("id" bigserial,"event_type" varchar(50) NOT NULL,"aggregate_id" bigint,"payload" json NOT NULL,"status" varchar(20) DEFAULT 'pending',"attempts" bigint DEFAULT 0,"last_error" text,"available_at" timestamptz NOT NULL,"processed_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_pending" ON "outbox_events" ("status","available_at");
+
+CREATE INDEX IF NOT EXISTS "idx_outbox_events_aggregate_id" ON "outbox_events" ("aggregate_id");
+
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "idx_addresses_user_default";
+DROP INDEX IF EXISTS "idx_push_tokens_user_active";
+CREATE INDEX IF NOT EXISTS "idx_worker_daily_stats_worker_id" ON "worker_daily_stats" ("worker_id");
+DROP INDEX IF EXISTS "idx_worker_daily_stats_worker_date";
+DROP INDEX IF EXISTS "idx_chat_messages_room_created";
+DROP INDEX IF EXISTS "idx_csr_category_status_worker";
@@ -0,0 +1,18 @@
+-- Composite indexes for the hottest queries
+
+-- Worker feed: category_id = ? AND status = 'broadcast' AND assigned_worker_id IS NULL
+CREATE INDEX IF NOT EXISTS "idx_csr_category_status_worker" ON "customer_service_requests" ("category_id", "status", "assigned_worker_id");
+
+-- Chat history: chat_room_id = ? ORDER BY created_at DESC LIMIT n
+CREATE INDEX IF NOT EXISTS "idx_chat_messages_room_created" ON "chat_messages" ("chat_room_id", "created_at" DESC);
+
+-- Analytics trends and daily upserts: worker_id = ? AND date >= ? / date = ?
+CREATE INDEX IF NOT EXISTS "idx_worker_daily_stats_worker_date" ON "worker_daily_stats" ("worker_id", "date");
+-- Covered by the composite index above
+DROP INDEX IF EXISTS "idx_worker_daily_stats_worker_id";
+
+-- Push delivery: user_id = ? AND active = true
+CREATE INDEX IF NOT EXISTS "idx_push_tokens_user_active" ON "push_tokens" ("user_id", "active");
+
+-- Worker feed default address lookup: user_id IN (...) AND is_default = true
+CREATE INDEX IF NOT EXISTS "idx_addresses_user_default" ON "addresses" ("user_id", "is_default");
@@ -0,0 +1,7 @@
+-- Stored responses for requests sent with an Idempotency-Key header
+
+CREATE TABLE "idempotency_keys" ("id" bigserial,"user_id" bigint NOT NULL,"key" varchar(255) NOT NULL,"method" varchar(10) NOT NULL,"path" varchar(255) NOT NULL,"request_hash" varchar(64) NOT NULL,"status_code" bigint,"content_type" varchar(100),"response" bytea,"completed_at" timestamptz,"expires_at" timestamptz NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_idempotency_user_key" ON "idempotency_keys" ("user_id","key");
+
+CREATE INDEX IF NOT EXISTS "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
@@ -0,0 +1,3 @@
+ALTER TABLE "users" DROP COLUMN IF EXISTS "phone_verified_at";
+
+DROP TABLE IF EXISTS "phone_verifications";
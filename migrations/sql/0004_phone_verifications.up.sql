@@ -0,0 +1,14 @@
+-- SMS one-time codes and verified phone numbers
+
+CREATE TABLE "phone_verifications" ("id" bigserial,"user_id" bigint,"phone_number" varchar(20) NOT NULL,"purpose" varchar(20) NOT NULL,"code_hash" varchar(64) NOT NULL,"attempts" bigint NOT NULL DEFAULT 0,"expires_at" timestamptz NOT NULL,"verified_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_phone_verifications_user_id" ON "phone_verifications" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_phone_verifications_phone_purpose" ON "phone_verifications" ("phone_number","purpose");
+
+CREATE INDEX IF NOT EXISTS "idx_phone_verifications_expires_at" ON "phone_verifications" ("expires_at");
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "phone_verified_at" timestamptz;
+
+-- Accounts created before verification existed keep creating requests without re-verifying
+UPDATE "users" SET "phone_verified_at" = "created_at" WHERE "phone_verified_at" IS NULL;
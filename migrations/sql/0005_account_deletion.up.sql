@@ -0,0 +1,9 @@
+-- Scheduled account deletion and anonymization
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "deletion_requested_at" timestamptz;
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "deletion_scheduled_for" timestamptz;
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "anonymized_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "idx_users_deletion_scheduled_for" ON "users" ("deletion_scheduled_for");
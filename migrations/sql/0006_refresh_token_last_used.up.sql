@@ -0,0 +1,5 @@
+-- Session listing shows when each refresh token was last used
+
+ALTER TABLE "refresh_tokens" ADD COLUMN IF NOT EXISTS "last_used_at" timestamptz;
+
+UPDATE "refresh_tokens" SET "last_used_at" = "updated_at" WHERE "last_used_at" IS NULL;
@@ -0,0 +1,7 @@
+-- Failed sign-in counters for progressive delays and account lockout
+
+CREATE TABLE "login_throttles" ("id" bigserial,"key" varchar(80) NOT NULL,"failures" bigint NOT NULL DEFAULT 0,"last_failure_at" timestamptz NOT NULL,"locked_until" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_login_throttles_key" ON "login_throttles" ("key");
+
+CREATE INDEX IF NOT EXISTS "idx_login_throttles_last_failure_at" ON "login_throttles" ("last_failure_at");
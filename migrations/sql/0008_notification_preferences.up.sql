@@ -0,0 +1,5 @@
+-- Per-user notification toggles, quiet hours and preferred channel
+
+CREATE TABLE "notification_preferences" ("id" bigserial,"user_id" bigint NOT NULL,"chat_enabled" boolean NOT NULL DEFAULT true,"status_updates_enabled" boolean NOT NULL DEFAULT true,"promotions_enabled" boolean NOT NULL DEFAULT true,"quiet_hours_enabled" boolean NOT NULL DEFAULT false,"quiet_hours_start" varchar(5) NOT NULL DEFAULT '22:00',"quiet_hours_end" varchar(5) NOT NULL DEFAULT '07:00',"timezone" varchar(64) NOT NULL DEFAULT 'Africa/Nouakchott',"preferred_channel" varchar(10) NOT NULL DEFAULT 'push',"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notification_preferences_user_id" ON "notification_preferences" ("user_id");
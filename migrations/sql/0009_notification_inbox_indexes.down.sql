@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_notifications_created_at";
+DROP INDEX IF EXISTS "idx_notifications_user_read_created";
@@ -0,0 +1,5 @@
+-- Paginated inbox listings, unread badge counts and the retention purge
+
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_read_created" ON "notifications" ("user_id", "read", "created_at" DESC);
+
+CREATE INDEX IF NOT EXISTS "idx_notifications_created_at" ON "notifications" ("created_at");
@@ -0,0 +1,9 @@
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "earnings_summary_sent_at";
+
+DROP TABLE IF EXISTS "email_verifications";
+
+DROP INDEX IF EXISTS "idx_users_email";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email_verified_at";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "email";
@@ -0,0 +1,17 @@
+-- Verified email addresses, emailed codes and weekly worker earnings emails
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email" varchar(255);
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email_verified_at" timestamptz;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE "email_verifications" ("id" bigserial,"user_id" bigint NOT NULL,"email" varchar(255) NOT NULL,"purpose" varchar(20) NOT NULL,"code_hash" varchar(64) NOT NULL,"attempts" bigint NOT NULL DEFAULT 0,"expires_at" timestamptz NOT NULL,"verified_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_user_id" ON "email_verifications" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_email_purpose" ON "email_verifications" ("email","purpose");
+
+CREATE INDEX IF NOT EXISTS "idx_email_verifications_expires_at" ON "email_verifications" ("expires_at");
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "earnings_summary_sent_at" timestamptz;
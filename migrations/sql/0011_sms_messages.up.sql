@@ -0,0 +1,7 @@
+-- Per-message SMS log for cost tracking
+
+CREATE TABLE "sms_messages" ("id" bigserial,"user_id" bigint,"phone_number" varchar(20) NOT NULL,"purpose" varchar(20) NOT NULL,"notification_type" varchar(50),"provider" varchar(20) NOT NULL,"status" varchar(10) NOT NULL,"segments" bigint NOT NULL,"cost" decimal(10,4) NOT NULL DEFAULT 0,"error" text,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_sms_messages_user_id" ON "sms_messages" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_sms_messages_created_at" ON "sms_messages" ("created_at");
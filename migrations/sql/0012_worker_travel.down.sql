@@ -0,0 +1,15 @@
+ALTER TABLE "worker_stats" DROP COLUMN IF EXISTS "total_travels";
+
+ALTER TABLE "worker_stats" DROP COLUMN IF EXISTS "average_travel_time";
+
+ALTER TABLE "worker_daily_stats" DROP COLUMN IF EXISTS "jobs_with_travel";
+
+ALTER TABLE "worker_daily_stats" DROP COLUMN IF EXISTS "total_travel_time";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "eta_updated_at";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "eta_minutes";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "arrived_at";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "en_route_at";
@@ -0,0 +1,17 @@
+-- En route and arrival workflow with live ETA and travel time analytics
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "en_route_at" timestamptz;
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "arrived_at" timestamptz;
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "eta_minutes" bigint;
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "eta_updated_at" timestamptz;
+
+ALTER TABLE "worker_daily_stats" ADD COLUMN IF NOT EXISTS "total_travel_time" decimal;
+
+ALTER TABLE "worker_daily_stats" ADD COLUMN IF NOT EXISTS "jobs_with_travel" bigint;
+
+ALTER TABLE "worker_stats" ADD COLUMN IF NOT EXISTS "average_travel_time" decimal DEFAULT 0;
+
+ALTER TABLE "worker_stats" ADD COLUMN IF NOT EXISTS "total_travels" bigint DEFAULT 0;
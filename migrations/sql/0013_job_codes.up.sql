@@ -0,0 +1,5 @@
+-- Customer codes confirming job start and completion
+
+CREATE TABLE "job_codes" ("id" bigserial,"service_request_id" bigint NOT NULL,"stage" varchar(20) NOT NULL,"code" varchar(4) NOT NULL,"attempts" bigint NOT NULL DEFAULT 0,"verified_at" timestamptz,"overridden_by" bigint,"override_reason" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_job_codes_request_stage" ON "job_codes" ("service_request_id","stage");
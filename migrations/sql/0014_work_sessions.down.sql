@@ -0,0 +1,7 @@
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "worked_seconds";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "timer_started_at";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "timer_state";
+
+DROP TABLE IF EXISTS "work_sessions";
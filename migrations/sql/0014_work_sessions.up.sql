@@ -0,0 +1,13 @@
+-- Job timer with pause and resume
+
+CREATE TABLE "work_sessions" ("id" bigserial,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"started_at" timestamptz NOT NULL,"ended_at" timestamptz,"pause_reason" varchar(255),"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_work_sessions_service_request_id" ON "work_sessions" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_work_sessions_worker_id" ON "work_sessions" ("worker_id");
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "timer_state" varchar(10) NOT NULL DEFAULT '';
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "timer_started_at" timestamptz;
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "worked_seconds" bigint NOT NULL DEFAULT 0;
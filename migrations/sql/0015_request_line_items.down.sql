@@ -0,0 +1,3 @@
+ALTER TABLE "service_histories" DROP COLUMN IF EXISTS "parts_total";
+
+DROP TABLE IF EXISTS "request_line_items";
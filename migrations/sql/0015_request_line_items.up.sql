@@ -0,0 +1,7 @@
+-- Parts and materials added to a job by the worker
+
+CREATE TABLE "request_line_items" ("id" bigserial,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"description" varchar(255) NOT NULL,"quantity" decimal(10,2) NOT NULL,"unit_price" decimal(10,2) NOT NULL,"receipt_photo_url" text,"status" varchar(10) NOT NULL DEFAULT 'pending',"decided_at" timestamptz,"rejection_reason" varchar(255),"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_request_line_items_service_request_id" ON "request_line_items" ("service_request_id");
+
+ALTER TABLE "service_histories" ADD COLUMN IF NOT EXISTS "parts_total" decimal(10,2) DEFAULT 0;
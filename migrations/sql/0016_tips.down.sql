@@ -0,0 +1,15 @@
+ALTER TABLE "worker_monthly_stats" DROP COLUMN IF EXISTS "tips";
+
+ALTER TABLE "worker_daily_stats" DROP COLUMN IF EXISTS "tips";
+
+ALTER TABLE "worker_stats" DROP COLUMN IF EXISTS "daily_tips";
+
+ALTER TABLE "worker_stats" DROP COLUMN IF EXISTS "monthly_tips";
+
+ALTER TABLE "worker_stats" DROP COLUMN IF EXISTS "total_tips";
+
+ALTER TABLE "service_histories" DROP COLUMN IF EXISTS "tip_amount";
+
+DROP TABLE IF EXISTS "platform_settings";
+
+DROP TABLE IF EXISTS "tips";
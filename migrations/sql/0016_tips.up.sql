@@ -0,0 +1,23 @@
+-- Customer tips and admin-editable platform settings
+
+CREATE TABLE "tips" ("id" bigserial,"service_request_id" bigint NOT NULL,"customer_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"amount" decimal(10,2) NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tips_service_request_id" ON "tips" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_tips_customer_id" ON "tips" ("customer_id");
+
+CREATE INDEX IF NOT EXISTS "idx_tips_worker_id" ON "tips" ("worker_id");
+
+CREATE TABLE "platform_settings" ("key" varchar(100),"value" text NOT NULL,"updated_by" bigint,"updated_at" timestamptz,PRIMARY KEY ("key"));
+
+ALTER TABLE "service_histories" ADD COLUMN IF NOT EXISTS "tip_amount" decimal(10,2) DEFAULT 0;
+
+ALTER TABLE "worker_stats" ADD COLUMN IF NOT EXISTS "total_tips" decimal DEFAULT 0;
+
+ALTER TABLE "worker_stats" ADD COLUMN IF NOT EXISTS "monthly_tips" decimal DEFAULT 0;
+
+ALTER TABLE "worker_stats" ADD COLUMN IF NOT EXISTS "daily_tips" decimal DEFAULT 0;
+
+ALTER TABLE "worker_daily_stats" ADD COLUMN IF NOT EXISTS "tips" decimal;
+
+ALTER TABLE "worker_monthly_stats" ADD COLUMN IF NOT EXISTS "tips" decimal;
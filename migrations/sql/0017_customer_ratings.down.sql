@@ -0,0 +1,7 @@
+ALTER TABLE "users" DROP COLUMN IF EXISTS "customer_rating_count";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "customer_score";
+
+DROP TABLE IF EXISTS "abuse_reports";
+
+DROP TABLE IF EXISTS "customer_ratings";
@@ -0,0 +1,19 @@
+-- Worker ratings of customers, customer scores and abuse reports
+
+CREATE TABLE "customer_ratings" ("id" bigserial,"service_request_id" bigint NOT NULL,"customer_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"stars" bigint NOT NULL,"punctual" bigint NOT NULL,"respectful" bigint NOT NULL,"paid_promptly" bigint NOT NULL,"comment" text,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "chk_customer_ratings_stars" CHECK (stars >= 1 AND stars <= 5),CONSTRAINT "chk_customer_ratings_punctual" CHECK (punctual >= 1 AND punctual <= 5),CONSTRAINT "chk_customer_ratings_respectful" CHECK (respectful >= 1 AND respectful <= 5),CONSTRAINT "chk_customer_ratings_paid_promptly" CHECK (paid_promptly >= 1 AND paid_promptly <= 5));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customer_ratings_service_request_id" ON "customer_ratings" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_customer_ratings_customer_id" ON "customer_ratings" ("customer_id");
+
+CREATE INDEX IF NOT EXISTS "idx_customer_ratings_worker_id" ON "customer_ratings" ("worker_id");
+
+CREATE TABLE "abuse_reports" ("id" bigserial,"customer_id" bigint NOT NULL,"customer_rating_id" bigint NOT NULL,"reason" varchar(255) NOT NULL,"low_rating_count" bigint NOT NULL DEFAULT 1,"customer_score" decimal(3,2),"status" varchar(20) NOT NULL DEFAULT 'open',"resolved_by" bigint,"resolved_at" timestamptz,"resolution_note" text,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_abuse_reports_customer_id" ON "abuse_reports" ("customer_id");
+
+CREATE INDEX IF NOT EXISTS "idx_abuse_reports_status" ON "abuse_reports" ("status");
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "customer_score" decimal(3,2);
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "customer_rating_count" bigint DEFAULT 0;
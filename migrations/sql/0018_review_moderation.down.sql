@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS "review_flags";
+
+DROP INDEX IF EXISTS "idx_worker_ratings_moderation_status";
+
+ALTER TABLE "worker_ratings" DROP COLUMN IF EXISTS "moderation_note";
+
+ALTER TABLE "worker_ratings" DROP COLUMN IF EXISTS "moderated_at";
+
+ALTER TABLE "worker_ratings" DROP COLUMN IF EXISTS "moderated_by";
+
+ALTER TABLE "worker_ratings" DROP COLUMN IF EXISTS "flag_count";
+
+ALTER TABLE "worker_ratings" DROP COLUMN IF EXISTS "moderation_status";
+
+ALTER TABLE "worker_ratings" DROP COLUMN IF EXISTS "worker_replied_at";
+
+ALTER TABLE "worker_ratings" DROP COLUMN IF EXISTS "worker_reply";
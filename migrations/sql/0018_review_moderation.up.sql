@@ -0,0 +1,21 @@
+-- Worker replies to ratings and the review moderation queue
+
+ALTER TABLE "worker_ratings" ADD COLUMN IF NOT EXISTS "worker_reply" text;
+
+ALTER TABLE "worker_ratings" ADD COLUMN IF NOT EXISTS "worker_replied_at" timestamptz;
+
+ALTER TABLE "worker_ratings" ADD COLUMN IF NOT EXISTS "moderation_status" varchar(20) NOT NULL DEFAULT 'visible';
+
+ALTER TABLE "worker_ratings" ADD COLUMN IF NOT EXISTS "flag_count" bigint DEFAULT 0;
+
+ALTER TABLE "worker_ratings" ADD COLUMN IF NOT EXISTS "moderated_by" bigint;
+
+ALTER TABLE "worker_ratings" ADD COLUMN IF NOT EXISTS "moderated_at" timestamptz;
+
+ALTER TABLE "worker_ratings" ADD COLUMN IF NOT EXISTS "moderation_note" text;
+
+CREATE INDEX IF NOT EXISTS "idx_worker_ratings_moderation_status" ON "worker_ratings" ("moderation_status");
+
+CREATE TABLE "review_flags" ("id" bigserial,"rating_id" bigint NOT NULL,"reporter_id" bigint NOT NULL,"reason" varchar(20) NOT NULL,"details" text,"resolved_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_review_flags_rating_reporter" ON "review_flags" ("rating_id","reporter_id");
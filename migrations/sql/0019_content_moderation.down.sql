@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "content_violations";
+
+DROP TABLE IF EXISTS "moderation_terms";
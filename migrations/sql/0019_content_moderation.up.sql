@@ -0,0 +1,23 @@
+-- Content moderation word lists and the violation log
+
+CREATE TABLE "moderation_terms" ("id" bigserial,"term" varchar(100) NOT NULL,"kind" varchar(20) NOT NULL,"created_by" bigint,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_moderation_terms_kind_term" ON "moderation_terms" ("term","kind");
+
+CREATE TABLE "content_violations" ("id" bigserial,"user_id" bigint NOT NULL,"source_type" varchar(30) NOT NULL,"source_id" bigint NOT NULL,"kind" varchar(20) NOT NULL,"detail" varchar(255),"original_text" text,"filtered_text" text,"reviewed_by" bigint,"reviewed_at" timestamptz,"review_note" text,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_content_violations_user_id" ON "content_violations" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_content_violations_source" ON "content_violations" ("source_type","source_id");
+
+CREATE INDEX IF NOT EXISTS "idx_content_violations_kind" ON "content_violations" ("kind");
+
+CREATE INDEX IF NOT EXISTS "idx_content_violations_reviewed_at" ON "content_violations" ("reviewed_at");
+
+INSERT INTO "moderation_terms" ("term","kind","created_at") VALUES
+('fuck','profanity',NOW()),('fucking','profanity',NOW()),('shit','profanity',NOW()),('bitch','profanity',NOW()),('asshole','profanity',NOW()),('bastard','profanity',NOW()),
+('merde','profanity',NOW()),('putain','profanity',NOW()),('connard','profanity',NOW()),('connasse','profanity',NOW()),('salope','profanity',NOW()),('enculé','profanity',NOW()),
+('pay cash','payment_bait',NOW()),('pay me directly','payment_bait',NOW()),('outside the app','payment_bait',NOW()),('whatsapp','payment_bait',NOW()),
+('bankily','payment_bait',NOW()),('masrvi','payment_bait',NOW()),('sedad','payment_bait',NOW()),
+('en espèces','payment_bait',NOW()),('hors application','payment_bait',NOW()),('payer directement','payment_bait',NOW())
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,7 @@
+-- AI assistant conversations linked to the requests they booked
+
+CREATE TABLE "ai_conversations" ("id" bigserial,"user_id" bigint NOT NULL,"service_request_id" bigint NOT NULL,"transcript" json,"draft" json,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_ai_conversations_user_id" ON "ai_conversations" ("user_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_ai_conversations_service_request_id" ON "ai_conversations" ("service_request_id");
@@ -0,0 +1,7 @@
+-- AI photo diagnoses, linked to the requests booked for them
+
+CREATE TABLE "ai_diagnoses" ("id" bigserial,"user_id" bigint NOT NULL,"service_request_id" bigint,"note" text,"problem" text NOT NULL,"severity" varchar(20) NOT NULL,"suggested_category_id" bigint,"price_min" decimal(10,2),"price_max" decimal(10,2),"advice" text,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_ai_diagnoses_user_id" ON "ai_diagnoses" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_ai_diagnoses_service_request_id" ON "ai_diagnoses" ("service_request_id");
@@ -0,0 +1,3 @@
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "transcript_status";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "transcript";
@@ -0,0 +1,5 @@
+-- Speech-to-text transcripts of chat voice messages
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "transcript" text;
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "transcript_status" varchar(20);
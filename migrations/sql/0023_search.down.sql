@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS "idx_users_full_name_trgm";
+
+DROP INDEX IF EXISTS "idx_worker_profiles_skills_trgm";
+
+DROP INDEX IF EXISTS "idx_worker_profiles_search_vector";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "search_vector";
+
+DROP INDEX IF EXISTS "idx_services_name_ar_trgm";
+
+DROP INDEX IF EXISTS "idx_services_name_trgm";
+
+DROP INDEX IF EXISTS "idx_services_search_vector";
+
+ALTER TABLE "services" DROP COLUMN IF EXISTS "search_vector";
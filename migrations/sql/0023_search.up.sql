@@ -0,0 +1,30 @@
+-- Full-text and trigram search over services and worker profiles
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE "services" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('french', coalesce("name", '')), 'A') ||
+	setweight(to_tsvector('arabic', coalesce("name_ar", '')), 'A') ||
+	setweight(to_tsvector('french', coalesce("description", '')), 'B') ||
+	setweight(to_tsvector('arabic', coalesce("description_ar", '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS "idx_services_search_vector" ON "services" USING gin ("search_vector");
+
+CREATE INDEX IF NOT EXISTS "idx_services_name_trgm" ON "services" USING gin ("name" gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS "idx_services_name_ar_trgm" ON "services" USING gin ("name_ar" gin_trgm_ops);
+
+-- Skills and experience are free text in French or Arabic, so both stemmers index them
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('french', coalesce("skills", '')), 'A') ||
+	setweight(to_tsvector('arabic', coalesce("skills", '')), 'A') ||
+	setweight(to_tsvector('french', coalesce("experience", '')), 'B') ||
+	setweight(to_tsvector('arabic', coalesce("experience", '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS "idx_worker_profiles_search_vector" ON "worker_profiles" USING gin ("search_vector");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_profiles_skills_trgm" ON "worker_profiles" USING gin ("skills" gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS "idx_users_full_name_trgm" ON "users" USING gin ("full_name" gin_trgm_ops);
@@ -0,0 +1,10 @@
+-- Language users get notifications, emails and error messages in
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "preferred_language" varchar(5);
+
+-- Some databases already have the column from before it was in the model
+UPDATE "users" SET "preferred_language" = '' WHERE "preferred_language" IS NULL OR "preferred_language" NOT IN ('en', 'fr', 'ar');
+
+ALTER TABLE "users" ALTER COLUMN "preferred_language" SET DEFAULT '';
+
+ALTER TABLE "users" ALTER COLUMN "preferred_language" SET NOT NULL;
@@ -0,0 +1,15 @@
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "description_ar";
+
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "description_en";
+
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "title_ar";
+
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "title_en";
+
+ALTER TABLE "service_categories" DROP COLUMN IF EXISTS "description_ar";
+
+ALTER TABLE "service_categories" DROP COLUMN IF EXISTS "description_en";
+
+ALTER TABLE "service_categories" DROP COLUMN IF EXISTS "name_ar";
+
+ALTER TABLE "service_categories" DROP COLUMN IF EXISTS "name_en";
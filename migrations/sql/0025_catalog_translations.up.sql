@@ -0,0 +1,17 @@
+-- English and Arabic names and descriptions for categories and service options; the existing columns hold French
+
+ALTER TABLE "service_categories" ADD COLUMN IF NOT EXISTS "name_en" varchar(100) NOT NULL DEFAULT '';
+
+ALTER TABLE "service_categories" ADD COLUMN IF NOT EXISTS "name_ar" varchar(100) NOT NULL DEFAULT '';
+
+ALTER TABLE "service_categories" ADD COLUMN IF NOT EXISTS "description_en" text NOT NULL DEFAULT '';
+
+ALTER TABLE "service_categories" ADD COLUMN IF NOT EXISTS "description_ar" text NOT NULL DEFAULT '';
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "title_en" text NOT NULL DEFAULT '';
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "title_ar" text NOT NULL DEFAULT '';
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "description_en" text NOT NULL DEFAULT '';
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "description_ar" text NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "worker_skills";
+
+DROP INDEX IF EXISTS "idx_service_categories_parent_id";
+
+ALTER TABLE "service_categories" DROP CONSTRAINT IF EXISTS "fk_service_categories_subcategories";
+
+ALTER TABLE "service_categories" DROP COLUMN IF EXISTS "parent_id";
@@ -0,0 +1,13 @@
+-- Subcategories under a top-level parent, and extra categories workers are tagged for
+
+ALTER TABLE "service_categories" ADD COLUMN IF NOT EXISTS "parent_id" bigint;
+
+ALTER TABLE "service_categories" ADD CONSTRAINT "fk_service_categories_subcategories" FOREIGN KEY ("parent_id") REFERENCES "service_categories"("id");
+
+CREATE INDEX IF NOT EXISTS "idx_service_categories_parent_id" ON "service_categories" ("parent_id");
+
+CREATE TABLE "worker_skills" ("id" bigserial,"worker_id" bigint NOT NULL,"category_id" bigint NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_profiles_skill_tags" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"),CONSTRAINT "fk_worker_skills_category" FOREIGN KEY ("category_id") REFERENCES "service_categories"("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_worker_skills_worker_category" ON "worker_skills" ("worker_id","category_id");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_skills_category_id" ON "worker_skills" ("category_id");
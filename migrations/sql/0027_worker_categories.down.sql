@@ -0,0 +1,17 @@
+ALTER TABLE "worker_categories" DROP COLUMN IF EXISTS "verified_by";
+
+ALTER TABLE "worker_categories" DROP COLUMN IF EXISTS "verified_at";
+
+ALTER TABLE "worker_categories" RENAME CONSTRAINT "fk_worker_categories_category" TO "fk_worker_skills_category";
+
+ALTER TABLE "worker_categories" RENAME CONSTRAINT "fk_worker_profiles_categories" TO "fk_worker_profiles_skill_tags";
+
+ALTER INDEX "idx_worker_categories_category_id" RENAME TO "idx_worker_skills_category_id";
+
+ALTER INDEX "idx_worker_categories_worker_category" RENAME TO "idx_worker_skills_worker_category";
+
+ALTER SEQUENCE "worker_categories_id_seq" RENAME TO "worker_skills_id_seq";
+
+ALTER INDEX "worker_categories_pkey" RENAME TO "worker_skills_pkey";
+
+ALTER TABLE "worker_categories" RENAME TO "worker_skills";
@@ -0,0 +1,21 @@
+-- Worker skill tags become worker categories that admins verify one by one; existing tags stay in effect
+
+ALTER TABLE "worker_skills" RENAME TO "worker_categories";
+
+ALTER INDEX "worker_skills_pkey" RENAME TO "worker_categories_pkey";
+
+ALTER SEQUENCE "worker_skills_id_seq" RENAME TO "worker_categories_id_seq";
+
+ALTER INDEX "idx_worker_skills_worker_category" RENAME TO "idx_worker_categories_worker_category";
+
+ALTER INDEX "idx_worker_skills_category_id" RENAME TO "idx_worker_categories_category_id";
+
+ALTER TABLE "worker_categories" RENAME CONSTRAINT "fk_worker_profiles_skill_tags" TO "fk_worker_profiles_categories";
+
+ALTER TABLE "worker_categories" RENAME CONSTRAINT "fk_worker_skills_category" TO "fk_worker_categories_category";
+
+ALTER TABLE "worker_categories" ADD COLUMN "verified_at" timestamptz;
+
+ALTER TABLE "worker_categories" ADD COLUMN "verified_by" bigint;
+
+UPDATE "worker_categories" SET "verified_at" = COALESCE("created_at", NOW());
@@ -0,0 +1,11 @@
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "agreed_price";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "price_breakdown";
+
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "free_distance_km";
+
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "distance_fee_per_km";
+
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "urgent_surcharge_percent";
+
+ALTER TABLE "service_options" DROP COLUMN IF EXISTS "weekend_surcharge_percent";
@@ -0,0 +1,14 @@
+-- Admin-configurable price modifiers on service options, and the price breakdown stored on each
+-- request along with the price the worker agrees when starting the job
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "weekend_surcharge_percent" decimal(5,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "urgent_surcharge_percent" decimal(5,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "distance_fee_per_km" decimal(10,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE "service_options" ADD COLUMN IF NOT EXISTS "free_distance_km" decimal(6,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "price_breakdown" json;
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "agreed_price" decimal(10,2);
@@ -0,0 +1,5 @@
+-- Photos of past work shown on workers' public profiles
+
+CREATE TABLE "worker_portfolio_photos" ("id" bigserial,"worker_id" bigint NOT NULL,"url" varchar(500) NOT NULL,"public_id" varchar(255) NOT NULL DEFAULT '',"caption" varchar(200) NOT NULL DEFAULT '',"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_portfolio_photos_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_portfolio_photos_worker_id" ON "worker_portfolio_photos" ("worker_id");
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "idx_worker_profiles_onboarding_status";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "onboarding_updated_at";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "onboarding_note";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "onboarding_status";
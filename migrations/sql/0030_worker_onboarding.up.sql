@@ -0,0 +1,16 @@
+-- Onboarding status of workers; existing workers start where their profile and documents put them
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "onboarding_status" varchar(30) NOT NULL DEFAULT 'profile_incomplete';
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "onboarding_note" text;
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "onboarding_updated_at" timestamptz;
+
+UPDATE "worker_profiles" SET "onboarding_status" = CASE
+	WHEN "is_verified" THEN 'active'
+	WHEN COALESCE("experience", '') = '' OR COALESCE("skills", '') = '' OR COALESCE("profile_photo", '') = '' THEN 'profile_incomplete'
+	WHEN COALESCE("id_card_photo", '') = '' OR COALESCE("id_card_back_photo", '') = '' THEN 'documents_pending'
+	ELSE 'under_review'
+END;
+
+CREATE INDEX IF NOT EXISTS "idx_worker_profiles_onboarding_status" ON "worker_profiles" ("onboarding_status");
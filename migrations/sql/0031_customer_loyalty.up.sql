@@ -0,0 +1,5 @@
+-- Customer loyalty points, tiers and streaks, recomputed from completed requests
+
+CREATE TABLE "customer_loyalty" ("user_id" bigint,"points" bigint NOT NULL DEFAULT 0,"lifetime_points" bigint NOT NULL DEFAULT 0,"completed_requests" bigint NOT NULL DEFAULT 0,"tier" varchar(10) NOT NULL DEFAULT 'bronze',"streak_months" bigint NOT NULL DEFAULT 0,"tier_since" timestamptz,"computed_at" timestamptz,PRIMARY KEY ("user_id"),CONSTRAINT "fk_customer_loyalty_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_customer_loyalty_tier" ON "customer_loyalty" ("tier");
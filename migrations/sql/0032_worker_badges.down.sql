@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "worker_challenge_completions";
+
+DROP TABLE IF EXISTS "worker_badges";
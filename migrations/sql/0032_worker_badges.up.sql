@@ -0,0 +1,9 @@
+-- Worker badges and weekly challenge completions
+
+CREATE TABLE "worker_badges" ("id" bigserial,"worker_id" bigint NOT NULL,"badge" varchar(30) NOT NULL,"awarded_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_profiles_badges" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_worker_badges_worker_badge" ON "worker_badges" ("worker_id","badge");
+
+CREATE TABLE "worker_challenge_completions" ("id" bigserial,"worker_id" bigint NOT NULL,"challenge" varchar(30) NOT NULL,"week_start" date NOT NULL,"completed_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_challenge_completions_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_worker_challenge_completions_week" ON "worker_challenge_completions" ("worker_id","challenge","week_start");
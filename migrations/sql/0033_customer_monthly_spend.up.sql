@@ -0,0 +1,5 @@
+-- Pre-aggregated customer spend per month, category and worker, for the customer analytics dashboard
+
+CREATE TABLE "customer_monthly_spend" ("id" bigserial,"customer_id" bigint NOT NULL,"year" bigint NOT NULL,"month" bigint NOT NULL,"category_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"services" bigint,"spend" decimal(12,2),"tips" decimal(12,2),"computed_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_customer_monthly_spend" ON "customer_monthly_spend" ("customer_id","year","month","category_id","worker_id");
@@ -0,0 +1 @@
+ALTER TABLE "service_histories" DROP COLUMN IF EXISTS "commission_percent";
@@ -0,0 +1,3 @@
+-- Platform commission rate snapshotted on each completed service, for worker earnings statements
+
+ALTER TABLE "service_histories" ADD "commission_percent" decimal(5,2) NOT NULL DEFAULT 0;
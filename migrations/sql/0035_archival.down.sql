@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "archived_chat_messages";
+
+ALTER TABLE "chat_rooms" DROP COLUMN IF EXISTS "archived_at";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "archived_at";
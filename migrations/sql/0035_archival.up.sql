@@ -0,0 +1,13 @@
+-- Archival of old completed and cancelled requests: requests and chat rooms are flagged, their messages move to an archive table
+
+ALTER TABLE "customer_service_requests" ADD "archived_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "idx_customer_service_requests_archived_at" ON "customer_service_requests" ("archived_at");
+
+ALTER TABLE "chat_rooms" ADD "archived_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "idx_chat_rooms_archived_at" ON "chat_rooms" ("archived_at");
+
+CREATE TABLE "archived_chat_messages" ("id" bigint,"chat_room_id" bigint NOT NULL,"sender_id" bigint NOT NULL,"sender_type" text NOT NULL,"content" text NOT NULL,"message_text" text NOT NULL,"message_type" text DEFAULT 'text',"audio_url" text,"duration" bigint,"transcript" text,"transcript_status" varchar(20),"is_read" boolean DEFAULT false,"read_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,"archived_at" timestamptz NOT NULL,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_archived_chat_messages_room_created" ON "archived_chat_messages" ("chat_room_id","created_at");
@@ -0,0 +1,10 @@
+-- Regions with their own currency, time zone, default language, categories and commission, seeded with Mauritania as the default
+
+CREATE TABLE "regions" ("id" bigserial,"code" varchar(20) NOT NULL,"name" varchar(100) NOT NULL,"cities" json,"currency" varchar(3) NOT NULL,"timezone" varchar(64) NOT NULL,"default_language" varchar(5) NOT NULL,"supported_category_ids" json,"commission_percent" decimal(5,2),"is_default" boolean NOT NULL DEFAULT false,"is_active" boolean DEFAULT true,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_regions_code" ON "regions" ("code");
+
+CREATE INDEX IF NOT EXISTS "idx_regions_deleted_at" ON "regions" ("deleted_at");
+
+INSERT INTO "regions" ("code","name","cities","currency","timezone","default_language","supported_category_ids","is_default","is_active","created_at","updated_at") VALUES
+('MR', 'Mauritania', '["Nouakchott","Nouadhibou"]', 'MRU', 'Africa/Nouakchott', 'en', '[]', true, true, NOW(), NOW());
@@ -0,0 +1,25 @@
+ALTER TABLE "service_options" ALTER COLUMN "price" TYPE decimal;
+
+ALTER TABLE "worker_monthly_stats" ALTER COLUMN "average_earnings_per_job" TYPE decimal;
+
+ALTER TABLE "worker_monthly_stats" ALTER COLUMN "tips" TYPE decimal;
+
+ALTER TABLE "worker_monthly_stats" ALTER COLUMN "earnings" TYPE decimal;
+
+ALTER TABLE "worker_daily_stats" ALTER COLUMN "tips" TYPE decimal;
+
+ALTER TABLE "worker_daily_stats" ALTER COLUMN "earnings" TYPE decimal;
+
+ALTER TABLE "worker_stats" ALTER COLUMN "daily_tips" TYPE decimal;
+
+ALTER TABLE "worker_stats" ALTER COLUMN "monthly_tips" TYPE decimal;
+
+ALTER TABLE "worker_stats" ALTER COLUMN "total_tips" TYPE decimal;
+
+ALTER TABLE "worker_stats" ALTER COLUMN "average_earnings_per_job" TYPE decimal;
+
+ALTER TABLE "worker_stats" ALTER COLUMN "daily_earnings" TYPE decimal;
+
+ALTER TABLE "worker_stats" ALTER COLUMN "monthly_earnings" TYPE decimal;
+
+ALTER TABLE "worker_stats" ALTER COLUMN "total_earnings" TYPE decimal;
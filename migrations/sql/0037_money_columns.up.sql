@@ -0,0 +1,27 @@
+-- Money is kept in whole hundredths: round the unbounded analytics and catalog amounts to two decimals
+
+ALTER TABLE "worker_stats" ALTER COLUMN "total_earnings" TYPE decimal(12,2) USING round("total_earnings", 2);
+
+ALTER TABLE "worker_stats" ALTER COLUMN "monthly_earnings" TYPE decimal(12,2) USING round("monthly_earnings", 2);
+
+ALTER TABLE "worker_stats" ALTER COLUMN "daily_earnings" TYPE decimal(12,2) USING round("daily_earnings", 2);
+
+ALTER TABLE "worker_stats" ALTER COLUMN "average_earnings_per_job" TYPE decimal(12,2) USING round("average_earnings_per_job", 2);
+
+ALTER TABLE "worker_stats" ALTER COLUMN "total_tips" TYPE decimal(12,2) USING round("total_tips", 2);
+
+ALTER TABLE "worker_stats" ALTER COLUMN "monthly_tips" TYPE decimal(12,2) USING round("monthly_tips", 2);
+
+ALTER TABLE "worker_stats" ALTER COLUMN "daily_tips" TYPE decimal(12,2) USING round("daily_tips", 2);
+
+ALTER TABLE "worker_daily_stats" ALTER COLUMN "earnings" TYPE decimal(12,2) USING round("earnings", 2);
+
+ALTER TABLE "worker_daily_stats" ALTER COLUMN "tips" TYPE decimal(12,2) USING round("tips", 2);
+
+ALTER TABLE "worker_monthly_stats" ALTER COLUMN "earnings" TYPE decimal(12,2) USING round("earnings", 2);
+
+ALTER TABLE "worker_monthly_stats" ALTER COLUMN "tips" TYPE decimal(12,2) USING round("tips", 2);
+
+ALTER TABLE "worker_monthly_stats" ALTER COLUMN "average_earnings_per_job" TYPE decimal(12,2) USING round("average_earnings_per_job", 2);
+
+ALTER TABLE "service_options" ALTER COLUMN "price" TYPE decimal(10,2) USING round("price", 2);
@@ -0,0 +1 @@
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "scheduled_tz_offset";
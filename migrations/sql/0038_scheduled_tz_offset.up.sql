@@ -0,0 +1,3 @@
+-- Customer's UTC offset in minutes when they scheduled a request, so scheduled_for can be shown in their local time
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "scheduled_tz_offset" bigint;
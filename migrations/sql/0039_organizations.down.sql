@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "idx_customer_service_requests_organization_id";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "organization_id";
+
+DROP TABLE IF EXISTS "organization_members";
+
+DROP TABLE IF EXISTS "organizations";
@@ -0,0 +1,15 @@
+-- Organization accounts whose members book requests billed to them
+
+CREATE TABLE "organizations" ("id" bigserial,"name" varchar(200) NOT NULL,"billing_email" varchar(255),"tax_id" varchar(50),"created_by_id" bigint NOT NULL,"created_at" timestamptz,"updated_at" timestamptz,"deleted_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_organizations_deleted_at" ON "organizations" ("deleted_at");
+
+CREATE TABLE "organization_members" ("id" bigserial,"organization_id" bigint NOT NULL,"user_id" bigint NOT NULL,"role" varchar(20) NOT NULL DEFAULT 'member',"status" varchar(20) NOT NULL DEFAULT 'invited',"invited_by_id" bigint,"joined_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_organizations_members" FOREIGN KEY ("organization_id") REFERENCES "organizations"("id"),CONSTRAINT "fk_organization_members_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_organization_members_user" ON "organization_members" ("organization_id","user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_organization_members_user_id" ON "organization_members" ("user_id");
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "organization_id" bigint;
+
+CREATE INDEX IF NOT EXISTS "idx_customer_service_requests_organization_id" ON "customer_service_requests" ("organization_id");
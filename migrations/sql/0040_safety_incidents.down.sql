@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "safety_incidents";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "emergency_contact_phone";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "emergency_contact_name";
@@ -0,0 +1,11 @@
+-- SOS raised during jobs, and the emergency contact texted about them
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "emergency_contact_name" varchar(255);
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "emergency_contact_phone" varchar(20);
+
+CREATE TABLE "safety_incidents" ("id" bigserial,"service_request_id" bigint NOT NULL,"reported_by_id" bigint NOT NULL,"reporter_role" varchar(20) NOT NULL,"lat" decimal(10,8),"lng" decimal(11,8),"message" text,"previous_status" varchar(20) NOT NULL,"emergency_contact_notified" boolean NOT NULL DEFAULT false,"status" varchar(20) NOT NULL DEFAULT 'open',"resolution" varchar(20),"resolution_note" text,"resolved_by_id" bigint,"resolved_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_safety_incidents_service_request" FOREIGN KEY ("service_request_id") REFERENCES "customer_service_requests"("id"),CONSTRAINT "fk_safety_incidents_reported_by" FOREIGN KEY ("reported_by_id") REFERENCES "users"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_safety_incidents_service_request_id" ON "safety_incidents" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_safety_incidents_status" ON "safety_incidents" ("status");
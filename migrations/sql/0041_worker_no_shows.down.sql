@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "worker_no_shows";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "no_show_count";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "reliability_score";
+
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "accepted_at";
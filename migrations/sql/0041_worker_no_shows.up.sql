@@ -0,0 +1,13 @@
+-- Accepted workers who never turned up, and the reliability score they lose
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "accepted_at" timestamptz;
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "reliability_score" decimal(5,2) NOT NULL DEFAULT 100;
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "no_show_count" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "worker_no_shows" ("id" bigserial,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"stage" varchar(20) NOT NULL,"accepted_at" timestamptz,"penalty" decimal(5,2) NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_no_shows_service_request" FOREIGN KEY ("service_request_id") REFERENCES "customer_service_requests"("id"),CONSTRAINT "fk_worker_no_shows_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_no_shows_service_request_id" ON "worker_no_shows" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_no_shows_worker_id" ON "worker_no_shows" ("worker_id");
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "worker_reliability_events";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "suspension_reason";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "suspended_until";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "reliability_tier";
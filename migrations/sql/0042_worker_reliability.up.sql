@@ -0,0 +1,15 @@
+-- Reliability tiers, suspensions and the events that score them
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "reliability_tier" varchar(20) NOT NULL DEFAULT 'good';
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "suspended_until" timestamptz;
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "suspension_reason" text;
+
+CREATE TABLE "worker_reliability_events" ("id" bigserial,"worker_id" bigint NOT NULL,"service_request_id" bigint,"kind" varchar(30) NOT NULL,"points" decimal(5,2) NOT NULL,"note" text,"waived_at" timestamptz,"waived_by_id" bigint,"waive_note" text,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_worker_reliability_events_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_worker_reliability_events_worker_id" ON "worker_reliability_events" ("worker_id");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_reliability_events_service_request_id" ON "worker_reliability_events" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_reliability_events_created_at" ON "worker_reliability_events" ("created_at");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "call_events";
+
+DROP TABLE IF EXISTS "call_sessions";
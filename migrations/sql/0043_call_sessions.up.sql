@@ -0,0 +1,15 @@
+-- Masked-call sessions between customers and workers, and the calls placed through them
+
+CREATE TABLE "call_sessions" ("id" bigserial,"service_request_id" bigint NOT NULL,"worker_id" bigint NOT NULL,"provider" varchar(20) NOT NULL,"provider_session_id" varchar(64) NOT NULL,"customer_participant_id" varchar(64),"customer_proxy_number" varchar(20) NOT NULL,"worker_participant_id" varchar(64),"worker_proxy_number" varchar(20) NOT NULL,"status" varchar(20) NOT NULL DEFAULT 'active',"expires_at" timestamptz,"closed_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_call_sessions_service_request" FOREIGN KEY ("service_request_id") REFERENCES "customer_service_requests"("id"),CONSTRAINT "fk_call_sessions_worker" FOREIGN KEY ("worker_id") REFERENCES "worker_profiles"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_call_sessions_service_request_id" ON "call_sessions" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_call_sessions_provider_session_id" ON "call_sessions" ("provider_session_id");
+
+CREATE INDEX IF NOT EXISTS "idx_call_sessions_status" ON "call_sessions" ("status");
+
+CREATE TABLE "call_events" ("id" bigserial,"call_session_id" bigint NOT NULL,"service_request_id" bigint NOT NULL,"provider_call_id" varchar(64),"caller_role" varchar(20),"status" varchar(20) NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_call_events_call_session_id" ON "call_events" ("call_session_id");
+
+CREATE INDEX IF NOT EXISTS "idx_call_events_service_request_id" ON "call_events" ("service_request_id");
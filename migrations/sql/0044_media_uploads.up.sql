@@ -0,0 +1,7 @@
+-- Every file stored in Cloudinary with the user who uploaded it
+
+CREATE TABLE "media_uploads" ("id" bigserial,"owner_id" bigint NOT NULL,"purpose" varchar(30) NOT NULL,"mime_type" varchar(50) NOT NULL,"size" bigint NOT NULL,"url" text NOT NULL,"public_id" varchar(255) NOT NULL,"resource" varchar(10) NOT NULL,"scan_status" varchar(10) NOT NULL,"deleted_at" timestamptz,"created_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_media_uploads_owner" FOREIGN KEY ("owner_id") REFERENCES "users"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_media_uploads_owner_id" ON "media_uploads" ("owner_id");
+
+CREATE INDEX IF NOT EXISTS "idx_media_uploads_public_id" ON "media_uploads" ("public_id");
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "request_attachments";
+
+DROP TABLE IF EXISTS "upload_sessions";
+
+ALTER TABLE "worker_portfolio_photos" DROP COLUMN IF EXISTS "kind";
@@ -0,0 +1,17 @@
+-- Resumable video uploads straight to Cloudinary, portfolio videos and request attachments
+
+ALTER TABLE "worker_portfolio_photos" ADD COLUMN IF NOT EXISTS "kind" varchar(10) NOT NULL DEFAULT 'photo';
+
+CREATE TABLE "upload_sessions" ("id" bigserial,"owner_id" bigint NOT NULL,"purpose" varchar(30) NOT NULL,"service_request_id" bigint,"caption" varchar(200) NOT NULL DEFAULT '',"public_id" varchar(255) NOT NULL,"declared_size" bigint NOT NULL,"status" varchar(20) NOT NULL DEFAULT 'pending',"reject_reason" varchar(255),"media_upload_id" bigint,"expires_at" timestamptz,"completed_at" timestamptz,"created_at" timestamptz,"updated_at" timestamptz,PRIMARY KEY ("id"),CONSTRAINT "fk_upload_sessions_owner" FOREIGN KEY ("owner_id") REFERENCES "users"("id"),CONSTRAINT "fk_upload_sessions_media_upload" FOREIGN KEY ("media_upload_id") REFERENCES "media_uploads"("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_upload_sessions_owner_id" ON "upload_sessions" ("owner_id");
+
+CREATE INDEX IF NOT EXISTS "idx_upload_sessions_service_request_id" ON "upload_sessions" ("service_request_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_upload_sessions_public_id" ON "upload_sessions" ("public_id");
+
+CREATE INDEX IF NOT EXISTS "idx_upload_sessions_status" ON "upload_sessions" ("status");
+
+CREATE TABLE "request_attachments" ("id" bigserial,"service_request_id" bigint NOT NULL,"uploaded_by_id" bigint NOT NULL,"uploader_role" varchar(20) NOT NULL,"media_upload_id" bigint NOT NULL,"url" text NOT NULL,"created_at" timestamptz,PRIMARY KEY ("id"));
+
+CREATE INDEX IF NOT EXISTS "idx_request_attachments_service_request_id" ON "request_attachments" ("service_request_id");
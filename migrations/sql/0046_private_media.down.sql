@@ -0,0 +1,7 @@
+ALTER TABLE "request_line_items" DROP COLUMN IF EXISTS "receipt_media_id";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "id_card_back_media_id";
+
+ALTER TABLE "worker_profiles" DROP COLUMN IF EXISTS "id_card_media_id";
+
+ALTER TABLE "media_uploads" DROP COLUMN IF EXISTS "private";
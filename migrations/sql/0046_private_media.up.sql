@@ -0,0 +1,9 @@
+-- Private storage for ID card photos and receipts, referenced by upload instead of public URL
+
+ALTER TABLE "media_uploads" ADD COLUMN IF NOT EXISTS "private" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "id_card_media_id" bigint;
+
+ALTER TABLE "worker_profiles" ADD COLUMN IF NOT EXISTS "id_card_back_media_id" bigint;
+
+ALTER TABLE "request_line_items" ADD COLUMN IF NOT EXISTS "receipt_media_id" bigint;
@@ -0,0 +1,7 @@
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "message_text" text NOT NULL DEFAULT '';
+
+UPDATE "archived_chat_messages" SET "message_text" = "content";
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "message_text" text NOT NULL DEFAULT '';
+
+UPDATE "chat_messages" SET "message_text" = "content";
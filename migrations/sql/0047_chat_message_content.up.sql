@@ -0,0 +1,9 @@
+-- Chat messages keep their text in "content" only; "message_text" held a copy of it
+
+UPDATE "chat_messages" SET "content" = "message_text" WHERE "content" = '' AND "message_text" <> '';
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "message_text";
+
+UPDATE "archived_chat_messages" SET "content" = "message_text" WHERE "content" = '' AND "message_text" <> '';
+
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "message_text";
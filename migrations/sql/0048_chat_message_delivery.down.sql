@@ -0,0 +1,13 @@
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "is_read" boolean DEFAULT false;
+
+UPDATE "archived_chat_messages" SET "is_read" = "read_at" IS NOT NULL;
+
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "delivered_at";
+
+DROP INDEX IF EXISTS "idx_chat_messages_room_unread";
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "is_read" boolean DEFAULT false;
+
+UPDATE "chat_messages" SET "is_read" = "read_at" IS NOT NULL;
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "delivered_at";
@@ -0,0 +1,19 @@
+-- Chat messages record when their recipient received them; read_at replaces the is_read flag
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "delivered_at" timestamptz;
+
+UPDATE "chat_messages" SET "read_at" = COALESCE("read_at", "updated_at") WHERE "is_read";
+
+UPDATE "chat_messages" SET "delivered_at" = "read_at" WHERE "read_at" IS NOT NULL;
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "is_read";
+
+CREATE INDEX IF NOT EXISTS "idx_chat_messages_room_unread" ON "chat_messages" ("chat_room_id","sender_id") WHERE "read_at" IS NULL;
+
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "delivered_at" timestamptz;
+
+UPDATE "archived_chat_messages" SET "read_at" = COALESCE("read_at", "updated_at") WHERE "is_read";
+
+UPDATE "archived_chat_messages" SET "delivered_at" = "read_at" WHERE "read_at" IS NOT NULL;
+
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "is_read";
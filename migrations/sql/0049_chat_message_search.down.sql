@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_chat_messages_search_vector";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "search_vector";
@@ -0,0 +1,10 @@
+-- Full-text search over chat message text and voice transcripts, which are in French or Arabic
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (
+	setweight(to_tsvector('french', coalesce("content", '')), 'A') ||
+	setweight(to_tsvector('arabic', coalesce("content", '')), 'A') ||
+	setweight(to_tsvector('french', coalesce("transcript", '')), 'B') ||
+	setweight(to_tsvector('arabic', coalesce("transcript", '')), 'B')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS "idx_chat_messages_search_vector" ON "chat_messages" USING gin ("search_vector");
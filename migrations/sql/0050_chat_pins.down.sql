@@ -0,0 +1,9 @@
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "pinned_by";
+
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "pinned_at";
+
+DROP INDEX IF EXISTS "idx_chat_messages_pinned";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "pinned_by";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "pinned_at";
@@ -0,0 +1,11 @@
+-- Messages pinned to the top of their chat room
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "pinned_at" timestamptz;
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "pinned_by" bigint;
+
+CREATE INDEX IF NOT EXISTS "idx_chat_messages_pinned" ON "chat_messages" ("chat_room_id") WHERE "pinned_at" IS NOT NULL;
+
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "pinned_at" timestamptz;
+
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "pinned_by" bigint;
@@ -0,0 +1,17 @@
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "live_ended_at";
+
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "live_until";
+
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "longitude";
+
+ALTER TABLE "archived_chat_messages" DROP COLUMN IF EXISTS "latitude";
+
+DROP INDEX IF EXISTS "idx_chat_messages_live_until";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "live_ended_at";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "live_until";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "longitude";
+
+ALTER TABLE "chat_messages" DROP COLUMN IF EXISTS "latitude";
@@ -0,0 +1,19 @@
+-- Location messages in chat, either a static pin or a live share that ends at live_until
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "latitude" decimal(10,8);
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "longitude" decimal(11,8);
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "live_until" timestamptz;
+
+ALTER TABLE "chat_messages" ADD COLUMN IF NOT EXISTS "live_ended_at" timestamptz;
+
+CREATE INDEX IF NOT EXISTS "idx_chat_messages_live_until" ON "chat_messages" ("live_until") WHERE "live_until" IS NOT NULL AND "live_ended_at" IS NULL;
+
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "latitude" decimal(10,8);
+
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "longitude" decimal(11,8);
+
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "live_until" timestamptz;
+
+ALTER TABLE "archived_chat_messages" ADD COLUMN IF NOT EXISTS "live_ended_at" timestamptz;
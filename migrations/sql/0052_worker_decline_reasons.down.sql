@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_worker_responses_declines";
+
+ALTER TABLE "worker_responses" DROP COLUMN IF EXISTS "decline_reason";
@@ -0,0 +1,5 @@
+-- Why workers turn down service requests
+
+ALTER TABLE "worker_responses" ADD COLUMN IF NOT EXISTS "decline_reason" varchar(30);
+
+CREATE INDEX IF NOT EXISTS "idx_worker_responses_declines" ON "worker_responses" ("responded_at") WHERE "response" = 'decline';
@@ -0,0 +1 @@
+ALTER TABLE "service_categories" DROP COLUMN IF EXISTS "urgent_disabled";
@@ -0,0 +1,3 @@
+-- Categories in which customers cannot ask for urgent service
+
+ALTER TABLE "service_categories" ADD COLUMN IF NOT EXISTS "urgent_disabled" boolean NOT NULL DEFAULT false;
@@ -0,0 +1,3 @@
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "fulfilled_category_id";
+
+DROP TABLE IF EXISTS "request_categories";
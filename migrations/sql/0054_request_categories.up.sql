@@ -0,0 +1,16 @@
+-- Extra categories a request is broadcast to, and the category of the worker who took it
+
+CREATE TABLE IF NOT EXISTS "request_categories" (
+    "id" bigserial PRIMARY KEY,
+    "service_request_id" bigint NOT NULL REFERENCES "customer_service_requests" ("id") ON DELETE CASCADE,
+    "category_id" bigint NOT NULL REFERENCES "service_categories" ("id"),
+    "created_at" timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_request_categories_request_category" ON "request_categories" ("service_request_id", "category_id");
+
+CREATE INDEX IF NOT EXISTS "idx_request_categories_category_id" ON "request_categories" ("category_id");
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "fulfilled_category_id" bigint;
+
+CREATE INDEX IF NOT EXISTS "idx_customer_service_requests_fulfilled_category_id" ON "customer_service_requests" ("fulfilled_category_id");
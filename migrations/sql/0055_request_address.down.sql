@@ -0,0 +1 @@
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "address_id";
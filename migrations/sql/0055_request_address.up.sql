@@ -0,0 +1,3 @@
+-- Saved address a request's location was copied from
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "address_id" bigint;
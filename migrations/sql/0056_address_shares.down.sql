@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "address_share_accesses";
+
+DROP TABLE IF EXISTS "address_shares";
@@ -0,0 +1,28 @@
+-- Short-lived links to saved addresses, and every time one is opened
+
+CREATE TABLE IF NOT EXISTS "address_shares" (
+    "id" bigserial PRIMARY KEY,
+    "address_id" bigint NOT NULL REFERENCES "addresses" ("id") ON DELETE CASCADE,
+    "user_id" bigint NOT NULL,
+    "service_request_id" bigint,
+    "token_hash" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "revoked_at" timestamptz,
+    "access_count" bigint NOT NULL DEFAULT 0,
+    "last_accessed_at" timestamptz,
+    "created_at" timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_address_shares_token_hash" ON "address_shares" ("token_hash");
+
+CREATE INDEX IF NOT EXISTS "idx_address_shares_address_id" ON "address_shares" ("address_id");
+
+CREATE TABLE IF NOT EXISTS "address_share_accesses" (
+    "id" bigserial PRIMARY KEY,
+    "share_id" bigint NOT NULL REFERENCES "address_shares" ("id") ON DELETE CASCADE,
+    "ip_address" varchar(45),
+    "user_agent" varchar(500),
+    "accessed_at" timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS "idx_address_share_accesses_share_id" ON "address_share_accesses" ("share_id");
@@ -0,0 +1,3 @@
+ALTER TABLE "customer_service_requests" DROP COLUMN IF EXISTS "entrance_notes";
+
+ALTER TABLE "addresses" DROP COLUMN IF EXISTS "entrance_notes";
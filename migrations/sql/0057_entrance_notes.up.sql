@@ -0,0 +1,5 @@
+-- Directions to the way in, kept on saved addresses and copied onto requests
+
+ALTER TABLE "addresses" ADD COLUMN IF NOT EXISTS "entrance_notes" text;
+
+ALTER TABLE "customer_service_requests" ADD COLUMN IF NOT EXISTS "entrance_notes" text;
@@ -0,0 +1,7 @@
+ALTER TABLE "service_histories" ALTER COLUMN "estimated_duration" TYPE varchar(100) USING (
+    CASE WHEN "estimated_duration" IS NOT NULL THEN "estimated_duration"::text || 'm' END
+);
+
+ALTER TABLE "customer_service_requests" ALTER COLUMN "estimated_duration" TYPE varchar(100) USING (
+    CASE WHEN "estimated_duration" IS NOT NULL THEN "estimated_duration"::text || 'm' END
+);
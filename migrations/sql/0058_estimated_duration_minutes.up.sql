@@ -0,0 +1,29 @@
+-- Estimated durations become integer minutes, read the way duration.Parse reads legacy text: a
+-- number with an optional unit ("90", "45 min", "1.5 hours", "3 jours"), plain numbers being
+-- minutes, or hours and minutes ("1h30m"), rounded to the nearest minute with halves up. Other
+-- text, and durations that round to zero or do not fit in an integer, become NULL.
+
+CREATE FUNCTION "legacy_duration_minutes"(text) RETURNS integer LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE WHEN minutes >= 1 AND minutes <= 2147483647 THEN minutes::integer END
+    FROM (
+        SELECT CASE
+            WHEN value ~ '^[0-9]+(\.[0-9]+)? *(m|min|mins|minute|minutes|h|hr|hrs|hour|hours|heure|heures|d|day|days|j|jour|jours)?$' THEN round(
+                substring(value from '^[0-9]+(?:\.[0-9]+)?')::numeric * CASE
+                    WHEN substring(value from '[a-z]*$') IN ('h', 'hr', 'hrs', 'hour', 'hours', 'heure', 'heures') THEN 60
+                    WHEN substring(value from '[a-z]*$') IN ('d', 'day', 'days', 'j', 'jour', 'jours') THEN 1440
+                    ELSE 1
+                END
+            )
+            WHEN value ~ '^[0-9]+h[0-9]+m$' THEN substring(value from '^([0-9]+)h')::numeric * 60 + substring(value from 'h([0-9]+)m$')::numeric
+        END AS minutes
+        FROM (SELECT lower(btrim($1, E' \t\r\n')) AS value) AS input
+    ) AS parsed
+$$;
+
+ALTER TABLE "customer_service_requests" ALTER COLUMN "estimated_duration" TYPE integer
+    USING "legacy_duration_minutes"("estimated_duration");
+
+ALTER TABLE "service_histories" ALTER COLUMN "estimated_duration" TYPE integer
+    USING "legacy_duration_minutes"("estimated_duration");
+
+DROP FUNCTION "legacy_duration_minutes"(text);
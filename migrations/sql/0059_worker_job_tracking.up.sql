@@ -0,0 +1,29 @@
+-- Job events already applied to worker stats. Each event is recorded once per worker and request,
+-- so a redelivered event conflicts on the unique index and is not counted again.
+
+CREATE TABLE IF NOT EXISTS "worker_job_tracking" (
+    "id" bigserial PRIMARY KEY,
+    "worker_id" bigint NOT NULL,
+    "service_request_id" bigint NOT NULL,
+    "job_type" text NOT NULL,
+    "processed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz
+);
+
+-- Databases created by AutoMigrate may already hold the table, with duplicate events
+DELETE FROM "worker_job_tracking" t
+USING "worker_job_tracking" kept
+WHERE t."worker_id" = kept."worker_id"
+    AND t."service_request_id" = kept."service_request_id"
+    AND t."job_type" = kept."job_type"
+    AND t."id" > kept."id";
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_worker_job_tracking_event" ON "worker_job_tracking" ("worker_id", "service_request_id", "job_type");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_job_tracking_worker_id" ON "worker_job_tracking" ("worker_id");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_job_tracking_service_request_id" ON "worker_job_tracking" ("service_request_id");
+
+CREATE INDEX IF NOT EXISTS "idx_worker_job_tracking_deleted_at" ON "worker_job_tracking" ("deleted_at");
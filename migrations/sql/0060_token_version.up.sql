@@ -0,0 +1,3 @@
+-- Bumped when a user signs out everywhere, rejecting every token issued at an older version
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "token_version" bigint NOT NULL DEFAULT 0;
@@ -11,6 +11,7 @@ type Address struct {
 	Label          string    `json:"label" gorm:"size:50"` // e.g., "Maison", "Bureau"
 	AddressDetails string    `json:"address_details" gorm:"type:text;not null"`
 	City           string    `json:"city" gorm:"size:50;not null;default:'Nouakchott'"`
+	EntranceNotes  string    `json:"entrance_notes" gorm:"type:text"` // How to find the way in, for places without a street address
 	Latitude       float64   `json:"latitude" gorm:"type:decimal(10,8);not null"`
 	Longitude      float64   `json:"longitude" gorm:"type:decimal(11,8);not null"`
 	IsDefault      bool      `json:"is_default" gorm:"default:false"`
@@ -31,6 +32,7 @@ type AddressRequest struct {
 	Label          string  `json:"label" binding:"required"`
 	AddressDetails string  `json:"address_details" binding:"required"`
 	City           string  `json:"city" binding:"required"`
+	EntranceNotes  string  `json:"entrance_notes" binding:"max=500"`
 	Latitude       float64 `json:"latitude"` // Will be generated by backend geocoding
 	Longitude      float64 `json:"longitude"` // Will be generated by backend geocoding
 	IsDefault      bool    `json:"is_default"`
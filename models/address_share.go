@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// AddressShare is a short-lived link to a saved address that a customer sends a worker, for
+// places without a street address. Only a hash of the link's token is stored.
+type AddressShare struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	AddressID        uint       `json:"address_id" gorm:"not null;index"`
+	UserID           uint       `json:"user_id" gorm:"not null"`
+	ServiceRequestID *uint      `json:"service_request_id"` // The link stops working once this request is finished
+	TokenHash        string     `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt        time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	AccessCount      int        `json:"access_count" gorm:"not null;default:0"`
+	LastAccessedAt   *time.Time `json:"last_accessed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+
+	Address Address `json:"-" gorm:"foreignKey:AddressID"`
+}
+
+// TableName specifies the table name for AddressShare
+func (AddressShare) TableName() string {
+	return "address_shares"
+}
+
+// AddressShareAccess is one opening of an address share link
+type AddressShareAccess struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	ShareID    uint      `json:"share_id" gorm:"not null;index"`
+	IPAddress  string    `json:"ip_address" gorm:"size:45"`
+	UserAgent  string    `json:"user_agent" gorm:"size:500"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+// TableName specifies the table name for AddressShareAccess
+func (AddressShareAccess) TableName() string {
+	return "address_share_accesses"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// AIConversation is an AI assistant conversation that ended in a booking, linked to the
+// request it created
+type AIConversation struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	UserID           uint      `json:"user_id" gorm:"not null;index"`
+	ServiceRequestID uint      `json:"service_request_id" gorm:"not null;uniqueIndex"`
+	Transcript       string    `json:"transcript" gorm:"type:json"` // Messages exchanged before the booking
+	Draft            string    `json:"draft" gorm:"type:json"`      // The proposal the user accepted
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for AIConversation
+func (AIConversation) TableName() string {
+	return "ai_conversations"
+}
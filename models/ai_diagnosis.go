@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"repair-service-server/money"
+)
+
+// AIDiagnosis is the assistant's assessment of a photo of a problem. It is linked to the
+// service request the customer books for it, if any.
+type AIDiagnosis struct {
+	ID                  uint         `json:"id" gorm:"primaryKey"`
+	UserID              uint         `json:"user_id" gorm:"not null;index"`
+	ServiceRequestID    *uint        `json:"service_request_id" gorm:"index"`
+	Note                string       `json:"note" gorm:"type:text"` // What the customer said about the photo
+	Problem             string       `json:"problem" gorm:"type:text;not null"`
+	Severity            string       `json:"severity" gorm:"type:varchar(20);not null"` // low, medium, high or urgent
+	SuggestedCategoryID *uint        `json:"suggested_category_id"`
+	PriceMin            money.Amount `json:"price_min" gorm:"type:decimal(10,2)"` // Estimated cost in MRU
+	PriceMax            money.Amount `json:"price_max" gorm:"type:decimal(10,2)"`
+	Advice              string       `json:"advice" gorm:"type:text"` // What to do until the worker arrives
+	CreatedAt           time.Time    `json:"created_at"`
+}
+
+// TableName specifies the table name for AIDiagnosis
+func (AIDiagnosis) TableName() string {
+	return "ai_diagnoses"
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog is a persisted record of a security-relevant request: every mutation, every admin
+// request and every impersonated request. Before/After hold only the fields an admin mutation changed.
+type AuditLog struct {
+	ID             uint                   `json:"id" gorm:"primaryKey"`
+	RequestID      string                 `json:"request_id" gorm:"type:varchar(64);index"`
+	ActorID        *uint                  `json:"actor_id" gorm:"index"`
+	ActorRole      string                 `json:"actor_role" gorm:"type:varchar(20)"`
+	ImpersonatorID *uint                  `json:"impersonator_id" gorm:"index"`
+	Method         string                 `json:"method" gorm:"type:varchar(10);not null"`
+	Route          string                 `json:"route" gorm:"type:varchar(255);index"` // Route pattern, e.g. /api/v1/admin/users/:id
+	Path           string                 `json:"path" gorm:"type:varchar(500)"`
+	StatusCode     int                    `json:"status_code"`
+	EntityType     string                 `json:"entity_type" gorm:"type:varchar(50);index:idx_audit_entity"`
+	EntityID       string                 `json:"entity_id" gorm:"type:varchar(50);index:idx_audit_entity"`
+	Before         map[string]interface{} `json:"before,omitempty" gorm:"-"`
+	BeforeJSON     *string                `json:"-" gorm:"column:before;type:json"`
+	After          map[string]interface{} `json:"after,omitempty" gorm:"-"`
+	AfterJSON      *string                `json:"-" gorm:"column:after;type:json"`
+	IPAddress      string                 `json:"ip_address" gorm:"type:varchar(45)"`
+	UserAgent      string                 `json:"user_agent" gorm:"type:varchar(500)"`
+	DurationMs     int64                  `json:"duration_ms"`
+	CreatedAt      time.Time              `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for AuditLog
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeSave serializes the before/after diffs to their JSON columns
+func (a *AuditLog) BeforeSave(tx *gorm.DB) error {
+	a.BeforeJSON = marshalAuditDiff(a.Before)
+	a.AfterJSON = marshalAuditDiff(a.After)
+	return nil
+}
+
+// AfterFind deserializes the before/after diffs from their JSON columns
+func (a *AuditLog) AfterFind(tx *gorm.DB) error {
+	if a.BeforeJSON != nil {
+		json.Unmarshal([]byte(*a.BeforeJSON), &a.Before)
+	}
+	if a.AfterJSON != nil {
+		json.Unmarshal([]byte(*a.AfterJSON), &a.After)
+	}
+	return nil
+}
+
+func marshalAuditDiff(diff map[string]interface{}) *string {
+	if len(diff) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(diff)
+	if err != nil {
+		return nil
+	}
+	s := string(data)
+	return &s
+}
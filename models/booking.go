@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"repair-service-server/money"
 )
 
 type BookingStatus string
@@ -15,22 +17,22 @@ const (
 )
 
 type Booking struct {
-	ID          uint          `json:"id" gorm:"primaryKey"`
-	UserID      uint          `json:"user_id" gorm:"not null"`
-	ServiceID   uint          `json:"service_id" gorm:"not null"`
-	WorkerID    *uint         `json:"worker_id"` // Can be null initially
-	Status      BookingStatus `json:"status" gorm:"type:varchar(20);default:'pending';check:status IN ('pending','accepted','in_progress','completed','cancelled')"`
-	Address     string        `json:"address" gorm:"size:500;not null"`
-	Date        time.Time     `json:"date" gorm:"not null"`
-	Time        string        `json:"time" gorm:"size:20;not null"`
-	Notes       *string       `json:"notes" gorm:"size:1000"`
-	TotalPrice  float64       `json:"total_price" gorm:"type:decimal(10,2);not null"`
-	CreatedAt   time.Time     `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	ID         uint          `json:"id" gorm:"primaryKey"`
+	UserID     uint          `json:"user_id" gorm:"not null"`
+	ServiceID  uint          `json:"service_id" gorm:"not null"`
+	WorkerID   *uint         `json:"worker_id"` // Can be null initially
+	Status     BookingStatus `json:"status" gorm:"type:varchar(20);default:'pending';check:status IN ('pending','accepted','in_progress','completed','cancelled')"`
+	Address    string        `json:"address" gorm:"size:500;not null"`
+	Date       time.Time     `json:"date" gorm:"not null"`
+	Time       string        `json:"time" gorm:"size:20;not null"`
+	Notes      *string       `json:"notes" gorm:"size:1000"`
+	TotalPrice money.Amount  `json:"total_price" gorm:"type:decimal(10,2);not null"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// Relationships
-	User    User         `json:"user,omitempty" gorm:"foreignKey:UserID"`
-	Service Service      `json:"service,omitempty" gorm:"foreignKey:ServiceID"`
+	User    User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Service Service        `json:"service,omitempty" gorm:"foreignKey:ServiceID"`
 	Worker  *WorkerProfile `json:"worker,omitempty" gorm:"foreignKey:WorkerID"`
 }
 
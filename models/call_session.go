@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// CallSessionStatus says whether a masked-call session still connects the two parties
+type CallSessionStatus string
+
+const (
+	CallSessionActive CallSessionStatus = "active"
+	CallSessionClosed CallSessionStatus = "closed" // Proxy numbers handed back to the provider's pool
+)
+
+// CallSession is a masked-call session between a request's customer and its assigned worker. Each
+// party dials their own proxy number and the provider connects them without sharing real numbers.
+type CallSession struct {
+	ID                    uint                   `json:"id" gorm:"primaryKey"`
+	ServiceRequestID      uint                   `json:"service_request_id" gorm:"not null;index"`
+	ServiceRequest        CustomerServiceRequest `json:"-" gorm:"foreignKey:ServiceRequestID"`
+	WorkerID              uint                   `json:"worker_id" gorm:"not null"`
+	Worker                WorkerProfile          `json:"-" gorm:"foreignKey:WorkerID"`
+	Provider              string                 `json:"provider" gorm:"type:varchar(20);not null"`
+	ProviderSessionID     string                 `json:"-" gorm:"type:varchar(64);not null;index"`
+	CustomerParticipantID string                 `json:"-" gorm:"type:varchar(64)"`
+	CustomerProxyNumber   string                 `json:"customer_proxy_number" gorm:"type:varchar(20);not null"` // Number the customer dials
+	WorkerParticipantID   string                 `json:"-" gorm:"type:varchar(64)"`
+	WorkerProxyNumber     string                 `json:"worker_proxy_number" gorm:"type:varchar(20);not null"` // Number the worker dials
+	Status                CallSessionStatus      `json:"status" gorm:"type:varchar(20);not null;default:'active';index"`
+	ExpiresAt             time.Time              `json:"expires_at"`
+	ClosedAt              *time.Time             `json:"closed_at"`
+	CreatedAt             time.Time              `json:"created_at"`
+	UpdatedAt             time.Time              `json:"updated_at"`
+}
+
+// TableName specifies the table name for CallSession
+func (CallSession) TableName() string {
+	return "call_sessions"
+}
+
+// CallEvent logs a call placed through a masked-call session, or a party asking for their number
+type CallEvent struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	CallSessionID    uint      `json:"call_session_id" gorm:"not null;index"`
+	ServiceRequestID uint      `json:"service_request_id" gorm:"not null;index"`
+	ProviderCallID   string    `json:"provider_call_id" gorm:"type:varchar(64)"` // Empty for number requests
+	CallerRole       UserRole  `json:"caller_role" gorm:"type:varchar(20)"`
+	Status           string    `json:"status" gorm:"type:varchar(20);not null"` // "requested", or the provider's call status
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for CallEvent
+func (CallEvent) TableName() string {
+	return "call_events"
+}
@@ -2,8 +2,6 @@ package models
 
 import (
 	"time"
-
-	"gorm.io/gorm"
 )
 
 // ChatRoom represents a chat conversation between a customer and worker
@@ -22,26 +20,86 @@ type ChatRoom struct {
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 	DeletedAt         *time.Time `json:"deleted_at,omitempty" gorm:"index"`
+
+	ArchivedAt *time.Time `json:"archived_at,omitempty" gorm:"index"` // Set with the room's service request
 }
 
+// Transcript statuses of voice messages
+const (
+	TranscriptPending   = "pending"
+	TranscriptCompleted = "completed"
+	TranscriptFailed    = "failed"
+)
+
 // ChatMessage represents a single message in a chat room
 type ChatMessage struct {
 	ID         uint      `json:"id" gorm:"primaryKey"`
 	ChatRoomID uint      `json:"chat_room_id" gorm:"not null"`
 	SenderID   uint      `json:"sender_id" gorm:"not null"`
-	SenderType string    `json:"sender_type" gorm:"not null"` // "customer" or "worker"
-	Content    string    `json:"content" gorm:"type:text;not null"`
-	MessageText string   `json:"message_text" gorm:"type:text;not null"` // Alias for content
-	MessageType string   `json:"message_type" gorm:"default:text"` // "text", "image", "file", "voice"
+	SenderType string    `json:"sender_type" gorm:"not null"` // "customer", "worker" or "system"
+	Content    string    `json:"content" gorm:"type:text;not null"` // Text, or the URL of an image or file
+	MessageType string   `json:"message_type" gorm:"default:text"` // "text", "image", "file", "voice", "location"
 	AudioURL   string    `json:"audio_url"` // URL for voice messages
 	Duration   int       `json:"duration"` // Duration in seconds for voice messages
-	IsRead     bool      `json:"is_read" gorm:"default:false"`
+	Transcript string    `json:"transcript" gorm:"type:text"` // Speech-to-text of voice messages, filtered like text messages
+	TranscriptStatus string `json:"transcript_status" gorm:"type:varchar(20)"` // Empty when speech-to-text is not configured
+	// Rooms have two participants, so each message has one recipient whose progress it records
+	DeliveredAt *time.Time `json:"delivered_at"` // When the recipient's app received it
 	ReadAt     *time.Time `json:"read_at"`
+	PinnedAt   *time.Time `json:"pinned_at,omitempty"` // Set while the message is pinned to its room
+	PinnedBy   *uint      `json:"pinned_by,omitempty"`
+	// Location messages share a point; a live one follows the sender until LiveUntil
+	Latitude    *float64   `json:"latitude,omitempty" gorm:"type:decimal(10,8)"`
+	Longitude   *float64   `json:"longitude,omitempty" gorm:"type:decimal(11,8)"`
+	LiveUntil   *time.Time `json:"live_until,omitempty"`
+	LiveEndedAt *time.Time `json:"live_ended_at,omitempty"` // Set when the sender stops sharing or LiveUntil passes
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
 	DeletedAt  *time.Time `json:"deleted_at,omitempty" gorm:"index"`
 }
 
+// MessageTypeLocation is the message type of shared locations
+const MessageTypeLocation = "location"
+
+// System messages record request lifecycle events in the request's chat room. They have no
+// sender, so their SenderID is 0.
+const (
+	SenderTypeSystem  = "system"
+	MessageTypeSystem = "system"
+)
+
+// IsLiveAt reports whether the message is a live location still being shared at now
+func (m ChatMessage) IsLiveAt(now time.Time) bool {
+	return m.LiveUntil != nil && m.LiveEndedAt == nil && now.Before(*m.LiveUntil)
+}
+
+// MaxPinnedMessages caps how many messages a chat room can have pinned at once
+const MaxPinnedMessages = 10
+
+// Delivery states of a chat message
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+)
+
+// DeliveryStatus reports how far the message got with its recipient
+func (m ChatMessage) DeliveryStatus() string {
+	switch {
+	case m.ReadAt != nil:
+		return MessageStatusRead
+	case m.DeliveredAt != nil:
+		return MessageStatusDelivered
+	}
+	return MessageStatusSent
+}
+
+// ArchivedChatMessage is a message of an archived chat room, moved out of chat_messages
+type ArchivedChatMessage struct {
+	ChatMessage `gorm:"embedded"`
+	ArchivedAt  time.Time `json:"archived_at" gorm:"not null"`
+}
+
 // ChatNotification represents push notifications for chat messages
 type ChatNotification struct {
 	ID           uint      `json:"id" gorm:"primaryKey"`
@@ -85,6 +143,11 @@ func (ChatMessage) TableName() string {
 	return "chat_messages"
 }
 
+// TableName specifies the table name for ArchivedChatMessage
+func (ArchivedChatMessage) TableName() string {
+	return "archived_chat_messages"
+}
+
 // TableName specifies the table name for ChatNotification
 func (ChatNotification) TableName() string {
 	return "chat_notifications"
@@ -94,23 +157,3 @@ func (ChatNotification) TableName() string {
 func (UserDeviceToken) TableName() string {
 	return "user_device_tokens"
 }
-
-// BeforeSave hook to sync Content and MessageText fields
-func (m *ChatMessage) BeforeSave(tx *gorm.DB) error {
-	if m.Content != "" && m.MessageText == "" {
-		m.MessageText = m.Content
-	} else if m.MessageText != "" && m.Content == "" {
-		m.Content = m.MessageText
-	}
-	return nil
-}
-
-// AfterFind hook to sync Content and MessageText fields
-func (m *ChatMessage) AfterFind(tx *gorm.DB) error {
-	if m.Content != "" && m.MessageText == "" {
-		m.MessageText = m.Content
-	} else if m.MessageText != "" && m.Content == "" {
-		m.Content = m.MessageText
-	}
-	return nil
-}
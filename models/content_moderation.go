@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// ModerationTermKind is what a moderation term detects
+type ModerationTermKind string
+
+const (
+	ModerationTermProfanity   ModerationTermKind = "profanity"    // Masked wherever it appears as a word
+	ModerationTermPaymentBait ModerationTermKind = "payment_bait" // Phrases that lure users into paying off-platform; logged, not masked
+)
+
+// ModerationTerm is an admin-managed entry in the content moderation word lists
+type ModerationTerm struct {
+	ID        uint               `json:"id" gorm:"primaryKey"`
+	Term      string             `json:"term" gorm:"size:100;not null;uniqueIndex:idx_moderation_terms_kind_term"`
+	Kind      ModerationTermKind `json:"kind" gorm:"type:varchar(20);not null;uniqueIndex:idx_moderation_terms_kind_term"`
+	CreatedBy *uint              `json:"created_by"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// TableName specifies the table name for ModerationTerm
+func (ModerationTerm) TableName() string {
+	return "moderation_terms"
+}
+
+// ContentViolationKind is the rule a piece of user content broke
+type ContentViolationKind string
+
+const (
+	ViolationProfanity   ContentViolationKind = "profanity"
+	ViolationPhoneNumber ContentViolationKind = "phone_number"
+	ViolationEmail       ContentViolationKind = "email"
+	ViolationPaymentBait ContentViolationKind = "payment_bait"
+	ViolationAIFlagged   ContentViolationKind = "ai_flagged"
+)
+
+// ContentViolation records a rule broken by a chat message or review for admin review. The
+// original text is kept here only; the stored message or review has it masked.
+type ContentViolation struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	UserID       uint                 `json:"user_id" gorm:"not null;index"`
+	SourceType   string               `json:"source_type" gorm:"size:30;not null;index:idx_content_violations_source"` // chat_message, worker_rating, rating_reply or customer_rating
+	SourceID     uint                 `json:"source_id" gorm:"not null;index:idx_content_violations_source"`
+	Kind         ContentViolationKind `json:"kind" gorm:"type:varchar(20);not null;index"`
+	Detail       string               `json:"detail" gorm:"size:255"` // Matched terms, or the AI's category
+	OriginalText string               `json:"original_text" gorm:"type:text"`
+	FilteredText string               `json:"filtered_text" gorm:"type:text"`
+	ReviewedBy   *uint                `json:"reviewed_by"`
+	ReviewedAt   *time.Time           `json:"reviewed_at" gorm:"index"`
+	ReviewNote   string               `json:"review_note" gorm:"type:text"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+// TableName specifies the table name for ContentViolation
+func (ContentViolation) TableName() string {
+	return "content_violations"
+}
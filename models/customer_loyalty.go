@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// LoyaltyTier is a customer's loyalty tier
+type LoyaltyTier string
+
+// Loyalty tiers, from lowest to highest
+const (
+	LoyaltyBronze LoyaltyTier = "bronze"
+	LoyaltySilver LoyaltyTier = "silver"
+	LoyaltyGold   LoyaltyTier = "gold"
+)
+
+// CustomerLoyalty is a customer's loyalty points, tier and streak. It is recomputed from completed
+// requests after each completion and every night, so points that age out lower the tier.
+type CustomerLoyalty struct {
+	UserID            uint        `json:"user_id" gorm:"primaryKey;autoIncrement:false"`
+	Points            int         `json:"points" gorm:"not null;default:0"` // Earned over the last 12 months; sets the tier
+	LifetimePoints    int         `json:"lifetime_points" gorm:"not null;default:0"`
+	CompletedRequests int         `json:"completed_requests" gorm:"not null;default:0"`
+	Tier              LoyaltyTier `json:"tier" gorm:"type:varchar(10);not null;default:'bronze';index"`
+	StreakMonths      int         `json:"streak_months" gorm:"not null;default:0"` // Consecutive months, up to this one or the last, with a completed request
+	TierSince         time.Time   `json:"tier_since"`
+	ComputedAt        time.Time   `json:"computed_at"`
+}
+
+// TableName specifies the table name for CustomerLoyalty
+func (CustomerLoyalty) TableName() string {
+	return "customer_loyalty"
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// CustomerRating is a worker's rating of the customer after a completed job
+type CustomerRating struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint      `json:"service_request_id" gorm:"not null;uniqueIndex"`
+	CustomerID       uint      `json:"customer_id" gorm:"not null;index"`
+	WorkerID         uint      `json:"worker_id" gorm:"not null;index"`
+	Stars            int       `json:"stars" gorm:"not null;check:stars >= 1 AND stars <= 5"`
+	Punctual         int       `json:"punctual" gorm:"not null;check:punctual >= 1 AND punctual <= 5"`       // Was there and ready when the worker arrived
+	Respectful       int       `json:"respectful" gorm:"not null;check:respectful >= 1 AND respectful <= 5"` // Treated the worker with respect
+	PaidPromptly     int       `json:"paid_promptly" gorm:"not null;check:paid_promptly >= 1 AND paid_promptly <= 5"`
+	Comment          string    `json:"comment" gorm:"type:text"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// CustomerRatingCreate is the body a worker sends to rate a customer
+type CustomerRatingCreate struct {
+	Stars        int    `json:"stars" binding:"required,min=1,max=5"`
+	Punctual     int    `json:"punctual" binding:"required,min=1,max=5"`
+	Respectful   int    `json:"respectful" binding:"required,min=1,max=5"`
+	PaidPromptly int    `json:"paid_promptly" binding:"required,min=1,max=5"`
+	Comment      string `json:"comment" binding:"max=1000"`
+}
+
+// TableName specifies the table name for CustomerRating
+func (CustomerRating) TableName() string {
+	return "customer_ratings"
+}
+
+// AbuseReportStatus tracks an abuse report through admin review
+type AbuseReportStatus string
+
+const (
+	AbuseReportOpen      AbuseReportStatus = "open"
+	AbuseReportDismissed AbuseReportStatus = "dismissed"
+	AbuseReportActioned  AbuseReportStatus = "actioned"
+)
+
+// AbuseReport flags a customer for admin review after very low ratings from workers. A
+// customer has at most one open report; later low ratings are counted on it.
+type AbuseReport struct {
+	ID               uint              `json:"id" gorm:"primaryKey"`
+	CustomerID       uint              `json:"customer_id" gorm:"not null;index"`
+	CustomerRatingID uint              `json:"customer_rating_id" gorm:"not null"` // Rating that opened the report
+	Reason           string            `json:"reason" gorm:"size:255;not null"`
+	LowRatingCount   int               `json:"low_rating_count" gorm:"not null;default:1"`
+	CustomerScore    float64           `json:"customer_score" gorm:"type:decimal(3,2)"` // Score when the report was last updated
+	Status           AbuseReportStatus `json:"status" gorm:"type:varchar(20);not null;default:'open';index"`
+	ResolvedBy       *uint             `json:"resolved_by"`
+	ResolvedAt       *time.Time        `json:"resolved_at"`
+	ResolutionNote   string            `json:"resolution_note" gorm:"type:text"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+
+	Customer User `json:"customer,omitempty" gorm:"foreignKey:CustomerID"`
+}
+
+// TableName specifies the table name for AbuseReport
+func (AbuseReport) TableName() string {
+	return "abuse_reports"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"repair-service-server/money"
+)
+
+// CustomerMonthlySpend is a pre-aggregated row of what a customer spent in one month, category and
+// with one worker, rebuilt from their service history. Spend is the final price less refunds; tips
+// are counted separately.
+type CustomerMonthlySpend struct {
+	ID         uint         `json:"id" gorm:"primaryKey"`
+	CustomerID uint         `json:"customer_id" gorm:"not null;uniqueIndex:idx_customer_monthly_spend"`
+	Year       int          `json:"year" gorm:"not null;uniqueIndex:idx_customer_monthly_spend"`
+	Month      int          `json:"month" gorm:"not null;uniqueIndex:idx_customer_monthly_spend"`
+	CategoryID uint         `json:"category_id" gorm:"not null;uniqueIndex:idx_customer_monthly_spend"`
+	WorkerID   uint         `json:"worker_id" gorm:"not null;uniqueIndex:idx_customer_monthly_spend"`
+	Services   int          `json:"services"`
+	Spend      money.Amount `json:"spend" gorm:"type:decimal(12,2)"`
+	Tips       money.Amount `json:"tips" gorm:"type:decimal(12,2)"`
+	ComputedAt time.Time    `json:"computed_at"`
+}
+
+// TableName specifies the table name for CustomerMonthlySpend
+func (CustomerMonthlySpend) TableName() string {
+	return "customer_monthly_spend"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// DemandHeatmapCell is a pre-aggregated count of service requests per geohash cell and hour of day
+type DemandHeatmapCell struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CategoryID   uint      `json:"category_id" gorm:"not null;uniqueIndex:idx_heatmap_cell"`
+	Geohash      string    `json:"geohash" gorm:"type:varchar(12);not null;uniqueIndex:idx_heatmap_cell"`
+	HourOfDay    int       `json:"hour_of_day" gorm:"type:int;not null;uniqueIndex:idx_heatmap_cell"`
+	RequestCount int       `json:"request_count" gorm:"not null;default:0"`
+	CenterLat    float64   `json:"center_lat" gorm:"type:decimal(10,8)"`
+	CenterLng    float64   `json:"center_lng" gorm:"type:decimal(11,8)"`
+	WeeksCovered int       `json:"weeks_covered" gorm:"not null"`
+	ComputedAt   time.Time `json:"computed_at"`
+}
+
+// TableName specifies the table name for DemandHeatmapCell
+func (DemandHeatmapCell) TableName() string {
+	return "demand_heatmap_cells"
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// DispatchMode controls how a service request is matched with workers
+type DispatchMode string
+
+const (
+	DispatchModeBroadcast DispatchMode = "broadcast" // Every nearby worker sees the request
+	DispatchModeAuto      DispatchMode = "auto"      // The platform offers the request to one worker at a time
+)
+
+// DispatchOfferStatus represents the state of an auto-assignment offer
+type DispatchOfferStatus string
+
+const (
+	OfferStatusPending  DispatchOfferStatus = "pending"
+	OfferStatusAccepted DispatchOfferStatus = "accepted"
+	OfferStatusDeclined DispatchOfferStatus = "declined"
+	OfferStatusExpired  DispatchOfferStatus = "expired"
+)
+
+// DispatchOffer records an auto-assignment offer made to a single worker
+type DispatchOffer struct {
+	ID               uint                `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint                `json:"service_request_id" gorm:"not null;index"`
+	WorkerID         uint                `json:"worker_id" gorm:"not null;index"`
+	Rank             int                 `json:"rank" gorm:"not null"`
+	Score            float64             `json:"score" gorm:"type:decimal(6,4)"`
+	Distance         float64             `json:"distance" gorm:"type:decimal(8,2)"`
+	Status           DispatchOfferStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	ExpiresAt        time.Time           `json:"expires_at" gorm:"not null;index"`
+	RespondedAt      *time.Time          `json:"responded_at"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
+
+// TableName specifies the table name for DispatchOffer
+func (DispatchOffer) TableName() string {
+	return "dispatch_offers"
+}
@@ -0,0 +1,11 @@
+package models
+
+// DurationEstimate is how long a kind of job usually takes, learned from the actual durations
+// of completed jobs
+type DurationEstimate struct {
+	LowMinutes     int    `json:"low_minutes"`     // A quarter of the jobs were shorter
+	TypicalMinutes int    `json:"typical_minutes"` // Median
+	HighMinutes    int    `json:"high_minutes"`    // A quarter of the jobs were longer
+	SampleSize     int    `json:"sample_size"`
+	Basis          string `json:"basis"` // service_option, category or parent_category: what the jobs had in common
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// EmailVerificationPurpose says what an email code was sent for
+type EmailVerificationPurpose string
+
+const (
+	EmailVerificationConfirm       EmailVerificationPurpose = "email_confirm"  // Verify an address before it is attached to the account
+	EmailVerificationPasswordReset EmailVerificationPurpose = "password_reset" // Prove ownership of the account's address to set a new password
+)
+
+// EmailVerification is a one-time code sent by email. Only a hash of the code is stored.
+type EmailVerification struct {
+	ID         uint                     `json:"id" gorm:"primaryKey"`
+	UserID     uint                     `json:"user_id" gorm:"not null;index"`
+	Email      string                   `json:"email" gorm:"type:varchar(255);not null;index:idx_email_verifications_email_purpose,priority:1"`
+	Purpose    EmailVerificationPurpose `json:"purpose" gorm:"type:varchar(20);not null;index:idx_email_verifications_email_purpose,priority:2"`
+	CodeHash   string                   `json:"-" gorm:"type:varchar(64);not null"`
+	Attempts   int                      `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt  time.Time                `json:"expires_at" gorm:"not null;index"`
+	VerifiedAt *time.Time               `json:"verified_at"`
+	CreatedAt  time.Time                `json:"created_at"`
+}
+
+// TableName specifies the table name for EmailVerification
+func (EmailVerification) TableName() string {
+	return "email_verifications"
+}
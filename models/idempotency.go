@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// IdempotencyKey records the response to a mutating request sent with an Idempotency-Key header,
+// so a client retry with the same key replays it instead of repeating the write.
+type IdempotencyKey struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	UserID      uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_idempotency_user_key,priority:1"`
+	Key         string     `json:"key" gorm:"type:varchar(255);not null;uniqueIndex:idx_idempotency_user_key,priority:2"`
+	Method      string     `json:"method" gorm:"type:varchar(10);not null"`
+	Path        string     `json:"path" gorm:"type:varchar(255);not null"`
+	RequestHash string     `json:"request_hash" gorm:"type:varchar(64);not null"`
+	StatusCode  int        `json:"status_code"`
+	ContentType string     `json:"content_type" gorm:"type:varchar(100)"`
+	Response    []byte     `json:"-" gorm:"type:bytea"`
+	CompletedAt *time.Time `json:"completed_at"` // Nil while the original request is still running
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for IdempotencyKey
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// JobCodeStage is the step of a job a code confirms
+type JobCodeStage string
+
+const (
+	JobCodeStart      JobCodeStage = "start"      // Entered by the worker to start work
+	JobCodeCompletion JobCodeStage = "completion" // Entered by the worker to complete work
+)
+
+// JobCode is a short code shown to the customer that the worker must enter to start or complete a
+// job, proving they are on site with the customer
+type JobCode struct {
+	ID               uint         `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint         `json:"service_request_id" gorm:"not null;uniqueIndex:idx_job_codes_request_stage"`
+	Stage            JobCodeStage `json:"stage" gorm:"type:varchar(20);not null;uniqueIndex:idx_job_codes_request_stage"`
+	Code             string       `json:"-" gorm:"type:varchar(4);not null"`
+	Attempts         int          `json:"attempts" gorm:"not null;default:0"`
+	VerifiedAt       *time.Time   `json:"verified_at"`
+	OverriddenBy     *uint        `json:"overridden_by"` // Admin who confirmed the stage without the code
+	OverrideReason   string       `json:"override_reason,omitempty" gorm:"type:text"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+// TableName specifies the table name for JobCode
+func (JobCode) TableName() string {
+	return "job_codes"
+}
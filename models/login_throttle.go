@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// LoginThrottle counts recent failed sign-ins for one phone number or client IP. Key is
+// "phone:<E.164>" or "ip:<address>".
+type LoginThrottle struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	Key           string     `json:"key" gorm:"type:varchar(80);not null;uniqueIndex"`
+	Failures      int        `json:"failures" gorm:"not null;default:0"`
+	LastFailureAt time.Time  `json:"last_failure_at" gorm:"not null;index"`
+	LockedUntil   *time.Time `json:"locked_until"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for LoginThrottle
+func (LoginThrottle) TableName() string {
+	return "login_throttles"
+}
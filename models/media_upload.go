@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// MediaPurpose says what a file was uploaded for, which sets the types and size it may have
+type MediaPurpose string
+
+const (
+	MediaPurposeProfilePhoto   MediaPurpose = "profile_photo"
+	MediaPurposeIDCard         MediaPurpose = "id_card"
+	MediaPurposePortfolioPhoto MediaPurpose = "portfolio_photo"
+	MediaPurposeReceiptPhoto   MediaPurpose = "receipt_photo"
+	MediaPurposeVoiceMessage   MediaPurpose = "voice_message"
+	MediaPurposePortfolioVideo MediaPurpose = "portfolio_video" // Uploaded straight to Cloudinary in chunks
+	MediaPurposeRequestVideo   MediaPurpose = "request_video"   // Uploaded straight to Cloudinary in chunks
+)
+
+// IsPrivate reports whether files for the purpose are sensitive documents, stored privately and
+// only shown through short-lived signed URLs
+func (p MediaPurpose) IsPrivate() bool {
+	return p == MediaPurposeIDCard || p == MediaPurposeReceiptPhoto
+}
+
+// MediaScanStatus is the outcome of the malware scan run before a file is stored
+type MediaScanStatus string
+
+const (
+	MediaScanClean   MediaScanStatus = "clean"
+	MediaScanSkipped MediaScanStatus = "skipped" // No scanner configured, or uploaded straight to Cloudinary
+)
+
+// MediaUpload records every file stored in Cloudinary with the user who uploaded it, so uploads
+// can be found and removed later
+type MediaUpload struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	OwnerID    uint            `json:"owner_id" gorm:"not null;index"`
+	Owner      User            `json:"-" gorm:"foreignKey:OwnerID"`
+	Purpose    MediaPurpose    `json:"purpose" gorm:"type:varchar(30);not null"`
+	MIMEType   string          `json:"mime_type" gorm:"type:varchar(50);not null"` // Sniffed from the content, not the file name
+	Size       int64           `json:"size" gorm:"not null"`                       // Bytes stored, after metadata stripping
+	URL        string          `json:"url" gorm:"type:text;not null"`              // Only works for public uploads
+	PublicID   string          `json:"-" gorm:"type:varchar(255);not null;index"`  // Cloudinary public ID, to delete the upload
+	Resource   string          `json:"-" gorm:"type:varchar(10);not null"`         // Cloudinary resource type
+	ScanStatus MediaScanStatus `json:"scan_status" gorm:"type:varchar(10);not null"`
+	Private    bool            `json:"private" gorm:"not null;default:false"` // Stored with Cloudinary's private delivery type
+	DeletedAt  *time.Time      `json:"deleted_at"`                            // Set once the file is removed from Cloudinary
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// TableName specifies the table name for MediaUpload
+func (MediaUpload) TableName() string {
+	return "media_uploads"
+}
@@ -11,7 +11,7 @@ type Notification struct {
 	UserID    uint           `json:"user_id" gorm:"not null"`
 	Title     string         `json:"title" gorm:"not null"`
 	Body      string         `json:"body" gorm:"not null"`
-	Type      string         `json:"type" gorm:"not null"` // booking_created, booking_accepted, booking_in_progress, booking_completed, booking_cancelled, worker_assigned, payment_received, promotion, system
+	Type      string         `json:"type" gorm:"not null"` // booking_created, booking_accepted, booking_en_route, booking_arrived, booking_in_progress, booking_completed, booking_cancelled, worker_assigned, payment_received, promotion, system
 	Data      string         `json:"data" gorm:"type:text"` // JSON data
 	Read      bool           `json:"read" gorm:"default:false"`
 	CreatedAt time.Time      `json:"created_at"`
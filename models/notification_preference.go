@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationChannel is how a user prefers to receive notifications
+type NotificationChannel string
+
+const (
+	NotificationChannelPush  NotificationChannel = "push"   // Push notification plus the in-app inbox
+	NotificationChannelSMS   NotificationChannel = "sms"    // SMS plus the in-app inbox; promotions stay in-app only
+	NotificationChannelInApp NotificationChannel = "in_app" // In-app inbox only
+	NotificationChannelEmail NotificationChannel = "email"  // Email to the verified address plus the in-app inbox; promotions stay in-app only
+)
+
+// NotificationCategory groups notification types for per-user toggles
+type NotificationCategory string
+
+const (
+	NotificationCategoryChat          NotificationCategory = "chat"
+	NotificationCategoryStatusUpdates NotificationCategory = "status_updates"
+	NotificationCategoryPromotions    NotificationCategory = "promotions"
+	NotificationCategorySecurity      NotificationCategory = "security" // Always delivered
+)
+
+// DefaultNotificationTimezone is used for quiet hours until the user picks one
+const DefaultNotificationTimezone = "Africa/Nouakchott"
+
+// NotificationPreference holds a user's notification toggles and quiet hours. Users without a
+// row get DefaultNotificationPreference.
+type NotificationPreference struct {
+	ID                   uint                `json:"-" gorm:"primaryKey"`
+	UserID               uint                `json:"user_id" gorm:"not null;uniqueIndex"`
+	ChatEnabled          bool                `json:"chat_enabled" gorm:"not null;default:true"`
+	StatusUpdatesEnabled bool                `json:"status_updates_enabled" gorm:"not null;default:true"`
+	PromotionsEnabled    bool                `json:"promotions_enabled" gorm:"not null;default:true"`
+	QuietHoursEnabled    bool                `json:"quiet_hours_enabled" gorm:"not null;default:false"`
+	QuietHoursStart      string              `json:"quiet_hours_start" gorm:"type:varchar(5);not null;default:'22:00'"` // HH:MM local time
+	QuietHoursEnd        string              `json:"quiet_hours_end" gorm:"type:varchar(5);not null;default:'07:00'"`   // HH:MM local time
+	Timezone             string              `json:"timezone" gorm:"type:varchar(64);not null;default:'Africa/Nouakchott'"`
+	PreferredChannel     NotificationChannel `json:"preferred_channel" gorm:"type:varchar(10);not null;default:'push'"`
+	CreatedAt            time.Time           `json:"created_at"`
+	UpdatedAt            time.Time           `json:"updated_at"`
+}
+
+// TableName specifies the table name for NotificationPreference
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// DefaultNotificationPreference returns the preferences of a user who never changed them
+func DefaultNotificationPreference(userID uint) NotificationPreference {
+	return NotificationPreference{
+		UserID:               userID,
+		ChatEnabled:          true,
+		StatusUpdatesEnabled: true,
+		PromotionsEnabled:    true,
+		QuietHoursStart:      "22:00",
+		QuietHoursEnd:        "07:00",
+		Timezone:             DefaultNotificationTimezone,
+		PreferredChannel:     NotificationChannelPush,
+	}
+}
+
+// CategoryEnabled reports whether the user wants notifications of category
+func (p *NotificationPreference) CategoryEnabled(category NotificationCategory) bool {
+	switch category {
+	case NotificationCategoryChat:
+		return p.ChatEnabled
+	case NotificationCategoryStatusUpdates:
+		return p.StatusUpdatesEnabled
+	case NotificationCategoryPromotions:
+		return p.PromotionsEnabled
+	}
+	return true
+}
+
+// QuietUntil returns when the quiet hours containing now end, or nil outside quiet hours.
+// A window whose start is after its end spans midnight.
+func (p *NotificationPreference) QuietUntil(now time.Time) *time.Time {
+	if !p.QuietHoursEnabled {
+		return nil
+	}
+	start, errStart := ParseClock(p.QuietHoursStart)
+	end, errEnd := ParseClock(p.QuietHoursEnd)
+	if errStart != nil || errEnd != nil || start == end {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+	var until time.Time
+	switch {
+	case start < end && minute >= start && minute < end:
+		until = midnight.Add(time.Duration(end) * time.Minute)
+	case start > end && minute >= start:
+		until = midnight.AddDate(0, 0, 1).Add(time.Duration(end) * time.Minute)
+	case start > end && minute < end:
+		until = midnight.Add(time.Duration(end) * time.Minute)
+	default:
+		return nil
+	}
+	return &until
+}
+
+// ParseClock parses HH:MM into minutes after midnight
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
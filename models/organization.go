@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OrganizationRole is what a member may do in an organization
+type OrganizationRole string
+
+const (
+	// OrganizationRoleOwner manages members and sees every request, invoice and analytics of the organization
+	OrganizationRoleOwner OrganizationRole = "owner"
+	// OrganizationRoleMember creates requests billed to the organization and sees their own
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+// OrganizationMemberStatus tracks an invitation until the invited user accepts it
+type OrganizationMemberStatus string
+
+const (
+	OrganizationMemberInvited OrganizationMemberStatus = "invited"
+	OrganizationMemberActive  OrganizationMemberStatus = "active"
+)
+
+// Organization is a company account whose members book repairs billed to it
+type Organization struct {
+	ID           uint                 `json:"id" gorm:"primaryKey"`
+	Name         string               `json:"name" gorm:"type:varchar(200);not null"`
+	BillingEmail *string              `json:"billing_email" gorm:"type:varchar(255)"`
+	TaxID        string               `json:"tax_id" gorm:"type:varchar(50)"` // Printed on invoices
+	CreatedByID  uint                 `json:"created_by_id" gorm:"not null"`
+	Members      []OrganizationMember `json:"members,omitempty" gorm:"foreignKey:OrganizationID"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt       `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// TableName specifies the table name for Organization
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// OrganizationMember is a user's membership of an organization, or their pending invitation to it
+type OrganizationMember struct {
+	ID             uint                     `json:"id" gorm:"primaryKey"`
+	OrganizationID uint                     `json:"organization_id" gorm:"not null;uniqueIndex:idx_organization_members_user"`
+	UserID         uint                     `json:"user_id" gorm:"not null;uniqueIndex:idx_organization_members_user;index"`
+	User           User                     `json:"user" gorm:"foreignKey:UserID"`
+	Organization   *Organization            `json:"organization,omitempty" gorm:"foreignKey:OrganizationID"`
+	Role           OrganizationRole         `json:"role" gorm:"type:varchar(20);not null;default:'member'"`
+	Status         OrganizationMemberStatus `json:"status" gorm:"type:varchar(20);not null;default:'invited'"`
+	InvitedByID    *uint                    `json:"invited_by_id"`
+	JoinedAt       *time.Time               `json:"joined_at"` // Set when the invitation is accepted
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// TableName specifies the table name for OrganizationMember
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// IsOwner reports whether the membership is active with the owner role
+func (m OrganizationMember) IsOwner() bool {
+	return m.Status == OrganizationMemberActive && m.Role == OrganizationRoleOwner
+}
+
+// OrganizationRequest represents the request structure for creating/updating organizations
+type OrganizationRequest struct {
+	Name         string  `json:"name" binding:"required,max=200"`
+	BillingEmail *string `json:"billing_email" binding:"omitempty,email,max=255"`
+	TaxID        string  `json:"tax_id" binding:"max=50"`
+}
+
+// OrganizationInviteRequest invites an existing user by phone number or verified email
+type OrganizationInviteRequest struct {
+	PhoneNumber string           `json:"phone_number" binding:"required_without=Email,omitempty,phone"`
+	Email       string           `json:"email" binding:"required_without=PhoneNumber,omitempty,email"`
+	Role        OrganizationRole `json:"role" binding:"omitempty,oneof=owner member"`
+}
+
+// OrganizationMemberUpdate changes a member's role
+type OrganizationMemberUpdate struct {
+	Role OrganizationRole `json:"role" binding:"required,oneof=owner member"`
+}
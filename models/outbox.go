@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"repair-service-server/money"
+)
+
+// OutboxStatus tracks delivery of an outbox event
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusProcessed OutboxStatus = "processed"
+	OutboxStatusFailed    OutboxStatus = "failed" // Gave up after too many attempts
+)
+
+// Outbox event types
+const (
+	OutboxEventJobCompletionAnalytics = "job_completion_analytics"
+	OutboxEventServiceStatusNotify    = "service_status_notification"
+	OutboxEventPushNotification       = "push_notification"
+	OutboxEventEmail                  = "email"
+	OutboxEventTipAnalytics           = "tip_analytics"
+	OutboxEventSMS                    = "sms"
+)
+
+// OutboxEvent is a side effect recorded in the same transaction as the write that caused it and
+// delivered afterwards by the outbox worker, so a crash never loses or half-applies it.
+type OutboxEvent struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	EventType   string       `json:"event_type" gorm:"type:varchar(50);not null"`
+	AggregateID uint         `json:"aggregate_id" gorm:"index"`
+	Payload     string       `json:"payload" gorm:"type:json;not null"`
+	Status      OutboxStatus `json:"status" gorm:"type:varchar(20);default:'pending';index:idx_outbox_pending,priority:1"`
+	Attempts    int          `json:"attempts" gorm:"default:0"`
+	LastError   string       `json:"last_error" gorm:"type:text"`
+	AvailableAt time.Time    `json:"available_at" gorm:"not null;index:idx_outbox_pending,priority:2"`
+	ProcessedAt *time.Time   `json:"processed_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+// TableName specifies the table name for OutboxEvent
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// Decode unmarshals the event payload into v
+func (e *OutboxEvent) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(e.Payload), v)
+}
+
+// JobCompletionAnalyticsPayload is the payload of OutboxEventJobCompletionAnalytics
+type JobCompletionAnalyticsPayload struct {
+	WorkerID         uint         `json:"worker_id"`
+	ServiceRequestID uint         `json:"service_request_id"`
+	Earnings         money.Amount `json:"earnings"`
+	WorkHours        float64      `json:"work_hours"`
+}
+
+// ServiceStatusNotifyPayload is the payload of OutboxEventServiceStatusNotify
+type ServiceStatusNotifyPayload struct {
+	CustomerID       uint   `json:"customer_id"`
+	ServiceRequestID uint   `json:"service_request_id"`
+	Status           string `json:"status"`
+}
+
+// PushNotificationPayload is the payload of OutboxEventPushNotification. With Message set, the
+// title and body are that catalog message filled from Vars, written in the user's language when
+// it is delivered; otherwise Title and Body are sent as they are.
+type PushNotificationPayload struct {
+	UserID  uint                   `json:"user_id"`
+	Title   string                 `json:"title,omitempty"`
+	Body    string                 `json:"body,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	Vars    map[string]interface{} `json:"vars,omitempty"`
+	Type    string                 `json:"type"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// EmailPayload is the payload of OutboxEventEmail. The email goes to the user's verified address,
+// if they have one.
+type EmailPayload struct {
+	UserID   uint                   `json:"user_id"`
+	Template string                 `json:"template"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// SMSPayload is the payload of OutboxEventSMS, a text to a number that may not belong to any user
+type SMSPayload struct {
+	UserID           *uint      `json:"user_id,omitempty"` // Account the text is sent for, if any
+	To               string     `json:"to"`
+	Body             string     `json:"body"`
+	Purpose          SMSPurpose `json:"purpose"`
+	NotificationType string     `json:"notification_type"`
+}
+
+// TipAnalyticsPayload is the payload of OutboxEventTipAnalytics
+type TipAnalyticsPayload struct {
+	WorkerID         uint         `json:"worker_id"`
+	ServiceRequestID uint         `json:"service_request_id"`
+	Amount           money.Amount `json:"amount"`
+}
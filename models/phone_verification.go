@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// PhoneVerificationPurpose says what a one-time code was sent for
+type PhoneVerificationPurpose string
+
+const (
+	PhoneVerificationSignup PhoneVerificationPurpose = "signup"       // Verify the number an account registers or already uses
+	PhoneVerificationChange PhoneVerificationPurpose = "phone_change" // Verify a new number before it replaces the current one
+)
+
+// PhoneVerification is a one-time code sent by SMS. Only a hash of the code is stored.
+type PhoneVerification struct {
+	ID          uint                     `json:"id" gorm:"primaryKey"`
+	UserID      *uint                    `json:"user_id" gorm:"index"` // Nil for codes sent before signup
+	PhoneNumber string                   `json:"phone_number" gorm:"type:varchar(20);not null;index:idx_phone_verifications_phone_purpose,priority:1"`
+	Purpose     PhoneVerificationPurpose `json:"purpose" gorm:"type:varchar(20);not null;index:idx_phone_verifications_phone_purpose,priority:2"`
+	CodeHash    string                   `json:"-" gorm:"type:varchar(64);not null"`
+	Attempts    int                      `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt   time.Time                `json:"expires_at" gorm:"not null;index"`
+	VerifiedAt  *time.Time               `json:"verified_at"`
+	CreatedAt   time.Time                `json:"created_at"`
+}
+
+// TableName specifies the table name for PhoneVerification
+func (PhoneVerification) TableName() string {
+	return "phone_verifications"
+}
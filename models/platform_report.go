@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"repair-service-server/money"
+)
+
+// PlatformDailyMetric is a pre-aggregated row of platform activity for one day, category and city.
+// Request counts are attributed to the day the request was created; money is attributed to the
+// day the service was completed (GMV) or the day a refund was issued (refunds).
+type PlatformDailyMetric struct {
+	ID                 uint         `json:"id" gorm:"primaryKey"`
+	Date               time.Time    `json:"date" gorm:"type:date;not null;uniqueIndex:idx_platform_metric"`
+	CategoryID         uint         `json:"category_id" gorm:"not null;uniqueIndex:idx_platform_metric"`
+	City               string       `json:"city" gorm:"type:varchar(100);not null;uniqueIndex:idx_platform_metric"`
+	RequestsCreated    int          `json:"requests_created"`
+	RequestsAccepted   int          `json:"requests_accepted"`
+	RequestsCompleted  int          `json:"requests_completed"`
+	RequestsCancelled  int          `json:"requests_cancelled"`
+	TotalAcceptMinutes float64      `json:"total_accept_minutes"` // Sum of created -> accepted delays
+	GMV                money.Amount `json:"gmv" gorm:"column:gmv;type:decimal(12,2)"`
+	Refunds            money.Amount `json:"refunds" gorm:"type:decimal(12,2)"`
+	ComputedAt         time.Time    `json:"computed_at"`
+}
+
+// TableName specifies the table name for PlatformDailyMetric
+func (PlatformDailyMetric) TableName() string {
+	return "platform_daily_metrics"
+}
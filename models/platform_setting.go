@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PlatformSetting is an admin-editable setting stored as text under a well-known key
+type PlatformSetting struct {
+	Key       string    `json:"key" gorm:"primaryKey;type:varchar(100)"`
+	Value     string    `json:"value" gorm:"type:text;not null"`
+	UpdatedBy *uint     `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for PlatformSetting
+func (PlatformSetting) TableName() string {
+	return "platform_settings"
+}
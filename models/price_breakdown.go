@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"repair-service-server/money"
+)
+
+// Price modifier types
+const (
+	PriceModifierWeekend   = "weekend_surcharge"
+	PriceModifierUrgent    = "urgent_surcharge"
+	PriceModifierZoneSurge = "zone_surge"
+	PriceModifierDistance  = "distance_fee"
+	PriceModifierLoyalty   = "loyalty_discount"
+)
+
+// PriceModifier is one surcharge, fee or discount applied to a service option's price. Discounts
+// have a negative amount.
+type PriceModifier struct {
+	Type       string       `json:"type"`
+	Percent    float64      `json:"percent,omitempty"`     // Percentage of the base price, for surcharges
+	DistanceKm float64      `json:"distance_km,omitempty"` // Billed distance, for the distance fee
+	Amount     money.Amount `json:"amount"`
+}
+
+// PriceBreakdown is how a request's price was computed from its service option
+type PriceBreakdown struct {
+	ServiceOptionID uint            `json:"service_option_id"`
+	BasePrice       money.Amount    `json:"base_price"`
+	Modifiers       []PriceModifier `json:"modifiers"`
+	Total           money.Amount    `json:"total"`
+	Currency        string          `json:"currency"`
+	ComputedAt      time.Time       `json:"computed_at"`
+}
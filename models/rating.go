@@ -27,11 +27,58 @@ type WorkerRating struct {
 	// Metadata
 	IsAnonymous     bool           `json:"is_anonymous" gorm:"default:false"`
 	IsVerified      bool           `json:"is_verified" gorm:"default:false"` // Service was actually completed
+	
+	// Worker's public reply
+	WorkerReply     string         `json:"worker_reply" gorm:"type:text"`
+	WorkerRepliedAt *time.Time     `json:"worker_replied_at"`
+	
+	// Moderation
+	ModerationStatus ReviewModerationStatus `json:"moderation_status" gorm:"type:varchar(20);not null;default:'visible';index"`
+	FlagCount        int                    `json:"flag_count" gorm:"default:0"`
+	ModeratedBy      *uint                  `json:"-"`
+	ModeratedAt      *time.Time             `json:"-"`
+	ModerationNote   string                 `json:"-" gorm:"type:text"`
+	Flags            []ReviewFlag           `json:"flags,omitempty" gorm:"foreignKey:RatingID"`
+	
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
 	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
 
+// ReviewModerationStatus is whether a rating is shown publicly
+type ReviewModerationStatus string
+
+const (
+	ReviewVisible ReviewModerationStatus = "visible" // Shown and counted in the worker's score
+	ReviewFlagged ReviewModerationStatus = "flagged" // Reported by a user, still shown until an admin decides
+	ReviewHidden  ReviewModerationStatus = "hidden"  // Hidden by an admin and left out of the worker's score
+)
+
+// ReviewFlagReason is why a user reported a rating
+type ReviewFlagReason string
+
+const (
+	ReviewFlagOffensive ReviewFlagReason = "offensive"
+	ReviewFlagSpam      ReviewFlagReason = "spam"
+	ReviewFlagOther     ReviewFlagReason = "other"
+)
+
+// ReviewFlag is a user's report of a rating for admin moderation
+type ReviewFlag struct {
+	ID         uint             `json:"id" gorm:"primaryKey"`
+	RatingID   uint             `json:"rating_id" gorm:"not null;uniqueIndex:idx_review_flags_rating_reporter"`
+	ReporterID uint             `json:"reporter_id" gorm:"not null;uniqueIndex:idx_review_flags_rating_reporter"`
+	Reason     ReviewFlagReason `json:"reason" gorm:"type:varchar(20);not null"`
+	Details    string           `json:"details" gorm:"type:text"`
+	ResolvedAt *time.Time       `json:"resolved_at"` // Set when an admin hides or restores the rating
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// TableName specifies the table name for ReviewFlag
+func (ReviewFlag) TableName() string {
+	return "review_flags"
+}
+
 // WorkerRatingCreate represents the request structure for creating a worker rating
 type WorkerRatingCreate struct {
 	ServiceRequestID uint   `json:"service_request_id" binding:"required"`
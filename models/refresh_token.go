@@ -20,6 +20,7 @@ type RefreshToken struct {
 	DeviceID   string `json:"device_id" gorm:"size:255"`
 	UserAgent  string `json:"user_agent" gorm:"size:500"`
 	IPAddress  string `json:"ip_address" gorm:"size:45"`
+	LastUsedAt *time.Time `json:"last_used_at"` // Last sign-in or refresh with this token
 	
 	// Relationships
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
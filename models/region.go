@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Region groups the cities that share a currency, time zone, default language, category catalog
+// and commission. Cities outside every region belong to the default region.
+type Region struct {
+	ID                    uint           `json:"id" gorm:"primaryKey"`
+	Code                  string         `json:"code" gorm:"type:varchar(20);not null;uniqueIndex"`
+	Name                  string         `json:"name" gorm:"type:varchar(100);not null"`
+	Cities                []string       `json:"cities" gorm:"-"` // Canonical city names
+	CitiesJSON            string         `json:"-" gorm:"column:cities;type:json"`
+	Currency              string         `json:"currency" gorm:"type:varchar(3);not null"`  // ISO 4217 code
+	Timezone              string         `json:"timezone" gorm:"type:varchar(64);not null"` // IANA name
+	DefaultLanguage       string         `json:"default_language" gorm:"type:varchar(5);not null"`
+	SupportedCategoryIDs  []uint         `json:"supported_category_ids" gorm:"-"` // Empty means all categories
+	SupportedCategoryJSON string         `json:"-" gorm:"column:supported_category_ids;type:json"`
+	CommissionPercent     *float64       `json:"commission_percent" gorm:"type:decimal(5,2)"` // Nil follows the worker_commission_percent setting
+	IsDefault             bool           `json:"is_default" gorm:"not null;default:false"`
+	IsActive              bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// RegionRequest represents the request structure for creating/updating regions
+type RegionRequest struct {
+	Code                 string   `json:"code" binding:"required,max=20"`
+	Name                 string   `json:"name" binding:"required,max=100"`
+	Cities               []string `json:"cities"`
+	Currency             string   `json:"currency" binding:"required,len=3,uppercase"`
+	Timezone             string   `json:"timezone" binding:"required,max=64"`
+	DefaultLanguage      string   `json:"default_language" binding:"required,oneof=en fr ar"`
+	SupportedCategoryIDs []uint   `json:"supported_category_ids"`
+	CommissionPercent    *float64 `json:"commission_percent" binding:"omitempty,gte=0,lte=100"`
+	IsDefault            *bool    `json:"is_default"`
+	IsActive             *bool    `json:"is_active"`
+}
+
+// TableName specifies the table name for Region
+func (Region) TableName() string {
+	return "regions"
+}
+
+// HasCity reports whether city is one of the region's cities
+func (r *Region) HasCity(city string) bool {
+	for _, c := range r.Cities {
+		if strings.EqualFold(c, city) {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsCategory reports whether requests of the given category are accepted in the region
+func (r *Region) SupportsCategory(categoryID uint) bool {
+	if len(r.SupportedCategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range r.SupportedCategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// Location returns the region's time zone, or UTC when it cannot be loaded
+func (r *Region) Location() *time.Location {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// BeforeSave hook to convert cities and categories to JSON
+func (r *Region) BeforeSave(tx *gorm.DB) error {
+	if r.Cities == nil {
+		r.Cities = []string{}
+	}
+	citiesJSON, err := json.Marshal(r.Cities)
+	if err != nil {
+		return err
+	}
+	r.CitiesJSON = string(citiesJSON)
+
+	if r.SupportedCategoryIDs == nil {
+		r.SupportedCategoryIDs = []uint{}
+	}
+	categoriesJSON, err := json.Marshal(r.SupportedCategoryIDs)
+	if err != nil {
+		return err
+	}
+	r.SupportedCategoryJSON = string(categoriesJSON)
+	return nil
+}
+
+// AfterFind hook to convert JSON back to cities and categories
+func (r *Region) AfterFind(tx *gorm.DB) error {
+	if r.CitiesJSON != "" {
+		if err := json.Unmarshal([]byte(r.CitiesJSON), &r.Cities); err != nil {
+			return err
+		}
+	}
+	if r.SupportedCategoryJSON != "" {
+		return json.Unmarshal([]byte(r.SupportedCategoryJSON), &r.SupportedCategoryIDs)
+	}
+	return nil
+}
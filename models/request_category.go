@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RequestCategory is a category a request is broadcast to besides its main one, for problems
+// that could need more than one trade
+type RequestCategory struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint      `json:"service_request_id" gorm:"not null;uniqueIndex:idx_request_categories_request_category"`
+	CategoryID       uint      `json:"category_id" gorm:"not null;uniqueIndex:idx_request_categories_request_category;index"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for RequestCategory
+func (RequestCategory) TableName() string {
+	return "request_categories"
+}
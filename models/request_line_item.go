@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"repair-service-server/money"
+)
+
+// LineItemStatus is the customer's decision on a line item
+type LineItemStatus string
+
+const (
+	LineItemPending  LineItemStatus = "pending"
+	LineItemApproved LineItemStatus = "approved"
+	LineItemRejected LineItemStatus = "rejected"
+)
+
+// RequestLineItem is a part or material the worker bought for a job. Approved items are added
+// to the final price.
+type RequestLineItem struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint           `json:"service_request_id" gorm:"not null;index"`
+	WorkerID         uint           `json:"worker_id" gorm:"not null"`
+	Description      string         `json:"description" gorm:"type:varchar(255);not null"`
+	Quantity         float64        `json:"quantity" gorm:"type:decimal(10,2);not null"`
+	UnitPrice        money.Amount   `json:"unit_price" gorm:"type:decimal(10,2);not null"`
+	ReceiptPhotoURL  string         `json:"receipt_photo_url" gorm:"type:text"` // Legacy public receipt; new receipts use ReceiptMediaID
+	ReceiptMediaID   *uint          `json:"receipt_media_id"`                   // Private upload, shown through GET /media/:id/url
+	Status           LineItemStatus `json:"status" gorm:"type:varchar(10);not null;default:'pending'"`
+	DecidedAt        *time.Time     `json:"decided_at"`
+	RejectionReason  string         `json:"rejection_reason,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// Total returns quantity times unit price, rounded to the minor unit
+func (i RequestLineItem) Total() money.Amount {
+	return i.UnitPrice.Mul(i.Quantity)
+}
+
+// TableName specifies the table name for RequestLineItem
+func (RequestLineItem) TableName() string {
+	return "request_line_items"
+}
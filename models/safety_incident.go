@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// SafetyIncidentStatus tracks an SOS until an admin has reviewed it
+type SafetyIncidentStatus string
+
+const (
+	SafetyIncidentOpen     SafetyIncidentStatus = "open"
+	SafetyIncidentResolved SafetyIncidentStatus = "resolved"
+)
+
+// SafetyResolution is what an admin decided for the request frozen by an SOS
+type SafetyResolution string
+
+const (
+	SafetyResolutionResume SafetyResolution = "resume" // The request goes back to its status before the SOS
+	SafetyResolutionCancel SafetyResolution = "cancel" // The request is cancelled
+)
+
+// SafetyIncident is an SOS raised by the customer or the worker during a job. The request stays
+// on hold until an admin resolves it.
+type SafetyIncident struct {
+	ID                       uint                         `json:"id" gorm:"primaryKey"`
+	ServiceRequestID         uint                         `json:"service_request_id" gorm:"not null;index"`
+	ServiceRequest           CustomerServiceRequest       `json:"service_request" gorm:"foreignKey:ServiceRequestID"`
+	ReportedByID             uint                         `json:"reported_by_id" gorm:"not null"`
+	ReportedBy               User                         `json:"reported_by" gorm:"foreignKey:ReportedByID"`
+	ReporterRole             UserRole                     `json:"reporter_role" gorm:"type:varchar(20);not null"` // customer or worker
+	Lat                      *float64                     `json:"lat" gorm:"type:decimal(10,8)"`
+	Lng                      *float64                     `json:"lng" gorm:"type:decimal(11,8)"`
+	Message                  string                       `json:"message" gorm:"type:text"`
+	PreviousStatus           CustomerServiceRequestStatus `json:"previous_status" gorm:"type:varchar(20);not null"`
+	EmergencyContactNotified bool                         `json:"emergency_contact_notified" gorm:"not null;default:false"`
+	Status                   SafetyIncidentStatus         `json:"status" gorm:"type:varchar(20);not null;default:'open';index"`
+	Resolution               SafetyResolution             `json:"resolution" gorm:"type:varchar(20)"`
+	ResolutionNote           string                       `json:"resolution_note" gorm:"type:text"`
+	ResolvedByID             *uint                        `json:"resolved_by_id"`
+	ResolvedAt               *time.Time                   `json:"resolved_at"`
+	CreatedAt                time.Time                    `json:"created_at"`
+	UpdatedAt                time.Time                    `json:"updated_at"`
+}
+
+// TableName specifies the table name for SafetyIncident
+func (SafetyIncident) TableName() string {
+	return "safety_incidents"
+}
+
+// SOSRequest is what the customer or worker sends when raising an SOS
+type SOSRequest struct {
+	Lat                    *float64 `json:"lat" binding:"omitempty,latitude"`
+	Lng                    *float64 `json:"lng" binding:"omitempty,longitude"`
+	Message                string   `json:"message" binding:"max=1000"`
+	NotifyEmergencyContact bool     `json:"notify_emergency_contact"`
+}
+
+// SafetyResolveRequest is an admin's decision on an SOS
+type SafetyResolveRequest struct {
+	Resolution SafetyResolution `json:"resolution" binding:"required,oneof=resume cancel"`
+	Note       string           `json:"note" binding:"max=2000"`
+}
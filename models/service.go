@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"repair-service-server/money"
 )
 
 // ServiceCategory represents a service category
@@ -19,60 +21,107 @@ type ServiceCategory struct {
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Hierarchy: subcategories point to a top-level parent; there are only two levels
+	ParentID      *uint             `json:"parent_id" gorm:"index"`
+	Subcategories []ServiceCategory `json:"subcategories,omitempty" gorm:"foreignKey:ParentID"`
+
+	// Translations of Name and Description, which are in French
+	NameEn        string `json:"name_en" gorm:"type:varchar(100);not null;default:''"`
+	NameAr        string `json:"name_ar" gorm:"type:varchar(100);not null;default:''"`
+	DescriptionEn string `json:"description_en" gorm:"type:text;not null;default:''"`
+	DescriptionAr string `json:"description_ar" gorm:"type:text;not null;default:''"`
+
+	// Customers cannot ask for urgent service in a category, or its subcategories, with this set
+	UrgentDisabled bool `json:"urgent_disabled" gorm:"not null;default:false"`
 }
 
 // Service represents a service offered by workers
 type Service struct {
-	ID            uint           `json:"id" gorm:"primaryKey"`
-	CategoryID    uint           `json:"category_id" gorm:"not null"`
+	ID            uint            `json:"id" gorm:"primaryKey"`
+	CategoryID    uint            `json:"category_id" gorm:"not null"`
 	Category      ServiceCategory `json:"category" gorm:"foreignKey:CategoryID"`
-	Name          string         `json:"name" gorm:"type:varchar(200);not null"`
-	Description   string         `json:"description" gorm:"type:text"`
-	Price         float64        `json:"price" gorm:"type:decimal(10,2)"`
-	ImageURL      string         `json:"image_url" gorm:"type:varchar(255);not null"`
-	IsActive      bool           `json:"is_active" gorm:"default:true"`
-	CreatedAt     time.Time      `json:"created_at"`
-	UpdatedAt     time.Time      `json:"updated_at"`
-	NameAr        string         `json:"name_ar" gorm:"type:varchar(200);not null"`
-	DescriptionAr string         `json:"description_ar" gorm:"type:varchar(500);not null"`
-	BasePrice     float64        `json:"base_price" gorm:"type:decimal(10,2)"`
-	PriceUnit     string         `json:"price_unit" gorm:"type:varchar(50)"`
-	Guarantee     string         `json:"guarantee" gorm:"type:varchar(100)"`
-	Policies      string         `json:"policies" gorm:"type:varchar(500)"`
-	DeletedAt     gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
-	Duration      int            `json:"duration" gorm:"type:int"` // in minutes
+	Name          string          `json:"name" gorm:"type:varchar(200);not null"`
+	Description   string          `json:"description" gorm:"type:text"`
+	Price         money.Amount    `json:"price" gorm:"type:decimal(10,2)"`
+	ImageURL      string          `json:"image_url" gorm:"type:varchar(255);not null"`
+	IsActive      bool            `json:"is_active" gorm:"default:true"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	NameAr        string          `json:"name_ar" gorm:"type:varchar(200);not null"`
+	DescriptionAr string          `json:"description_ar" gorm:"type:varchar(500);not null"`
+	BasePrice     money.Amount    `json:"base_price" gorm:"type:decimal(10,2)"`
+	PriceUnit     string          `json:"price_unit" gorm:"type:varchar(50)"`
+	Guarantee     string          `json:"guarantee" gorm:"type:varchar(100)"`
+	Policies      string          `json:"policies" gorm:"type:varchar(500)"`
+	DeletedAt     gorm.DeletedAt  `json:"deleted_at,omitempty" gorm:"index"`
+	Duration      int             `json:"duration" gorm:"type:int"` // in minutes
 }
 
 // ServiceRequest represents the request structure for creating/updating services
 type ServiceRequest struct {
-	CategoryID  uint    `json:"category_id" binding:"required"`
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description" binding:"required"`
-	Price       float64 `json:"price" binding:"required"`
-	Duration    int     `json:"duration" binding:"required"`
+	CategoryID  uint         `json:"category_id" binding:"required"`
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description" binding:"required"`
+	Price       money.Amount `json:"price" binding:"required"`
+	Duration    int          `json:"duration" binding:"required"`
 }
 
 // ServiceResponse represents the response structure for services
 type ServiceResponse struct {
-	ID            uint           `json:"id"`
-	CategoryID    uint           `json:"category_id"`
+	ID            uint            `json:"id"`
+	CategoryID    uint            `json:"category_id"`
 	Category      ServiceCategory `json:"category"`
-	Name          string         `json:"name"`
-	Description   string         `json:"description"`
-	Price         float64        `json:"price"`
-	ImageURL      string         `json:"image_url"`
-	Duration      int            `json:"duration"`
-	IsActive      bool           `json:"is_active"`
-	CreatedAt     time.Time      `json:"created_at"`
-	NameAr        string         `json:"name_ar"`
-	DescriptionAr string         `json:"description_ar"`
-	BasePrice     float64        `json:"base_price"`
-	PriceUnit     string         `json:"price_unit"`
-	Guarantee     string         `json:"guarantee"`
-	Policies      string         `json:"policies"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	Price         money.Amount    `json:"price"`
+	ImageURL      string          `json:"image_url"`
+	Duration      int             `json:"duration"`
+	IsActive      bool            `json:"is_active"`
+	CreatedAt     time.Time       `json:"created_at"`
+	NameAr        string          `json:"name_ar"`
+	DescriptionAr string          `json:"description_ar"`
+	BasePrice     money.Amount    `json:"base_price"`
+	PriceUnit     string          `json:"price_unit"`
+	Guarantee     string          `json:"guarantee"`
+	Policies      string          `json:"policies"`
 }
 
 // TableName specifies the table name for the Service model
 func (Service) TableName() string {
 	return "services"
-}
\ No newline at end of file
+}
+// Localized returns a copy of the category and its subcategories with Name and Description in
+// lang, keeping the French text where no translation was entered
+func (c ServiceCategory) Localized(lang string) ServiceCategory {
+	c.Name = localizedText(lang, c.Name, c.NameEn, c.NameAr)
+	c.Description = localizedText(lang, c.Description, c.DescriptionEn, c.DescriptionAr)
+	if c.Subcategories != nil {
+		subcategories := make([]ServiceCategory, len(c.Subcategories))
+		for i, subcategory := range c.Subcategories {
+			subcategories[i] = subcategory.Localized(lang)
+		}
+		c.Subcategories = subcategories
+	}
+	return c
+}
+
+// Localized returns a copy of the service and its category in lang. Services are only translated
+// to Arabic.
+func (s Service) Localized(lang string) Service {
+	s.Name = localizedText(lang, s.Name, "", s.NameAr)
+	s.Description = localizedText(lang, s.Description, "", s.DescriptionAr)
+	s.Category = s.Category.Localized(lang)
+	return s
+}
+
+// localizedText picks the translation for lang ("en" or "ar"), or base when it is missing
+func localizedText(lang, base, en, ar string) string {
+	switch {
+	case lang == "en" && en != "":
+		return en
+	case lang == "ar" && ar != "":
+		return ar
+	}
+	return base
+}
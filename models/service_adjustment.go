@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"repair-service-server/money"
+)
+
+// ServiceAdjustmentType represents the kind of financial correction applied to a service
+type ServiceAdjustmentType string
+
+const (
+	AdjustmentTypePrice         ServiceAdjustmentType = "price_adjustment"
+	AdjustmentTypeRefund        ServiceAdjustmentType = "refund"
+	AdjustmentTypePartialRefund ServiceAdjustmentType = "partial_refund"
+)
+
+// ServiceAdjustment is an append-only ledger entry recording an admin correction to a
+// completed service. EarningsDelta is the amount booked against the worker's earnings
+// (negative for reversals), so analytics are corrected without editing past entries.
+type ServiceAdjustment struct {
+	ID               uint                  `json:"id" gorm:"primaryKey"`
+	ServiceHistoryID uint                  `json:"service_history_id" gorm:"not null;index"`
+	ServiceRequestID uint                  `json:"service_request_id" gorm:"not null;index"`
+	WorkerID         uint                  `json:"worker_id" gorm:"not null;index"`
+	CustomerID       uint                  `json:"customer_id" gorm:"not null;index"`
+	AdminID          uint                  `json:"admin_id" gorm:"not null"`
+	Type             ServiceAdjustmentType `json:"type" gorm:"type:varchar(30);not null"`
+	PreviousPrice    *money.Amount         `json:"previous_price" gorm:"type:decimal(10,2)"`
+	NewPrice         *money.Amount         `json:"new_price" gorm:"type:decimal(10,2)"`
+	Amount           money.Amount          `json:"amount" gorm:"type:decimal(10,2);not null"`
+	EarningsDelta    money.Amount          `json:"earnings_delta" gorm:"type:decimal(10,2);not null"`
+	Reason           string                `json:"reason" gorm:"type:text;not null"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+// PriceAdjustmentRequest represents an admin change to a service's final price
+type PriceAdjustmentRequest struct {
+	FinalPrice money.Amount `json:"final_price" binding:"min=0"`
+	Reason     string       `json:"reason" binding:"required"`
+}
+
+// RefundRequest represents an admin refund on a service. A nil amount refunds the remaining balance.
+type RefundRequest struct {
+	Amount *money.Amount `json:"amount" binding:"omitempty,gt=0"`
+	Reason string        `json:"reason" binding:"required"`
+}
+
+// TableName specifies the table name for ServiceAdjustment
+func (ServiceAdjustment) TableName() string {
+	return "service_adjustments"
+}
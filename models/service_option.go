@@ -5,27 +5,41 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"repair-service-server/money"
 )
 
 // ServiceOption represents a specific service option within a category
 type ServiceOption struct {
-	ID          uint           `json:"id" gorm:"primaryKey"`
-	CategoryID  uint           `json:"category_id" gorm:"not null"`
-	Title       string         `json:"title" gorm:"not null"`
-	Description string         `json:"description" gorm:"not null"`
-	ImageURL    string         `json:"image_url"`
-	Price       float64        `json:"price" gorm:"not null"`
-	Duration    int            `json:"duration" gorm:"not null"` // in minutes
-	Features    []string       `json:"features" gorm:"-"`        // Will be stored as JSON
-	FeaturesJSON string        `json:"-" gorm:"column:features;type:json"`
-	IsActive    bool           `json:"is_active" gorm:"default:true"`
-	SortOrder   int            `json:"sort_order" gorm:"default:0"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	CategoryID   uint           `json:"category_id" gorm:"not null"`
+	Title        string         `json:"title" gorm:"not null"`
+	Description  string         `json:"description" gorm:"not null"`
+	ImageURL     string         `json:"image_url"`
+	Price        money.Amount   `json:"price" gorm:"type:decimal(10,2);not null"`
+	Duration     int            `json:"duration" gorm:"not null"` // in minutes
+	Features     []string       `json:"features" gorm:"-"`        // Will be stored as JSON
+	FeaturesJSON string         `json:"-" gorm:"column:features;type:json"`
+	IsActive     bool           `json:"is_active" gorm:"default:true"`
+	SortOrder    int            `json:"sort_order" gorm:"default:0"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	// Relationships
 	Category ServiceCategory `json:"category" gorm:"foreignKey:CategoryID"`
+
+	// Translations of Title and Description, which are in French
+	TitleEn       string `json:"title_en" gorm:"not null;default:''"`
+	TitleAr       string `json:"title_ar" gorm:"not null;default:''"`
+	DescriptionEn string `json:"description_en" gorm:"not null;default:''"`
+	DescriptionAr string `json:"description_ar" gorm:"not null;default:''"`
+
+	// Price modifiers; percentages apply to Price
+	WeekendSurchargePercent float64      `json:"weekend_surcharge_percent" gorm:"type:decimal(5,2);not null;default:0"`
+	UrgentSurchargePercent  float64      `json:"urgent_surcharge_percent" gorm:"type:decimal(5,2);not null;default:0"`
+	DistanceFeePerKm        money.Amount `json:"distance_fee_per_km" gorm:"type:decimal(10,2);not null;default:0"`
+	FreeDistanceKm          float64      `json:"free_distance_km" gorm:"type:decimal(6,2);not null;default:0"` // Distance from the city center covered by Price
 }
 
 // TableName specifies the table name for ServiceOption
@@ -33,6 +47,15 @@ func (ServiceOption) TableName() string {
 	return "service_options"
 }
 
+// Localized returns a copy of the option and its category with Title and Description in lang,
+// keeping the French text where no translation was entered
+func (o ServiceOption) Localized(lang string) ServiceOption {
+	o.Title = localizedText(lang, o.Title, o.TitleEn, o.TitleAr)
+	o.Description = localizedText(lang, o.Description, o.DescriptionEn, o.DescriptionAr)
+	o.Category = o.Category.Localized(lang)
+	return o
+}
+
 // BeforeSave hook to convert features slice to JSON
 func (so *ServiceOption) BeforeSave(tx *gorm.DB) error {
 	if len(so.Features) > 0 {
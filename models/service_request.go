@@ -1,9 +1,13 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"gorm.io/gorm"
+
+	"repair-service-server/duration"
+	"repair-service-server/money"
 )
 
 // CustomerServiceRequestStatus represents the current status of a customer service request
@@ -13,115 +17,313 @@ const (
 	RequestStatusPending    CustomerServiceRequestStatus = "pending"
 	RequestStatusBroadcast  CustomerServiceRequestStatus = "broadcast"
 	RequestStatusAccepted   CustomerServiceRequestStatus = "accepted"
+	RequestStatusEnRoute    CustomerServiceRequestStatus = "en_route" // Worker is travelling to the customer
+	RequestStatusArrived    CustomerServiceRequestStatus = "arrived"  // Worker is on site but has not started
 	RequestStatusInProgress CustomerServiceRequestStatus = "in_progress"
 	RequestStatusCompleted  CustomerServiceRequestStatus = "completed"
 	RequestStatusCancelled  CustomerServiceRequestStatus = "cancelled"
 	RequestStatusExpired    CustomerServiceRequestStatus = "expired"
 	RequestStatusScheduled  CustomerServiceRequestStatus = "scheduled"
+	RequestStatusOnHold     CustomerServiceRequestStatus = "on_hold" // Frozen by an SOS until an admin reviews it
+)
+
+// ActiveRequestStatuses are the statuses of a request a worker has taken on and not finished yet
+var ActiveRequestStatuses = []CustomerServiceRequestStatus{
+	RequestStatusAccepted, RequestStatusEnRoute, RequestStatusArrived, RequestStatusInProgress,
+}
+
+// IsActive reports whether a worker has taken the request on and not finished it yet
+func (s CustomerServiceRequestStatus) IsActive() bool {
+	for _, active := range ActiveRequestStatuses {
+		if s == active {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished reports whether the request has reached a status it never leaves
+func (s CustomerServiceRequestStatus) IsFinished() bool {
+	return s == RequestStatusCompleted || s == RequestStatusCancelled || s == RequestStatusExpired
+}
+
+// WorkTimerState is where a worker's job timer stands
+type WorkTimerState string
+
+const (
+	WorkTimerIdle    WorkTimerState = ""        // Not started yet
+	WorkTimerRunning WorkTimerState = "running"
+	WorkTimerPaused  WorkTimerState = "paused"
+	WorkTimerStopped WorkTimerState = "stopped"
 )
 
 // CustomerServiceRequest represents a service request from a customer
 type CustomerServiceRequest struct {
-	ID              uint           `json:"id" gorm:"primaryKey"`
-	CustomerID      uint           `json:"customer_id" gorm:"not null"`
-	Customer        User           `json:"customer" gorm:"foreignKey:CustomerID"`
-	CategoryID      uint           `json:"category_id" gorm:"not null"`
-	Category        ServiceCategory `json:"category" gorm:"foreignKey:CategoryID"`
-	ServiceOptionID *uint          `json:"service_option_id"` // New: Selected service option
-	ServiceOption   *ServiceOption `json:"service_option,omitempty" gorm:"foreignKey:ServiceOptionID"` // New: Service option details
-	Title           string         `json:"title" gorm:"type:varchar(200);not null"`
-	Description     string         `json:"description" gorm:"type:text"`
-	Priority        string         `json:"priority" gorm:"type:varchar(20);not null"` // low, medium, high, urgent
-	Budget          *float64       `json:"budget" gorm:"type:decimal(10,2)"`
-	EstimatedDuration string       `json:"estimated_duration" gorm:"type:varchar(100)"`
-	LocationAddress string         `json:"location_address" gorm:"type:text;not null"`
-	LocationCity    string         `json:"location_city" gorm:"type:varchar(100);not null"`
-	LocationLat     *float64       `json:"location_lat" gorm:"type:decimal(10,8)"`
-	LocationLng     *float64       `json:"location_lng" gorm:"type:decimal(11,8)"`
-	Status          CustomerServiceRequestStatus `json:"status" gorm:"type:varchar(20);not null;default:'broadcast'"` // broadcast, assigned, in_progress, completed, cancelled
-	AssignedWorkerID *uint         `json:"assigned_worker_id"`
-	AssignedWorker  *WorkerProfile `json:"assigned_worker,omitempty" gorm:"foreignKey:AssignedWorkerID"`
-	StartedAt       *time.Time     `json:"started_at"`
-	CompletedAt     *time.Time     `json:"completed_at"`
-	ExpiresAt       *time.Time     `json:"expires_at"`
-	ScheduledFor    *time.Time     `json:"scheduled_for"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+	ID                uint                         `json:"id" gorm:"primaryKey"`
+	CustomerID        uint                         `json:"customer_id" gorm:"not null"`
+	Customer          User                         `json:"customer" gorm:"foreignKey:CustomerID"`
+	OrganizationID    *uint                        `json:"organization_id" gorm:"index"` // Set when the request is billed to the customer's organization
+	CategoryID        uint                         `json:"category_id" gorm:"not null"`
+	Category          ServiceCategory              `json:"category" gorm:"foreignKey:CategoryID"`
+	ExtraCategories   []RequestCategory            `json:"extra_categories,omitempty" gorm:"foreignKey:ServiceRequestID"` // Also broadcast to these categories' workers
+	ServiceOptionID   *uint                        `json:"service_option_id"`                                          // New: Selected service option
+	ServiceOption     *ServiceOption               `json:"service_option,omitempty" gorm:"foreignKey:ServiceOptionID"` // New: Service option details
+	Title             string                       `json:"title" gorm:"type:varchar(200);not null"`
+	Description       string                       `json:"description" gorm:"type:text"`
+	Priority          string                       `json:"priority" gorm:"type:varchar(20);not null"` // low, medium, high, urgent
+	Budget            *money.Amount                `json:"budget" gorm:"type:decimal(10,2)"`
+	EstimatedDuration duration.Minutes             `json:"estimated_duration" gorm:"type:integer"` // Customer's estimate, 0 when not given
+	AddressID         *uint                        `json:"address_id"` // Saved address the location was copied from; later edits to it leave the request as is
+	LocationAddress   string                       `json:"location_address" gorm:"type:text;not null"`
+	LocationCity      string                       `json:"location_city" gorm:"type:varchar(100);not null"`
+	EntranceNotes     string                       `json:"entrance_notes" gorm:"type:text"` // Shown to the worker when they navigate to the request
+	GeocodedAddress   string                       `json:"geocoded_address" gorm:"type:text"` // Reverse-geocoded from LocationLat/LocationLng
+	ServiceZoneID     *uint                        `json:"service_zone_id" gorm:"index"`
+	ServiceZone       *ServiceZone                 `json:"service_zone,omitempty" gorm:"foreignKey:ServiceZoneID"`
+	SurgeMultiplier   float64                      `json:"surge_multiplier" gorm:"type:decimal(4,2);default:1"`
+	LocationLat       *float64                     `json:"location_lat" gorm:"type:decimal(10,8)"`
+	LocationLng       *float64                     `json:"location_lng" gorm:"type:decimal(11,8)"`
+	Status            CustomerServiceRequestStatus `json:"status" gorm:"type:varchar(20);not null;default:'broadcast'"` // broadcast, accepted, en_route, arrived, in_progress, completed, cancelled
+	DispatchMode      DispatchMode                 `json:"dispatch_mode" gorm:"type:varchar(20);not null;default:'broadcast'"`
+	AssignedWorkerID  *uint                        `json:"assigned_worker_id"`
+	AssignedWorker    *WorkerProfile               `json:"assigned_worker,omitempty" gorm:"foreignKey:AssignedWorkerID"`
+	AcceptedAt        *time.Time                   `json:"accepted_at"` // When the current worker took the request
+	EnRouteAt         *time.Time                   `json:"en_route_at"`
+	ArrivedAt         *time.Time                   `json:"arrived_at"`
+	EtaMinutes        *int                         `json:"eta_minutes"` // Live while the worker is en route
+	EtaUpdatedAt      *time.Time                   `json:"eta_updated_at"`
+	StartedAt         *time.Time                   `json:"started_at"`
+	TimerState        WorkTimerState               `json:"timer_state" gorm:"type:varchar(10);not null;default:''"`
+	TimerStartedAt    *time.Time                   `json:"timer_started_at"`                         // Start of the running session, nil unless running
+	WorkedSeconds     int                          `json:"worked_seconds" gorm:"not null;default:0"` // Sum of finished sessions
+	CompletedAt       *time.Time                   `json:"completed_at"`
+	ExpiresAt         *time.Time                   `json:"expires_at"`
+	ScheduledFor      *time.Time                   `json:"scheduled_for"`       // Stored in UTC
+	ScheduledTzOffset *int                         `json:"scheduled_tz_offset"` // Customer's UTC offset in minutes when they scheduled it
+	CreatedAt         time.Time                    `json:"created_at"`
+	UpdatedAt         time.Time                    `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt               `json:"deleted_at,omitempty" gorm:"index"`
+
+	// Price of the service option with its modifiers when the request was created
+	PriceBreakdown     *PriceBreakdown `json:"price_breakdown,omitempty" gorm:"-"`
+	PriceBreakdownJSON *string         `json:"-" gorm:"column:price_breakdown;type:json"`
+
+	// Set once the request is archived; its chat messages then live in archived_chat_messages
+	ArchivedAt *time.Time `json:"archived_at,omitempty" gorm:"index"`
+
+	// Which of the request's categories the worker who took it serves, for reporting
+	FulfilledCategoryID *uint `json:"fulfilled_category_id" gorm:"index"`
+
+	// Price the worker set when starting the job, replacing the quote; nil until then
+	AgreedPrice *money.Amount `json:"agreed_price" gorm:"type:decimal(10,2)"`
+}
+
+// Price is what the customer is charged for the work, before parts: the price agreed when the
+// job started, else the total of the price breakdown for requests priced from a service option,
+// else the customer's budget. Nil when none of them is known.
+func (r *CustomerServiceRequest) Price() *money.Amount {
+	switch {
+	case r.AgreedPrice != nil:
+		return r.AgreedPrice
+	case r.PriceBreakdown != nil:
+		return r.PriceBreakdown.Total.Ptr()
+	}
+	return r.Budget
+}
+
+// BeforeSave hook to convert the price breakdown to JSON
+func (r *CustomerServiceRequest) BeforeSave(tx *gorm.DB) error {
+	if r.PriceBreakdown != nil {
+		breakdownJSON, err := json.Marshal(r.PriceBreakdown)
+		if err != nil {
+			return err
+		}
+		encoded := string(breakdownJSON)
+		r.PriceBreakdownJSON = &encoded
+	}
+	return nil
+}
+
+// AfterFind hook to convert JSON back to the price breakdown
+func (r *CustomerServiceRequest) AfterFind(tx *gorm.DB) error {
+	if r.PriceBreakdownJSON != nil && *r.PriceBreakdownJSON != "" {
+		r.PriceBreakdown = &PriceBreakdown{}
+		return json.Unmarshal([]byte(*r.PriceBreakdownJSON), r.PriceBreakdown)
+	}
+	return nil
+}
+
+// ScheduleAt schedules the request for at, stored in UTC with the offset of at's zone so that it can
+// be shown back in the customer's local time
+func (r *CustomerServiceRequest) ScheduleAt(at time.Time) {
+	_, offset := at.Zone()
+	minutes := offset / 60
+	utc := at.UTC()
+	r.ScheduledFor = &utc
+	r.ScheduledTzOffset = &minutes
+}
+
+// ScheduledForLocal returns the scheduled time at the customer's UTC offset, or nil when the
+// request is not scheduled or was scheduled before offsets were recorded
+func (r CustomerServiceRequest) ScheduledForLocal() *time.Time {
+	if r.ScheduledFor == nil || r.ScheduledTzOffset == nil {
+		return nil
+	}
+	local := r.ScheduledFor.In(time.FixedZone("", *r.ScheduledTzOffset*60))
+	return &local
+}
+
+// WorkedSecondsAt returns the time worked on the request as of now, including the running session
+func (r CustomerServiceRequest) WorkedSecondsAt(now time.Time) int {
+	if r.TimerState == WorkTimerRunning && r.TimerStartedAt != nil {
+		return r.WorkedSeconds + int(now.Sub(*r.TimerStartedAt).Seconds())
+	}
+	return r.WorkedSeconds
 }
 
 // CustomerServiceRequestCreate represents the request structure for creating a customer service request
 type CustomerServiceRequestCreate struct {
-	CategoryID       uint     `json:"category_id" binding:"required"`
-	ServiceOptionID  *uint    `json:"service_option_id"` // New: Selected service option ID
-	Title            string   `json:"title" binding:"required"`
-	Description      string   `json:"description"`
-	Priority         string   `json:"priority"`
-	Budget           *float64 `json:"budget"`
-	EstimatedDuration string  `json:"estimated_duration"`
-	LocationLat      float64  `json:"location_lat" binding:"required"`
-	LocationLng      float64  `json:"location_lng" binding:"required"`
-	LocationAddress  string   `json:"location_address" binding:"required"`
-	LocationCity     string   `json:"location_city" binding:"required"`
+	CategoryID        uint             `json:"category_id" binding:"required"`
+	ExtraCategoryIDs  []uint           `json:"extra_category_ids" binding:"omitempty,max=3,dive,gt=0"` // Other categories that could fix the problem
+	ServiceOptionID   *uint            `json:"service_option_id"` // New: Selected service option ID
+	Title             string           `json:"title" binding:"required,max=200"`
+	Description       string           `json:"description" binding:"max=2000"`
+	Priority          string           `json:"priority" binding:"omitempty,priority"`
+	Budget            *money.Amount    `json:"budget" binding:"omitempty,gt=0"`
+	EstimatedDuration duration.Minutes `json:"estimated_duration" binding:"omitempty,duration"` // Minutes; legacy text such as "2 hours" is still read
+	AddressID         *uint            `json:"address_id"` // Saved address to take the location from, instead of the fields below
+	LocationLat       float64          `json:"location_lat" binding:"omitempty,latitude"` // Required without address_id
+	LocationLng       float64          `json:"location_lng" binding:"omitempty,longitude"`
+	LocationAddress   string           `json:"location_address"` // Filled from reverse geocoding when empty
+	LocationCity      string           `json:"location_city"`    // Normalized by the geocoding service
+	EntranceNotes     string           `json:"entrance_notes" binding:"max=500"` // Defaults to the saved address's notes
+	DispatchMode      DispatchMode     `json:"dispatch_mode" binding:"omitempty,oneof=broadcast auto"`
+	DiagnosisID       *uint            `json:"diagnosis_id"`    // AI photo diagnosis the request is booked for
+	OrganizationID    *uint            `json:"organization_id"` // Bills the request to an organization the customer is an active member of
 }
 
 // CustomerServiceRequestResponse represents the response structure for customer service request data
 type CustomerServiceRequestResponse struct {
-	ID              uint                           `json:"id"`
-	CustomerID      uint                           `json:"customer_id"`
-	ServiceCategory WorkerCategory                 `json:"service_category"`
-	Title           string                         `json:"title"`
-	Description     string                         `json:"description"`
-	Notes           string                         `json:"notes"`
-	LocationLat     float64                        `json:"location_lat"`
-	LocationLng     float64                        `json:"location_lng"`
-	LocationAddress string                         `json:"location_address"`
-	LocationCity    string                         `json:"location_city"`
-	IsImmediate     bool                           `json:"is_immediate"`
-	ScheduledDate   *time.Time                     `json:"scheduled_date"`
-	ScheduledTime   *time.Time                     `json:"scheduled_time"`
-	PreferredTime   string                         `json:"preferred_time"`
-	Status          CustomerServiceRequestStatus   `json:"status"`
-	Priority        string                         `json:"priority"`
-	Budget          *float64                       `json:"budget"`
-	EstimatedDuration string                       `json:"estimated_duration"`
-	AssignedWorkerID *uint                         `json:"assigned_worker_id"`
-	AcceptedAt       *time.Time                    `json:"accepted_at"`
-	StartedAt        *time.Time                    `json:"started_at"`
-	CompletedAt      *time.Time                    `json:"completed_at"`
-	BroadcastRadius  float64                       `json:"broadcast_radius"`
-	BroadcastedAt   *time.Time                     `json:"broadcasted_at"`
-	ExpiresAt       *time.Time                     `json:"expires_at"`
-	CustomerRating  *float64                       `json:"customer_rating"`
-	CustomerReview  string                         `json:"customer_review"`
-	CreatedAt       time.Time                      `json:"created_at"`
-	UpdatedAt       time.Time                      `json:"updated_at"`
-	Customer        User                           `json:"customer,omitempty"`
-	AssignedWorker *WorkerProfile                 `json:"assigned_worker,omitempty"`
+	ID                uint                         `json:"id"`
+	CustomerID        uint                         `json:"customer_id"`
+	ServiceCategory   WorkerCategory               `json:"service_category"`
+	Title             string                       `json:"title"`
+	Description       string                       `json:"description"`
+	Notes             string                       `json:"notes"`
+	LocationLat       float64                      `json:"location_lat"`
+	LocationLng       float64                      `json:"location_lng"`
+	LocationAddress   string                       `json:"location_address"`
+	LocationCity      string                       `json:"location_city"`
+	IsImmediate       bool                         `json:"is_immediate"`
+	ScheduledDate     *time.Time                   `json:"scheduled_date"`
+	ScheduledTime     *time.Time                   `json:"scheduled_time"`
+	PreferredTime     string                       `json:"preferred_time"`
+	Status            CustomerServiceRequestStatus `json:"status"`
+	Priority          string                       `json:"priority"`
+	Budget            *money.Amount                `json:"budget"`
+	EstimatedDuration duration.Minutes             `json:"estimated_duration"`
+	AssignedWorkerID  *uint                        `json:"assigned_worker_id"`
+	AcceptedAt        *time.Time                   `json:"accepted_at"`
+	StartedAt         *time.Time                   `json:"started_at"`
+	CompletedAt       *time.Time                   `json:"completed_at"`
+	BroadcastRadius   float64                      `json:"broadcast_radius"`
+	BroadcastedAt     *time.Time                   `json:"broadcasted_at"`
+	ExpiresAt         *time.Time                   `json:"expires_at"`
+	CustomerRating    *float64                     `json:"customer_rating"`
+	CustomerReview    string                       `json:"customer_review"`
+	CreatedAt         time.Time                    `json:"created_at"`
+	UpdatedAt         time.Time                    `json:"updated_at"`
+	Customer          User                         `json:"customer,omitempty"`
+	AssignedWorker    *WorkerProfile               `json:"assigned_worker,omitempty"`
 }
 
+// Coordinates is a latitude/longitude pair in API responses
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// AvailableServiceRequestResponse is a broadcast request as listed in a worker's feed
+type AvailableServiceRequestResponse struct {
+	ID                     uint                         `json:"id"`
+	Title                  string                       `json:"title"`
+	Description            string                       `json:"description"`
+	CategoryID             uint                         `json:"category_id"`
+	ServiceOptionID        *uint                        `json:"service_option_id"`
+	LocationAddress        string                       `json:"location_address"`
+	LocationCity           string                       `json:"location_city"`
+	LocationLat            *float64                     `json:"location_lat"`
+	LocationLng            *float64                     `json:"location_lng"`
+	Priority               string                       `json:"priority"`
+	Budget                 *money.Amount                `json:"budget"`
+	EstimatedDuration      duration.Minutes             `json:"estimated_duration"`
+	DurationEstimate       *DurationEstimate            `json:"duration_estimate"` // Learned from similar jobs; nil until enough were timed
+	Distance               *float64                     `json:"distance"`    // km; nil when the worker has no recent location
+	ETAMinutes             *int                         `json:"eta_minutes"` // nil when the worker has no recent location
+	CustomerName           string                       `json:"customer_name"`
+	CustomerPhone          string                       `json:"customer_phone"`
+	CustomerScore          *float64                     `json:"customer_score"` // Average stars from workers; nil until first rated
+	CustomerRatingCount    int                          `json:"customer_rating_count"`
+	CustomerAddressDetails string                       `json:"customer_address_details"`
+	Coordinates            Coordinates                  `json:"coordinates"`
+	CreatedAt              time.Time                    `json:"created_at"`
+	Status                 CustomerServiceRequestStatus `json:"status"`
+}
+
+// ActiveServiceRequestResponse is an accepted or in-progress request assigned to a worker
+type ActiveServiceRequestResponse struct {
+	ID                uint                         `json:"id"`
+	Title             string                       `json:"title"`
+	Description       string                       `json:"description"`
+	LocationAddress   string                       `json:"location_address"`
+	LocationCity      string                       `json:"location_city"`
+	Priority          string                       `json:"priority"`
+	Budget            *money.Amount                `json:"budget"`
+	EstimatedDuration duration.Minutes             `json:"estimated_duration"`
+	Status            CustomerServiceRequestStatus `json:"status"`
+	StartedAt         *time.Time                   `json:"started_at"`
+	CompletedAt       *time.Time                   `json:"completed_at"`
+	CustomerName      string                       `json:"customer_name"`
+	CreatedAt         time.Time                    `json:"created_at"`
+}
+
+// DeclineReason says why a worker turned down a service request
+type DeclineReason string
+
+const (
+	DeclineReasonTooFar        DeclineReason = "too_far"
+	DeclineReasonBusy          DeclineReason = "busy"
+	DeclineReasonPriceTooLow   DeclineReason = "price_too_low"
+	DeclineReasonWrongCategory DeclineReason = "wrong_category"
+)
+
+// DeclineReasons lists every decline reason, in the order admin reports show them
+var DeclineReasons = []DeclineReason{DeclineReasonTooFar, DeclineReasonBusy, DeclineReasonPriceTooLow, DeclineReasonWrongCategory}
+
 // WorkerResponse represents a worker's response to a customer service request
 type WorkerResponse struct {
-	ID              uint                     `json:"id" gorm:"primaryKey"`
-	ServiceRequestID uint                    `json:"service_request_id" gorm:"not null"`
-	WorkerID        uint                     `json:"worker_id" gorm:"not null"`
-	Response        string                   `json:"response" gorm:"type:varchar(20);not null"` // "accept", "decline", "interested"
-	Message         string                   `json:"message" gorm:"type:text"`
-	ProposedPrice   *float64                 `json:"proposed_price" gorm:"type:decimal(10,2)"`
-	ProposedTime    *time.Time               `json:"proposed_time"`
-	Distance        float64                  `json:"distance" gorm:"type:decimal(5,2)"` // in kilometers
-	ETA             *time.Time               `json:"eta"`
-	RespondedAt     time.Time                `json:"responded_at"`
-	
+	ID               uint          `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint          `json:"service_request_id" gorm:"not null"`
+	WorkerID         uint          `json:"worker_id" gorm:"not null"`
+	Response         string        `json:"response" gorm:"type:varchar(20);not null"` // "accept", "decline", "interested"
+	Message          string        `json:"message" gorm:"type:text"`
+	DeclineReason    DeclineReason `json:"decline_reason,omitempty" gorm:"type:varchar(30)"` // Set on declines
+	ProposedPrice    *money.Amount `json:"proposed_price" gorm:"type:decimal(10,2)"`
+	ProposedTime     *time.Time    `json:"proposed_time"`
+	Distance         float64       `json:"distance" gorm:"type:decimal(5,2)"` // in kilometers
+	ETA              *time.Time    `json:"eta"`
+	RespondedAt      time.Time     `json:"responded_at"`
+
 	// Relationships
-	ServiceRequest  CustomerServiceRequest    `json:"service_request,omitempty" gorm:"foreignKey:ServiceRequestID"`
-	Worker          WorkerProfile             `json:"worker,omitempty" gorm:"foreignKey:WorkerID"`
+	ServiceRequest CustomerServiceRequest `json:"service_request,omitempty" gorm:"foreignKey:ServiceRequestID"`
+	Worker         WorkerProfile          `json:"worker,omitempty" gorm:"foreignKey:WorkerID"`
 }
 
 // WorkerResponseCreate represents the request structure for a worker's response
 type WorkerResponseCreate struct {
-	Response        string     `json:"response" binding:"required,oneof=accept decline interested"`
-	Message         string     `json:"message"`
-	ProposedPrice   *float64   `json:"proposed_price"`
-	ProposedTime    *time.Time `json:"proposed_time"`
+	Response      string        `json:"response" binding:"required,oneof=accept decline interested"`
+	Message       string        `json:"message"`
+	DeclineReason DeclineReason `json:"decline_reason" binding:"omitempty,oneof=too_far busy price_too_low wrong_category"` // Required to decline
+	ProposedPrice *money.Amount `json:"proposed_price"`
+	ProposedTime  *time.Time    `json:"proposed_time"`
 }
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ZonePoint represents a single vertex of a service zone polygon
+type ZonePoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// ServiceZone represents an admin-managed area where service requests are accepted
+type ServiceZone struct {
+	ID                    uint           `json:"id" gorm:"primaryKey"`
+	Name                  string         `json:"name" gorm:"type:varchar(100);not null"`
+	City                  string         `json:"city" gorm:"type:varchar(100);not null;index"`
+	Polygon               []ZonePoint    `json:"polygon" gorm:"-"`                       // Will be stored as JSON
+	PolygonJSON           string         `json:"-" gorm:"column:polygon;type:json"`
+	BroadcastRadiusKm     float64        `json:"broadcast_radius_km" gorm:"type:decimal(6,2);default:10"`
+	SurgeMultiplier       float64        `json:"surge_multiplier" gorm:"type:decimal(4,2);default:1"`
+	SupportedCategoryIDs  []uint         `json:"supported_category_ids" gorm:"-"`      // Empty means all categories
+	SupportedCategoryJSON string         `json:"-" gorm:"column:supported_category_ids;type:json"`
+	IsActive              bool           `json:"is_active" gorm:"default:true"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// ServiceZoneRequest represents the request structure for creating/updating service zones
+type ServiceZoneRequest struct {
+	Name                 string      `json:"name" binding:"required"`
+	City                 string      `json:"city" binding:"required"`
+	Polygon              []ZonePoint `json:"polygon" binding:"required,min=3"`
+	BroadcastRadiusKm    float64     `json:"broadcast_radius_km" binding:"omitempty,gt=0,lte=50"`
+	SurgeMultiplier      float64     `json:"surge_multiplier" binding:"omitempty,gte=1,lte=5"`
+	SupportedCategoryIDs []uint      `json:"supported_category_ids"`
+	IsActive             *bool       `json:"is_active"`
+}
+
+// TableName specifies the table name for ServiceZone
+func (ServiceZone) TableName() string {
+	return "service_zones"
+}
+
+// SupportsCategory reports whether requests of the given category are accepted in the zone
+func (z *ServiceZone) SupportsCategory(categoryID uint) bool {
+	if len(z.SupportedCategoryIDs) == 0 {
+		return true
+	}
+	for _, id := range z.SupportedCategoryIDs {
+		if id == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+// BeforeSave hook to convert polygon and categories to JSON
+func (z *ServiceZone) BeforeSave(tx *gorm.DB) error {
+	polygonJSON, err := json.Marshal(z.Polygon)
+	if err != nil {
+		return err
+	}
+	z.PolygonJSON = string(polygonJSON)
+
+	if z.SupportedCategoryIDs == nil {
+		z.SupportedCategoryIDs = []uint{}
+	}
+	categoriesJSON, err := json.Marshal(z.SupportedCategoryIDs)
+	if err != nil {
+		return err
+	}
+	z.SupportedCategoryJSON = string(categoriesJSON)
+	return nil
+}
+
+// AfterFind hook to convert JSON back to polygon and categories
+func (z *ServiceZone) AfterFind(tx *gorm.DB) error {
+	if z.PolygonJSON != "" {
+		if err := json.Unmarshal([]byte(z.PolygonJSON), &z.Polygon); err != nil {
+			return err
+		}
+	}
+	if z.SupportedCategoryJSON != "" {
+		return json.Unmarshal([]byte(z.SupportedCategoryJSON), &z.SupportedCategoryIDs)
+	}
+	return nil
+}
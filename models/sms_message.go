@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// SMSPurpose says why an SMS was sent
+type SMSPurpose string
+
+const (
+	SMSPurposeOTP          SMSPurpose = "otp"          // Verification codes
+	SMSPurposeNotification SMSPurpose = "notification" // Notifications for users whose preferred channel is SMS
+	SMSPurposeFallback     SMSPurpose = "fallback"     // Critical notifications that could not be pushed
+	SMSPurposeEmergency    SMSPurpose = "emergency"    // SOS alerts to a user's emergency contact
+)
+
+// SMSStatus is the provider's answer to a send
+type SMSStatus string
+
+const (
+	SMSStatusSent   SMSStatus = "sent"
+	SMSStatusFailed SMSStatus = "failed"
+)
+
+// SMSMessage records every SMS handed to the provider with its estimated cost
+type SMSMessage struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	UserID           *uint      `json:"user_id" gorm:"index"` // Nil for codes sent before signup
+	PhoneNumber      string     `json:"phone_number" gorm:"type:varchar(20);not null"`
+	Purpose          SMSPurpose `json:"purpose" gorm:"type:varchar(20);not null"`
+	NotificationType string     `json:"notification_type" gorm:"type:varchar(50)"`
+	Provider         string     `json:"provider" gorm:"type:varchar(20);not null"`
+	Status           SMSStatus  `json:"status" gorm:"type:varchar(10);not null"`
+	Segments         int        `json:"segments" gorm:"not null"`
+	Cost             float64    `json:"cost" gorm:"type:decimal(10,4);not null;default:0"`
+	Error            string     `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt        time.Time  `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for SMSMessage
+func (SMSMessage) TableName() string {
+	return "sms_messages"
+}
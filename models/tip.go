@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"repair-service-server/money"
+)
+
+// Tip is a gratuity a customer adds for the worker after a completed and rated job. It is kept
+// apart from the agreed and final price.
+type Tip struct {
+	ID               uint         `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint         `json:"service_request_id" gorm:"not null;uniqueIndex"`
+	CustomerID       uint         `json:"customer_id" gorm:"not null;index"`
+	WorkerID         uint         `json:"worker_id" gorm:"not null;index"`
+	Amount           money.Amount `json:"amount" gorm:"type:decimal(10,2);not null"`
+	CreatedAt        time.Time    `json:"created_at"`
+}
+
+// TableName specifies the table name for Tip
+func (Tip) TableName() string {
+	return "tips"
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// MaxRequestAttachments is how many videos can be attached to one service request
+const MaxRequestAttachments = 5
+
+// UploadSessionStatus tracks a resumable upload from its signature to its verification
+type UploadSessionStatus string
+
+const (
+	UploadSessionPending   UploadSessionStatus = "pending"   // Signed, the client is uploading chunks
+	UploadSessionCompleted UploadSessionStatus = "completed" // Verified and attached
+	UploadSessionRejected  UploadSessionStatus = "rejected"  // Failed verification; the file was deleted
+	UploadSessionExpired   UploadSessionStatus = "expired"   // Never completed in time
+)
+
+// UploadSession lets a client upload a large file straight to Cloudinary in resumable chunks.
+// The server picks the public ID and signs the upload; the file is checked and attached once
+// Cloudinary has it.
+type UploadSession struct {
+	ID               uint                `json:"id" gorm:"primaryKey"`
+	OwnerID          uint                `json:"owner_id" gorm:"not null;index"`
+	Owner            User                `json:"-" gorm:"foreignKey:OwnerID"`
+	Purpose          MediaPurpose        `json:"purpose" gorm:"type:varchar(30);not null"`
+	ServiceRequestID *uint               `json:"service_request_id,omitempty" gorm:"index"` // For request videos
+	Caption          string              `json:"caption,omitempty" gorm:"type:varchar(200);not null;default:''"`
+	PublicID         string              `json:"public_id" gorm:"type:varchar(255);not null;uniqueIndex"`
+	DeclaredSize     int64               `json:"declared_size" gorm:"not null"`
+	Status           UploadSessionStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	RejectReason     string              `json:"reject_reason,omitempty" gorm:"type:varchar(255)"`
+	MediaUploadID    *uint               `json:"media_upload_id,omitempty"`
+	MediaUpload      *MediaUpload        `json:"media_upload,omitempty" gorm:"foreignKey:MediaUploadID"`
+	ExpiresAt        time.Time           `json:"expires_at"`
+	CompletedAt      *time.Time          `json:"completed_at"`
+	CreatedAt        time.Time           `json:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at"`
+}
+
+// TableName specifies the table name for UploadSession
+func (UploadSession) TableName() string {
+	return "upload_sessions"
+}
+
+// RequestAttachment is a video the customer or the assigned worker attached to a service request
+type RequestAttachment struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint      `json:"service_request_id" gorm:"not null;index"`
+	UploadedByID     uint      `json:"uploaded_by_id" gorm:"not null"`
+	UploaderRole     UserRole  `json:"uploader_role" gorm:"type:varchar(20);not null"`
+	MediaUploadID    uint      `json:"media_upload_id" gorm:"not null"`
+	URL              string    `json:"url" gorm:"type:text;not null"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for RequestAttachment
+func (RequestAttachment) TableName() string {
+	return "request_attachments"
+}
+
+// UploadSessionCreateRequest starts a resumable upload
+type UploadSessionCreateRequest struct {
+	Purpose          MediaPurpose `json:"purpose" binding:"required,oneof=portfolio_video request_video"`
+	Size             int64        `json:"size" binding:"required,min=1"` // Bytes the client will send
+	ServiceRequestID uint         `json:"service_request_id" binding:"required_if=Purpose request_video"`
+	Caption          string       `json:"caption" binding:"max=200"`
+}
@@ -22,9 +22,23 @@ type User struct {
 	Role             UserRole  `json:"role" gorm:"type:varchar(20);not null;default:'customer';check:role IN ('customer','worker','admin')"`
 	ProfilePictureURL *string  `json:"profile_picture_url" gorm:"size:255"`
 	IsActive         bool      `json:"is_active" gorm:"default:true"`
+	PhoneVerifiedAt  *time.Time `json:"phone_verified_at"` // Set once the number is confirmed by SMS code
+	Email            *string    `json:"email" gorm:"size:255;uniqueIndex"` // Only set once confirmed by an emailed code
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	DeletionRequestedAt  *time.Time `json:"deletion_requested_at,omitempty"`
+	DeletionScheduledFor *time.Time `json:"deletion_scheduled_for,omitempty" gorm:"index"` // Personal data is anonymized after this
+	AnonymizedAt         *time.Time `json:"anonymized_at,omitempty"`
+	TokenVersion         int        `json:"-" gorm:"not null;default:0"` // Bumped to reject every token issued before
+	CustomerScore        *float64   `json:"customer_score" gorm:"type:decimal(3,2)"` // Average stars from workers; nil until first rated
+	CustomerRatingCount  int        `json:"customer_rating_count" gorm:"default:0"`
+	PreferredLanguage    string     `json:"preferred_language" gorm:"type:varchar(5);not null;default:''"` // en, fr or ar; empty follows Accept-Language
 	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
+	// Texted when the user raises an SOS during a job and asks for them to be told
+	EmergencyContactName  *string `json:"emergency_contact_name" gorm:"size:255"`
+	EmergencyContactPhone *string `json:"emergency_contact_phone" gorm:"size:20"` // E.164
+
 	// Relationships
 	Bookings []Booking `json:"bookings,omitempty" gorm:"foreignKey:UserID"`
 	Addresses []Address `json:"addresses,omitempty" gorm:"foreignKey:UserID"`
@@ -53,6 +67,16 @@ func (u *User) IsValidRole() bool {
 	}
 }
 
+// IsPhoneVerified checks if the user has confirmed their phone number
+func (u *User) IsPhoneVerified() bool {
+	return u.PhoneVerifiedAt != nil
+}
+
+// IsEmailVerified checks if the user has a confirmed email address
+func (u *User) IsEmailVerified() bool {
+	return u.Email != nil && u.EmailVerifiedAt != nil
+}
+
 // IsWorker checks if the user is a worker
 func (u *User) IsWorker() bool {
 	return u.Role == RoleWorker
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// WorkSession is one uninterrupted stretch of work on a request, from start or resume until the
+// worker pauses or stops the timer
+type WorkSession struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint       `json:"service_request_id" gorm:"not null;index"`
+	WorkerID         uint       `json:"worker_id" gorm:"not null;index"`
+	StartedAt        time.Time  `json:"started_at" gorm:"not null"`
+	EndedAt          *time.Time `json:"ended_at"` // Nil while the session is running
+	PauseReason      string     `json:"pause_reason,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for WorkSession
+func (WorkSession) TableName() string {
+	return "work_sessions"
+}
@@ -40,6 +40,8 @@ type WorkerProfile struct {
 	ProfilePhoto    *string        `json:"profile_photo" gorm:"type:varchar(500)"`
 	IDCardPhoto     *string        `json:"id_card_photo" gorm:"type:varchar(500)"`
 	IDCardBackPhoto *string        `json:"id_card_photo_back" gorm:"type:varchar(500)"`
+	IDCardMediaID     *uint        `json:"id_card_media_id"`      // Private uploads, shown through GET /media/:id/url
+	IDCardBackMediaID *uint        `json:"id_card_back_media_id"`
 	
 	// Location and Availability Fields
 	IsAvailable     bool           `json:"is_available" gorm:"default:false"`
@@ -50,10 +52,17 @@ type WorkerProfile struct {
 	
 	// Service Request Fields
 	ActiveRequests  int            `json:"active_requests" gorm:"default:0"`
+	MaxConcurrentJobs int          `json:"max_concurrent_jobs" gorm:"default:1"` // Accepted + in-progress jobs allowed at once
 	CompletedJobs   int            `json:"completed_jobs" gorm:"default:0"`
 	Rating          float64        `json:"rating" gorm:"type:decimal(3,2);default:0"`
 	TotalReviews    int            `json:"total_reviews" gorm:"default:0"`
 	IsVerified      bool           `json:"is_verified" gorm:"default:false"`
+	ReliabilityScore float64       `json:"reliability_score" gorm:"type:decimal(5,2);not null;default:100"` // 0-100, lowered by reliability events
+	ReliabilityTier ReliabilityTier `json:"reliability_tier" gorm:"type:varchar(20);not null;default:'good'"`
+	NoShowCount     int            `json:"no_show_count" gorm:"not null;default:0"`
+	SuspendedUntil  *time.Time     `json:"suspended_until"` // Cannot take jobs or go available until then
+	SuspensionReason string        `json:"suspension_reason" gorm:"type:text"`
+	EarningsSummarySentAt *time.Time `json:"-"` // Last weekly earnings email, so each week is sent once
 	
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
@@ -61,12 +70,25 @@ type WorkerProfile struct {
 	
 	// Relationships
 	User            User           `json:"user,omitempty" gorm:"foreignKey:UserID"`
+	Categories      []WorkerServiceCategory `json:"categories,omitempty" gorm:"foreignKey:WorkerID"`
+
+	// Onboarding; IsVerified is true exactly while the status is active
+	OnboardingStatus    WorkerOnboardingStatus `json:"onboarding_status" gorm:"type:varchar(30);not null;default:'profile_incomplete';index"`
+	OnboardingNote      string                 `json:"onboarding_note" gorm:"type:text"` // Admin's reason for the last transition
+	OnboardingUpdatedAt *time.Time             `json:"onboarding_updated_at"`
+
+	Badges []WorkerBadge `json:"badges,omitempty" gorm:"foreignKey:WorkerID"`
+}
+
+// IsSuspended reports whether the worker is serving a reliability suspension at now
+func (w WorkerProfile) IsSuspended(now time.Time) bool {
+	return w.SuspendedUntil != nil && w.SuspendedUntil.After(now)
 }
 
 // WorkerProfileRequest represents the request structure for creating/updating a worker profile
 type WorkerProfileRequest struct {
 	CategoryID      uint           `json:"category_id" binding:"required"`
-	PhoneNumber     string         `json:"phone_number" binding:"required"`
+	PhoneNumber     string         `json:"phone_number" binding:"required,phone"`
 	Country         string         `json:"country" binding:"required"`
 	State           string         `json:"state" binding:"required"`
 	City            string         `json:"city" binding:"required"`
@@ -74,7 +96,7 @@ type WorkerProfileRequest struct {
 	Address         string         `json:"address"`
 	Experience      string         `json:"experience"`
 	Skills          string         `json:"skills"`
-	HourlyRate      float64        `json:"hourly_rate"`
+	HourlyRate      float64        `json:"hourly_rate" binding:"gte=0"`
 	ProfilePhoto    *string        `json:"profile_photo"`
 	IDCardPhoto     *string        `json:"id_card_photo"`
 }
@@ -99,6 +121,7 @@ type WorkerProfileResponse struct {
 	LastLocationUpdate *time.Time  `json:"last_location_update"`
 	LocationAccuracy *float64      `json:"location_accuracy"`
 	ActiveRequests  int            `json:"active_requests"`
+	MaxConcurrentJobs int          `json:"max_concurrent_jobs"`
 	CompletedJobs   int            `json:"completed_jobs"`
 	Rating          float64        `json:"rating"`
 	TotalReviews    int            `json:"total_reviews"`
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Badge codes
+const (
+	BadgeFirstJob       = "first_job"
+	BadgeJobs100        = "jobs_100"
+	BadgeJobs500        = "jobs_500"
+	BadgeFiveStarWeek   = "five_star_week"
+	BadgeFastResponder  = "fast_responder"
+	BadgeTopRated       = "top_rated"
+	BadgeWeeklyChampion = "weekly_champion"
+)
+
+// Weekly challenge codes
+const (
+	ChallengeCompleteJobs = "complete_jobs"
+	ChallengeEarn         = "earn"
+	ChallengeActiveDays   = "active_days"
+	ChallengeRespondJobs  = "respond_jobs"
+)
+
+// BadgeDefinition describes a badge, how it looks and how much of its measure earns it
+type BadgeDefinition struct {
+	Badge       string  `json:"badge"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Icon        string  `json:"icon"` // Name of the icon the app draws
+	Target      float64 `json:"target"`
+}
+
+// ChallengeDefinition describes a weekly challenge and the target to reach within the week
+type ChallengeDefinition struct {
+	Challenge   string  `json:"challenge"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Target      float64 `json:"target"`
+}
+
+// WeeklyChallenges are the challenges every worker gets each week, starting on Monday
+var WeeklyChallenges = []ChallengeDefinition{
+	{ChallengeCompleteJobs, "Busy week", "Complete 10 jobs this week", 10},
+	{ChallengeEarn, "Big earner", "Earn 5000 MRU this week", 5000},
+	{ChallengeActiveDays, "Every day counts", "Complete a job on 5 different days this week", 5},
+	{ChallengeRespondJobs, "Quick to answer", "Respond to 15 jobs this week", 15},
+}
+
+// BadgeDefinitions lists every badge a worker can earn, in the order the app shows them
+var BadgeDefinitions = []BadgeDefinition{
+	{BadgeFirstJob, "First job", "Complete your first job", "wrench", 1},
+	{BadgeJobs100, "100 jobs", "Complete 100 jobs", "medal", 100},
+	{BadgeJobs500, "500 jobs", "Complete 500 jobs", "trophy", 500},
+	{BadgeFiveStarWeek, "5-star week", "Get at least 5 ratings in one week, all of them 5 stars", "star", 5},
+	{BadgeFastResponder, "Fast responder", "Respond to 20 jobs with an average response time of 5 minutes or less", "lightning", 20},
+	{BadgeTopRated, "Top rated", "Keep a rating of 4.8 or more over at least 20 reviews", "crown", 20},
+	{BadgeWeeklyChampion, "Weekly champion", "Complete every weekly challenge in the same week", "flag", float64(len(WeeklyChallenges))},
+}
+
+// FindBadgeDefinition returns the definition of badge
+func FindBadgeDefinition(badge string) (BadgeDefinition, bool) {
+	for _, def := range BadgeDefinitions {
+		if def.Badge == badge {
+			return def, true
+		}
+	}
+	return BadgeDefinition{}, false
+}
+
+// WorkerBadge is a badge a worker has earned. Badges are kept once earned.
+type WorkerBadge struct {
+	ID        uint      `json:"-" gorm:"primaryKey"`
+	WorkerID  uint      `json:"-" gorm:"not null;uniqueIndex:idx_worker_badges_worker_badge"`
+	Badge     string    `json:"badge" gorm:"type:varchar(30);not null;uniqueIndex:idx_worker_badges_worker_badge"`
+	Name      string    `json:"name" gorm:"-"`
+	Icon      string    `json:"icon" gorm:"-"`
+	AwardedAt time.Time `json:"awarded_at"`
+}
+
+// TableName specifies the table name for WorkerBadge
+func (WorkerBadge) TableName() string {
+	return "worker_badges"
+}
+
+// AfterFind fills in the badge's name and icon from its definition
+func (b *WorkerBadge) AfterFind(tx *gorm.DB) error {
+	if def, ok := FindBadgeDefinition(b.Badge); ok {
+		b.Name = def.Name
+		b.Icon = def.Icon
+	}
+	return nil
+}
+
+// WorkerChallengeCompletion records that a worker completed a weekly challenge
+type WorkerChallengeCompletion struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	WorkerID    uint      `json:"worker_id" gorm:"not null;uniqueIndex:idx_worker_challenge_completions_week"`
+	Challenge   string    `json:"challenge" gorm:"type:varchar(30);not null;uniqueIndex:idx_worker_challenge_completions_week"`
+	WeekStart   time.Time `json:"week_start" gorm:"type:date;not null;uniqueIndex:idx_worker_challenge_completions_week"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// TableName specifies the table name for WorkerChallengeCompletion
+func (WorkerChallengeCompletion) TableName() string {
+	return "worker_challenge_completions"
+}
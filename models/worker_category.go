@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// WorkerServiceCategory is a category or subcategory a worker serves besides their main one.
+// Workers receive its requests, and those of its subcategories, once an admin verifies it.
+type WorkerServiceCategory struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	WorkerID   uint            `json:"worker_id" gorm:"not null;uniqueIndex:idx_worker_categories_worker_category"`
+	CategoryID uint            `json:"category_id" gorm:"not null;uniqueIndex:idx_worker_categories_worker_category;index"`
+	Category   ServiceCategory `json:"category" gorm:"foreignKey:CategoryID"`
+	VerifiedAt *time.Time      `json:"verified_at"`
+	VerifiedBy *uint           `json:"verified_by,omitempty"` // Admin user ID
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// TableName specifies the table name for WorkerServiceCategory
+func (WorkerServiceCategory) TableName() string {
+	return "worker_categories"
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// NoShowStage is the step an assigned worker failed to reach in time
+type NoShowStage string
+
+const (
+	NoShowStageEnRoute NoShowStage = "en_route" // Never set off
+	NoShowStageStart   NoShowStage = "start"    // Never started the job
+)
+
+// WorkerNoShow records a worker taken off a request they accepted but did not turn up for.
+// The request was put back up for other workers.
+type WorkerNoShow struct {
+	ID               uint                   `json:"id" gorm:"primaryKey"`
+	ServiceRequestID uint                   `json:"service_request_id" gorm:"not null;index"`
+	ServiceRequest   CustomerServiceRequest `json:"-" gorm:"foreignKey:ServiceRequestID"`
+	WorkerID         uint                   `json:"worker_id" gorm:"not null;index"`
+	Worker           WorkerProfile          `json:"-" gorm:"foreignKey:WorkerID"`
+	Stage            NoShowStage            `json:"stage" gorm:"type:varchar(20);not null"`
+	AcceptedAt       time.Time              `json:"accepted_at"`
+	Penalty          float64                `json:"penalty" gorm:"type:decimal(5,2);not null"` // Points taken off the reliability score
+	CreatedAt        time.Time              `json:"created_at"`
+}
+
+// TableName specifies the table name for WorkerNoShow
+func (WorkerNoShow) TableName() string {
+	return "worker_no_shows"
+}
@@ -0,0 +1,48 @@
+package models
+
+// WorkerOnboardingStatus is how far a worker is through onboarding
+type WorkerOnboardingStatus string
+
+const (
+	OnboardingProfileIncomplete WorkerOnboardingStatus = "profile_incomplete" // Missing experience, skills or profile photo
+	OnboardingDocumentsPending  WorkerOnboardingStatus = "documents_pending"  // Missing the front or back of the ID card
+	OnboardingUnderReview       WorkerOnboardingStatus = "under_review"       // Waiting for an admin to check the documents
+	OnboardingTrainingRequired  WorkerOnboardingStatus = "training_required"  // Approved, waiting for the training session
+	OnboardingActive            WorkerOnboardingStatus = "active"             // Done; the worker is verified
+)
+
+// OnboardingSteps lists the onboarding statuses in order
+var OnboardingSteps = []WorkerOnboardingStatus{
+	OnboardingProfileIncomplete,
+	OnboardingDocumentsPending,
+	OnboardingUnderReview,
+	OnboardingTrainingRequired,
+	OnboardingActive,
+}
+
+// MissingProfileFields lists the profile fields the worker still has to fill in
+func (w *WorkerProfile) MissingProfileFields() []string {
+	missing := []string{}
+	if w.Experience == "" {
+		missing = append(missing, "experience")
+	}
+	if w.Skills == "" {
+		missing = append(missing, "skills")
+	}
+	if w.ProfilePhoto == nil || *w.ProfilePhoto == "" {
+		missing = append(missing, "profile_photo")
+	}
+	return missing
+}
+
+// MissingDocuments lists the identity documents the worker still has to upload
+func (w *WorkerProfile) MissingDocuments() []string {
+	missing := []string{}
+	if w.IDCardMediaID == nil && (w.IDCardPhoto == nil || *w.IDCardPhoto == "") {
+		missing = append(missing, "id_card_photo")
+	}
+	if w.IDCardBackMediaID == nil && (w.IDCardBackPhoto == nil || *w.IDCardBackPhoto == "") {
+		missing = append(missing, "id_card_photo_back")
+	}
+	return missing
+}
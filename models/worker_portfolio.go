@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	MaxPortfolioPhotos = 30 // How many portfolio photos a worker can keep
+	MaxPortfolioVideos = 5  // How many portfolio videos a worker can keep
+)
+
+// PortfolioItemKind says whether a portfolio item is a photo or a video
+type PortfolioItemKind string
+
+const (
+	PortfolioPhoto PortfolioItemKind = "photo"
+	PortfolioVideo PortfolioItemKind = "video"
+)
+
+// WorkerPortfolioPhoto is a photo or video of past work a worker shows on their public profile
+type WorkerPortfolioPhoto struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	WorkerID  uint              `json:"worker_id" gorm:"not null;index"`
+	Kind      PortfolioItemKind `json:"kind" gorm:"type:varchar(10);not null;default:'photo'"`
+	URL       string            `json:"url" gorm:"type:varchar(500);not null"`
+	PublicID  string            `json:"-" gorm:"type:varchar(255);not null;default:''"` // Cloudinary public ID, to delete the upload
+	Caption   string            `json:"caption" gorm:"type:varchar(200);not null;default:''"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// TableName specifies the table name for WorkerPortfolioPhoto
+func (WorkerPortfolioPhoto) TableName() string {
+	return "worker_portfolio_photos"
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// ReliabilityEventKind is something a worker did that lowers their reliability score
+type ReliabilityEventKind string
+
+const (
+	ReliabilityCancellation       ReliabilityEventKind = "cancellation"         // Gave up a job after setting off or arriving
+	ReliabilityNoShow             ReliabilityEventKind = "no_show"              // Never set off or started, see WorkerNoShow
+	ReliabilityDeclineAfterAccept ReliabilityEventKind = "decline_after_accept" // Gave up a job before setting off
+	ReliabilityLateArrival        ReliabilityEventKind = "late_arrival"         // Arrived after the late arrival window
+)
+
+// ReliabilityTier is the band a worker's reliability score falls in, from best to worst
+type ReliabilityTier string
+
+const (
+	ReliabilityTierGood            ReliabilityTier = "good"
+	ReliabilityTierWarning         ReliabilityTier = "warning"
+	ReliabilityTierReducedPriority ReliabilityTier = "reduced_priority" // Sees new broadcasts after other workers
+	ReliabilityTierSuspended       ReliabilityTier = "suspended"        // Suspended on the next event unless already
+)
+
+// Rank orders tiers from good (0) to suspended (3)
+func (t ReliabilityTier) Rank() int {
+	switch t {
+	case ReliabilityTierWarning:
+		return 1
+	case ReliabilityTierReducedPriority:
+		return 2
+	case ReliabilityTierSuspended:
+		return 3
+	}
+	return 0
+}
+
+// WorkerReliabilityEvent takes points off a worker's reliability score for as long as it is in the
+// scoring window, unless an admin waives it
+type WorkerReliabilityEvent struct {
+	ID               uint                 `json:"id" gorm:"primaryKey"`
+	WorkerID         uint                 `json:"worker_id" gorm:"not null;index"`
+	Worker           WorkerProfile        `json:"-" gorm:"foreignKey:WorkerID"`
+	ServiceRequestID *uint                `json:"service_request_id" gorm:"index"`
+	Kind             ReliabilityEventKind `json:"kind" gorm:"type:varchar(30);not null"`
+	Points           float64              `json:"points" gorm:"type:decimal(5,2);not null"`
+	Note             string               `json:"note" gorm:"type:text"` // Worker's reason for giving up the job
+	WaivedAt         *time.Time           `json:"waived_at"`
+	WaivedByID       *uint                `json:"waived_by_id"`
+	WaiveNote        string               `json:"waive_note" gorm:"type:text"`
+	CreatedAt        time.Time            `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for WorkerReliabilityEvent
+func (WorkerReliabilityEvent) TableName() string {
+	return "worker_reliability_events"
+}
+
+// WorkerCancelRequest is an assigned worker giving a job back
+type WorkerCancelRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ReliabilitySuspendRequest is an admin suspending a worker by hand
+type ReliabilitySuspendRequest struct {
+	Days   int    `json:"days" binding:"required,min=1,max=365"`
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// ReliabilityWaiveRequest is an admin excusing a reliability event
+type ReliabilityWaiveRequest struct {
+	Note string `json:"note" binding:"required,max=500"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// WorkerScheduleSlot represents a recurring weekly availability window for a worker
+type WorkerScheduleSlot struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	WorkerID  uint      `json:"worker_id" gorm:"not null;index"`
+	DayOfWeek int       `json:"day_of_week" gorm:"type:int;not null;check:day_of_week >= 0 AND day_of_week <= 6"` // 0 = Sunday
+	StartTime string    `json:"start_time" gorm:"type:varchar(5);not null"`                                       // HH:MM
+	EndTime   string    `json:"end_time" gorm:"type:varchar(5);not null"`                                         // HH:MM
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkerTimeOff represents a one-off block during which a worker is unavailable
+type WorkerTimeOff struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	WorkerID  uint      `json:"worker_id" gorm:"not null;index"`
+	StartsAt  time.Time `json:"starts_at" gorm:"not null;index"`
+	EndsAt    time.Time `json:"ends_at" gorm:"not null;index"`
+	Reason    string    `json:"reason" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkerScheduleSlotRequest represents a single slot in a weekly schedule update
+type WorkerScheduleSlotRequest struct {
+	DayOfWeek int    `json:"day_of_week" binding:"min=0,max=6"`
+	StartTime string `json:"start_time" binding:"required"`
+	EndTime   string `json:"end_time" binding:"required"`
+}
+
+// WorkerScheduleRequest replaces a worker's whole weekly schedule
+type WorkerScheduleRequest struct {
+	Slots []WorkerScheduleSlotRequest `json:"slots" binding:"dive"`
+}
+
+// WorkerTimeOffRequest represents the request structure for creating a time-off block
+type WorkerTimeOffRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+	Reason   string    `json:"reason"`
+}
+
+// TableName specifies the table name for WorkerScheduleSlot
+func (WorkerScheduleSlot) TableName() string {
+	return "worker_schedule_slots"
+}
+
+// TableName specifies the table name for WorkerTimeOff
+func (WorkerTimeOff) TableName() string {
+	return "worker_time_off"
+}
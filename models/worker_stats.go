@@ -2,80 +2,90 @@ package models
 
 import (
 	"time"
+
+	"repair-service-server/money"
 )
 
 // WorkerStats tracks comprehensive worker performance metrics
 type WorkerStats struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	WorkerID  uint      `json:"worker_id" gorm:"not null;index"`
-	Worker    WorkerProfile `json:"worker" gorm:"foreignKey:WorkerID"`
-	
+	ID       uint          `json:"id" gorm:"primaryKey"`
+	WorkerID uint          `json:"worker_id" gorm:"not null;index"`
+	Worker   WorkerProfile `json:"worker" gorm:"foreignKey:WorkerID"`
+
 	// Lifetime Statistics
-	TotalJobsReceived     int     `json:"total_jobs_received" gorm:"default:0"`
-	TotalJobsResponded    int     `json:"total_jobs_responded" gorm:"default:0"`
-	TotalJobsCompleted    int     `json:"total_jobs_completed" gorm:"default:0"`
-	TotalJobsDeclined     int     `json:"total_jobs_declined" gorm:"default:0"`
-	TotalEarnings         float64 `json:"total_earnings" gorm:"default:0"`
-	TotalWorkHours        float64 `json:"total_work_hours" gorm:"default:0"`
-	
+	TotalJobsReceived  int          `json:"total_jobs_received" gorm:"default:0"`
+	TotalJobsResponded int          `json:"total_jobs_responded" gorm:"default:0"`
+	TotalJobsCompleted int          `json:"total_jobs_completed" gorm:"default:0"`
+	TotalJobsDeclined  int          `json:"total_jobs_declined" gorm:"default:0"`
+	TotalEarnings      money.Amount `json:"total_earnings" gorm:"type:decimal(12,2);default:0"`
+	TotalWorkHours     float64      `json:"total_work_hours" gorm:"default:0"`
+	TotalTips          money.Amount `json:"total_tips" gorm:"type:decimal(12,2);default:0"` // Not included in TotalEarnings
+
 	// Monthly Statistics (current month)
-	MonthlyJobsReceived   int     `json:"monthly_jobs_received" gorm:"default:0"`
-	MonthlyJobsResponded  int     `json:"monthly_jobs_responded" gorm:"default:0"`
-	MonthlyJobsCompleted  int     `json:"monthly_jobs_completed" gorm:"default:0"`
-	MonthlyJobsDeclined   int     `json:"monthly_jobs_declined" gorm:"default:0"`
-	MonthlyEarnings       float64 `json:"monthly_earnings" gorm:"default:0"`
-	MonthlyWorkHours      float64 `json:"monthly_work_hours" gorm:"default:0"`
-	
+	MonthlyJobsReceived  int          `json:"monthly_jobs_received" gorm:"default:0"`
+	MonthlyJobsResponded int          `json:"monthly_jobs_responded" gorm:"default:0"`
+	MonthlyJobsCompleted int          `json:"monthly_jobs_completed" gorm:"default:0"`
+	MonthlyJobsDeclined  int          `json:"monthly_jobs_declined" gorm:"default:0"`
+	MonthlyEarnings      money.Amount `json:"monthly_earnings" gorm:"type:decimal(12,2);default:0"`
+	MonthlyWorkHours     float64      `json:"monthly_work_hours" gorm:"default:0"`
+	MonthlyTips          money.Amount `json:"monthly_tips" gorm:"type:decimal(12,2);default:0"`
+
 	// Daily Statistics (current day)
-	DailyJobsReceived     int     `json:"daily_jobs_received" gorm:"default:0"`
-	DailyJobsResponded    int     `json:"daily_jobs_responded" gorm:"default:0"`
-	DailyJobsCompleted    int     `json:"daily_jobs_completed" gorm:"default:0"`
-	DailyJobsDeclined     int     `json:"daily_jobs_declined" gorm:"default:0"`
-	DailyEarnings         float64 `json:"daily_earnings" gorm:"default:0"`
-	DailyWorkHours        float64 `json:"daily_work_hours" gorm:"default:0"`
-	
+	DailyJobsReceived  int          `json:"daily_jobs_received" gorm:"default:0"`
+	DailyJobsResponded int          `json:"daily_jobs_responded" gorm:"default:0"`
+	DailyJobsCompleted int          `json:"daily_jobs_completed" gorm:"default:0"`
+	DailyJobsDeclined  int          `json:"daily_jobs_declined" gorm:"default:0"`
+	DailyEarnings      money.Amount `json:"daily_earnings" gorm:"type:decimal(12,2);default:0"`
+	DailyWorkHours     float64      `json:"daily_work_hours" gorm:"default:0"`
+	DailyTips          money.Amount `json:"daily_tips" gorm:"type:decimal(12,2);default:0"`
+
 	// Performance Metrics
-	ResponseRate          float64 `json:"response_rate" gorm:"default:0"` // Percentage of jobs responded to
-	CompletionRate        float64 `json:"completion_rate" gorm:"default:0"` // Percentage of responded jobs completed
-	AverageResponseTime   float64 `json:"average_response_time" gorm:"default:0"` // Average time to respond in minutes
-	AverageJobDuration    float64 `json:"average_job_duration" gorm:"default:0"` // Average job completion time in hours
-	AverageEarningsPerJob float64 `json:"average_earnings_per_job" gorm:"default:0"`
-	
+	ResponseRate          float64      `json:"response_rate" gorm:"default:0"`         // Percentage of jobs responded to
+	CompletionRate        float64      `json:"completion_rate" gorm:"default:0"`       // Percentage of responded jobs completed
+	AverageResponseTime   float64      `json:"average_response_time" gorm:"default:0"` // Average time to respond in minutes
+	AverageJobDuration    float64      `json:"average_job_duration" gorm:"default:0"`  // Average job completion time in hours
+	AverageEarningsPerJob money.Amount `json:"average_earnings_per_job" gorm:"type:decimal(12,2);default:0"`
+	AverageTravelTime     float64      `json:"average_travel_time" gorm:"default:0"` // Average minutes from en route to arrival
+	TotalTravels          int          `json:"total_travels" gorm:"default:0"`       // Trips counted in AverageTravelTime
+
 	// Customer Satisfaction
-	AverageRating         float64 `json:"average_rating" gorm:"default:0"`
-	TotalRatings          int     `json:"total_ratings" gorm:"default:0"`
-	
+	AverageRating float64 `json:"average_rating" gorm:"default:0"`
+	TotalRatings  int     `json:"total_ratings" gorm:"default:0"`
+
 	// Last Updated
-	LastJobReceived       *time.Time `json:"last_job_received"`
-	LastJobResponded      *time.Time `json:"last_job_responded"`
-	LastJobCompleted      *time.Time `json:"last_job_completed"`
-	LastEarning           *time.Time `json:"last_earning"`
-	
+	LastJobReceived  *time.Time `json:"last_job_received"`
+	LastJobResponded *time.Time `json:"last_job_responded"`
+	LastJobCompleted *time.Time `json:"last_job_completed"`
+	LastEarning      *time.Time `json:"last_earning"`
+
 	// Timestamps
-	CreatedAt             time.Time  `json:"created_at"`
-	UpdatedAt             time.Time  `json:"updated_at"`
-	DeletedAt             *time.Time `json:"deleted_at" gorm:"index"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
 }
 
 // WorkerDailyStats tracks daily performance for trend analysis
 type WorkerDailyStats struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	WorkerID  uint      `json:"worker_id" gorm:"not null;index"`
-	Date      time.Time `json:"date" gorm:"not null;index"`
-	
+	ID       uint      `json:"id" gorm:"primaryKey"`
+	WorkerID uint      `json:"worker_id" gorm:"not null;index"`
+	Date     time.Time `json:"date" gorm:"not null;index"`
+
 	// Daily Metrics
-	JobsReceived     int     `json:"jobs_received"`
-	JobsResponded    int     `json:"jobs_responded"`
-	JobsCompleted    int     `json:"jobs_completed"`
-	JobsDeclined     int     `json:"jobs_declined"`
-	Earnings         float64 `json:"earnings"`
-	WorkHours        float64 `json:"work_hours"`
-	AverageRating    float64 `json:"average_rating"`
-	
+	JobsReceived  int          `json:"jobs_received"`
+	JobsResponded int          `json:"jobs_responded"`
+	JobsCompleted int          `json:"jobs_completed"`
+	JobsDeclined  int          `json:"jobs_declined"`
+	Earnings      money.Amount `json:"earnings" gorm:"type:decimal(12,2)"`
+	WorkHours     float64      `json:"work_hours"`
+	Tips          money.Amount `json:"tips" gorm:"type:decimal(12,2)"` // Not included in Earnings
+	AverageRating float64      `json:"average_rating"`
+
 	// Response Time Metrics
 	TotalResponseTime float64 `json:"total_response_time"` // Total response time in minutes
 	JobsWithResponse  int     `json:"jobs_with_response"`  // Jobs that had response time tracked
-	
+	TotalTravelTime   float64 `json:"total_travel_time"`   // Total en route to arrival time in minutes
+	JobsWithTravel    int     `json:"jobs_with_travel"`    // Jobs that had travel time tracked
+
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
@@ -83,27 +93,28 @@ type WorkerDailyStats struct {
 
 // WorkerMonthlyStats tracks monthly performance for trend analysis
 type WorkerMonthlyStats struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	WorkerID  uint      `json:"worker_id" gorm:"not null;index"`
-	Year      int       `json:"year" gorm:"not null;index"`
-	Month     int       `json:"month" gorm:"not null;index"`
-	
+	ID       uint `json:"id" gorm:"primaryKey"`
+	WorkerID uint `json:"worker_id" gorm:"not null;index"`
+	Year     int  `json:"year" gorm:"not null;index"`
+	Month    int  `json:"month" gorm:"not null;index"`
+
 	// Monthly Metrics
-	JobsReceived     int     `json:"jobs_received"`
-	JobsResponded    int     `json:"jobs_responded"`
-	JobsCompleted    int     `json:"jobs_completed"`
-	JobsDeclined     int     `json:"jobs_declined"`
-	Earnings         float64 `json:"earnings"`
-	WorkHours        float64 `json:"work_hours"`
-	AverageRating    float64 `json:"average_rating"`
-	
+	JobsReceived  int          `json:"jobs_received"`
+	JobsResponded int          `json:"jobs_responded"`
+	JobsCompleted int          `json:"jobs_completed"`
+	JobsDeclined  int          `json:"jobs_declined"`
+	Earnings      money.Amount `json:"earnings" gorm:"type:decimal(12,2)"`
+	WorkHours     float64      `json:"work_hours"`
+	Tips          money.Amount `json:"tips" gorm:"type:decimal(12,2)"` // Not included in Earnings
+	AverageRating float64      `json:"average_rating"`
+
 	// Performance Metrics
-	ResponseRate          float64 `json:"response_rate"`
-	CompletionRate        float64 `json:"completion_rate"`
-	AverageResponseTime   float64 `json:"average_response_time"`
-	AverageJobDuration    float64 `json:"average_job_duration"`
-	AverageEarningsPerJob float64 `json:"average_earnings_per_job"`
-	
+	ResponseRate          float64      `json:"response_rate"`
+	CompletionRate        float64      `json:"completion_rate"`
+	AverageResponseTime   float64      `json:"average_response_time"`
+	AverageJobDuration    float64      `json:"average_job_duration"`
+	AverageEarningsPerJob money.Amount `json:"average_earnings_per_job" gorm:"type:decimal(12,2)"`
+
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"deleted_at" gorm:"index"`
@@ -160,9 +171,9 @@ func (WorkerMonthlyStats) TableName() string {
 // WorkerJobTracking tracks which jobs have been processed for analytics to prevent duplicates
 type WorkerJobTracking struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
-	WorkerID        uint      `json:"worker_id" gorm:"not null;index"`
-	ServiceRequestID uint      `json:"service_request_id" gorm:"not null;index"`
-	JobType         string    `json:"job_type" gorm:"not null"` // "completion", "response", "received", "declined"
+	WorkerID        uint      `json:"worker_id" gorm:"not null;index;uniqueIndex:idx_worker_job_tracking_event"`
+	ServiceRequestID uint      `json:"service_request_id" gorm:"not null;index;uniqueIndex:idx_worker_job_tracking_event"`
+	JobType         string    `json:"job_type" gorm:"not null;uniqueIndex:idx_worker_job_tracking_event"` // "completion", "response", "received", "declined", "travel", "tip"
 	ProcessedAt     time.Time `json:"processed_at"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
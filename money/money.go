@@ -0,0 +1,180 @@
+// Package money represents sums of money as whole hundredths of a currency unit, so that prices,
+// earnings and analytics add up exactly instead of drifting like float64 sums. Amounts are stored
+// in decimal(…, 2) columns and written to JSON as plain decimal numbers such as 1250.50, so the
+// database schema and API shape stay those of the former float64 fields.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of minor units in one currency unit. Every supported currency, the
+// ouguiya included, is priced to two decimals.
+const Scale = 100
+
+// Amount is a sum of money in minor units (hundredths) of its currency
+type Amount int64
+
+// Money is an amount together with its ISO 4217 currency code
+type Money struct {
+	Amount   Amount `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// ErrInvalidAmount is returned when parsing text that is not a decimal number
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// FromFloat converts a float amount in currency units to minor units, rounding half away from zero
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * Scale))
+}
+
+// FromPtr converts an optional float amount, keeping nil
+func FromPtr(f *float64) *Amount {
+	if f == nil {
+		return nil
+	}
+	a := FromFloat(*f)
+	return &a
+}
+
+// Parse reads a decimal amount such as "1250", "-3.5" or "0.125" exactly, rounding extra decimals
+// half away from zero
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if strings.ContainsAny(s, "eE") {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, ErrInvalidAmount
+		}
+		return FromFloat(f), nil
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	units, fraction, _ := strings.Cut(s, ".")
+	if units == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	if units == "" {
+		units = "0"
+	}
+	whole, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	var cents int64
+	for i, digit := range fraction {
+		if digit < '0' || digit > '9' {
+			return 0, ErrInvalidAmount
+		}
+		switch {
+		case i == 0:
+			cents += int64(digit-'0') * 10
+		case i == 1:
+			cents += int64(digit - '0')
+		case i == 2 && digit >= '5':
+			cents++
+		}
+	}
+	a := Amount(whole*Scale + cents)
+	if negative {
+		a = -a
+	}
+	return a, nil
+}
+
+// Float returns the amount in currency units, for ratios and display only
+func (a Amount) Float() float64 {
+	return float64(a) / Scale
+}
+
+// Ptr returns a pointer to a copy of the amount
+func (a Amount) Ptr() *Amount {
+	return &a
+}
+
+// Abs returns the amount without its sign
+func (a Amount) Abs() Amount {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// Percent returns percent percent of the amount, rounded half away from zero to the minor unit
+func (a Amount) Percent(percent float64) Amount {
+	return Amount(math.Round(float64(a) * percent / 100))
+}
+
+// Mul returns the amount multiplied by factor, rounded half away from zero to the minor unit
+func (a Amount) Mul(factor float64) Amount {
+	return Amount(math.Round(float64(a) * factor))
+}
+
+// Div splits the amount into n equal parts, rounded half away from zero. It returns 0 when n is 0.
+func (a Amount) Div(n int64) Amount {
+	if n == 0 {
+		return 0
+	}
+	return Amount(math.Round(float64(a) / float64(n)))
+}
+
+// String formats the amount as a plain decimal with two digits, such as -1250.50
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/Scale, v%Scale)
+}
+
+// MarshalJSON writes the amount as a decimal number
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON reads a decimal number, or a quoted one
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "null" {
+		return nil
+	}
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// Scan reads a decimal column
+func (a *Amount) Scan(src interface{}) error {
+	var err error
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case []byte:
+		*a, err = Parse(string(v))
+	case string:
+		*a, err = Parse(v)
+	case float64:
+		*a = FromFloat(v)
+	case int64:
+		*a = Amount(v * Scale)
+	default:
+		err = fmt.Errorf("money: cannot scan %T", src)
+	}
+	return err
+}
+
+// Value writes the amount as a decimal, so columns keep holding currency units
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
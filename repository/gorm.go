@@ -0,0 +1,422 @@
+package repository
+
+import (
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/models"
+)
+
+type gormUserRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepo) filtered(filter UserFilter) *gorm.DB {
+	query := r.db.Model(&models.User{})
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	return query
+}
+
+func (r *gormUserRepo) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepo) List(filter UserFilter, page Page) ([]models.User, int64, error) {
+	var total int64
+	if err := r.filtered(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := r.filtered(filter).Offset(page.Offset).Limit(page.Limit).Order("created_at DESC").Find(&users).Error
+	return users, total, err
+}
+
+func (r *gormUserRepo) EachBatch(filter UserFilter, size int, fn func([]models.User) error) error {
+	var batch []models.User
+	return r.filtered(filter).FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+func (r *gormUserRepo) Save(user *models.User) error {
+	return r.db.Save(user).Error
+}
+
+func (r *gormUserRepo) Delete(user *models.User) error {
+	return r.db.Delete(user).Error
+}
+
+func (r *gormUserRepo) FindWorkerByUserID(userID uint) (*models.WorkerProfile, error) {
+	var worker models.WorkerProfile
+	if err := r.db.Where("user_id = ?", userID).First(&worker).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &worker, nil
+}
+
+type gormRequestRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormRequestRepo) filtered(filter RequestFilter) *gorm.DB {
+	query := r.db.Model(&models.CustomerServiceRequest{}).Preload("Customer").Preload("AssignedWorker.User").Preload("Category")
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return query
+}
+
+func (r *gormRequestRepo) FindByID(id uint) (*models.CustomerServiceRequest, error) {
+	var request models.CustomerServiceRequest
+	if err := r.db.Preload("Customer").Preload("AssignedWorker.User").Preload("Category").First(&request, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &request, nil
+}
+
+func (r *gormRequestRepo) FindForCustomer(id, customerID uint) (*models.CustomerServiceRequest, error) {
+	var request models.CustomerServiceRequest
+	if err := r.db.Where("id = ? AND customer_id = ?", id, customerID).First(&request).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &request, nil
+}
+
+func (r *gormRequestRepo) List(filter RequestFilter, page Page) ([]models.CustomerServiceRequest, int64, error) {
+	var total int64
+	if err := r.filtered(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var requests []models.CustomerServiceRequest
+	err := r.filtered(filter).Offset(page.Offset).Limit(page.Limit).Order("created_at DESC").Find(&requests).Error
+	return requests, total, err
+}
+
+func (r *gormRequestRepo) EachBatch(filter RequestFilter, size int, fn func([]models.CustomerServiceRequest) error) error {
+	var batch []models.CustomerServiceRequest
+	return r.filtered(filter).FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+type gormChatRepo struct {
+	db *gorm.DB
+}
+
+func (r *gormChatRepo) ListRoomsForUser(userID uint) ([]models.ChatRoom, error) {
+	var rooms []models.ChatRoom
+	err := r.db.
+		Preload("Customer").
+		Preload("Worker").
+		Preload("ServiceRequest").
+		Where("(customer_id = ? OR worker_id = ?) AND archived_at IS NULL", userID, userID).
+		Order("last_message_at DESC NULLS LAST, created_at DESC").
+		Find(&rooms).Error
+	return rooms, err
+}
+
+func (r *gormChatRepo) FindRoomForUser(roomID, userID uint) (*models.ChatRoom, error) {
+	var room models.ChatRoom
+	if err := r.db.Where("id = ? AND (customer_id = ? OR worker_id = ?)", roomID, userID, userID).
+		First(&room).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &room, nil
+}
+
+func (r *gormChatRepo) RoomDetails(roomID, userID uint) (*models.ChatRoom, error) {
+	var room models.ChatRoom
+	if err := r.db.
+		Preload("Customer").
+		Preload("Worker").
+		Preload("ServiceRequest").
+		Where("id = ? AND (customer_id = ? OR worker_id = ?)", roomID, userID, userID).
+		First(&room).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &room, nil
+}
+
+func (r *gormChatRepo) FindRoom(customerID, workerID, serviceRequestID uint) (*models.ChatRoom, error) {
+	var room models.ChatRoom
+	if err := r.db.Where("customer_id = ? AND worker_id = ? AND service_request_id = ?",
+		customerID, workerID, serviceRequestID).First(&room).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &room, nil
+}
+
+func (r *gormChatRepo) CreateRoom(room *models.ChatRoom) error {
+	return r.db.Create(room).Error
+}
+
+func (r *gormChatRepo) ListMessages(roomID uint, page Page) ([]models.ChatMessage, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.ChatMessage{}).Where("chat_room_id = ?", roomID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []models.ChatMessage
+	err := r.db.
+		Where("chat_room_id = ?", roomID).
+		Order("created_at DESC").
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&messages).Error
+	return messages, total, err
+}
+
+// chatSearchQuery is the search text stemmed in French and in Arabic, matching either
+const chatSearchQuery = `(websearch_to_tsquery('french', @query) || websearch_to_tsquery('arabic', @query))`
+
+func (r *gormChatRepo) SearchMessages(userID uint, roomID *uint, query string, page Page) ([]models.ChatMessage, int64, error) {
+	args := map[string]interface{}{"query": query}
+	scope := r.db.Model(&models.ChatMessage{}).
+		Where("chat_room_id IN (?)", r.db.Model(&models.ChatRoom{}).Select("id").Where("customer_id = ? OR worker_id = ?", userID, userID)).
+		Where("search_vector @@ "+chatSearchQuery, args)
+	if roomID != nil {
+		scope = scope.Where("chat_room_id = ?", *roomID)
+	}
+
+	var total int64
+	if err := scope.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var messages []models.ChatMessage
+	err := scope.
+		Clauses(clause.OrderBy{Expression: clause.NamedExpr{SQL: "ts_rank_cd(search_vector, " + chatSearchQuery + ") DESC, created_at DESC", Vars: []interface{}{args}}}).
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&messages).Error
+	return messages, total, err
+}
+
+func (r *gormChatRepo) MessageContext(roomID, messageID uint, size int) ([]models.ChatMessage, []models.ChatMessage, error) {
+	var before, after []models.ChatMessage
+	if err := r.db.Where("chat_room_id = ? AND id < ?", roomID, messageID).Order("id DESC").Limit(size).Find(&before).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := r.db.Where("chat_room_id = ? AND id > ?", roomID, messageID).Order("id").Limit(size).Find(&after).Error; err != nil {
+		return nil, nil, err
+	}
+	for i, j := 0, len(before)-1; i < j; i, j = i+1, j-1 {
+		before[i], before[j] = before[j], before[i]
+	}
+	return before, after, nil
+}
+
+func (r *gormChatRepo) CreateMessage(message *models.ChatMessage) error {
+	return r.db.Create(message).Error
+}
+
+func (r *gormChatRepo) FindMessage(messageID uint) (*models.ChatMessage, error) {
+	var message models.ChatMessage
+	if err := r.db.Where("id = ? AND deleted_at IS NULL", messageID).First(&message).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &message, nil
+}
+
+func (r *gormChatRepo) PinnedMessages(roomID uint) ([]models.ChatMessage, error) {
+	var messages []models.ChatMessage
+	err := r.db.Where("chat_room_id = ? AND pinned_at IS NOT NULL AND deleted_at IS NULL", roomID).
+		Order("pinned_at DESC").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *gormChatRepo) UpdateLocation(message *models.ChatMessage) error {
+	result := r.db.Model(message).Where("live_ended_at IS NULL").Updates(map[string]interface{}{
+		"latitude":      message.Latitude,
+		"longitude":     message.Longitude,
+		"live_ended_at": message.LiveEndedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormChatRepo) SetPinned(message *models.ChatMessage) error {
+	return r.db.Model(message).Updates(map[string]interface{}{
+		"pinned_at": message.PinnedAt,
+		"pinned_by": message.PinnedBy,
+	}).Error
+}
+
+func (r *gormChatRepo) TouchRoom(room *models.ChatRoom, text string, at time.Time) error {
+	return r.db.Model(room).Updates(map[string]interface{}{
+		"last_message_at":   &at,
+		"last_message_text": text,
+		"unread_count":      gorm.Expr("unread_count + 1"),
+	}).Error
+}
+
+func (r *gormChatRepo) MarkDelivered(roomID, recipientID, upToID uint, at time.Time) (uint, error) {
+	return r.markMessages(roomID, recipientID, upToID, "delivered_at", map[string]interface{}{"delivered_at": at})
+}
+
+func (r *gormChatRepo) MarkRead(roomID, recipientID, upToID uint, at time.Time) (uint, error) {
+	return r.markMessages(roomID, recipientID, upToID, "read_at", map[string]interface{}{
+		"read_at":      at,
+		"delivered_at": gorm.Expr("COALESCE(delivered_at, ?)", at),
+	})
+}
+
+// markMessages applies updates to the room's messages to recipientID that have no value in column
+func (r *gormChatRepo) markMessages(roomID, recipientID, upToID uint, column string, updates map[string]interface{}) (uint, error) {
+	scope := func() *gorm.DB {
+		q := r.db.Model(&models.ChatMessage{}).
+			Where("chat_room_id = ? AND sender_id <> ?", roomID, recipientID).
+			Where(column + " IS NULL")
+		if upToID != 0 {
+			q = q.Where("id <= ?", upToID)
+		}
+		return q
+	}
+
+	var lastID uint
+	if err := scope().Select("COALESCE(MAX(id), 0)").Scan(&lastID).Error; err != nil {
+		return 0, err
+	}
+	if lastID == 0 {
+		return 0, nil
+	}
+	// Messages sent after the lookup are left for the next receipt
+	return lastID, scope().Where("id <= ?", lastID).Updates(updates).Error
+}
+
+type gormAnalyticsRepo struct {
+	db *gorm.DB
+}
+
+// leaderboardRow is a ranked leaderboard value before its worker is loaded
+type leaderboardRow struct {
+	Rank     int
+	WorkerID uint
+	Value    float64
+}
+
+func (r *gormAnalyticsRepo) DailyStats(workerID uint, since time.Time) ([]models.WorkerDailyStats, error) {
+	var stats []models.WorkerDailyStats
+	err := r.db.Where("worker_id = ? AND date >= ?", workerID, since).Order("date ASC").Find(&stats).Error
+	return stats, err
+}
+
+func (r *gormAnalyticsRepo) MonthlyStats(workerID uint, since time.Time) ([]models.WorkerMonthlyStats, error) {
+	var stats []models.WorkerMonthlyStats
+	err := r.db.Where("worker_id = ? AND (year > ? OR (year = ? AND month >= ?))",
+		workerID, since.Year(), since.Year(), int(since.Month())).
+		Order("year ASC, month ASC").Find(&stats).Error
+	return stats, err
+}
+
+func (r *gormAnalyticsRepo) Leaderboard(query LeaderboardQuery, workerID uint) ([]LeaderboardEntry, *LeaderboardEntry, error) {
+	order := "m.value DESC"
+	if query.Metric == LeaderboardResponseTime {
+		order = "m.value ASC"
+	}
+	ranked := r.db.Table("(?) AS m", r.leaderboardValues(query)).
+		Select("m.worker_id, m.value, RANK() OVER (ORDER BY "+order+") AS rank").
+		Joins("JOIN worker_profiles wp ON wp.id = m.worker_id AND wp.deleted_at IS NULL").
+		Where("wp.category_id = ? OR wp.id IN (SELECT worker_id FROM worker_categories WHERE category_id = ? AND verified_at IS NOT NULL)", query.CategoryID, query.CategoryID)
+	if query.City != "" {
+		ranked = ranked.Where("LOWER(wp.city) = LOWER(?)", query.City)
+	}
+	if query.ZoneID != nil {
+		zone := r.db.Table("customer_service_requests").Select("assigned_worker_id").
+			Where("service_zone_id = ? AND status = ?", *query.ZoneID, models.RequestStatusCompleted)
+		if query.Period != LeaderboardAllTime {
+			zone = zone.Where("completed_at >= ?", query.Since)
+		}
+		ranked = ranked.Where("m.worker_id IN (?)", zone)
+	}
+
+	var rows []leaderboardRow
+	if err := r.db.Table("(?) AS ranked", ranked).Select("rank, worker_id, value").
+		Order("rank, worker_id").Limit(query.Limit).Scan(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	var own []leaderboardRow
+	if err := r.db.Table("(?) AS ranked", ranked).Select("rank, worker_id, value").
+		Where("worker_id = ?", workerID).Scan(&own).Error; err != nil {
+		return nil, nil, err
+	}
+
+	ids := []uint{workerID}
+	for _, row := range rows {
+		ids = append(ids, row.WorkerID)
+	}
+	var workers []models.WorkerProfile
+	if err := r.db.Preload("User").Preload("Category").Preload("Badges").Find(&workers, ids).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint]models.WorkerProfile, len(workers))
+	for _, w := range workers {
+		byID[w.ID] = w
+	}
+
+	entry := func(row leaderboardRow) LeaderboardEntry {
+		return LeaderboardEntry{
+			Rank:     row.Rank,
+			WorkerID: row.WorkerID,
+			Value:    math.Round(row.Value*100) / 100,
+			Worker:   byID[row.WorkerID],
+		}
+	}
+	leaderboard := make([]LeaderboardEntry, 0, len(rows))
+	for _, row := range rows {
+		leaderboard = append(leaderboard, entry(row))
+	}
+	if len(own) == 0 {
+		return leaderboard, nil, nil
+	}
+	mine := entry(own[0])
+	return leaderboard, &mine, nil
+}
+
+// leaderboardValues selects each worker's value for the query's metric over its period, leaving
+// out workers the metric means nothing for yet
+func (r *gormAnalyticsRepo) leaderboardValues(query LeaderboardQuery) *gorm.DB {
+	if query.Period == LeaderboardAllTime {
+		switch query.Metric {
+		case LeaderboardJobs:
+			return r.db.Table("worker_stats").Select("worker_id, total_jobs_completed AS value").Where("total_jobs_completed > 0")
+		case LeaderboardRating:
+			return r.db.Table("worker_profiles").Select("id AS worker_id, rating AS value").Where("total_reviews > 0")
+		case LeaderboardResponseTime:
+			return r.db.Table("worker_stats").Select("worker_id, average_response_time AS value").Where("total_jobs_responded > 0")
+		}
+		return r.db.Table("worker_stats").Select("worker_id, total_earnings AS value")
+	}
+
+	switch query.Metric {
+	case LeaderboardJobs:
+		return r.db.Table("worker_daily_stats").Select("worker_id, SUM(jobs_completed) AS value").
+			Where("date >= ?", query.Since).Group("worker_id").Having("SUM(jobs_completed) > 0")
+	case LeaderboardRating:
+		return r.db.Table("worker_ratings").Select("worker_id, AVG(stars) AS value").
+			Where("created_at >= ? AND deleted_at IS NULL AND moderation_status <> ?", query.Since, models.ReviewHidden).
+			Group("worker_id")
+	case LeaderboardResponseTime:
+		return r.db.Table("worker_daily_stats").Select("worker_id, SUM(total_response_time) / SUM(jobs_with_response) AS value").
+			Where("date >= ?", query.Since).Group("worker_id").Having("SUM(jobs_with_response) > 0")
+	}
+	return r.db.Table("worker_daily_stats").Select("worker_id, SUM(earnings) AS value").
+		Where("date >= ?", query.Since).Group("worker_id")
+}
@@ -0,0 +1,655 @@
+// Package mock provides in-memory implementations of the repository interfaces for unit
+// testing handlers without a database.
+package mock
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"repair-service-server/models"
+	"repair-service-server/repository"
+)
+
+// NewRepositories creates empty in-memory repositories
+func NewRepositories() *repository.Repositories {
+	return &repository.Repositories{
+		Users:     NewUserRepo(),
+		Requests:  NewRequestRepo(),
+		Chats:     NewChatRepo(),
+		Analytics: NewAnalyticsRepo(),
+	}
+}
+
+// window returns the slice bounds selected by page
+func window(n int, page repository.Page) (int, int) {
+	start := page.Offset
+	if start > n {
+		start = n
+	}
+	end := n
+	if page.Limit > 0 && start+page.Limit < n {
+		end = start + page.Limit
+	}
+	return start, end
+}
+
+// UserRepo is an in-memory repository.UserRepo
+type UserRepo struct {
+	mu      sync.Mutex
+	nextID  uint
+	Users   map[uint]models.User
+	Workers map[uint]models.WorkerProfile // keyed by user ID
+}
+
+// NewUserRepo creates an empty user repository
+func NewUserRepo() *UserRepo {
+	return &UserRepo{
+		Users:   make(map[uint]models.User),
+		Workers: make(map[uint]models.WorkerProfile),
+	}
+}
+
+func (r *UserRepo) FindByID(id uint) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.Users[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *UserRepo) matching(filter repository.UserFilter) []models.User {
+	var users []models.User
+	for _, user := range r.Users {
+		if filter.Role != "" && string(user.Role) != filter.Role {
+			continue
+		}
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].CreatedAt.After(users[j].CreatedAt)
+	})
+	return users
+}
+
+func (r *UserRepo) List(filter repository.UserFilter, page repository.Page) ([]models.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := r.matching(filter)
+	start, end := window(len(users), page)
+	return users[start:end], int64(len(users)), nil
+}
+
+func (r *UserRepo) EachBatch(filter repository.UserFilter, size int, fn func([]models.User) error) error {
+	r.mu.Lock()
+	users := r.matching(filter)
+	r.mu.Unlock()
+
+	for start := 0; start < len(users); start += size {
+		end := start + size
+		if end > len(users) {
+			end = len(users)
+		}
+		if err := fn(users[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *UserRepo) Save(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user.ID == 0 {
+		r.nextID++
+		user.ID = r.nextID
+		user.CreatedAt = time.Now()
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	user.UpdatedAt = time.Now()
+	r.Users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepo) Delete(user *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.Users[user.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.Users, user.ID)
+	return nil
+}
+
+func (r *UserRepo) FindWorkerByUserID(userID uint) (*models.WorkerProfile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	worker, ok := r.Workers[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &worker, nil
+}
+
+// RequestRepo is an in-memory repository.RequestRepo
+type RequestRepo struct {
+	mu       sync.Mutex
+	Requests map[uint]models.CustomerServiceRequest
+}
+
+// NewRequestRepo creates an empty service request repository
+func NewRequestRepo() *RequestRepo {
+	return &RequestRepo{Requests: make(map[uint]models.CustomerServiceRequest)}
+}
+
+// Add stores a request, replacing any request with the same ID
+func (r *RequestRepo) Add(request models.CustomerServiceRequest) {
+	r.mu.Lock()
+	r.Requests[request.ID] = request
+	r.mu.Unlock()
+}
+
+func (r *RequestRepo) FindByID(id uint) (*models.CustomerServiceRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	request, ok := r.Requests[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &request, nil
+}
+
+func (r *RequestRepo) FindForCustomer(id, customerID uint) (*models.CustomerServiceRequest, error) {
+	request, err := r.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.CustomerID != customerID {
+		return nil, repository.ErrNotFound
+	}
+	return request, nil
+}
+
+func (r *RequestRepo) matching(filter repository.RequestFilter) []models.CustomerServiceRequest {
+	var requests []models.CustomerServiceRequest
+	for _, request := range r.Requests {
+		if filter.Status != "" && string(request.Status) != filter.Status {
+			continue
+		}
+		requests = append(requests, request)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedAt.After(requests[j].CreatedAt)
+	})
+	return requests
+}
+
+func (r *RequestRepo) List(filter repository.RequestFilter, page repository.Page) ([]models.CustomerServiceRequest, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	requests := r.matching(filter)
+	start, end := window(len(requests), page)
+	return requests[start:end], int64(len(requests)), nil
+}
+
+func (r *RequestRepo) EachBatch(filter repository.RequestFilter, size int, fn func([]models.CustomerServiceRequest) error) error {
+	r.mu.Lock()
+	requests := r.matching(filter)
+	r.mu.Unlock()
+
+	for start := 0; start < len(requests); start += size {
+		end := start + size
+		if end > len(requests) {
+			end = len(requests)
+		}
+		if err := fn(requests[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ChatRepo is an in-memory repository.ChatRepo
+type ChatRepo struct {
+	mu            sync.Mutex
+	nextRoomID    uint
+	nextMessageID uint
+	Rooms         map[uint]models.ChatRoom
+	Messages      []models.ChatMessage
+}
+
+// NewChatRepo creates an empty chat repository
+func NewChatRepo() *ChatRepo {
+	return &ChatRepo{Rooms: make(map[uint]models.ChatRoom)}
+}
+
+func (r *ChatRepo) ListRoomsForUser(userID uint) ([]models.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var rooms []models.ChatRoom
+	for _, room := range r.Rooms {
+		if (room.CustomerID == userID || room.WorkerID == userID) && room.ArchivedAt == nil {
+			rooms = append(rooms, room)
+		}
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].ID > rooms[j].ID
+	})
+	return rooms, nil
+}
+
+func (r *ChatRepo) FindRoomForUser(roomID, userID uint) (*models.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	room, ok := r.Rooms[roomID]
+	if !ok || (room.CustomerID != userID && room.WorkerID != userID) {
+		return nil, repository.ErrNotFound
+	}
+	return &room, nil
+}
+
+func (r *ChatRepo) RoomDetails(roomID, userID uint) (*models.ChatRoom, error) {
+	return r.FindRoomForUser(roomID, userID)
+}
+
+func (r *ChatRepo) FindRoom(customerID, workerID, serviceRequestID uint) (*models.ChatRoom, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, room := range r.Rooms {
+		if room.CustomerID == customerID && room.WorkerID == workerID && room.ServiceRequestID == serviceRequestID {
+			return &room, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *ChatRepo) CreateRoom(room *models.ChatRoom) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextRoomID++
+	room.ID = r.nextRoomID
+	room.CreatedAt = time.Now()
+	r.Rooms[room.ID] = *room
+	return nil
+}
+
+func (r *ChatRepo) ListMessages(roomID uint, page repository.Page) ([]models.ChatMessage, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var messages []models.ChatMessage
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		if r.Messages[i].ChatRoomID == roomID {
+			messages = append(messages, r.Messages[i])
+		}
+	}
+	start, end := window(len(messages), page)
+	return messages[start:end], int64(len(messages)), nil
+}
+
+func (r *ChatRepo) SearchMessages(userID uint, roomID *uint, query string, page repository.Page) ([]models.ChatMessage, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	query = strings.ToLower(query)
+	var messages []models.ChatMessage
+	for i := len(r.Messages) - 1; i >= 0; i-- {
+		m := r.Messages[i]
+		room, ok := r.Rooms[m.ChatRoomID]
+		if !ok || (room.CustomerID != userID && room.WorkerID != userID) {
+			continue
+		}
+		if roomID != nil && m.ChatRoomID != *roomID {
+			continue
+		}
+		if strings.Contains(strings.ToLower(m.Content), query) || strings.Contains(strings.ToLower(m.Transcript), query) {
+			messages = append(messages, m)
+		}
+	}
+	start, end := window(len(messages), page)
+	return messages[start:end], int64(len(messages)), nil
+}
+
+func (r *ChatRepo) MessageContext(roomID, messageID uint, size int) ([]models.ChatMessage, []models.ChatMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var before, after []models.ChatMessage
+	for _, m := range r.Messages {
+		switch {
+		case m.ChatRoomID != roomID:
+		case m.ID < messageID:
+			before = append(before, m)
+		case m.ID > messageID && len(after) < size:
+			after = append(after, m)
+		}
+	}
+	if len(before) > size {
+		before = before[len(before)-size:]
+	}
+	return before, after, nil
+}
+
+func (r *ChatRepo) FindMessage(messageID uint) (*models.ChatMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.Messages {
+		if m.ID == messageID && m.DeletedAt == nil {
+			return &m, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *ChatRepo) PinnedMessages(roomID uint) ([]models.ChatMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var pinned []models.ChatMessage
+	for _, m := range r.Messages {
+		if m.ChatRoomID == roomID && m.PinnedAt != nil && m.DeletedAt == nil {
+			pinned = append(pinned, m)
+		}
+	}
+	sort.SliceStable(pinned, func(i, j int) bool { return pinned[i].PinnedAt.After(*pinned[j].PinnedAt) })
+	return pinned, nil
+}
+
+func (r *ChatRepo) UpdateLocation(message *models.ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.Messages {
+		if r.Messages[i].ID == message.ID && r.Messages[i].LiveEndedAt == nil {
+			r.Messages[i].Latitude = message.Latitude
+			r.Messages[i].Longitude = message.Longitude
+			r.Messages[i].LiveEndedAt = message.LiveEndedAt
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *ChatRepo) SetPinned(message *models.ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.Messages {
+		if r.Messages[i].ID == message.ID {
+			r.Messages[i].PinnedAt = message.PinnedAt
+			r.Messages[i].PinnedBy = message.PinnedBy
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *ChatRepo) CreateMessage(message *models.ChatMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextMessageID++
+	message.ID = r.nextMessageID
+	message.CreatedAt = time.Now()
+	r.Messages = append(r.Messages, *message)
+	return nil
+}
+
+func (r *ChatRepo) TouchRoom(room *models.ChatRoom, text string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.Rooms[room.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.LastMessageAt = &at
+	stored.LastMessageText = text
+	stored.UnreadCount++
+	r.Rooms[room.ID] = stored
+	return nil
+}
+
+func (r *ChatRepo) MarkDelivered(roomID, recipientID, upToID uint, at time.Time) (uint, error) {
+	return r.markMessages(roomID, recipientID, upToID, func(m *models.ChatMessage) bool {
+		if m.DeliveredAt != nil {
+			return false
+		}
+		m.DeliveredAt = &at
+		return true
+	}), nil
+}
+
+func (r *ChatRepo) MarkRead(roomID, recipientID, upToID uint, at time.Time) (uint, error) {
+	return r.markMessages(roomID, recipientID, upToID, func(m *models.ChatMessage) bool {
+		if m.ReadAt != nil {
+			return false
+		}
+		m.ReadAt = &at
+		if m.DeliveredAt == nil {
+			m.DeliveredAt = &at
+		}
+		return true
+	}), nil
+}
+
+// markMessages applies mark to the room's messages to recipientID and returns the highest ID it
+// changed
+func (r *ChatRepo) markMessages(roomID, recipientID, upToID uint, mark func(*models.ChatMessage) bool) uint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var lastID uint
+	for i := range r.Messages {
+		m := &r.Messages[i]
+		if m.ChatRoomID != roomID || m.SenderID == recipientID || (upToID != 0 && m.ID > upToID) {
+			continue
+		}
+		if mark(m) && m.ID > lastID {
+			lastID = m.ID
+		}
+	}
+	return lastID
+}
+
+// AnalyticsRepo is an in-memory repository.AnalyticsRepo. Leaderboards take workers' profiles
+// from Lifetime, and period ratings from the daily average ratings.
+type AnalyticsRepo struct {
+	mu       sync.Mutex
+	Daily    []models.WorkerDailyStats
+	Monthly  []models.WorkerMonthlyStats
+	Lifetime []models.WorkerStats
+
+	ZoneWorkers map[uint][]uint // Workers who completed a request in each zone, whatever the period
+}
+
+// NewAnalyticsRepo creates an empty analytics repository
+func NewAnalyticsRepo() *AnalyticsRepo {
+	return &AnalyticsRepo{}
+}
+
+func (r *AnalyticsRepo) DailyStats(workerID uint, since time.Time) ([]models.WorkerDailyStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stats []models.WorkerDailyStats
+	for _, s := range r.Daily {
+		if s.WorkerID == workerID && !s.Date.Before(since) {
+			stats = append(stats, s)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Date.Before(stats[j].Date)
+	})
+	return stats, nil
+}
+
+func (r *AnalyticsRepo) MonthlyStats(workerID uint, since time.Time) ([]models.WorkerMonthlyStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	from := since.Year()*12 + int(since.Month())
+	var stats []models.WorkerMonthlyStats
+	for _, s := range r.Monthly {
+		if s.WorkerID == workerID && s.Year*12+s.Month >= from {
+			stats = append(stats, s)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Year*12+stats[i].Month < stats[j].Year*12+stats[j].Month
+	})
+	return stats, nil
+}
+
+func (r *AnalyticsRepo) Leaderboard(query repository.LeaderboardQuery, workerID uint) ([]repository.LeaderboardEntry, *repository.LeaderboardEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values := r.leaderboardValues(query)
+	var entries []repository.LeaderboardEntry
+	for _, s := range r.Lifetime {
+		value, ok := values[s.WorkerID]
+		if !ok || !servesCategory(s.Worker, query.CategoryID) {
+			continue
+		}
+		if query.City != "" && !strings.EqualFold(s.Worker.City, query.City) {
+			continue
+		}
+		if query.ZoneID != nil && !containsID(r.ZoneWorkers[*query.ZoneID], s.WorkerID) {
+			continue
+		}
+		entries = append(entries, repository.LeaderboardEntry{WorkerID: s.WorkerID, Value: value, Worker: s.Worker})
+	}
+
+	ascending := query.Metric == repository.LeaderboardResponseTime
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return (entries[i].Value < entries[j].Value) == ascending
+		}
+		return entries[i].WorkerID < entries[j].WorkerID
+	})
+	var own *repository.LeaderboardEntry
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Value == entries[i-1].Value {
+			entries[i].Rank = entries[i-1].Rank
+		}
+		if entries[i].WorkerID == workerID {
+			mine := entries[i]
+			own = &mine
+		}
+	}
+	if query.Limit > 0 && len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+	}
+	return entries, own, nil
+}
+
+// leaderboardValues mirrors the SQL metric values, keyed by worker ID
+func (r *AnalyticsRepo) leaderboardValues(query repository.LeaderboardQuery) map[uint]float64 {
+	values := map[uint]float64{}
+	if query.Period == repository.LeaderboardAllTime {
+		for _, s := range r.Lifetime {
+			switch query.Metric {
+			case repository.LeaderboardJobs:
+				if s.TotalJobsCompleted > 0 {
+					values[s.WorkerID] = float64(s.TotalJobsCompleted)
+				}
+			case repository.LeaderboardRating:
+				if s.Worker.TotalReviews > 0 {
+					values[s.WorkerID] = s.Worker.Rating
+				}
+			case repository.LeaderboardResponseTime:
+				if s.TotalJobsResponded > 0 {
+					values[s.WorkerID] = s.AverageResponseTime
+				}
+			default:
+				values[s.WorkerID] = s.TotalEarnings.Float()
+			}
+		}
+		return values
+	}
+
+	sums := map[uint]float64{}
+	counts := map[uint]float64{}
+	for _, d := range r.Daily {
+		if d.Date.Before(query.Since) {
+			continue
+		}
+		switch query.Metric {
+		case repository.LeaderboardJobs:
+			sums[d.WorkerID] += float64(d.JobsCompleted)
+			counts[d.WorkerID] += float64(d.JobsCompleted)
+		case repository.LeaderboardRating:
+			if d.AverageRating > 0 {
+				sums[d.WorkerID] += d.AverageRating
+				counts[d.WorkerID]++
+			}
+		case repository.LeaderboardResponseTime:
+			sums[d.WorkerID] += d.TotalResponseTime
+			counts[d.WorkerID] += float64(d.JobsWithResponse)
+		default:
+			sums[d.WorkerID] += d.Earnings.Float()
+			counts[d.WorkerID]++
+		}
+	}
+	for id, sum := range sums {
+		if counts[id] == 0 {
+			continue
+		}
+		switch query.Metric {
+		case repository.LeaderboardRating, repository.LeaderboardResponseTime:
+			values[id] = sum / counts[id]
+		default:
+			values[id] = sum
+		}
+	}
+	return values
+}
+
+// containsID reports whether ids contains id
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Compile-time checks that the mocks satisfy the interfaces
+var (
+	_ repository.UserRepo      = (*UserRepo)(nil)
+	_ repository.RequestRepo   = (*RequestRepo)(nil)
+	_ repository.ChatRepo      = (*ChatRepo)(nil)
+	_ repository.AnalyticsRepo = (*AnalyticsRepo)(nil)
+)
+
+// servesCategory mirrors the SQL rule: the worker's main category or a verified extra one
+func servesCategory(worker models.WorkerProfile, categoryID uint) bool {
+	if worker.CategoryID == categoryID {
+		return true
+	}
+	for _, wc := range worker.Categories {
+		if wc.CategoryID == categoryID && wc.VerifiedAt != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+// Package repository defines the data access interfaces handlers depend on, so they can be
+// constructed with either the gorm implementation or an in-memory mock.
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/models"
+)
+
+// ErrNotFound is returned when a lookup matches no record
+var ErrNotFound = errors.New("record not found")
+
+// Page selects a window of a list result
+type Page struct {
+	Offset int
+	Limit  int
+}
+
+// UserFilter narrows user listings
+type UserFilter struct {
+	Role string
+}
+
+// RequestFilter narrows service request listings
+type RequestFilter struct {
+	Status string
+}
+
+// UserRepo provides access to users and their worker profiles
+type UserRepo interface {
+	FindByID(id uint) (*models.User, error)
+	List(filter UserFilter, page Page) ([]models.User, int64, error)
+	EachBatch(filter UserFilter, size int, fn func([]models.User) error) error
+	Save(user *models.User) error
+	Delete(user *models.User) error
+	FindWorkerByUserID(userID uint) (*models.WorkerProfile, error)
+}
+
+// RequestRepo provides access to customer service requests
+type RequestRepo interface {
+	// FindByID loads a request with its customer, category and assigned worker
+	FindByID(id uint) (*models.CustomerServiceRequest, error)
+	FindForCustomer(id, customerID uint) (*models.CustomerServiceRequest, error)
+	List(filter RequestFilter, page Page) ([]models.CustomerServiceRequest, int64, error)
+	EachBatch(filter RequestFilter, size int, fn func([]models.CustomerServiceRequest) error) error
+}
+
+// ChatRepo provides access to chat rooms and messages
+type ChatRepo interface {
+	// ListRoomsForUser returns the user's rooms that are not archived
+	ListRoomsForUser(userID uint) ([]models.ChatRoom, error)
+	// FindRoomForUser returns the room only if the user is one of its participants
+	FindRoomForUser(roomID, userID uint) (*models.ChatRoom, error)
+	// RoomDetails loads a room with its participants and service request
+	RoomDetails(roomID, userID uint) (*models.ChatRoom, error)
+	FindRoom(customerID, workerID, serviceRequestID uint) (*models.ChatRoom, error)
+	CreateRoom(room *models.ChatRoom) error
+	ListMessages(roomID uint, page Page) ([]models.ChatMessage, int64, error)
+	// SearchMessages finds messages whose text or voice transcript matches query, in the rooms
+	// userID takes part in, best matches first. roomID narrows the search to one room.
+	SearchMessages(userID uint, roomID *uint, query string, page Page) ([]models.ChatMessage, int64, error)
+	// MessageContext returns up to size messages sent just before and just after messageID in its
+	// room, oldest first
+	MessageContext(roomID, messageID uint, size int) (before, after []models.ChatMessage, err error)
+	CreateMessage(message *models.ChatMessage) error
+	// FindMessage returns a message that is not deleted
+	FindMessage(messageID uint) (*models.ChatMessage, error)
+	// PinnedMessages returns the room's pinned messages, most recently pinned first
+	PinnedMessages(roomID uint) ([]models.ChatMessage, error)
+	// SetPinned saves the message's PinnedAt and PinnedBy
+	SetPinned(message *models.ChatMessage) error
+	// UpdateLocation saves a live location message's coordinates and LiveEndedAt. It returns
+	// ErrNotFound once the live location has ended.
+	UpdateLocation(message *models.ChatMessage) error
+	// TouchRoom records a new message on the room's summary fields
+	TouchRoom(room *models.ChatRoom, text string, at time.Time) error
+	// MarkDelivered records that recipientID received the room's messages up to upToID, or all of
+	// them when upToID is 0. It returns the highest message ID it marked, or 0 if there were none.
+	MarkDelivered(roomID, recipientID, upToID uint, at time.Time) (uint, error)
+	// MarkRead is MarkDelivered for messages the recipient read, which also marks them delivered
+	MarkRead(roomID, recipientID, upToID uint, at time.Time) (uint, error)
+}
+
+// Leaderboard periods
+const (
+	LeaderboardAllTime = "all"
+	LeaderboardWeek    = "week"
+	LeaderboardMonth   = "month"
+)
+
+// Leaderboard metrics. Response time ranks the lowest first; the others rank the highest first.
+const (
+	LeaderboardEarnings     = "earnings"
+	LeaderboardJobs         = "jobs"
+	LeaderboardRating       = "rating"
+	LeaderboardResponseTime = "response_time"
+)
+
+// LeaderboardQuery selects what a leaderboard ranks and which workers it includes
+type LeaderboardQuery struct {
+	CategoryID uint
+	Period     string
+	Since      time.Time // Start of the week or month; unused for all time
+	Metric     string
+	City       string // Only workers based in this city, when set
+	ZoneID     *uint  // Only workers who completed a request in this zone during the period, when set
+	Limit      int
+}
+
+// LeaderboardEntry is a worker's rank and metric value on a leaderboard. Workers with the same
+// value share a rank.
+type LeaderboardEntry struct {
+	Rank     int                  `json:"rank"`
+	WorkerID uint                 `json:"worker_id"`
+	Value    float64              `json:"value"`
+	Worker   models.WorkerProfile `json:"worker"`
+}
+
+// AnalyticsRepo provides read access to worker performance statistics
+type AnalyticsRepo interface {
+	DailyStats(workerID uint, since time.Time) ([]models.WorkerDailyStats, error)
+	MonthlyStats(workerID uint, since time.Time) ([]models.WorkerMonthlyStats, error)
+	// Leaderboard returns the top query.Limit workers serving query.CategoryID, and workerID's own
+	// entry wherever it ranks, which is nil when they have no value for the metric or are out of scope
+	Leaderboard(query LeaderboardQuery, workerID uint) ([]LeaderboardEntry, *LeaderboardEntry, error)
+}
+
+// Repositories groups every repository a handler may need
+type Repositories struct {
+	Users     UserRepo
+	Requests  RequestRepo
+	Chats     ChatRepo
+	Analytics AnalyticsRepo
+}
+
+// NewGormRepositories creates gorm-backed repositories sharing one connection
+func NewGormRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Users:     &gormUserRepo{db: db},
+		Requests:  &gormRequestRepo{db: db},
+		Chats:     &gormChatRepo{db: db},
+		Analytics: &gormAnalyticsRepo{db: db},
+	}
+}
+
+// translate maps gorm's not-found error to ErrNotFound
+func translate(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -6,8 +6,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
+	"repair-service-server/serializers"
 	"repair-service-server/utils"
 )
 
@@ -19,6 +21,9 @@ func RegisterAddressRoutes(router *gin.RouterGroup) {
 	router.PUT("/:id", updateAddress)
 	router.DELETE("/:id", deleteAddress)
 	router.PUT("/:id/default", setDefaultAddress)
+	router.POST("/:id/share", shareAddress)
+	router.GET("/:id/shares", listAddressShares)
+	router.DELETE("/:id/shares/:shareId", revokeAddressShare)
 }
 
 // getUserAddresses gets all addresses for the current user
@@ -27,16 +32,13 @@ func getUserAddresses(c *gin.Context) {
 	
 	var addresses []models.Address
 	if err := database.DB.Where("user_id = ?", userID).Order("is_default DESC, created_at DESC").Find(&addresses).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get addresses",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Internal("Failed to get addresses", err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Addresses retrieved successfully",
-		"data":    addresses,
+		"data":    serializers.Addresses(addresses),
 	})
 }
 
@@ -50,19 +52,13 @@ func createAddress(c *gin.Context) {
 	
 	if userID == 0 {
 		log.Printf("❌ createAddress: user_id is 0, authentication failed")
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Authentication required",
-			"message": "User ID not found in context",
-		})
+		apierror.Abort(c, apierror.Unauthorized("User ID not found in context"))
 		return
 	}
 
 	var req models.AddressRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request data",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Validation("Invalid request data").WithDetails(err.Error()))
 		return
 	}
 
@@ -96,10 +92,7 @@ func createAddress(c *gin.Context) {
 	if req.IsDefault {
 		// Remove default from other addresses
 		if err := database.DB.Model(&models.Address{}).Where("user_id = ?", userID).Update("is_default", false).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Failed to update existing addresses",
-				"message": err.Error(),
-			})
+			apierror.Abort(c, apierror.Internal("Failed to update existing addresses", err))
 			return
 		}
 	}
@@ -109,22 +102,20 @@ func createAddress(c *gin.Context) {
 		Label:          req.Label,
 		AddressDetails: req.AddressDetails,
 		City:           req.City,
+		EntranceNotes:  req.EntranceNotes,
 		Latitude:       req.Latitude,
 		Longitude:      req.Longitude,
 		IsDefault:      req.IsDefault,
 	}
 
 	if err := database.DB.Create(&address).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to create address",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Internal("Failed to create address", err))
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Address created successfully",
-		"data":    address,
+		"data":    serializers.Address(address),
 	})
 }
 
@@ -135,16 +126,13 @@ func getAddress(c *gin.Context) {
 	
 	var address models.Address
 	if err := database.DB.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Address not found",
-			"message": "The requested address does not exist",
-		})
+		apierror.Abort(c, apierror.NotFound("The requested address does not exist"))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Address retrieved successfully",
-		"data":    address,
+		"data":    serializers.Address(address),
 	})
 }
 
@@ -155,30 +143,21 @@ func updateAddress(c *gin.Context) {
 	
 	var req models.AddressRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request data",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Validation("Invalid request data").WithDetails(err.Error()))
 		return
 	}
 
 	// Check if address exists and belongs to user
 	var existingAddress models.Address
 	if err := database.DB.Where("id = ? AND user_id = ?", addressID, userID).First(&existingAddress).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Address not found",
-			"message": "The requested address does not exist",
-		})
+		apierror.Abort(c, apierror.NotFound("The requested address does not exist"))
 		return
 	}
 
 	// If setting this address as default, remove default from others
 	if req.IsDefault && !existingAddress.IsDefault {
 		if err := database.DB.Model(&models.Address{}).Where("user_id = ?", userID).Update("is_default", false).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Failed to update existing addresses",
-				"message": err.Error(),
-			})
+			apierror.Abort(c, apierror.Internal("Failed to update existing addresses", err))
 			return
 		}
 	}
@@ -208,16 +187,14 @@ func updateAddress(c *gin.Context) {
 		"label":           req.Label,
 		"address_details": req.AddressDetails,
 		"city":            req.City,
+		"entrance_notes":  req.EntranceNotes,
 		"latitude":        req.Latitude,
 		"longitude":       req.Longitude,
 		"is_default":      req.IsDefault,
 	}
 
 	if err := database.DB.Model(&existingAddress).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update address",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Internal("Failed to update address", err))
 		return
 	}
 
@@ -227,7 +204,7 @@ func updateAddress(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Address updated successfully",
-		"data":    updatedAddress,
+		"data":    serializers.Address(updatedAddress),
 	})
 }
 
@@ -239,10 +216,7 @@ func deleteAddress(c *gin.Context) {
 	// Check if address exists and belongs to user
 	var address models.Address
 	if err := database.DB.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Address not found",
-			"message": "The requested address does not exist",
-		})
+		apierror.Abort(c, apierror.NotFound("The requested address does not exist"))
 		return
 	}
 
@@ -257,10 +231,7 @@ func deleteAddress(c *gin.Context) {
 
 	// Delete the address
 	if err := database.DB.Delete(&address).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete address",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Internal("Failed to delete address", err))
 		return
 	}
 
@@ -277,33 +248,24 @@ func setDefaultAddress(c *gin.Context) {
 	// Check if address exists and belongs to user
 	var address models.Address
 	if err := database.DB.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "Address not found",
-			"message": "The requested address does not exist",
-		})
+		apierror.Abort(c, apierror.NotFound("The requested address does not exist"))
 		return
 	}
 
 	// Remove default from all other addresses
 	if err := database.DB.Model(&models.Address{}).Where("user_id = ?", userID).Update("is_default", false).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update existing addresses",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Internal("Failed to update existing addresses", err))
 		return
 	}
 
 	// Set this address as default
 	if err := database.DB.Model(&address).Update("is_default", true).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to set address as default",
-			"message": err.Error(),
-		})
+		apierror.Abort(c, apierror.Internal("Failed to set address as default", err))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Address set as default successfully",
-		"data":    address,
+		"data":    serializers.Address(address),
 	})
 }
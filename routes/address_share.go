@@ -0,0 +1,125 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RegisterSharedLocationRoutes registers the public endpoint behind address links
+func RegisterSharedLocationRoutes(router *gin.RouterGroup) {
+	router.GET("/shared-locations/:token", GetSharedLocation)
+}
+
+// shareAddress creates a link to one of the customer's addresses for a worker to open
+func shareAddress(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid address ID"))
+		return
+	}
+	var req struct {
+		ServiceRequestID *uint `json:"service_request_id"` // The link stops working once the request is finished
+		ExpiresInHours   int   `json:"expires_in_hours" binding:"omitempty,min=1,max=72"`
+	}
+	if c.Request.ContentLength != 0 && !validation.BindJSON(c, &req) {
+		return
+	}
+	ttl := services.DefaultAddressShareTTL
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+
+	share, token, err := services.NewAddressShareService().Create(userID, uint(addressID), req.ServiceRequestID, ttl)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("The requested address does not exist"))
+		return
+	case errors.Is(err, services.ErrShareRequestNotOpen):
+		apierror.Abort(c, apierror.Unprocessable("The service request is not open"))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to share address", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Address link created",
+		"data":    serializers.AddressShare(*share, "https://"+c.Request.Host+"/api/v1/shared-locations/"+token),
+	})
+}
+
+// listAddressShares lists the links created for one of the customer's addresses
+func listAddressShares(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid address ID"))
+		return
+	}
+	shares, err := services.NewAddressShareService().List(userID, uint(addressID))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch address links", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Address links retrieved successfully",
+		"data":    serializers.AddressShares(shares),
+	})
+}
+
+// revokeAddressShare stops an address link working before it expires
+func revokeAddressShare(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid address ID"))
+		return
+	}
+	shareID, err := strconv.ParseUint(c.Param("shareId"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid link ID"))
+		return
+	}
+	if err := services.NewAddressShareService().Revoke(userID, uint(addressID), uint(shareID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.NotFound("Address link not found"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to revoke address link", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Address link revoked",
+	})
+}
+
+// GetSharedLocation returns the location behind an address link to anyone holding it, and logs
+// the access for the address's owner
+func GetSharedLocation(c *gin.Context) {
+	share, err := services.NewAddressShareService().Open(c.Param("token"), c.ClientIP(), c.Request.UserAgent())
+	switch {
+	case errors.Is(err, services.ErrAddressShareNotFound):
+		apierror.Abort(c, apierror.NotFound("Location link not found"))
+		return
+	case errors.Is(err, services.ErrAddressShareExpired):
+		apierror.Abort(c, apierror.New(http.StatusGone, apierror.CodeNotFound, "This location link has expired"))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to open location link", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": serializers.SharedLocation(*share),
+	})
+}
@@ -4,11 +4,18 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/repository"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
 	"repair-service-server/utils"
 )
 
@@ -16,9 +23,12 @@ import (
 func AdminAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
+		if token == "" && c.IsWebsocket() {
+			// Browsers cannot set headers on WebSocket upgrades, so accept the token as a query parameter
+			token = c.Query("token")
+		}
 		if token == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Authorization header required"))
 			return
 		}
 
@@ -31,8 +41,14 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 		claims, err := utils.VerifyToken(token)
 		if err != nil {
 			log.Printf("❌ Token verification failed: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("Invalid token"))
+			return
+		}
+
+		// Impersonation tokens never grant admin access
+		if claims.ImpersonatorID != 0 {
+			log.Printf("⚠️ Impersonation token used on admin route by admin %d", claims.ImpersonatorID)
+			apierror.Abort(c, apierror.Forbidden("Impersonation tokens cannot access admin routes"))
 			return
 		}
 
@@ -40,24 +56,26 @@ func AdminAuthMiddleware() gin.HandlerFunc {
 		var user models.User
 		if err := database.DB.First(&user, claims.UserID).Error; err != nil {
 			log.Printf("❌ User not found: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
-			c.Abort()
+			apierror.Abort(c, apierror.Unauthorized("User not found"))
 			return
 		}
 
 		// Check if user is admin
 		if user.Role != models.RoleAdmin {
 			log.Printf("❌ User %d is not admin, role: %s", user.ID, user.Role)
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
-			c.Abort()
+			apierror.Abort(c, apierror.Forbidden("Admin access required"))
 			return
 		}
 
 		// Check if user is active
 		if !user.IsActive {
 			log.Printf("❌ Admin user %d is inactive", user.ID)
-			c.JSON(http.StatusForbidden, gin.H{"error": "Account is inactive"})
-			c.Abort()
+			apierror.Abort(c, apierror.Forbidden("Account is inactive"))
+			return
+		}
+
+		if middleware.SessionRevoked(claims, user) {
+			apierror.Abort(c, apierror.Unauthorized("Session has been signed out"))
 			return
 		}
 
@@ -76,7 +94,14 @@ func AdminLogin(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
+		return
+	}
+
+	loginGuard := services.NewLoginGuardService()
+	ipAddress := c.ClientIP()
+	if err := loginGuard.Check(req.PhoneNumber, ipAddress); err != nil {
+		abortLoginThrottled(c, err)
 		return
 	}
 
@@ -84,43 +109,46 @@ func AdminLogin(c *gin.Context) {
 	var user models.User
 	if err := database.DB.Where("phone_number = ?", req.PhoneNumber).First(&user).Error; err != nil {
 		log.Printf("❌ Admin login failed for phone %s: %v", req.PhoneNumber, err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		loginGuard.RecordFailure(req.PhoneNumber, ipAddress, nil)
+		apierror.Abort(c, apierror.Unauthorized("Invalid credentials"))
 		return
 	}
 
 	// Check if user is admin
 	if user.Role != models.RoleAdmin {
 		log.Printf("❌ Login attempt by non-admin user %d with role %s", user.ID, user.Role)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin access required"})
+		apierror.Abort(c, apierror.Unauthorized("Admin access required"))
 		return
 	}
 
 	// Check if user is active
 	if !user.IsActive {
 		log.Printf("❌ Login attempt by inactive admin user %d", user.ID)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is inactive"})
+		apierror.Abort(c, apierror.Unauthorized("Account is inactive"))
 		return
 	}
 
 	// Verify password
 	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
 		log.Printf("❌ Invalid password for admin user %d", user.ID)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		loginGuard.RecordFailure(req.PhoneNumber, ipAddress, &user)
+		apierror.Abort(c, apierror.Unauthorized("Invalid credentials"))
 		return
 	}
+	loginGuard.RecordSuccess(req.PhoneNumber)
 
 	// Generate tokens
-	token, err := utils.GenerateToken(user.ID, string(user.Role))
+	token, err := utils.GenerateToken(user.ID, string(user.Role), user.TokenVersion)
 	if err != nil {
 		log.Printf("❌ Failed to generate token for admin user %d: %v", user.ID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Abort(c, apierror.Internal("Failed to generate token", nil))
 		return
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID)
+	refreshToken, err := utils.GenerateRefreshToken(user.ID, user.TokenVersion)
 	if err != nil {
 		log.Printf("❌ Failed to generate refresh token for admin user %d: %v", user.ID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate refresh token"})
+		apierror.Abort(c, apierror.Internal("Failed to generate refresh token", nil))
 		return
 	}
 
@@ -151,7 +179,7 @@ func AdminRefreshToken(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
@@ -159,7 +187,7 @@ func AdminRefreshToken(c *gin.Context) {
 	claims, err := utils.VerifyRefreshToken(req.RefreshToken)
 	if err != nil {
 		log.Printf("❌ Refresh token verification failed: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		apierror.Abort(c, apierror.Unauthorized("Invalid refresh token"))
 		return
 	}
 
@@ -167,29 +195,34 @@ func AdminRefreshToken(c *gin.Context) {
 	var user models.User
 	if err := database.DB.First(&user, claims.UserID).Error; err != nil {
 		log.Printf("❌ User not found: %v", err)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		apierror.Abort(c, apierror.Unauthorized("User not found"))
 		return
 	}
 
 	// Check if user is admin
 	if user.Role != models.RoleAdmin {
 		log.Printf("❌ User %d is not admin, role: %s", user.ID, user.Role)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Admin access required"})
+		apierror.Abort(c, apierror.Unauthorized("Admin access required"))
 		return
 	}
 
 	// Check if user is active
 	if !user.IsActive {
 		log.Printf("❌ Admin user %d is inactive", user.ID)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is inactive"})
+		apierror.Abort(c, apierror.Unauthorized("Account is inactive"))
+		return
+	}
+
+	if middleware.SessionRevoked(claims, user) {
+		apierror.Abort(c, apierror.Unauthorized("Refresh token has been signed out"))
 		return
 	}
 
 	// Generate new token
-	token, err := utils.GenerateToken(user.ID, string(user.Role))
+	token, err := utils.GenerateToken(user.ID, string(user.Role), user.TokenVersion)
 	if err != nil {
 		log.Printf("❌ Failed to generate token for admin user %d: %v", user.ID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		apierror.Abort(c, apierror.Internal("Failed to generate token", nil))
 		return
 	}
 
@@ -208,7 +241,7 @@ func GetCurrentAdmin(c *gin.Context) {
 	
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		apierror.Abort(c, apierror.NotFound("User not found"))
 		return
 	}
 
@@ -227,8 +260,28 @@ func GetCurrentAdmin(c *gin.Context) {
 	})
 }
 
+// AdminHandler serves the admin user and service request endpoints from injected repositories
+type AdminHandler struct {
+	users    repository.UserRepo
+	requests repository.RequestRepo
+}
+
+// NewAdminHandler creates an admin handler backed by the given repositories
+func NewAdminHandler(users repository.UserRepo, requests repository.RequestRepo) *AdminHandler {
+	return &AdminHandler{users: users, requests: requests}
+}
+
+// parseIDParam parses a numeric route parameter
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
 // GetAllUsers returns all users with pagination
-func GetAllUsers(c *gin.Context) {
+func (h *AdminHandler) GetAllUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	role := c.Query("role")
@@ -241,47 +294,29 @@ func GetAllUsers(c *gin.Context) {
 	}
 
 	offset := (page - 1) * limit
-
-	var users []models.User
-	var total int64
-
-	query := database.DB.Model(&models.User{})
-	if role != "" {
-		query = query.Where("role = ?", role)
+	filter := repository.UserFilter{Role: role}
+	fields, ok := requestedFields(c)
+	if !ok {
+		return
 	}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		log.Printf("❌ Failed to count users: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count users"})
+	if wantsCSV(c) {
+		streamCSVBatches(c, "users", userCSVHeader, func(size int, fn func([]models.User) error) error {
+			return h.users.EachBatch(filter, size, fn)
+		}, userCSVRow)
 		return
 	}
 
-	// Get users with pagination
-	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&users).Error; err != nil {
+	users, total, err := h.users.List(filter, repository.Page{Offset: offset, Limit: limit})
+	if err != nil {
 		log.Printf("❌ Failed to fetch users: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch users", nil))
 		return
 	}
 
-	// Format response
-	var userList []gin.H
-	for _, user := range users {
-		userList = append(userList, gin.H{
-			"id":                user.ID,
-			"full_name":         user.FullName,
-			"phone_number":      user.PhoneNumber,
-			"role":              user.Role,
-			"profile_picture_url": user.ProfilePictureURL,
-			"is_active":         user.IsActive,
-			"created_at":        user.CreatedAt,
-			"updated_at":        user.UpdatedAt,
-		})
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    userList,
+		"data":    serializers.Sparse(serializers.Users(users), fields),
 		"total":   total,
 		"page":    page,
 		"limit":   limit,
@@ -289,12 +324,16 @@ func GetAllUsers(c *gin.Context) {
 }
 
 // GetUserById returns user by ID
-func GetUserById(c *gin.Context) {
-	userID := c.Param("id")
-	
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+func (h *AdminHandler) GetUserById(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid user ID"))
+		return
+	}
+
+	user, err := h.users.FindByID(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("User not found"))
 		return
 	}
 
@@ -314,37 +353,43 @@ func GetUserById(c *gin.Context) {
 }
 
 // UpdateUserStatus updates user status
-func UpdateUserStatus(c *gin.Context) {
-	userID := c.Param("id")
-	
+func (h *AdminHandler) UpdateUserStatus(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid user ID"))
+		return
+	}
+
 	var req struct {
 		IsActive bool `json:"is_active" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	user, err := h.users.FindByID(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("User not found"))
 		return
 	}
 
 	// Prevent admin from deactivating themselves
 	adminID := c.GetUint("user_id")
 	if user.ID == adminID && !req.IsActive {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot deactivate your own account"})
+		apierror.Abort(c, apierror.Validation("Cannot deactivate your own account"))
 		return
 	}
 
+	before := *user
 	user.IsActive = req.IsActive
-	if err := database.DB.Save(&user).Error; err != nil {
+	if err := h.users.Save(user); err != nil {
 		log.Printf("❌ Failed to update user status: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user status"})
+		apierror.Abort(c, apierror.Internal("Failed to update user status", nil))
 		return
 	}
+	middleware.RecordAuditChange(c, "users", user.ID, before, *user)
 
 	log.Printf("✅ User %d status updated to %v by admin %d", user.ID, req.IsActive, adminID)
 
@@ -364,29 +409,71 @@ func UpdateUserStatus(c *gin.Context) {
 	})
 }
 
+// UnlockUser lifts a sign-in lockout caused by repeated failed passwords
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid user ID"))
+		return
+	}
+
+	user, err := h.users.FindByID(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("User not found"))
+		return
+	}
+
+	loginGuard := services.NewLoginGuardService()
+	lockedUntil := loginGuard.LockedUntil(user.PhoneNumber)
+	wasLocked, err := loginGuard.Unlock(user.PhoneNumber)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to unlock user", err))
+		return
+	}
+
+	if wasLocked {
+		middleware.RecordAuditChange(c, "users", user.ID, gin.H{"locked_until": lockedUntil}, gin.H{"locked_until": nil})
+		log.Printf("🔓 User %d unlocked by admin %d", user.ID, c.GetUint("user_id"))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "User sign-in unlocked",
+		"data": gin.H{
+			"id":         user.ID,
+			"was_locked": wasLocked,
+		},
+	})
+}
+
 // DeleteUser deletes a user
-func DeleteUser(c *gin.Context) {
-	userID := c.Param("id")
+func (h *AdminHandler) DeleteUser(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid user ID"))
+		return
+	}
 	adminID := c.GetUint("user_id")
 
 	// Prevent admin from deleting themselves
-	if userID == strconv.Itoa(int(adminID)) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot delete your own account"})
+	if userID == adminID {
+		apierror.Abort(c, apierror.Validation("Cannot delete your own account"))
 		return
 	}
 
-	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	user, err := h.users.FindByID(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("User not found"))
 		return
 	}
 
 	// Soft delete the user
-	if err := database.DB.Delete(&user).Error; err != nil {
+	if err := h.users.Delete(user); err != nil {
 		log.Printf("❌ Failed to delete user: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		apierror.Abort(c, apierror.Internal("Failed to delete user", nil))
 		return
 	}
+	middleware.RecordAuditChange(c, "users", user.ID, *user, nil)
 
 	log.Printf("✅ User %d deleted by admin %d", user.ID, adminID)
 
@@ -399,19 +486,19 @@ func DeleteUser(c *gin.Context) {
 // GetDashboardStats returns dashboard statistics
 func GetDashboardStats(c *gin.Context) {
 	var stats struct {
-		TotalUsers           int64 `json:"total_users"`
-		TotalWorkers         int64 `json:"total_workers"`
-		TotalCustomers       int64 `json:"total_customers"`
-		TotalAdmins          int64 `json:"total_admins"`
-		VerifiedWorkers      int64 `json:"verified_workers"`
-		UnverifiedWorkers    int64 `json:"unverified_workers"`
-		ActiveWorkers        int64 `json:"active_workers"`
-		InactiveWorkers      int64 `json:"inactive_workers"`
-		TotalServiceRequests int64 `json:"total_service_requests"`
-		CompletedRequests    int64 `json:"completed_requests"`
-		PendingRequests      int64 `json:"pending_requests"`
-		TotalEarnings        float64 `json:"total_earnings"`
-		MonthlyEarnings      float64 `json:"monthly_earnings"`
+		TotalUsers           int64        `json:"total_users"`
+		TotalWorkers         int64        `json:"total_workers"`
+		TotalCustomers       int64        `json:"total_customers"`
+		TotalAdmins          int64        `json:"total_admins"`
+		VerifiedWorkers      int64        `json:"verified_workers"`
+		UnverifiedWorkers    int64        `json:"unverified_workers"`
+		ActiveWorkers        int64        `json:"active_workers"`
+		InactiveWorkers      int64        `json:"inactive_workers"`
+		TotalServiceRequests int64        `json:"total_service_requests"`
+		CompletedRequests    int64        `json:"completed_requests"`
+		PendingRequests      int64        `json:"pending_requests"`
+		TotalEarnings        money.Amount `json:"total_earnings"`
+		MonthlyEarnings      money.Amount `json:"monthly_earnings"`
 	}
 
 	// Count users by role
@@ -431,12 +518,22 @@ func GetDashboardStats(c *gin.Context) {
 	// Count service requests
 	database.DB.Model(&models.CustomerServiceRequest{}).Count(&stats.TotalServiceRequests)
 	database.DB.Model(&models.CustomerServiceRequest{}).Where("status = ?", models.RequestStatusCompleted).Count(&stats.CompletedRequests)
-	database.DB.Model(&models.CustomerServiceRequest{}).Where("status IN (?)", []string{string(models.RequestStatusBroadcast), string(models.RequestStatusAccepted)}).Count(&stats.PendingRequests)
+	database.DB.Model(&models.CustomerServiceRequest{}).Where("status IN (?)", []models.CustomerServiceRequestStatus{models.RequestStatusBroadcast, models.RequestStatusAccepted, models.RequestStatusEnRoute, models.RequestStatusArrived}).Count(&stats.PendingRequests)
 
-	// Calculate earnings (this would need to be implemented based on your business logic)
-	// For now, we'll use placeholder values
-	stats.TotalEarnings = 0.0
-	stats.MonthlyEarnings = 0.0
+	// Calculate earnings from completed services, net of refunds
+	reportService := services.NewReportService()
+	if total, err := reportService.TotalEarnings(time.Time{}); err != nil {
+		log.Printf("⚠️ Failed to calculate total earnings: %v", err)
+	} else {
+		stats.TotalEarnings = total
+	}
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if monthly, err := reportService.TotalEarnings(monthStart); err != nil {
+		log.Printf("⚠️ Failed to calculate monthly earnings: %v", err)
+	} else {
+		stats.MonthlyEarnings = monthly
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -445,7 +542,7 @@ func GetDashboardStats(c *gin.Context) {
 }
 
 // GetAllServiceRequests returns all service requests with pagination and filters
-func GetAllServiceRequests(c *gin.Context) {
+func (h *AdminHandler) GetAllServiceRequests(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	status := c.Query("status")
@@ -458,84 +555,29 @@ func GetAllServiceRequests(c *gin.Context) {
 	}
 
 	offset := (page - 1) * limit
-
-	var requests []models.CustomerServiceRequest
-	var total int64
-
-	query := database.DB.Model(&models.CustomerServiceRequest{}).Preload("Customer").Preload("AssignedWorker.User").Preload("Category")
-	
-	// Apply status filter
-	if status != "" {
-		query = query.Where("status = ?", status)
+	filter := repository.RequestFilter{Status: status}
+	fields, ok := requestedFields(c)
+	if !ok {
+		return
 	}
 
-	// Get total count
-	if err := query.Count(&total).Error; err != nil {
-		log.Printf("❌ Failed to count service requests: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count service requests"})
+	if wantsCSV(c) {
+		streamCSVBatches(c, "service-requests", serviceRequestCSVHeader, func(size int, fn func([]models.CustomerServiceRequest) error) error {
+			return h.requests.EachBatch(filter, size, fn)
+		}, serviceRequestCSVRow)
 		return
 	}
 
-	// Get service requests with pagination
-	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&requests).Error; err != nil {
+	requests, total, err := h.requests.List(filter, repository.Page{Offset: offset, Limit: limit})
+	if err != nil {
 		log.Printf("❌ Failed to fetch service requests: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service requests"})
-		return
-	}
-
-	// Format response
-	var requestList []gin.H
-	for _, request := range requests {
-		requestData := gin.H{
-			"id":                request.ID,
-			"customer_id":       request.CustomerID,
-			"category_id":       request.CategoryID,
-			"title":             request.Title,
-			"description":       request.Description,
-			"priority":          request.Priority,
-			"budget":            request.Budget,
-			"estimated_duration": request.EstimatedDuration,
-			"location_address":  request.LocationAddress,
-			"location_city":     request.LocationCity,
-			"location_lat":      request.LocationLat,
-			"location_lng":      request.LocationLng,
-			"status":            request.Status,
-			"assigned_worker_id": request.AssignedWorkerID,
-			"started_at":        request.StartedAt,
-			"completed_at":      request.CompletedAt,
-			"expires_at":        request.ExpiresAt,
-			"created_at":        request.CreatedAt,
-			"updated_at":        request.UpdatedAt,
-			"customer": gin.H{
-				"id":                request.Customer.ID,
-				"full_name":         request.Customer.FullName,
-				"phone_number":      request.Customer.PhoneNumber,
-				"profile_picture_url": request.Customer.ProfilePictureURL,
-			},
-			"category": gin.H{
-				"id":   request.Category.ID,
-				"name": request.Category.Name,
-			},
-		}
-
-		if request.AssignedWorker != nil {
-			requestData["assigned_worker"] = gin.H{
-				"id": request.AssignedWorker.ID,
-				"user": gin.H{
-					"id":                request.AssignedWorker.User.ID,
-					"full_name":         request.AssignedWorker.User.FullName,
-					"phone_number":      request.AssignedWorker.User.PhoneNumber,
-					"profile_picture_url": request.AssignedWorker.User.ProfilePictureURL,
-				},
-			}
-		}
-
-		requestList = append(requestList, requestData)
+		apierror.Abort(c, apierror.Internal("Failed to fetch service requests", nil))
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    requestList,
+		"data":    serializers.Sparse(serializers.ServiceRequests(requests), fields),
 		"total":   total,
 		"page":    page,
 		"limit":   limit,
@@ -543,62 +585,22 @@ func GetAllServiceRequests(c *gin.Context) {
 }
 
 // GetServiceRequestById returns service request by ID
-func GetServiceRequestById(c *gin.Context) {
-	requestID := c.Param("id")
-	
-	var request models.CustomerServiceRequest
-	if err := database.DB.Preload("Customer").Preload("AssignedWorker.User").Preload("Category").First(&request, requestID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
-		return
-	}
-
-	requestData := gin.H{
-		"id":                request.ID,
-		"customer_id":       request.CustomerID,
-		"category_id":       request.CategoryID,
-		"title":             request.Title,
-		"description":       request.Description,
-		"priority":          request.Priority,
-		"budget":            request.Budget,
-		"estimated_duration": request.EstimatedDuration,
-		"location_address":  request.LocationAddress,
-		"location_city":     request.LocationCity,
-		"location_lat":      request.LocationLat,
-		"location_lng":      request.LocationLng,
-		"status":            request.Status,
-		"assigned_worker_id": request.AssignedWorkerID,
-		"started_at":        request.StartedAt,
-		"completed_at":      request.CompletedAt,
-		"expires_at":        request.ExpiresAt,
-		"created_at":        request.CreatedAt,
-		"updated_at":        request.UpdatedAt,
-		"customer": gin.H{
-			"id":                request.Customer.ID,
-			"full_name":         request.Customer.FullName,
-			"phone_number":      request.Customer.PhoneNumber,
-			"profile_picture_url": request.Customer.ProfilePictureURL,
-		},
-		"category": gin.H{
-			"id":   request.Category.ID,
-			"name": request.Category.Name,
-		},
+func (h *AdminHandler) GetServiceRequestById(c *gin.Context) {
+	requestID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid service request ID"))
+		return
 	}
 
-	if request.AssignedWorker != nil {
-		requestData["assigned_worker"] = gin.H{
-			"id": request.AssignedWorker.ID,
-			"user": gin.H{
-				"id":                request.AssignedWorker.User.ID,
-				"full_name":         request.AssignedWorker.User.FullName,
-				"phone_number":      request.AssignedWorker.User.PhoneNumber,
-				"profile_picture_url": request.AssignedWorker.User.ProfilePictureURL,
-			},
-		}
+	request, err := h.requests.FindByID(requestID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    requestData,
+		"data":    serializers.ServiceRequest(*request),
 	})
 }
 
@@ -0,0 +1,139 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/middleware"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// GetArchivedServiceRequests lists archived requests, most recently archived first. It takes
+// customer_id, worker_id, status, and from and to (YYYY-MM-DD, inclusive) on the archive date.
+func GetArchivedServiceRequests(c *gin.Context) {
+	var req struct {
+		CustomerID uint   `form:"customer_id"`
+		WorkerID   uint   `form:"worker_id"`
+		Status     string `form:"status" binding:"omitempty,oneof=completed cancelled"`
+		From       string `form:"from"`
+		To         string `form:"to"`
+		Page       int    `form:"page" binding:"omitempty,min=1"`
+		Limit      int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	filter := services.ArchiveFilter{
+		CustomerID: req.CustomerID,
+		WorkerID:   req.WorkerID,
+		Status:     req.Status,
+	}
+	if req.From != "" {
+		from, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+		if err != nil {
+			validation.Fail(c, "from", "default", "")
+			return
+		}
+		filter.From = from
+	}
+	if req.To != "" {
+		to, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+		if err != nil {
+			validation.Fail(c, "to", "default", "")
+			return
+		}
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	requests, total, err := services.NewArchiveService().List(filter, (req.Page-1)*req.Limit, req.Limit)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch archived requests", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"service_requests": requests,
+			"pagination": gin.H{
+				"page":        req.Page,
+				"limit":       req.Limit,
+				"total":       total,
+				"total_pages": (total + int64(req.Limit) - 1) / int64(req.Limit),
+			},
+		},
+	})
+}
+
+// GetArchivedServiceRequest returns an archived request with its archived chat messages
+func GetArchivedServiceRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid service request ID"))
+		return
+	}
+
+	request, messages, err := services.NewArchiveService().Find(uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apierror.Abort(c, apierror.NotFound("Archived service request not found"))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch archived request", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"service_request": request,
+			"messages":        messages,
+		},
+	})
+}
+
+// RestoreArchivedServiceRequest brings an archived request and its chats back into the live tables
+func RestoreArchivedServiceRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid service request ID"))
+		return
+	}
+
+	request, err := services.NewArchiveService().Restore(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	case errors.Is(err, services.ErrRequestNotArchived):
+		apierror.Abort(c, apierror.Conflict("Service request is not archived"))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to restore service request", err))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "service_request", request.ID, gin.H{"archived": true}, gin.H{"archived": false})
+	log.Printf("📦 Service request %d restored from the archive by admin %d", request.ID, c.GetUint("user_id"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Service request restored",
+		"data":    request,
+	})
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// GetAuditLogs returns persisted audit entries, newest first.
+// Filters: actor_id, impersonator_id, entity_type, entity_id, method, request_id, from, to (YYYY-MM-DD, inclusive).
+func GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.AuditLog{})
+
+	if actorID, err := strconv.ParseUint(c.Query("actor_id"), 10, 32); err == nil {
+		query = query.Where("actor_id = ?", actorID)
+	}
+	if impersonatorID, err := strconv.ParseUint(c.Query("impersonator_id"), 10, 32); err == nil {
+		query = query.Where("impersonator_id = ?", impersonatorID)
+	}
+	if entityType := c.Query("entity_type"); entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID := c.Query("entity_id"); entityID != "" {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	if method := c.Query("method"); method != "" {
+		query = query.Where("method = ?", method)
+	}
+	if requestID := c.Query("request_id"); requestID != "" {
+		query = query.Where("request_id = ?", requestID)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation("Invalid from date, expected YYYY-MM-DD"))
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation("Invalid to date, expected YYYY-MM-DD"))
+			return
+		}
+		query = query.Where("created_at < ?", t.AddDate(0, 0, 1))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Printf("❌ Failed to count audit logs: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to count audit logs", nil))
+		return
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&logs).Error; err != nil {
+		log.Printf("❌ Failed to fetch audit logs: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to fetch audit logs", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    logs,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
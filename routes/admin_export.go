@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/services"
+)
+
+// csvExportBatchSize is how many rows are loaded from the database per batch while streaming an export
+const csvExportBatchSize = 500
+
+// wantsCSV reports whether the caller asked for a CSV export (?format=csv)
+func wantsCSV(c *gin.Context) bool {
+	return c.Query("format") == "csv"
+}
+
+// startCSV writes the download headers and returns a CSV writer on the response.
+// A UTF-8 BOM is written first so Excel displays accented and Arabic names correctly.
+func startCSV(c *gin.Context, name string, header []string) *csv.Writer {
+	filename := fmt.Sprintf("%s-%s.csv", name, time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	c.Writer.Write([]byte("\xEF\xBB\xBF"))
+	w := csv.NewWriter(c.Writer)
+	w.Write(header)
+	return w
+}
+
+// streamCSV writes every row matched by query as CSV, loading and flushing one batch at a time
+// so large exports are never held in memory. Preloads on the query are honored per batch.
+func streamCSV[T any](c *gin.Context, name string, header []string, query *gorm.DB, toRow func(T) []string) {
+	streamCSVBatches(c, name, header, func(size int, fn func([]T) error) error {
+		var batch []T
+		return query.FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+	}, toRow)
+}
+
+// streamCSVBatches writes CSV from a batch iterator such as a repository's EachBatch
+func streamCSVBatches[T any](c *gin.Context, name string, header []string, each func(size int, fn func([]T) error) error, toRow func(T) []string) {
+	w := startCSV(c, name, header)
+
+	exported := 0
+	err := each(csvExportBatchSize, func(batch []T) error {
+		for _, item := range batch {
+			if err := w.Write(toRow(item)); err != nil {
+				return err
+			}
+		}
+		exported += len(batch)
+		w.Flush()
+		c.Writer.Flush()
+		return w.Error()
+	})
+
+	w.Flush()
+	if err != nil {
+		// Headers are already sent, so the truncated file is the only signal left to the client
+		log.Printf("❌ CSV export %s failed after %d rows: %v", name, exported, err)
+		return
+	}
+	log.Printf("📊 CSV export %s: %d rows", name, exported)
+}
+
+// writeReportCSV writes report points (time-series or breakdown) as CSV
+func writeReportCSV(c *gin.Context, name string, points []services.ReportPoint) {
+	w := startCSV(c, name, []string{
+		"period", "category_id", "city", "requests_created", "requests_accepted", "requests_completed",
+		"requests_cancelled", "completion_rate", "avg_time_to_accept_mins", "gmv", "refunds", "earnings",
+	})
+	for _, p := range points {
+		w.Write([]string{
+			p.Period, csvUint(p.CategoryID), p.City,
+			strconv.Itoa(p.RequestsCreated), strconv.Itoa(p.RequestsAccepted), strconv.Itoa(p.RequestsCompleted),
+			strconv.Itoa(p.RequestsCancelled), csvFloat(p.CompletionRate), csvFloat(p.AvgTimeToAcceptMins),
+			p.GMV.String(), p.Refunds.String(), p.Earnings.String(),
+		})
+	}
+	w.Flush()
+}
+
+func userCSVRow(user models.User) []string {
+	return []string{
+		csvUint(user.ID), user.FullName, user.PhoneNumber, string(user.Role),
+		strconv.FormatBool(user.IsActive), csvTime(&user.CreatedAt), csvTime(&user.UpdatedAt),
+	}
+}
+
+func workerCSVRow(worker models.WorkerProfile) []string {
+	return []string{
+		csvUint(worker.ID), csvUint(worker.UserID), worker.User.FullName, worker.PhoneNumber,
+		worker.Category.Name, worker.City, worker.Country, csvFloat(worker.HourlyRate),
+		strconv.FormatBool(worker.IsVerified), strconv.FormatBool(worker.IsAvailable),
+		strconv.Itoa(worker.MaxConcurrentJobs), strconv.Itoa(worker.CompletedJobs),
+		csvFloat(worker.Rating), strconv.Itoa(worker.TotalReviews), csvTime(&worker.CreatedAt),
+	}
+}
+
+func serviceRequestCSVRow(request models.CustomerServiceRequest) []string {
+	workerID := ""
+	if request.AssignedWorkerID != nil {
+		workerID = csvUint(*request.AssignedWorkerID)
+	}
+	return []string{
+		csvUint(request.ID), request.Title, string(request.Status), request.Priority,
+		request.Category.Name, csvUint(request.CustomerID), request.Customer.FullName, workerID,
+		csvAmountPtr(request.Budget), request.LocationCity, request.LocationAddress,
+		csvTime(&request.CreatedAt), csvTime(request.StartedAt), csvTime(request.CompletedAt),
+	}
+}
+
+var (
+	userCSVHeader = []string{"id", "full_name", "phone_number", "role", "is_active", "created_at", "updated_at"}
+
+	workerCSVHeader = []string{
+		"id", "user_id", "full_name", "phone_number", "category", "city", "country", "hourly_rate",
+		"is_verified", "is_available", "max_concurrent_jobs", "completed_jobs", "rating", "total_reviews", "created_at",
+	}
+
+	serviceRequestCSVHeader = []string{
+		"id", "title", "status", "priority", "category", "customer_id", "customer_name", "assigned_worker_id",
+		"budget", "location_city", "location_address", "created_at", "started_at", "completed_at",
+	}
+)
+
+func csvUint(v uint) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func csvFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func csvAmountPtr(v *money.Amount) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
 
@@ -45,10 +46,7 @@ func GetAllFeedback(c *gin.Context) {
 		Offset(offset).
 		Limit(limit).
 		Find(&feedback).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch feedback",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to fetch feedback", nil))
 		return
 	}
 
@@ -88,10 +86,7 @@ func GetAllFeedback(c *gin.Context) {
 func GetFeedbackById(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid feedback ID",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid feedback ID"))
 		return
 	}
 
@@ -99,10 +94,7 @@ func GetFeedbackById(c *gin.Context) {
 	if err := database.DB.
 		Preload("User").
 		First(&feedback, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": "Feedback not found",
-		})
+		apierror.Abort(c, apierror.NotFound("Feedback not found"))
 		return
 	}
 
@@ -116,18 +108,12 @@ func GetFeedbackById(c *gin.Context) {
 func DeleteFeedback(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid feedback ID",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid feedback ID"))
 		return
 	}
 
 	if err := database.DB.Delete(&models.Feedback{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to delete feedback",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to delete feedback", err))
 		return
 	}
 
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/config"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/utils"
+)
+
+// maxImpersonationTTL caps how long an impersonation token can live
+const maxImpersonationTTL = time.Hour
+
+// impersonationTTL returns the lifetime of impersonation tokens (IMPERSONATION_TTL_MINUTES, default 15)
+func impersonationTTL() time.Duration {
+	ttl := config.Current().ImpersonationTTL
+	if ttl > maxImpersonationTTL {
+		ttl = maxImpersonationTTL
+	}
+	return ttl
+}
+
+// ImpersonateUser issues a short-lived token that lets support act as a customer or worker
+func ImpersonateUser(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("User not found"))
+		return
+	}
+
+	if user.Role == models.RoleAdmin {
+		log.Printf("⚠️ AUDIT: admin %d attempted to impersonate admin %d", adminID, user.ID)
+		apierror.Abort(c, apierror.Forbidden("Admins cannot be impersonated"))
+		return
+	}
+
+	if !user.IsActive {
+		apierror.Abort(c, apierror.Validation("Cannot impersonate an inactive user"))
+		return
+	}
+
+	token, expiresAt, err := utils.GenerateImpersonationToken(user.ID, adminID, user.TokenVersion, impersonationTTL())
+	if err != nil {
+		log.Printf("❌ Failed to generate impersonation token: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to generate token", nil))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "users", user.ID, nil, gin.H{
+		"impersonation_expires_at": expiresAt,
+		"impersonation_reason":     req.Reason,
+	})
+
+	log.Printf("🔍 AUDIT: admin %d started impersonating user %d (%s) from %s until %s: %s",
+		adminID, user.ID, user.Role, c.ClientIP(), expiresAt.Format(time.RFC3339), req.Reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Impersonation token issued",
+		"data": gin.H{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_at":   expiresAt,
+			"expires_in":   int64(time.Until(expiresAt).Seconds()),
+			"user": gin.H{
+				"id":        user.ID,
+				"full_name": user.FullName,
+				"role":      user.Role,
+			},
+		},
+	})
+}
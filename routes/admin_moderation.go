@@ -0,0 +1,199 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// GetModerationTerms lists the content moderation word lists, optionally of one kind
+func GetModerationTerms(c *gin.Context) {
+	query := database.DB.Order("kind, term")
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var terms []models.ModerationTerm
+	if err := query.Find(&terms).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch moderation terms", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    terms,
+	})
+}
+
+// AddModerationTerm adds a word to the profanity list or a phrase to the payment bait list
+func AddModerationTerm(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req struct {
+		Term string `json:"term" binding:"required,max=100"`
+		Kind string `json:"kind" binding:"required,oneof=profanity payment_bait"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	term := models.ModerationTerm{
+		Term:      strings.ToLower(strings.TrimSpace(req.Term)),
+		Kind:      models.ModerationTermKind(req.Kind),
+		CreatedBy: &adminID,
+	}
+	if term.Term == "" {
+		validation.Fail(c, "term", "required", "")
+		return
+	}
+	if term.Kind == models.ModerationTermProfanity && strings.Contains(term.Term, " ") {
+		apierror.Abort(c, apierror.Validation("Profanity terms must be a single word"))
+		return
+	}
+
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&term)
+	if result.Error != nil {
+		apierror.Abort(c, apierror.Internal("Failed to add moderation term", result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Abort(c, apierror.Conflict("Term is already on the list"))
+		return
+	}
+	services.InvalidateModerationTerms()
+	middleware.RecordAuditChange(c, "moderation_terms", term.ID, nil, term)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Moderation term added",
+		"data":    term,
+	})
+}
+
+// DeleteModerationTerm removes a term from the word lists
+func DeleteModerationTerm(c *gin.Context) {
+	var term models.ModerationTerm
+	if err := database.DB.First(&term, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Moderation term not found"))
+		return
+	}
+	if err := database.DB.Delete(&term).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to delete moderation term", err))
+		return
+	}
+	services.InvalidateModerationTerms()
+	middleware.RecordAuditChange(c, "moderation_terms", term.ID, term, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Moderation term deleted",
+	})
+}
+
+// GetContentViolations lists logged content violations, unreviewed ones by default
+func GetContentViolations(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.ContentViolation{})
+	switch c.DefaultQuery("status", "pending") {
+	case "pending":
+		query = query.Where("reviewed_at IS NULL")
+	case "reviewed":
+		query = query.Where("reviewed_at IS NOT NULL")
+	}
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if sourceType := c.Query("source_type"); sourceType != "" {
+		query = query.Where("source_type = ?", sourceType)
+	}
+	if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32); err == nil {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to count content violations", err))
+		return
+	}
+
+	var violations []models.ContentViolation
+	if err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&violations).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch content violations", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    violations,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// ReviewContentViolation marks a violation as reviewed. Acting on the user, such as
+// suspending them, is done through the user endpoints.
+func ReviewContentViolation(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req struct {
+		Note string `json:"note" binding:"max=1000"`
+	}
+	if c.Request.ContentLength > 0 && !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var violation models.ContentViolation
+	if err := database.DB.First(&violation, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Content violation not found"))
+		return
+	}
+	if violation.ReviewedAt != nil {
+		apierror.Abort(c, apierror.Conflict("Content violation has already been reviewed"))
+		return
+	}
+
+	now := time.Now()
+	violation.ReviewedBy = &adminID
+	violation.ReviewedAt = &now
+	violation.ReviewNote = req.Note
+	if err := database.DB.Model(&violation).Updates(map[string]interface{}{
+		"reviewed_by": violation.ReviewedBy,
+		"reviewed_at": violation.ReviewedAt,
+		"review_note": violation.ReviewNote,
+	}).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to review content violation", err))
+		return
+	}
+
+	log.Printf("🛡️ Admin %d reviewed content violation %d", adminID, violation.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Content violation reviewed",
+		"data":    violation,
+	})
+}
@@ -0,0 +1,163 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/utils"
+
+	ws "repair-service-server/websocket"
+)
+
+var opsHub *ws.OpsHub
+
+// SetOpsHub sets the hub used to stream live operations events to admins
+func SetOpsHub(hub *ws.OpsHub) {
+	opsHub = hub
+}
+
+// publishOpsEvent streams an event to connected admin dashboards, if any
+func publishOpsEvent(eventType string, data gin.H) {
+	if opsHub == nil {
+		return
+	}
+	go opsHub.Publish(eventType, data)
+}
+
+// publishRequestEvent streams a service request lifecycle event to admin dashboards and to the
+// customers watching the request
+func publishRequestEvent(eventType string, request models.CustomerServiceRequest) {
+	pushRequestStatus(eventType, request)
+	publishOpsEvent(eventType, gin.H{
+		"request_id":         request.ID,
+		"title":              request.Title,
+		"category_id":        request.CategoryID,
+		"status":             request.Status,
+		"priority":           request.Priority,
+		"location_city":      request.LocationCity,
+		"service_zone_id":    request.ServiceZoneID,
+		"assigned_worker_id": request.AssignedWorkerID,
+		"created_at":         request.CreatedAt,
+	})
+}
+
+// HandleAdminOpsWebSocket upgrades an admin connection to the live operations stream
+func HandleAdminOpsWebSocket(c *gin.Context) {
+	if opsHub == nil {
+		apierror.Abort(c, apierror.Unavailable("Operations stream not available", nil))
+		return
+	}
+	opsHub.Serve(c.Writer, c.Request, c.GetUint("user_id"))
+}
+
+// GetOpsLiveSnapshot summarizes current broadcasts, stale unassigned requests and active workers per zone
+func GetOpsLiveSnapshot(c *gin.Context) {
+	staleMinutes := 5
+	if v, err := strconv.Atoi(c.Query("stale_minutes")); err == nil && v > 0 {
+		staleMinutes = v
+	}
+	staleBefore := time.Now().Add(-time.Duration(staleMinutes) * time.Minute)
+
+	var broadcastCount int64
+	if err := database.DB.Model(&models.CustomerServiceRequest{}).
+		Where("status = ?", models.RequestStatusBroadcast).Count(&broadcastCount).Error; err != nil {
+		log.Printf("❌ Failed to count broadcasts: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to build live snapshot", nil))
+		return
+	}
+
+	var staleRequests []models.CustomerServiceRequest
+	if err := database.DB.Where("status IN ? AND assigned_worker_id IS NULL AND created_at <= ?",
+		[]models.CustomerServiceRequestStatus{models.RequestStatusBroadcast, models.RequestStatusPending}, staleBefore).
+		Order("created_at ASC").Limit(100).Find(&staleRequests).Error; err != nil {
+		log.Printf("❌ Failed to fetch stale requests: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to build live snapshot", nil))
+		return
+	}
+
+	var unassigned []gin.H
+	for _, request := range staleRequests {
+		unassigned = append(unassigned, gin.H{
+			"id":              request.ID,
+			"title":           request.Title,
+			"category_id":     request.CategoryID,
+			"status":          request.Status,
+			"location_city":   request.LocationCity,
+			"service_zone_id": request.ServiceZoneID,
+			"created_at":      request.CreatedAt,
+			"waiting_minutes": int(time.Since(request.CreatedAt).Minutes()),
+		})
+	}
+
+	var zones []models.ServiceZone
+	if err := database.DB.Where("is_active = ?", true).Find(&zones).Error; err != nil {
+		log.Printf("❌ Failed to fetch service zones: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to build live snapshot", nil))
+		return
+	}
+
+	var activeWorkers []models.WorkerProfile
+	if err := database.DB.Where("is_available = ? AND current_lat IS NOT NULL AND current_lng IS NOT NULL", true).
+		Find(&activeWorkers).Error; err != nil {
+		log.Printf("❌ Failed to fetch active workers: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to build live snapshot", nil))
+		return
+	}
+
+	workersPerZone := make(map[uint]int, len(zones))
+	unzoned := 0
+	for _, worker := range activeWorkers {
+		if !utils.IsLocationRecent(worker.LastLocationUpdate) {
+			continue
+		}
+		matched := false
+		for i := range zones {
+			if services.ZoneContains(&zones[i], *worker.CurrentLat, *worker.CurrentLng) {
+				workersPerZone[zones[i].ID]++
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unzoned++
+		}
+	}
+
+	var zoneSummary []gin.H
+	for _, zone := range zones {
+		zoneSummary = append(zoneSummary, gin.H{
+			"zone_id":        zone.ID,
+			"name":           zone.Name,
+			"city":           zone.City,
+			"active_workers": workersPerZone[zone.ID],
+		})
+	}
+
+	online := map[string]int{}
+	if chatHub != nil {
+		online = chatHub.CountConnectedByType()
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"current_broadcasts":     broadcastCount,
+			"stale_minutes":          staleMinutes,
+			"unassigned_requests":    unassigned,
+			"unassigned_count":       len(unassigned),
+			"active_workers_by_zone": zoneSummary,
+			"active_workers_unzoned": unzoned,
+			"online_users":           online,
+			"websocket_connections":  ws.Stats(),
+			"generated_at":           time.Now(),
+		},
+	})
+}
@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+)
+
+// GetAllRegions returns every region, inactive ones included
+func GetAllRegions(c *gin.Context) {
+	var regions []models.Region
+	if err := database.DB.Order("name ASC").Find(&regions).Error; err != nil {
+		log.Printf("❌ Failed to fetch regions: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to fetch regions", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    regions,
+	})
+}
+
+// GetRegionById returns a single region
+func GetRegionById(c *gin.Context) {
+	var region models.Region
+	if err := database.DB.First(&region, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Region not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    region,
+	})
+}
+
+// CreateRegion creates a new region
+func CreateRegion(c *gin.Context) {
+	var req models.RegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	region := models.Region{IsActive: true}
+	applyRegionRequest(&region, &req)
+
+	if err := services.NewRegionService().Save(&region); err != nil {
+		abortRegionSave(c, err, "Failed to create region")
+		return
+	}
+	middleware.RecordAuditChange(c, "regions", region.ID, nil, region)
+
+	log.Printf("✅ Region created: %s (ID: %d)", region.Code, region.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Region created successfully",
+		"data":    region,
+	})
+}
+
+// UpdateRegion updates an existing region
+func UpdateRegion(c *gin.Context) {
+	var req models.RegionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	var region models.Region
+	if err := database.DB.First(&region, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Region not found"))
+		return
+	}
+
+	before := region
+	applyRegionRequest(&region, &req)
+
+	if err := services.NewRegionService().Save(&region); err != nil {
+		abortRegionSave(c, err, "Failed to update region")
+		return
+	}
+	middleware.RecordAuditChange(c, "regions", region.ID, before, region)
+
+	log.Printf("✅ Region updated: %s (ID: %d)", region.Code, region.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Region updated successfully",
+		"data":    region,
+	})
+}
+
+// DeleteRegion soft deletes a region. Its cities fall back to the default region.
+func DeleteRegion(c *gin.Context) {
+	var region models.Region
+	if err := database.DB.First(&region, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Region not found"))
+		return
+	}
+
+	if err := services.NewRegionService().Delete(&region); err != nil {
+		if errors.Is(err, services.ErrDefaultRegionRequired) {
+			apierror.Abort(c, apierror.Conflict("The default region cannot be deleted"))
+			return
+		}
+		log.Printf("❌ Failed to delete region: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to delete region", nil))
+		return
+	}
+	middleware.RecordAuditChange(c, "regions", region.ID, region, nil)
+
+	log.Printf("✅ Region deleted: %s (ID: %d)", region.Code, region.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Region deleted successfully",
+	})
+}
+
+// applyRegionRequest copies the request fields onto the region
+func applyRegionRequest(region *models.Region, req *models.RegionRequest) {
+	region.Code = req.Code
+	region.Name = req.Name
+	region.Cities = req.Cities
+	region.Currency = req.Currency
+	region.Timezone = req.Timezone
+	region.DefaultLanguage = req.DefaultLanguage
+	region.SupportedCategoryIDs = req.SupportedCategoryIDs
+	region.CommissionPercent = req.CommissionPercent
+	if req.IsDefault != nil {
+		region.IsDefault = *req.IsDefault
+	}
+	if req.IsActive != nil {
+		region.IsActive = *req.IsActive
+	}
+}
+
+// abortRegionSave maps a region save error to its API error
+func abortRegionSave(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTimezone):
+		apierror.Abort(c, apierror.Validation("Timezone must be an IANA time zone such as Africa/Nouakchott"))
+	case errors.Is(err, services.ErrRegionCityTaken):
+		apierror.Abort(c, apierror.Conflict("A city already belongs to another region"))
+	case errors.Is(err, services.ErrDefaultRegionRequired):
+		apierror.Abort(c, apierror.Conflict("Exactly one active region must be the default"))
+	default:
+		log.Printf("❌ %s: %v", message, err)
+		apierror.Abort(c, apierror.Internal(message, nil))
+	}
+}
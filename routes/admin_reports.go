@@ -0,0 +1,177 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/services"
+)
+
+// maxReportRangeDays caps how many days a single report request may span
+const maxReportRangeDays = 366
+
+// parseReportFilter reads from/to (YYYY-MM-DD, default last 30 days), category_id and city from the query
+func parseReportFilter(c *gin.Context) (services.ReportFilter, bool) {
+	today := services.TruncateDay(time.Now())
+	filter := services.ReportFilter{
+		From: today.AddDate(0, 0, -29),
+		To:   today,
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation("Invalid from date, expected YYYY-MM-DD"))
+			return filter, false
+		}
+		filter.From = t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation("Invalid to date, expected YYYY-MM-DD"))
+			return filter, false
+		}
+		filter.To = t
+	}
+
+	if filter.To.Before(filter.From) {
+		apierror.Abort(c, apierror.Validation("from must be before to"))
+		return filter, false
+	}
+	if filter.To.Sub(filter.From) > maxReportRangeDays*24*time.Hour {
+		apierror.Abort(c, apierror.Validation("Date range is too large"))
+		return filter, false
+	}
+
+	if categoryID, err := strconv.ParseUint(c.Query("category_id"), 10, 32); err == nil {
+		filter.CategoryID = uint(categoryID)
+	}
+	if city := c.Query("city"); city != "" {
+		filter.City = services.NormalizeCity(city)
+	}
+
+	return filter, true
+}
+
+// GetReportTimeSeries returns platform metrics over time (?from, ?to, ?granularity=day|week|month, ?category_id, ?city)
+func GetReportTimeSeries(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+	granularity := c.DefaultQuery("granularity", services.GranularityDay)
+
+	points, err := services.NewReportService().GetTimeSeries(filter, granularity)
+	if err == services.ErrInvalidGranularity {
+		apierror.Abort(c, apierror.Validation(err.Error()))
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to build report time-series: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to build report", nil))
+		return
+	}
+
+	if wantsCSV(c) {
+		writeReportCSV(c, "report-"+granularity, points)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"from":        filter.From.Format("2006-01-02"),
+			"to":          filter.To.Format("2006-01-02"),
+			"granularity": granularity,
+			"series":      points,
+		},
+	})
+}
+
+// GetReportBreakdown returns platform totals for a date range grouped by category or city (?group_by=category|city)
+func GetReportBreakdown(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+	groupBy := c.DefaultQuery("group_by", "category")
+	if groupBy != "category" && groupBy != "city" {
+		apierror.Abort(c, apierror.Validation("group_by must be category or city"))
+		return
+	}
+
+	points, err := services.NewReportService().GetBreakdown(filter, groupBy)
+	if err != nil {
+		log.Printf("❌ Failed to build report breakdown: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to build report", nil))
+		return
+	}
+
+	if wantsCSV(c) {
+		writeReportCSV(c, "report-by-"+groupBy, points)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"from":      filter.From.Format("2006-01-02"),
+			"to":        filter.To.Format("2006-01-02"),
+			"group_by":  groupBy,
+			"breakdown": points,
+		},
+	})
+}
+
+// GetDeclineReasonReport returns how often workers gave each reason for declining requests
+// (?from, ?to, ?category_id, ?city)
+func GetDeclineReasonReport(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	reasons, err := services.NewReportService().GetDeclineReasons(filter)
+	if err != nil {
+		log.Printf("❌ Failed to build decline reason report: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to build report", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"from":    filter.From.Format("2006-01-02"),
+			"to":      filter.To.Format("2006-01-02"),
+			"reasons": reasons,
+		},
+	})
+}
+
+// RebuildReports recomputes the pre-aggregated metrics for a date range, e.g. after a backfill
+func RebuildReports(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	if err := services.NewReportService().AggregateRange(filter.From, filter.To); err != nil {
+		log.Printf("❌ Failed to rebuild reports: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to rebuild reports", nil))
+		return
+	}
+
+	log.Printf("📊 Reports rebuilt from %s to %s by admin %d",
+		filter.From.Format("2006-01-02"), filter.To.Format("2006-01-02"), c.GetUint("user_id"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Reports rebuilt successfully",
+	})
+}
@@ -0,0 +1,261 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/services"
+)
+
+// GetServiceHistoryAdjustments returns the adjustment ledger for a service history entry
+func GetServiceHistoryAdjustments(c *gin.Context) {
+	var history models.ServiceHistory
+	if err := database.DB.First(&history, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service history not found"))
+		return
+	}
+
+	var adjustments []models.ServiceAdjustment
+	if err := database.DB.Where("service_history_id = ?", history.ID).
+		Order("created_at ASC").Find(&adjustments).Error; err != nil {
+		log.Printf("❌ Failed to fetch adjustments for history %d: %v", history.ID, err)
+		apierror.Abort(c, apierror.Internal("Failed to fetch adjustments", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"history":     history,
+			"adjustments": adjustments,
+		},
+	})
+}
+
+// AdjustServiceHistoryPrice changes the final price of a completed service
+func AdjustServiceHistoryPrice(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req models.PriceAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	var previousPrice money.Amount
+	newPrice := req.FinalPrice
+	before, history, adjustment, err := saveServiceAdjustment(c.Param("id"), func(history *models.ServiceHistory) (*models.ServiceAdjustment, error) {
+		previousPrice = priceOrZero(history.FinalPrice)
+		if newPrice < history.RefundedAmount {
+			return nil, apierror.Validation("Final price cannot be lower than the amount already refunded")
+		}
+		if newPrice == previousPrice {
+			return nil, apierror.Validation("Final price is unchanged")
+		}
+
+		adjustment := &models.ServiceAdjustment{
+			ServiceHistoryID: history.ID,
+			ServiceRequestID: history.ServiceRequestID,
+			WorkerID:         history.WorkerID,
+			CustomerID:       history.CustomerID,
+			AdminID:          adminID,
+			Type:             models.AdjustmentTypePrice,
+			PreviousPrice:    history.FinalPrice,
+			NewPrice:         &newPrice,
+			Amount:           (newPrice - previousPrice).Abs(),
+			EarningsDelta:    newPrice - previousPrice,
+			Reason:           req.Reason,
+		}
+
+		history.FinalPrice = &newPrice
+		if history.RefundedAmount > 0 {
+			history.PaymentStatus = refundPaymentStatus(newPrice, history.RefundedAmount)
+		}
+		return adjustment, nil
+	})
+	if err != nil {
+		apierror.Abort(c, serviceAdjustmentError(err, "Failed to adjust price"))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "service_history", history.ID, before, history)
+
+	log.Printf("✅ Service history %d final price changed from %s to %s by admin %d", history.ID, previousPrice, newPrice, adminID)
+
+	notifyServiceAdjustment(history, *adjustment, "notification.price_updated", i18n.Vars{"title": history.Title, "price": newPrice})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Price adjusted successfully",
+		"data": gin.H{
+			"history":    history,
+			"adjustment": adjustment,
+		},
+	})
+}
+
+// RefundServiceHistory refunds all or part of a completed service's final price
+func RefundServiceHistory(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req models.RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	var amount money.Amount
+	before, history, adjustment, err := saveServiceAdjustment(c.Param("id"), func(history *models.ServiceHistory) (*models.ServiceAdjustment, error) {
+		finalPrice := priceOrZero(history.FinalPrice)
+		remaining := finalPrice - history.RefundedAmount
+		if remaining <= 0 {
+			return nil, apierror.Validation("Nothing left to refund on this service")
+		}
+
+		amount = remaining
+		if req.Amount != nil {
+			amount = *req.Amount
+		}
+		if amount > remaining {
+			return nil, apierror.Validation(fmt.Sprintf("At most %s can be refunded", remaining))
+		}
+
+		history.RefundedAmount += amount
+		history.RefundReason = req.Reason
+		history.PaymentStatus = refundPaymentStatus(finalPrice, history.RefundedAmount)
+
+		adjustmentType := models.AdjustmentTypePartialRefund
+		if history.PaymentStatus == models.PaymentStatusRefunded {
+			adjustmentType = models.AdjustmentTypeRefund
+		}
+
+		return &models.ServiceAdjustment{
+			ServiceHistoryID: history.ID,
+			ServiceRequestID: history.ServiceRequestID,
+			WorkerID:         history.WorkerID,
+			CustomerID:       history.CustomerID,
+			AdminID:          adminID,
+			Type:             adjustmentType,
+			PreviousPrice:    history.FinalPrice,
+			NewPrice:         history.FinalPrice,
+			Amount:           amount,
+			EarningsDelta:    -amount,
+			Reason:           req.Reason,
+		}, nil
+	})
+	if err != nil {
+		apierror.Abort(c, serviceAdjustmentError(err, "Failed to record refund"))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "service_history", history.ID, before, history)
+
+	log.Printf("✅ Service history %d refunded %s (%s) by admin %d", history.ID, amount, history.PaymentStatus, adminID)
+
+	notifyServiceAdjustment(history, *adjustment, "notification.refund_issued", i18n.Vars{"title": history.Title, "amount": amount})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Refund recorded successfully",
+		"data": gin.H{
+			"history":    history,
+			"adjustment": adjustment,
+		},
+	})
+}
+
+// saveServiceAdjustment locks the service history entry, lets apply validate it against the
+// locked values and describe the change, then persists the history, its ledger entry and the
+// worker's earnings correction in one transaction. Concurrent refunds or price changes on the
+// same entry wait for each other instead of both passing validation on a stale read.
+func saveServiceAdjustment(historyID string, apply func(history *models.ServiceHistory) (*models.ServiceAdjustment, error)) (before, history models.ServiceHistory, adjustment *models.ServiceAdjustment, err error) {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Worker").First(&history, historyID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apierror.NotFound("Service history not found")
+			}
+			return err
+		}
+
+		before = history
+		adjustment, err = apply(&history)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&history).Updates(map[string]interface{}{
+			"final_price":     history.FinalPrice,
+			"payment_status":  history.PaymentStatus,
+			"refunded_amount": history.RefundedAmount,
+			"refund_reason":   history.RefundReason,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(adjustment).Error; err != nil {
+			return err
+		}
+		return services.RecordEarningsAdjustment(tx, adjustment.WorkerID, adjustment.EarningsDelta)
+	})
+	return before, history, adjustment, err
+}
+
+// serviceAdjustmentError passes validation and lookup errors through and logs anything else as
+// an internal failure described by message
+func serviceAdjustmentError(err error, message string) error {
+	var apiErr *apierror.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return apierror.Internal(message, err)
+}
+
+// notifyServiceAdjustment tells both the customer and the worker about an admin correction with
+// catalog message, each in their own language, with amounts in the currency of the service's region
+func notifyServiceAdjustment(history models.ServiceHistory, adjustment models.ServiceAdjustment, message string, vars i18n.Vars) {
+	vars["currency"] = services.NewRegionService().ForCity(history.LocationCity).Currency
+	data := map[string]interface{}{
+		"service_request_id": history.ServiceRequestID,
+		"service_history_id": history.ID,
+		"adjustment_type":    adjustment.Type,
+		"amount":             adjustment.Amount,
+		"final_price":        history.FinalPrice,
+		"payment_status":     history.PaymentStatus,
+		"reason":             adjustment.Reason,
+	}
+
+	if err := SendLocalizedPushNotification(history.CustomerID, message, vars, "payment_adjustment", data); err != nil {
+		log.Printf("⚠️ Failed to notify customer %d of adjustment: %v", history.CustomerID, err)
+	}
+	if history.Worker.UserID != 0 {
+		if err := SendLocalizedPushNotification(history.Worker.UserID, message, vars, "payment_adjustment", data); err != nil {
+			log.Printf("⚠️ Failed to notify worker %d of adjustment: %v", history.WorkerID, err)
+		}
+	}
+}
+
+// refundPaymentStatus derives the payment status from the final price and the amount refunded so far
+func refundPaymentStatus(finalPrice, refunded money.Amount) string {
+	if refunded >= finalPrice {
+		return models.PaymentStatusRefunded
+	}
+	return models.PaymentStatusPartiallyRefunded
+}
+
+func priceOrZero(price *money.Amount) money.Amount {
+	if price == nil {
+		return 0
+	}
+	return *price
+}
@@ -6,8 +6,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
+	"repair-service-server/money"
 )
 
 // GetAllServices returns all services
@@ -15,7 +17,7 @@ func GetAllServices(c *gin.Context) {
 	var services []models.Service
 	if err := database.DB.Preload("Category").Find(&services).Error; err != nil {
 		log.Printf("❌ Failed to fetch services: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch services"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch services", nil))
 		return
 	}
 
@@ -28,23 +30,23 @@ func GetAllServices(c *gin.Context) {
 // CreateService creates a new service
 func CreateService(c *gin.Context) {
 	var req struct {
-		Name          string  `json:"name" binding:"required"`
-		Description   string  `json:"description"`
-		CategoryID    uint    `json:"category_id" binding:"required"`
-		Price         float64 `json:"price"`
-		ImageURL      string  `json:"image_url"`
-		IsActive      bool    `json:"is_active"`
-		NameAr        string  `json:"name_ar"`
-		DescriptionAr string  `json:"description_ar"`
-		BasePrice     float64 `json:"base_price"`
-		PriceUnit     string  `json:"price_unit"`
-		Guarantee     string  `json:"guarantee"`
-		Policies      string  `json:"policies"`
-		Duration      int     `json:"duration"`
+		Name          string       `json:"name" binding:"required"`
+		Description   string       `json:"description"`
+		CategoryID    uint         `json:"category_id" binding:"required"`
+		Price         money.Amount `json:"price"`
+		ImageURL      string       `json:"image_url"`
+		IsActive      bool         `json:"is_active"`
+		NameAr        string       `json:"name_ar"`
+		DescriptionAr string       `json:"description_ar"`
+		BasePrice     money.Amount `json:"base_price"`
+		PriceUnit     string       `json:"price_unit"`
+		Guarantee     string       `json:"guarantee"`
+		Policies      string       `json:"policies"`
+		Duration      int          `json:"duration"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
@@ -66,13 +68,14 @@ func CreateService(c *gin.Context) {
 
 	if err := database.DB.Create(&service).Error; err != nil {
 		log.Printf("❌ Failed to create service: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service"})
+		apierror.Abort(c, apierror.Internal("Failed to create service", nil))
 		return
 	}
 
 	// Preload related data
 	database.DB.Preload("Category").First(&service, service.ID)
 
+	invalidateServiceCache()
 	log.Printf("✅ Service created: %s (ID: %d)", service.Name, service.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -87,29 +90,29 @@ func UpdateService(c *gin.Context) {
 	serviceID := c.Param("id")
 	
 	var req struct {
-		Name          string  `json:"name" binding:"required"`
-		Description   string  `json:"description"`
-		CategoryID    uint    `json:"category_id" binding:"required"`
-		Price         float64 `json:"price"`
-		ImageURL      string  `json:"image_url"`
-		IsActive      bool    `json:"is_active"`
-		NameAr        string  `json:"name_ar"`
-		DescriptionAr string  `json:"description_ar"`
-		BasePrice     float64 `json:"base_price"`
-		PriceUnit     string  `json:"price_unit"`
-		Guarantee     string  `json:"guarantee"`
-		Policies      string  `json:"policies"`
-		Duration      int     `json:"duration"`
+		Name          string       `json:"name" binding:"required"`
+		Description   string       `json:"description"`
+		CategoryID    uint         `json:"category_id" binding:"required"`
+		Price         money.Amount `json:"price"`
+		ImageURL      string       `json:"image_url"`
+		IsActive      bool         `json:"is_active"`
+		NameAr        string       `json:"name_ar"`
+		DescriptionAr string       `json:"description_ar"`
+		BasePrice     money.Amount `json:"base_price"`
+		PriceUnit     string       `json:"price_unit"`
+		Guarantee     string       `json:"guarantee"`
+		Policies      string       `json:"policies"`
+		Duration      int          `json:"duration"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
 	var service models.Service
 	if err := database.DB.First(&service, serviceID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		apierror.Abort(c, apierror.NotFound("Service not found"))
 		return
 	}
 
@@ -129,13 +132,14 @@ func UpdateService(c *gin.Context) {
 
 	if err := database.DB.Save(&service).Error; err != nil {
 		log.Printf("❌ Failed to update service: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service"})
+		apierror.Abort(c, apierror.Internal("Failed to update service", nil))
 		return
 	}
 
 	// Preload related data
 	database.DB.Preload("Category").First(&service, service.ID)
 
+	invalidateServiceCache()
 	log.Printf("✅ Service updated: %s (ID: %d)", service.Name, service.ID)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -151,16 +155,17 @@ func DeleteService(c *gin.Context) {
 
 	var service models.Service
 	if err := database.DB.First(&service, serviceID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		apierror.Abort(c, apierror.NotFound("Service not found"))
 		return
 	}
 
 	if err := database.DB.Delete(&service).Error; err != nil {
 		log.Printf("❌ Failed to delete service: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service"})
+		apierror.Abort(c, apierror.Internal("Failed to delete service", nil))
 		return
 	}
 
+	invalidateServiceCache()
 	log.Printf("✅ Service deleted: %s (ID: %d)", service.Name, service.ID)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -174,7 +179,7 @@ func GetAllServiceOptionsForAdmin(c *gin.Context) {
 	var options []models.ServiceOption
 	if err := database.DB.Preload("Category").Find(&options).Error; err != nil {
 		log.Printf("❌ Failed to fetch service options: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service options"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch service options", nil))
 		return
 	}
 
@@ -187,19 +192,24 @@ func GetAllServiceOptionsForAdmin(c *gin.Context) {
 // CreateServiceOptionForAdmin creates a new service option for admin
 func CreateServiceOptionForAdmin(c *gin.Context) {
 	var req struct {
-		Title       string  `json:"title" binding:"required"`
-		Description string  `json:"description" binding:"required"`
-		Price       float64 `json:"price" binding:"required"`
-		Duration    int     `json:"duration" binding:"required"`
-		CategoryID  uint    `json:"category_id" binding:"required"`
-		ImageURL    string  `json:"image_url"`
-		Features    []string `json:"features"`
-		IsActive    bool    `json:"is_active"`
-		SortOrder   int     `json:"sort_order"`
+		Title       string       `json:"title" binding:"required"`
+		Description string       `json:"description" binding:"required"`
+		Price       money.Amount `json:"price" binding:"required"`
+		Duration    int          `json:"duration" binding:"required"`
+		CategoryID  uint         `json:"category_id" binding:"required"`
+		ImageURL    string       `json:"image_url"`
+		Features    []string     `json:"features"`
+		IsActive    bool         `json:"is_active"`
+		SortOrder   int          `json:"sort_order"`
+
+		WeekendSurchargePercent float64      `json:"weekend_surcharge_percent" binding:"gte=0,lte=200"`
+		UrgentSurchargePercent  float64      `json:"urgent_surcharge_percent" binding:"gte=0,lte=200"`
+		DistanceFeePerKm        money.Amount `json:"distance_fee_per_km" binding:"gte=0"`
+		FreeDistanceKm          float64      `json:"free_distance_km" binding:"gte=0"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
@@ -213,17 +223,23 @@ func CreateServiceOptionForAdmin(c *gin.Context) {
 		Features:    req.Features,
 		IsActive:    req.IsActive,
 		SortOrder:   req.SortOrder,
+
+		WeekendSurchargePercent: req.WeekendSurchargePercent,
+		UrgentSurchargePercent:  req.UrgentSurchargePercent,
+		DistanceFeePerKm:        req.DistanceFeePerKm,
+		FreeDistanceKm:          req.FreeDistanceKm,
 	}
 
 	if err := database.DB.Create(&option).Error; err != nil {
 		log.Printf("❌ Failed to create service option: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service option"})
+		apierror.Abort(c, apierror.Internal("Failed to create service option", nil))
 		return
 	}
 
 	// Preload related data
 	database.DB.Preload("Category").First(&option, option.ID)
 
+	invalidateServiceOptionCache()
 	log.Printf("✅ Service option created: %s (ID: %d)", option.Title, option.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -238,25 +254,30 @@ func UpdateServiceOptionForAdmin(c *gin.Context) {
 	optionID := c.Param("id")
 	
 	var req struct {
-		Title       string  `json:"title" binding:"required"`
-		Description string  `json:"description" binding:"required"`
-		Price       float64 `json:"price" binding:"required"`
-		Duration    int     `json:"duration" binding:"required"`
-		CategoryID  uint    `json:"category_id" binding:"required"`
-		ImageURL    string  `json:"image_url"`
-		Features    []string `json:"features"`
-		IsActive    bool    `json:"is_active"`
-		SortOrder   int     `json:"sort_order"`
+		Title       string       `json:"title" binding:"required"`
+		Description string       `json:"description" binding:"required"`
+		Price       money.Amount `json:"price" binding:"required"`
+		Duration    int          `json:"duration" binding:"required"`
+		CategoryID  uint         `json:"category_id" binding:"required"`
+		ImageURL    string       `json:"image_url"`
+		Features    []string     `json:"features"`
+		IsActive    bool         `json:"is_active"`
+		SortOrder   int          `json:"sort_order"`
+
+		WeekendSurchargePercent float64      `json:"weekend_surcharge_percent" binding:"gte=0,lte=200"`
+		UrgentSurchargePercent  float64      `json:"urgent_surcharge_percent" binding:"gte=0,lte=200"`
+		DistanceFeePerKm        money.Amount `json:"distance_fee_per_km" binding:"gte=0"`
+		FreeDistanceKm          float64      `json:"free_distance_km" binding:"gte=0"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
 	var option models.ServiceOption
 	if err := database.DB.First(&option, optionID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service option not found"})
+		apierror.Abort(c, apierror.NotFound("Service option not found"))
 		return
 	}
 
@@ -269,16 +290,21 @@ func UpdateServiceOptionForAdmin(c *gin.Context) {
 	option.Features = req.Features
 	option.IsActive = req.IsActive
 	option.SortOrder = req.SortOrder
+	option.WeekendSurchargePercent = req.WeekendSurchargePercent
+	option.UrgentSurchargePercent = req.UrgentSurchargePercent
+	option.DistanceFeePerKm = req.DistanceFeePerKm
+	option.FreeDistanceKm = req.FreeDistanceKm
 
 	if err := database.DB.Save(&option).Error; err != nil {
 		log.Printf("❌ Failed to update service option: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service option"})
+		apierror.Abort(c, apierror.Internal("Failed to update service option", nil))
 		return
 	}
 
 	// Preload related data
 	database.DB.Preload("Category").First(&option, option.ID)
 
+	invalidateServiceOptionCache()
 	log.Printf("✅ Service option updated: %s (ID: %d)", option.Title, option.ID)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -294,16 +320,17 @@ func DeleteServiceOptionForAdmin(c *gin.Context) {
 
 	var option models.ServiceOption
 	if err := database.DB.First(&option, optionID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service option not found"})
+		apierror.Abort(c, apierror.NotFound("Service option not found"))
 		return
 	}
 
 	if err := database.DB.Delete(&option).Error; err != nil {
 		log.Printf("❌ Failed to delete service option: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete service option"})
+		apierror.Abort(c, apierror.Internal("Failed to delete service option", nil))
 		return
 	}
 
+	invalidateServiceOptionCache()
 	log.Printf("✅ Service option deleted: %s (ID: %d)", option.Title, option.ID)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/middleware"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// GetPlatformSettings returns every admin-editable setting with its current value
+func GetPlatformSettings(c *gin.Context) {
+	values, err := services.NewSettingsService().All()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load settings", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    values,
+	})
+}
+
+// UpdatePlatformSettings changes the settings in the body, e.g. {"tip_min_amount": 20}
+func UpdatePlatformSettings(c *gin.Context) {
+	var req map[string]float64
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	if len(req) == 0 {
+		apierror.Abort(c, apierror.Validation("No settings provided"))
+		return
+	}
+
+	settings := services.NewSettingsService()
+	before, err := settings.All()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load settings", err))
+		return
+	}
+
+	if err := settings.Update(req, c.GetUint("user_id")); err != nil {
+		if errors.Is(err, services.ErrInvalidSetting) {
+			apierror.Abort(c, apierror.Validation(err.Error()))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to update settings", err))
+		return
+	}
+
+	after, err := settings.All()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load settings", err))
+		return
+	}
+	middleware.RecordAuditChange(c, "platform_settings", nil, before, after)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Settings updated",
+		"data":    after,
+	})
+}
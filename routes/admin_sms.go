@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/services"
+)
+
+// GetSMSUsage returns SMS volume and cost per purpose and notification type (?from, ?to)
+func GetSMSUsage(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+	if !ok {
+		return
+	}
+
+	usage, err := services.NewSMSService().Usage(filter.From, filter.To.AddDate(0, 0, 1))
+	if err != nil {
+		log.Printf("❌ Failed to load SMS usage: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to load SMS usage", nil))
+		return
+	}
+
+	var total services.SMSUsage
+	for _, row := range usage {
+		total.Messages += row.Messages
+		total.Failed += row.Failed
+		total.Segments += row.Segments
+		total.Cost += row.Cost
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"from":  filter.From.Format("2006-01-02"),
+			"to":    filter.To.Format("2006-01-02"),
+			"usage": usage,
+			"total": gin.H{
+				"messages": total.Messages,
+				"failed":   total.Failed,
+				"segments": total.Segments,
+				"cost":     total.Cost,
+			},
+		},
+	})
+}
@@ -4,11 +4,16 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
 )
 
 // GetAllWorkers returns all workers with pagination and filters
@@ -25,6 +30,10 @@ func GetAllWorkers(c *gin.Context) {
 	}
 
 	offset := (page - 1) * limit
+	fields, ok := requestedFields(c)
+	if !ok {
+		return
+	}
 
 	var workers []models.WorkerProfile
 	var total int64
@@ -37,72 +46,32 @@ func GetAllWorkers(c *gin.Context) {
 	} else if verified == "false" {
 		query = query.Where("is_verified = ?", false)
 	}
+	if status := c.Query("onboarding_status"); status != "" {
+		query = query.Where("onboarding_status = ?", status)
+	}
+
+	if wantsCSV(c) {
+		streamCSV(c, "workers", workerCSVHeader, query, workerCSVRow)
+		return
+	}
 
 	// Get total count
 	if err := query.Count(&total).Error; err != nil {
 		log.Printf("❌ Failed to count workers: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count workers"})
+		apierror.Abort(c, apierror.Internal("Failed to count workers", nil))
 		return
 	}
 
 	// Get workers with pagination
 	if err := query.Offset(offset).Limit(limit).Order("created_at DESC").Find(&workers).Error; err != nil {
 		log.Printf("❌ Failed to fetch workers: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch workers"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch workers", nil))
 		return
 	}
 
-	// Format response
-	var workerList []gin.H
-	for _, worker := range workers {
-		workerList = append(workerList, gin.H{
-			"id":                    worker.ID,
-			"user_id":               worker.UserID,
-			"category_id":           worker.CategoryID,
-			"category": gin.H{
-				"id":   worker.Category.ID,
-				"name": worker.Category.Name,
-			},
-			"phone_number":          worker.PhoneNumber,
-			"country":               worker.Country,
-			"state":                 worker.State,
-			"city":                  worker.City,
-			"postal_code":           worker.PostalCode,
-			"address":               worker.Address,
-			"experience":            worker.Experience,
-			"skills":                worker.Skills,
-			"hourly_rate":           worker.HourlyRate,
-			"profile_photo":         worker.ProfilePhoto,
-			"id_card_photo":         worker.IDCardPhoto,
-			"id_card_photo_back":    worker.IDCardBackPhoto,
-			"is_available":          worker.IsAvailable,
-			"current_lat":           worker.CurrentLat,
-			"current_lng":           worker.CurrentLng,
-			"last_location_update":  worker.LastLocationUpdate,
-			"location_accuracy":     worker.LocationAccuracy,
-			"active_requests":       worker.ActiveRequests,
-			"completed_jobs":        worker.CompletedJobs,
-			"rating":                worker.Rating,
-			"total_reviews":         worker.TotalReviews,
-			"is_verified":           worker.IsVerified,
-			"created_at":            worker.CreatedAt,
-			"updated_at":            worker.UpdatedAt,
-			"user": gin.H{
-				"id":                worker.User.ID,
-				"full_name":         worker.User.FullName,
-				"phone_number":      worker.User.PhoneNumber,
-				"role":              worker.User.Role,
-				"profile_picture_url": worker.User.ProfilePictureURL,
-				"is_active":         worker.User.IsActive,
-				"created_at":        worker.User.CreatedAt,
-				"updated_at":        worker.User.UpdatedAt,
-			},
-		})
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    workerList,
+		"data":    serializers.Sparse(serializers.WorkerProfiles(workers), fields),
 		"total":   total,
 		"page":    page,
 		"limit":   limit,
@@ -114,56 +83,14 @@ func GetWorkerById(c *gin.Context) {
 	workerID := c.Param("id")
 	
 	var worker models.WorkerProfile
-	if err := database.DB.Preload("User").Preload("Category").First(&worker, workerID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+	if err := database.DB.Preload("User").Preload("Category").Preload("Categories.Category").First(&worker, workerID).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": gin.H{
-			"id":                    worker.ID,
-			"user_id":               worker.UserID,
-			"category_id":           worker.CategoryID,
-			"category": gin.H{
-				"id":   worker.Category.ID,
-				"name": worker.Category.Name,
-			},
-			"phone_number":          worker.PhoneNumber,
-			"country":               worker.Country,
-			"state":                 worker.State,
-			"city":                  worker.City,
-			"postal_code":           worker.PostalCode,
-			"address":               worker.Address,
-			"experience":            worker.Experience,
-			"skills":                worker.Skills,
-			"hourly_rate":           worker.HourlyRate,
-			"profile_photo":         worker.ProfilePhoto,
-			"id_card_photo":         worker.IDCardPhoto,
-			"id_card_photo_back":    worker.IDCardBackPhoto,
-			"is_available":          worker.IsAvailable,
-			"current_lat":           worker.CurrentLat,
-			"current_lng":           worker.CurrentLng,
-			"last_location_update":  worker.LastLocationUpdate,
-			"location_accuracy":     worker.LocationAccuracy,
-			"active_requests":       worker.ActiveRequests,
-			"completed_jobs":        worker.CompletedJobs,
-			"rating":                worker.Rating,
-			"total_reviews":         worker.TotalReviews,
-			"is_verified":           worker.IsVerified,
-			"created_at":            worker.CreatedAt,
-			"updated_at":            worker.UpdatedAt,
-			"user": gin.H{
-				"id":                worker.User.ID,
-				"full_name":         worker.User.FullName,
-				"phone_number":      worker.User.PhoneNumber,
-				"role":              worker.User.Role,
-				"profile_picture_url": worker.User.ProfilePictureURL,
-				"is_active":         worker.User.IsActive,
-				"created_at":        worker.User.CreatedAt,
-				"updated_at":        worker.User.UpdatedAt,
-			},
-		},
+		"data": serializers.WorkerProfile(worker),
 	})
 }
 
@@ -173,7 +100,7 @@ func GetWorkerStatsForAdmin(c *gin.Context) {
 	
 	var worker models.WorkerProfile
 	if err := database.DB.First(&worker, workerID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
 		return
 	}
 
@@ -187,6 +114,12 @@ func GetWorkerStatsForAdmin(c *gin.Context) {
 		database.DB.Create(&stats)
 	}
 
+	declineReasons, err := services.NewReportService().GetWorkerDeclineReasons(worker.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load decline reasons", err))
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"data": gin.H{
@@ -208,6 +141,7 @@ func GetWorkerStatsForAdmin(c *gin.Context) {
 			"daily_jobs_responded":      stats.DailyJobsResponded,
 			"daily_jobs_completed":      stats.DailyJobsCompleted,
 			"daily_jobs_declined":       stats.DailyJobsDeclined,
+			"decline_reasons":           declineReasons,
 			"daily_earnings":            stats.DailyEarnings,
 			"daily_work_hours":          stats.DailyWorkHours,
 			"response_rate":             stats.ResponseRate,
@@ -231,71 +165,40 @@ func VerifyWorker(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
 	var worker models.WorkerProfile
-	if err := database.DB.Preload("User").Preload("Category").First(&worker, workerID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+	if err := database.DB.Preload("User").Preload("Category").Preload("Categories.Category").First(&worker, workerID).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
 		return
 	}
 
+	before := worker
 	worker.IsVerified = req.IsVerified
+	// Keep onboarding in step: verifying completes it, unverifying sends the worker back to review
+	now := time.Now()
+	if req.IsVerified && worker.OnboardingStatus != models.OnboardingActive {
+		worker.OnboardingStatus = models.OnboardingActive
+		worker.OnboardingUpdatedAt = &now
+	} else if !req.IsVerified && worker.OnboardingStatus == models.OnboardingActive {
+		worker.OnboardingStatus = models.OnboardingUnderReview
+		worker.OnboardingUpdatedAt = &now
+	}
 	if err := database.DB.Save(&worker).Error; err != nil {
 		log.Printf("❌ Failed to update worker verification: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update worker verification"})
+		apierror.Abort(c, apierror.Internal("Failed to update worker verification", nil))
 		return
 	}
+	middleware.RecordAuditChange(c, "workers", worker.ID, before, worker)
 
 	log.Printf("✅ Worker %d verification updated to %v by admin %d", worker.ID, req.IsVerified, adminID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Worker verification updated successfully",
-		"data": gin.H{
-			"id":                    worker.ID,
-			"user_id":               worker.UserID,
-			"category_id":           worker.CategoryID,
-			"category": gin.H{
-				"id":   worker.Category.ID,
-				"name": worker.Category.Name,
-			},
-			"phone_number":          worker.PhoneNumber,
-			"country":               worker.Country,
-			"state":                 worker.State,
-			"city":                  worker.City,
-			"postal_code":           worker.PostalCode,
-			"address":               worker.Address,
-			"experience":            worker.Experience,
-			"skills":                worker.Skills,
-			"hourly_rate":           worker.HourlyRate,
-			"profile_photo":         worker.ProfilePhoto,
-			"id_card_photo":         worker.IDCardPhoto,
-			"id_card_photo_back":    worker.IDCardBackPhoto,
-			"is_available":          worker.IsAvailable,
-			"current_lat":           worker.CurrentLat,
-			"current_lng":           worker.CurrentLng,
-			"last_location_update":  worker.LastLocationUpdate,
-			"location_accuracy":     worker.LocationAccuracy,
-			"active_requests":       worker.ActiveRequests,
-			"completed_jobs":        worker.CompletedJobs,
-			"rating":                worker.Rating,
-			"total_reviews":         worker.TotalReviews,
-			"is_verified":           worker.IsVerified,
-			"created_at":            worker.CreatedAt,
-			"updated_at":            worker.UpdatedAt,
-			"user": gin.H{
-				"id":                worker.User.ID,
-				"full_name":         worker.User.FullName,
-				"phone_number":      worker.User.PhoneNumber,
-				"role":              worker.User.Role,
-				"profile_picture_url": worker.User.ProfilePictureURL,
-				"is_active":         worker.User.IsActive,
-				"created_at":        worker.User.CreatedAt,
-				"updated_at":        worker.User.UpdatedAt,
-			},
-		},
+		"data": serializers.WorkerProfile(worker),
 	})
 }
 
@@ -309,70 +212,76 @@ func UpdateWorkerAvailability(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
 	var worker models.WorkerProfile
-	if err := database.DB.Preload("User").Preload("Category").First(&worker, workerID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+	if err := database.DB.Preload("User").Preload("Category").Preload("Categories.Category").First(&worker, workerID).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
 		return
 	}
 
+	before := worker
 	worker.IsAvailable = req.IsAvailable
 	if err := database.DB.Save(&worker).Error; err != nil {
 		log.Printf("❌ Failed to update worker availability: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update worker availability"})
+		apierror.Abort(c, apierror.Internal("Failed to update worker availability", nil))
 		return
 	}
+	middleware.RecordAuditChange(c, "workers", worker.ID, before, worker)
 
 	log.Printf("✅ Worker %d availability updated to %v by admin %d", worker.ID, req.IsAvailable, adminID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Worker availability updated successfully",
+		"data": serializers.WorkerProfile(worker),
+	})
+}
+
+// UpdateWorkerCapacity updates how many concurrent jobs a worker may hold (admin only)
+func UpdateWorkerCapacity(c *gin.Context) {
+	workerID := c.Param("id")
+	adminID := c.GetUint("user_id")
+
+	var req struct {
+		MaxConcurrentJobs int `json:"max_concurrent_jobs" binding:"required,min=1,max=10"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	var worker models.WorkerProfile
+	if err := database.DB.First(&worker, workerID).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
+		return
+	}
+
+	before := worker
+	if err := database.DB.Model(&worker).Update("max_concurrent_jobs", req.MaxConcurrentJobs).Error; err != nil {
+		log.Printf("❌ Failed to update worker capacity: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to update worker capacity", nil))
+		return
+	}
+	worker.MaxConcurrentJobs = req.MaxConcurrentJobs
+	middleware.RecordAuditChange(c, "workers", worker.ID, before, worker)
+
+	capacity, err := services.NewWorkerCapacityService().GetCapacity(&worker)
+	if err != nil {
+		log.Printf("⚠️ Failed to compute capacity for worker %d: %v", worker.ID, err)
+	}
+
+	log.Printf("✅ Worker %d max concurrent jobs set to %d by admin %d", worker.ID, req.MaxConcurrentJobs, adminID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Worker capacity updated successfully",
 		"data": gin.H{
-			"id":                    worker.ID,
-			"user_id":               worker.UserID,
-			"category_id":           worker.CategoryID,
-			"category": gin.H{
-				"id":   worker.Category.ID,
-				"name": worker.Category.Name,
-			},
-			"phone_number":          worker.PhoneNumber,
-			"country":               worker.Country,
-			"state":                 worker.State,
-			"city":                  worker.City,
-			"postal_code":           worker.PostalCode,
-			"address":               worker.Address,
-			"experience":            worker.Experience,
-			"skills":                worker.Skills,
-			"hourly_rate":           worker.HourlyRate,
-			"profile_photo":         worker.ProfilePhoto,
-			"id_card_photo":         worker.IDCardPhoto,
-			"id_card_photo_back":    worker.IDCardBackPhoto,
-			"is_available":          worker.IsAvailable,
-			"current_lat":           worker.CurrentLat,
-			"current_lng":           worker.CurrentLng,
-			"last_location_update":  worker.LastLocationUpdate,
-			"location_accuracy":     worker.LocationAccuracy,
-			"active_requests":       worker.ActiveRequests,
-			"completed_jobs":        worker.CompletedJobs,
-			"rating":                worker.Rating,
-			"total_reviews":         worker.TotalReviews,
-			"is_verified":           worker.IsVerified,
-			"created_at":            worker.CreatedAt,
-			"updated_at":            worker.UpdatedAt,
-			"user": gin.H{
-				"id":                worker.User.ID,
-				"full_name":         worker.User.FullName,
-				"phone_number":      worker.User.PhoneNumber,
-				"role":              worker.User.Role,
-				"profile_picture_url": worker.User.ProfilePictureURL,
-				"is_active":         worker.User.IsActive,
-				"created_at":        worker.User.CreatedAt,
-				"updated_at":        worker.User.UpdatedAt,
-			},
+			"id":       worker.ID,
+			"capacity": capacity,
 		},
 	})
 }
@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/utils"
+)
+
+// GetAllServiceZones returns all service zones, optionally filtered by city
+func GetAllServiceZones(c *gin.Context) {
+	query := database.DB.Order("city ASC, name ASC")
+	if city := c.Query("city"); city != "" {
+		query = query.Where("city = ?", services.NormalizeCity(city))
+	}
+
+	var zones []models.ServiceZone
+	if err := query.Find(&zones).Error; err != nil {
+		log.Printf("❌ Failed to fetch service zones: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to fetch service zones", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    zones,
+	})
+}
+
+// GetServiceZoneById returns a single service zone
+func GetServiceZoneById(c *gin.Context) {
+	var zone models.ServiceZone
+	if err := database.DB.First(&zone, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service zone not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    zone,
+	})
+}
+
+// CreateServiceZone creates a new service zone
+func CreateServiceZone(c *gin.Context) {
+	var req models.ServiceZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	if !validZonePolygon(req.Polygon) {
+		apierror.Abort(c, apierror.Validation("Polygon contains invalid coordinates"))
+		return
+	}
+
+	zone := models.ServiceZone{IsActive: true}
+	applyServiceZoneRequest(&zone, &req)
+
+	if err := database.DB.Create(&zone).Error; err != nil {
+		log.Printf("❌ Failed to create service zone: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to create service zone", nil))
+		return
+	}
+	middleware.RecordAuditChange(c, "zones", zone.ID, nil, zone)
+
+	log.Printf("✅ Service zone created: %s (ID: %d)", zone.Name, zone.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Service zone created successfully",
+		"data":    zone,
+	})
+}
+
+// UpdateServiceZone updates an existing service zone
+func UpdateServiceZone(c *gin.Context) {
+	var req models.ServiceZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(err.Error()))
+		return
+	}
+
+	if !validZonePolygon(req.Polygon) {
+		apierror.Abort(c, apierror.Validation("Polygon contains invalid coordinates"))
+		return
+	}
+
+	var zone models.ServiceZone
+	if err := database.DB.First(&zone, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service zone not found"))
+		return
+	}
+
+	before := zone
+	applyServiceZoneRequest(&zone, &req)
+
+	if err := database.DB.Save(&zone).Error; err != nil {
+		log.Printf("❌ Failed to update service zone: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to update service zone", nil))
+		return
+	}
+	middleware.RecordAuditChange(c, "zones", zone.ID, before, zone)
+
+	log.Printf("✅ Service zone updated: %s (ID: %d)", zone.Name, zone.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Service zone updated successfully",
+		"data":    zone,
+	})
+}
+
+// DeleteServiceZone soft deletes a service zone
+func DeleteServiceZone(c *gin.Context) {
+	var zone models.ServiceZone
+	if err := database.DB.First(&zone, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service zone not found"))
+		return
+	}
+
+	if err := database.DB.Delete(&zone).Error; err != nil {
+		log.Printf("❌ Failed to delete service zone: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to delete service zone", nil))
+		return
+	}
+	middleware.RecordAuditChange(c, "zones", zone.ID, zone, nil)
+
+	log.Printf("✅ Service zone deleted: %s (ID: %d)", zone.Name, zone.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Service zone deleted successfully",
+	})
+}
+
+// applyServiceZoneRequest copies the request fields onto the zone, applying defaults
+func applyServiceZoneRequest(zone *models.ServiceZone, req *models.ServiceZoneRequest) {
+	zone.Name = req.Name
+	zone.City = services.NormalizeCity(req.City)
+	zone.Polygon = req.Polygon
+	zone.SupportedCategoryIDs = req.SupportedCategoryIDs
+
+	zone.BroadcastRadiusKm = req.BroadcastRadiusKm
+	if zone.BroadcastRadiusKm == 0 {
+		zone.BroadcastRadiusKm = utils.GetDefaultBroadcastRadius()
+	}
+	zone.SurgeMultiplier = req.SurgeMultiplier
+	if zone.SurgeMultiplier == 0 {
+		zone.SurgeMultiplier = 1
+	}
+	if req.IsActive != nil {
+		zone.IsActive = *req.IsActive
+	}
+}
+
+// validZonePolygon checks that every polygon vertex is a valid coordinate
+func validZonePolygon(polygon []models.ZonePoint) bool {
+	for _, p := range polygon {
+		if !utils.IsLocationValid(p.Lat, p.Lng) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,156 @@
+package routes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+)
+
+// aiBookingPath is the route recorded on the idempotency keys of assistant bookings
+const aiBookingPath = "/api/v1/ws/ai-chat"
+
+// CreateServiceRequestFromDraft books a request the AI assistant proposed and the customer
+// accepted. It goes through the same location checks, dispatch and notifications as a request
+// created from the app, at the customer's default address. Drafts with a time are created as
+// scheduled requests. Like POST /service-requests it counts against the request_create budget,
+// needs a verified phone number and books each proposal only once.
+func CreateServiceRequestFromDraft(customerID uint, draft services.ServiceRequestDraft) (serviceRequest *models.CustomerServiceRequest, err error) {
+	if result := middleware.AllowRequest(middleware.BudgetRequestCreate, fmt.Sprintf("user:%d", customerID), ""); !result.Allowed {
+		return nil, services.ErrRequestBudgetExceeded
+	}
+	var customer models.User
+	if err := database.DB.First(&customer, customerID).Error; err != nil {
+		return nil, err
+	}
+	if !customer.IsPhoneVerified() {
+		return nil, services.ErrPhoneUnverified
+	}
+
+	claim, err := claimDraft(customerID, draft)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		completeDraftClaim(claim, serviceRequest)
+	}()
+
+	var address models.Address
+	if err := database.DB.Where("user_id = ? AND is_default = ?", customerID, true).First(&address).Error; err != nil {
+		return nil, services.ErrNoDefaultAddress
+	}
+
+	req := models.CustomerServiceRequestCreate{
+		CategoryID:      draft.CategoryID,
+		Title:           draft.Title,
+		Description:     draft.Description,
+		Priority:        draft.Priority,
+		Budget:          draft.Budget,
+		LocationLat:     address.Latitude,
+		LocationLng:     address.Longitude,
+		LocationAddress: address.AddressDetails,
+		LocationCity:    address.City,
+	}
+	location, err := resolveLocation(&req)
+	if err != nil {
+		return nil, err
+	}
+	// The assistant may judge a problem urgent in a category where urgent service is turned off
+	if req.Priority == "urgent" {
+		if allowed, err := services.NewCategoryService().UrgentAllowed(req.CategoryID); err != nil {
+			return nil, err
+		} else if !allowed {
+			req.Priority = "high"
+		}
+	}
+
+	serviceRequest = &models.CustomerServiceRequest{
+		CustomerID:      customerID,
+		CategoryID:      req.CategoryID,
+		Title:           req.Title,
+		Description:     req.Description,
+		Priority:        req.Priority,
+		Budget:          req.Budget,
+		LocationLat:     &req.LocationLat,
+		LocationLng:     &req.LocationLng,
+		LocationAddress: req.LocationAddress,
+		LocationCity:    req.LocationCity,
+	}
+	location.apply(serviceRequest)
+
+	if draft.ScheduledFor != nil {
+		serviceRequest.Status = models.RequestStatusScheduled
+		serviceRequest.ScheduleAt(*draft.ScheduledFor)
+	} else {
+		expiresAt := time.Now().Add(3 * time.Minute)
+		serviceRequest.Status = models.RequestStatusBroadcast
+		serviceRequest.ExpiresAt = &expiresAt
+		applyDispatchMode(serviceRequest, "")
+	}
+
+	if err := database.DB.Create(serviceRequest).Error; err != nil {
+		return nil, err
+	}
+	publishRequestEvent("request_created", *serviceRequest)
+	enqueueRequestConfirmationEmail(*serviceRequest)
+	linkDiagnosis(customerID, draft.DiagnosisID, serviceRequest.ID)
+
+	if draft.ScheduledFor == nil {
+		startDispatch(*serviceRequest)
+		trackJobReceivedInCategory(*serviceRequest)
+	}
+	return serviceRequest, nil
+}
+
+// claimDraft stores an idempotency key for the proposal, like the Idempotency middleware does for
+// an HTTP request, so accepting the same proposal again is refused instead of booking it twice
+func claimDraft(customerID uint, draft services.ServiceRequestDraft) (*models.IdempotencyKey, error) {
+	proposal, err := json.Marshal(draft)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(proposal)
+	claim := models.IdempotencyKey{
+		UserID:      customerID,
+		Key:         "ai-draft:" + hex.EncodeToString(hash[:]),
+		Method:      "WS",
+		Path:        aiBookingPath,
+		RequestHash: hex.EncodeToString(hash[:]),
+		ExpiresAt:   time.Now().Add(middleware.IdempotencyTTL),
+	}
+
+	database.DB.Where("user_id = ? AND key = ? AND expires_at < ?", customerID, claim.Key, time.Now()).Delete(&models.IdempotencyKey{})
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&claim)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, services.ErrDraftAlreadyBooked
+	}
+	return &claim, nil
+}
+
+// completeDraftClaim records the booked request on the claim, or releases the claim when booking
+// failed so the proposal can be accepted again
+func completeDraftClaim(claim *models.IdempotencyKey, serviceRequest *models.CustomerServiceRequest) {
+	if serviceRequest == nil || serviceRequest.ID == 0 {
+		database.DB.Delete(&models.IdempotencyKey{}, claim.ID)
+		return
+	}
+	response, _ := json.Marshal(map[string]uint{"service_request_id": serviceRequest.ID})
+	database.DB.Model(claim).Updates(map[string]interface{}{
+		"status_code":  http.StatusCreated,
+		"content_type": "application/json",
+		"response":     response,
+		"completed_at": time.Now(),
+	})
+}
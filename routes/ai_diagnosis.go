@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// DiagnosePhoto diagnoses a photo of a problem the customer uploads. The result can be passed
+// as diagnosis_id when creating the service request for it.
+func DiagnosePhoto(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	// Each photo is a call to the AI provider, counted with the assistant's messages
+	if result := middleware.AllowAIPrompt(middleware.RateLimitIdentity(c)); !result.Allowed {
+		middleware.AbortRateLimited(c, result)
+		return
+	}
+
+	var req struct {
+		Description string `form:"description" binding:"max=1000"`
+		Language    string `form:"language" binding:"omitempty,oneof=fr ar en"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+
+	header, err := c.FormFile("image")
+	if err != nil {
+		validation.Fail(c, "image", "required", "")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to read image", err))
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, header.Size+1))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to read image", err))
+		return
+	}
+
+	photo, err := services.PrepareDiagnosisImage(data)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImage) {
+			apierror.Abort(c, apierror.Validation(services.ErrInvalidImage.Error()))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to process image", err))
+		return
+	}
+
+	diagnosis, err := services.NewAIService().DiagnoseImage(userID, photo, req.Description, req.Language)
+	switch {
+	case errors.Is(err, services.ErrAIQuotaExhausted):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(services.AIQuotaRetryAfter().Seconds()))))
+		apierror.Abort(c, apierror.Unavailable("AI diagnosis is busy. Please try again in a few minutes.", err))
+		return
+	case errors.Is(err, services.ErrAIUnavailable):
+		apierror.Abort(c, apierror.Unavailable("AI diagnosis is currently unavailable", err))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Unavailable("Failed to diagnose the photo. Please try again.", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    diagnosis,
+	})
+}
+
+// GetDiagnosis returns one of the customer's photo diagnoses
+func GetDiagnosis(c *gin.Context) {
+	var diagnosis models.AIDiagnosis
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("id"), c.GetUint("user_id")).First(&diagnosis).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Diagnosis not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    diagnosis,
+	})
+}
+
+// checkDiagnosis makes sure a diagnosis_id sent with a new request is the customer's own and
+// not already booked
+func checkDiagnosis(c *gin.Context, userID uint, diagnosisID *uint) bool {
+	if diagnosisID == nil {
+		return true
+	}
+	var count int64
+	if err := database.DB.Model(&models.AIDiagnosis{}).
+		Where("id = ? AND user_id = ? AND service_request_id IS NULL", *diagnosisID, userID).
+		Count(&count).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load diagnosis", err))
+		return false
+	}
+	if count == 0 {
+		validation.Fail(c, "diagnosis_id", "default", "")
+		return false
+	}
+	return true
+}
+
+// linkDiagnosis attaches a diagnosis to the request created for it. The request stands even
+// if the link fails.
+func linkDiagnosis(userID uint, diagnosisID *uint, requestID uint) {
+	if diagnosisID == nil {
+		return
+	}
+	if err := services.LinkDiagnosis(userID, *diagnosisID, requestID); err != nil {
+		log.Printf("⚠️ Failed to link diagnosis %d to service request %d: %v", *diagnosisID, requestID, err)
+	}
+}
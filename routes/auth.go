@@ -6,21 +6,25 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/serializers"
 	"repair-service-server/utils"
+	"repair-service-server/validation"
 )
 
 // AuthRequest represents the authentication request
 type AuthRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required"`
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
 	Password    string `json:"password" binding:"required,min=6"`
-	FullName    string `json:"full_name" binding:"required"`
+	FullName    string `json:"full_name" binding:"required,min=2,max=100"`
 }
 
 // SignInRequest represents the sign in request
 type SignInRequest struct {
-	PhoneNumber string `json:"phone_number" binding:"required"`
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
 	Password    string `json:"password" binding:"required"`
 }
 
@@ -47,43 +51,24 @@ func RegisterAuthRoutes(router *gin.RouterGroup) {
 // signUp handles user registration
 func signUp(c *gin.Context) {
 	var req AuthRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request data",
-			"message": err.Error(),
-		})
+	if !validation.BindJSON(c, &req) {
 		return
 	}
 
 	// Format phone number
-	phoneNumber := utils.FormatPhoneNumber(req.PhoneNumber)
-
-	// Validate phone number
-	if !utils.ValidatePhoneNumber(phoneNumber) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid phone number",
-			"message": "Phone number must be in +222 format",
-		})
-		return
-	}
+	phoneNumber := validation.NormalizePhone(req.PhoneNumber)
 
 	// Check if user already exists
 	var existingUser models.User
 	if err := database.DB.Where("phone_number = ?", phoneNumber).First(&existingUser).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{
-			"error":   "User already exists",
-			"message": "A user with this phone number already exists",
-		})
+		apierror.Abort(c, apierror.Conflict("A user with this phone number already exists"))
 		return
 	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Password hashing failed",
-			"message": "Failed to process password",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to process password", err))
 		return
 	}
 
@@ -97,20 +82,14 @@ func signUp(c *gin.Context) {
 	}
 
 	if err := database.DB.Create(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "User creation failed",
-			"message": "Failed to create user account",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to create user account", err))
 		return
 	}
 
 	// Generate token
-	token, err := utils.GenerateToken(user.ID, string(user.Role))
+	token, err := utils.GenerateToken(user.ID, string(user.Role), user.TokenVersion)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Token generation failed",
-			"message": "Failed to generate authentication token",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to generate authentication token", err))
 		return
 	}
 
@@ -127,7 +106,7 @@ func signUp(c *gin.Context) {
 		"token": token,
 		"refresh_token": token, // For now, use same token as refresh token
 		"expires_in": 24 * 60 * 60, // 24 hours in seconds
-		"user": user,
+		"user": serializers.User(user),
 		"redirect_to": redirectTo,
 	})
 }
@@ -135,73 +114,49 @@ func signUp(c *gin.Context) {
 // signIn handles user authentication
 func signIn(c *gin.Context) {
 	var req SignInRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request data",
-			"message": err.Error(),
-		})
+	if !validation.BindJSON(c, &req) {
 		return
 	}
 
 	// Format phone number
-	phoneNumber := utils.FormatPhoneNumber(req.PhoneNumber)
-
-	// Validate phone number
-	if !utils.ValidatePhoneNumber(phoneNumber) {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid phone number",
-			"message": "Phone number must be in +222 format",
-		})
-		return
-	}
+	phoneNumber := validation.NormalizePhone(req.PhoneNumber)
 
 	// Find user by phone number
 	var user models.User
 	if err := database.DB.Where("phone_number = ?", phoneNumber).First(&user).Error; err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Authentication failed",
-			"message": "Invalid phone number or password",
-		})
+		apierror.Abort(c, apierror.Unauthorized("Invalid phone number or password"))
 		return
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Account deactivated",
-			"message": "Your account has been deactivated",
-		})
+		apierror.Abort(c, apierror.Unauthorized("Your account has been deactivated"))
 		return
 	}
 
 	// Verify password
 	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Authentication failed",
-			"message": "Invalid phone number or password",
-		})
+		apierror.Abort(c, apierror.Unauthorized("Invalid phone number or password"))
 		return
 	}
 
 	// Generate token
-	token, err := utils.GenerateToken(user.ID, string(user.Role))
+	token, err := utils.GenerateToken(user.ID, string(user.Role), user.TokenVersion)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Token generation failed",
-			"message": "Failed to generate authentication token",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to generate authentication token", err))
 		return
 	}
 
 	// Check if user is a worker and has a profile
-	var workerProfile *models.WorkerProfile
+	var workerProfile *serializers.WorkerProfileResponse
 	var redirectTo string
 
 	if user.Role == models.RoleWorker {
 		var profile models.WorkerProfile
 		if err := database.DB.Where("user_id = ?", user.ID).First(&profile).Error; err == nil {
 			// Worker has profile, redirect to worker dashboard
-			workerProfile = &profile
+			resp := serializers.WorkerProfile(profile)
+			workerProfile = &resp
 			redirectTo = "worker"
 		} else {
 			// Worker doesn't have profile, redirect to profile setup
@@ -217,7 +172,7 @@ func signIn(c *gin.Context) {
 		"token": token,
 		"refresh_token": token, // For now, use same token as refresh token
 		"expires_in": 24 * 60 * 60, // 24 hours in seconds
-		"user": user,
+		"user": serializers.User(user),
 		"worker_profile": workerProfile,
 		"redirect_to": redirectTo,
 	})
@@ -228,20 +183,14 @@ func GetCurrentUser(c *gin.Context) {
 	// Get user from context (set by AuthMiddleware)
 	user, exists := c.Get("user")
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "User not authenticated",
-			"message": "Please log in to access your profile",
-		})
+		apierror.Abort(c, apierror.Unauthorized("Please log in to access your profile"))
 		return
 	}
 
 	// Cast user to models.User
 	userModel, ok := user.(models.User)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Invalid user data",
-			"message": "Failed to retrieve user information",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to retrieve user information", nil))
 		return
 	}
 
@@ -257,11 +206,7 @@ func refreshToken(c *gin.Context) {
 		RefreshToken string `json:"refresh_token" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request",
-			"message": err.Error(),
-		})
+	if !validation.BindJSON(c, &req) {
 		return
 	}
 
@@ -269,41 +214,34 @@ func refreshToken(c *gin.Context) {
 
 	// Validate refresh token (in production, this should be a separate refresh token)
 	// For now, we'll treat it as a regular token and validate it
-	userID, err := utils.ValidateToken(req.RefreshToken)
+	claims, err := utils.VerifyToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Invalid refresh token",
-			"message": "Refresh token is invalid or expired",
-		})
+		apierror.Abort(c, apierror.Unauthorized("Refresh token is invalid or expired"))
 		return
 	}
 
 	// Get user from database
 	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error":   "User not found",
-			"message": "User associated with refresh token not found",
-		})
+	if err := database.DB.First(&user, claims.UserID).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("User associated with refresh token not found"))
 		return
 	}
 
 	// Check if user is active
 	if !user.IsActive {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error":   "Account deactivated",
-			"message": "Your account has been deactivated",
-		})
+		apierror.Abort(c, apierror.Unauthorized("Your account has been deactivated"))
+		return
+	}
+
+	if middleware.SessionRevoked(claims, user) {
+		apierror.Abort(c, apierror.Unauthorized("Refresh token has been signed out"))
 		return
 	}
 
 	// Generate new token
-	newToken, err := utils.GenerateToken(user.ID, string(user.Role))
+	newToken, err := utils.GenerateToken(user.ID, string(user.Role), user.TokenVersion)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Token generation failed",
-			"message": "Failed to generate new authentication token",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to generate new authentication token", err))
 		return
 	}
 
@@ -314,7 +252,7 @@ func refreshToken(c *gin.Context) {
 		"token": newToken,
 		"refresh_token": newToken, // For now, use same token
 		"expires_in": 24 * 60 * 60, // 24 hours in seconds
-		"user": user,
+		"user": serializers.User(user),
 	})
 }
 
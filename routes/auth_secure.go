@@ -1,91 +1,92 @@
 package routes
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
+	"repair-service-server/i18n"
 	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/serializers"
 	"repair-service-server/services"
+	"repair-service-server/validation"
 )
 
 // RegisterSecureAuthRoutes registers secure authentication routes
 func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 	jwtService := services.NewJWTService()
+	otpService := services.NewOTPService()
+	loginGuard := services.NewLoginGuardService()
+
+	RegisterPhoneVerificationRoutes(router, otpService)
+	RegisterEmailRoutes(router, jwtService, loginGuard)
 
 	// Sign up endpoint
 	router.POST("/signup", func(c *gin.Context) {
 		var req struct {
 			FullName         string `json:"full_name" binding:"required,min=2,max=100"`
-			PhoneNumber      string `json:"phone_number" binding:"required"`
+			PhoneNumber      string `json:"phone_number" binding:"required,phone"`
 			Password         string `json:"password" binding:"required,min=8,max=128"`
 			ConfirmPassword  string `json:"confirm_password" binding:"required"`
 			Role             string `json:"role" binding:"omitempty,oneof=customer worker"`
+			OTPCode          string `json:"otp_code" binding:"omitempty,numeric,len=6"` // From POST /otp/send; may also be verified after signup
+			PreferredLanguage string `json:"preferred_language" binding:"omitempty,oneof=en fr ar"` // Defaults to the Accept-Language header
 		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request",
-				"message": err.Error(),
-			})
+		if !validation.BindJSON(c, &req) {
 			return
 		}
 
 		// Sanitize input
 		req.FullName = middleware.SanitizeInput(req.FullName)
-		req.PhoneNumber = strings.TrimSpace(req.PhoneNumber)
-
-		// Validate phone number
-		if !middleware.ValidatePhoneNumber(req.PhoneNumber) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid phone number",
-				"message": "Phone number must be in format +222XXXXXXXX",
-			})
-			return
-		}
+		req.PhoneNumber = validation.NormalizePhone(req.PhoneNumber)
 
 		// Validate password strength
 		isStrong, errors := middleware.ValidatePasswordStrength(req.Password)
 		if !isStrong {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Weak password",
-				"message": "Password does not meet security requirements",
-				"details": errors,
-			})
+			apierror.Abort(c, apierror.Validation("Password does not meet security requirements").WithDetails(errors))
 			return
 		}
 
 		// Check password confirmation
 		if req.Password != req.ConfirmPassword {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Password mismatch",
-				"message": "Passwords do not match",
-			})
+			apierror.Abort(c, apierror.Validation("Passwords do not match"))
 			return
 		}
 
 		// Check if user already exists
 		var existingUser models.User
 		if err := database.DB.Where("phone_number = ?", req.PhoneNumber).First(&existingUser).Error; err == nil {
-			c.JSON(http.StatusConflict, gin.H{
-				"error":   "User already exists",
-				"message": "An account with this phone number already exists",
-			})
+			apierror.Abort(c, apierror.Conflict("An account with this phone number already exists"))
 			return
 		}
 
+		// A code sent to the number before signup verifies it right away
+		var phoneVerifiedAt *time.Time
+		if req.OTPCode != "" {
+			if err := otpService.VerifyCode(req.PhoneNumber, models.PhoneVerificationSignup, req.OTPCode); err != nil {
+				abortOTPError(c, err, "Failed to verify phone number")
+				return
+			}
+			now := time.Now()
+			phoneVerifiedAt = &now
+		}
+
 		// Hash password
 		hashedPassword, err := jwtService.HashPassword(req.Password)
 		if err != nil {
 			log.Printf("❌ Password hashing failed: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal server error",
-				"message": "Failed to process password",
-			})
+			apierror.Abort(c, apierror.Internal("Failed to process password", nil))
 			return
 		}
 
@@ -95,6 +96,10 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 			userRole = models.RoleWorker
 		}
 
+		if req.PreferredLanguage == "" {
+			req.PreferredLanguage = i18n.Negotiate(c.GetHeader("Accept-Language"))
+		}
+
 		// Create user
 		user := models.User{
 			FullName:     req.FullName,
@@ -102,14 +107,13 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 			PasswordHash: hashedPassword,
 			Role:         userRole,
 			IsActive:     true,
+			PhoneVerifiedAt: phoneVerifiedAt,
+			PreferredLanguage: req.PreferredLanguage,
 		}
 
 		if err := database.DB.Create(&user).Error; err != nil {
 			log.Printf("❌ User creation failed: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal server error",
-				"message": "Failed to create account",
-			})
+			apierror.Abort(c, apierror.Internal("Failed to create account", nil))
 			return
 		}
 
@@ -123,10 +127,7 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 		tokenPair, err := jwtService.GenerateTokenPair(user.ID, deviceID, userAgent, ipAddress)
 		if err != nil {
 			log.Printf("❌ Token generation failed: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal server error",
-				"message": "Failed to generate authentication tokens",
-			})
+			apierror.Abort(c, apierror.Internal("Failed to generate authentication tokens", nil))
 			return
 		}
 
@@ -142,6 +143,8 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 					"phone_number": user.PhoneNumber,
 					"role":         user.Role,
 					"is_active":    user.IsActive,
+					"phone_verified_at": user.PhoneVerifiedAt,
+					"preferred_language": user.PreferredLanguage,
 					"created_at":   user.CreatedAt,
 				},
 				"tokens": tokenPair,
@@ -152,27 +155,21 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 	// Sign in endpoint
 	router.POST("/signin", func(c *gin.Context) {
 		var req struct {
-			PhoneNumber string `json:"phone_number" binding:"required"`
+			PhoneNumber string `json:"phone_number" binding:"required,phone"`
 			Password    string `json:"password" binding:"required"`
 		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request",
-				"message": err.Error(),
-			})
+		if !validation.BindJSON(c, &req) {
 			return
 		}
 
 		// Sanitize input
-		req.PhoneNumber = strings.TrimSpace(req.PhoneNumber)
+		req.PhoneNumber = validation.NormalizePhone(req.PhoneNumber)
 
-		// Validate phone number
-		if !middleware.ValidatePhoneNumber(req.PhoneNumber) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid phone number",
-				"message": "Phone number must be in format +222XXXXXXXX",
-			})
+		// Refuse early while the number or IP is backing off from failed attempts
+		ipAddress := c.ClientIP()
+		if err := loginGuard.Check(req.PhoneNumber, ipAddress); err != nil {
+			abortLoginThrottled(c, err)
 			return
 		}
 
@@ -180,49 +177,45 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 		var user models.User
 		if err := database.DB.Where("phone_number = ?", req.PhoneNumber).First(&user).Error; err != nil {
 			log.Printf("❌ User not found: %s", req.PhoneNumber)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid credentials",
-				"message": "Phone number or password is incorrect",
-			})
+			loginGuard.RecordFailure(req.PhoneNumber, ipAddress, nil)
+			apierror.Abort(c, apierror.Unauthorized("Phone number or password is incorrect"))
 			return
 		}
 
 		// Check if user is active
 		if !user.IsActive {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Account deactivated",
-				"message": "Your account has been deactivated",
-			})
+			apierror.Abort(c, apierror.Unauthorized("Your account has been deactivated"))
 			return
 		}
 
 		// Verify password
 		if !jwtService.CheckPasswordHash(req.Password, user.PasswordHash) {
 			log.Printf("❌ Invalid password for user: %d", user.ID)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid credentials",
-				"message": "Phone number or password is incorrect",
-			})
+			loginGuard.RecordFailure(req.PhoneNumber, ipAddress, &user)
+			apierror.Abort(c, apierror.Unauthorized("Phone number or password is incorrect"))
 			return
 		}
+		loginGuard.RecordSuccess(req.PhoneNumber)
+
+		// Generate new tokens
+		deviceID := c.GetHeader("X-Device-ID")
 
-		// Revoke all existing tokens for security
-		if err := jwtService.RevokeAllUserTokens(user.ID); err != nil {
+		// Replace this device's previous session; without a device ID every session is revoked
+		// for security, since the old ones cannot be told apart from this one
+		if deviceID != "" {
+			if err := jwtService.RevokeDeviceTokens(user.ID, deviceID); err != nil {
+				log.Printf("⚠️ Failed to revoke existing tokens for user %d on device %s: %v", user.ID, deviceID, err)
+			}
+		} else if err := jwtService.RevokeAllUserTokens(user.ID); err != nil {
 			log.Printf("⚠️ Failed to revoke existing tokens for user %d: %v", user.ID, err)
 		}
 
-		// Generate new tokens
-		deviceID := c.GetHeader("X-Device-ID")
 		userAgent := c.GetHeader("User-Agent")
-		ipAddress := c.ClientIP()
 
 		tokenPair, err := jwtService.GenerateTokenPair(user.ID, deviceID, userAgent, ipAddress)
 		if err != nil {
 			log.Printf("❌ Token generation failed: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal server error",
-				"message": "Failed to generate authentication tokens",
-			})
+			apierror.Abort(c, apierror.Internal("Failed to generate authentication tokens", nil))
 			return
 		}
 
@@ -238,6 +231,8 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 					"phone_number": user.PhoneNumber,
 					"role":         user.Role,
 					"is_active":    user.IsActive,
+					"phone_verified_at": user.PhoneVerifiedAt,
+					"preferred_language": user.PreferredLanguage,
 					"created_at":   user.CreatedAt,
 				},
 				"tokens": tokenPair,
@@ -251,11 +246,7 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 			RefreshToken string `json:"refresh_token" binding:"required"`
 		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request",
-				"message": err.Error(),
-			})
+		if !validation.BindJSON(c, &req) {
 			return
 		}
 
@@ -263,10 +254,7 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 		tokenPair, err := jwtService.RefreshAccessToken(req.RefreshToken)
 		if err != nil {
 			log.Printf("❌ Token refresh failed: %v", err)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid refresh token",
-				"message": "Refresh token is invalid or expired",
-			})
+			apierror.Abort(c, apierror.Unauthorized("Refresh token is invalid or expired"))
 			return
 		}
 
@@ -316,10 +304,7 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 		
 		var user models.User
 		if err := database.DB.First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "User not found",
-				"message": "User not found",
-			})
+			apierror.Abort(c, apierror.NotFound("User not found"))
 			return
 		}
 
@@ -332,6 +317,10 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 					"phone_number": user.PhoneNumber,
 					"role":         user.Role,
 					"is_active":    user.IsActive,
+					"phone_verified_at": user.PhoneVerifiedAt,
+					"deletion_scheduled_for": user.DeletionScheduledFor,
+					"preferred_language": user.PreferredLanguage,
+					"emergency_contact": serializers.EmergencyContactOf(user),
 					"created_at":   user.CreatedAt,
 					"updated_at":   user.UpdatedAt,
 				},
@@ -339,6 +328,64 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 		})
 	})
 
+	// Update account preferences: the language of notifications, emails, error messages and catalog
+	// content; an empty language follows Accept-Language
+	router.PUT("/preferences", middleware.AuthMiddleware(), func(c *gin.Context) {
+		user := c.MustGet("user").(models.User)
+
+		var req struct {
+			PreferredLanguage string `json:"preferred_language" binding:"omitempty,oneof=en fr ar"`
+		}
+		if !validation.BindJSON(c, &req) {
+			return
+		}
+
+		if err := database.DB.Model(&user).Update("preferred_language", req.PreferredLanguage).Error; err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to update language", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Preferences updated",
+			"data":    gin.H{"user": serializers.User(user)},
+		})
+	})
+
+	// Set the emergency contact texted when the user raises an SOS during a job; an empty phone
+	// number removes it
+	router.PUT("/emergency-contact", middleware.AuthMiddleware(), func(c *gin.Context) {
+		user := c.MustGet("user").(models.User)
+
+		var req struct {
+			Name        string `json:"name" binding:"required_with=PhoneNumber,max=255"`
+			PhoneNumber string `json:"phone_number" binding:"omitempty,phone"`
+		}
+		if !validation.BindJSON(c, &req) {
+			return
+		}
+
+		var name, phone *string
+		if req.PhoneNumber != "" {
+			normalized := validation.NormalizePhone(req.PhoneNumber)
+			name, phone = &req.Name, &normalized
+		}
+		if err := database.DB.Model(&user).Updates(map[string]interface{}{
+			"emergency_contact_name":  name,
+			"emergency_contact_phone": phone,
+		}).Error; err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to update emergency contact", err))
+			return
+		}
+		user.EmergencyContactName, user.EmergencyContactPhone = name, phone
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Emergency contact updated",
+			"data":    gin.H{"user": serializers.User(user)},
+		})
+	})
+
 	// Change password endpoint
 	router.POST("/change-password", middleware.AuthMiddleware(), func(c *gin.Context) {
 		userID := c.GetUint("user_id")
@@ -348,41 +395,27 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 			NewPassword     string `json:"new_password" binding:"required,min=8,max=128"`
 		}
 
-		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid request",
-				"message": err.Error(),
-			})
+		if !validation.BindJSON(c, &req) {
 			return
 		}
 
 		// Get user
 		var user models.User
 		if err := database.DB.First(&user, userID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "User not found",
-				"message": "User not found",
-			})
+			apierror.Abort(c, apierror.NotFound("User not found"))
 			return
 		}
 
 		// Verify current password
 		if !jwtService.CheckPasswordHash(req.CurrentPassword, user.PasswordHash) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error":   "Invalid current password",
-				"message": "Current password is incorrect",
-			})
+			apierror.Abort(c, apierror.Unauthorized("Current password is incorrect"))
 			return
 		}
 
 		// Validate new password strength
 		isStrong, errors := middleware.ValidatePasswordStrength(req.NewPassword)
 		if !isStrong {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Weak password",
-				"message": "New password does not meet security requirements",
-				"details": errors,
-			})
+			apierror.Abort(c, apierror.Validation("New password does not meet security requirements").WithDetails(errors))
 			return
 		}
 
@@ -390,10 +423,7 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 		hashedPassword, err := jwtService.HashPassword(req.NewPassword)
 		if err != nil {
 			log.Printf("❌ Password hashing failed: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal server error",
-				"message": "Failed to process new password",
-			})
+			apierror.Abort(c, apierror.Internal("Failed to process new password", nil))
 			return
 		}
 
@@ -401,10 +431,7 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 		user.PasswordHash = hashedPassword
 		if err := database.DB.Save(&user).Error; err != nil {
 			log.Printf("❌ Password update failed: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Internal server error",
-				"message": "Failed to update password",
-			})
+			apierror.Abort(c, apierror.Internal("Failed to update password", nil))
 			return
 		}
 
@@ -420,4 +447,161 @@ func RegisterSecureAuthRoutes(router *gin.RouterGroup) {
 			"message": "Password changed successfully. Please sign in again.",
 		})
 	})
+
+	sessionService := services.NewSessionService()
+
+	// List the devices the user is signed in on
+	router.GET("/sessions", middleware.AuthMiddleware(), func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+
+		sessions, err := sessionService.ActiveSessions(userID)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to load sessions", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    serializers.Sessions(sessions, c.GetHeader("X-Device-ID")),
+		})
+	})
+
+	// Sign out on every device, including this one once its access token expires
+	router.DELETE("/sessions", middleware.AuthMiddleware(), func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+
+		revoked, err := sessionService.RevokeAll(userID)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to sign out of all sessions", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Signed out of all devices",
+			"data":    gin.H{"revoked": revoked},
+		})
+	})
+
+	// Sign out one device
+	router.DELETE("/sessions/:id", middleware.AuthMiddleware(), func(c *gin.Context) {
+		userID := c.GetUint("user_id")
+
+		sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation("Invalid session ID"))
+			return
+		}
+
+		if _, err := sessionService.Revoke(userID, uint(sessionID)); err != nil {
+			if errors.Is(err, services.ErrSessionNotFound) {
+				apierror.Abort(c, apierror.NotFound("Session not found"))
+				return
+			}
+			apierror.Abort(c, apierror.Internal("Failed to revoke session", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Session revoked",
+		})
+	})
+
+	accountService := services.NewAccountService()
+
+	// Request account deletion; personal data is anonymized once the grace period ends
+	router.POST("/delete-account", middleware.AuthMiddleware(), func(c *gin.Context) {
+		user := c.MustGet("user").(models.User)
+
+		var req struct {
+			Password string `json:"password" binding:"required"`
+			Reason   string `json:"reason" binding:"max=500"`
+		}
+		if !validation.BindJSON(c, &req) {
+			return
+		}
+
+		if !jwtService.CheckPasswordHash(req.Password, user.PasswordHash) {
+			apierror.Abort(c, apierror.Unauthorized("Password is incorrect"))
+			return
+		}
+
+		if err := accountService.RequestDeletion(&user); err != nil {
+			if errors.Is(err, services.ErrDeletionAlreadyScheduled) {
+				apierror.Abort(c, apierror.Conflict("Account deletion is already scheduled").WithDetails(gin.H{"deletion_scheduled_for": user.DeletionScheduledFor}))
+				return
+			}
+			apierror.Abort(c, apierror.Internal("Failed to schedule account deletion", err))
+			return
+		}
+
+		log.Printf("🗑️ User %d requested account deletion, scheduled for %s (reason: %q)", user.ID, user.DeletionScheduledFor.Format(time.RFC3339), req.Reason)
+
+		c.JSON(http.StatusAccepted, gin.H{
+			"success": true,
+			"message": "Account deletion scheduled. Sign in and cancel before the scheduled date to keep your account.",
+			"data": gin.H{
+				"deletion_requested_at":  user.DeletionRequestedAt,
+				"deletion_scheduled_for": user.DeletionScheduledFor,
+				"grace_period_days":      services.AccountDeletionGraceDays(),
+			},
+		})
+	})
+
+	// Cancel a pending account deletion
+	router.POST("/delete-account/cancel", middleware.AuthMiddleware(), func(c *gin.Context) {
+		user := c.MustGet("user").(models.User)
+
+		if err := accountService.CancelDeletion(&user); err != nil {
+			if errors.Is(err, services.ErrNoDeletionScheduled) {
+				apierror.Abort(c, apierror.Conflict("No account deletion is scheduled"))
+				return
+			}
+			apierror.Abort(c, apierror.Internal("Failed to cancel account deletion", err))
+			return
+		}
+
+		log.Printf("✅ User %d cancelled account deletion", user.ID)
+
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Account deletion cancelled",
+			"data":    gin.H{"user": serializers.User(user)},
+		})
+	})
+
+	// Download everything stored about the current user as a JSON file
+	router.GET("/export", middleware.AuthMiddleware(), func(c *gin.Context) {
+		user := c.MustGet("user").(models.User)
+
+		export, err := accountService.Export(user)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to export account data", err))
+			return
+		}
+
+		filename := fmt.Sprintf("account-export-%d-%s.json", user.ID, export.ExportedAt.Format("2006-01-02"))
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Header("Cache-Control", "no-store")
+		c.IndentedJSON(http.StatusOK, export)
+	})
+}
+
+// abortLoginThrottled answers a sign-in refused by the login guard with 429 and Retry-After
+func abortLoginThrottled(c *gin.Context, err error) {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		apierror.Abort(c, apierror.Internal("Failed to sign in", err))
+		return
+	}
+
+	retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	details := gin.H{"retry_after": retryAfter}
+	if throttled.Locked {
+		apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeAccountLocked, "Too many failed sign-in attempts. Please try again later.").WithDetails(details))
+		return
+	}
+	apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "Please wait before trying to sign in again.").WithDetails(details))
 }
@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/serializers"
+	"repair-service-server/validation"
+)
+
+// MaxBatchRequests is how many reads one batch may combine
+const MaxBatchRequests = 10
+
+const batchPath = "/api/v1/batch"
+
+// batchReadTimeout bounds each read, so one slow endpoint cannot hold the whole batch open
+var batchReadTimeout = 10 * time.Second
+
+// batchStreamingSuffixes end the paths of endpoints that keep the connection open to stream
+// events, which a batch could never wait for
+var batchStreamingSuffixes = []string{"/events", "/live", "/ws"}
+
+// BatchRead is one GET in a batch. Fields, when given, trims the response to those dot-separated
+// paths; a path through an array applies to each of its elements.
+type BatchRead struct {
+	ID     string   `json:"id" binding:"required,max=50"`
+	Path   string   `json:"path" binding:"required,max=500"`
+	Fields []string `json:"fields" binding:"max=50,dive,max=100"`
+}
+
+// BatchRequest is the body of POST /batch
+type BatchRequest struct {
+	Requests []BatchRead `json:"requests" binding:"required,min=1,dive"`
+}
+
+// BatchResponse is the outcome of one read, in the order it was requested
+type BatchResponse struct {
+	ID     string          `json:"id"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// BatchHandler answers several read-only API calls in one round trip by running each through the
+// router with the caller's own headers, so authentication, permissions and rate limits apply to
+// every read as if it had been sent separately
+type BatchHandler struct {
+	router http.Handler
+}
+
+// NewBatchHandler creates a batch handler dispatching to router
+func NewBatchHandler(router http.Handler) *BatchHandler {
+	return &BatchHandler{router: router}
+}
+
+// Batch runs the requested reads concurrently
+func (h *BatchHandler) Batch(c *gin.Context) {
+	var req BatchRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	if len(req.Requests) > MaxBatchRequests {
+		validation.Fail(c, "requests", "max", "10")
+		return
+	}
+	seen := map[string]bool{}
+	for _, read := range req.Requests {
+		if seen[read.ID] {
+			apierror.Abort(c, apierror.Validation("Batch request ids must be unique"))
+			return
+		}
+		seen[read.ID] = true
+		if !strings.HasPrefix(read.Path, "/api/v1/") || strings.HasPrefix(read.Path, batchPath) {
+			apierror.Abort(c, apierror.Validation("Batch paths must be API paths under /api/v1/ other than the batch endpoint itself"))
+			return
+		}
+		if isStreamingPath(read.Path) {
+			apierror.Abort(c, apierror.Validation("Event streams and WebSocket endpoints cannot be batched"))
+			return
+		}
+	}
+
+	responses := make([]BatchResponse, len(req.Requests))
+	var wg sync.WaitGroup
+	for i, read := range req.Requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = h.read(c, read)
+		}()
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, gin.H{"responses": responses})
+}
+
+// isStreamingPath reports whether a batch path leads to an endpoint that streams, judged on the
+// path the router will see once it is decoded and cleaned
+func isStreamingPath(rawPath string) bool {
+	u, err := url.ParseRequestURI(rawPath)
+	if err != nil {
+		return false
+	}
+	cleaned := path.Clean(u.Path)
+	if strings.HasPrefix(cleaned, "/api/v1/ws/") {
+		return true
+	}
+	for _, suffix := range batchStreamingSuffixes {
+		if strings.HasSuffix(cleaned, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// read runs one GET through the router with the caller's headers
+func (h *BatchHandler) read(c *gin.Context, read BatchRead) BatchResponse {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), batchReadTimeout)
+	defer cancel()
+	sub, err := http.NewRequestWithContext(ctx, http.MethodGet, read.Path, http.NoBody)
+	if err != nil {
+		return batchError(read.ID, apierror.Validation("Invalid batch path"))
+	}
+	for name, values := range c.Request.Header {
+		switch http.CanonicalHeaderKey(name) {
+		case "Content-Length", "Content-Type", "Idempotency-Key", "Accept-Encoding":
+			// Each body is embedded in the batch response, which is compressed as a whole
+			continue
+		}
+		sub.Header[name] = values
+	}
+	sub.RemoteAddr = c.Request.RemoteAddr
+
+	recorder := httptest.NewRecorder()
+	h.router.ServeHTTP(recorder, sub)
+	if ctx.Err() == context.DeadlineExceeded {
+		return batchError(read.ID, apierror.New(http.StatusGatewayTimeout, apierror.CodeUnavailable, "The read took too long to be batched"))
+	}
+
+	body := bytes.TrimSpace(recorder.Body.Bytes())
+	if len(body) == 0 {
+		body = []byte("null")
+	}
+	if !json.Valid(body) {
+		if recorder.Code == http.StatusNotFound {
+			// Unknown routes answer with plain text
+			return batchError(read.ID, apierror.NotFound("No API endpoint at "+sub.URL.Path))
+		}
+		return batchError(read.ID, apierror.Unprocessable("Only JSON responses can be batched"))
+	}
+	if len(read.Fields) > 0 && recorder.Code < http.StatusBadRequest {
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err == nil {
+			if trimmed, err := json.Marshal(serializers.Select(value, serializers.NewFieldset(read.Fields))); err == nil {
+				body = trimmed
+			}
+		}
+	}
+	return BatchResponse{ID: read.ID, Status: recorder.Code, Body: body}
+}
+
+// batchError reports a read that could not be run in the error envelope it would have had
+func batchError(id string, apiErr *apierror.Error) BatchResponse {
+	body, _ := json.Marshal(gin.H{
+		"success": false,
+		"code":    apiErr.Code,
+		"message": apiErr.Message,
+		"error":   apiErr.Message,
+	})
+	return BatchResponse{ID: id, Status: apiErr.Status, Body: body}
+}
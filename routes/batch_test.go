@@ -0,0 +1,112 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newBatchTestRouter serves a JSON read, an endpoint that streams until the client leaves and
+// the batch endpoint over them
+func newBatchTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/profile", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"name": "Aminetou", "city": "Nouakchott"})
+	})
+	hold := func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Status(http.StatusOK)
+		<-c.Request.Context().Done()
+	}
+	router.GET("/api/v1/chat/events", hold)
+	router.GET("/api/v1/slow", hold)
+	router.POST("/api/v1/batch", NewBatchHandler(router).Batch)
+	return router
+}
+
+// postBatch sends a batch and fails the test if it is not answered within a few seconds
+func postBatch(t *testing.T, router http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(recorder, req)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch did not return")
+	}
+	return recorder
+}
+
+func TestIsStreamingPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/api/v1/chat/events", true},
+		{"/api/v1/service-requests/7/events?after=3", true},
+		{"/api/v1/service-requests/7/live", true},
+		{"/api/v1/ws/worker", true},
+		{"/api/v1/admin/ops/ws", true},
+		{"/api/v1/chat/%65vents", true},
+		{"/api/v1/chat/./events", true},
+		{"/api/v1/service-requests/7", false},
+		{"/api/v1/service-requests/my-requests?status=completed", false},
+		{"/api/v1/liveness", false},
+	}
+	for _, tt := range tests {
+		if got := isStreamingPath(tt.path); got != tt.want {
+			t.Errorf("isStreamingPath(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBatchRejectsStreamingPaths(t *testing.T) {
+	router := newBatchTestRouter()
+	for _, path := range []string{"/api/v1/chat/events", "/api/v1/chat/%65vents", "/api/v1/service-requests/7/live"} {
+		body := `{"requests": [{"id": "profile", "path": "/api/v1/profile"}, {"id": "events", "path": "` + path + `"}]}`
+		recorder := postBatch(t, router, body)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("batch with %s: status %d, want %d", path, recorder.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestBatchTimesOutSlowReads(t *testing.T) {
+	previous := batchReadTimeout
+	batchReadTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { batchReadTimeout = previous })
+
+	router := newBatchTestRouter()
+	recorder := postBatch(t, router, `{"requests": [{"id": "slow", "path": "/api/v1/slow"}, {"id": "profile", "path": "/api/v1/profile", "fields": ["name"]}]}`)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d: %s", recorder.Code, http.StatusOK, recorder.Body)
+	}
+
+	var body struct {
+		Responses []BatchResponse `json:"responses"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Responses) != 2 {
+		t.Fatalf("got %d responses, want 2", len(body.Responses))
+	}
+	if slow := body.Responses[0]; slow.ID != "slow" || slow.Status != http.StatusGatewayTimeout {
+		t.Errorf("slow read: id %q status %d, want %q %d", slow.ID, slow.Status, "slow", http.StatusGatewayTimeout)
+	}
+	if profile := body.Responses[1]; profile.Status != http.StatusOK || string(profile.Body) != `{"name":"Aminetou"}` {
+		t.Errorf("profile read: status %d body %s", profile.Status, profile.Body)
+	}
+}
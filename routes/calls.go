@@ -0,0 +1,82 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/services"
+)
+
+// callOtherParty returns the proxy number the customer or the assigned worker dials to reach the
+// other, so neither has to share their own number
+func callOtherParty(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	role := models.RoleCustomer
+	if serviceRequest.CustomerID != user.ID {
+		workerProfile, ok := currentWorkerProfile(c)
+		if !ok {
+			return
+		}
+		if serviceRequest.AssignedWorkerID == nil || *serviceRequest.AssignedWorkerID != workerProfile.ID {
+			apierror.Abort(c, apierror.NotFound("Service request not found"))
+			return
+		}
+		role = models.RoleWorker
+	}
+
+	session, proxyNumber, err := services.NewCallMaskingService().ProxyNumber(serviceRequest.ID, role)
+	switch {
+	case errors.Is(err, services.ErrCallNotAvailable):
+		apierror.Abort(c, apierror.Unprocessable(err.Error()))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to set up the call", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"proxy_number": proxyNumber,
+			"expires_at":   session.ExpiresAt,
+		},
+	})
+}
+
+// CallWebhook records call events the call masking provider reports
+func CallWebhook(c *gin.Context) {
+	err := services.NewCallMaskingService().RecordWebhook(c.Request)
+	if errors.Is(err, services.ErrInvalidCallWebhook) {
+		apierror.Abort(c, apierror.Forbidden(err.Error()))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to record call event", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ProcessFinishedCallSessions closes call sessions whose job is over so their proxy numbers are
+// recycled; called periodically by the call session job
+func ProcessFinishedCallSessions() {
+	closed, err := services.NewCallMaskingService().CloseFinished(time.Now())
+	if err != nil {
+		log.Printf("❌ Failed to close finished call sessions: %v", err)
+	} else if closed > 0 {
+		log.Printf("📞 Closed %d finished call sessions", closed)
+	}
+}
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"fmt"
+	"time"
+
+	"repair-service-server/cache"
+	"repair-service-server/config"
+)
+
+// Cache key prefixes for the public catalog. Services and options embed their category,
+// so category changes invalidate all three.
+const (
+	categoryCacheKey      = "catalog:categories:"
+	serviceCacheKey       = "catalog:services:"
+	serviceOptionCacheKey = "catalog:service_options:"
+)
+
+// catalogCacheTTL returns how long catalog reads are cached (CATALOG_CACHE_TTL_SECONDS, default 300)
+func catalogCacheTTL() time.Duration {
+	return config.Current().CatalogTTL
+}
+
+// catalogKey builds a cache key under prefix
+func catalogKey(prefix string, parts ...interface{}) string {
+	key := prefix
+	for _, part := range parts {
+		key += fmt.Sprint(part) + ":"
+	}
+	return key
+}
+
+// invalidateCategoryCache drops cached categories and everything that embeds them
+func invalidateCategoryCache() {
+	cache.Invalidate(categoryCacheKey, serviceCacheKey, serviceOptionCacheKey)
+}
+
+// invalidateServiceCache drops cached service listings
+func invalidateServiceCache() {
+	cache.Invalidate(serviceCacheKey)
+}
+
+// invalidateServiceOptionCache drops cached service option listings
+func invalidateServiceOptionCache() {
+	cache.Invalidate(serviceOptionCacheKey)
+}
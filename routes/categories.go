@@ -1,68 +1,109 @@
 package routes
 
 import (
+	"errors"
 	"log"
 	"net/http"
 
+	"repair-service-server/apierror"
+	"repair-service-server/cache"
 	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // RegisterCategoryRoutes registers category-related routes
 func RegisterCategoryRoutes(router *gin.RouterGroup) {
 	categories := router.Group("/categories")
 	{
-		categories.GET("", GetServiceCategories)
+		categories.GET("", middleware.ConditionalGET(), GetServiceCategories)
 	}
 }
 
-// GetServiceCategories returns all active service categories
+// GetServiceCategories returns the active top-level categories with their active subcategories, in
+// the request's language
 func GetServiceCategories(c *gin.Context) {
 	db := database.GetDB()
 
-	var categories []models.ServiceCategory
-	if err := db.Where("is_active = ?", true).Order("sort_order ASC").Find(&categories).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch service categories",
-			"error":   err.Error(),
-		})
+	categories, err := cache.GetOrLoad(catalogKey(categoryCacheKey, "active"), catalogCacheTTL(), func() ([]models.ServiceCategory, error) {
+		var categories []models.ServiceCategory
+		err := db.Where("is_active = ? AND parent_id IS NULL", true).
+			Preload("Subcategories", func(db *gorm.DB) *gorm.DB {
+				return db.Where("is_active = ?", true).Order("sort_order ASC")
+			}).
+			Order("sort_order ASC").Find(&categories).Error
+		return categories, err
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch service categories", err))
 		return
 	}
 
+	lang := i18n.Locale(c)
+	localized := make([]models.ServiceCategory, len(categories))
+	for i, category := range categories {
+		localized[i] = category.Localized(lang)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success":    true,
-		"categories": categories,
+		"language":   lang,
+		"dir":        i18n.Dir(lang),
+		"categories": localized,
 	})
 }
 
+// categoryRequest is the body of the admin category endpoints; name and description are in French
+type categoryRequest struct {
+	Name          string `json:"name" binding:"required"`
+	Description   string `json:"description"`
+	ParentID      *uint  `json:"parent_id"` // Makes this a subcategory of a top-level category
+	NameEn        string `json:"name_en" binding:"max=100"`
+	NameAr        string `json:"name_ar" binding:"max=100"`
+	DescriptionEn string `json:"description_en"`
+	DescriptionAr string `json:"description_ar"`
+	// UrgentDisabled stops customers asking for urgent service in the category
+	UrgentDisabled bool `json:"urgent_disabled"`
+}
+
 // CreateCategory creates a new service category
 func CreateCategory(c *gin.Context) {
-	var req struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-	}
+	var req categoryRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
+		return
+	}
+
+	if !validateCategoryParent(c, 0, req.ParentID) {
 		return
 	}
 
 	category := models.ServiceCategory{
-		Name:        req.Name,
-		Description: req.Description,
-		IsActive:    true,
-		SortOrder:   0,
+		ParentID:       req.ParentID,
+		Name:           req.Name,
+		Description:    req.Description,
+		IsActive:       true,
+		SortOrder:      0,
+		NameEn:         req.NameEn,
+		NameAr:         req.NameAr,
+		DescriptionEn:  req.DescriptionEn,
+		DescriptionAr:  req.DescriptionAr,
+		UrgentDisabled: req.UrgentDisabled,
 	}
 
 	if err := database.DB.Create(&category).Error; err != nil {
 		log.Printf("❌ Failed to create category: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
+		apierror.Abort(c, apierror.Internal("Failed to create category", nil))
 		return
 	}
 
+	invalidateCategoryCache()
 	log.Printf("✅ Category created: %s (ID: %d)", category.Name, category.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -76,31 +117,39 @@ func CreateCategory(c *gin.Context) {
 func UpdateCategory(c *gin.Context) {
 	categoryID := c.Param("id")
 	
-	var req struct {
-		Name        string `json:"name" binding:"required"`
-		Description string `json:"description"`
-	}
+	var req categoryRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		apierror.Abort(c, apierror.Validation("Invalid request format"))
 		return
 	}
 
 	var category models.ServiceCategory
 	if err := database.DB.First(&category, categoryID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		apierror.Abort(c, apierror.NotFound("Category not found"))
 		return
 	}
 
+	if !validateCategoryParent(c, category.ID, req.ParentID) {
+		return
+	}
+
+	category.ParentID = req.ParentID
 	category.Name = req.Name
 	category.Description = req.Description
+	category.NameEn = req.NameEn
+	category.NameAr = req.NameAr
+	category.DescriptionEn = req.DescriptionEn
+	category.DescriptionAr = req.DescriptionAr
+	category.UrgentDisabled = req.UrgentDisabled
 
 	if err := database.DB.Save(&category).Error; err != nil {
 		log.Printf("❌ Failed to update category: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+		apierror.Abort(c, apierror.Internal("Failed to update category", nil))
 		return
 	}
 
+	invalidateCategoryCache()
 	log.Printf("✅ Category updated: %s (ID: %d)", category.Name, category.ID)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -110,22 +159,50 @@ func UpdateCategory(c *gin.Context) {
 	})
 }
 
+// validateCategoryParent checks the parent of a new (categoryID 0) or updated category; it aborts
+// and returns false when the parent is unknown or would nest categories more than two levels deep
+func validateCategoryParent(c *gin.Context, categoryID uint, parentID *uint) bool {
+	err := services.NewCategoryService().ValidateParent(categoryID, parentID)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrUnknownCategory):
+		apierror.Abort(c, apierror.Validation("Parent category not found"))
+	case errors.Is(err, services.ErrCategoryDepth):
+		apierror.Abort(c, apierror.Validation("Subcategories must have a top-level parent and cannot have subcategories of their own"))
+	default:
+		apierror.Abort(c, apierror.Internal("Failed to check parent category", err))
+	}
+	return false
+}
+
 // DeleteCategory deletes a service category
 func DeleteCategory(c *gin.Context) {
 	categoryID := c.Param("id")
 
 	var category models.ServiceCategory
 	if err := database.DB.First(&category, categoryID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		apierror.Abort(c, apierror.NotFound("Category not found"))
+		return
+	}
+
+	var subcategories int64
+	if err := database.DB.Model(&models.ServiceCategory{}).Where("parent_id = ?", category.ID).Count(&subcategories).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to check subcategories", err))
+		return
+	}
+	if subcategories > 0 {
+		apierror.Abort(c, apierror.Conflict("Delete or move the category's subcategories first"))
 		return
 	}
 
 	if err := database.DB.Delete(&category).Error; err != nil {
 		log.Printf("❌ Failed to delete category: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+		apierror.Abort(c, apierror.Internal("Failed to delete category", nil))
 		return
 	}
 
+	invalidateCategoryCache()
 	log.Printf("✅ Category deleted: %s (ID: %d)", category.Name, category.ID)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -1,25 +1,25 @@
 package routes
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"log"
-	"mime/multipart"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/repository"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
 	ws "repair-service-server/websocket"
-
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 )
 
 var chatHub *ws.Hub
@@ -37,27 +37,57 @@ func GetChatHub() *ws.Hub {
 	return chatHub
 }
 
+// ChatHandler serves chat room and message endpoints from injected repositories
+type ChatHandler struct {
+	chats    repository.ChatRepo
+	requests repository.RequestRepo
+	users    repository.UserRepo
+	hub      *ws.Hub
+}
+
+// NewChatHandler creates a chat handler backed by the given repositories
+func NewChatHandler(chats repository.ChatRepo, requests repository.RequestRepo, users repository.UserRepo, hub *ws.Hub) *ChatHandler {
+	return &ChatHandler{chats: chats, requests: requests, users: users, hub: hub}
+}
+
 // ChatRoutes sets up chat-related routes
-func ChatRoutes(router *gin.Engine, hub *ws.Hub) {
+func ChatRoutes(router *gin.Engine, hub *ws.Hub, repos *repository.Repositories) {
 	// Set the local chatHub variable to use the passed hub
 	chatHub = hub
+	h := NewChatHandler(repos.Chats, repos.Requests, repos.Users, hub)
+	
+	// Receipts sent over the WebSocket are recorded before they reach the other participant
+	hub.MessageHandlers["delivered"] = h.handleReceiptAck(models.MessageStatusDelivered)
+	hub.MessageHandlers["read"] = h.handleReceiptAck(models.MessageStatusRead)
+	hub.MessageHandlers["location_update"] = h.handleLocationUpdate
 	
 	chat := router.Group("/api/v1/chat")
 	{
 		// WebSocket connection - use WebSocket-specific auth middleware
-		chat.GET("/ws", middleware.WebSocketAuthMiddleware(), handleWebSocketConnection)
+		chat.GET("/ws", middleware.WebSocketAuthMiddleware(), h.handleWebSocketConnection)
+		// Server-Sent Events fallback for clients that cannot use WebSocket
+		chat.GET("/events", middleware.WebSocketAuthMiddleware(), h.handleEventStream)
 		
 		// Chat room management
-		chat.GET("/rooms", middleware.AuthMiddleware(), getChatRooms)
-		chat.POST("/rooms", middleware.AuthMiddleware(), createChatRoom)
+		chat.GET("/rooms", middleware.AuthMiddleware(), h.getChatRooms)
+		chat.POST("/rooms", middleware.AuthMiddleware(), h.createChatRoom)
 		chat.POST("/rooms/get-or-create", middleware.AuthMiddleware(), getOrCreateChatRoom)
-		chat.GET("/rooms/:id", middleware.AuthMiddleware(), getChatRoom)
+		chat.GET("/rooms/:id", middleware.AuthMiddleware(), h.getChatRoom)
 		
 		// Message management
-		chat.GET("/rooms/:id/messages", middleware.AuthMiddleware(), getChatMessages)
-		chat.POST("/rooms/:id/messages", middleware.AuthMiddleware(), sendMessage)
-		chat.POST("/rooms/:id/mark-read", middleware.AuthMiddleware(), markMessagesAsReadEndpoint)
-		chat.PUT("/messages/:id/read", middleware.AuthMiddleware(), markMessageAsRead)
+		chat.GET("/rooms/:id/messages", middleware.AuthMiddleware(), h.getChatMessages)
+		chat.GET("/rooms/:id/search", middleware.AuthMiddleware(), h.searchRoomMessages)
+		chat.POST("/rooms/:id/messages", middleware.AuthMiddleware(), middleware.Idempotency(), h.sendMessage)
+		chat.POST("/rooms/:id/location", middleware.AuthMiddleware(), middleware.Idempotency(), h.sendLocation)
+		chat.POST("/rooms/:id/mark-read", middleware.AuthMiddleware(), h.markRoomRead)
+		chat.POST("/rooms/:id/mark-delivered", middleware.AuthMiddleware(), h.markRoomDelivered)
+		chat.GET("/search", middleware.AuthMiddleware(), h.searchMessages)
+		chat.PUT("/messages/:id/read", middleware.AuthMiddleware(), h.markMessageRead)
+		chat.PUT("/messages/:id/location", middleware.AuthMiddleware(), h.updateLocation)
+		chat.DELETE("/messages/:id/location", middleware.AuthMiddleware(), h.stopLocation)
+		chat.POST("/messages/:id/pin", middleware.AuthMiddleware(), h.pinMessage)
+		chat.DELETE("/messages/:id/pin", middleware.AuthMiddleware(), h.unpinMessage)
+		chat.GET("/quick-replies", middleware.AuthMiddleware(), getQuickReplies)
 		
 		// Voice message management
 		chat.POST("/rooms/:id/voice-messages", middleware.AuthMiddleware(), uploadVoiceMessage)
@@ -69,18 +99,17 @@ func ChatRoutes(router *gin.Engine, hub *ws.Hub) {
 }
 
 // handleWebSocketConnection handles WebSocket connection and adds user to their chat rooms
-func handleWebSocketConnection(c *gin.Context) {
+func (h *ChatHandler) handleWebSocketConnection(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	userType := c.Query("user_type") // Get user_type from query parameters
 	
 	if userType == "" {
 		// Determine user type based on whether they have a worker profile
-		var workerProfile models.WorkerProfile
-		if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
+		if _, err := h.users.FindWorkerByUserID(userID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
 				userType = "customer"
 			} else {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to determine user type"})
+				apierror.Abort(c, apierror.Internal("Failed to determine user type", nil))
 				return
 			}
 		} else {
@@ -91,44 +120,61 @@ func handleWebSocketConnection(c *gin.Context) {
 	log.Printf("🔌 WebSocket connection: UserID=%d, UserType=%s", userID, userType)
 	
 	// Add user to their existing chat rooms for real-time messaging
-	var chatRooms []models.ChatRoom
-	if err := database.DB.Where("customer_id = ? OR worker_id = ?", userID, userID).Find(&chatRooms).Error; err == nil {
+	if chatRooms, err := h.chats.ListRoomsForUser(userID); err == nil {
 		for _, room := range chatRooms {
-			chatHub.AddUserToChatRoom(userID, room.ID)
+			h.hub.AddUserToChatRoom(userID, room.ID)
 			log.Printf("👥 User %d added to existing chat room %d", userID, room.ID)
 		}
 	}
 	
 	// Upgrade HTTP connection to WebSocket
-	ws.ServeWebSocket(chatHub, c.Writer, c.Request, userID, userType)
+	ws.ServeWebSocket(h.hub, c.Writer, c.Request, userID, userType)
+}
+
+// handleEventStream streams everything the hub sends the user as Server-Sent Events. A client
+// that reconnects too late to replay what it missed gets a "resync" message and should reload.
+func (h *ChatHandler) handleEventStream(c *gin.Context) {
+	if h.hub.Events == nil {
+		apierror.Abort(c, apierror.Unavailable("Event streams are not available", nil))
+		return
+	}
+	userID := c.GetUint("user_id")
+
+	// Room messages are only published to the rooms' known members
+	if chatRooms, err := h.chats.ListRoomsForUser(userID); err == nil {
+		for _, room := range chatRooms {
+			h.hub.AddUserToChatRoom(userID, room.ID)
+		}
+	}
+
+	log.Printf("📡 Event stream connection: UserID=%d", userID)
+	h.hub.Events.ServeSSE(c.Writer, c.Request, ws.SSEStream{
+		Topic: ws.UserTopic(userID),
+		Resync: func() interface{} {
+			return ws.Message{Type: "resync", Timestamp: time.Now()}
+		},
+	})
 }
 
 // getChatRooms returns all chat rooms for the authenticated user
-func getChatRooms(c *gin.Context) {
+func (h *ChatHandler) getChatRooms(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	
-	var chatRooms []models.ChatRoom
-	
 	// Get chat rooms where user is either customer or worker
-	if err := database.DB.
-		Preload("Customer").
-		Preload("Worker").
-		Preload("ServiceRequest").
-		Where("customer_id = ? OR worker_id = ?", userID, userID).
-		Order("last_message_at DESC NULLS LAST, created_at DESC").
-		Find(&chatRooms).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch chat rooms"})
+	chatRooms, err := h.chats.ListRoomsForUser(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch chat rooms", err))
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"chat_rooms": chatRooms,
+		"chat_rooms": serializers.ChatRooms(chatRooms),
 	})
 }
 
 // createChatRoom creates a new chat room between customer and worker
-func createChatRoom(c *gin.Context) {
+func (h *ChatHandler) createChatRoom(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	
 	var request struct {
@@ -137,25 +183,22 @@ func createChatRoom(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
 		return
 	}
 	
 	// Verify the service request exists and belongs to the customer
-	var serviceRequest models.CustomerServiceRequest
-	if err := database.DB.Where("id = ? AND customer_id = ?", request.ServiceRequestID, userID).First(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+	if _, err := h.requests.FindForCustomer(request.ServiceRequestID, userID); err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
 		return
 	}
 	
 	// Check if chat room already exists
-	var existingRoom models.ChatRoom
-	if err := database.DB.Where("customer_id = ? AND worker_id = ? AND service_request_id = ?", 
-		userID, request.WorkerID, request.ServiceRequestID).First(&existingRoom).Error; err == nil {
+	if existingRoom, err := h.chats.FindRoom(userID, request.WorkerID, request.ServiceRequestID); err == nil {
 		// Room already exists, return it
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"chat_room": existingRoom,
+			"chat_room": serializers.ChatRoom(*existingRoom),
 			"message": "Chat room already exists",
 		})
 		return
@@ -169,64 +212,63 @@ func createChatRoom(c *gin.Context) {
 		IsActive:         true,
 	}
 	
-	if err := database.DB.Create(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chat room"})
+	if err := h.chats.CreateRoom(&chatRoom); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create chat room", err))
 		return
 	}
 	
 	// Load the created room with relationships
-	database.DB.
-		Preload("Customer", "id, full_name, profile_picture_url").
-		Preload("Worker", "id, full_name, profile_picture_url").
-		Preload("ServiceRequest", "id, title, status").
-		First(&chatRoom, chatRoom.ID)
+	if details, err := h.chats.RoomDetails(chatRoom.ID, userID); err == nil {
+		chatRoom = *details
+	}
 	
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
-		"chat_room": chatRoom,
+		"chat_room": serializers.ChatRoom(chatRoom),
 	})
 }
 
 // getChatRoom returns a specific chat room with messages
-func getChatRoom(c *gin.Context) {
+func (h *ChatHandler) getChatRoom(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chat room ID"})
+		apierror.Abort(c, apierror.Validation("Invalid chat room ID"))
 		return
 	}
 	
-	var chatRoom models.ChatRoom
-	if err := database.DB.
-		Preload("Customer").
-		Preload("Worker").
-		Preload("ServiceRequest").
-		Where("id = ? AND (customer_id = ? OR worker_id = ?)", chatRoomID, userID, userID).
-		First(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chat room not found"})
+	chatRoom, err := h.chats.RoomDetails(uint(chatRoomID), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Chat room not found"))
+		return
+	}
+	pinned, err := h.chats.PinnedMessages(chatRoom.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load pinned messages", err))
 		return
 	}
 	
+	response := serializers.ChatRoom(*chatRoom)
+	response.PinnedMessages = serializers.ChatMessages(pinned)
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"chat_room": chatRoom,
+		"chat_room": response,
 	})
 }
 
 // getChatMessages returns messages for a specific chat room
-func getChatMessages(c *gin.Context) {
+func (h *ChatHandler) getChatMessages(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chat room ID"})
+		apierror.Abort(c, apierror.Validation("Invalid chat room ID"))
 		return
 	}
 	
 	// Verify user has access to this chat room
-	var chatRoom models.ChatRoom
-	if err := database.DB.Where("id = ? AND (customer_id = ? OR worker_id = ?)", 
-		chatRoomID, userID, userID).First(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chat room not found"})
+	chatRoom, err := h.chats.FindRoomForUser(uint(chatRoomID), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Chat room not found"))
 		return
 	}
 	
@@ -235,29 +277,18 @@ func getChatMessages(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	offset := (page - 1) * limit
 	
-	var messages []models.ChatMessage
-	var total int64
-	
-	// Get total count
-	database.DB.Model(&models.ChatMessage{}).Where("chat_room_id = ?", chatRoomID).Count(&total)
-	
-	// Get messages with pagination
-	if err := database.DB.
-		Where("chat_room_id = ?", chatRoomID).
-		Order("created_at DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&messages).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+	messages, total, err := h.chats.ListMessages(uint(chatRoomID), repository.Page{Offset: offset, Limit: limit})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch messages", err))
 		return
 	}
 	
-	// Mark messages as read for the other user
-	go markMessagesAsRead(uint(chatRoomID), userID)
+	// Fetching the messages means the user has read them
+	go h.recordReceipt(*chatRoom, userID, models.MessageStatusRead, 0)
 	
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"messages": messages,
+		"messages": serializers.ChatMessages(messages),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -267,29 +298,36 @@ func getChatMessages(c *gin.Context) {
 }
 
 // sendMessage sends a new message in a chat room
-func sendMessage(c *gin.Context) {
+func (h *ChatHandler) sendMessage(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chat room ID"})
+		apierror.Abort(c, apierror.Validation("Invalid chat room ID"))
 		return
 	}
 	
 	var request struct {
-		MessageText string `json:"message_text" binding:"required"`
+		Content     string `json:"content"`
+		MessageText string `json:"message_text"` // Deprecated: older clients send the text here
 		MessageType string `json:"message_type" binding:"required"`
 	}
 	
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
+		return
+	}
+	if request.Content == "" {
+		request.Content = request.MessageText
+	}
+	if request.Content == "" {
+		validation.Fail(c, "content", "required", "")
 		return
 	}
 	
 	// Verify user has access to this chat room
-	var chatRoom models.ChatRoom
-	if err := database.DB.Where("id = ? AND (customer_id = ? OR worker_id = ?)", 
-		chatRoomID, userID, userID).First(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chat room not found"})
+	chatRoom, err := h.chats.FindRoomForUser(uint(chatRoomID), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Chat room not found"))
 		return
 	}
 	
@@ -301,32 +339,36 @@ func sendMessage(c *gin.Context) {
 		senderType = "worker"
 	}
 	
+	// Mask profanity and contact details before anything is stored or delivered
+	moderation := services.NewContentModerationService()
+	original := request.Content
+	filtered := moderation.Filter(original)
+	request.Content = filtered.Text
+	
 	// Create the message
 	message := models.ChatMessage{
 		ChatRoomID:  uint(chatRoomID),
 		SenderID:    userID,
 		SenderType:  senderType,
-		Content:     request.MessageText,
-		MessageText: request.MessageText, // Also set MessageText to match Content
+		Content:     request.Content,
 		MessageType: request.MessageType,
-		IsRead:      false,
 	}
 	
-	if err := database.DB.Create(&message).Error; err != nil {
+	if err := h.chats.CreateMessage(&message); err != nil {
 		log.Printf("❌ Database error creating chat message: %v", err)
-		log.Printf("🔍 Message data: ChatRoomID=%d, SenderID=%d, SenderType=%s, Content='%s', MessageText='%s'", 
-			message.ChatRoomID, message.SenderID, message.SenderType, message.Content, message.MessageText)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		log.Printf("🔍 Message data: ChatRoomID=%d, SenderID=%d, SenderType=%s, Content='%s'", 
+			message.ChatRoomID, message.SenderID, message.SenderType, message.Content)
+		apierror.Abort(c, apierror.Internal("Failed to send message", nil))
 		return
 	}
 	
+	moderation.Record(userID, "chat_message", message.ID, original, filtered)
+	
 	// Update chat room last message info
 	now := time.Now()
-	database.DB.Model(&chatRoom).Updates(map[string]interface{}{
-		"last_message_at":   &now,
-		"last_message_text": request.MessageText,
-		"unread_count":      gorm.Expr("unread_count + 1"),
-	})
+	if err := h.chats.TouchRoom(chatRoom, request.Content, now); err != nil {
+		log.Printf("⚠️ Failed to update chat room %d summary: %v", chatRoom.ID, err)
+	}
 	
 	// Send real-time message via WebSocket
 	websocketMessage := &ws.Message{
@@ -334,71 +376,22 @@ func sendMessage(c *gin.Context) {
 		ChatRoomID:  uint(chatRoomID),
 		SenderID:    userID,
 		SenderType:  senderType,
-		Content:     request.MessageText,
+		Content:     request.Content,
 		Timestamp:   now,
 	}
 	
 	// Ensure sender is in the chat room for WebSocket
-	chatHub.AddUserToChatRoom(userID, uint(chatRoomID))
+	h.hub.AddUserToChatRoom(userID, uint(chatRoomID))
 	
 	// Send to all users in the chat room (excluding sender)
-	chatHub.SendToChatRoom(uint(chatRoomID), websocketMessage, userID)
+	h.hub.SendToChatRoom(uint(chatRoomID), websocketMessage, userID)
 	
-	// Send push notifications to offline users
-	go sendPushNotifications(uint(chatRoomID), userID, request.MessageText)
+	// Send push notifications to users not following the room live
+	go sendPushNotifications(*chatRoom, userID, request.Content)
 	
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
-		"message": message,
-	})
-}
-
-// markMessageAsRead marks a specific message as read
-func markMessageAsRead(c *gin.Context) {
-	userID := c.GetUint("user_id")
-	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
-		return
-	}
-	
-	var message models.ChatMessage
-	if err := database.DB.Where("id = ?", messageID).First(&message).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
-		return
-	}
-	
-	// Verify user has access to this message's chat room
-	var chatRoom models.ChatRoom
-	if err := database.DB.Where("id = ? AND (customer_id = ? OR worker_id = ?)", 
-		message.ChatRoomID, userID, userID).First(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
-		return
-	}
-	
-	// Mark message as read
-	now := time.Now()
-	database.DB.Model(&message).Updates(map[string]interface{}{
-		"is_read": &now,
-		"read_at": &now,
-	})
-	
-	// Send read receipt via WebSocket
-	readReceipt := &ws.Message{
-		Type:       "read_receipt",
-		ChatRoomID: message.ChatRoomID,
-		Data: map[string]interface{}{
-			"message_id": messageID,
-			"read_at":    now,
-		},
-		Timestamp: now,
-	}
-	
-	chatHub.SendToChatRoom(message.ChatRoomID, readReceipt, userID)
-	
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Message marked as read",
+		"message": serializers.ChatMessage(message),
 	})
 }
 
@@ -413,13 +406,13 @@ func registerDeviceToken(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
 		return
 	}
 	
 	// Validate platform
 	if request.Platform != "android" && request.Platform != "ios" && request.Platform != "web" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid platform"})
+		apierror.Abort(c, apierror.Validation("Invalid platform"))
 		return
 	}
 	
@@ -436,7 +429,7 @@ func registerDeviceToken(c *gin.Context) {
 		})
 	
 	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register device token"})
+		apierror.Abort(c, apierror.Internal("Failed to register device token", result.Error))
 		return
 	}
 	
@@ -465,14 +458,14 @@ func unregisterDeviceToken(c *gin.Context) {
 	}
 	
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
 		return
 	}
 	
 	// Soft delete device token
 	if err := database.DB.Where("user_id = ? AND platform = ?", userID, request.Platform).
 		Delete(&models.UserDeviceToken{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unregister device token"})
+		apierror.Abort(c, apierror.Internal("Failed to unregister device token", nil))
 		return
 	}
 	
@@ -490,7 +483,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 	var raw map[string]interface{}
 	if err := c.ShouldBindJSON(&raw); err != nil {
 		log.Printf("🔍 Invalid request data (bind): %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
 		return
 	}
 	
@@ -519,7 +512,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 	serviceRequestID, ok3 := parseUint(raw["service_request_id"])
 	if !ok1 || !ok2 || !ok3 || customerID == 0 || workerID == 0 || serviceRequestID == 0 {
 		log.Printf("🔍 Invalid request values: raw=%v", raw)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data"})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
 		return
 	}
 	
@@ -528,7 +521,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 	// Verify the user is either the customer or worker
 	if userID != customerID && userID != workerID {
 		log.Printf("🔍 Access denied: userID=%d, customerID=%d, workerID=%d", userID, customerID, workerID)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		apierror.Abort(c, apierror.Forbidden("Access denied"))
 		return
 	}
 	
@@ -543,7 +536,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 		// Room already exists, return it
 		c.JSON(http.StatusOK, gin.H{
 			"success": true,
-			"chat_room": existingRoom,
+			"chat_room": serializers.ChatRoom(existingRoom),
 		})
 		return
 	}
@@ -552,7 +545,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 	var serviceRequest models.CustomerServiceRequest
 	if err := database.DB.Where("id = ?", serviceRequestID).First(&serviceRequest).Error; err != nil {
 		log.Printf("🔍 Service request not found: ID=%d, error=%v", serviceRequestID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
 		return
 	}
 	
@@ -562,7 +555,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 	var customer models.User
 	if err := database.DB.Where("id = ?", customerID).First(&customer).Error; err != nil {
 		log.Printf("🔍 Customer not found: ID=%d, error=%v", customerID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		apierror.Abort(c, apierror.NotFound("Customer not found"))
 		return
 	}
 	
@@ -571,7 +564,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 	var worker models.User
 	if err := database.DB.Where("id = ?", workerID).First(&worker).Error; err != nil {
 		log.Printf("🔍 Worker not found: ID=%d, error=%v", workerID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
 		return
 	}
 	
@@ -587,7 +580,7 @@ func getOrCreateChatRoom(c *gin.Context) {
 	}
 	
 	if err := database.DB.Create(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chat room"})
+		apierror.Abort(c, apierror.Internal("Failed to create chat room", err))
 		return
 	}
 	
@@ -598,86 +591,22 @@ func getOrCreateChatRoom(c *gin.Context) {
 		Preload("ServiceRequest").
 		Where("id = ?", chatRoom.ID).
 		First(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load created chat room"})
+		apierror.Abort(c, apierror.Internal("Failed to load created chat room", nil))
 		return
 	}
 	
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
-		"chat_room": chatRoom,
-	})
-}
-
-// markMessagesAsReadEndpoint marks all messages in a chat room as read for the authenticated user
-func markMessagesAsReadEndpoint(c *gin.Context) {
-	userID := c.GetUint("user_id")
-	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chat room ID"})
-		return
-	}
-	
-	// Verify user has access to this chat room
-	var chatRoom models.ChatRoom
-	if err := database.DB.Where("id = ? AND (customer_id = ? OR worker_id = ?)", 
-		chatRoomID, userID, userID).First(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chat room not found"})
-		return
-	}
-	
-	// Mark messages as read
-	markMessagesAsRead(uint(chatRoomID), userID)
-	
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Messages marked as read",
+		"chat_room": serializers.ChatRoom(chatRoom),
 	})
 }
 
-// Helper functions
-
-// markMessagesAsRead marks all unread messages in a chat room as read for a specific user
-func markMessagesAsRead(chatRoomID uint, userID uint) {
-	// Get the other user's ID from the chat room
-	var chatRoom models.ChatRoom
-	if err := database.DB.Where("id = ?", chatRoomID).First(&chatRoom).Error; err != nil {
-		return
-	}
-	
-	var otherUserID uint
-	if chatRoom.CustomerID == userID {
-		otherUserID = chatRoom.WorkerID
-	} else {
-		otherUserID = chatRoom.CustomerID
-	}
-	
-	// Mark messages from the other user as read
-	now := time.Now()
-	database.DB.Model(&models.ChatMessage{}).
-		Where("chat_room_id = ? AND sender_id = ? AND is_read = ?", 
-			chatRoomID, otherUserID, false).
-		Updates(map[string]interface{}{
-			"is_read": &now,
-			"read_at": &now,
-		})
-	
-	// Reset unread count
-	database.DB.Model(&chatRoom).Update("unread_count", 0)
-}
-
-// sendPushNotifications sends push notifications to offline users
-func sendPushNotifications(chatRoomID uint, senderID uint, messageContent string) {
-	// This will be implemented with Firebase/Expo push notification services
-	// For now, just log the action
-	log.Printf("📱 Push notification would be sent for chat room %d, message: %s", chatRoomID, messageContent)
-}
-
 // uploadVoiceMessage handles voice message uploads
 func uploadVoiceMessage(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chat room ID"})
+		apierror.Abort(c, apierror.Validation("Invalid chat room ID"))
 		return
 	}
 
@@ -685,33 +614,20 @@ func uploadVoiceMessage(c *gin.Context) {
 	var chatRoom models.ChatRoom
 	if err := database.DB.Where("id = ? AND (customer_id = ? OR worker_id = ?)", 
 		chatRoomID, userID, userID).First(&chatRoom).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Chat room not found"})
+		apierror.Abort(c, apierror.NotFound("Chat room not found"))
 		return
 	}
 
 	// Parse multipart form
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil { // 32MB max
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form"})
+		apierror.Abort(c, apierror.Validation("Failed to parse form"))
 		return
 	}
 
 	// Get audio file
-	file, header, err := c.Request.FormFile("audio")
+	_, header, err := c.Request.FormFile("audio")
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No audio file provided"})
-		return
-	}
-	defer file.Close()
-
-	// Validate file type
-	if !strings.HasSuffix(header.Filename, ".m4a") && !strings.HasSuffix(header.Filename, ".mp3") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Only .m4a and .mp3 files are supported"})
-		return
-	}
-
-	// Validate file size (max 10MB)
-	if header.Size > 10<<20 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File size too large. Maximum 10MB allowed"})
+		apierror.Abort(c, apierror.Validation("No audio file provided"))
 		return
 	}
 
@@ -719,15 +635,20 @@ func uploadVoiceMessage(c *gin.Context) {
 	durationStr := c.Request.FormValue("duration")
 	duration, err := strconv.Atoi(durationStr)
 	if err != nil || duration <= 0 || duration > 600 { // Max 10 minutes
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration. Must be between 1-600 seconds"})
+		apierror.Abort(c, apierror.Validation("Invalid duration. Must be between 1-600 seconds"))
 		return
 	}
 
-	// Upload to Cloudinary
-	audioURL, err := uploadToCloudinary(file, header.Filename)
+	// Check the audio and keep it for transcription, then upload it to Cloudinary
+	media := services.NewMediaService()
+	audio, ok := prepareMedia(c, media, models.MediaPurposeVoiceMessage, "audio", header)
+	if !ok {
+		return
+	}
+	upload, err := media.Store(userID, audio, fmt.Sprintf("voice_messages/%d", chatRoomID))
 	if err != nil {
 		log.Printf("❌ Cloudinary upload failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload audio file"})
+		apierror.Abort(c, apierror.Internal("Failed to upload audio file", nil))
 		return
 	}
 
@@ -745,16 +666,18 @@ func uploadVoiceMessage(c *gin.Context) {
 		SenderID:    userID,
 		SenderType:  senderType,
 		Content:     "🎤 Voice message",
-		MessageText: "🎤 Voice message",
 		MessageType: "voice",
-		AudioURL:    audioURL,
+		AudioURL:    upload.URL,
 		Duration:    duration,
-		IsRead:      false,
+	}
+	transcriber := services.NewTranscriber()
+	if _, disabled := transcriber.(*services.NoTranscriber); !disabled {
+		message.TranscriptStatus = models.TranscriptPending
 	}
 
 	if err := database.DB.Create(&message).Error; err != nil {
 		log.Printf("❌ Database error creating voice message: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save voice message"})
+		apierror.Abort(c, apierror.Internal("Failed to save voice message", nil))
 		return
 	}
 
@@ -775,7 +698,7 @@ func uploadVoiceMessage(c *gin.Context) {
 		Content:     "🎤 Voice message",
 		Timestamp:   now,
 		Data: gin.H{
-			"message": message,
+			"message": serializers.ChatMessage(message),
 			"chat_room_id": chatRoomID,
 		},
 	}
@@ -784,33 +707,15 @@ func uploadVoiceMessage(c *gin.Context) {
 	chatHub.AddUserToChatRoom(userID, uint(chatRoomID))
 	chatHub.SendToChatRoom(uint(chatRoomID), websocketMessage, userID)
 
+	if message.TranscriptStatus == models.TranscriptPending {
+		go transcribeVoiceMessage(transcriber, message, audio.Data, "voice"+audio.Extension, c.Request.FormValue("language"))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Voice message sent successfully",
 		"data": gin.H{
-			"message": message,
+			"message": serializers.ChatMessage(message),
 		},
 	})
 }
-
-// uploadToCloudinary uploads audio file to Cloudinary
-func uploadToCloudinary(file multipart.File, filename string) (string, error) {
-	// Configure Cloudinary
-	cld, err := cloudinary.New()
-	if err != nil {
-		return "", err
-	}
-
-	// Upload file with basic parameters
-	result, err := cld.Upload.Upload(context.Background(), file, uploader.UploadParams{
-		ResourceType: "video", // Use video for audio files
-		PublicID:     fmt.Sprintf("voice_messages/%s_%d", filename, time.Now().Unix()),
-		Format:       "mp3", // Convert to MP3 for better compatibility
-		Transformation: "f_mp3", // Force MP3 format
-	})
-	if err != nil {
-		return "", err
-	}
-
-	return result.SecureURL, nil
-}
@@ -0,0 +1,256 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/repository"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+	ws "repair-service-server/websocket"
+)
+
+// defaultLocationLabel is the text of a location message the sender did not label
+const defaultLocationLabel = "📍 Location"
+
+// sendLocation shares a point in a chat room, either once or live for a number of minutes
+func (h *ChatHandler) sendLocation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid chat room ID"))
+		return
+	}
+
+	var req struct {
+		Latitude    *float64 `json:"latitude" binding:"required,latitude"`
+		Longitude   *float64 `json:"longitude" binding:"required,longitude"`
+		LiveMinutes int      `json:"live_minutes" binding:"min=0,max=120"` // 0 shares the point once
+		Label       string   `json:"label" binding:"max=200"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	room, err := h.chats.FindRoomForUser(uint(chatRoomID), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Chat room not found"))
+		return
+	}
+	senderType := "worker"
+	if room.CustomerID == userID {
+		senderType = "customer"
+	}
+
+	label := defaultLocationLabel
+	if req.Label != "" {
+		label = services.NewContentModerationService().Filter(req.Label).Text
+	}
+	now := time.Now()
+	message := models.ChatMessage{
+		ChatRoomID:  room.ID,
+		SenderID:    userID,
+		SenderType:  senderType,
+		Content:     label,
+		MessageType: models.MessageTypeLocation,
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+	}
+	if req.LiveMinutes > 0 {
+		liveUntil := now.Add(time.Duration(req.LiveMinutes) * time.Minute)
+		message.LiveUntil = &liveUntil
+	}
+	if err := h.chats.CreateMessage(&message); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to share location", err))
+		return
+	}
+	if err := h.chats.TouchRoom(room, label, now); err != nil {
+		log.Printf("⚠️ Failed to update chat room %d summary: %v", room.ID, err)
+	}
+
+	h.hub.AddUserToChatRoom(userID, room.ID)
+	h.hub.SendToChatRoom(room.ID, &ws.Message{
+		Type:       "location",
+		ChatRoomID: room.ID,
+		SenderID:   userID,
+		SenderType: senderType,
+		Content:    label,
+		Timestamp:  now,
+		Data:       gin.H{"message": serializers.ChatMessage(message)},
+	}, userID)
+	go sendPushNotifications(*room, userID, label)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": serializers.ChatMessage(message),
+	})
+}
+
+// liveLocationFor loads a live location message the user is sharing, or aborts
+func (h *ChatHandler) liveLocationFor(c *gin.Context, userID uint) (*models.ChatMessage, bool) {
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid message ID"))
+		return nil, false
+	}
+	message, err := h.chats.FindMessage(uint(messageID))
+	if err != nil || message.SenderID != userID || message.MessageType != models.MessageTypeLocation {
+		apierror.Abort(c, apierror.NotFound("Location message not found"))
+		return nil, false
+	}
+	if !message.IsLiveAt(time.Now()) {
+		apierror.Abort(c, apierror.Conflict("Location is no longer being shared"))
+		return nil, false
+	}
+	return message, true
+}
+
+// updateLocation moves a live location the user is sharing
+func (h *ChatHandler) updateLocation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	var req struct {
+		Latitude  *float64 `json:"latitude" binding:"required,latitude"`
+		Longitude *float64 `json:"longitude" binding:"required,longitude"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	message, ok := h.liveLocationFor(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.moveLiveLocation(message, *req.Latitude, *req.Longitude); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			apierror.Abort(c, apierror.Conflict("Location is no longer being shared"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to update location", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": serializers.ChatMessage(*message),
+	})
+}
+
+// stopLocation ends a live location the user is sharing before it expires
+func (h *ChatHandler) stopLocation(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	message, ok := h.liveLocationFor(c, userID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	message.LiveEndedAt = &now
+	if err := h.chats.UpdateLocation(message); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			apierror.Abort(c, apierror.Conflict("Location is no longer being shared"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to stop sharing location", err))
+		return
+	}
+	publishLocationEnded(*message)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": serializers.ChatMessage(*message),
+	})
+}
+
+// moveLiveLocation saves a live location's new position and relays it to the room
+func (h *ChatHandler) moveLiveLocation(message *models.ChatMessage, latitude, longitude float64) error {
+	message.Latitude, message.Longitude = &latitude, &longitude
+	if err := h.chats.UpdateLocation(message); err != nil {
+		return err
+	}
+	h.hub.SendToChatRoom(message.ChatRoomID, &ws.Message{
+		Type:       "location_update",
+		ChatRoomID: message.ChatRoomID,
+		SenderID:   message.SenderID,
+		Timestamp:  time.Now(),
+		Data: gin.H{
+			"message_id": message.ID,
+			"latitude":   latitude,
+			"longitude":  longitude,
+			"live_until": message.LiveUntil,
+		},
+	}, message.SenderID)
+	return nil
+}
+
+// handleLocationUpdate moves a live location from a WebSocket message shaped like
+// {"type": "location_update", "data": {"message_id": 42, "latitude": 18.08, "longitude": -15.97}}
+func (h *ChatHandler) handleLocationUpdate(client *ws.Client, message *ws.Message) error {
+	fields, _ := message.Data.(map[string]interface{})
+	latitude, okLat := fields["latitude"].(float64)
+	longitude, okLng := fields["longitude"].(float64)
+	if !okLat || !okLng || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		log.Printf("⚠️ User %d sent an invalid location update", client.ID)
+		return nil
+	}
+
+	location, err := h.chats.FindMessage(messageIDOf(message.Data))
+	if err != nil || location.SenderID != client.ID || location.MessageType != models.MessageTypeLocation || !location.IsLiveAt(time.Now()) {
+		log.Printf("⚠️ User %d sent an update for a location they are not sharing", client.ID)
+		return nil
+	}
+	return h.moveLiveLocation(location, latitude, longitude)
+}
+
+// publishLocationEnded tells the room a live location is no longer shared
+func publishLocationEnded(message models.ChatMessage) {
+	if chatHub == nil {
+		return
+	}
+	chatHub.SendToChatRoom(message.ChatRoomID, &ws.Message{
+		Type:       "location_ended",
+		ChatRoomID: message.ChatRoomID,
+		SenderID:   message.SenderID,
+		Timestamp:  time.Now(),
+		Data: gin.H{
+			"message_id":    message.ID,
+			"live_ended_at": message.LiveEndedAt,
+		},
+	}, 0)
+}
+
+// ProcessExpiredLiveLocations ends live locations whose sharing time is over
+func ProcessExpiredLiveLocations() {
+	var expired []models.ChatMessage
+	if err := database.DB.
+		Where("live_until IS NOT NULL AND live_ended_at IS NULL AND live_until <= ?", time.Now()).
+		Find(&expired).Error; err != nil {
+		log.Printf("❌ Failed to load expired live locations: %v", err)
+		return
+	}
+
+	for _, message := range expired {
+		message.LiveEndedAt = message.LiveUntil
+		result := database.DB.Model(&models.ChatMessage{}).
+			Where("id = ? AND live_ended_at IS NULL", message.ID).
+			Update("live_ended_at", message.LiveEndedAt)
+		if result.Error != nil {
+			log.Printf("❌ Failed to end live location %d: %v", message.ID, result.Error)
+			continue
+		}
+		// Another instance or the sender got there first
+		if result.RowsAffected == 0 {
+			continue
+		}
+		publishLocationEnded(message)
+	}
+	if len(expired) > 0 {
+		log.Printf("📍 Ended %d expired live locations", len(expired))
+	}
+}
@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	ws "repair-service-server/websocket"
+)
+
+// pinMessage pins a message to the top of its chat room, for both participants
+func (h *ChatHandler) pinMessage(c *gin.Context) {
+	h.setPinned(c, true)
+}
+
+// unpinMessage takes a message off its chat room's pinned messages
+func (h *ChatHandler) unpinMessage(c *gin.Context) {
+	h.setPinned(c, false)
+}
+
+func (h *ChatHandler) setPinned(c *gin.Context, pinned bool) {
+	userID := c.GetUint("user_id")
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid message ID"))
+		return
+	}
+
+	message, err := h.chats.FindMessage(uint(messageID))
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Message not found"))
+		return
+	}
+	room, err := h.chats.FindRoomForUser(message.ChatRoomID, userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Message not found"))
+		return
+	}
+
+	// Pinning a pinned message or unpinning an unpinned one changes nothing
+	if pinned != (message.PinnedAt != nil) {
+		if pinned {
+			current, err := h.chats.PinnedMessages(room.ID)
+			if err != nil {
+				apierror.Abort(c, apierror.Internal("Failed to load pinned messages", err))
+				return
+			}
+			if len(current) >= models.MaxPinnedMessages {
+				apierror.Abort(c, apierror.Conflict(fmt.Sprintf("A chat room can have at most %d pinned messages", models.MaxPinnedMessages)))
+				return
+			}
+			now := time.Now()
+			message.PinnedAt, message.PinnedBy = &now, &userID
+		} else {
+			message.PinnedAt, message.PinnedBy = nil, nil
+		}
+		if err := h.chats.SetPinned(message); err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to update pinned message", err))
+			return
+		}
+
+		eventType := "message_unpinned"
+		if pinned {
+			eventType = "message_pinned"
+		}
+		h.hub.SendToChatRoom(room.ID, &ws.Message{
+			Type:       eventType,
+			ChatRoomID: room.ID,
+			SenderID:   userID,
+			Timestamp:  time.Now(),
+			Data:       gin.H{"message": serializers.ChatMessage(*message)},
+		}, userID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": serializers.ChatMessage(*message),
+	})
+}
@@ -0,0 +1,113 @@
+package routes
+
+import (
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+)
+
+// chatPushDelay is how long a chat push waits for more messages in the room, so a burst of
+// messages becomes one notification
+const chatPushDelay = 10 * time.Second
+
+// chatPreviewLength is how many characters of a message its push shows
+const chatPreviewLength = 100
+
+// chatPushKey identifies the pending push of one chat room for one recipient
+type chatPushKey struct {
+	chatRoomID  uint
+	recipientID uint
+}
+
+// pendingChatPush collects the messages sent to a recipient while their push waits
+type pendingChatPush struct {
+	room     models.ChatRoom
+	senderID uint
+	count    int
+	preview  string
+}
+
+var (
+	chatPushMu        sync.Mutex
+	pendingChatPushes = make(map[chatPushKey]*pendingChatPush)
+)
+
+// sendPushNotifications queues a push of a new chat message for the other participant of the
+// room. Nothing is sent while they follow the room over WebSocket. Messages arriving within
+// chatPushDelay of the first are summarized in one push.
+func sendPushNotifications(room models.ChatRoom, senderID uint, messageContent string) {
+	recipientID := room.CustomerID
+	if senderID == room.CustomerID {
+		recipientID = room.WorkerID
+	}
+	if chatRecipientIsLive(recipientID, room.ID) {
+		return
+	}
+
+	key := chatPushKey{chatRoomID: room.ID, recipientID: recipientID}
+	preview := chatPreview(messageContent)
+
+	chatPushMu.Lock()
+	defer chatPushMu.Unlock()
+	if pending, ok := pendingChatPushes[key]; ok {
+		pending.senderID = senderID
+		pending.count++
+		pending.preview = preview
+		return
+	}
+	pendingChatPushes[key] = &pendingChatPush{room: room, senderID: senderID, count: 1, preview: preview}
+	time.AfterFunc(chatPushDelay, func() { flushChatPush(key) })
+}
+
+// flushChatPush sends the push collected for key, unless the recipient opened the room meanwhile
+func flushChatPush(key chatPushKey) {
+	chatPushMu.Lock()
+	pending := pendingChatPushes[key]
+	delete(pendingChatPushes, key)
+	chatPushMu.Unlock()
+	if pending == nil || chatRecipientIsLive(key.recipientID, key.chatRoomID) {
+		return
+	}
+
+	var sender models.User
+	if err := database.DB.Select("id", "full_name").First(&sender, pending.senderID).Error; err != nil {
+		log.Printf("⚠️ Failed to load chat sender %d for push: %v", pending.senderID, err)
+		return
+	}
+
+	message := "notification.chat.message"
+	if pending.count > 1 {
+		message = "notification.chat.summary"
+	}
+	vars := i18n.Vars{"name": sender.FullName, "count": pending.count, "preview": pending.preview}
+	// Apps open the conversation from the room and request IDs
+	data := map[string]interface{}{
+		"type":               "chat_message",
+		"screen":             "chat",
+		"chat_room_id":       pending.room.ID,
+		"service_request_id": pending.room.ServiceRequestID,
+		"sender_id":          pending.senderID,
+		"message_count":      pending.count,
+	}
+	if err := SendLocalizedPushNotification(key.recipientID, message, vars, "chat_message", data); err != nil {
+		log.Printf("❌ Failed to send chat push for room %d to user %d: %v", key.chatRoomID, key.recipientID, err)
+	}
+}
+
+// chatRecipientIsLive reports whether the user is following the room over WebSocket
+func chatRecipientIsLive(userID, chatRoomID uint) bool {
+	return chatHub != nil && chatHub.IsUserInChatRoom(userID, chatRoomID)
+}
+
+// chatPreview shortens a message to what a push shows
+func chatPreview(content string) string {
+	if utf8.RuneCountInString(content) <= chatPreviewLength {
+		return content
+	}
+	return string([]rune(content)[:chatPreviewLength-1]) + "…"
+}
@@ -0,0 +1,44 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+)
+
+// quickReplies are the IDs of the canned chat messages offered to each role, in display order.
+// Their text is in the i18n catalogs under chat.quick_reply.<role>.<id>.
+var quickReplies = map[models.UserRole][]string{
+	models.RoleWorker:   {"on_my_way", "arriving_soon", "running_late", "share_location", "outside", "job_done"},
+	models.RoleCustomer: {"when_arriving", "location_shared", "call_on_arrival", "at_home", "thanks"},
+}
+
+// getQuickReplies returns the canned messages for the caller's role, in their language. Workers
+// get the worker replies; everyone else gets the customer ones.
+func getQuickReplies(c *gin.Context) {
+	role := models.RoleCustomer
+	if user, ok := c.Get("user"); ok {
+		if u, isUser := user.(models.User); isUser && u.Role == models.RoleWorker {
+			role = models.RoleWorker
+		}
+	}
+
+	lang := i18n.Locale(c)
+	replies := make([]gin.H, 0, len(quickReplies[role]))
+	for _, id := range quickReplies[role] {
+		replies = append(replies, gin.H{
+			"id":   id,
+			"text": i18n.T(lang, "chat.quick_reply."+string(role)+"."+id, nil),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":       true,
+		"role":          role,
+		"language":      lang,
+		"quick_replies": replies,
+	})
+}
@@ -0,0 +1,139 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	ws "repair-service-server/websocket"
+)
+
+// recordReceipt marks the room's messages to userID as delivered or read, up to upToID or all of
+// them when upToID is 0, and sends the other participant one receipt covering them all
+func (h *ChatHandler) recordReceipt(room models.ChatRoom, userID uint, status string, upToID uint) error {
+	now := time.Now()
+	mark, eventType, atField := h.chats.MarkDelivered, "delivery_receipt", "delivered_at"
+	if status == models.MessageStatusRead {
+		mark, eventType, atField = h.chats.MarkRead, "read_receipt", "read_at"
+	}
+
+	lastID, err := mark(room.ID, userID, upToID, now)
+	if err != nil {
+		log.Printf("❌ Failed to mark chat room %d messages %s for user %d: %v", room.ID, status, userID, err)
+		return err
+	}
+	if lastID == 0 {
+		return nil
+	}
+	if status == models.MessageStatusRead {
+		database.DB.Model(&models.ChatRoom{}).Where("id = ?", room.ID).Update("unread_count", 0)
+	}
+
+	// The receipt covers message_id and every earlier message in the room
+	h.hub.SendToChatRoom(room.ID, &ws.Message{
+		Type:       eventType,
+		ChatRoomID: room.ID,
+		Data: map[string]interface{}{
+			"user_id":    userID,
+			"message_id": lastID,
+			atField:      now,
+		},
+		Timestamp: now,
+	}, userID)
+	return nil
+}
+
+// messageIDOf reads the message_id a WebSocket message refers to, 0 when absent
+func messageIDOf(data interface{}) uint {
+	fields, _ := data.(map[string]interface{})
+	id, _ := fields["message_id"].(float64)
+	if id < 1 {
+		return 0
+	}
+	return uint(id)
+}
+
+// handleReceiptAck records delivery or read receipts that clients send over the WebSocket as
+// {"type": "delivered", "chat_room_id": 1, "data": {"message_id": 42}}. Without a message_id
+// the receipt covers every message in the room.
+func (h *ChatHandler) handleReceiptAck(status string) ws.MessageHandler {
+	return func(client *ws.Client, message *ws.Message) error {
+		room, err := h.chats.FindRoomForUser(message.ChatRoomID, client.ID)
+		if err != nil {
+			log.Printf("⚠️ User %d sent a %s receipt for chat room %d they are not in", client.ID, status, message.ChatRoomID)
+			return nil
+		}
+		return h.recordReceipt(*room, client.ID, status, messageIDOf(message.Data))
+	}
+}
+
+// markMessageRead marks a message, and every earlier one in its room, as read
+func (h *ChatHandler) markMessageRead(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid message ID"))
+		return
+	}
+
+	var message models.ChatMessage
+	if err := database.DB.Where("id = ?", messageID).First(&message).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Message not found"))
+		return
+	}
+	room, err := h.chats.FindRoomForUser(message.ChatRoomID, userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Forbidden("Access denied"))
+		return
+	}
+
+	if err := h.recordReceipt(*room, userID, models.MessageStatusRead, message.ID); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to mark message as read", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Message marked as read",
+	})
+}
+
+// markRoomRead marks every message in a chat room as read for the authenticated user
+func (h *ChatHandler) markRoomRead(c *gin.Context) {
+	h.markRoom(c, models.MessageStatusRead)
+}
+
+// markRoomDelivered marks every message in a chat room as delivered, for clients that do not
+// acknowledge messages over the WebSocket
+func (h *ChatHandler) markRoomDelivered(c *gin.Context) {
+	h.markRoom(c, models.MessageStatusDelivered)
+}
+
+func (h *ChatHandler) markRoom(c *gin.Context, status string) {
+	userID := c.GetUint("user_id")
+	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid chat room ID"))
+		return
+	}
+
+	room, err := h.chats.FindRoomForUser(uint(chatRoomID), userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Chat room not found"))
+		return
+	}
+
+	if err := h.recordReceipt(*room, userID, status, 0); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to mark messages as "+status, err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Messages marked as " + status,
+	})
+}
@@ -0,0 +1,105 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/repository"
+	"repair-service-server/serializers"
+	"repair-service-server/validation"
+)
+
+// defaultSearchContext is how many messages are shown on each side of a search match, unless
+// the client asks for another number up to 5
+const defaultSearchContext = 2
+
+// searchMessages searches message text and voice transcripts in the caller's chat rooms
+func (h *ChatHandler) searchMessages(c *gin.Context) {
+	var req struct {
+		RoomID *uint `form:"room_id"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	h.serveMessageSearch(c, req.RoomID, true)
+}
+
+// searchRoomMessages searches message text and voice transcripts in one of the caller's rooms
+func (h *ChatHandler) searchRoomMessages(c *gin.Context) {
+	chatRoomID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid chat room ID"))
+		return
+	}
+	if _, err := h.chats.FindRoomForUser(uint(chatRoomID), c.GetUint("user_id")); err != nil {
+		apierror.Abort(c, apierror.NotFound("Chat room not found"))
+		return
+	}
+	roomID := uint(chatRoomID)
+	h.serveMessageSearch(c, &roomID, false)
+}
+
+// serveMessageSearch answers a search with each match and the messages around it, best matches
+// first. withMessages also lists the bare matches, which older clients of the cross-room search
+// read.
+func (h *ChatHandler) serveMessageSearch(c *gin.Context, roomID *uint, withMessages bool) {
+	userID := c.GetUint("user_id")
+
+	var req struct {
+		Query   string `form:"q" binding:"required,min=2,max=100"`
+		Context *int   `form:"context" binding:"omitempty,min=0,max=5"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	contextSize := defaultSearchContext
+	if req.Context != nil {
+		contextSize = *req.Context
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+
+	messages, total, err := h.chats.SearchMessages(userID, roomID, req.Query, repository.Page{Offset: (page - 1) * limit, Limit: limit})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to search messages", err))
+		return
+	}
+
+	results := make([]serializers.ChatSearchResult, 0, len(messages))
+	for _, message := range messages {
+		result := serializers.ChatSearchResult{Message: serializers.ChatMessage(message)}
+		if contextSize > 0 {
+			before, after, err := h.chats.MessageContext(message.ChatRoomID, message.ID, contextSize)
+			if err != nil {
+				apierror.Abort(c, apierror.Internal("Failed to load search context", err))
+				return
+			}
+			result.Before, result.After = serializers.ChatMessages(before), serializers.ChatMessages(after)
+		}
+		results = append(results, result)
+	}
+
+	response := gin.H{
+		"success": true,
+		"results": results,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+		},
+	}
+	if withMessages {
+		response["messages"] = serializers.ChatMessages(messages)
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,97 @@
+package routes
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	ws "repair-service-server/websocket"
+)
+
+// Request lifecycle events recorded as system messages in the request's chat room
+const (
+	systemEventAccepted     = "request_accepted"
+	systemEventStarted      = "request_started"
+	systemEventPriceUpdated = "price_updated"
+	systemEventCompleted    = "request_completed"
+)
+
+// openRequestChatRoom creates the chat room of a request its worker just accepted, unless they
+// already have one, and opens its timeline with the acceptance
+func openRequestChatRoom(request models.CustomerServiceRequest, workerUserID uint) {
+	room := models.ChatRoom{
+		CustomerID:       request.CustomerID,
+		WorkerID:         workerUserID,
+		ServiceRequestID: request.ID,
+	}
+	if err := database.DB.Where(&room).Attrs(models.ChatRoom{IsActive: true}).FirstOrCreate(&room).Error; err != nil {
+		log.Printf("⚠️ Failed to open chat room for service request %d: %v", request.ID, err)
+		return
+	}
+
+	var worker models.User
+	text := "✅ A worker accepted the request."
+	if err := database.DB.Select("id", "full_name").First(&worker, workerUserID).Error; err == nil && worker.FullName != "" {
+		text = fmt.Sprintf("✅ %s accepted the request.", worker.FullName)
+	}
+	postSystemMessage(room, systemEventAccepted, text)
+}
+
+// postRequestSystemMessage records a lifecycle event in the request's current chat room, when it
+// has one
+func postRequestSystemMessage(request models.CustomerServiceRequest, event, text string) {
+	var room models.ChatRoom
+	if err := database.DB.Where("service_request_id = ?", request.ID).Order("id DESC").First(&room).Error; err != nil {
+		return
+	}
+	postSystemMessage(room, event, text)
+}
+
+// postSystemMessage adds a message without a sender to the room and shows it to both participants
+func postSystemMessage(room models.ChatRoom, event, text string) {
+	message := models.ChatMessage{
+		ChatRoomID:  room.ID,
+		SenderType:  models.SenderTypeSystem,
+		Content:     text,
+		MessageType: models.MessageTypeSystem,
+	}
+	if err := database.DB.Create(&message).Error; err != nil {
+		log.Printf("⚠️ Failed to post %s system message to chat room %d: %v", event, room.ID, err)
+		return
+	}
+
+	now := time.Now()
+	database.DB.Model(&room).Updates(map[string]interface{}{
+		"last_message_at":   &now,
+		"last_message_text": text,
+		"unread_count":      gorm.Expr("unread_count + ?", 1),
+	})
+
+	if chatHub != nil {
+		chatHub.SendToChatRoom(room.ID, &ws.Message{
+			Type:       "system_message",
+			ChatRoomID: room.ID,
+			SenderType: models.SenderTypeSystem,
+			Content:    text,
+			Timestamp:  now,
+			Data: gin.H{
+				"event":   event,
+				"message": serializers.ChatMessage(message),
+			},
+		}, 0)
+	}
+}
+
+// requestMoney formats an amount in the request's currency for system messages
+func requestMoney(request models.CustomerServiceRequest, amount money.Amount) string {
+	return i18n.Money(i18n.English, amount, services.NewRegionService().ForRequest(request).Currency)
+}
@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/money"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RegisterCustomerAnalyticsRoutes registers the customer's year in review dashboard
+func RegisterCustomerAnalyticsRoutes(router *gin.RouterGroup) {
+	router.GET("/customers/analytics", getCustomerAnalytics)
+}
+
+// getCustomerAnalytics returns the current customer's spend per month and category, most hired
+// workers, ratings given and upcoming scheduled services for a year, the current one by default
+func getCustomerAnalytics(c *gin.Context) {
+	var req struct {
+		Year int `form:"year" binding:"omitempty,min=2000,max=2100"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	}
+
+	dashboard, err := services.NewCustomerAnalyticsService().Dashboard(c.GetUint("user_id"), req.Year)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch customer analytics", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dashboard,
+		"formatted": formatAmounts(c, dashboard.Currency, map[string]money.Amount{
+			"spend": dashboard.Spend,
+			"tips":  dashboard.Tips,
+		}),
+	})
+}
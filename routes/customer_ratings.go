@@ -0,0 +1,187 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RateCustomer lets the assigned worker rate the customer of a completed request. Very low
+// ratings open an abuse report for admins.
+func RateCustomer(c *gin.Context) {
+	var req models.CustomerRatingCreate
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	workerProfile, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+	if serviceRequest.Status != models.RequestStatusCompleted {
+		apierror.Abort(c, apierror.Validation("Customers can only be rated after the service is completed"))
+		return
+	}
+
+	moderation := services.NewContentModerationService()
+	filtered := moderation.Filter(req.Comment)
+
+	rating := models.CustomerRating{
+		ServiceRequestID: serviceRequest.ID,
+		CustomerID:       serviceRequest.CustomerID,
+		WorkerID:         workerProfile.ID,
+		Stars:            req.Stars,
+		Punctual:         req.Punctual,
+		Respectful:       req.Respectful,
+		PaidPromptly:     req.PaidPromptly,
+		Comment:          filtered.Text,
+	}
+	report, err := services.NewCustomerRatingService().Rate(&rating)
+	switch {
+	case errors.Is(err, services.ErrCustomerAlreadyRated):
+		apierror.Abort(c, apierror.Conflict("You have already rated this customer for this request"))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to rate customer", err))
+		return
+	}
+
+	moderation.Record(c.GetUint("user_id"), "customer_rating", rating.ID, req.Comment, filtered)
+
+	if report != nil {
+		log.Printf("🚩 Abuse report %d for customer %d: %s", report.ID, report.CustomerID, report.Reason)
+		publishOpsEvent("customer_abuse_report", gin.H{
+			"report_id":          report.ID,
+			"customer_id":        report.CustomerID,
+			"reason":             report.Reason,
+			"low_rating_count":   report.LowRatingCount,
+			"customer_score":     report.CustomerScore,
+			"service_request_id": serviceRequest.ID,
+		})
+	}
+
+	log.Printf("⭐ Worker %d rated customer %d %d stars on service request %d", workerProfile.ID, rating.CustomerID, rating.Stars, serviceRequest.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Customer rated",
+		"data":    rating,
+	})
+}
+
+// GetAbuseReports lists customer abuse reports, open ones by default
+func GetAbuseReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	offset := (page - 1) * limit
+
+	query := database.DB.Model(&models.AbuseReport{})
+	if status := c.DefaultQuery("status", string(models.AbuseReportOpen)); status != "all" {
+		query = query.Where("status = ?", status)
+	}
+	if customerID, err := strconv.ParseUint(c.Query("customer_id"), 10, 32); err == nil {
+		query = query.Where("customer_id = ?", customerID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to count abuse reports", err))
+		return
+	}
+
+	var reports []models.AbuseReport
+	if err := query.Preload("Customer").Order("created_at DESC").Offset(offset).Limit(limit).Find(&reports).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch abuse reports", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reports,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// GetCustomerRatingsForAdmin lists the ratings workers gave a customer, newest first
+func GetCustomerRatingsForAdmin(c *gin.Context) {
+	var ratings []models.CustomerRating
+	if err := database.DB.Where("customer_id = ?", c.Param("id")).Order("created_at DESC").Find(&ratings).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch customer ratings", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ratings,
+	})
+}
+
+// ResolveAbuseReport closes an open abuse report. Suspending the customer is a separate call
+// to the user status endpoint.
+func ResolveAbuseReport(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req struct {
+		Status string `json:"status" binding:"required,oneof=dismissed actioned"`
+		Note   string `json:"note" binding:"max=1000"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var report models.AbuseReport
+	if err := database.DB.First(&report, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Abuse report not found"))
+		return
+	}
+	if report.Status != models.AbuseReportOpen {
+		apierror.Abort(c, apierror.Conflict("Abuse report has already been resolved"))
+		return
+	}
+
+	before := report
+	now := time.Now()
+	report.Status = models.AbuseReportStatus(req.Status)
+	report.ResolvedBy = &adminID
+	report.ResolvedAt = &now
+	report.ResolutionNote = req.Note
+	if err := database.DB.Model(&report).Updates(map[string]interface{}{
+		"status":          report.Status,
+		"resolved_by":     report.ResolvedBy,
+		"resolved_at":     report.ResolvedAt,
+		"resolution_note": report.ResolutionNote,
+	}).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to resolve abuse report", err))
+		return
+	}
+	middleware.RecordAuditChange(c, "abuse_reports", report.ID, before, report)
+
+	log.Printf("🚩 Admin %d %s abuse report %d", adminID, report.Status, report.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Abuse report resolved",
+		"data":    report,
+	})
+}
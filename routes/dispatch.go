@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+	"repair-service-server/services"
+
+	ws "repair-service-server/websocket"
+)
+
+// defaultDispatchMode returns the dispatch mode used when a request does not specify one
+func defaultDispatchMode() models.DispatchMode {
+	if models.DispatchMode(os.Getenv("DISPATCH_MODE")) == models.DispatchModeAuto {
+		return models.DispatchModeAuto
+	}
+	return models.DispatchModeBroadcast
+}
+
+// dispatchServiceRequest offers an auto-dispatched request to the next best worker.
+// When no candidate is left, the request falls back to a regular broadcast.
+func dispatchServiceRequest(serviceRequest models.CustomerServiceRequest) {
+	matchingService := services.NewMatchingService()
+
+	offer, err := matchingService.OfferToNextCandidate(serviceRequest, zoneBroadcastRadius(serviceRequest))
+	if err != nil {
+		log.Printf("❌ Auto-dispatch failed for request %d: %v", serviceRequest.ID, err)
+	}
+
+	if offer == nil {
+		log.Printf("📡 No auto-dispatch candidate left for request %d, falling back to broadcast", serviceRequest.ID)
+		expiresAt := time.Now().Add(3 * time.Minute)
+		serviceRequest.ExpiresAt = &expiresAt
+		broadcastServiceRequest(serviceRequest)
+		return
+	}
+
+	var worker models.WorkerProfile
+	if err := database.DB.First(&worker, offer.WorkerID).Error; err != nil {
+		log.Printf("❌ Failed to load offered worker %d: %v", offer.WorkerID, err)
+		return
+	}
+
+	// The customer sees that a worker is being asked, not who
+	pushRequestUpdate(serviceRequest.ID, "offer_sent", map[string]interface{}{
+		"rank":       offer.Rank,
+		"distance":   offer.Distance,
+		"expires_at": offer.ExpiresAt,
+	})
+
+	data := map[string]interface{}{
+		"request_id": serviceRequest.ID,
+		"offer_id":   offer.ID,
+		"expires_at": offer.ExpiresAt,
+		"distance":   offer.Distance,
+		"title":      serviceRequest.Title,
+	}
+
+	if chatHub != nil {
+		chatHub.SendToUser(worker.UserID, &ws.Message{
+			Type:      "job_offer",
+			Data:      data,
+			Timestamp: time.Now(),
+		})
+	}
+
+	if err := SendLocalizedPushNotification(worker.UserID, "notification.job_offer", i18n.Vars{"title": serviceRequest.Title}, "job_offer", data); err != nil {
+		log.Printf("⚠️ Failed to send job offer notification to worker %d: %v", worker.ID, err)
+	}
+}
+
+// ProcessExpiredDispatchOffers expires timed-out offers and cascades each request to the next worker
+func ProcessExpiredDispatchOffers() {
+	requestIDs, err := services.NewMatchingService().ExpireStaleOffers()
+	if err != nil {
+		log.Printf("❌ Failed to expire dispatch offers: %v", err)
+		return
+	}
+
+	for _, requestID := range requestIDs {
+		var serviceRequest models.CustomerServiceRequest
+		if err := database.DB.First(&serviceRequest, requestID).Error; err != nil {
+			log.Printf("❌ Failed to load request %d for dispatch cascade: %v", requestID, err)
+			continue
+		}
+
+		// Only cascade requests that are still waiting for a worker
+		if serviceRequest.Status != models.RequestStatusPending || serviceRequest.AssignedWorkerID != nil {
+			continue
+		}
+
+		log.Printf("⏰ Offer for request %d timed out, cascading to next worker", requestID)
+		dispatchServiceRequest(serviceRequest)
+	}
+}
+
+// ProcessWorkerNoShows takes requests away from accepted workers past their no-show window and
+// offers them to other workers
+func ProcessWorkerNoShows() {
+	requests, err := services.NewNoShowService().FlagDue(time.Now())
+	if err != nil {
+		log.Printf("❌ Failed to check worker no-shows: %v", err)
+		return
+	}
+
+	for _, serviceRequest := range requests {
+		publishRequestEvent("request_no_show", serviceRequest)
+		startDispatch(serviceRequest)
+	}
+}
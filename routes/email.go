@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// EmailHandler serves email address verification and password reset by email
+type EmailHandler struct {
+	codes      *services.EmailCodeService
+	jwt        *services.JWTService
+	loginGuard *services.LoginGuardService
+}
+
+// RegisterEmailRoutes registers email and password reset routes under the auth group
+func RegisterEmailRoutes(router *gin.RouterGroup, jwt *services.JWTService, loginGuard *services.LoginGuardService) {
+	h := &EmailHandler{codes: services.NewEmailCodeService(), jwt: jwt, loginGuard: loginGuard}
+
+	// Signed-in users: attach or replace their email address
+	email := router.Group("/email")
+	email.Use(middleware.AuthMiddleware())
+	{
+		email.POST("/send-code", h.sendVerificationCode)
+		email.POST("/verify", h.verifyEmail)
+	}
+
+	// Signed-out users: reset a forgotten password through their verified address
+	router.POST("/password/forgot", h.forgotPassword)
+	router.POST("/password/reset", h.resetPassword)
+}
+
+// sendVerificationCode emails a code to the address the user wants to attach
+func (h *EmailHandler) sendVerificationCode(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req struct {
+		Email string `json:"email" binding:"required,email,max=255"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	email := services.NormalizeEmail(req.Email)
+
+	if user.IsEmailVerified() && *user.Email == email {
+		apierror.Abort(c, apierror.Conflict("Email address is already verified"))
+		return
+	}
+	if emailTaken(email, user.ID) {
+		apierror.Abort(c, apierror.Conflict("An account with this email address already exists"))
+		return
+	}
+
+	if err := h.codes.SendCode(user, email, models.EmailVerificationConfirm, i18n.Locale(c)); err != nil {
+		abortOTPError(c, err, "Failed to send verification email")
+		return
+	}
+	respondEmailCodeSent(c, email)
+}
+
+// verifyEmail attaches the address once its code is confirmed
+func (h *EmailHandler) verifyEmail(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req struct {
+		Email   string `json:"email" binding:"required,email,max=255"`
+		OTPCode string `json:"otp_code" binding:"required,numeric,len=6"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	email := services.NormalizeEmail(req.Email)
+
+	verification, err := h.codes.VerifyCode(email, models.EmailVerificationConfirm, req.OTPCode)
+	if err == nil && verification.UserID != user.ID {
+		// Codes are bound to the account that requested them
+		err = services.ErrOTPInvalid
+	}
+	if err != nil {
+		abortOTPError(c, err, "Failed to verify email address")
+		return
+	}
+
+	// The address may have been attached to another account since the code was sent
+	if emailTaken(email, user.ID) {
+		apierror.Abort(c, apierror.Conflict("An account with this email address already exists"))
+		return
+	}
+
+	before := user
+	now := time.Now()
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": &now,
+	}).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to update email address", err))
+		return
+	}
+	user.Email = &email
+	user.EmailVerifiedAt = &now
+	middleware.RecordAuditChange(c, "users", user.ID, before, user)
+
+	log.Printf("✅ Email verified for user %d", user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Email address verified",
+		"data":    gin.H{"user": serializers.User(user)},
+	})
+}
+
+// forgotPassword emails a reset code when the address belongs to an account. The response is
+// the same either way so it cannot be used to find out which addresses are registered.
+func (h *EmailHandler) forgotPassword(c *gin.Context) {
+	var req struct {
+		Email string `json:"email" binding:"required,email,max=255"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	email := services.NormalizeEmail(req.Email)
+
+	var user models.User
+	err := database.DB.Where("email = ? AND email_verified_at IS NOT NULL AND is_active = ?", email, true).First(&user).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		log.Printf("🔑 Password reset requested for unknown email")
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to process password reset", err))
+		return
+	default:
+		var cooldown *services.OTPCooldownError
+		if err := h.codes.SendCode(user, email, models.EmailVerificationPasswordReset, i18n.Locale(c)); err != nil && !errors.As(err, &cooldown) {
+			log.Printf("❌ Failed to send password reset code to user %d: %v", user.ID, err)
+		}
+	}
+
+	respondEmailCodeSent(c, email)
+}
+
+// resetPassword sets a new password once the emailed reset code is confirmed and signs the
+// user out everywhere
+func (h *EmailHandler) resetPassword(c *gin.Context) {
+	var req struct {
+		Email       string `json:"email" binding:"required,email,max=255"`
+		OTPCode     string `json:"otp_code" binding:"required,numeric,len=6"`
+		NewPassword string `json:"new_password" binding:"required,min=8,max=128"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	email := services.NormalizeEmail(req.Email)
+
+	if isStrong, problems := middleware.ValidatePasswordStrength(req.NewPassword); !isStrong {
+		apierror.Abort(c, apierror.Validation("New password does not meet security requirements").WithDetails(problems))
+		return
+	}
+
+	verification, err := h.codes.VerifyCode(email, models.EmailVerificationPasswordReset, req.OTPCode)
+	if err != nil {
+		abortOTPError(c, err, "Failed to reset password")
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Where("id = ? AND email = ?", verification.UserID, email).First(&user).Error; err != nil {
+		// The address was removed from the account after the code was sent
+		validation.Fail(c, "otp_code", "otp_invalid", "")
+		return
+	}
+
+	hashedPassword, err := h.jwt.HashPassword(req.NewPassword)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to process new password", err))
+		return
+	}
+	if err := database.DB.Model(&user).Update("password_hash", hashedPassword).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to update password", err))
+		return
+	}
+
+	if err := h.jwt.RevokeAllUserTokens(user.ID); err != nil {
+		log.Printf("⚠️ Failed to revoke tokens after password reset: %v", err)
+	}
+	if _, err := h.loginGuard.Unlock(user.PhoneNumber); err != nil {
+		log.Printf("⚠️ Failed to clear sign-in lockout after password reset: %v", err)
+	}
+
+	log.Printf("✅ Password reset by email for user %d", user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Password reset successfully. Please sign in again.",
+	})
+}
+
+// emailTaken reports whether another account than exceptUserID uses email
+func emailTaken(email string, exceptUserID uint) bool {
+	var count int64
+	database.DB.Model(&models.User{}).Where("email = ? AND id <> ?", email, exceptUserID).Count(&count)
+	return count > 0
+}
+
+// respondEmailCodeSent confirms delivery without echoing the code
+func respondEmailCodeSent(c *gin.Context, email string) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "If the address can receive it, a code has been emailed",
+		"data": gin.H{
+			"email":        email,
+			"expires_in":   int(services.OTPTTL.Seconds()),
+			"resend_after": int(services.OTPResendAfter.Seconds()),
+			"max_attempts": services.OTPMaxAttempts,
+		},
+	})
+}
@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/serializers"
+	"repair-service-server/validation"
+)
+
+// maxRequestedFields caps the paths one fields parameter may list
+const maxRequestedFields = 50
+
+// requestedFields reads the fields query parameter that trims each item of a list response to the
+// listed dot-separated paths. A nil fieldset means the client wants every field.
+func requestedFields(c *gin.Context) (serializers.Fieldset, bool) {
+	raw := c.Query("fields")
+	if raw == "" {
+		return nil, true
+	}
+	if strings.Count(raw, ",") >= maxRequestedFields {
+		validation.Fail(c, "fields", "max", "50")
+		return nil, false
+	}
+	return serializers.ParseFields(raw), true
+}
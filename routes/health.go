@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/cache"
+	"repair-service-server/database"
+	"repair-service-server/jobs"
+)
+
+// Dependency health states reported by the readiness endpoint
+const (
+	healthOK            = "ok"
+	healthDown          = "down"
+	healthDegraded      = "degraded"
+	healthNotConfigured = "not_configured"
+)
+
+// dependencyCheck is the readiness result for a single dependency
+type dependencyCheck struct {
+	Status   string      `json:"status"`
+	Critical bool        `json:"critical"`
+	Latency  string      `json:"latency,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// Liveness reports that the process is up and serving requests
+func Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": healthOK,
+		"time":   time.Now().UTC(),
+	})
+}
+
+// Readiness checks every dependency and returns 503 when a critical one is down
+func Readiness(c *gin.Context) {
+	checks := map[string]dependencyCheck{
+		"database":   checkDatabase(c.Request.Context()),
+		"redis":      checkRedis(c.Request.Context()),
+		"cloudinary": checkCloudinary(),
+		"jobs":       checkJobs(),
+	}
+
+	status := healthOK
+	code := http.StatusOK
+	for _, check := range checks {
+		if check.Status != healthDown && check.Status != healthDegraded {
+			continue
+		}
+		if check.Critical {
+			status = healthDown
+			code = http.StatusServiceUnavailable
+			break
+		}
+		status = healthDegraded
+	}
+
+	c.JSON(code, gin.H{
+		"status": status,
+		"checks": checks,
+		"time":   time.Now().UTC(),
+	})
+}
+
+func checkDatabase(ctx context.Context) dependencyCheck {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := database.Ping(ctx); err != nil {
+		return dependencyCheck{Status: healthDown, Critical: true, Error: err.Error()}
+	}
+	return dependencyCheck{Status: healthOK, Critical: true, Latency: time.Since(start).String()}
+}
+
+// checkRedis pings the cache store when Redis is configured; the cache falls back to memory, so it is not critical
+func checkRedis(ctx context.Context) dependencyCheck {
+	if os.Getenv("REDIS_URL") == "" {
+		return dependencyCheck{Status: healthNotConfigured}
+	}
+	store := cache.Default()
+	if store.Name() != "redis" {
+		return dependencyCheck{Status: healthDegraded, Error: "REDIS_URL is set but the cache fell back to memory"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	if err := store.Ping(ctx); err != nil {
+		return dependencyCheck{Status: healthDegraded, Error: err.Error()}
+	}
+	return dependencyCheck{Status: healthOK, Latency: time.Since(start).String()}
+}
+
+// checkCloudinary verifies media uploads are configured; without it uploads fail but the API still serves
+func checkCloudinary() dependencyCheck {
+	for _, key := range []string{"CLOUDINARY_CLOUD_NAME", "CLOUDINARY_API_KEY", "CLOUDINARY_API_SECRET"} {
+		if os.Getenv(key) == "" {
+			return dependencyCheck{Status: healthDegraded, Error: key + " is not set"}
+		}
+	}
+	return dependencyCheck{Status: healthOK}
+}
+
+// checkJobs reports stale background job heartbeats
+func checkJobs() dependencyCheck {
+	heartbeats := jobs.Heartbeats()
+	for _, hb := range heartbeats {
+		if !hb.Healthy {
+			return dependencyCheck{Status: healthDegraded, Error: hb.Name + " job missed its heartbeat", Details: heartbeats}
+		}
+	}
+	return dependencyCheck{Status: healthOK, Details: heartbeats}
+}
@@ -0,0 +1,114 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// getJobCodes shows the customer the codes they read out to the worker at the start and end
+// of the job
+func getJobCodes(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Where("id = ? AND customer_id = ?", c.Param("id"), userID).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	if serviceRequest.AssignedWorkerID == nil {
+		apierror.Abort(c, apierror.Validation("Codes are available once a worker accepts the request"))
+		return
+	}
+
+	codes := services.NewJobCodeService()
+	start, err := codes.Get(serviceRequest.ID, models.JobCodeStart)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load job codes", err))
+		return
+	}
+	completion, err := codes.Get(serviceRequest.ID, models.JobCodeCompletion)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load job codes", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"start_code":             start.Code,
+			"start_verified_at":      start.VerifiedAt,
+			"completion_code":        completion.Code,
+			"completion_verified_at": completion.VerifiedAt,
+		},
+	})
+}
+
+// OverrideJobCode lets an admin confirm a stage without its code, e.g. when the customer cannot
+// read it out or the worker burned it with wrong guesses
+func OverrideJobCode(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req struct {
+		Stage  string `json:"stage" binding:"required,oneof=start completion"`
+		Reason string `json:"reason" binding:"required,max=500"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.First(&serviceRequest, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	if !serviceRequest.Status.IsActive() {
+		apierror.Abort(c, apierror.Validation("Service request is not active"))
+		return
+	}
+
+	before, after, err := services.NewJobCodeService().Override(serviceRequest.ID, models.JobCodeStage(req.Stage), adminID, req.Reason)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to override job code", err))
+		return
+	}
+	middleware.RecordAuditChange(c, "job_codes", after.ID, before, after)
+
+	log.Printf("🔓 Admin %d overrode the %s code of service request %d: %s", adminID, req.Stage, serviceRequest.ID, req.Reason)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Job code overridden",
+		"data":    after,
+	})
+}
+
+// verifyJobCode checks the code the worker entered for stage, aborting with a field error on
+// field when it is missing or wrong
+func verifyJobCode(c *gin.Context, serviceRequest models.CustomerServiceRequest, workerID uint, stage models.JobCodeStage, field, code string) bool {
+	_, err := services.NewJobCodeService().Verify(serviceRequest.ID, stage, code)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, services.ErrJobCodeRequired):
+		validation.Fail(c, field, "required", "")
+	case errors.Is(err, services.ErrOTPTooManyAttempts):
+		log.Printf("🚫 Worker %d burned the %s code of service request %d", workerID, stage, serviceRequest.ID)
+		apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "Too many incorrect codes. Please contact support."))
+	case errors.Is(err, services.ErrOTPInvalid):
+		log.Printf("⚠️ Worker %d entered a wrong %s code for service request %d", workerID, stage, serviceRequest.ID)
+		validation.Fail(c, field, "otp_invalid", "")
+	default:
+		apierror.Abort(c, apierror.Internal("Failed to verify job code", err))
+	}
+	return false
+}
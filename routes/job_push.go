@@ -0,0 +1,115 @@
+package routes
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+)
+
+// jobPushWindow is how long after a job push further broadcast requests wait, so a busy
+// category sends a worker one digest instead of a push per request
+const jobPushWindow = 2 * time.Minute
+
+// digestedJob is a broadcast request waiting in a worker's digest
+type digestedJob struct {
+	requestID uint
+	title     string
+	priority  string
+	distance  float64
+}
+
+var (
+	jobPushMu sync.Mutex
+	// jobDigests holds the requests waiting for each worker's digest, by worker user ID. A worker
+	// has an entry while their push window is open.
+	jobDigests = make(map[uint][]digestedJob)
+)
+
+// pushBroadcastRequest tells a worker about a broadcast request near them. Urgent requests are
+// pushed right away. Others are pushed right away unless the worker got a job push in the last
+// jobPushWindow, in which case they are collected and sent together when the window ends.
+func pushBroadcastRequest(worker models.WorkerProfile, request models.CustomerServiceRequest, distance float64) {
+	job := digestedJob{requestID: request.ID, title: request.Title, priority: request.Priority, distance: distance}
+	if request.Priority != "urgent" {
+		jobPushMu.Lock()
+		if pending, open := jobDigests[worker.UserID]; open {
+			jobDigests[worker.UserID] = append(pending, job)
+			jobPushMu.Unlock()
+			return
+		}
+		jobDigests[worker.UserID] = nil
+		jobPushMu.Unlock()
+		time.AfterFunc(jobPushWindow, func() { flushJobDigest(worker.UserID) })
+	}
+	sendJobPush(worker.UserID, job)
+}
+
+// flushJobDigest closes the worker's push window and sends what it collected, leaving out
+// requests another worker took meanwhile
+func flushJobDigest(workerUserID uint) {
+	jobPushMu.Lock()
+	pending := jobDigests[workerUserID]
+	delete(jobDigests, workerUserID)
+	jobPushMu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	requestIDs := make([]uint, 0, len(pending))
+	for _, job := range pending {
+		requestIDs = append(requestIDs, job.requestID)
+	}
+	var open []uint
+	if err := database.DB.Model(&models.CustomerServiceRequest{}).
+		Where("id IN ? AND status = ?", requestIDs, models.RequestStatusBroadcast).
+		Order("id").
+		Pluck("id", &open).Error; err != nil {
+		log.Printf("⚠️ Failed to check digested requests for worker %d: %v", workerUserID, err)
+		return
+	}
+
+	switch len(open) {
+	case 0:
+		return
+	case 1:
+		for _, job := range pending {
+			if job.requestID == open[0] {
+				sendJobPush(workerUserID, job)
+			}
+		}
+	default:
+		data := map[string]interface{}{
+			"type":                "new_jobs",
+			"screen":              "available_requests",
+			"service_request_ids": open,
+		}
+		if err := SendLocalizedPushNotification(workerUserID, "notification.new_jobs", i18n.Vars{"count": len(open)}, "new_jobs", data); err != nil {
+			log.Printf("❌ Failed to send job digest to user %d: %v", workerUserID, err)
+		}
+	}
+}
+
+// sendJobPush pushes one broadcast request to a worker. Urgent requests go out as urgent_job, which
+// reaches workers during quiet hours on the louder urgent channel.
+func sendJobPush(workerUserID uint, job digestedJob) {
+	notificationType := "new_job"
+	if job.priority == "urgent" {
+		notificationType = "urgent_job"
+	}
+	data := map[string]interface{}{
+		"type":                notificationType,
+		"screen":              "available_requests",
+		"service_request_id":  job.requestID,
+		"service_request_ids": []uint{job.requestID},
+		"priority":            job.priority,
+	}
+	vars := i18n.Vars{"title": job.title, "distance": fmt.Sprintf("%.1f", job.distance)}
+	if err := SendLocalizedPushNotification(workerUserID, "notification."+notificationType, vars, notificationType, data); err != nil {
+		log.Printf("❌ Failed to send job push for request %d to user %d: %v", job.requestID, workerUserID, err)
+	}
+}
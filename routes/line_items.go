@@ -0,0 +1,334 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+	ws "repair-service-server/websocket"
+)
+
+// errLineItemDecided aborts a decision on a line item that is no longer pending
+var errLineItemDecided = errors.New("line item already decided")
+
+// AddLineItem lets the assigned worker add a part they bought to an in progress request. The
+// customer is asked to approve it by push notification and in the request's chat.
+func AddLineItem(c *gin.Context) {
+	var req struct {
+		Description string  `form:"description" binding:"required,max=255"`
+		Quantity    float64 `form:"quantity" binding:"required,gt=0,lte=10000"`
+		UnitPrice   float64 `form:"unit_price" binding:"gte=0,lte=1000000"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+
+	workerProfile, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+	if serviceRequest.Status != models.RequestStatusInProgress {
+		apierror.Abort(c, apierror.Validation("Service request is not in progress"))
+		return
+	}
+
+	item := models.RequestLineItem{
+		ServiceRequestID: serviceRequest.ID,
+		WorkerID:         workerProfile.ID,
+		Description:      req.Description,
+		Quantity:         req.Quantity,
+		UnitPrice:        money.FromFloat(req.UnitPrice),
+		Status:           models.LineItemPending,
+	}
+
+	if receipt, _ := c.FormFile("receipt_photo"); receipt != nil {
+		media := services.NewMediaService()
+		prepared, ok := prepareMedia(c, media, models.MediaPurposeReceiptPhoto, "receipt_photo", receipt)
+		if !ok {
+			return
+		}
+		uploaded, err := media.Store(workerProfile.UserID, prepared, fmt.Sprintf("receipts/%d", serviceRequest.ID))
+		if err != nil {
+			log.Printf("❌ Receipt photo upload failed: %v", err)
+			apierror.Abort(c, apierror.Internal("Failed to upload receipt photo", nil))
+			return
+		}
+		item.ReceiptMediaID = &uploaded.ID
+	}
+
+	if err := database.DB.Create(&item).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to add line item", err))
+		return
+	}
+
+	currency := services.NewRegionService().ForRequest(serviceRequest).Currency
+	text := fmt.Sprintf("🧾 Added %s (%g × %s = %s). Please approve or reject it.",
+		item.Description, item.Quantity, i18n.Money(i18n.English, item.UnitPrice, currency), i18n.Money(i18n.English, item.Total(), currency))
+	postLineItemChatMessage(serviceRequest.ID, workerProfile.UserID, "worker", text, item)
+	if err := SendLocalizedPushNotification(serviceRequest.CustomerID, "notification.line_item_added", i18n.Vars{
+		"description": item.Description,
+		"quantity":    item.Quantity,
+		"unit_price":  item.UnitPrice,
+		"total":       item.Total(),
+		"currency":    currency,
+	}, "line_item_added", map[string]interface{}{
+		"action":             "line_item_approval",
+		"service_request_id": serviceRequest.ID,
+		"line_item_id":       item.ID,
+	}); err != nil {
+		log.Printf("⚠️ Failed to send line item notification: %v", err)
+	}
+
+	log.Printf("🧾 Worker %d added line item %d to service request %d", workerProfile.ID, item.ID, serviceRequest.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Line item added, waiting for customer approval",
+		"data":    item,
+	})
+}
+
+// DeleteLineItem lets the worker withdraw a line item the customer has not decided on yet
+func DeleteLineItem(c *gin.Context) {
+	_, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND service_request_id = ? AND status = ?", c.Param("itemId"), serviceRequest.ID, models.LineItemPending).
+		Delete(&models.RequestLineItem{})
+	if result.Error != nil {
+		apierror.Abort(c, apierror.Internal("Failed to delete line item", result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Abort(c, apierror.NotFound("Pending line item not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Line item deleted",
+	})
+}
+
+// getLineItems lists a request's line items for its customer or assigned worker
+func getLineItems(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Preload("AssignedWorker").First(&serviceRequest, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	isWorker := serviceRequest.AssignedWorker != nil && serviceRequest.AssignedWorker.UserID == userID
+	if serviceRequest.CustomerID != userID && !isWorker {
+		apierror.Abort(c, apierror.Forbidden("Access denied"))
+		return
+	}
+
+	var items []models.RequestLineItem
+	if err := database.DB.Where("service_request_id = ?", serviceRequest.ID).Order("id").Find(&items).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load line items", err))
+		return
+	}
+
+	var approvedTotal, pendingTotal money.Amount
+	for _, item := range items {
+		switch item.Status {
+		case models.LineItemApproved:
+			approvedTotal += item.Total()
+		case models.LineItemPending:
+			pendingTotal += item.Total()
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"items":          items,
+			"approved_total": approvedTotal,
+			"pending_total":  pendingTotal,
+		},
+	})
+}
+
+// approveLineItem adds a part to the customer's bill
+func approveLineItem(c *gin.Context) {
+	decideLineItem(c, models.LineItemApproved, "")
+}
+
+// rejectLineItem leaves a part off the customer's bill
+func rejectLineItem(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason" binding:"max=255"`
+	}
+	if c.Request.ContentLength > 0 && !validation.BindJSON(c, &req) {
+		return
+	}
+	decideLineItem(c, models.LineItemRejected, req.Reason)
+}
+
+// decideLineItem records the customer's decision on a pending line item and tells the worker
+func decideLineItem(c *gin.Context, status models.LineItemStatus, reason string) {
+	userID := c.GetUint("user_id")
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Preload("AssignedWorker").Where("id = ? AND customer_id = ?", c.Param("id"), userID).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	if serviceRequest.Status != models.RequestStatusInProgress || serviceRequest.AssignedWorker == nil {
+		apierror.Abort(c, apierror.Validation("Service request is not in progress"))
+		return
+	}
+
+	var item models.RequestLineItem
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the request so a decision cannot land while the job is being completed
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&serviceRequest, serviceRequest.ID).Error; err != nil {
+			return err
+		}
+		if serviceRequest.Status != models.RequestStatusInProgress {
+			return errRequestNotInProgress
+		}
+		if err := tx.Where("id = ? AND service_request_id = ?", c.Param("itemId"), serviceRequest.ID).First(&item).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&item).Where("status = ?", models.LineItemPending).Updates(map[string]interface{}{
+			"status":           status,
+			"decided_at":       &now,
+			"rejection_reason": reason,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errLineItemDecided
+		}
+		item.Status = status
+		item.DecidedAt = &now
+		item.RejectionReason = reason
+		return nil
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("Line item not found"))
+		return
+	case errors.Is(err, errRequestNotInProgress):
+		apierror.Abort(c, apierror.Validation("Service request is not in progress"))
+		return
+	case errors.Is(err, errLineItemDecided):
+		apierror.Abort(c, apierror.Conflict("Line item has already been decided"))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to update line item", err))
+		return
+	}
+
+	currency := services.NewRegionService().ForRequest(serviceRequest).Currency
+	text := fmt.Sprintf("✅ Approved %s (%s).", item.Description, i18n.Money(i18n.English, item.Total(), currency))
+	if status == models.LineItemRejected {
+		text = fmt.Sprintf("❌ Rejected %s (%s).", item.Description, i18n.Money(i18n.English, item.Total(), currency))
+		if reason != "" {
+			text += " " + reason
+		}
+	}
+	postLineItemChatMessage(serviceRequest.ID, userID, "customer", text, item)
+	if status == models.LineItemApproved {
+		postLineItemPrice(serviceRequest)
+	}
+	if err := SendLocalizedPushNotification(serviceRequest.AssignedWorker.UserID, "notification.line_item_"+string(status), i18n.Vars{
+		"description": item.Description,
+		"total":       item.Total(),
+		"reason":      reason,
+		"currency":    currency,
+	}, "line_item_decided", map[string]interface{}{
+		"service_request_id": serviceRequest.ID,
+		"line_item_id":       item.ID,
+		"status":             item.Status,
+	}); err != nil {
+		log.Printf("⚠️ Failed to send line item decision notification: %v", err)
+	}
+
+	log.Printf("🧾 Customer %d %s line item %d on service request %d", userID, status, item.ID, serviceRequest.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Line item " + string(status),
+		"data":    item,
+	})
+}
+
+// postLineItemChatMessage records a line item update in the request's chat, when it has one, so
+// both sides see it in the conversation
+func postLineItemChatMessage(requestID, senderID uint, senderType, text string, item models.RequestLineItem) {
+	var chatRoom models.ChatRoom
+	if err := database.DB.Where("service_request_id = ?", requestID).First(&chatRoom).Error; err != nil {
+		return
+	}
+
+	message := models.ChatMessage{
+		ChatRoomID:  chatRoom.ID,
+		SenderID:    senderID,
+		SenderType:  senderType,
+		Content:     text,
+		MessageType: "line_item",
+	}
+	if err := database.DB.Create(&message).Error; err != nil {
+		log.Printf("⚠️ Failed to post line item message to chat room %d: %v", chatRoom.ID, err)
+		return
+	}
+
+	now := time.Now()
+	database.DB.Model(&chatRoom).Updates(map[string]interface{}{
+		"last_message_at":   &now,
+		"last_message_text": text,
+		"unread_count":      gorm.Expr("unread_count + ?", 1),
+	})
+
+	if chatHub != nil {
+		chatHub.SendToChatRoom(chatRoom.ID, &ws.Message{
+			Type:       "line_item",
+			ChatRoomID: chatRoom.ID,
+			SenderID:   senderID,
+			SenderType: senderType,
+			Content:    text,
+			Timestamp:  now,
+			Data: gin.H{
+				"message":   serializers.ChatMessage(message),
+				"line_item": item,
+			},
+		}, senderID)
+	}
+}
+
+// postLineItemPrice records the request's price with its approved parts in the request's chat
+func postLineItemPrice(serviceRequest models.CustomerServiceRequest) {
+	_, partsTotal, err := services.ApprovedLineItems(database.DB, serviceRequest.ID)
+	if err != nil {
+		log.Printf("⚠️ Failed to total approved line items of service request %d: %v", serviceRequest.ID, err)
+		return
+	}
+	total := partsTotal
+	if price := serviceRequest.Price(); price != nil {
+		total += *price
+	}
+	postRequestSystemMessage(serviceRequest, systemEventPriceUpdated,
+		fmt.Sprintf("💰 Price updated to %s with approved parts.", requestMoney(serviceRequest, total)))
+}
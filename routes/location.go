@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
 	"repair-service-server/utils"
@@ -35,20 +36,20 @@ func updateWorkerLocation(c *gin.Context) {
 	
 	var req models.LocationUpdateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 	
 	// Validate location coordinates
 	if !utils.IsLocationValid(req.Latitude, req.Longitude) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location coordinates"})
+		apierror.Abort(c, apierror.Validation("Invalid location coordinates"))
 		return
 	}
 	
 	// Get worker profile
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -61,10 +62,13 @@ func updateWorkerLocation(c *gin.Context) {
 	workerProfile.IsAvailable = req.IsAvailable
 	
 	if err := database.DB.Save(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update location"})
+		apierror.Abort(c, apierror.Internal("Failed to update location", err))
 		return
 	}
 	
+	// Keep customers waiting on this worker up to date
+	go refreshWorkerETAs(workerProfile)
+	
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Location updated successfully",
@@ -90,11 +94,7 @@ func toggleWorkerAvailability(c *gin.Context) {
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Printf("❌ JSON binding error: %v", err)
 		log.Printf("🔍 Request body: %s", string(body))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request format",
-			"message": err.Error(),
-			"expected": "JSON with 'is_available' boolean field",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid request format").WithDetails(gin.H{"error": err.Error(), "expected": "JSON with 'is_available' boolean field"}))
 		return
 	}
 	
@@ -105,7 +105,7 @@ func toggleWorkerAvailability(c *gin.Context) {
 	// Get worker profile
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -113,7 +113,7 @@ func toggleWorkerAvailability(c *gin.Context) {
 	workerProfile.IsAvailable = req.IsAvailable
 	
 	if err := database.DB.Save(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability"})
+		apierror.Abort(c, apierror.Internal("Failed to update availability", err))
 		return
 	}
 	
@@ -133,14 +133,14 @@ func getNearbyWorkers(c *gin.Context) {
 	radiusStr := c.Query("radius")
 	
 	if latStr == "" || lngStr == "" || categoryStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing required parameters: lat, lng, category"})
+		apierror.Abort(c, apierror.Validation("Missing required parameters: lat, lng, category"))
 		return
 	}
 	
 	// Parse coordinates
 	lat, lng, err := parseCoordinates(latStr, lngStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid coordinates"})
+		apierror.Abort(c, apierror.Validation("Invalid coordinates"))
 		return
 	}
 	
@@ -157,7 +157,7 @@ func getNearbyWorkers(c *gin.Context) {
 	
 	// Validate radius
 	if !utils.ValidateBroadcastRadius(radius) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid broadcast radius"})
+		apierror.Abort(c, apierror.Validation("Invalid broadcast radius"))
 		return
 	}
 	
@@ -165,7 +165,7 @@ func getNearbyWorkers(c *gin.Context) {
 	location := utils.Location{Latitude: lat, Longitude: lng}
 	workers, err := utils.FindNearbyWorkers(database.DB, location, radius, category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find nearby workers"})
+		apierror.Abort(c, apierror.Internal("Failed to find nearby workers", err))
 		return
 	}
 	
@@ -211,7 +211,7 @@ func getCurrentLocation(c *gin.Context) {
 	
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/services"
+)
+
+// RegisterLoyaltyRoutes registers the route customers use to follow their loyalty points and tier
+func RegisterLoyaltyRoutes(router *gin.RouterGroup) {
+	router.GET("/loyalty", getLoyalty)
+}
+
+// getLoyalty returns the current customer's points, tier, streak, benefits and what the next tier
+// needs
+func getLoyalty(c *gin.Context) {
+	status, err := services.NewLoyaltyService().Status(c.GetUint("user_id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch loyalty", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    status,
+	})
+}
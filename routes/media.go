@@ -0,0 +1,100 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RegisterMediaRoutes registers the routes that hand out links to stored uploads
+func RegisterMediaRoutes(router *gin.RouterGroup) {
+	router.GET("/media/:id/url", getMediaURL)
+}
+
+// getMediaURL returns a short-lived signed URL to a private upload, such as an ID card photo or a
+// receipt, for the users allowed to see it. Everyone else gets a 404.
+func getMediaURL(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	uploadID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid media ID"))
+		return
+	}
+
+	var upload models.MediaUpload
+	if err := database.DB.Where("deleted_at IS NULL").First(&upload, uploadID).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Media not found"))
+		return
+	}
+	media := services.NewMediaService()
+	allowed, err := media.CanView(user, upload)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to check media access", err))
+		return
+	}
+	if !allowed {
+		apierror.Abort(c, apierror.NotFound("Media not found"))
+		return
+	}
+
+	url, expiresAt, err := media.SignedURL(upload)
+	if err != nil {
+		apierror.Abort(c, apierror.Unavailable("Failed to sign the media URL", err))
+		return
+	}
+	if upload.Private {
+		log.Printf("🔏 User %d was issued a signed URL for %s media %d", user.ID, upload.Purpose, upload.ID)
+	}
+
+	data := gin.H{"url": url, "mime_type": upload.MIMEType}
+	if !expiresAt.IsZero() {
+		data["expires_at"] = expiresAt
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// MigratePrivateMedia moves ID card photos and receipts still stored as public files to private
+// storage. It can be run again to retry files that failed.
+func MigratePrivateMedia(c *gin.Context) {
+	moved, err := services.NewMediaService().MigratePrivateDocuments()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to migrate private media", err))
+		return
+	}
+	log.Printf("🔏 Admin %d moved %d documents to private storage", c.GetUint("user_id"), moved)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"moved": moved},
+	})
+}
+
+// prepareMedia checks the file uploaded as field for purpose and aborts with an error on field
+// when it is refused
+func prepareMedia(c *gin.Context, media *services.MediaService, purpose models.MediaPurpose, field string, header *multipart.FileHeader) (*services.PreparedMedia, bool) {
+	prepared, err := media.Prepare(purpose, header)
+	switch {
+	case err == nil:
+		return prepared, true
+	case errors.Is(err, services.ErrMediaTooLarge), errors.Is(err, services.ErrMediaType):
+		message := services.MediaLimitMessage(purpose)
+		apierror.Abort(c, apierror.Validation(message).WithDetails([]validation.FieldError{{Field: field, Rule: "file", Message: message}}))
+	case errors.Is(err, services.ErrMediaInfected):
+		apierror.Abort(c, apierror.Unprocessable(err.Error()).WithDetails([]validation.FieldError{{Field: field, Rule: "file", Message: err.Error()}}))
+	default:
+		apierror.Abort(c, apierror.Unavailable("Failed to check the uploaded file. Please try again.", err))
+	}
+	return nil, false
+}
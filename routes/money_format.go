@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/i18n"
+	"repair-service-server/money"
+	"repair-service-server/services"
+)
+
+// formatAmounts writes amounts in currency with the digit grouping and decimal mark of the
+// request's language, for clients that display them as they are
+func formatAmounts(c *gin.Context, currency string, amounts map[string]money.Amount) gin.H {
+	lang := i18n.Locale(c)
+	formatted := make(gin.H, len(amounts))
+	for key, amount := range amounts {
+		formatted[key] = i18n.Money(lang, amount, currency)
+	}
+	return formatted
+}
+
+// formatTotals formats the totals of an earnings statement or yearly summary
+func formatTotals(c *gin.Context, currency string, t services.EarningsTotals) gin.H {
+	return formatAmounts(c, currency, map[string]money.Amount{
+		"gross":      t.Gross,
+		"parts":      t.Parts,
+		"commission": t.Commission,
+		"refunded":   t.Refunded,
+		"tips":       t.Tips,
+		"net":        t.Net,
+	})
+}
@@ -8,75 +8,29 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// getUserPreferredLanguage tries to read the user's preferred language.
-// Falls back to "en" if not set or on any error (including missing column).
-func getUserPreferredLanguage(userID uint) string {
-    var lang string
-    err := database.DB.Raw("SELECT COALESCE(preferred_language, '') FROM users WHERE id = ?", userID).Scan(&lang).Error
-    if err != nil || lang == "" {
-        if err != nil {
-            log.Printf("⚠️ Could not read preferred_language for user %d (defaulting to en): %v", userID, err)
-        }
-        return "en"
-    }
-    return lang
-}
-
-// getLocalizedStatusMessage returns localized title/body/type for a status
-func getLocalizedStatusMessage(status string, lang string) (string, string, string) {
-    switch lang {
-    case "fr", "ar", "zh":
-    default:
-        lang = "en"
-    }
-
-    type message struct{ title, body, ntype string }
-    msgs := map[string]map[string]message{
-        "en": {
-            "accepted":   {"Service Request Accepted", "A professional has accepted your service request and is on the way!", "booking_accepted"},
-            "in_progress": {"Work Started", "Your service professional has started working on your request.", "booking_in_progress"},
-            "completed":   {"Service Completed", "Your service request has been completed. Please rate your experience.", "booking_completed"},
-            "cancelled":   {"Service Cancelled", "Your service request has been cancelled.", "booking_cancelled"},
-            "default":     {"Service Update", "Your service request status has been updated.", "system"},
-        },
-        "fr": {
-            "accepted":   {"Demande acceptée", "Un professionnel a accepté votre demande et arrive !", "booking_accepted"},
-            "in_progress": {"Travaux commencés", "Votre professionnel a commencé à travailler sur votre demande.", "booking_in_progress"},
-            "completed":   {"Service terminé", "Votre demande est terminée. Merci d'évaluer votre expérience.", "booking_completed"},
-            "cancelled":   {"Service annulé", "Votre demande de service a été annulée.", "booking_cancelled"},
-            "default":     {"Mise à jour du service", "Le statut de votre demande a été mis à jour.", "system"},
-        },
-        "ar": {
-            "accepted":   {"تم قبول الطلب", "تم قبول طلب خدمتك والمهني في الطريق!", "booking_accepted"},
-            "in_progress": {"بدأ العمل", "بدأ المهني العمل على طلبك.", "booking_in_progress"},
-            "completed":   {"اكتملت الخدمة", "تم إكمال طلب خدمتك. يرجى تقييم تجربتك.", "booking_completed"},
-            "cancelled":   {"تم إلغاء الخدمة", "تم إلغاء طلب خدمتك.", "booking_cancelled"},
-            "default":     {"تحديث الخدمة", "تم تحديث حالة طلب خدمتك.", "system"},
-        },
-        "zh": {
-            "accepted":   {"服务请求已接受", "服务人员已接受您的请求，正在赶来！", "booking_accepted"},
-            "in_progress": {"工作已开始", "服务人员已开始处理您的请求。", "booking_in_progress"},
-            "completed":   {"服务已完成", "您的服务请求已完成。请为体验打分。", "booking_completed"},
-            "cancelled":   {"服务已取消", "您的服务请求已被取消。", "booking_cancelled"},
-            "default":     {"服务更新", "您的服务请求状态已更新。", "system"},
-        },
-    }
-
-    mlang := msgs[lang]
-    m, ok := mlang[status]
-    if !ok {
-        m = mlang["default"]
-    }
-    return m.title, m.body, m.ntype
+// notifiedStatuses are the request statuses with their own notification message and type
+var notifiedStatuses = map[string]bool{
+	"accepted":    true,
+	"en_route":    true,
+	"arrived":     true,
+	"in_progress": true,
+	"completed":   true,
+	"cancelled":   true,
 }
 
 // RegisterPushToken registers a push token for a user
@@ -90,7 +44,7 @@ func RegisterPushToken(c *gin.Context) {
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 
@@ -110,14 +64,14 @@ func RegisterPushToken(c *gin.Context) {
 		
 		if err := database.DB.Create(&token).Error; err != nil {
 			log.Printf("❌ Error creating push token: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register push token"})
+			apierror.Abort(c, apierror.Internal("Failed to register push token", nil))
 			return
 		}
 		
 		log.Printf("✅ Push token registered for user %d", userID)
 	} else if err != nil {
 		log.Printf("❌ Error checking existing token: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		apierror.Abort(c, apierror.Internal("Database error", nil))
 		return
 	} else {
 		// Update existing token
@@ -129,7 +83,7 @@ func RegisterPushToken(c *gin.Context) {
 		
 		if err := database.DB.Save(&existingToken).Error; err != nil {
 			log.Printf("❌ Error updating push token: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update push token"})
+			apierror.Abort(c, apierror.Internal("Failed to update push token", nil))
 			return
 		}
 		
@@ -149,7 +103,7 @@ func HasPushToken(c *gin.Context) {
     var count int64
     if err := database.DB.Model(&models.PushToken{}).Where("user_id = ? AND active = ?", userID, true).Count(&count).Error; err != nil {
         log.Printf("❌ Error checking push token existence: %v", err)
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+        apierror.Abort(c, apierror.Internal("Database error", nil))
         return
     }
 
@@ -159,27 +113,128 @@ func HasPushToken(c *gin.Context) {
     })
 }
 
-// GetUserNotifications gets all notifications for a user
+// GetUserNotifications returns a page of the user's notifications, optionally filtered by
+// type (comma separated) and read state
 func GetUserNotifications(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	log.Printf("🔍 GetUserNotifications called for user ID: %d", userID)
-	
-	var notifications []models.Notification
-	err := database.DB.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Limit(50).
-		Find(&notifications).Error
-	
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	var filter services.NotificationFilter
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	if raw := c.Query("read"); raw != "" {
+		read, err := strconv.ParseBool(raw)
+		if err != nil {
+			validation.Fail(c, "read", "oneof", "true false")
+			return
+		}
+		filter.Read = &read
+	}
+
+	inbox := services.NewNotificationInboxService()
+	// Polling clients usually already have the current page; answer them before loading it
+	if count, lastUpdated, err := inbox.Version(userID); err == nil {
+		etag := middleware.WeakETag("notifications", strconv.FormatUint(uint64(userID), 10),
+			strconv.FormatInt(count, 10), strconv.FormatInt(lastUpdated.UnixNano(), 10), c.Request.URL.RawQuery)
+		if middleware.NotModified(c, etag, lastUpdated) {
+			return
+		}
+	} else {
+		log.Printf("⚠️ Failed to check notification version for user %d: %v", userID, err)
+	}
+
+	notifications, total, err := inbox.List(userID, filter, offset, limit)
 	if err != nil {
 		log.Printf("❌ Error fetching notifications: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch notifications", nil))
 		return
 	}
 
-	log.Printf("📱 Found %d notifications for user %d", len(notifications), userID)
 	c.JSON(http.StatusOK, gin.H{
-		"success":      true,
+		"success":       true,
 		"notifications": notifications,
+		"pagination": gin.H{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// DeleteNotification deletes one of the user's notifications
+func DeleteNotification(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid notification ID"))
+		return
+	}
+
+	deleted, err := services.NewNotificationInboxService().Delete(userID, []uint{uint(id)})
+	if err != nil {
+		log.Printf("❌ Error deleting notification %d: %v", id, err)
+		apierror.Abort(c, apierror.Internal("Failed to delete notification", nil))
+		return
+	}
+	if deleted == 0 {
+		apierror.Abort(c, apierror.NotFound("Notification not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notification deleted",
+	})
+}
+
+// DeleteNotifications deletes the listed notifications, or every read one when all_read is set
+func DeleteNotifications(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req struct {
+		IDs     []uint `json:"ids" binding:"omitempty,max=100"`
+		AllRead bool   `json:"all_read"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	if len(req.IDs) == 0 && !req.AllRead {
+		validation.Fail(c, "ids", "required", "")
+		return
+	}
+
+	inbox := services.NewNotificationInboxService()
+	var deleted int64
+	var err error
+	if req.AllRead {
+		deleted, err = inbox.DeleteRead(userID)
+	} else {
+		deleted, err = inbox.Delete(userID, req.IDs)
+	}
+	if err != nil {
+		log.Printf("❌ Error deleting notifications for user %d: %v", userID, err)
+		apierror.Abort(c, apierror.Internal("Failed to delete notifications", nil))
+		return
+	}
+
+	log.Printf("🗑️ Deleted %d notifications for user %d", deleted, userID)
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"deleted": deleted,
 	})
 }
 
@@ -191,7 +246,7 @@ func MarkNotificationAsRead(c *gin.Context) {
 	// Convert string to uint
 	id, err := strconv.ParseUint(notificationID, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		apierror.Abort(c, apierror.Validation("Invalid notification ID"))
 		return
 	}
 
@@ -200,10 +255,10 @@ func MarkNotificationAsRead(c *gin.Context) {
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			apierror.Abort(c, apierror.NotFound("Notification not found"))
 		} else {
 			log.Printf("❌ Error finding notification: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			apierror.Abort(c, apierror.Internal("Database error", nil))
 		}
 		return
 	}
@@ -213,7 +268,7 @@ func MarkNotificationAsRead(c *gin.Context) {
 	
 	if err := database.DB.Save(&notification).Error; err != nil {
 		log.Printf("❌ Error updating notification: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		apierror.Abort(c, apierror.Internal("Failed to update notification", nil))
 		return
 	}
 
@@ -236,7 +291,7 @@ func MarkAllNotificationsAsRead(c *gin.Context) {
 	
 	if err != nil {
 		log.Printf("❌ Error marking all notifications as read: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+		apierror.Abort(c, apierror.Internal("Failed to mark notifications as read", nil))
 		return
 	}
 
@@ -246,35 +301,143 @@ func MarkAllNotificationsAsRead(c *gin.Context) {
 	})
 }
 
-// GetUnreadCount returns the count of unread notifications for the user
+// GetUnreadCount returns the user's unread notification count, also broken down per type and
+// per preference category so clients can render badges on individual tabs
 func GetUnreadCount(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	log.Printf("🔍 GetUnreadCount called for user ID: %d", userID)
-	
-	var count int64
-	err := database.DB.Model(&models.Notification{}).
-		Where("user_id = ? AND read = ?", userID, false).
-		Count(&count).Error
 
+	counts, err := services.NewNotificationInboxService().UnreadCounts(userID)
 	if err != nil {
 		log.Printf("❌ Error getting unread count: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread count"})
+		apierror.Abort(c, apierror.Internal("Failed to get unread count", nil))
 		return
 	}
 
-	log.Printf("📊 Unread count for user %d: %d", userID, count)
-	c.JSON(http.StatusOK, gin.H{
-		"count": count,
-	})
+	c.JSON(http.StatusOK, counts)
+}
+
+// GetNotificationPreferences returns the user's notification toggles and quiet hours
+func GetNotificationPreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	pref, err := services.NewNotificationPreferenceService().Get(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load notification preferences", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": pref})
 }
 
-// SendPushNotification sends a push notification to a user (internal function)
+// UpdateNotificationPreferences changes the fields present in the body and keeps the rest
+func UpdateNotificationPreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req struct {
+		ChatEnabled          *bool   `json:"chat_enabled"`
+		StatusUpdatesEnabled *bool   `json:"status_updates_enabled"`
+		PromotionsEnabled    *bool   `json:"promotions_enabled"`
+		QuietHoursEnabled    *bool   `json:"quiet_hours_enabled"`
+		QuietHoursStart      *string `json:"quiet_hours_start"`
+		QuietHoursEnd        *string `json:"quiet_hours_end"`
+		Timezone             *string `json:"timezone" binding:"omitempty,max=64"`
+		PreferredChannel     *string `json:"preferred_channel" binding:"omitempty,oneof=push sms in_app email"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	if req.QuietHoursStart != nil {
+		if _, err := models.ParseClock(*req.QuietHoursStart); err != nil {
+			validation.Fail(c, "quiet_hours_start", "time_of_day", "")
+			return
+		}
+	}
+	if req.QuietHoursEnd != nil {
+		if _, err := models.ParseClock(*req.QuietHoursEnd); err != nil {
+			validation.Fail(c, "quiet_hours_end", "time_of_day", "")
+			return
+		}
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			validation.Fail(c, "timezone", "timezone", "")
+			return
+		}
+	}
+
+	preferences := services.NewNotificationPreferenceService()
+	pref, err := preferences.Get(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load notification preferences", err))
+		return
+	}
+
+	if req.ChatEnabled != nil {
+		pref.ChatEnabled = *req.ChatEnabled
+	}
+	if req.StatusUpdatesEnabled != nil {
+		pref.StatusUpdatesEnabled = *req.StatusUpdatesEnabled
+	}
+	if req.PromotionsEnabled != nil {
+		pref.PromotionsEnabled = *req.PromotionsEnabled
+	}
+	if req.QuietHoursEnabled != nil {
+		pref.QuietHoursEnabled = *req.QuietHoursEnabled
+	}
+	if req.QuietHoursStart != nil {
+		pref.QuietHoursStart = *req.QuietHoursStart
+	}
+	if req.QuietHoursEnd != nil {
+		pref.QuietHoursEnd = *req.QuietHoursEnd
+	}
+	if req.Timezone != nil {
+		pref.Timezone = *req.Timezone
+	}
+	if req.PreferredChannel != nil {
+		pref.PreferredChannel = models.NotificationChannel(*req.PreferredChannel)
+	}
+
+	if err := preferences.Save(&pref); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to save notification preferences", err))
+		return
+	}
+
+	log.Printf("✅ Notification preferences updated for user %d", userID)
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Notification preferences updated", "data": pref})
+}
+
+// SendPushNotification sends a push notification to a user (internal function). The user's
+// preferences decide whether it is sent, deferred past their quiet hours, or delivered by SMS
+// or in-app only.
 func SendPushNotification(userID uint, title, body, notificationType string, data map[string]interface{}) error {
 	log.Printf("🔔 SendPushNotification called for user %d: %s - %s", userID, title, body)
-	
+
+	decision, err := services.NewNotificationPreferenceService().Decide(userID, notificationType, time.Now())
+	if err != nil {
+		// Fall back to plain push rather than dropping the notification
+		log.Printf("⚠️ Could not load notification preferences for user %d: %v", userID, err)
+	}
+	if !decision.Deliver {
+		log.Printf("🔕 User %d turned off %s notifications, skipping %s", userID, decision.Category, notificationType)
+		return nil
+	}
+	if decision.DeferUntil != nil {
+		log.Printf("🌙 User %d is in quiet hours, deferring %s until %s", userID, notificationType, decision.DeferUntil.Format(time.RFC3339))
+		return services.EnqueueOutboxEventAt(database.DB, models.OutboxEventPushNotification, userID, models.PushNotificationPayload{
+			UserID: userID,
+			Title:  title,
+			Body:   body,
+			Type:   notificationType,
+			Data:   data,
+		}, *decision.DeferUntil)
+	}
+	if decision.Channel != models.NotificationChannelPush {
+		return deliverWithoutPush(userID, title, body, notificationType, data, decision)
+	}
+
 	// Get user's push tokens
 	var tokens []models.PushToken
-	err := database.DB.Where("user_id = ? AND active = ?", userID, true).Find(&tokens).Error
+	err = database.DB.Where("user_id = ? AND active = ?", userID, true).Find(&tokens).Error
 	if err != nil {
 		log.Printf("❌ Error fetching push tokens for user %d: %v", userID, err)
 		return err
@@ -285,7 +448,8 @@ func SendPushNotification(userID uint, title, body, notificationType string, dat
 		log.Printf("🔑 Token %d: %s (platform: %s)", i+1, token.Token, token.Platform)
 	}
 
-	if len(tokens) == 0 {
+	fallback := services.SMSFallbackEnabled(notificationType)
+	if len(tokens) == 0 && !fallback {
 		log.Printf("⚠️ No push tokens found for user %d", userID)
 		return nil
 	}
@@ -312,7 +476,7 @@ func SendPushNotification(userID uint, title, body, notificationType string, dat
 	successCount := 0
 	for i, token := range tokens {
 		log.Printf("📱 Sending push notification %d/%d to user %d", i+1, len(tokens), userID)
-		err := sendExpoPushNotification(token.Token, title, body, data)
+		err := sendExpoPushNotification(token.Token, title, body, pushChannel(notificationType), data)
 		if err != nil {
 			log.Printf("❌ Error sending push notification to token %s: %v", token.Token, err)
 		} else {
@@ -322,11 +486,79 @@ func SendPushNotification(userID uint, title, body, notificationType string, dat
 	}
 
 	log.Printf("📊 Push notification summary: %d/%d sent successfully to user %d", successCount, len(tokens), userID)
+
+	// Critical events must reach the user even without a working push token
+	if successCount == 0 && fallback {
+		log.Printf("📱 Push failed for critical %s, falling back to SMS for user %d", notificationType, userID)
+		return sendNotificationSMS(userID, title, body, notificationType, models.SMSPurposeFallback)
+	}
+	return nil
+}
+
+// deliverWithoutPush records the in-app notification and, for the SMS and email channels, also
+// texts or emails it to the user. Promotions are never sent by SMS or email.
+func deliverWithoutPush(userID uint, title, body, notificationType string, data map[string]interface{}, decision services.NotificationDecision) error {
+	dataJSON, _ := json.Marshal(data)
+	notification := models.Notification{
+		UserID: userID,
+		Title:  title,
+		Body:   body,
+		Type:   notificationType,
+		Data:   string(dataJSON),
+	}
+	if err := database.DB.Create(&notification).Error; err != nil {
+		log.Printf("❌ Error creating notification record for user %d: %v", userID, err)
+		return err
+	}
+
+	if decision.Channel == models.NotificationChannelInApp || decision.Category == models.NotificationCategoryPromotions {
+		log.Printf("📥 Notification stored in-app only for user %d", userID)
+		return nil
+	}
+
+	if decision.Channel == models.NotificationChannelEmail {
+		sent, err := services.NewEmailService().SendToUser(userID, services.EmailTemplateNotification, map[string]interface{}{
+			"title": title,
+			"body":  body,
+		})
+		if err != nil {
+			log.Printf("❌ Error sending email notification to user %d: %v", userID, err)
+			return err
+		}
+		if !sent {
+			log.Printf("📥 User %d has no verified email, notification stored in-app only", userID)
+		}
+		return nil
+	}
+
+	return sendNotificationSMS(userID, title, body, notificationType, models.SMSPurposeNotification)
+}
+
+// sendNotificationSMS texts a notification to the user's phone number
+func sendNotificationSMS(userID uint, title, body, notificationType string, purpose models.SMSPurpose) error {
+	var user models.User
+	if err := database.DB.Select("id", "phone_number").First(&user, userID).Error; err != nil {
+		return err
+	}
+	if err := services.NewSMSService().Send(&userID, user.PhoneNumber, title+": "+body, purpose, notificationType); err != nil {
+		log.Printf("❌ Error sending SMS notification to user %d: %v", userID, err)
+		return err
+	}
+	log.Printf("📱 Notification sent by SMS to user %d", userID)
 	return nil
 }
 
+// pushChannel is the Android notification channel of a notification type. The app sets the
+// urgent_jobs channel to a louder sound that plays through do-not-disturb.
+func pushChannel(notificationType string) string {
+	if notificationType == "urgent_job" {
+		return "urgent_jobs"
+	}
+	return "service_updates"
+}
+
 // sendExpoPushNotification sends a notification via Expo Push API
-func sendExpoPushNotification(token, title, body string, data map[string]interface{}) error {
+func sendExpoPushNotification(token, title, body, channelID string, data map[string]interface{}) error {
 	// Send to Expo Push API
 	payload := map[string]interface{}{
 		"to":          token,
@@ -335,7 +567,7 @@ func sendExpoPushNotification(token, title, body string, data map[string]interfa
 		"data":        data,
 		"sound":       "default",
 		"priority":    "high",
-		"channelId":   "service_updates",
+		"channelId":   channelID,
 	}
 
 	bodyBytes, _ := json.Marshal(payload)
@@ -370,11 +602,32 @@ func sendExpoPushNotification(token, title, body string, data map[string]interfa
 		log.Printf("❌ Expo push send failed: %s - %s", resp.Status, string(respBody))
 		return fmt.Errorf("expo push failed: %s", resp.Status)
 	}
+
+	// Expo answers 200 with an error ticket for tokens it cannot deliver to
+	var ticket struct {
+		Data struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(respBody, &ticket) == nil && ticket.Data.Status == "error" {
+		log.Printf("❌ Expo rejected push: %s", ticket.Data.Message)
+		return fmt.Errorf("expo push rejected: %s", ticket.Data.Message)
+	}
 	
 	log.Printf("✅ Expo push notification sent successfully")
 	return nil
 }
 
+// SendLocalizedPushNotification sends catalog message in the user's preferred language. The
+// catalog holds its title under message+".title" and its body under message+".body".
+func SendLocalizedPushNotification(userID uint, message string, vars i18n.Vars, notificationType string, data map[string]interface{}) error {
+	lang := services.UserLanguage(userID)
+	title, body := i18n.T(lang, message+".title", vars), i18n.T(lang, message+".body", vars)
+	log.Printf("📝 Notification content (%s): %s - %s (type: %s)", lang, title, body, notificationType)
+	return SendPushNotification(userID, title, body, notificationType, data)
+}
+
 // SendServiceStatusNotification sends a notification when service status changes
 func SendServiceStatusNotification(userID uint, serviceRequestID uint, status string) error {
 	log.Printf("🔔 SendServiceStatusNotification called: userID=%d, serviceRequestID=%d, status=%s", userID, serviceRequestID, status)
@@ -392,11 +645,10 @@ func SendServiceStatusNotification(userID uint, serviceRequestID uint, status st
 		return nil // Don't send duplicate notification
 	}
 	
-	// Localize message by user preferred language
-	lang := getUserPreferredLanguage(userID)
-	title, body, notificationType := getLocalizedStatusMessage(status, lang)
-
-	log.Printf("📝 Notification content: %s - %s (type: %s)", title, body, notificationType)
+	message, notificationType := "notification.status."+status, "booking_"+status
+	if !notifiedStatuses[status] {
+		message, notificationType = "notification.status.default", "system"
+	}
 
 	data := map[string]interface{}{
 		"service_request_id": serviceRequestID,
@@ -404,7 +656,7 @@ func SendServiceStatusNotification(userID uint, serviceRequestID uint, status st
 		"type":              "status_update",
 	}
 
-	err = SendPushNotification(userID, title, body, notificationType, data)
+	err = SendLocalizedPushNotification(userID, message, nil, notificationType, data)
 	if err != nil {
 		log.Printf("❌ SendServiceStatusNotification failed for user %d: %v", userID, err)
 	} else {
@@ -450,18 +702,18 @@ func SendCampaignNotification(c *gin.Context) {
 	
 	var campaign NotificationCampaign
 	if err := c.ShouldBindJSON(&campaign); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 
 	// Set user ID from context
 	campaign.UserID = userID
 
-	// Send the notification
-	err := SendPushNotification(userID, campaign.Title, campaign.Body, "system", campaign.Data)
+	// Send the notification; campaigns are promotions, so the user's promotions toggle and quiet hours apply
+	err := SendPushNotification(userID, campaign.Title, campaign.Body, "promotion", campaign.Data)
 	if err != nil {
 		log.Printf("❌ SendCampaignNotification failed for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send notification"})
+		apierror.Abort(c, apierror.Internal("Failed to send notification", nil))
 		return
 	}
 
@@ -475,13 +727,22 @@ func ScheduleCampaignNotification(c *gin.Context) {
 	
 	var campaign NotificationCampaign
 	if err := c.ShouldBindJSON(&campaign); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 
 	// Set user ID from context
 	campaign.UserID = userID
 
+	pref, err := services.NewNotificationPreferenceService().Get(userID)
+	if err != nil {
+		log.Printf("⚠️ Could not load notification preferences for user %d: %v", userID, err)
+	} else if !pref.PromotionsEnabled {
+		log.Printf("🔕 User %d turned off promotions, not scheduling campaign %s", userID, campaign.Type)
+		c.JSON(http.StatusOK, gin.H{"success": true, "message": "Promotions are turned off for this user", "skipped": true})
+		return
+	}
+
 	// Convert data to JSON string
 	var dataJSON string
 	if campaign.Data != nil {
@@ -501,7 +762,7 @@ func ScheduleCampaignNotification(c *gin.Context) {
 		UserID: userID,
 		Title:  campaign.Title,
 		Body:   campaign.Body,
-		Type:   "system",
+		Type:   "promotion",
 		Data:   dataJSON,
 		Read:   false,
 	}
@@ -510,7 +771,7 @@ func ScheduleCampaignNotification(c *gin.Context) {
 	// For now, we'll store it as a regular notification
 	if err := database.DB.Create(&notification).Error; err != nil {
 		log.Printf("❌ ScheduleCampaignNotification failed for user %d: %v", userID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule notification"})
+		apierror.Abort(c, apierror.Internal("Failed to schedule notification", nil))
 		return
 	}
 
@@ -524,7 +785,7 @@ func TrackUserActivity(c *gin.Context) {
 	
 	var activity UserActivity
 	if err := c.ShouldBindJSON(&activity); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 
@@ -546,7 +807,7 @@ func SubmitFeedback(c *gin.Context) {
 	
 	var feedback FeedbackSubmission
 	if err := c.ShouldBindJSON(&feedback); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 
@@ -564,7 +825,7 @@ func SubmitFeedback(c *gin.Context) {
 	}
 	if err := database.DB.Create(&fb).Error; err != nil {
 		log.Printf("❌ Failed to save feedback: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feedback"})
+		apierror.Abort(c, apierror.Internal("Failed to save feedback", nil))
 		return
 	}
 
@@ -0,0 +1,491 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/utils"
+	"repair-service-server/validation"
+)
+
+// RegisterOrganizationRoutes registers the routes company accounts use to manage their members and
+// follow the requests billed to them
+func RegisterOrganizationRoutes(router *gin.RouterGroup) {
+	orgs := router.Group("/organizations")
+	{
+		orgs.POST("", createOrganization)
+		orgs.GET("", getMyOrganizations)
+		orgs.GET("/:id", getOrganization)
+		orgs.PUT("/:id", updateOrganization)
+
+		// Members and invitations
+		orgs.GET("/:id/members", getOrganizationMembers)
+		orgs.POST("/:id/members", inviteOrganizationMember)
+		orgs.PUT("/:id/members/:userId", updateOrganizationMember)
+		orgs.DELETE("/:id/members/:userId", removeOrganizationMember)
+		orgs.POST("/:id/invitation/accept", acceptOrganizationInvitation)
+		orgs.POST("/:id/invitation/decline", declineOrganizationInvitation)
+
+		// Consolidated history, invoices and analytics (owners only)
+		orgs.GET("/:id/history", getOrganizationHistory)
+		orgs.GET("/:id/invoices/:month", getOrganizationInvoice)
+		orgs.GET("/:id/analytics", getOrganizationAnalytics)
+	}
+}
+
+// abortOrganizationError maps organization service errors to API errors
+func abortOrganizationError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotOrganizationMember):
+		apierror.Abort(c, apierror.NotFound("Organization not found"))
+	case errors.Is(err, services.ErrOrganizationOwnerRequired):
+		apierror.Abort(c, apierror.Forbidden("Only organization owners can do this"))
+	case errors.Is(err, services.ErrLastOrganizationOwner):
+		apierror.Abort(c, apierror.Conflict("An organization needs at least one owner"))
+	case errors.Is(err, services.ErrAlreadyOrganizationMember):
+		apierror.Abort(c, apierror.Conflict("User is already a member or invited"))
+	case errors.Is(err, services.ErrInviteeNotFound):
+		apierror.Abort(c, apierror.NotFound("No user with this phone number or email"))
+	case errors.Is(err, services.ErrNoOrganizationInvitation):
+		apierror.Abort(c, apierror.NotFound("No pending invitation to this organization"))
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("Member not found"))
+	default:
+		apierror.Abort(c, apierror.Internal(message, err))
+	}
+}
+
+// organizationParam parses the :id route parameter
+func organizationParam(c *gin.Context) (uint, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid organization ID"))
+	}
+	return id, ok
+}
+
+// ownedOrganization loads the :id organization if the current user owns it
+func ownedOrganization(c *gin.Context) (*models.Organization, bool) {
+	id, ok := organizationParam(c)
+	if !ok {
+		return nil, false
+	}
+	org, err := services.NewOrganizationService().FindOwned(id, c.GetUint("user_id"))
+	if err != nil {
+		abortOrganizationError(c, "Failed to fetch organization", err)
+		return nil, false
+	}
+	return org, true
+}
+
+// checkOrganization verifies that the customer may bill a new request to the organization
+func checkOrganization(c *gin.Context, userID uint, orgID *uint) bool {
+	if orgID == nil {
+		return true
+	}
+	if _, err := services.NewOrganizationService().Membership(*orgID, userID); err != nil {
+		if errors.Is(err, services.ErrNotOrganizationMember) {
+			validation.Fail(c, "organization_id", "default", "")
+			return false
+		}
+		apierror.Abort(c, apierror.Internal("Failed to load organization", err))
+		return false
+	}
+	return true
+}
+
+// ownsRequestOrganization reports whether the user owns the organization the request is billed to
+func ownsRequestOrganization(userID uint, r models.CustomerServiceRequest) bool {
+	if r.OrganizationID == nil {
+		return false
+	}
+	member, err := services.NewOrganizationService().Membership(*r.OrganizationID, userID)
+	return err == nil && member.IsOwner()
+}
+
+// createOrganization creates an organization owned by the current user
+func createOrganization(c *gin.Context) {
+	var req models.OrganizationRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	userID := c.GetUint("user_id")
+	org, err := services.NewOrganizationService().Create(userID, req)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create organization", err))
+		return
+	}
+	log.Printf("🏢 Organization %d (%s) created by user %d", org.ID, org.Name, userID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    org,
+	})
+}
+
+// getMyOrganizations lists the organizations the current user belongs to or is invited to
+func getMyOrganizations(c *gin.Context) {
+	members, err := services.NewOrganizationService().ForUser(c.GetUint("user_id"))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch organizations", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    serializers.OrganizationMemberships(members),
+	})
+}
+
+// getOrganization returns an organization the current user is a member of, with their role
+func getOrganization(c *gin.Context) {
+	id, ok := organizationParam(c)
+	if !ok {
+		return
+	}
+	org, member, err := services.NewOrganizationService().Find(id, c.GetUint("user_id"))
+	if err != nil {
+		abortOrganizationError(c, "Failed to fetch organization", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    org,
+		"role":    member.Role,
+	})
+}
+
+// updateOrganization changes the name and billing details of an organization the user owns
+func updateOrganization(c *gin.Context) {
+	org, ok := ownedOrganization(c)
+	if !ok {
+		return
+	}
+	var req models.OrganizationRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	before := *org
+	if err := services.NewOrganizationService().Update(org, req); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to update organization", err))
+		return
+	}
+	middleware.RecordAuditChange(c, "organizations", org.ID, before, org)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    org,
+	})
+}
+
+// getOrganizationMembers lists the members and pending invitations of the user's organization
+func getOrganizationMembers(c *gin.Context) {
+	id, ok := organizationParam(c)
+	if !ok {
+		return
+	}
+	orgService := services.NewOrganizationService()
+	if _, err := orgService.Membership(id, c.GetUint("user_id")); err != nil {
+		abortOrganizationError(c, "Failed to fetch organization", err)
+		return
+	}
+	members, err := orgService.Members(id)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch members", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    serializers.OrganizationMembers(members),
+	})
+}
+
+// inviteOrganizationMember invites an existing user by phone number or email; owners only
+func inviteOrganizationMember(c *gin.Context) {
+	org, ok := ownedOrganization(c)
+	if !ok {
+		return
+	}
+	var req models.OrganizationInviteRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	member, err := services.NewOrganizationService().Invite(org, c.GetUint("user_id"), req)
+	if err != nil {
+		abortOrganizationError(c, "Failed to invite member", err)
+		return
+	}
+	log.Printf("🏢 User %d invited to organization %d as %s", member.UserID, org.ID, member.Role)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    serializers.OrganizationMember(*member),
+	})
+}
+
+// updateOrganizationMember changes a member's role; owners only
+func updateOrganizationMember(c *gin.Context) {
+	org, ok := ownedOrganization(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "userId")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid user ID"))
+		return
+	}
+	var req models.OrganizationMemberUpdate
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	member, err := services.NewOrganizationService().SetRole(org.ID, userID, req.Role)
+	if err != nil {
+		abortOrganizationError(c, "Failed to update member", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    serializers.OrganizationMember(*member),
+	})
+}
+
+// removeOrganizationMember removes a member or cancels an invitation. Owners may remove anyone;
+// members may only leave.
+func removeOrganizationMember(c *gin.Context) {
+	id, ok := organizationParam(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "userId")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid user ID"))
+		return
+	}
+
+	currentUserID := c.GetUint("user_id")
+	orgService := services.NewOrganizationService()
+	if userID != currentUserID {
+		if _, err := orgService.FindOwned(id, currentUserID); err != nil {
+			abortOrganizationError(c, "Failed to fetch organization", err)
+			return
+		}
+	}
+	if err := orgService.RemoveMember(id, userID); err != nil {
+		abortOrganizationError(c, "Failed to remove member", err)
+		return
+	}
+	log.Printf("🏢 User %d removed from organization %d by user %d", userID, id, currentUserID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Member removed",
+	})
+}
+
+// acceptOrganizationInvitation makes the current user a member of an organization that invited them
+func acceptOrganizationInvitation(c *gin.Context) {
+	id, ok := organizationParam(c)
+	if !ok {
+		return
+	}
+	member, err := services.NewOrganizationService().Accept(id, c.GetUint("user_id"))
+	if err != nil {
+		abortOrganizationError(c, "Failed to accept invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Invitation accepted",
+		"role":    member.Role,
+	})
+}
+
+// declineOrganizationInvitation deletes the current user's pending invitation
+func declineOrganizationInvitation(c *gin.Context) {
+	id, ok := organizationParam(c)
+	if !ok {
+		return
+	}
+	if err := services.NewOrganizationService().Decline(id, c.GetUint("user_id")); err != nil {
+		abortOrganizationError(c, "Failed to decline invitation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Invitation declined",
+	})
+}
+
+// getOrganizationHistory returns the completed requests billed to the organization, optionally
+// booked by one member; owners only
+func getOrganizationHistory(c *gin.Context) {
+	org, ok := ownedOrganization(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		MemberID uint `form:"member_id"`
+		Page     int  `form:"page" binding:"omitempty,min=1"`
+		Limit    int  `form:"limit" binding:"omitempty,min=1,max=50"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 10
+	}
+
+	history, total, err := services.NewOrganizationService().History(org.ID, req.MemberID, (req.Page-1)*req.Limit, req.Limit)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch organization history", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"history": history,
+		"pagination": gin.H{
+			"page":  req.Page,
+			"limit": req.Limit,
+			"total": total,
+			"pages": (total + int64(req.Limit) - 1) / int64(req.Limit),
+		},
+	})
+}
+
+// getOrganizationInvoice returns the organization's invoice for a month (YYYY-MM) as JSON, or as a
+// CSV or PDF download; owners only
+func getOrganizationInvoice(c *gin.Context) {
+	org, ok := ownedOrganization(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	month, err := time.ParseInLocation("2006-01", c.Param("month"), time.Local)
+	if err != nil {
+		validation.Fail(c, "month", "default", "")
+		return
+	}
+
+	invoice, err := services.NewOrganizationService().Invoice(*org, month)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to build invoice", err))
+		return
+	}
+
+	switch req.Format {
+	case "csv":
+		w := startCSV(c, "invoice-"+invoice.Number, []string{
+			"completed_at", "service_request_id", "title", "category", "member", "price", "refunded", "tips", "total", "payment_status",
+		})
+		for _, line := range invoice.Lines {
+			w.Write([]string{
+				csvTime(&line.CompletedAt), csvUint(line.ServiceRequestID), line.Title, line.CategoryName, line.MemberName,
+				line.Price.String(), line.Refunded.String(), line.Tips.String(), line.Total.String(), line.PaymentStatus,
+			})
+		}
+		t := invoice.Totals
+		w.Write([]string{
+			"total", strconv.Itoa(t.Services), "", "", "", t.Price.String(), t.Refunded.String(), t.Tips.String(), t.Total.String(), "",
+		})
+		w.Flush()
+	case "pdf":
+		pdf := utils.NewTextPDF()
+		pdf.Heading("Invoice " + invoice.Number)
+		pdf.Text(invoice.Organization)
+		if invoice.TaxID != "" {
+			pdf.Text("Tax ID " + invoice.TaxID)
+		}
+		pdf.Text("Month " + invoice.Month + ", generated " + invoice.GeneratedAt.Format("2006-01-02 15:04") + ", amounts in " + invoice.Currency)
+		pdf.Space()
+		pdf.Row(fmt.Sprintf("%-10s %-8s %-24s %-16s %10s %10s %8s %10s", "Date", "Request", "Service", "Member", "Price", "Refunded", "Tips", "Total"))
+		for _, line := range invoice.Lines {
+			pdf.Row(fmt.Sprintf("%-10s %-8d %-24.24s %-16.16s %10s %10s %8s %10s",
+				line.CompletedAt.Format("2006-01-02"), line.ServiceRequestID, line.Title, line.MemberName,
+				line.Price, line.Refunded, line.Tips, line.Total))
+		}
+		pdf.Space()
+		t := invoice.Totals
+		pdf.Subheading("Totals")
+		pdf.Text(fmt.Sprintf("Services: %d", t.Services))
+		pdf.Text(fmt.Sprintf("Price: %s", t.Price))
+		pdf.Text(fmt.Sprintf("Refunded: %s", t.Refunded))
+		pdf.Text(fmt.Sprintf("Tips: %s", t.Tips))
+		pdf.Subheading(fmt.Sprintf("Total due: %s", t.Total))
+		sendPDF(c, "invoice-"+invoice.Number, pdf)
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"data":    invoice,
+			"formatted": formatAmounts(c, invoice.Currency, map[string]money.Amount{
+				"price":    invoice.Totals.Price,
+				"refunded": invoice.Totals.Refunded,
+				"tips":     invoice.Totals.Tips,
+				"total":    invoice.Totals.Total,
+			}),
+		})
+	}
+}
+
+// getOrganizationAnalytics returns the organization's spend per month, category and member for a
+// year, the current one by default; owners only
+func getOrganizationAnalytics(c *gin.Context) {
+	org, ok := ownedOrganization(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Year int `form:"year" binding:"omitempty,min=2000,max=2100"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	}
+
+	dashboard, err := services.NewOrganizationService().Analytics(org.ID, req.Year)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch organization analytics", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    dashboard,
+		"formatted": formatAmounts(c, dashboard.Currency, map[string]money.Amount{
+			"spend": dashboard.Spend,
+			"tips":  dashboard.Tips,
+		}),
+	})
+}
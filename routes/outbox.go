@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"log"
+	"time"
+
+	"repair-service-server/models"
+	"repair-service-server/services"
+)
+
+// outboxRetention is how long delivered outbox events are kept for inspection
+const outboxRetention = 7 * 24 * time.Hour
+
+var lastOutboxPurge time.Time
+
+// outboxHandlers delivers each outbox event type
+var outboxHandlers = map[string]services.OutboxHandler{
+	models.OutboxEventJobCompletionAnalytics: func(event models.OutboxEvent) error {
+		var payload models.JobCompletionAnalyticsPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		// TrackJobCompletion skips jobs it has already tracked, so redelivery is safe
+		return services.NewWorkerAnalyticsService().TrackJobCompletion(payload.WorkerID, payload.ServiceRequestID, payload.Earnings, payload.WorkHours)
+	},
+	models.OutboxEventServiceStatusNotify: func(event models.OutboxEvent) error {
+		var payload models.ServiceStatusNotifyPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return SendServiceStatusNotification(payload.CustomerID, payload.ServiceRequestID, payload.Status)
+	},
+	models.OutboxEventPushNotification: func(event models.OutboxEvent) error {
+		var payload models.PushNotificationPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		if payload.Message != "" {
+			return SendLocalizedPushNotification(payload.UserID, payload.Message, payload.Vars, payload.Type, payload.Data)
+		}
+		return SendPushNotification(payload.UserID, payload.Title, payload.Body, payload.Type, payload.Data)
+	},
+	models.OutboxEventTipAnalytics: func(event models.OutboxEvent) error {
+		var payload models.TipAnalyticsPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		// TrackTip skips tips it has already tracked, so redelivery is safe
+		return services.NewWorkerAnalyticsService().TrackTip(payload.WorkerID, payload.ServiceRequestID, payload.Amount)
+	},
+	models.OutboxEventEmail: func(event models.OutboxEvent) error {
+		var payload models.EmailPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		_, err := services.NewEmailService().SendToUser(payload.UserID, payload.Template, payload.Data)
+		return err
+	},
+	models.OutboxEventSMS: func(event models.OutboxEvent) error {
+		var payload models.SMSPayload
+		if err := event.Decode(&payload); err != nil {
+			return err
+		}
+		return services.NewSMSService().Send(payload.UserID, payload.To, payload.Body, payload.Purpose, payload.NotificationType)
+	},
+}
+
+// ProcessOutbox delivers pending outbox events; called periodically by the outbox job
+func ProcessOutbox() {
+	outbox := services.NewOutboxService()
+
+	delivered, err := outbox.ProcessPending(outboxHandlers)
+	if err != nil {
+		log.Printf("❌ Failed to process outbox: %v", err)
+	} else if delivered > 0 {
+		log.Printf("📤 Delivered %d outbox events", delivered)
+	}
+
+	if time.Since(lastOutboxPurge) > time.Hour {
+		lastOutboxPurge = time.Now()
+		if purged, err := outbox.PurgeProcessed(outboxRetention); err != nil {
+			log.Printf("⚠️ Failed to purge outbox: %v", err)
+		} else if purged > 0 {
+			log.Printf("🧹 Purged %d delivered outbox events", purged)
+		}
+	}
+}
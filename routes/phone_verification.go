@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// PhoneVerificationHandler serves SMS code delivery and phone number verification
+type PhoneVerificationHandler struct {
+	otp *services.OTPService
+}
+
+// RegisterPhoneVerificationRoutes registers OTP routes under the auth group
+func RegisterPhoneVerificationRoutes(router *gin.RouterGroup, otp *services.OTPService) {
+	h := &PhoneVerificationHandler{otp: otp}
+
+	// Before signup: send a code to the number being registered
+	router.POST("/otp/send", h.sendSignupCode)
+
+	// Signed-in users: verify the current number or move to a new one
+	phone := router.Group("/phone")
+	phone.Use(middleware.AuthMiddleware())
+	{
+		phone.POST("/send-code", h.sendVerificationCode)
+		phone.POST("/verify", h.verifyPhone)
+		phone.POST("/change", h.requestPhoneChange)
+		phone.POST("/change/confirm", h.confirmPhoneChange)
+	}
+}
+
+// sendSignupCode sends a code to a number that is not registered yet
+func (h *PhoneVerificationHandler) sendSignupCode(c *gin.Context) {
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required,phone"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	phone := validation.NormalizePhone(req.PhoneNumber)
+
+	if phoneTaken(phone, 0) {
+		apierror.Abort(c, apierror.Conflict("An account with this phone number already exists"))
+		return
+	}
+
+	if err := h.otp.SendCode(phone, models.PhoneVerificationSignup, nil); err != nil {
+		abortOTPError(c, err, "Failed to send verification code")
+		return
+	}
+	respondCodeSent(c, phone)
+}
+
+// sendVerificationCode sends a code to the signed-in user's current number
+func (h *PhoneVerificationHandler) sendVerificationCode(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	if user.IsPhoneVerified() {
+		apierror.Abort(c, apierror.Conflict("Phone number is already verified"))
+		return
+	}
+
+	if err := h.otp.SendCode(user.PhoneNumber, models.PhoneVerificationSignup, &user.ID); err != nil {
+		abortOTPError(c, err, "Failed to send verification code")
+		return
+	}
+	respondCodeSent(c, user.PhoneNumber)
+}
+
+// verifyPhone confirms the signed-in user's current number
+func (h *PhoneVerificationHandler) verifyPhone(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req struct {
+		OTPCode string `json:"otp_code" binding:"required,numeric,len=6"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	if err := h.otp.VerifyCode(user.PhoneNumber, models.PhoneVerificationSignup, req.OTPCode); err != nil {
+		abortOTPError(c, err, "Failed to verify phone number")
+		return
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(&user).Update("phone_verified_at", &now).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to verify phone number", err))
+		return
+	}
+	user.PhoneVerifiedAt = &now
+
+	log.Printf("✅ Phone verified for user %d", user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Phone number verified",
+		"data":    gin.H{"user": serializers.User(user)},
+	})
+}
+
+// requestPhoneChange sends a code to the number the user wants to switch to
+func (h *PhoneVerificationHandler) requestPhoneChange(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required,phone"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	phone := validation.NormalizePhone(req.PhoneNumber)
+
+	if phone == user.PhoneNumber {
+		apierror.Abort(c, apierror.Validation("New phone number must differ from the current one"))
+		return
+	}
+	if phoneTaken(phone, user.ID) {
+		apierror.Abort(c, apierror.Conflict("An account with this phone number already exists"))
+		return
+	}
+
+	if err := h.otp.SendCode(phone, models.PhoneVerificationChange, &user.ID); err != nil {
+		abortOTPError(c, err, "Failed to send verification code")
+		return
+	}
+	respondCodeSent(c, phone)
+}
+
+// confirmPhoneChange switches the user to the new number once its code is confirmed
+func (h *PhoneVerificationHandler) confirmPhoneChange(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req struct {
+		PhoneNumber string `json:"phone_number" binding:"required,phone"`
+		OTPCode     string `json:"otp_code" binding:"required,numeric,len=6"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	phone := validation.NormalizePhone(req.PhoneNumber)
+
+	if err := h.otp.VerifyCode(phone, models.PhoneVerificationChange, req.OTPCode); err != nil {
+		abortOTPError(c, err, "Failed to verify phone number")
+		return
+	}
+
+	// The number may have been registered since the code was sent
+	if phoneTaken(phone, user.ID) {
+		apierror.Abort(c, apierror.Conflict("An account with this phone number already exists"))
+		return
+	}
+
+	before := user
+	now := time.Now()
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"phone_number":      phone,
+		"phone_verified_at": &now,
+	}).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to update phone number", err))
+		return
+	}
+	user.PhoneNumber = phone
+	user.PhoneVerifiedAt = &now
+	middleware.RecordAuditChange(c, "users", user.ID, before, user)
+
+	log.Printf("✅ User %d changed phone number", user.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Phone number updated",
+		"data":    gin.H{"user": serializers.User(user)},
+	})
+}
+
+// phoneTaken reports whether another account than exceptUserID uses phone
+func phoneTaken(phone string, exceptUserID uint) bool {
+	var count int64
+	database.DB.Model(&models.User{}).Where("phone_number = ? AND id <> ?", phone, exceptUserID).Count(&count)
+	return count > 0
+}
+
+// respondCodeSent confirms delivery without echoing the code
+func respondCodeSent(c *gin.Context, phone string) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Verification code sent",
+		"data": gin.H{
+			"phone_number": phone,
+			"expires_in":   int(services.OTPTTL.Seconds()),
+			"resend_after": int(services.OTPResendAfter.Seconds()),
+			"max_attempts": services.OTPMaxAttempts,
+		},
+	})
+}
+
+// abortOTPError maps OTP service errors to API errors; anything unexpected becomes a 500
+// with fallback as its message
+func abortOTPError(c *gin.Context, err error, fallback string) {
+	var cooldown *services.OTPCooldownError
+	switch {
+	case errors.As(err, &cooldown):
+		retryAfter := int(math.Ceil(cooldown.RetryAfter.Seconds()))
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "A verification code was sent recently. Please try again later.").WithDetails(gin.H{"retry_after": retryAfter}))
+	case errors.Is(err, services.ErrOTPTooManyAttempts):
+		apierror.Abort(c, apierror.New(http.StatusTooManyRequests, apierror.CodeRateLimited, "Too many incorrect codes. Please request a new one."))
+	case errors.Is(err, services.ErrOTPInvalid):
+		validation.Fail(c, "otp_code", "otp_invalid", "")
+	default:
+		apierror.Abort(c, apierror.Internal(fallback, err))
+	}
+}
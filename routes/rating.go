@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 	"time"
@@ -8,8 +9,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/services"
 )
 
 // RegisterRatingRoutes registers all rating-related routes
@@ -17,7 +21,7 @@ func RegisterRatingRoutes(router *gin.RouterGroup) {
 	ratingRoutes := router.Group("/ratings")
 	{
 		// Create a new rating for a worker
-		ratingRoutes.POST("/", createWorkerRating)
+		ratingRoutes.POST("/", middleware.Idempotency(), createWorkerRating)
 		
 		// Get ratings for a specific worker
 		ratingRoutes.GET("/worker/:workerId", getWorkerRatings)
@@ -36,6 +40,13 @@ func RegisterRatingRoutes(router *gin.RouterGroup) {
 		
 		// Get all ratings for a customer
 		ratingRoutes.GET("/customer", getCustomerRatings)
+		
+		// Public reply by the rated worker
+		ratingRoutes.POST("/:ratingId/reply", replyToRating)
+		ratingRoutes.DELETE("/:ratingId/reply", deleteRatingReply)
+		
+		// Report a rating for moderation
+		ratingRoutes.POST("/:ratingId/flag", flagRating)
 	}
 }
 
@@ -43,7 +54,7 @@ func RegisterRatingRoutes(router *gin.RouterGroup) {
 func createWorkerRating(c *gin.Context) {
 	var ratingData models.WorkerRatingCreate
 	if err := c.ShouldBindJSON(&ratingData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rating data", "details": err.Error()})
+		apierror.Abort(c, apierror.Validation("Invalid rating data").WithDetails(err.Error()))
 		return
 	}
 
@@ -56,38 +67,44 @@ func createWorkerRating(c *gin.Context) {
 		Preload("AssignedWorker").
 		First(&serviceRequest, ratingData.ServiceRequestID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+			apierror.Abort(c, apierror.NotFound("Service request not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service request"})
+			apierror.Abort(c, apierror.Internal("Failed to fetch service request", nil))
 		}
 		return
 	}
 
 	// Verify the service request belongs to the current customer
 	if serviceRequest.CustomerID != customerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only rate services you requested"})
+		apierror.Abort(c, apierror.Forbidden("You can only rate services you requested"))
 		return
 	}
 
 	// Verify the service request is completed
 	if serviceRequest.Status != models.RequestStatusCompleted {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Can only rate completed services"})
+		apierror.Abort(c, apierror.Validation("Can only rate completed services"))
 		return
 	}
 
 	// Verify the service request has an assigned worker
 	if serviceRequest.AssignedWorkerID == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request has no assigned worker"})
+		apierror.Abort(c, apierror.Validation("Service request has no assigned worker"))
 		return
 	}
 
 	// Check if rating already exists for this service request
 	var existingRating models.WorkerRating
 	if err := database.DB.Where("service_request_id = ?", ratingData.ServiceRequestID).First(&existingRating).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Rating already exists for this service request"})
+		apierror.Abort(c, apierror.Conflict("Rating already exists for this service request"))
 		return
 	}
 
+	// Mask profanity and contact details in the public comment
+	moderation := services.NewContentModerationService()
+	originalComment := ratingData.Comment
+	filteredComment := moderation.Filter(originalComment)
+	ratingData.Comment = filteredComment.Text
+
 	// Create the rating
 	rating := models.WorkerRating{
 		CustomerID:      customerID,
@@ -106,16 +123,20 @@ func createWorkerRating(c *gin.Context) {
 	}
 
 	if err := database.DB.Create(&rating).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create rating"})
+		apierror.Abort(c, apierror.Internal("Failed to create rating", err))
 		return
 	}
+	moderation.Record(customerID, "worker_rating", rating.ID, originalComment, filteredComment)
 
 	// Update worker profile statistics
 	if err := updateWorkerRatingStats(*serviceRequest.AssignedWorkerID); err != nil {
 		// Log error but don't fail the rating creation
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rating created but failed to update worker stats"})
+		apierror.Abort(c, apierror.Internal("Rating created but failed to update worker stats", err))
 		return
 	}
+	if _, err := services.NewWorkerAnalyticsService().AwardBadges(*serviceRequest.AssignedWorkerID); err != nil {
+		log.Printf("⚠️ Failed to award badges to worker %d after rating: %v", *serviceRequest.AssignedWorkerID, err)
+	}
 
 	// Load the created rating with relationships
 	var createdRating models.WorkerRating
@@ -124,7 +145,7 @@ func createWorkerRating(c *gin.Context) {
 		Preload("Worker").
 		Preload("ServiceRequest").
 		First(&createdRating, rating.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rating created but failed to load details"})
+		apierror.Abort(c, apierror.Internal("Rating created but failed to load details", nil))
 		return
 	}
 
@@ -139,7 +160,7 @@ func getWorkerRatings(c *gin.Context) {
 	workerIDStr := c.Param("workerId")
 	workerID, err := strconv.ParseUint(workerIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worker ID"})
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
 		return
 	}
 
@@ -157,8 +178,8 @@ func getWorkerRatings(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
-	// Build query
-	query := database.DB.Where("worker_id = ?", workerID)
+	// Build query, leaving out ratings hidden by moderation
+	query := database.DB.Where("worker_id = ? AND moderation_status <> ?", workerID, models.ReviewHidden)
 	if stars > 0 && stars <= 5 {
 		query = query.Where("stars = ?", stars)
 	}
@@ -176,7 +197,7 @@ func getWorkerRatings(c *gin.Context) {
 		Offset(offset).
 		Limit(limit).
 		Find(&ratings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch ratings", nil))
 		return
 	}
 
@@ -196,11 +217,22 @@ func getWorkerRatingSummary(c *gin.Context) {
 	workerIDStr := c.Param("workerId")
 	workerID, err := strconv.ParseUint(workerIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worker ID"})
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
 		return
 	}
 
-	// Get rating summary
+	summary, err := workerRatingSummary(uint(workerID))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch rating summary", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// workerRatingSummary returns the star breakdown and average scores of a worker's ratings,
+// leaving out those hidden by moderation
+func workerRatingSummary(workerID uint) (models.WorkerRatingSummary, error) {
 	var summary models.WorkerRatingSummary
 	if err := database.DB.Raw(`
 		SELECT 
@@ -217,21 +249,19 @@ func getWorkerRatingSummary(c *gin.Context) {
 			AVG(CAST(punctuality AS DECIMAL(3,2))) as average_punctuality,
 			AVG(CAST(communication AS DECIMAL(3,2))) as average_communication
 		FROM worker_ratings 
-		WHERE worker_id = ? AND deleted_at IS NULL
+		WHERE worker_id = ? AND deleted_at IS NULL AND moderation_status <> ?
 		GROUP BY worker_id
-	`, workerID).Scan(&summary).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating summary"})
-		return
+	`, workerID, models.ReviewHidden).Scan(&summary).Error; err != nil {
+		return summary, err
 	}
 
 	// If no ratings found, return default values
 	if summary.TotalRatings == 0 {
-		summary.WorkerID = uint(workerID)
+		summary.WorkerID = workerID
 		summary.AverageStars = 0
 		summary.TotalRatings = 0
 	}
-
-	c.JSON(http.StatusOK, summary)
+	return summary, nil
 }
 
 // getRating retrieves a specific rating by ID
@@ -239,7 +269,7 @@ func getRating(c *gin.Context) {
 	ratingIDStr := c.Param("ratingId")
 	ratingID, err := strconv.ParseUint(ratingIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rating ID"})
+		apierror.Abort(c, apierror.Validation("Invalid rating ID"))
 		return
 	}
 
@@ -250,13 +280,19 @@ func getRating(c *gin.Context) {
 		Preload("ServiceRequest").
 		First(&rating, ratingID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+			apierror.Abort(c, apierror.NotFound("Rating not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating"})
+			apierror.Abort(c, apierror.Internal("Failed to fetch rating", nil))
 		}
 		return
 	}
 
+	// Hidden ratings are only shown to the customer who wrote them
+	if rating.ModerationStatus == models.ReviewHidden && rating.CustomerID != c.GetUint("user_id") {
+		apierror.Abort(c, apierror.NotFound("Rating not found"))
+		return
+	}
+
 	c.JSON(http.StatusOK, rating)
 }
 
@@ -265,7 +301,7 @@ func updateRating(c *gin.Context) {
 	ratingIDStr := c.Param("ratingId")
 	ratingID, err := strconv.ParseUint(ratingIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rating ID"})
+		apierror.Abort(c, apierror.Validation("Invalid rating ID"))
 		return
 	}
 
@@ -276,25 +312,30 @@ func updateRating(c *gin.Context) {
 	var existingRating models.WorkerRating
 	if err := database.DB.First(&existingRating, ratingID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+			apierror.Abort(c, apierror.NotFound("Rating not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating"})
+			apierror.Abort(c, apierror.Internal("Failed to fetch rating", nil))
 		}
 		return
 	}
 
 	if existingRating.CustomerID != customerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own ratings"})
+		apierror.Abort(c, apierror.Forbidden("You can only update your own ratings"))
 		return
 	}
 
 	// Parse update data
 	var updateData models.WorkerRatingCreate
 	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid update data"})
+		apierror.Abort(c, apierror.Validation("Invalid update data"))
 		return
 	}
 
+	moderation := services.NewContentModerationService()
+	originalComment := updateData.Comment
+	filteredComment := moderation.Filter(originalComment)
+	updateData.Comment = filteredComment.Text
+
 	// Update the rating
 	updates := map[string]interface{}{
 		"stars":            updateData.Stars,
@@ -308,13 +349,14 @@ func updateRating(c *gin.Context) {
 	}
 
 	if err := database.DB.Model(&existingRating).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rating"})
+		apierror.Abort(c, apierror.Internal("Failed to update rating", err))
 		return
 	}
+	moderation.Record(customerID, "worker_rating", existingRating.ID, originalComment, filteredComment)
 
 	// Update worker rating stats
 	if err := updateWorkerRatingStats(existingRating.WorkerID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rating updated but failed to update worker stats"})
+		apierror.Abort(c, apierror.Internal("Rating updated but failed to update worker stats", err))
 		return
 	}
 
@@ -326,7 +368,7 @@ func deleteRating(c *gin.Context) {
 	ratingIDStr := c.Param("ratingId")
 	ratingID, err := strconv.ParseUint(ratingIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rating ID"})
+		apierror.Abort(c, apierror.Validation("Invalid rating ID"))
 		return
 	}
 
@@ -337,27 +379,27 @@ func deleteRating(c *gin.Context) {
 	var existingRating models.WorkerRating
 	if err := database.DB.First(&existingRating, ratingID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Rating not found"})
+			apierror.Abort(c, apierror.NotFound("Rating not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rating"})
+			apierror.Abort(c, apierror.Internal("Failed to fetch rating", nil))
 		}
 		return
 	}
 
 	if existingRating.CustomerID != customerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own ratings"})
+		apierror.Abort(c, apierror.Forbidden("You can only delete your own ratings"))
 		return
 	}
 
 	// Delete the rating
 	if err := database.DB.Delete(&existingRating).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rating"})
+		apierror.Abort(c, apierror.Internal("Failed to delete rating", err))
 		return
 	}
 
 	// Update worker rating stats
 	if err := updateWorkerRatingStats(existingRating.WorkerID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Rating deleted but failed to update worker stats"})
+		apierror.Abort(c, apierror.Internal("Rating deleted but failed to update worker stats", err))
 		return
 	}
 
@@ -396,7 +438,7 @@ func getCustomerRatings(c *gin.Context) {
 		Offset(offset).
 		Limit(limit).
 		Find(&ratings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch ratings"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch ratings", nil))
 		return
 	}
 
@@ -419,8 +461,8 @@ func updateWorkerRatingStats(workerID uint) error {
 			AVG(CAST(stars AS DECIMAL(3,2))) as average_stars,
 			COUNT(*) as total_ratings
 		FROM worker_ratings 
-		WHERE worker_id = ? AND deleted_at IS NULL
-	`, workerID).Scan(&summary).Error; err != nil {
+		WHERE worker_id = ? AND deleted_at IS NULL AND moderation_status <> ?
+	`, workerID, models.ReviewHidden).Scan(&summary).Error; err != nil {
 		return err
 	}
 
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/services"
+)
+
+// RegisterRegionRoutes registers the public region listing
+func RegisterRegionRoutes(router *gin.RouterGroup) {
+	router.GET("/regions", GetRegions)
+}
+
+// GetRegions returns the active regions with their cities, currency, time zone and language, so
+// clients can format prices and pick a default language before the user signs in
+func GetRegions(c *gin.Context) {
+	regions, err := services.NewRegionService().Active()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch regions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    regions,
+	})
+}
@@ -0,0 +1,200 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/models"
+	"repair-service-server/repository/mock"
+	ws "repair-service-server/websocket"
+)
+
+// serveAs runs a single handler mounted on route for target, as if userID had signed in
+func serveAs(userID uint, route string, handler gin.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	}, handler)
+
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// decodeBody unmarshals a response body into out, failing the test on the wrong status
+func decodeBody(t *testing.T, recorder *httptest.ResponseRecorder, want int, out interface{}) {
+	t.Helper()
+	if recorder.Code != want {
+		t.Fatalf("status %d, want %d: %s", recorder.Code, want, recorder.Body)
+	}
+	if out == nil {
+		return
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminListsUsersFromRepo(t *testing.T) {
+	users := mock.NewUserRepo()
+	for _, user := range []models.User{
+		{FullName: "Sidi", Role: "worker"},
+		{FullName: "Mariem", Role: "customer"},
+		{FullName: "Ahmed", Role: "worker"},
+	} {
+		if err := users.Save(&user); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond) // Newest first needs distinct creation times
+	}
+	handler := NewAdminHandler(users, mock.NewRequestRepo())
+
+	var body struct {
+		Data  []map[string]interface{} `json:"data"`
+		Total int64                    `json:"total"`
+	}
+	recorder := serveAs(1, "/admin/users", handler.GetAllUsers, http.MethodGet, "/admin/users?role=worker&limit=1&page=2", "")
+	decodeBody(t, recorder, http.StatusOK, &body)
+	if body.Total != 2 {
+		t.Errorf("total = %d, want the 2 workers", body.Total)
+	}
+	if len(body.Data) != 1 || body.Data[0]["full_name"] != "Sidi" {
+		t.Errorf("page 2 = %v, want only the older worker Sidi", body.Data)
+	}
+
+	recorder = serveAs(1, "/admin/users/:id", handler.GetUserById, http.MethodGet, "/admin/users/42", "")
+	decodeBody(t, recorder, http.StatusNotFound, nil)
+}
+
+func TestAdminGetsServiceRequestFromRepo(t *testing.T) {
+	requests := mock.NewRequestRepo()
+	requests.Add(models.CustomerServiceRequest{ID: 7, CustomerID: 3, Title: "Leaking sink", Status: "pending"})
+	requests.Add(models.CustomerServiceRequest{ID: 8, CustomerID: 3, Title: "Broken socket", Status: "completed"})
+	handler := NewAdminHandler(mock.NewUserRepo(), requests)
+
+	var found struct {
+		Data struct {
+			ID     uint   `json:"id"`
+			Title  string `json:"title"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	recorder := serveAs(1, "/admin/service-requests/:id", handler.GetServiceRequestById, http.MethodGet, "/admin/service-requests/7", "")
+	decodeBody(t, recorder, http.StatusOK, &found)
+	if found.Data.ID != 7 || found.Data.Title != "Leaking sink" || found.Data.Status != "pending" {
+		t.Errorf("request = %+v, want request 7", found.Data)
+	}
+
+	recorder = serveAs(1, "/admin/service-requests/:id", handler.GetServiceRequestById, http.MethodGet, "/admin/service-requests/9", "")
+	decodeBody(t, recorder, http.StatusNotFound, nil)
+
+	var list struct {
+		Data  []map[string]interface{} `json:"data"`
+		Total int64                    `json:"total"`
+	}
+	recorder = serveAs(1, "/admin/service-requests", handler.GetAllServiceRequests, http.MethodGet, "/admin/service-requests?status=completed", "")
+	decodeBody(t, recorder, http.StatusOK, &list)
+	if list.Total != 1 || len(list.Data) != 1 || list.Data[0]["id"] != float64(8) {
+		t.Errorf("completed requests = %v (total %d), want only request 8", list.Data, list.Total)
+	}
+}
+
+func TestChatRoomCreatedOnceFromRepo(t *testing.T) {
+	requests := mock.NewRequestRepo()
+	requests.Add(models.CustomerServiceRequest{ID: 5, CustomerID: 10, Title: "Leaking sink", Status: "accepted"})
+	chats := mock.NewChatRepo()
+	handler := NewChatHandler(chats, requests, mock.NewUserRepo(), ws.NewHub())
+	body := `{"worker_id": 20, "service_request_id": 5}`
+
+	var created struct {
+		ChatRoom struct {
+			ID               uint `json:"id"`
+			CustomerID       uint `json:"customer_id"`
+			WorkerID         uint `json:"worker_id"`
+			ServiceRequestID uint `json:"service_request_id"`
+		} `json:"chat_room"`
+	}
+	recorder := serveAs(10, "/chat/rooms", handler.createChatRoom, http.MethodPost, "/chat/rooms", body)
+	decodeBody(t, recorder, http.StatusCreated, &created)
+	if room := created.ChatRoom; room.ID == 0 || room.CustomerID != 10 || room.WorkerID != 20 || room.ServiceRequestID != 5 {
+		t.Errorf("created room = %+v, want customer 10, worker 20 and request 5", room)
+	}
+
+	var existing struct {
+		ChatRoom struct {
+			ID uint `json:"id"`
+		} `json:"chat_room"`
+	}
+	recorder = serveAs(10, "/chat/rooms", handler.createChatRoom, http.MethodPost, "/chat/rooms", body)
+	decodeBody(t, recorder, http.StatusOK, &existing)
+	if existing.ChatRoom.ID != created.ChatRoom.ID {
+		t.Errorf("second create returned room %d, want the existing room %d", existing.ChatRoom.ID, created.ChatRoom.ID)
+	}
+	if len(chats.Rooms) != 1 {
+		t.Errorf("repo has %d rooms, want 1", len(chats.Rooms))
+	}
+
+	// Another customer cannot open a room on the request
+	recorder = serveAs(11, "/chat/rooms", handler.createChatRoom, http.MethodPost, "/chat/rooms", body)
+	decodeBody(t, recorder, http.StatusNotFound, nil)
+
+	var listed struct {
+		ChatRooms []struct {
+			ID uint `json:"id"`
+		} `json:"chat_rooms"`
+	}
+	recorder = serveAs(20, "/chat/rooms", handler.getChatRooms, http.MethodGet, "/chat/rooms", "")
+	decodeBody(t, recorder, http.StatusOK, &listed)
+	if len(listed.ChatRooms) != 1 || listed.ChatRooms[0].ID != created.ChatRoom.ID {
+		t.Errorf("worker's rooms = %+v, want room %d", listed.ChatRooms, created.ChatRoom.ID)
+	}
+}
+
+func TestWorkerMonthlyTrendsFromRepo(t *testing.T) {
+	users := mock.NewUserRepo()
+	users.Workers[3] = models.WorkerProfile{ID: 30, UserID: 3}
+	analytics := mock.NewAnalyticsRepo()
+	now := time.Now()
+	analytics.Monthly = []models.WorkerMonthlyStats{
+		{WorkerID: 30, Year: now.Year(), Month: int(now.Month()), JobsCompleted: 4},
+		{WorkerID: 31, Year: now.Year(), Month: int(now.Month()), JobsCompleted: 9}, // Another worker
+	}
+	handler := NewWorkerAnalyticsHandler(users, analytics)
+
+	var body struct {
+		Months int `json:"months"`
+		Trends []struct {
+			Label         string `json:"label"`
+			HasData       bool   `json:"has_data"`
+			JobsCompleted int    `json:"jobs_completed"`
+		} `json:"trends"`
+	}
+	recorder := serveAs(3, "/analytics/trends/monthly", handler.getWorkerMonthlyTrends, http.MethodGet, "/analytics/trends/monthly?months=3", "")
+	decodeBody(t, recorder, http.StatusOK, &body)
+	if body.Months != 3 || len(body.Trends) != 3 {
+		t.Fatalf("got %d months and %d trends, want 3", body.Months, len(body.Trends))
+	}
+	current := body.Trends[2]
+	if current.Label != now.Format("2006-01") || !current.HasData || current.JobsCompleted != 4 {
+		t.Errorf("current month = %+v, want %s with the worker's 4 jobs", current, now.Format("2006-01"))
+	}
+	if body.Trends[0].HasData || body.Trends[1].HasData {
+		t.Errorf("earlier months = %+v, want no data", body.Trends[:2])
+	}
+
+	// Users without a worker profile have no trends
+	recorder = serveAs(4, "/analytics/trends/monthly", handler.getWorkerMonthlyTrends, http.MethodGet, "/analytics/trends/monthly", "")
+	decodeBody(t, recorder, http.StatusNotFound, nil)
+}
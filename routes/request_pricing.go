@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// previewRequestPrice returns the price breakdown a request would get, for the app to show before
+// the customer submits it
+func previewRequestPrice(c *gin.Context) {
+	var req struct {
+		ServiceOptionID uint    `json:"service_option_id" binding:"required"`
+		CategoryID      uint    `json:"category_id"`
+		Priority        string  `json:"priority" binding:"omitempty,priority"`
+		ScheduledFor    string  `json:"scheduled_for"` // ISO8601, now when empty
+		Timezone        string  `json:"timezone" binding:"omitempty,max=64"`
+		LocationLat     float64 `json:"location_lat" binding:"required,latitude"`
+		LocationLng     float64 `json:"location_lng" binding:"required,longitude"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	serviceRequest := models.CustomerServiceRequest{
+		CustomerID:      c.GetUint("user_id"),
+		CategoryID:      req.CategoryID,
+		ServiceOptionID: &req.ServiceOptionID,
+		Priority:        req.Priority,
+		LocationLat:     &req.LocationLat,
+		LocationLng:     &req.LocationLng,
+	}
+	if req.ScheduledFor != "" {
+		if !scheduleRequest(c, &serviceRequest, req.ScheduledFor, req.Timezone) {
+			return
+		}
+	}
+
+	if !priceRequest(c, &serviceRequest) {
+		return
+	}
+
+	breakdown := serviceRequest.PriceBreakdown
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    breakdown,
+		"formatted": formatAmounts(c, breakdown.Currency, map[string]money.Amount{
+			"base_price": breakdown.BasePrice,
+			"total":      breakdown.Total,
+		}),
+		"duration_estimate": requestDurationEstimate(serviceRequest),
+	})
+}
+
+// getDurationEstimate returns how long jobs of a category, and service option when given, usually
+// take. data is null until enough of them were timed.
+func getDurationEstimate(c *gin.Context) {
+	var req struct {
+		CategoryID      uint  `form:"category_id" json:"category_id" binding:"required"`
+		ServiceOptionID *uint `form:"service_option_id"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	estimate, err := services.NewDurationEstimateService().Estimate(req.CategoryID, req.ServiceOptionID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to estimate duration", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    estimate,
+	})
+}
+
+// requestDurationEstimate is the duration range of a request's kind of job for API responses,
+// nil when unknown or when it cannot be loaded
+func requestDurationEstimate(request models.CustomerServiceRequest) *models.DurationEstimate {
+	estimate, err := services.NewDurationEstimateService().ForRequest(request)
+	if err != nil {
+		log.Printf("⚠️ Failed to estimate duration of category %d: %v", request.CategoryID, err)
+		return nil
+	}
+	return estimate
+}
+
+// priceRequest attaches the price breakdown of the request's service option before it is created.
+// It writes the error response itself.
+func priceRequest(c *gin.Context, serviceRequest *models.CustomerServiceRequest) bool {
+	breakdown, err := services.NewPricingService().QuoteRequest(*serviceRequest)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownServiceOption) {
+			validation.Fail(c, "service_option_id", "default", "")
+			return false
+		}
+		apierror.Abort(c, apierror.Internal("Failed to price service request", err))
+		return false
+	}
+	serviceRequest.PriceBreakdown = breakdown
+	return true
+}
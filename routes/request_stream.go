@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+
+	ws "repair-service-server/websocket"
+)
+
+var requestHub *ws.RequestHub
+
+// SetRequestHub sets the hub used to stream live request updates to customers
+func SetRequestHub(hub *ws.RequestHub) {
+	requestHub = hub
+}
+
+// requestStatusData is what a customer's live stream says about the request's progress
+func requestStatusData(event string, request models.CustomerServiceRequest) gin.H {
+	return gin.H{
+		"event":              event,
+		"status":             request.Status,
+		"assigned_worker_id": request.AssignedWorkerID,
+		"eta_minutes":        request.EtaMinutes,
+		"eta_updated_at":     request.EtaUpdatedAt,
+		"en_route_at":        request.EnRouteAt,
+		"arrived_at":         request.ArrivedAt,
+		"started_at":         request.StartedAt,
+		"completed_at":       request.CompletedAt,
+		"expires_at":         request.ExpiresAt,
+		"scheduled_for":      request.ScheduledFor,
+	}
+}
+
+// pushRequestStatus streams a status change to the customers watching the request and ends
+// their streams once the request is finished
+func pushRequestStatus(event string, request models.CustomerServiceRequest) {
+	if requestHub == nil {
+		return
+	}
+	data := requestStatusData(event, request)
+	go func() {
+		requestHub.Publish(request.ID, "status_changed", data)
+		if request.Status.IsFinished() {
+			requestHub.Close(request.ID, "request "+string(request.Status))
+		}
+	}()
+}
+
+// pushRequestStatusByID reloads the request and streams its status, for changes made by services
+// that do not hand the request back
+func pushRequestStatusByID(event string, requestID uint) {
+	if requestHub == nil {
+		return
+	}
+	var request models.CustomerServiceRequest
+	if err := database.DB.First(&request, requestID).Error; err != nil {
+		log.Printf("⚠️ Failed to load service request %d for its live stream: %v", requestID, err)
+		return
+	}
+	pushRequestStatus(event, request)
+}
+
+// pushRequestUpdate streams an update other than a status change to the customers watching the
+// request
+func pushRequestUpdate(requestID uint, eventType string, data gin.H) {
+	if requestHub == nil {
+		return
+	}
+	go requestHub.Publish(requestID, eventType, data)
+}
+
+// OnRequestExpired reports a broadcast request the expiration job gave up on
+func OnRequestExpired(request models.CustomerServiceRequest) {
+	publishRequestEvent("request_expired", request)
+}
+
+// loadWatchedRequest loads the request in the :id param for a customer following it live
+func loadWatchedRequest(c *gin.Context) (models.CustomerServiceRequest, bool) {
+	var serviceRequest models.CustomerServiceRequest
+	if requestHub == nil {
+		apierror.Abort(c, apierror.Unavailable("Request updates are not available", nil))
+		return serviceRequest, false
+	}
+	userID := c.GetUint("user_id")
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid service request ID"))
+		return serviceRequest, false
+	}
+
+	if err := database.DB.Preload("AssignedWorker").First(&serviceRequest, requestID).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return serviceRequest, false
+	}
+	if serviceRequest.CustomerID != userID && !ownsRequestOrganization(userID, serviceRequest) {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return serviceRequest, false
+	}
+	return serviceRequest, true
+}
+
+// requestSnapshot is the first event of a live stream, so the client starts from the current state
+func requestSnapshot(serviceRequest models.CustomerServiceRequest) gin.H {
+	snapshot := requestStatusData("snapshot", serviceRequest)
+	// The worker's position is only shared while they are on their way
+	if serviceRequest.Status == models.RequestStatusEnRoute && serviceRequest.AssignedWorker != nil {
+		snapshot["latitude"] = serviceRequest.AssignedWorker.CurrentLat
+		snapshot["longitude"] = serviceRequest.AssignedWorker.CurrentLng
+	}
+	return snapshot
+}
+
+// HandleRequestStream upgrades a customer connection to the live updates of one of their
+// requests: status changes, the travelling worker's position and ETA, and auto-dispatch offers.
+// The server closes the stream once the request is completed, cancelled or expired.
+func HandleRequestStream(c *gin.Context) {
+	serviceRequest, ok := loadWatchedRequest(c)
+	if !ok {
+		return
+	}
+	if serviceRequest.Status.IsFinished() {
+		apierror.Abort(c, apierror.Conflict("Service request is already "+string(serviceRequest.Status)))
+		return
+	}
+	requestHub.Serve(c.Writer, c.Request, serviceRequest.ID, c.GetUint("user_id"), requestSnapshot(serviceRequest))
+}
+
+// HandleRequestEventStream serves the same updates as HandleRequestStream as Server-Sent Events,
+// for clients that cannot open a WebSocket. A client reconnecting with Last-Event-ID gets the
+// events it missed, and a finished request answers 204 once there is nothing left to replay.
+func HandleRequestEventStream(c *gin.Context) {
+	serviceRequest, ok := loadWatchedRequest(c)
+	if !ok {
+		return
+	}
+	requestHub.ServeSSE(c.Writer, c.Request, serviceRequest.ID, requestSnapshot(serviceRequest), serviceRequest.Status.IsFinished())
+}
@@ -0,0 +1,65 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/middleware"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// suggestServiceRequest suggests a category, service option, priority and price range from the
+// customer's description and optional photo, for the app to pre-fill the request form
+func suggestServiceRequest(c *gin.Context) {
+	if result := middleware.AllowAIPrompt(middleware.RateLimitIdentity(c)); !result.Allowed {
+		middleware.AbortRateLimited(c, result)
+		return
+	}
+
+	var req struct {
+		Description string `form:"description" binding:"required,min=3,max=2000"`
+		Language    string `form:"language" binding:"omitempty,oneof=fr ar en"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+
+	var photo []byte
+	if header, _ := c.FormFile("image"); header != nil {
+		file, err := header.Open()
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to read image", err))
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(file, header.Size+1))
+		file.Close()
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to read image", err))
+			return
+		}
+		if photo, err = services.PrepareDiagnosisImage(data); err != nil {
+			if errors.Is(err, services.ErrInvalidImage) {
+				apierror.Abort(c, apierror.Validation(services.ErrInvalidImage.Error()))
+				return
+			}
+			apierror.Abort(c, apierror.Internal("Failed to process image", err))
+			return
+		}
+	}
+
+	suggestion, err := services.NewAIService().SuggestRequest(req.Description, photo, req.Language)
+	if err != nil {
+		apierror.Abort(c, apierror.Unavailable("Failed to suggest a category. Please choose one.", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    suggestion,
+	})
+}
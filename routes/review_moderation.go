@@ -0,0 +1,297 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// errReviewAlreadyFlagged aborts a flag the user has already raised on the rating
+var errReviewAlreadyFlagged = errors.New("rating already flagged by this user")
+
+// replyToRating lets the rated worker post or replace a public reply to a rating
+func replyToRating(c *gin.Context) {
+	var req struct {
+		Reply string `json:"reply" binding:"required,max=1000"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	rating, ok := loadRatingForWorker(c)
+	if !ok {
+		return
+	}
+
+	moderation := services.NewContentModerationService()
+	filtered := moderation.Filter(req.Reply)
+
+	now := time.Now()
+	rating.WorkerReply = filtered.Text
+	rating.WorkerRepliedAt = &now
+	if err := database.DB.Model(&rating).Updates(map[string]interface{}{
+		"worker_reply":      rating.WorkerReply,
+		"worker_replied_at": rating.WorkerRepliedAt,
+	}).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to save reply", err))
+		return
+	}
+	moderation.Record(c.GetUint("user_id"), "rating_reply", rating.ID, req.Reply, filtered)
+
+	if err := SendLocalizedPushNotification(rating.CustomerID, "notification.review_reply", i18n.Vars{"reply": rating.WorkerReply}, "review_reply", map[string]interface{}{
+		"rating_id": rating.ID,
+	}); err != nil {
+		log.Printf("⚠️ Failed to send review reply notification: %v", err)
+	}
+
+	log.Printf("💬 Worker %d replied to rating %d", rating.WorkerID, rating.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Reply posted",
+		"data":    rating,
+	})
+}
+
+// deleteRatingReply removes the rated worker's reply
+func deleteRatingReply(c *gin.Context) {
+	rating, ok := loadRatingForWorker(c)
+	if !ok {
+		return
+	}
+
+	if err := database.DB.Model(&rating).Updates(map[string]interface{}{
+		"worker_reply":      "",
+		"worker_replied_at": nil,
+	}).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to delete reply", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Reply deleted",
+	})
+}
+
+// flagRating reports a rating to the admin moderation queue. Each user can flag a rating once.
+func flagRating(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req struct {
+		Reason  string `json:"reason" binding:"required,oneof=offensive spam other"`
+		Details string `json:"details" binding:"max=1000"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var rating models.WorkerRating
+	if err := database.DB.First(&rating, c.Param("ratingId")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Rating not found"))
+		return
+	}
+	if rating.CustomerID == userID {
+		apierror.Abort(c, apierror.Validation("You cannot flag your own rating"))
+		return
+	}
+	if rating.ModerationStatus == models.ReviewHidden {
+		apierror.Abort(c, apierror.Conflict("Rating has already been hidden"))
+		return
+	}
+
+	flag := models.ReviewFlag{
+		RatingID:   rating.ID,
+		ReporterID: userID,
+		Reason:     models.ReviewFlagReason(req.Reason),
+		Details:    req.Details,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&flag)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errReviewAlreadyFlagged
+		}
+		return tx.Model(&rating).Updates(map[string]interface{}{
+			"flag_count":        gorm.Expr("flag_count + 1"),
+			"moderation_status": models.ReviewFlagged,
+		}).Error
+	})
+	switch {
+	case errors.Is(err, errReviewAlreadyFlagged):
+		apierror.Abort(c, apierror.Conflict("You have already flagged this rating"))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to flag rating", err))
+		return
+	}
+
+	publishOpsEvent("review_flagged", gin.H{
+		"rating_id": rating.ID,
+		"worker_id": rating.WorkerID,
+		"reason":    flag.Reason,
+	})
+
+	log.Printf("🚩 User %d flagged rating %d as %s", userID, rating.ID, flag.Reason)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Rating reported for review",
+	})
+}
+
+// GetFlaggedReviews is the admin moderation queue: flagged ratings by default, or hidden ones
+// with status=hidden, each with its flags
+func GetFlaggedReviews(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	offset := (page - 1) * limit
+
+	status := models.ReviewModerationStatus(c.DefaultQuery("status", string(models.ReviewFlagged)))
+	if status != models.ReviewFlagged && status != models.ReviewHidden {
+		apierror.Abort(c, apierror.Validation("status must be flagged or hidden"))
+		return
+	}
+
+	query := database.DB.Model(&models.WorkerRating{}).Where("moderation_status = ?", status)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to count flagged reviews", err))
+		return
+	}
+
+	var ratings []models.WorkerRating
+	if err := query.Preload("Customer").Preload("Worker").Preload("Flags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at")
+	}).Order("flag_count DESC, updated_at").Offset(offset).Limit(limit).Find(&ratings).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch flagged reviews", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    ratings,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// HideReview hides a rating from the public and from the worker's score
+func HideReview(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason" binding:"required,max=500"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	moderateReview(c, models.ReviewHidden, req.Reason)
+}
+
+// RestoreReview shows a flagged or hidden rating again and dismisses its open flags
+func RestoreReview(c *gin.Context) {
+	var req struct {
+		Note string `json:"note" binding:"max=500"`
+	}
+	if c.Request.ContentLength > 0 && !validation.BindJSON(c, &req) {
+		return
+	}
+	moderateReview(c, models.ReviewVisible, req.Note)
+}
+
+// moderateReview applies an admin decision to a rating, resolves its open flags and recomputes
+// the worker's score
+func moderateReview(c *gin.Context, status models.ReviewModerationStatus, note string) {
+	adminID := c.GetUint("user_id")
+
+	var rating models.WorkerRating
+	if err := database.DB.First(&rating, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Rating not found"))
+		return
+	}
+	if rating.ModerationStatus == status {
+		apierror.Abort(c, apierror.Conflict("Rating is already "+string(status)))
+		return
+	}
+
+	before := rating
+	now := time.Now()
+	rating.ModerationStatus = status
+	rating.ModeratedBy = &adminID
+	rating.ModeratedAt = &now
+	rating.ModerationNote = note
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&rating).Updates(map[string]interface{}{
+			"moderation_status": rating.ModerationStatus,
+			"moderated_by":      rating.ModeratedBy,
+			"moderated_at":      rating.ModeratedAt,
+			"moderation_note":   rating.ModerationNote,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ReviewFlag{}).Where("rating_id = ? AND resolved_at IS NULL", rating.ID).
+			Update("resolved_at", now).Error
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to moderate rating", err))
+		return
+	}
+	middleware.RecordAuditChange(c, "worker_ratings", rating.ID, before, rating)
+
+	if err := updateWorkerRatingStats(rating.WorkerID); err != nil {
+		log.Printf("⚠️ Failed to update rating stats for worker %d: %v", rating.WorkerID, err)
+	}
+
+	log.Printf("🛡️ Admin %d set rating %d to %s", adminID, rating.ID, status)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Rating " + string(status),
+		"data":    rating,
+	})
+}
+
+// loadRatingForWorker loads the rating in the path and checks the caller is the rated worker
+func loadRatingForWorker(c *gin.Context) (models.WorkerRating, bool) {
+	var rating models.WorkerRating
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", c.GetUint("user_id")).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return rating, false
+	}
+	if err := database.DB.First(&rating, c.Param("ratingId")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Rating not found"))
+		return rating, false
+	}
+	if rating.WorkerID != workerProfile.ID {
+		apierror.Abort(c, apierror.Forbidden("You can only reply to your own ratings"))
+		return rating, false
+	}
+	return rating, true
+}
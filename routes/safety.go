@@ -0,0 +1,196 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// raiseSOS lets the customer or the assigned worker flag an emergency during a job. The request is
+// put on hold, admins are alerted on the ops channel and the other party is notified.
+func raiseSOS(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req models.SOSRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	role := models.RoleCustomer
+	if serviceRequest.CustomerID != user.ID {
+		workerProfile, ok := currentWorkerProfile(c)
+		if !ok {
+			return
+		}
+		if serviceRequest.AssignedWorkerID == nil || *serviceRequest.AssignedWorkerID != workerProfile.ID {
+			apierror.Abort(c, apierror.NotFound("Service request not found"))
+			return
+		}
+		role = models.RoleWorker
+	}
+
+	incident, created, err := services.NewSafetyService().RaiseSOS(serviceRequest.ID, user, role, req)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrSOSNotAllowed):
+		apierror.Abort(c, apierror.Unprocessable(err.Error()))
+		return
+	default:
+		apierror.Abort(c, apierror.Internal("Failed to raise SOS", err))
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "An SOS is already open for this request",
+			"data":    incident,
+		})
+		return
+	}
+
+	log.Printf("🆘 SOS %d raised by %s %d on service request %d", incident.ID, role, user.ID, serviceRequest.ID)
+	pushRequestStatusByID("sos_raised", serviceRequest.ID)
+	publishOpsEvent("sos_raised", gin.H{
+		"incident_id":                incident.ID,
+		"request_id":                 serviceRequest.ID,
+		"title":                      serviceRequest.Title,
+		"reported_by_id":             user.ID,
+		"reporter_role":              role,
+		"lat":                        incident.Lat,
+		"lng":                        incident.Lng,
+		"message":                    incident.Message,
+		"previous_status":            incident.PreviousStatus,
+		"emergency_contact_notified": incident.EmergencyContactNotified,
+		"created_at":                 incident.CreatedAt,
+	})
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "SOS raised, our team has been alerted",
+		"data":    incident,
+	})
+}
+
+// GetSafetyIncidents lists SOS incidents for admins, newest first, optionally filtered by status
+func GetSafetyIncidents(c *gin.Context) {
+	var req struct {
+		Status string `form:"status" binding:"omitempty,oneof=open resolved"`
+		Page   int    `form:"page" binding:"omitempty,min=1"`
+		Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	incidents, total, err := services.NewSafetyService().List(models.SafetyIncidentStatus(req.Status), (req.Page-1)*req.Limit, req.Limit)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch safety incidents", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"incidents": incidents,
+			"pagination": gin.H{
+				"page":        req.Page,
+				"limit":       req.Limit,
+				"total":       total,
+				"total_pages": (total + int64(req.Limit) - 1) / int64(req.Limit),
+			},
+		},
+	})
+}
+
+// GetSafetyIncident returns an SOS incident with its request and reporter
+func GetSafetyIncident(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid safety incident ID"))
+		return
+	}
+
+	incident, err := services.NewSafetyService().Find(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apierror.Abort(c, apierror.NotFound("Safety incident not found"))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch safety incident", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    incident,
+	})
+}
+
+// ResolveSafetyIncident records an admin's review of an SOS and either resumes or cancels the
+// request it froze
+func ResolveSafetyIncident(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid safety incident ID"))
+		return
+	}
+	var req models.SafetyResolveRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	adminID := c.GetUint("user_id")
+	incident, err := services.NewSafetyService().Resolve(id, adminID, req)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("Safety incident not found"))
+		return
+	case errors.Is(err, services.ErrIncidentResolved):
+		apierror.Abort(c, apierror.Conflict(err.Error()))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to resolve safety incident", err))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "safety_incident", incident.ID,
+		gin.H{"status": models.SafetyIncidentOpen},
+		gin.H{"status": incident.Status, "resolution": incident.Resolution, "note": incident.ResolutionNote})
+	log.Printf("🛟 SOS %d resolved (%s) by admin %d", incident.ID, incident.Resolution, adminID)
+	pushRequestStatusByID("sos_resolved", incident.ServiceRequestID)
+	publishOpsEvent("sos_resolved", gin.H{
+		"incident_id": incident.ID,
+		"request_id":  incident.ServiceRequestID,
+		"resolution":  incident.Resolution,
+		"resolved_by": adminID,
+		"resolved_at": incident.ResolvedAt,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Safety incident resolved",
+		"data":    incident,
+	})
+}
@@ -0,0 +1,107 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RegisterSearchRoutes registers the public search endpoint
+func RegisterSearchRoutes(router *gin.RouterGroup) {
+	router.GET("/search", search)
+}
+
+// searchResult is one service or worker in the mixed search results
+type searchResult struct {
+	Type    string                            `json:"type"`
+	Rank    float64                           `json:"rank"`
+	Service *models.ServiceResponse           `json:"service,omitempty"`
+	Worker  *serializers.WorkerPublicResponse `json:"worker,omitempty"`
+}
+
+// search returns services and workers matching q, best match first
+func search(c *gin.Context) {
+	var req struct {
+		Query string `form:"q" binding:"required,min=2,max=100"`
+		Type  string `form:"type" binding:"omitempty,oneof=service worker"`
+		Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+	var types []string
+	if req.Type != "" {
+		types = []string{req.Type}
+	}
+
+	hits, err := services.NewSearchService().Search(req.Query, types, req.Limit)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to search", err))
+		return
+	}
+
+	var serviceIDs, workerIDs []uint
+	for _, hit := range hits {
+		if hit.Type == services.SearchTypeService {
+			serviceIDs = append(serviceIDs, hit.ID)
+		} else {
+			workerIDs = append(workerIDs, hit.ID)
+		}
+	}
+
+	found := make(map[uint]models.Service, len(serviceIDs))
+	if len(serviceIDs) > 0 {
+		var list []models.Service
+		if err := database.DB.Preload("Category").Where("id IN ?", serviceIDs).Find(&list).Error; err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to load services", err))
+			return
+		}
+		for _, service := range list {
+			found[service.ID] = service
+		}
+	}
+	workers := make(map[uint]models.WorkerProfile, len(workerIDs))
+	if len(workerIDs) > 0 {
+		var list []models.WorkerProfile
+		if err := database.DB.Preload("User").Preload("Category").Where("id IN ?", workerIDs).Find(&list).Error; err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to load workers", err))
+			return
+		}
+		for _, worker := range list {
+			workers[worker.ID] = worker
+		}
+	}
+
+	lang := i18n.Locale(c)
+	results := make([]searchResult, 0, len(hits))
+	for _, hit := range hits {
+		result := searchResult{Type: hit.Type, Rank: hit.Rank}
+		if service, ok := found[hit.ID]; ok && hit.Type == services.SearchTypeService {
+			response := serviceResponseOf(service, lang)
+			result.Service = &response
+		} else if worker, ok := workers[hit.ID]; ok && hit.Type == services.SearchTypeWorker {
+			response := serializers.WorkerPublic(worker)
+			result.Worker = &response
+		} else {
+			continue // Deleted between the search and the load
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"query":   req.Query,
+		"results": results,
+	})
+}
@@ -5,7 +5,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"repair-service-server/apierror"
+	"repair-service-server/cache"
 	"repair-service-server/database"
+	"repair-service-server/i18n"
 	"repair-service-server/middleware"
 	"repair-service-server/models"
 
@@ -15,9 +18,9 @@ import (
 // RegisterServiceRoutes registers all service-related routes
 func RegisterServiceRoutes(router *gin.RouterGroup) {
 	// Public routes
-	router.GET("", getAllServicesUpdated)
+	router.GET("", middleware.ConditionalGET(), getAllServicesUpdated)
 	router.GET("/:id", getService)
-	router.GET("/category/:category", getServicesByCategory)
+	router.GET("/category/:category", middleware.ConditionalGET(), getServicesByCategory)
 	router.POST("/seed", seedServicesPublic) // Public seed endpoint
 
 	// Protected routes (admin only)
@@ -32,40 +35,29 @@ func RegisterServiceRoutes(router *gin.RouterGroup) {
 
 // getAllServicesUpdated returns all active services with all fields
 func getAllServicesUpdated(c *gin.Context) {
-	var services []models.Service
-	result := database.DB.Where("is_active = ?", true).Preload("Category").Find(&services)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch services"})
-		return
-	}
+	services, err := cache.GetOrLoad(catalogKey(serviceCacheKey, "active"), catalogCacheTTL(), func() ([]models.Service, error) {
+		var services []models.Service
+		if err := database.DB.Where("is_active = ?", true).Preload("Category").Find(&services).Error; err != nil {
+			return nil, err
+		}
 
-	// Debug logging
-	log.Printf("🔍 Found %d services in database", len(services))
-	for i, service := range services {
-		log.Printf("Service %d: ID=%d, Name=%s, CategoryID=%d, CategoryName=%s, ImageURL=%s", 
-			i+1, service.ID, service.Name, service.CategoryID, service.Category.Name, service.ImageURL)
+		// Debug logging
+		log.Printf("🔍 Found %d services in database", len(services))
+		for i, service := range services {
+			log.Printf("Service %d: ID=%d, Name=%s, CategoryID=%d, CategoryName=%s, ImageURL=%s", 
+				i+1, service.ID, service.Name, service.CategoryID, service.Category.Name, service.ImageURL)
+		}
+		return services, nil
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch services", err))
+		return
 	}
 
+	lang := i18n.Locale(c)
 	var responses []models.ServiceResponse
 	for _, service := range services {
-		responses = append(responses, models.ServiceResponse{
-			ID:            service.ID,
-			CategoryID:    service.CategoryID,
-			Category:      service.Category,
-			Name:          service.Name,
-			Description:   service.Description,
-			Price:         service.Price,
-			ImageURL:      service.ImageURL,
-			Duration:      service.Duration,
-			IsActive:      service.IsActive,
-			CreatedAt:     service.CreatedAt,
-			NameAr:        service.NameAr,
-			DescriptionAr: service.DescriptionAr,
-			BasePrice:     service.BasePrice,
-			PriceUnit:     service.PriceUnit,
-			Guarantee:     service.Guarantee,
-			Policies:      service.Policies,
-		})
+		responses = append(responses, serviceResponseOf(service, lang))
 	}
 
 	c.JSON(http.StatusOK, gin.H{"services": responses})
@@ -76,23 +68,33 @@ func getService(c *gin.Context) {
 	id := c.Param("id")
 	serviceID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service ID"})
+		apierror.Abort(c, apierror.Validation("Invalid service ID"))
 		return
 	}
 
-	var service models.Service
-	result := database.DB.Preload("Category").First(&service, serviceID)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+	service, err := cache.GetOrLoad(catalogKey(serviceCacheKey, "id", serviceID), catalogCacheTTL(), func() (models.Service, error) {
+		var service models.Service
+		err := database.DB.Preload("Category").First(&service, serviceID).Error
+		return service, err
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Service not found"))
 		return
 	}
 
-	response := models.ServiceResponse{
+	c.JSON(http.StatusOK, gin.H{"service": serviceResponseOf(service, i18n.Locale(c))})
+}
+
+// serviceResponseOf returns all public fields of a service, with its name, description and
+// category in lang
+func serviceResponseOf(service models.Service, lang string) models.ServiceResponse {
+	localized := service.Localized(lang)
+	return models.ServiceResponse{
 		ID:            service.ID,
 		CategoryID:    service.CategoryID,
-		Category:      service.Category,
-		Name:          service.Name,
-		Description:   service.Description,
+		Category:      localized.Category,
+		Name:          localized.Name,
+		Description:   localized.Description,
 		Price:         service.Price,
 		ImageURL:      service.ImageURL,
 		Duration:      service.Duration,
@@ -105,8 +107,6 @@ func getService(c *gin.Context) {
 		Guarantee:     service.Guarantee,
 		Policies:      service.Policies,
 	}
-
-	c.JSON(http.StatusOK, gin.H{"service": response})
 }
 
 // getServicesByCategory returns services filtered by category
@@ -114,19 +114,24 @@ func getServicesByCategory(c *gin.Context) {
 	categoryID := c.Param("category")
 	categoryIDUint, err := strconv.ParseUint(categoryID, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		apierror.Abort(c, apierror.Validation("Invalid category ID"))
 		return
 	}
 	
-	var services []models.Service
-	result := database.DB.Where("category_id = ? AND is_active = ?", categoryIDUint, true).Preload("Category").Find(&services)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch services"})
+	services, err := cache.GetOrLoad(catalogKey(serviceCacheKey, "category", categoryIDUint), catalogCacheTTL(), func() ([]models.Service, error) {
+		var services []models.Service
+		err := database.DB.Where("category_id = ? AND is_active = ?", categoryIDUint, true).Preload("Category").Find(&services).Error
+		return services, err
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch services", err))
 		return
 	}
 
+	lang := i18n.Locale(c)
 	var responses []models.ServiceResponse
 	for _, service := range services {
+		service = service.Localized(lang)
 		responses = append(responses, models.ServiceResponse{
 			ID:          service.ID,
 			CategoryID:  service.CategoryID,
@@ -147,7 +152,7 @@ func getServicesByCategory(c *gin.Context) {
 func createService(c *gin.Context) {
 	var request models.ServiceRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 
@@ -162,10 +167,11 @@ func createService(c *gin.Context) {
 
 	result := database.DB.Create(&service)
 	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service"})
+		apierror.Abort(c, apierror.Internal("Failed to create service", result.Error))
 		return
 	}
 
+	invalidateServiceCache()
 	c.JSON(http.StatusCreated, gin.H{"message": "Service created successfully", "service_id": service.ID})
 }
 
@@ -174,20 +180,20 @@ func updateService(c *gin.Context) {
 	id := c.Param("id")
 	serviceID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service ID"})
+		apierror.Abort(c, apierror.Validation("Invalid service ID"))
 		return
 	}
 
 	var request models.ServiceRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.Validation(err.Error()))
 		return
 	}
 
 	var service models.Service
 	result := database.DB.First(&service, serviceID)
 	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		apierror.Abort(c, apierror.NotFound("Service not found"))
 		return
 	}
 
@@ -199,6 +205,7 @@ func updateService(c *gin.Context) {
 	service.Duration = request.Duration
 
 	database.DB.Save(&service)
+	invalidateServiceCache()
 	c.JSON(http.StatusOK, gin.H{"message": "Service updated successfully"})
 }
 
@@ -207,19 +214,20 @@ func deleteService(c *gin.Context) {
 	id := c.Param("id")
 	serviceID, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service ID"})
+		apierror.Abort(c, apierror.Validation("Invalid service ID"))
 		return
 	}
 
 	var service models.Service
 	result := database.DB.First(&service, serviceID)
 	if result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service not found"})
+		apierror.Abort(c, apierror.NotFound("Service not found"))
 		return
 	}
 
 	// Soft delete
 	database.DB.Delete(&service)
+	invalidateServiceCache()
 	c.JSON(http.StatusOK, gin.H{"message": "Service deleted successfully"})
 }
 
@@ -229,14 +237,14 @@ func seedServicesPublic(c *gin.Context) {
 	var count int64
 	database.DB.Model(&models.Service{}).Count(&count)
 	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Services already seeded"})
+		apierror.Abort(c, apierror.Validation("Services already seeded"))
 		return
 	}
 
 	// Get category IDs first
 	var categories []models.ServiceCategory
 	if err := database.DB.Find(&categories).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch categories", err))
 		return
 	}
 
@@ -330,6 +338,7 @@ func seedServicesPublic(c *gin.Context) {
 		}
 	}
 
+	invalidateServiceCache()
 	c.JSON(http.StatusOK, gin.H{"message": "Services seeded successfully", "count": successCount})
 }
 
@@ -339,14 +348,14 @@ func seedServices(c *gin.Context) {
 	var count int64
 	database.DB.Model(&models.Service{}).Count(&count)
 	if count > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Services already seeded"})
+		apierror.Abort(c, apierror.Validation("Services already seeded"))
 		return
 	}
 
 	// Get category IDs first
 	var categories []models.ServiceCategory
 	if err := database.DB.Find(&categories).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch categories", err))
 		return
 	}
 
@@ -438,5 +447,6 @@ func seedServices(c *gin.Context) {
 		}
 	}
 
+	invalidateServiceCache()
 	c.JSON(http.StatusOK, gin.H{"message": "Services seeded successfully", "count": len(services)})
 }
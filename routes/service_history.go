@@ -8,8 +8,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
 )
 
 // RegisterServiceHistoryRoutes registers all service history-related routes
@@ -43,7 +46,7 @@ func RegisterServiceHistoryRoutes(router *gin.RouterGroup) {
 func createServiceHistory(c *gin.Context) {
 	var historyData models.ServiceHistoryCreate
 	if err := c.ShouldBindJSON(&historyData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history data", "details": err.Error()})
+		apierror.Abort(c, apierror.Validation("Invalid history data").WithDetails(err.Error()))
 		return
 	}
 
@@ -52,7 +55,7 @@ func createServiceHistory(c *gin.Context) {
 
 	// Verify the worker is authorized to create history for this service
 	if historyData.WorkerID != workerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only create history for your own services"})
+		apierror.Abort(c, apierror.Forbidden("You can only create history for your own services"))
 		return
 	}
 
@@ -64,29 +67,29 @@ func createServiceHistory(c *gin.Context) {
 		Preload("ServiceOption").
 		First(&serviceRequest, historyData.ServiceRequestID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+			apierror.Abort(c, apierror.NotFound("Service request not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service request"})
+			apierror.Abort(c, apierror.Internal("Failed to fetch service request", nil))
 		}
 		return
 	}
 
 	// Verify the service request is assigned to this worker
 	if serviceRequest.AssignedWorkerID == nil || *serviceRequest.AssignedWorkerID != workerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Service request is not assigned to you"})
+		apierror.Abort(c, apierror.Forbidden("Service request is not assigned to you"))
 		return
 	}
 
 	// Verify the service request is completed
 	if serviceRequest.Status != models.RequestStatusCompleted {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Can only create history for completed services"})
+		apierror.Abort(c, apierror.Validation("Can only create history for completed services"))
 		return
 	}
 
 	// Check if history already exists for this service request
 	var existingHistory models.ServiceHistory
 	if err := database.DB.Where("service_request_id = ?", historyData.ServiceRequestID).First(&existingHistory).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Service history already exists for this service request"})
+		apierror.Abort(c, apierror.Conflict("Service history already exists for this service request"))
 		return
 	}
 
@@ -114,6 +117,7 @@ func createServiceHistory(c *gin.Context) {
 		AgreedPrice:       historyData.AgreedPrice,
 		FinalPrice:        historyData.FinalPrice,
 		PaymentStatus:     historyData.PaymentStatus,
+		CommissionPercent: services.NewRegionService().CommissionPercentForRequest(serviceRequest),
 		WorkerNotes:       historyData.WorkerNotes,
 		CustomerNotes:     historyData.CustomerNotes,
 		CreatedAt:         time.Now(),
@@ -121,13 +125,13 @@ func createServiceHistory(c *gin.Context) {
 	}
 
 	if err := database.DB.Create(&history).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service history"})
+		apierror.Abort(c, apierror.Internal("Failed to create service history", err))
 		return
 	}
 
 	// Update worker profile statistics
-	if err := updateWorkerServiceStats(workerID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "History created but failed to update worker stats"})
+	if err := updateWorkerServiceStats(database.DB, workerID); err != nil {
+		apierror.Abort(c, apierror.Internal("History created but failed to update worker stats", err))
 		return
 	}
 
@@ -139,7 +143,7 @@ func createServiceHistory(c *gin.Context) {
 		Preload("Category").
 		Preload("ServiceOption").
 		First(&createdHistory, history.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "History created but failed to load details"})
+		apierror.Abort(c, apierror.Internal("History created but failed to load details", nil))
 		return
 	}
 
@@ -154,7 +158,7 @@ func getWorkerServiceHistory(c *gin.Context) {
 	workerIDStr := c.Param("workerId")
 	workerID, err := strconv.ParseUint(workerIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worker ID"})
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
 		return
 	}
 
@@ -172,16 +176,17 @@ func getWorkerServiceHistory(c *gin.Context) {
 	}
 
 	offset := (page - 1) * limit
+	fields, ok := requestedFields(c)
+	if !ok {
+		return
+	}
 
 	// Build query
 	query := database.DB.Where("worker_id = ?", workerID)
 	
 	// Filter by year and month if provided
 	if year > 0 {
-		query = query.Where("YEAR(completed_at) = ?", year)
-		if month > 0 && month <= 12 {
-			query = query.Where("MONTH(completed_at) = ?", month)
-		}
+		query = completedWithin(query, year, month)
 	}
 
 	// Get total count
@@ -198,12 +203,12 @@ func getWorkerServiceHistory(c *gin.Context) {
 		Offset(offset).
 		Limit(limit).
 		Find(&history).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service history"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch service history", nil))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"history": history,
+		"history": serializers.Sparse(history, fields),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -230,6 +235,10 @@ func getCustomerServiceHistory(c *gin.Context) {
 	}
 
 	offset := (page - 1) * limit
+	fields, ok := requestedFields(c)
+	if !ok {
+		return
+	}
 
 	// Get total count
 	var total int64
@@ -246,12 +255,12 @@ func getCustomerServiceHistory(c *gin.Context) {
 		Offset(offset).
 		Limit(limit).
 		Find(&history).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service history"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch service history", nil))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"history": history,
+		"history": serializers.Sparse(history, fields),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -266,7 +275,7 @@ func getWorkerServiceSummary(c *gin.Context) {
 	workerIDStr := c.Param("workerId")
 	workerID, err := strconv.ParseUint(workerIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worker ID"})
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
 		return
 	}
 
@@ -287,7 +296,7 @@ func getWorkerServiceSummary(c *gin.Context) {
 		FROM service_histories 
 		WHERE worker_id = ? AND deleted_at IS NULL
 	`, workerID).Scan(&summary).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service summary"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch service summary", nil))
 		return
 	}
 
@@ -295,14 +304,10 @@ func getWorkerServiceSummary(c *gin.Context) {
 	var monthlyCount int64
 	var yearlyCount int64
 
-	database.DB.Model(&models.ServiceHistory{}).
-		Where("worker_id = ? AND MONTH(completed_at) = ? AND YEAR(completed_at) = ?", 
-			workerID, currentMonth, currentYear).
+	completedWithin(database.DB.Model(&models.ServiceHistory{}).Where("worker_id = ?", workerID), currentYear, int(currentMonth)).
 		Count(&monthlyCount)
 
-	database.DB.Model(&models.ServiceHistory{}).
-		Where("worker_id = ? AND YEAR(completed_at) = ?", 
-			workerID, currentYear).
+	completedWithin(database.DB.Model(&models.ServiceHistory{}).Where("worker_id = ?", workerID), currentYear, 0).
 		Count(&yearlyCount)
 
 	summary.WorkerID = uint(workerID)
@@ -324,7 +329,7 @@ func getServiceHistory(c *gin.Context) {
 	historyIDStr := c.Param("historyId")
 	historyID, err := strconv.ParseUint(historyIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		apierror.Abort(c, apierror.Validation("Invalid history ID"))
 		return
 	}
 
@@ -336,9 +341,9 @@ func getServiceHistory(c *gin.Context) {
 		Preload("ServiceOption").
 		First(&history, historyID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Service history not found"})
+			apierror.Abort(c, apierror.NotFound("Service history not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service history"})
+			apierror.Abort(c, apierror.Internal("Failed to fetch service history", nil))
 		}
 		return
 	}
@@ -351,7 +356,7 @@ func updateServiceHistory(c *gin.Context) {
 	historyIDStr := c.Param("historyId")
 	historyID, err := strconv.ParseUint(historyIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		apierror.Abort(c, apierror.Validation("Invalid history ID"))
 		return
 	}
 
@@ -362,22 +367,22 @@ func updateServiceHistory(c *gin.Context) {
 	var existingHistory models.ServiceHistory
 	if err := database.DB.First(&existingHistory, historyID).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Service history not found"})
+			apierror.Abort(c, apierror.NotFound("Service history not found"))
 		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service history"})
+			apierror.Abort(c, apierror.Internal("Failed to fetch service history", nil))
 		}
 		return
 	}
 
 	if existingHistory.WorkerID != workerID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own service history"})
+		apierror.Abort(c, apierror.Forbidden("You can only update your own service history"))
 		return
 	}
 
 	// Parse update data
 	var updateData models.ServiceHistoryCreate
 	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid update data"})
+		apierror.Abort(c, apierror.Validation("Invalid update data"))
 		return
 	}
 
@@ -393,7 +398,7 @@ func updateServiceHistory(c *gin.Context) {
 	}
 
 	if err := database.DB.Model(&existingHistory).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service history"})
+		apierror.Abort(c, apierror.Internal("Failed to update service history", err))
 		return
 	}
 
@@ -419,6 +424,10 @@ func getServiceHistoryList(c *gin.Context) {
 	}
 
 	offset := (page - 1) * limit
+	fields, ok := requestedFields(c)
+	if !ok {
+		return
+	}
 
 	// Build query
 	query := database.DB.Model(&models.ServiceHistory{})
@@ -433,10 +442,7 @@ func getServiceHistoryList(c *gin.Context) {
 		query = query.Where("category_id = ?", categoryID)
 	}
 	if year > 0 {
-		query = query.Where("YEAR(completed_at) = ?", year)
-		if month > 0 && month <= 12 {
-			query = query.Where("MONTH(completed_at) = ?", month)
-		}
+		query = completedWithin(query, year, month)
 	}
 
 	// Get total count
@@ -454,12 +460,12 @@ func getServiceHistoryList(c *gin.Context) {
 		Offset(offset).
 		Limit(limit).
 		Find(&history).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service history"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch service history", nil))
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"history": history,
+		"history": serializers.Sparse(history, fields),
 		"pagination": gin.H{
 			"page":  page,
 			"limit": limit,
@@ -470,10 +476,10 @@ func getServiceHistoryList(c *gin.Context) {
 }
 
 // updateWorkerServiceStats updates the service statistics for a worker
-func updateWorkerServiceStats(workerID uint) error {
+func updateWorkerServiceStats(db *gorm.DB, workerID uint) error {
 	// Count total completed services
 	var totalServices int64
-	if err := database.DB.Model(&models.ServiceHistory{}).Where("worker_id = ?", workerID).Count(&totalServices).Error; err != nil {
+	if err := db.Model(&models.ServiceHistory{}).Where("worker_id = ?", workerID).Count(&totalServices).Error; err != nil {
 		return err
 	}
 
@@ -483,5 +489,15 @@ func updateWorkerServiceStats(workerID uint) error {
 		"updated_at":     time.Now(),
 	}
 
-	return database.DB.Model(&models.WorkerProfile{}).Where("id = ?", workerID).Updates(updates).Error
+	return db.Model(&models.WorkerProfile{}).Where("id = ?", workerID).Updates(updates).Error
+}
+
+// completedWithin limits query to services completed in year, or in one month of it when month is
+// between 1 and 12
+func completedWithin(query *gorm.DB, year, month int) *gorm.DB {
+	from, to := services.YearWindow(year, time.Local)
+	if month >= 1 && month <= 12 {
+		from, to = services.MonthWindow(year, time.Month(month), time.Local)
+	}
+	return query.Where("completed_at >= ? AND completed_at < ?", from, to)
 }
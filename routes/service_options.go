@@ -2,57 +2,64 @@ package routes
 
 import (
 	"net/http"
+	"repair-service-server/apierror"
+	"repair-service-server/cache"
 	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
-// GetServiceOptionsByCategory retrieves all service options for a specific category
+// GetServiceOptionsByCategory retrieves all service options for a specific category in the request's language
 func GetServiceOptionsByCategory(c *gin.Context) {
 	categoryIDStr := c.Param("categoryId")
 	categoryID, err := strconv.ParseUint(categoryIDStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid category ID",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid category ID"))
 		return
 	}
 
-	var serviceOptions []models.ServiceOption
-	result := database.DB.Where("category_id = ? AND is_active = ?", categoryID, true).
-		Order("sort_order ASC, title ASC").
-		Preload("Category").
-		Find(&serviceOptions)
-
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch service options",
-		})
+	serviceOptions, err := cache.GetOrLoad(catalogKey(serviceOptionCacheKey, "category", categoryID), catalogCacheTTL(), func() ([]models.ServiceOption, error) {
+		var serviceOptions []models.ServiceOption
+		err := database.DB.Where("category_id = ? AND is_active = ?", categoryID, true).
+			Order("sort_order ASC, title ASC").
+			Preload("Category").
+			Find(&serviceOptions).Error
+		return serviceOptions, err
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch service options", err))
 		return
 	}
 
+	lang := i18n.Locale(c)
+	localized := make([]models.ServiceOption, len(serviceOptions))
+	for i, option := range serviceOptions {
+		localized[i] = option.Localized(lang)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    serviceOptions,
+		"success":  true,
+		"language": lang,
+		"dir":      i18n.Dir(lang),
+		"data":     localized,
 	})
 }
 
 // GetAllServiceOptions retrieves all service options (admin only)
 func GetAllServiceOptions(c *gin.Context) {
-	var serviceOptions []models.ServiceOption
-	result := database.DB.Order("category_id ASC, sort_order ASC, title ASC").
-		Preload("Category").
-		Find(&serviceOptions)
-
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch service options",
-		})
+	serviceOptions, err := cache.GetOrLoad(catalogKey(serviceOptionCacheKey, "all"), catalogCacheTTL(), func() ([]models.ServiceOption, error) {
+		var serviceOptions []models.ServiceOption
+		err := database.DB.Order("category_id ASC, sort_order ASC, title ASC").
+			Preload("Category").
+			Find(&serviceOptions).Error
+		return serviceOptions, err
+	})
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch service options", err))
 		return
 	}
 
@@ -66,31 +73,24 @@ func GetAllServiceOptions(c *gin.Context) {
 func CreateServiceOption(c *gin.Context) {
 	var serviceOption models.ServiceOption
 	if err := c.ShouldBindJSON(&serviceOption); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request data",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
 		return
 	}
 
 	// Validate required fields
 	if serviceOption.Title == "" || serviceOption.Description == "" || serviceOption.CategoryID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Title, description, and category are required",
-		})
+		apierror.Abort(c, apierror.Validation("Title, description, and category are required"))
 		return
 	}
 
 	result := database.DB.Create(&serviceOption)
 	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to create service option",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to create service option", result.Error))
 		return
 	}
 
+	invalidateServiceOptionCache()
+
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Service option created successfully",
@@ -103,40 +103,30 @@ func UpdateServiceOption(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid ID",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid ID"))
 		return
 	}
 
 	var serviceOption models.ServiceOption
 	if err := database.DB.First(&serviceOption, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": "Service option not found",
-		})
+		apierror.Abort(c, apierror.NotFound("Service option not found"))
 		return
 	}
 
 	var updateData models.ServiceOption
 	if err := c.ShouldBindJSON(&updateData); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request data",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid request data"))
 		return
 	}
 
 	result := database.DB.Model(&serviceOption).Updates(updateData)
 	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to update service option",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to update service option", result.Error))
 		return
 	}
 
+	invalidateServiceOptionCache()
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Service option updated successfully",
@@ -149,31 +139,24 @@ func DeleteServiceOption(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid ID",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid ID"))
 		return
 	}
 
 	var serviceOption models.ServiceOption
 	if err := database.DB.First(&serviceOption, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": "Service option not found",
-		})
+		apierror.Abort(c, apierror.NotFound("Service option not found"))
 		return
 	}
 
 	result := database.DB.Delete(&serviceOption)
 	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to delete service option",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to delete service option", result.Error))
 		return
 	}
 
+	invalidateServiceOptionCache()
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Service option deleted successfully",
@@ -184,8 +167,8 @@ func DeleteServiceOption(c *gin.Context) {
 func RegisterServiceOptionRoutes(router *gin.RouterGroup) {
 	serviceOptions := router.Group("/service-options")
 	{
-		serviceOptions.GET("/category/:categoryId", GetServiceOptionsByCategory)
-		serviceOptions.GET("/", GetAllServiceOptions)
+		serviceOptions.GET("/category/:categoryId", middleware.ConditionalGET(), GetServiceOptionsByCategory)
+		serviceOptions.GET("/", middleware.ConditionalGET(), GetAllServiceOptions)
 		serviceOptions.POST("/", CreateServiceOption)
 		serviceOptions.PUT("/:id", UpdateServiceOption)
 		serviceOptions.DELETE("/:id", DeleteServiceOption)
@@ -1,16 +1,28 @@
 package routes
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"repair-service-server/apierror"
 	"repair-service-server/database"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/serializers"
 	"repair-service-server/services"
 	"repair-service-server/utils"
+	"repair-service-server/validation"
+	ws "repair-service-server/websocket"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RegisterServiceRequestRoutes registers all service request-related routes
@@ -18,13 +30,22 @@ func RegisterServiceRequestRoutes(router *gin.RouterGroup) {
 	log.Printf("🔧 RegisterServiceRequestRoutes called with router: %v", router)
 	
 	// Create a new service request
-	router.POST("/", createServiceRequest)
+	router.POST("/", middleware.RequireVerifiedPhone(), middleware.Idempotency(), createServiceRequest)
 
 	// Urgent service request (priority=urgent, broadcast immediately)
-	router.POST("/urgent", createUrgentServiceRequest)
+	router.POST("/urgent", middleware.RequireVerifiedPhone(), middleware.Idempotency(), createUrgentServiceRequest)
 
 	// Scheduled service request (status=scheduled, scheduled_for set)
-	router.POST("/scheduled", createScheduledServiceRequest)
+	router.POST("/scheduled", middleware.RequireVerifiedPhone(), middleware.Idempotency(), createScheduledServiceRequest)
+
+	// Category, option, priority and price suggestion to pre-fill the creation form
+	router.POST("/suggest", suggestServiceRequest)
+
+	// Price of a service option with its modifiers, shown before the request is submitted
+	router.POST("/price-preview", previewRequestPrice)
+
+	// How long similar jobs took, shown next to the estimated duration field
+	router.GET("/duration-estimate", getDurationEstimate)
 	log.Printf("✅ POST / route registered")
 	
 	// Get customer's service requests
@@ -35,16 +56,32 @@ func RegisterServiceRequestRoutes(router *gin.RouterGroup) {
 	router.GET("/:id", getServiceRequest)
 	log.Printf("✅ GET /:id route registered")
 	
-	// Update service request status
-	router.PUT("/:id/status", updateServiceRequestStatus)
-	log.Printf("✅ PUT /:id/status route registered")
+	// Codes the customer gives the worker to start and complete the job
+	router.GET("/:id/codes", getJobCodes)
+	
+	// Parts added by the worker, approved or rejected by the customer
+	router.GET("/:id/line-items", getLineItems)
+	router.POST("/:id/line-items/:itemId/approve", approveLineItem)
+	router.POST("/:id/line-items/:itemId/reject", rejectLineItem)
 	
 	// Cancel a service request
 	router.POST("/:id/cancel", cancelServiceRequest)
 	log.Printf("✅ POST /:id/cancel route registered")
 	
 	// Rate and review a completed service
-	router.POST("/:id/review", reviewService)
+	router.POST("/:id/review", middleware.Idempotency(), reviewService)
+	
+	// Tip the worker after rating a completed service
+	router.POST("/:id/tip", middleware.Idempotency(), tipServiceRequest)
+
+	// Either party raises an SOS during a job; the request is held until an admin reviews it
+	router.POST("/:id/sos", raiseSOS)
+
+	// Either party gets a proxy number to call the other without sharing their own
+	router.POST("/:id/call", callOtherParty)
+
+	// Videos the customer or the assigned worker attached with resumable uploads
+	router.GET("/:id/attachments", listRequestAttachments)
 	log.Printf("✅ POST /:id/review route registered")
 	
 	log.Printf("🎯 All service request routes registered successfully")
@@ -54,16 +91,36 @@ func createUrgentServiceRequest(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var req models.CustomerServiceRequestCreate
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(c, &req) {
 		return
 	}
 
 	// Force urgent priority
 	req.Priority = "urgent"
 
+	if !applySavedAddress(c, userID, &req) {
+		return
+	}
 	if !utils.IsLocationValid(req.LocationLat, req.LocationLng) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location coordinates"})
+		apierror.Abort(c, apierror.Validation("Invalid location coordinates"))
+		return
+	}
+
+	location, ok := resolveRequestLocation(c, &req)
+	if !ok {
+		return
+	}
+	if !checkDiagnosis(c, userID, req.DiagnosisID) {
+		return
+	}
+	if !checkOrganization(c, userID, req.OrganizationID) {
+		return
+	}
+	if !checkUrgent(c, req.CategoryID, req.Priority) {
+		return
+	}
+	extraCategories, ok := checkExtraCategories(c, req)
+	if !ok {
 		return
 	}
 
@@ -71,31 +128,43 @@ func createUrgentServiceRequest(c *gin.Context) {
 
 	serviceRequest := models.CustomerServiceRequest{
 		CustomerID:        userID,
+		OrganizationID:    req.OrganizationID,
 		CategoryID:        req.CategoryID,
+		ExtraCategories:   extraCategories,
 		ServiceOptionID:   req.ServiceOptionID,
 		Title:             req.Title,
 		Description:       req.Description,
 		Priority:          req.Priority,
 		Budget:            req.Budget,
 		EstimatedDuration: req.EstimatedDuration,
+		AddressID:         req.AddressID,
 		LocationLat:       &req.LocationLat,
 		LocationLng:       &req.LocationLng,
 		LocationAddress:   req.LocationAddress,
 		LocationCity:      req.LocationCity,
+		EntranceNotes:     req.EntranceNotes,
 		Status:            models.RequestStatusBroadcast,
 		ExpiresAt:         &expiresAt,
 	}
+	location.apply(&serviceRequest)
+	applyDispatchMode(&serviceRequest, req.DispatchMode)
+	if !priceRequest(c, &serviceRequest) {
+		return
+	}
 
 	if err := database.DB.Create(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service request"})
+		apierror.Abort(c, apierror.Internal("Failed to create service request", err))
 		return
 	}
+	publishRequestEvent("request_created", serviceRequest)
+	enqueueRequestConfirmationEmail(serviceRequest)
+	linkDiagnosis(userID, req.DiagnosisID, serviceRequest.ID)
 
-	go broadcastServiceRequest(serviceRequest)
+	startDispatch(serviceRequest)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Urgent service request created",
-		"service_request": serviceRequest,
+		"service_request": serializers.ServiceRequest(serviceRequest),
 	})
 }
 
@@ -105,53 +174,97 @@ func createScheduledServiceRequest(c *gin.Context) {
 
 	var body struct {
 		models.CustomerServiceRequestCreate
-		ScheduledFor string `json:"scheduled_for" binding:"required"` // ISO8601
+		ScheduledFor string `json:"scheduled_for" binding:"required"`    // ISO8601, local time of the region or timezone without an offset
+		Timezone     string `json:"timezone" binding:"omitempty,max=64"` // IANA name of the customer's time zone
 	}
 
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(c, &body) {
 		return
 	}
 
+	if !applySavedAddress(c, userID, &body.CustomerServiceRequestCreate) {
+		return
+	}
 	if !utils.IsLocationValid(body.LocationLat, body.LocationLng) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location coordinates"})
+		apierror.Abort(c, apierror.Validation("Invalid location coordinates"))
 		return
 	}
 
-	schedTime, err := time.Parse(time.RFC3339, body.ScheduledFor)
-	if err != nil || schedTime.Before(time.Now()) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "scheduled_for must be a future ISO time"})
+	location, ok := resolveRequestLocation(c, &body.CustomerServiceRequestCreate)
+	if !ok {
+		return
+	}
+	if !checkDiagnosis(c, userID, body.DiagnosisID) {
+		return
+	}
+	if !checkOrganization(c, userID, body.OrganizationID) {
+		return
+	}
+	if !checkUrgent(c, body.CategoryID, body.Priority) {
+		return
+	}
+	extraCategories, ok := checkExtraCategories(c, body.CustomerServiceRequestCreate)
+	if !ok {
 		return
 	}
 
 	serviceRequest := models.CustomerServiceRequest{
 		CustomerID:        userID,
+		OrganizationID:    body.OrganizationID,
 		CategoryID:        body.CategoryID,
+		ExtraCategories:   extraCategories,
 		ServiceOptionID:   body.ServiceOptionID,
 		Title:             body.Title,
 		Description:       body.Description,
 		Priority:          ifEmpty(body.Priority, "normal"),
 		Budget:            body.Budget,
 		EstimatedDuration: body.EstimatedDuration,
+		AddressID:         body.AddressID,
 		LocationLat:       &body.LocationLat,
 		LocationLng:       &body.LocationLng,
 		LocationAddress:   body.LocationAddress,
 		LocationCity:      body.LocationCity,
+		EntranceNotes:     body.EntranceNotes,
 		Status:            models.RequestStatusScheduled,
-		ScheduledFor:      &schedTime,
+	}
+	location.apply(&serviceRequest)
+	if !scheduleRequest(c, &serviceRequest, body.ScheduledFor, body.Timezone) {
+		return
+	}
+	if !priceRequest(c, &serviceRequest) {
+		return
 	}
 
 	if err := database.DB.Create(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create scheduled request"})
+		apierror.Abort(c, apierror.Internal("Failed to create scheduled request", err))
 		return
 	}
+	publishRequestEvent("request_created", serviceRequest)
+	enqueueRequestConfirmationEmail(serviceRequest)
+	linkDiagnosis(userID, body.DiagnosisID, serviceRequest.ID)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "Scheduled service request created",
-		"service_request": serviceRequest,
+		"service_request": serializers.ServiceRequest(serviceRequest),
 	})
 }
 
+// scheduleRequest schedules the request for the time the customer asked for, read in their time
+// zone or the request's region, aborting with a validation error when it is invalid or past
+func scheduleRequest(c *gin.Context, serviceRequest *models.CustomerServiceRequest, scheduledFor, timezone string) bool {
+	at, err := services.ParseScheduledFor(scheduledFor, timezone, services.NewRegionService().ForRequest(*serviceRequest))
+	if errors.Is(err, services.ErrInvalidTimezone) {
+		validation.Fail(c, "timezone", "timezone", "")
+		return false
+	}
+	if err != nil {
+		validation.Fail(c, "scheduled_for", "future", "")
+		return false
+	}
+	serviceRequest.ScheduleAt(at)
+	return true
+}
+
 func ifEmpty(s string, def string) string {
 	if s == "" {
 		return def
@@ -159,6 +272,109 @@ func ifEmpty(s string, def string) string {
 	return s
 }
 
+// requestLocation holds the server-side resolution of a request location
+type requestLocation struct {
+	GeocodedAddress string
+	Zone            *models.ServiceZone
+}
+
+// apply copies the resolved location data onto the service request
+func (l *requestLocation) apply(serviceRequest *models.CustomerServiceRequest) {
+	serviceRequest.GeocodedAddress = l.GeocodedAddress
+	serviceRequest.SurgeMultiplier = 1
+	if l.Zone != nil {
+		serviceRequest.ServiceZoneID = &l.Zone.ID
+		serviceRequest.SurgeMultiplier = l.Zone.SurgeMultiplier
+	}
+}
+
+// errLocationAddressRequired is returned when no address was given and none could be geocoded
+var errLocationAddressRequired = errors.New("location_address is required")
+
+// resolveRequestLocation reverse-geocodes the request coordinates, normalizes the city and
+// rejects points outside the supported service areas or active service zones, and categories the
+// city's region does not offer.
+// It writes the error response itself.
+func resolveRequestLocation(c *gin.Context, req *models.CustomerServiceRequestCreate) (*requestLocation, bool) {
+	location, err := resolveLocation(req)
+	switch {
+	case err == nil:
+		return location, true
+	case errors.Is(err, services.ErrOutsideServiceArea):
+		apierror.Abort(c, apierror.Unprocessable("Location is outside of our service areas").WithDetails(gin.H{"supported_areas": services.GetSupportedServiceAreas()}))
+	case errors.Is(err, errLocationAddressRequired):
+		apierror.Abort(c, apierror.Validation("location_address is required"))
+	case errors.Is(err, services.ErrNoActiveZone), errors.Is(err, services.ErrCategoryNotInZone), errors.Is(err, services.ErrCategoryNotInRegion):
+		apierror.Abort(c, apierror.Unprocessable(err.Error()))
+	default:
+		log.Printf("❌ Failed to look up service zone: %v", err)
+		apierror.Abort(c, apierror.Internal("Failed to validate service zone", nil))
+	}
+	return nil, false
+}
+
+// applySavedAddress takes a new request's location from the customer's saved address when it
+// names one, over any coordinates sent with it. Without one the coordinates are required.
+func applySavedAddress(c *gin.Context, userID uint, req *models.CustomerServiceRequestCreate) bool {
+	if req.AddressID == nil {
+		switch {
+		case req.LocationLat == 0:
+			validation.Fail(c, "location_lat", "required", "")
+			return false
+		case req.LocationLng == 0:
+			validation.Fail(c, "location_lng", "required", "")
+			return false
+		}
+		return true
+	}
+
+	var address models.Address
+	if err := database.DB.Where("id = ? AND user_id = ?", *req.AddressID, userID).First(&address).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.NotFound("The requested address does not exist"))
+			return false
+		}
+		apierror.Abort(c, apierror.Internal("Failed to load address", err))
+		return false
+	}
+	req.LocationLat, req.LocationLng = address.Latitude, address.Longitude
+	req.LocationAddress, req.LocationCity = address.AddressDetails, address.City
+	if req.EntranceNotes == "" {
+		req.EntranceNotes = address.EntranceNotes
+	}
+	return true
+}
+
+// resolveLocation is resolveRequestLocation for callers without an HTTP request
+func resolveLocation(req *models.CustomerServiceRequestCreate) (*requestLocation, error) {
+	address, city, result, err := services.NewGeocodingService().ResolveRequestLocation(
+		req.LocationLat, req.LocationLng, req.LocationAddress, req.LocationCity)
+	if err == services.ErrOutsideServiceArea {
+		return nil, err
+	}
+
+	if address == "" {
+		return nil, errLocationAddressRequired
+	}
+
+	zone, err := services.NewZoneService().FindZoneForRequest(req.LocationLat, req.LocationLng, req.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if region := services.NewRegionService().ForCity(city); !region.SupportsCategory(req.CategoryID) {
+		return nil, services.ErrCategoryNotInRegion
+	}
+
+	req.LocationAddress = address
+	req.LocationCity = city
+
+	location := &requestLocation{Zone: zone}
+	if result != nil {
+		location.GeocodedAddress = result.FormattedAddress
+	}
+	return location, nil
+}
+
 // Worker Service Functions (exported for use in main.go)
 func GetAvailableServiceRequests(c *gin.Context) {
 	getAvailableServiceRequests(c)
@@ -185,14 +401,35 @@ func createServiceRequest(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	
 	var req models.CustomerServiceRequestCreate
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(c, &req) {
 		return
 	}
 	
-	// Validate location coordinates
+	// Take the location from a saved address, then validate the coordinates
+	if !applySavedAddress(c, userID, &req) {
+		return
+	}
 	if !utils.IsLocationValid(req.LocationLat, req.LocationLng) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid location coordinates"})
+		apierror.Abort(c, apierror.Validation("Invalid location coordinates"))
+		return
+	}
+	
+	// Reverse-geocode and check the service area
+	location, ok := resolveRequestLocation(c, &req)
+	if !ok {
+		return
+	}
+	if !checkDiagnosis(c, userID, req.DiagnosisID) {
+		return
+	}
+	if !checkOrganization(c, userID, req.OrganizationID) {
+		return
+	}
+	if !checkUrgent(c, req.CategoryID, req.Priority) {
+		return
+	}
+	extraCategories, ok := checkExtraCategories(c, req)
+	if !ok {
 		return
 	}
 	
@@ -202,63 +439,119 @@ func createServiceRequest(c *gin.Context) {
 	// Create service request
 	serviceRequest := models.CustomerServiceRequest{
 		CustomerID:        userID,
+		OrganizationID:    req.OrganizationID,
 		CategoryID:        req.CategoryID,
+		ExtraCategories:   extraCategories,
 		ServiceOptionID:   req.ServiceOptionID, // New: Include service option ID
 		Title:             req.Title,
 		Description:       req.Description,
 		Priority:          req.Priority,
 		Budget:            req.Budget,
 		EstimatedDuration: req.EstimatedDuration,
+		AddressID:         req.AddressID,
 		LocationLat:       &req.LocationLat,
 		LocationLng:       &req.LocationLng,
 		LocationAddress:   req.LocationAddress,
 		LocationCity:      req.LocationCity,
+		EntranceNotes:     req.EntranceNotes,
 		Status:            models.RequestStatusBroadcast,
 		ExpiresAt:         &expiresAt,
 	}
+	location.apply(&serviceRequest)
+	applyDispatchMode(&serviceRequest, req.DispatchMode)
+	if !priceRequest(c, &serviceRequest) {
+		return
+	}
 	
 	if err := database.DB.Create(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service request"})
+		apierror.Abort(c, apierror.Internal("Failed to create service request", err))
 		return
 	}
+	publishRequestEvent("request_created", serviceRequest)
+	enqueueRequestConfirmationEmail(serviceRequest)
+	linkDiagnosis(userID, req.DiagnosisID, serviceRequest.ID)
 	
-	// Broadcast to nearby workers
-	go broadcastServiceRequest(serviceRequest)
+	// Broadcast to nearby workers, or offer to the best worker in auto-dispatch mode
+	startDispatch(serviceRequest)
 	
 	// Track analytics for all workers in this category (they received a job opportunity)
+	trackJobReceivedInCategory(serviceRequest)
+	
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Service request created successfully",
+		"service_request": serializers.ServiceRequest(serviceRequest),
+	})
+}
+
+// trackJobReceivedInCategory counts a new broadcast as a job opportunity for every active
+// worker tagged for one of its categories or their parents
+func trackJobReceivedInCategory(serviceRequest models.CustomerServiceRequest) {
 	analyticsService := services.NewWorkerAnalyticsService()
+	categoryIDs, err := services.NewCategoryService().TargetCategoryIDs(serviceRequest)
+	if err != nil {
+		log.Printf("⚠️ Failed to resolve categories for request %d: %v", serviceRequest.ID, err)
+		return
+	}
 	var workersInCategory []models.WorkerProfile
-	if err := database.DB.Where("category_id = ? AND is_active = ?", req.CategoryID, true).Find(&workersInCategory).Error; err == nil {
+	if err := database.DB.Where("is_active = ?", true).Scopes(services.WorkerInCategoriesScope(categoryIDs)).Find(&workersInCategory).Error; err == nil {
 		for _, worker := range workersInCategory {
 			if err := analyticsService.TrackJobReceived(worker.ID, serviceRequest.ID); err != nil {
 				log.Printf("⚠️ Failed to track job received analytics for worker %d: %v", worker.ID, err)
 			}
 		}
 	}
-	
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Service request created successfully",
-		"service_request": serviceRequest,
-	})
 }
 
-// getMyServiceRequests returns all service requests created by the current user
+// trackJobDecline counts a decline in the worker's analytics
+func trackJobDecline(workerID, serviceRequestID uint, reason models.DeclineReason) {
+	if err := services.NewWorkerAnalyticsService().TrackJobDecline(workerID, serviceRequestID); err != nil {
+		log.Printf("⚠️ Failed to track job decline analytics for worker %d: %v", workerID, err)
+		return
+	}
+	log.Printf("📊 Worker %d declined service request %d: %s", workerID, serviceRequestID, reason)
+}
+
+// getMyServiceRequests returns all service requests created by the current user. With
+// organization_id it returns the requests billed to that organization instead: all of them for
+// its owners, and their own for members.
 func getMyServiceRequests(c *gin.Context) {
 	userID := c.GetUint("user_id")
+	fields, ok := requestedFields(c)
+	if !ok {
+		return
+	}
+	
+	query := database.DB.Where("customer_id = ?", userID)
+	if orgParam := c.Query("organization_id"); orgParam != "" {
+		orgID, err := strconv.ParseUint(orgParam, 10, 32)
+		if err != nil {
+			validation.Fail(c, "organization_id", "default", "")
+			return
+		}
+		member, err := services.NewOrganizationService().Membership(uint(orgID), userID)
+		if err != nil {
+			abortOrganizationError(c, "Failed to fetch organization", err)
+			return
+		}
+		query = database.DB.Scopes(services.OrganizationRequests(member.OrganizationID))
+		if !member.IsOwner() {
+			query = query.Where("customer_id = ?", userID)
+		}
+	}
 	
 	var serviceRequests []models.CustomerServiceRequest
-	if err := database.DB.Where("customer_id = ?", userID).
+	if err := query.Where("archived_at IS NULL").
 		Preload("AssignedWorker.User").
 		Preload("Category").
 		Preload("ServiceOption"). // New: Preload service option details
 		Order("created_at DESC").
 		Find(&serviceRequests).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service requests"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch service requests", nil))
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
-		"service_requests": serviceRequests,
+		"service_requests": serializers.Sparse(serializers.ServiceRequests(serviceRequests), fields),
 		"total_count": len(serviceRequests),
 	})
 }
@@ -274,23 +567,25 @@ func getServiceRequest(c *gin.Context) {
 		Preload("AssignedWorker.User").
 		Preload("AssignedWorker.Category").
 		Preload("Category").
+		Preload("ExtraCategories").
 		Preload("ServiceOption"). // New: Preload service option details
 		First(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
 		return
 	}
 	
 	// Check if user has access to this request
 	if serviceRequest.CustomerID != userID {
-		// Check if user is the assigned worker
-		if serviceRequest.AssignedWorkerID == nil || *serviceRequest.AssignedWorkerID != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		// Check if user is the assigned worker or owns the organization the request is billed to
+		isAssignedWorker := serviceRequest.AssignedWorkerID != nil && *serviceRequest.AssignedWorkerID == userID
+		if !isAssignedWorker && !ownsRequestOrganization(userID, serviceRequest) {
+			apierror.Abort(c, apierror.Forbidden("Access denied"))
 			return
 		}
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
-		"service_request": serviceRequest,
+		"service_request": serializers.ServiceRequest(serviceRequest),
 	})
 }
 
@@ -304,7 +599,7 @@ func getAvailableServiceRequests(c *gin.Context) {
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
 		log.Printf("❌ Worker profile not found for user %d: %v", userID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -314,52 +609,119 @@ func getAvailableServiceRequests(c *gin.Context) {
 	// Check if worker is available
 	if !workerProfile.IsAvailable {
 		log.Printf("❌ Worker %d is not available", workerProfile.ID)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Worker is not available"})
+		apierror.Abort(c, apierror.Validation("Worker is not available"))
+		return
+	}
+	if workerProfile.IsSuspended(time.Now()) {
+		apierror.Abort(c, apierror.Forbidden("Your account is suspended until "+workerProfile.SuspendedUntil.Format("2006-01-02")))
 		return
 	}
 
-	// Check if worker has active work (only in-progress requests should block new requests)
-	var activeRequestCount int64
-	if err := database.DB.Model(&models.CustomerServiceRequest{}).
-		Where("assigned_worker_id = ? AND status = ?", 
-			workerProfile.ID, 
-			models.RequestStatusInProgress).
-		Count(&activeRequestCount).Error; err != nil {
+	// Check the worker's weekly schedule and time off
+	scheduleService := services.NewWorkerScheduleService()
+	if onShift, err := scheduleService.IsWorkerAvailableAt(workerProfile.ID, time.Now()); err != nil {
+		log.Printf("⚠️ Failed to check schedule for worker %d: %v", workerProfile.ID, err)
+	} else if !onShift {
+		log.Printf("❌ Worker %d is outside of their schedule", workerProfile.ID)
+		apierror.Abort(c, apierror.Validation("Worker is outside of their scheduled hours"))
+		return
+	}
+
+	// Check if worker has remaining capacity (accepted + in-progress jobs against their limit)
+	capacity, err := services.NewWorkerCapacityService().GetCapacity(&workerProfile)
+	if err != nil {
 		log.Printf("❌ Failed to check active requests for worker %d: %v", workerProfile.ID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check active requests"})
+		apierror.Abort(c, apierror.Internal("Failed to check active requests", nil))
 		return
 	}
 
-	log.Printf("🔍 Worker %d has %d in-progress requests", workerProfile.ID, activeRequestCount)
+	log.Printf("🔍 Worker %d has %d/%d active jobs", workerProfile.ID, capacity.ActiveJobs, capacity.MaxConcurrentJobs)
 
-	if activeRequestCount > 0 {
-		log.Printf("❌ Worker %d has active in-progress work and cannot accept new requests", workerProfile.ID)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Worker has active in-progress work and cannot accept new requests"})
+	if !capacity.HasCapacity {
+		log.Printf("❌ Worker %d is at capacity and cannot accept new requests", workerProfile.ID)
+		apierror.Abort(c, apierror.Validation("Worker has reached the maximum number of concurrent jobs").WithDetails(gin.H{"capacity": capacity}))
 		return
 	}
 	
+	// Categories the worker is tagged for, with their subcategories
+	categoryIDs, err := services.NewCategoryService().WorkerCategoryIDs(workerProfile)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load worker categories", err))
+		return
+	}
+	
+	availableRequests, err := availableRequestsFor(workerProfile, categoryIDs)
+	if err != nil {
+		log.Printf("❌ Failed to fetch service requests for categories %v: %v", categoryIDs, err)
+		apierror.Abort(c, apierror.Internal("Failed to fetch service requests", nil))
+		return
+	}
+	
+	// Job pushes and digests open the list on the requests they announced
+	if ids := c.Query("ids"); ids != "" {
+		wanted := make(map[uint]bool)
+		for _, raw := range strings.Split(ids, ",") {
+			if id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 32); err == nil {
+				wanted[uint(id)] = true
+			}
+		}
+		filtered := make([]models.AvailableServiceRequestResponse, 0, len(wanted))
+		for _, request := range availableRequests {
+			if wanted[request.ID] {
+				filtered = append(filtered, request)
+			}
+		}
+		availableRequests = filtered
+	}
+	
+	log.Printf("✅ Returning %d available requests for worker %d", len(availableRequests), workerProfile.ID)
+	
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"available_requests": availableRequests,
+		"total_count": len(availableRequests),
+		"worker_category": workerProfile.CategoryID,
+		"capacity": capacity,
+	})
+}
+
+// availableRequestsFor returns the broadcast requests in categoryIDs the worker is shown: those
+// within each request's broadcast radius, or all of them when the worker has no recent location
+func availableRequestsFor(workerProfile models.WorkerProfile, categoryIDs []uint) ([]models.AvailableServiceRequestResponse, error) {
 	// Check if worker has recent location data (optional for now)
 	hasLocationData := workerProfile.CurrentLat != nil && workerProfile.CurrentLng != nil && utils.IsLocationRecent(workerProfile.LastLocationUpdate)
 	log.Printf("🔍 Worker %d has location data: %v (lat=%v, lng=%v)", 
 		workerProfile.ID, hasLocationData, workerProfile.CurrentLat, workerProfile.CurrentLng)
 	
-	// Get available service requests in worker's category
+	// Get available service requests in worker's categories, joining the customer and zone in the same query.
+	// Urgent requests come first, then requests from customers with priority dispatch.
+	// Workers with reduced priority only see requests other than urgent ones once other workers had them for a while.
 	var serviceRequests []models.CustomerServiceRequest
-	if err := database.DB.Where("category_id = ? AND status = ? AND assigned_worker_id IS NULL", 
-		workerProfile.CategoryID, models.RequestStatusBroadcast).
-		Preload("Customer").
-		Preload("Category").
-		Preload("ServiceOption").
+	query := database.DB.Joins("Customer").Joins("ServiceZone")
+	if services.HasReducedPriority(workerProfile) {
+		query = query.Where("customer_service_requests.created_at <= ? OR customer_service_requests.priority = ?", time.Now().Add(-services.ReducedPriorityDelay), "urgent")
+	}
+	if err := query.
+		Scopes(services.RequestInCategoriesScope(categoryIDs)).
+		Where("customer_service_requests.status = ? AND customer_service_requests.assigned_worker_id IS NULL", 
+			models.RequestStatusBroadcast).
+		Order(gorm.Expr("customer_service_requests.priority = ? DESC", "urgent")).
+		Order(gorm.Expr("customer_service_requests.customer_id IN (SELECT user_id FROM customer_loyalty WHERE tier = ?) DESC", models.LoyaltyGold)).
 		Find(&serviceRequests).Error; err != nil {
-		log.Printf("❌ Failed to fetch service requests for category %d: %v", workerProfile.CategoryID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service requests"})
-		return
+		return nil, err
 	}
 	
-	log.Printf("🔍 Found %d broadcast requests in category %d", len(serviceRequests), workerProfile.CategoryID)
+	log.Printf("🔍 Found %d broadcast requests in categories %v", len(serviceRequests), categoryIDs)
+	
+	// Load every customer's default address in one query instead of once per request
+	defaultAddresses, err := loadDefaultAddresses(serviceRequests)
+	if err != nil {
+		log.Printf("⚠️ Failed to load customer default addresses: %v", err)
+	}
 	
 	// Filter requests by distance and add distance information
-	var availableRequests []gin.H
+	urgentRadiusMultiplier := services.NewSettingsService().Float(services.SettingUrgentBroadcastRadiusMultiplier)
+	var availableRequests []models.AvailableServiceRequestResponse
 	for _, request := range serviceRequests {
 		if hasLocationData {
 			distance := utils.HaversineDistance(
@@ -367,8 +729,11 @@ func getAvailableServiceRequests(c *gin.Context) {
 				*request.LocationLat, *request.LocationLng,
 			)
 			
-			// Use a default broadcast radius of 10km if not specified
-			broadcastRadius := 10.0
+			// Use the zone broadcast radius, or the 10km default if not specified, widened for urgent requests
+			broadcastRadius := zoneBroadcastRadius(request)
+			if request.Priority == "urgent" {
+				broadcastRadius *= urgentRadiusMultiplier
+			}
 			
 			if distance <= broadcastRadius {
 				eta := utils.CalculateETA(
@@ -377,152 +742,94 @@ func getAvailableServiceRequests(c *gin.Context) {
 					30.0, // Assume average speed of 30 km/h
 				)
 				
-				// Get customer details separately to avoid preload issues
-				var customer models.User
-				var customerName, customerPhone string
-				if err := database.DB.First(&customer, request.CustomerID).Error; err == nil {
-					customerName = customer.FullName
-					customerPhone = customer.PhoneNumber
-				} else {
-					customerName = "Unknown Customer"
-					customerPhone = ""
-				}
-				
-				// Get customer's default address for more detailed location info
-				var customerAddress models.Address
-				var addressDetails string
-				var customerLat, customerLng float64
-				if err := database.DB.Where("user_id = ? AND is_default = ?", request.CustomerID, true).First(&customerAddress).Error; err == nil {
-					addressDetails = customerAddress.AddressDetails
-					customerLat = customerAddress.Latitude
-					customerLng = customerAddress.Longitude
-				} else {
-					// Fallback to service request location if no default address
-					addressDetails = request.LocationAddress
-					if request.LocationLat != nil {
-						customerLat = *request.LocationLat
-					}
-					if request.LocationLng != nil {
-						customerLng = *request.LocationLng
-					}
-				}
-				
-				// Debug logging for coordinates
-				var requestLat, requestLng float64
-				if request.LocationLat != nil {
-					requestLat = *request.LocationLat
-				}
-				if request.LocationLng != nil {
-					requestLng = *request.LocationLng
-				}
-				log.Printf("🗺️ Request %d coordinates: customerLat=%.6f, customerLng=%.6f, requestLat=%.6f, requestLng=%.6f", 
-					request.ID, customerLat, customerLng, requestLat, requestLng)
-				
-				availableRequests = append(availableRequests, gin.H{
-					"id": request.ID,
-					"title": request.Title,
-					"description": request.Description,
-					"category_id": request.CategoryID,
-					"service_option_id": request.ServiceOptionID,
-					"location_address": request.LocationAddress,
-					"location_city": request.LocationCity,
-					"location_lat": request.LocationLat,
-					"location_lng": request.LocationLng,
-					"priority": request.Priority,
-					"budget": request.Budget,
-					"estimated_duration": request.EstimatedDuration,
-					"distance": distance,
-					"eta_minutes": int(eta.Minutes()),
-					"customer_name": customerName,
-					"customer_phone": customerPhone,
-					"customer_address_details": addressDetails,
-					"coordinates": gin.H{
-						"latitude": customerLat,
-						"longitude": customerLng,
-					},
-					"created_at": request.CreatedAt,
-					"status": request.Status,
-				})
+				etaMinutes := int(eta.Minutes())
+				availableRequests = append(availableRequests, availableRequestData(request, defaultAddresses, &distance, &etaMinutes))
 			}
 		} else {
 			// For workers without location data, show all requests in their category
-			// Get customer details separately to avoid preload issues
-			var customer models.User
-			var customerName, customerPhone string
-			if err := database.DB.First(&customer, request.CustomerID).Error; err == nil {
-				customerName = customer.FullName
-				customerPhone = customer.PhoneNumber
-			} else {
-				customerName = "Unknown Customer"
-				customerPhone = ""
-			}
-			
-			// Get customer's default address for more detailed location info
-			var customerAddress models.Address
-			var addressDetails string
-			var customerLat, customerLng float64
-			if err := database.DB.Where("user_id = ? AND is_default = ?", request.CustomerID, true).First(&customerAddress).Error; err == nil {
-				addressDetails = customerAddress.AddressDetails
-				customerLat = customerAddress.Latitude
-				customerLng = customerAddress.Longitude
-			} else {
-				// Fallback to service request location if no default address
-				addressDetails = request.LocationAddress
-				if request.LocationLat != nil {
-					customerLat = *request.LocationLat
-				}
-				if request.LocationLng != nil {
-					customerLng = *request.LocationLng
-				}
-			}
-			
-			// Debug logging for coordinates
-			var requestLat, requestLng float64
-			if request.LocationLat != nil {
-				requestLat = *request.LocationLat
-			}
-			if request.LocationLng != nil {
-				requestLng = *request.LocationLng
-			}
-			log.Printf("🗺️ Request %d coordinates (no location): customerLat=%.6f, customerLng=%.6f, requestLat=%.6f, requestLng=%.6f", 
-				request.ID, customerLat, customerLng, requestLat, requestLng)
-			
-			availableRequests = append(availableRequests, gin.H{
-				"id": request.ID,
-				"title": request.Title,
-				"description": request.Description,
-				"category_id": request.CategoryID,
-				"service_option_id": request.ServiceOptionID,
-				"location_address": request.LocationAddress,
-				"location_city": request.LocationCity,
-				"location_lat": request.LocationLat,
-				"location_lng": request.LocationLng,
-				"priority": request.Priority,
-				"budget": request.Budget,
-				"estimated_duration": request.EstimatedDuration,
-				"distance": nil,
-				"eta_minutes": nil,
-				"customer_name": customerName,
-				"customer_phone": customerPhone,
-				"customer_address_details": addressDetails,
-				"coordinates": gin.H{
-					"latitude": customerLat,
-					"longitude": customerLng,
-				},
-				"created_at": request.CreatedAt,
-				"status": request.Status,
-			})
+			availableRequests = append(availableRequests, availableRequestData(request, defaultAddresses, nil, nil))
 		}
 	}
 	
-	log.Printf("✅ Returning %d available requests for worker %d", len(availableRequests), workerProfile.ID)
+	return availableRequests, nil
+}
+
+// loadDefaultAddresses returns the default address of each request's customer, keyed by user ID
+func loadDefaultAddresses(requests []models.CustomerServiceRequest) (map[uint]models.Address, error) {
+	addresses := make(map[uint]models.Address)
+	if len(requests) == 0 {
+		return addresses, nil
+	}
 	
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"available_requests": availableRequests,
-		"total_count": len(availableRequests),
-		"worker_category": workerProfile.CategoryID,
-	})
+	customerIDs := make([]uint, 0, len(requests))
+	for _, request := range requests {
+		customerIDs = append(customerIDs, request.CustomerID)
+	}
+	
+	var defaults []models.Address
+	if err := database.DB.Where("user_id IN ? AND is_default = ?", customerIDs, true).Find(&defaults).Error; err != nil {
+		return addresses, err
+	}
+	for _, address := range defaults {
+		addresses[address.UserID] = address
+	}
+	return addresses, nil
+}
+
+// availableRequestData formats a broadcast request for the worker feed; distance and eta are nil
+// when the worker has no recent location
+func availableRequestData(request models.CustomerServiceRequest, defaultAddresses map[uint]models.Address, distance *float64, etaMinutes *int) models.AvailableServiceRequestResponse {
+	// Customer is joined with the request
+	customerName := "Unknown Customer"
+	var customerPhone string
+	if request.Customer.ID != 0 {
+		customerName = request.Customer.FullName
+		customerPhone = request.Customer.PhoneNumber
+	}
+	
+	// Prefer the customer's default address for more detailed location info
+	var addressDetails string
+	var customerLat, customerLng float64
+	if address, ok := defaultAddresses[request.CustomerID]; ok {
+		addressDetails = address.AddressDetails
+		customerLat = address.Latitude
+		customerLng = address.Longitude
+	} else {
+		// Fallback to service request location if no default address
+		addressDetails = request.LocationAddress
+		if request.LocationLat != nil {
+			customerLat = *request.LocationLat
+		}
+		if request.LocationLng != nil {
+			customerLng = *request.LocationLng
+		}
+	}
+	
+	return models.AvailableServiceRequestResponse{
+		ID:                     request.ID,
+		Title:                  request.Title,
+		Description:            request.Description,
+		CategoryID:             request.CategoryID,
+		ServiceOptionID:        request.ServiceOptionID,
+		LocationAddress:        request.LocationAddress,
+		LocationCity:           request.LocationCity,
+		LocationLat:            request.LocationLat,
+		LocationLng:            request.LocationLng,
+		Priority:               request.Priority,
+		Budget:                 request.Budget,
+		EstimatedDuration:      request.EstimatedDuration,
+		DurationEstimate:       requestDurationEstimate(request),
+		Distance:               distance,
+		ETAMinutes:             etaMinutes,
+		CustomerName:           customerName,
+		CustomerPhone:          customerPhone,
+		CustomerScore:          request.Customer.CustomerScore,
+		CustomerRatingCount:    request.Customer.CustomerRatingCount,
+		CustomerAddressDetails: addressDetails,
+		Coordinates:            models.Coordinates{Latitude: customerLat, Longitude: customerLng},
+		CreatedAt:              request.CreatedAt,
+		Status:                 request.Status,
+	}
 }
 
 // getWorkerActiveRequests returns active requests assigned to the worker
@@ -532,96 +839,179 @@ func getWorkerActiveRequests(c *gin.Context) {
 	// Get worker profile
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
-	// Get active requests (accepted and in-progress)
-	var serviceRequests []models.CustomerServiceRequest
-	if err := database.DB.Where(
-		"assigned_worker_id = ? AND status IN (?, ?)", 
-		workerProfile.ID, 
-		models.RequestStatusAccepted,
-		models.RequestStatusInProgress,
-	).
-	Order("created_at DESC").
-	Find(&serviceRequests).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch active requests"})
+	serviceRequests, err := workerActiveRequests(workerProfile.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch active requests", nil))
 		return
 	}
 	
+	capacity := services.CalculateWorkerCapacity(workerProfile.MaxConcurrentJobs, len(serviceRequests))
+	
 	// Format response
-	var activeRequests []gin.H
+	var activeRequests []models.ActiveServiceRequestResponse
 	for _, request := range serviceRequests {
-		// Get customer name separately to avoid preload issues
-		var customer models.User
-		var customerName string
-		if err := database.DB.First(&customer, request.CustomerID).Error; err == nil {
-			customerName = customer.FullName
-		} else {
-			customerName = "Unknown Customer"
-		}
-		
-		activeRequests = append(activeRequests, gin.H{
-			"id": request.ID,
-			"title": request.Title,
-			"description": request.Description,
-			"location_address": request.LocationAddress,
-			"location_city": request.LocationCity,
-			"priority": request.Priority,
-			"budget": request.Budget,
-			"estimated_duration": request.EstimatedDuration,
-			"status": request.Status,
-			"started_at": request.StartedAt,
-			"completed_at": request.CompletedAt,
-			"customer_name": customerName,
-			"created_at": request.CreatedAt,
-		})
+		activeRequests = append(activeRequests, activeRequestData(request))
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"active_requests": activeRequests,
 		"total_count": len(activeRequests),
+		"capacity": capacity,
 	})
 }
 
+// workerActiveRequests returns the worker's requests from accepted through in progress, newest
+// first, with their customer joined in the same query
+func workerActiveRequests(workerID uint) ([]models.CustomerServiceRequest, error) {
+	var serviceRequests []models.CustomerServiceRequest
+	err := database.DB.Joins("Customer").Where(
+		"customer_service_requests.assigned_worker_id = ? AND customer_service_requests.status IN ?", 
+		workerID, 
+		models.ActiveRequestStatuses,
+	).
+	Order("customer_service_requests.created_at DESC").
+	Find(&serviceRequests).Error
+	return serviceRequests, err
+}
+
+// activeRequestData formats an assigned request for the worker's active jobs
+func activeRequestData(request models.CustomerServiceRequest) models.ActiveServiceRequestResponse {
+	customerName := "Unknown Customer"
+	if request.Customer.ID != 0 {
+		customerName = request.Customer.FullName
+	}
+	
+	return models.ActiveServiceRequestResponse{
+		ID:                request.ID,
+		Title:             request.Title,
+		Description:       request.Description,
+		LocationAddress:   request.LocationAddress,
+		LocationCity:      request.LocationCity,
+		Priority:          request.Priority,
+		Budget:            request.Budget,
+		EstimatedDuration: request.EstimatedDuration,
+		Status:            request.Status,
+		StartedAt:         request.StartedAt,
+		CompletedAt:       request.CompletedAt,
+		CustomerName:      customerName,
+		CreatedAt:         request.CreatedAt,
+	}
+}
+
+// errRequestTaken aborts an accept that lost the race for a request to another worker
+var errRequestTaken = errors.New("service request already taken")
+
 // respondToServiceRequest allows workers to respond to service requests
 func respondToServiceRequest(c *gin.Context) {
 	requestID := c.Param("id")
 	userID := c.GetUint("user_id")
 	
 	var req models.WorkerResponseCreate
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	if req.Response != "decline" {
+		req.DeclineReason = ""
+	} else if req.DeclineReason == "" {
+		validation.Fail(c, "decline_reason", "required", "")
 		return
 	}
 	
 	// Get service request
 	var serviceRequest models.CustomerServiceRequest
 	if err := database.DB.Where("id = ?", requestID).First(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
 		return
 	}
 	
-	// Check if request is still available
-	if serviceRequest.Status != models.RequestStatusBroadcast {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request is no longer available"})
+	// Check if request is still available (broadcast, scheduled and not yet claimed, or offered via auto-dispatch)
+	isAutoDispatch := serviceRequest.DispatchMode == models.DispatchModeAuto && serviceRequest.Status == models.RequestStatusPending
+	if serviceRequest.Status != models.RequestStatusBroadcast && serviceRequest.Status != models.RequestStatusScheduled && !isAutoDispatch {
+		apierror.Abort(c, apierror.Validation("Service request is no longer available"))
 		return
 	}
 	
 	// Get worker profile
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+	
+	// Check the worker serves one of the request's categories
+	fulfilledCategoryID, err := services.NewCategoryService().ServedRequestCategory(workerProfile, serviceRequest)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load worker categories", err))
+		return
+	}
+	if fulfilledCategoryID == 0 {
+		apierror.Abort(c, apierror.Validation("Service category does not match worker's category"))
 		return
 	}
 	
-	// Check if worker category matches
-	if workerProfile.CategoryID != serviceRequest.CategoryID {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service category does not match worker's category"})
+	// Suspended workers cannot take jobs, and nobody can take back a job they gave up or missed
+	if workerProfile.IsSuspended(time.Now()) {
+		apierror.Abort(c, apierror.Forbidden("Your account is suspended until "+workerProfile.SuspendedUntil.Format("2006-01-02")))
 		return
 	}
+	if services.NewReliabilityService().DroppedRequest(serviceRequest.ID, workerProfile.ID) {
+		apierror.Abort(c, apierror.Forbidden("You gave up or missed this request and cannot take it again"))
+		return
+	}
+	
+	// Auto-dispatched requests can only be answered by the worker holding the current offer
+	matchingService := services.NewMatchingService()
+	var offer *models.DispatchOffer
+	if isAutoDispatch {
+		pendingOffer, err := matchingService.GetPendingOffer(serviceRequest.ID, workerProfile.ID)
+		if err != nil {
+			apierror.Abort(c, apierror.Forbidden("You do not have an active offer for this request"))
+			return
+		}
+		offer = pendingOffer
+	}
+	
+	// Workers can only accept jobs that fall inside their schedule and capacity
+	if req.Response == "accept" {
+		capacity, err := services.NewWorkerCapacityService().GetCapacity(&workerProfile)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to check active requests", err))
+			return
+		}
+		if !capacity.HasCapacity {
+			apierror.Abort(c, apierror.Validation("Worker has reached the maximum number of concurrent jobs").WithDetails(gin.H{"capacity": capacity}))
+			return
+		}
+		
+
+		jobTime := time.Now()
+		if serviceRequest.ScheduledFor != nil {
+			jobTime = *serviceRequest.ScheduledFor
+		}
+		onShift, err := services.NewWorkerScheduleService().IsWorkerAvailableAt(workerProfile.ID, jobTime)
+		if err != nil {
+			log.Printf("⚠️ Failed to check schedule for worker %d: %v", workerProfile.ID, err)
+		} else if !onShift {
+			apierror.Abort(c, apierror.Validation("Service request falls outside of your scheduled hours"))
+			return
+		}
+		
+		conflict, err := services.NewWorkerScheduleService().ConflictingJob(workerProfile.ID, serviceRequest)
+		if err != nil {
+			log.Printf("⚠️ Failed to check scheduled jobs of worker %d: %v", workerProfile.ID, err)
+		} else if conflict != nil {
+			apierror.Abort(c, apierror.Conflict("Service request overlaps another job you have scheduled").WithDetails(gin.H{
+				"service_request_id": conflict.ID,
+				"scheduled_for":      conflict.ScheduledFor,
+			}))
+			return
+		}
+	}
 	
 	// Calculate distance
 	var distance float64
@@ -638,25 +1028,60 @@ func respondToServiceRequest(c *gin.Context) {
 		WorkerID:         workerProfile.ID,
 		Response:         req.Response,
 		Message:          req.Message,
+		DeclineReason:    req.DeclineReason,
 		ProposedPrice:   req.ProposedPrice,
 		ProposedTime:    req.ProposedTime,
 		Distance:         distance,
 		RespondedAt:      time.Now(),
 	}
 	
-	if err := database.DB.Create(&workerResponse).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create response"})
+	// If worker accepts, claim the request in the same statement that checks it is still open, so
+	// two workers accepting at once cannot both be assigned
+	acceptedAt := time.Now()
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if req.Response == "accept" {
+			claimable := []models.CustomerServiceRequestStatus{models.RequestStatusBroadcast, models.RequestStatusScheduled}
+			if isAutoDispatch {
+				claimable = append(claimable, models.RequestStatusPending)
+			}
+			result := tx.Model(&models.CustomerServiceRequest{}).
+				Where("id = ? AND status IN ? AND assigned_worker_id IS NULL", serviceRequest.ID, claimable).
+				Updates(map[string]interface{}{
+					"assigned_worker_id":    workerProfile.ID,
+					"status":                models.RequestStatusAccepted,
+					"accepted_at":           &acceptedAt,
+					"fulfilled_category_id": fulfilledCategoryID,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errRequestTaken
+			}
+		}
+		return tx.Create(&workerResponse).Error
+	})
+	if errors.Is(err, errRequestTaken) {
+		apierror.Abort(c, apierror.Conflict("Service request has already been taken"))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create response", err))
 		return
 	}
 	
-	// If worker accepts, assign them to the request
 	if req.Response == "accept" {
 		serviceRequest.Status = models.RequestStatusAccepted
 		serviceRequest.AssignedWorkerID = &workerProfile.ID
+		serviceRequest.AcceptedAt = &acceptedAt
+		serviceRequest.FulfilledCategoryID = &fulfilledCategoryID
+		publishRequestEvent("request_accepted", serviceRequest)
+		openRequestChatRoom(serviceRequest, userID)
 		
-		if err := database.DB.Save(&serviceRequest).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign worker"})
-			return
+		if offer != nil {
+			if err := matchingService.ResolveOffer(offer, models.OfferStatusAccepted); err != nil {
+				log.Printf("⚠️ Failed to mark dispatch offer %d accepted: %v", offer.ID, err)
+			}
 		}
 		
 		// Send notification to customer about acceptance
@@ -672,6 +1097,15 @@ func respondToServiceRequest(c *gin.Context) {
 			log.Printf("⚠️ Failed to track job response analytics: %v", err)
 			// Don't fail the response, just log the error
 		}
+	} else if req.Response == "decline" {
+		trackJobDecline(workerProfile.ID, serviceRequest.ID, req.DeclineReason)
+		if offer != nil {
+			// Declined offers cascade to the next best worker right away
+			if err := matchingService.ResolveOffer(offer, models.OfferStatusDeclined); err != nil {
+				log.Printf("⚠️ Failed to mark dispatch offer %d declined: %v", offer.ID, err)
+			}
+			go dispatchServiceRequest(serviceRequest)
+		}
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
@@ -681,107 +1115,97 @@ func respondToServiceRequest(c *gin.Context) {
 	})
 }
 
-// Worker response to service request
-func workerRespondToRequest(c *gin.Context) {
-	// Get worker profile
-	workerID := c.GetUint("user_id")
-	var workerProfile models.WorkerProfile
-	if err := database.DB.Where("user_id = ?", workerID).First(&workerProfile).Error; err != nil {
-		log.Printf("❌ Worker profile not found for user %d: %v", workerID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
-		return
+// filterWorkersBySchedule drops workers who are off shift or on time off at the given time
+func filterWorkersBySchedule(workers []models.WorkerProfile, at time.Time) []models.WorkerProfile {
+	workerIDs := make([]uint, len(workers))
+	for i, w := range workers {
+		workerIDs[i] = w.ID
 	}
-
-	log.Printf("🔍 Worker profile found: ID=%d, UserID=%d, CategoryID=%d", 
-		workerProfile.ID, workerProfile.UserID, workerProfile.CategoryID)
-
-	// Parse request
-	var req struct {
-		Response       string  `json:"response" binding:"required,oneof=accept decline"`
-		Message        string  `json:"message"`
-		ProposedPrice *float64 `json:"proposed_price"`
-		ProposedTime  string  `json:"proposed_time"`
+	
+	onShift, err := services.NewWorkerScheduleService().FilterAvailableAt(workerIDs, at)
+	if err != nil {
+		log.Printf("⚠️ Failed to check worker schedules, ignoring schedules: %v", err)
+		return workers
 	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("❌ JSON binding error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
-		return
+	
+	filtered := make([]models.WorkerProfile, 0, len(workers))
+	for _, w := range workers {
+		if onShift[w.ID] {
+			filtered = append(filtered, w)
+		}
 	}
+	return filtered
+}
 
-	log.Printf("🔍 Worker %d responding to request with: %s", workerID, req.Response)
-
-	// Get service request ID from URL
-	requestID := c.Param("id")
-	if requestID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request ID is required"})
-		return
+// checkUrgent aborts and returns false when an urgent request is made in a category where urgent
+// service is turned off
+func checkUrgent(c *gin.Context, categoryID uint, priority string) bool {
+	if priority != "urgent" {
+		return true
 	}
-
-	// Parse request ID
-	requestIDInt, err := strconv.Atoi(requestID)
+	allowed, err := services.NewCategoryService().UrgentAllowed(categoryID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service request ID"})
-		return
+		apierror.Abort(c, apierror.Internal("Failed to load category", err))
+		return false
 	}
-
-	// Get service request
-	var serviceRequest models.CustomerServiceRequest
-	if err := database.DB.Preload("Customer").First(&serviceRequest, requestIDInt).Error; err != nil {
-		log.Printf("❌ Service request %d not found: %v", requestIDInt, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
-		return
+	if !allowed {
+		validation.Fail(c, "priority", "urgent_disabled", "")
+		return false
 	}
+	return true
+}
 
-	log.Printf("🔍 Service request %d found: status=%s, category_id=%d", 
-		requestIDInt, serviceRequest.Status, serviceRequest.CategoryID)
+// checkExtraCategories aborts and returns false when the extra categories of a new request are
+// not all active categories; otherwise it returns them ready to save with the request
+func checkExtraCategories(c *gin.Context, req models.CustomerServiceRequestCreate) ([]models.RequestCategory, bool) {
+	extra, err := services.NewCategoryService().ExtraCategories(req.CategoryID, req.ExtraCategoryIDs)
+	if errors.Is(err, services.ErrUnknownCategory) {
+		validation.Fail(c, "extra_category_ids", "unknown_category", "")
+		return nil, false
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load categories", err))
+		return nil, false
+	}
+	return extra, true
+}
 
-	// Check if request is still available
-	if serviceRequest.Status != models.RequestStatusBroadcast {
-		log.Printf("❌ Service request %d status is %s, expected %s", 
-			requestIDInt, serviceRequest.Status, models.RequestStatusBroadcast)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request is no longer available"})
-		return
+// applyDispatchMode sets the dispatch mode and the matching initial status on a new request.
+// Urgent requests are always broadcast, so every nearby worker sees them at once.
+func applyDispatchMode(serviceRequest *models.CustomerServiceRequest, mode models.DispatchMode) {
+	if mode == "" {
+		mode = defaultDispatchMode()
+	}
+	if serviceRequest.Priority == "urgent" {
+		mode = models.DispatchModeBroadcast
 	}
+	serviceRequest.DispatchMode = mode
+	if mode == models.DispatchModeAuto {
+		serviceRequest.Status = models.RequestStatusPending
+	}
+}
 
-	// Check if worker category matches
-	if workerProfile.CategoryID != serviceRequest.CategoryID {
-		log.Printf("❌ Worker category %d does not match service request category %d", 
-			workerProfile.CategoryID, serviceRequest.CategoryID)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Worker category does not match service request category"})
+// startDispatch hands a new request to the broadcast or auto-assignment flow
+func startDispatch(serviceRequest models.CustomerServiceRequest) {
+	if serviceRequest.DispatchMode == models.DispatchModeAuto {
+		go dispatchServiceRequest(serviceRequest)
 		return
 	}
+	go broadcastServiceRequest(serviceRequest)
+}
 
-	// Handle response
-	if req.Response == "accept" {
-		log.Printf("✅ Worker %d accepting service request %d", workerID, requestIDInt)
-		
-		// Update service request status to accepted
-		serviceRequest.Status = models.RequestStatusAccepted
-		serviceRequest.AssignedWorkerID = &workerProfile.ID
-		
-		if err := database.DB.Save(&serviceRequest).Error; err != nil {
-			log.Printf("❌ Failed to update service request %d: %v", requestIDInt, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update service request"})
-			return
+// zoneBroadcastRadius returns the broadcast radius configured on the request's zone
+func zoneBroadcastRadius(serviceRequest models.CustomerServiceRequest) float64 {
+	if serviceRequest.ServiceZone != nil && serviceRequest.ServiceZone.BroadcastRadiusKm > 0 {
+		return serviceRequest.ServiceZone.BroadcastRadiusKm
+	}
+	if serviceRequest.ServiceZoneID != nil {
+		var zone models.ServiceZone
+		if err := database.DB.First(&zone, *serviceRequest.ServiceZoneID).Error; err == nil && zone.BroadcastRadiusKm > 0 {
+			return zone.BroadcastRadiusKm
 		}
-		
-		log.Printf("✅ Service request %d assigned to worker %d (profile ID: %d)", 
-			requestIDInt, workerID, workerProfile.ID)
-		
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Service request accepted successfully",
-			"request_status": serviceRequest.Status,
-			"assigned_worker_id": serviceRequest.AssignedWorkerID,
-		})
-	} else {
-		log.Printf("❌ Worker %d declining service request %d", workerID, requestIDInt)
-		c.JSON(http.StatusOK, gin.H{
-			"success": true,
-			"message": "Response submitted successfully",
-		})
 	}
+	return utils.GetDefaultBroadcastRadius()
 }
 
 // Helper function to broadcast service request to nearby workers
@@ -793,6 +1217,7 @@ func broadcastServiceRequest(serviceRequest models.CustomerServiceRequest) {
 		log.Printf("❌ Failed to update service request status: %v", err)
 		return
 	}
+	pushRequestStatus("request_broadcast", serviceRequest)
 	
 	log.Printf("📡 Broadcasting service request %d to category %d workers", 
 		serviceRequest.ID, serviceRequest.CategoryID)
@@ -800,19 +1225,27 @@ func broadcastServiceRequest(serviceRequest models.CustomerServiceRequest) {
 	// Send real-time WebSocket notification to workers
 	broadcastServiceRequestViaWebSocket(serviceRequest)
 	
-	// Find available workers in the same category within broadcast radius
-	// Exclude workers who are already working on other requests
+	// Find available workers tagged for the category or its parent within broadcast radius
+	// Exclude workers who have reached their concurrent job limit
+	categoryIDs, err := services.NewCategoryService().TargetCategoryIDs(serviceRequest)
+	if err != nil {
+		log.Printf("❌ Failed to resolve categories for request %d: %v", serviceRequest.ID, err)
+		return
+	}
 	var availableWorkers []models.WorkerProfile
-	err := database.DB.Where(
-		"category_id = ? AND is_available = ? AND current_lat IS NOT NULL AND current_lng IS NOT NULL AND id NOT IN (SELECT DISTINCT assigned_worker_id FROM customer_service_requests WHERE assigned_worker_id IS NOT NULL AND status IN (?, ?))",
-		serviceRequest.CategoryID, true, models.RequestStatusAccepted, models.RequestStatusInProgress,
-	).Preload("User").Find(&availableWorkers).Error
+	err = database.DB.Where(
+		"is_available = ? AND current_lat IS NOT NULL AND current_lng IS NOT NULL",
+		true,
+	).Scopes(services.WorkerInCategoriesScope(categoryIDs), services.HasCapacityScope, services.NotSuspendedScope).Preload("User").Find(&availableWorkers).Error
 	
 	if err != nil {
 		log.Printf("❌ Failed to find available workers: %v", err)
 		return
 	}
 	
+	// Keep only workers whose weekly schedule and time off allow them to work now
+	availableWorkers = filterWorkersBySchedule(availableWorkers, time.Now())
+	
 	log.Printf("👷 Found %d available category workers", len(availableWorkers))
 	
 	// If no workers found, let's check what's in the database
@@ -821,7 +1254,7 @@ func broadcastServiceRequest(serviceRequest models.CustomerServiceRequest) {
 		
 		// Check all workers in this category
 		var allWorkersInCategory []models.WorkerProfile
-		if err := database.DB.Where("category_id = ?", serviceRequest.CategoryID).Find(&allWorkersInCategory).Error; err == nil {
+		if err := database.DB.Scopes(services.WorkerInCategoriesScope(categoryIDs)).Find(&allWorkersInCategory).Error; err == nil {
 			log.Printf("📊 Total workers in category %d: %d", serviceRequest.CategoryID, len(allWorkersInCategory))
 			for _, w := range allWorkersInCategory {
 				log.Printf("👷 Worker %d: available=%v, has_location=%v, lat=%v, lng=%v", 
@@ -840,23 +1273,52 @@ func broadcastServiceRequest(serviceRequest models.CustomerServiceRequest) {
 		}
 	}
 	
-	// Filter workers by distance and notify them
+	// Check if worker is within broadcast radius (default 10km, overridable per zone). Urgent
+	// requests reach farther.
+	broadcastRadius := zoneBroadcastRadius(serviceRequest)
+	urgent := serviceRequest.Priority == "urgent"
+	if urgent {
+		broadcastRadius *= services.NewSettingsService().Float(services.SettingUrgentBroadcastRadiusMultiplier)
+	}
+	
+	// Filter workers by distance and notify them; workers with reduced priority are notified later,
+	// except of urgent requests
+	var delayed []models.WorkerProfile
 	for _, worker := range availableWorkers {
-		if worker.CurrentLat != nil && worker.CurrentLng != nil && serviceRequest.LocationLat != nil && serviceRequest.LocationLng != nil {
-			distance := utils.HaversineDistance(
-				*worker.CurrentLat, *worker.CurrentLng,
-				*serviceRequest.LocationLat, *serviceRequest.LocationLng,
-			)
-			
-			// Check if worker is within broadcast radius (default 10km)
-			broadcastRadius := 10.0
-			if distance <= broadcastRadius {
-				log.Printf("📱 Notifying worker %d (distance: %.2f km)", worker.ID, distance)
-				
-				// Send real-time WebSocket notification
-				notifyWorkerViaWebSocket(worker, serviceRequest, distance)
-			}
+		if services.HasReducedPriority(worker) && !urgent {
+			delayed = append(delayed, worker)
+			continue
 		}
+		notifyWorkerInRadius(worker, serviceRequest, broadcastRadius)
+	}
+	if len(delayed) > 0 {
+		time.AfterFunc(services.ReducedPriorityDelay, func() {
+			var current models.CustomerServiceRequest
+			if err := database.DB.First(&current, serviceRequest.ID).Error; err != nil || current.Status != models.RequestStatusBroadcast {
+				return
+			}
+			for _, worker := range delayed {
+				notifyWorkerInRadius(worker, current, broadcastRadius)
+			}
+		})
+	}
+}
+
+// notifyWorkerInRadius notifies the worker of a broadcast request when they are within radiusKm of it
+func notifyWorkerInRadius(worker models.WorkerProfile, serviceRequest models.CustomerServiceRequest, radiusKm float64) {
+	if worker.CurrentLat == nil || worker.CurrentLng == nil || serviceRequest.LocationLat == nil || serviceRequest.LocationLng == nil {
+		return
+	}
+	distance := utils.HaversineDistance(
+		*worker.CurrentLat, *worker.CurrentLng,
+		*serviceRequest.LocationLat, *serviceRequest.LocationLng,
+	)
+	if distance <= radiusKm {
+		log.Printf("📱 Notifying worker %d (distance: %.2f km)", worker.ID, distance)
+		
+		// Send real-time WebSocket notification
+		notifyWorkerViaWebSocket(worker, serviceRequest, distance)
+		pushBroadcastRequest(worker, serviceRequest, distance)
 	}
 }
 
@@ -889,22 +1351,72 @@ func broadcastServiceRequestViaWebSocket(serviceRequest models.CustomerServiceRe
 	// This will send real-time notifications to workers like Deliveroo/Glovo
 }
 
-// Helper function to notify a worker via WebSocket
+// notifyWorkerViaWebSocket shows a broadcast request to a connected worker. Urgent requests come as
+// urgent_request so the app can highlight them.
 func notifyWorkerViaWebSocket(worker models.WorkerProfile, request models.CustomerServiceRequest, distance float64) {
-	// This function will be implemented when the WebSocket hub is properly integrated
-	// For now, it just logs the notification
+	if chatHub == nil {
+		return
+	}
+	eventType := "new_request"
+	if request.Priority == "urgent" {
+		eventType = "urgent_request"
+	}
 	log.Printf("📱 Notifying worker %d (distance: %.2f km) via WebSocket", worker.ID, distance)
+	chatHub.SendToUser(worker.UserID, &ws.Message{
+		Type: eventType,
+		Data: gin.H{
+			"request_id":        request.ID,
+			"title":             request.Title,
+			"category_id":       request.CategoryID,
+			"priority":          request.Priority,
+			"distance":          distance,
+			"budget":            request.Budget,
+			"expires_at":        request.ExpiresAt,
+			"duration_estimate": requestDurationEstimate(request),
+		},
+		Timestamp: time.Now(),
+	})
 }
 
-// Additional helper functions for request management
-func updateServiceRequestStatus(c *gin.Context) {
-	// Implementation for updating request status
-	c.JSON(http.StatusOK, gin.H{"message": "Status updated"})
-}
-
+// cancelServiceRequest lets a customer cancel their request before work has started
 func cancelServiceRequest(c *gin.Context) {
-	// Implementation for canceling requests
-	c.JSON(http.StatusOK, gin.H{"message": "Request cancelled"})
+	userID := c.GetUint("user_id")
+	
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Where("id = ? AND customer_id = ?", c.Param("id"), userID).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	
+	switch serviceRequest.Status {
+	case models.RequestStatusPending, models.RequestStatusBroadcast, models.RequestStatusScheduled, models.RequestStatusAccepted,
+		models.RequestStatusEnRoute, models.RequestStatusArrived:
+	default:
+		apierror.Abort(c, apierror.Validation("Service request can no longer be cancelled"))
+		return
+	}
+	
+	serviceRequest.Status = models.RequestStatusCancelled
+	if err := database.DB.Save(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to cancel service request", err))
+		return
+	}
+	publishRequestEvent("request_cancelled", serviceRequest)
+	
+	// Let the assigned worker know the job is off
+	if serviceRequest.AssignedWorkerID != nil {
+		var worker models.WorkerProfile
+		if err := database.DB.First(&worker, *serviceRequest.AssignedWorkerID).Error; err == nil {
+			if err := SendServiceStatusNotification(worker.UserID, serviceRequest.ID, "cancelled"); err != nil {
+				log.Printf("⚠️ Failed to send cancellation notification: %v", err)
+			}
+		}
+	}
+	
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Request cancelled",
+		"service_request": serializers.ServiceRequest(serviceRequest),
+	})
 }
 
 func reviewService(c *gin.Context) {
@@ -922,7 +1434,7 @@ func startServiceRequest(c *gin.Context) {
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
 		log.Printf("❌ Worker profile not found for user %d: %v", userID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -932,13 +1444,14 @@ func startServiceRequest(c *gin.Context) {
 	var serviceRequest models.CustomerServiceRequest
 	if err := database.DB.Where("id = ?", requestID).First(&serviceRequest).Error; err != nil {
 		log.Printf("❌ Service request %s not found: %v", requestID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
 		return
 	}
 
 	// Optionally capture agreed price from body (non-fatal if missing)
 	var body struct {
-		AgreedPrice *float64 `json:"agreed_price"`
+		AgreedPrice *money.Amount `json:"agreed_price"`
+		StartCode   string        `json:"start_code"`
 	}
 	_ = c.ShouldBindJSON(&body)
 	
@@ -948,22 +1461,29 @@ func startServiceRequest(c *gin.Context) {
 	// Check if request is assigned to this worker (compare with worker profile ID)
 	if serviceRequest.AssignedWorkerID == nil {
 		log.Printf("❌ Service request %s has no assigned worker", requestID)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Service request is not assigned to any worker"})
+		apierror.Abort(c, apierror.Forbidden("Service request is not assigned to any worker"))
 		return
 	}
 	
 	if *serviceRequest.AssignedWorkerID != workerProfile.ID {
 		log.Printf("❌ Worker profile %d not assigned to request %s (assigned to %d)", 
 			workerProfile.ID, requestID, *serviceRequest.AssignedWorkerID)
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not assigned to this request"})
+		apierror.Abort(c, apierror.Forbidden("You are not assigned to this request"))
 		return
 	}
 	
-	// Check if request is in accepted status
-	if serviceRequest.Status != models.RequestStatusAccepted {
+	// Work can start once accepted, whether or not the worker reported travelling and arriving
+	switch serviceRequest.Status {
+	case models.RequestStatusAccepted, models.RequestStatusEnRoute, models.RequestStatusArrived:
+	default:
 		log.Printf("❌ Service request %s status is %s, expected %s", 
 			requestID, serviceRequest.Status, models.RequestStatusAccepted)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request is not in accepted status"})
+		apierror.Abort(c, apierror.Validation("Service request is not in accepted status"))
+		return
+	}
+	
+	// The customer's start code proves the worker is on site
+	if !verifyJobCode(c, serviceRequest, workerProfile.ID, models.JobCodeStart, "start_code", body.StartCode) {
 		return
 	}
 	
@@ -971,16 +1491,24 @@ func startServiceRequest(c *gin.Context) {
 	now := time.Now()
 	serviceRequest.Status = models.RequestStatusInProgress
 	serviceRequest.StartedAt = &now
+	priceChanged := body.AgreedPrice != nil && (serviceRequest.Price() == nil || *serviceRequest.Price() != *body.AgreedPrice)
 	if body.AgreedPrice != nil {
-		serviceRequest.Budget = body.AgreedPrice
+		serviceRequest.AgreedPrice = body.AgreedPrice
 	}
 	
 	if err := database.DB.Save(&serviceRequest).Error; err != nil {
 		log.Printf("❌ Failed to update service request %s: %v", requestID, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start service request"})
+		apierror.Abort(c, apierror.Internal("Failed to start service request", nil))
 		return
 	}
 	
+	pushRequestStatus("request_started", serviceRequest)
+	postRequestSystemMessage(serviceRequest, systemEventStarted, "🔧 Work started.")
+	if priceChanged {
+		postRequestSystemMessage(serviceRequest, systemEventPriceUpdated,
+			fmt.Sprintf("💰 Agreed price set to %s.", requestMoney(serviceRequest, *serviceRequest.Price())))
+	}
+
 	// Send notification to customer about work starting
 	if err := SendServiceStatusNotification(serviceRequest.CustomerID, serviceRequest.ID, "in_progress"); err != nil {
 		log.Printf("⚠️ Failed to send work started notification: %v", err)
@@ -993,7 +1521,7 @@ func startServiceRequest(c *gin.Context) {
 		"message": "Work started successfully",
 		"request_status": serviceRequest.Status,
 		"started_at": serviceRequest.StartedAt,
-		"agreed_price": serviceRequest.Budget,
+		"agreed_price": serviceRequest.Price(),
 	})
 }
 
@@ -1005,159 +1533,163 @@ func completeServiceRequest(c *gin.Context) {
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
 		log.Printf("❌ Worker profile not found for user %d: %v", userID, err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
 	// Get service request
 	var serviceRequest models.CustomerServiceRequest
 	if err := database.DB.Where("id = ?", requestID).First(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Service request not found"})
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
 		return
 	}
 	
 	// Check if request is assigned to this worker (compare with worker profile ID)
 	if serviceRequest.AssignedWorkerID == nil || *serviceRequest.AssignedWorkerID != workerProfile.ID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not assigned to this request"})
+		apierror.Abort(c, apierror.Forbidden("You are not assigned to this request"))
 		return
 	}
 	
 	// Check if request is in progress
 	if serviceRequest.Status != models.RequestStatusInProgress {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Service request is not in progress"})
-		return
-	}
-	
-	// Update status to completed
-	now := time.Now()
-	serviceRequest.Status = models.RequestStatusCompleted
-	serviceRequest.CompletedAt = &now
-	
-	if err := database.DB.Save(&serviceRequest).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete service request"})
+		apierror.Abort(c, apierror.Validation("Service request is not in progress"))
 		return
 	}
 	
-	// Automatically create service history entry
-	historyData := models.ServiceHistoryCreate{
-		ServiceRequestID: serviceRequest.ID,
-		WorkerID:         workerProfile.ID,
-		ActualDuration:   nil, // Worker can update this later
-		AgreedPrice:      serviceRequest.Budget, // Use budget as agreed price
-		FinalPrice:       serviceRequest.Budget, // Use budget as final price
-		PaymentStatus:    "pending",
-		WorkerNotes:      "",
-		CustomerNotes:    "",
-	}
-	
-	// Create service history
-	history := models.ServiceHistory{
-		ServiceRequestID:  historyData.ServiceRequestID,
-		WorkerID:          historyData.WorkerID,
-		CustomerID:        serviceRequest.CustomerID,
-		CategoryID:        serviceRequest.CategoryID,
-		ServiceOptionID:   serviceRequest.ServiceOptionID,
-		Title:             serviceRequest.Title,
-		Description:       serviceRequest.Description,
-		Priority:          serviceRequest.Priority,
-		Budget:            serviceRequest.Budget,
-		EstimatedDuration: serviceRequest.EstimatedDuration,
-		ActualDuration:    historyData.ActualDuration,
-		LocationAddress:   serviceRequest.LocationAddress,
-		LocationCity:      serviceRequest.LocationCity,
-		LocationLat:       serviceRequest.LocationLat,
-		LocationLng:       serviceRequest.LocationLng,
-		RequestCreatedAt:  serviceRequest.CreatedAt,
-		AssignedAt:        nil, // Will be set when worker accepts
-		StartedAt:         serviceRequest.StartedAt,
-		CompletedAt:       *serviceRequest.CompletedAt,
-		AgreedPrice:       historyData.AgreedPrice,
-		FinalPrice:        historyData.FinalPrice,
-		PaymentStatus:     historyData.PaymentStatus,
-		WorkerNotes:       historyData.WorkerNotes,
-		CustomerNotes:     historyData.CustomerNotes,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
-	}
-	
-	if err := database.DB.Create(&history).Error; err != nil {
-		log.Printf("⚠️ Failed to create service history for request %d: %v", serviceRequest.ID, err)
-		// Don't fail the completion, just log the error
-	} else {
-		log.Printf("✅ Service history created for completed request %d", serviceRequest.ID)
-	}
-	
-	// Update worker profile statistics
-	if err := updateWorkerServiceStats(workerProfile.ID); err != nil {
-		log.Printf("⚠️ Failed to update worker stats for worker %d: %v", workerProfile.ID, err)
-		// Don't fail the completion, just log the error
+	// The customer's completion code confirms they agree the work is done
+	var body struct {
+		CompletionCode string `json:"completion_code"`
 	}
-	
-	// Track analytics for worker performance
-	analyticsService := services.NewWorkerAnalyticsService()
-	
-	// Handle budget conversion (it's a pointer)
-	var earnings float64
-	if serviceRequest.Budget != nil {
-		earnings = *serviceRequest.Budget
+	_ = c.ShouldBindJSON(&body)
+	if !verifyJobCode(c, serviceRequest, workerProfile.ID, models.JobCodeCompletion, "completion_code", body.CompletionCode) {
+		return
 	}
 	
-	// Handle duration conversion (it's a string, convert to float)
-	var workHours float64
-	if duration, err := strconv.ParseFloat(serviceRequest.EstimatedDuration, 64); err == nil {
-		workHours = duration / 60.0 // Convert minutes to hours
+	// The worker earns the price charged for the work, plus approved parts
+	var earnings money.Amount
+	if price := serviceRequest.Price(); price != nil {
+		earnings = *price
 	}
 	
-	if err := analyticsService.TrackJobCompletion(workerProfile.ID, serviceRequest.ID, earnings, workHours); err != nil {
-		log.Printf("⚠️ Failed to track job completion analytics: %v", err)
-		// Don't fail the completion, just log the error
-	}
+	// The customer's estimate stands in for the work time unless the worker used the timer
+	workHours := serviceRequest.EstimatedDuration.Hours()
 	
-	// Send notification to customer about completion
-	if err := SendServiceStatusNotification(serviceRequest.CustomerID, serviceRequest.ID, "completed"); err != nil {
-		log.Printf("⚠️ Failed to send completion notification: %v", err)
-	}
-
-	// Send feedback request notification to customer after first completion
-	var customerCompleted int64
-	database.DB.Model(&models.ServiceHistory{}).Where("customer_id = ?", serviceRequest.CustomerID).Count(&customerCompleted)
-	if customerCompleted == 1 {
-		customerFeedbackData := map[string]interface{}{
-			"action": "feedback_request",
-			"role": "customer",
-			"service_request_id": serviceRequest.ID,
+	// Complete the request, create its history and update worker stats atomically. Analytics and
+	// notifications go through the outbox so they are delivered only if the completion commits.
+	now := time.Now()
+	var history models.ServiceHistory
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Re-read under lock so two concurrent completions cannot both pass the status check
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&serviceRequest, serviceRequest.ID).Error; err != nil {
+			return err
 		}
-		if err := SendPushNotification(serviceRequest.CustomerID,
-			"We value your feedback",
-			"Your first service is complete! Please share your feedback to help us improve.",
-			"feedback_request",
-			customerFeedbackData); err != nil {
-			log.Printf("⚠️ Failed to send customer feedback request notification: %v", err)
-		} else {
-			log.Printf("✅ Feedback request notification sent to customer %d", serviceRequest.CustomerID)
+		if serviceRequest.Status != models.RequestStatusInProgress {
+			return errRequestNotInProgress
 		}
-	}
-
-	// Send feedback request notification to worker after first completion
-	var completedJobs int64
-	database.DB.Model(&models.ServiceHistory{}).Where("worker_id = ?", workerProfile.ID).Count(&completedJobs)
-	
-	if completedJobs == 1 { // First job completion
-		feedbackData := map[string]interface{}{
-			"action": "feedback_request",
-			"worker_id": workerProfile.ID,
-			"service_request_id": serviceRequest.ID,
+		
+		// Every part must be approved or rejected before the final price is fixed
+		pending, err := services.CountPendingLineItems(tx, serviceRequest.ID)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return errLineItemsPending
+		}
+		parts, partsTotal, err := services.ApprovedLineItems(tx, serviceRequest.ID)
+		if err != nil {
+			return err
+		}
+		agreedPrice := serviceRequest.Price()
+		finalPrice := agreedPrice
+		if partsTotal > 0 {
+			total := earnings + partsTotal
+			finalPrice = &total
+			earnings = total
 		}
 		
-		if err := SendPushNotification(userID, 
-			"Help Us Improve Your Experience", 
-			"Your first job is complete! Please share your feedback to help us enhance your experience.", 
-			"feedback_request", 
-			feedbackData); err != nil {
-			log.Printf("⚠️ Failed to send feedback request notification: %v", err)
-		} else {
-			log.Printf("✅ Feedback request notification sent to worker %d", userID)
+		// Stop the work timer; time tracked on it replaces the estimate
+		if err := services.StopWorkTimer(tx, &serviceRequest, now); err != nil {
+			return err
+		}
+		var actualDuration *int
+		if serviceRequest.WorkedSeconds > 0 {
+			minutes := int(math.Ceil(float64(serviceRequest.WorkedSeconds) / 60))
+			actualDuration = &minutes
+			workHours = float64(serviceRequest.WorkedSeconds) / 3600
+		}
+		
+		serviceRequest.Status = models.RequestStatusCompleted
+		serviceRequest.CompletedAt = &now
+		if err := tx.Save(&serviceRequest).Error; err != nil {
+			return err
 		}
+		
+		// Automatically create service history entry, charging the request's price and its parts
+		history = models.ServiceHistory{
+			ServiceRequestID:  serviceRequest.ID,
+			WorkerID:          workerProfile.ID,
+			CustomerID:        serviceRequest.CustomerID,
+			CategoryID:        serviceRequest.CategoryID,
+			ServiceOptionID:   serviceRequest.ServiceOptionID,
+			Title:             serviceRequest.Title,
+			Description:       serviceRequest.Description,
+			Priority:          serviceRequest.Priority,
+			Budget:            serviceRequest.Budget,
+			EstimatedDuration: serviceRequest.EstimatedDuration,
+			ActualDuration:    actualDuration, // Nil unless the worker used the timer
+			LocationAddress:   serviceRequest.LocationAddress,
+			LocationCity:      serviceRequest.LocationCity,
+			LocationLat:       serviceRequest.LocationLat,
+			LocationLng:       serviceRequest.LocationLng,
+			RequestCreatedAt:  serviceRequest.CreatedAt,
+			AssignedAt:        nil, // Will be set when worker accepts
+			StartedAt:         serviceRequest.StartedAt,
+			CompletedAt:       now,
+			AgreedPrice:       agreedPrice,
+			FinalPrice:        finalPrice,
+			PartsTotal:        partsTotal,
+			PaymentStatus:     models.PaymentStatusPending,
+			CommissionPercent: services.NewRegionService().CommissionPercentForRequest(serviceRequest),
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		if err := tx.Create(&history).Error; err != nil {
+			return err
+		}
+		
+		// Update worker profile statistics
+		if err := updateWorkerServiceStats(tx, workerProfile.ID); err != nil {
+			return err
+		}
+		
+		return enqueueCompletionEvents(tx, serviceRequest, workerProfile, userID, earnings, workHours, parts)
+	})
+	if err == errRequestNotInProgress {
+		apierror.Abort(c, apierror.Validation("Service request is not in progress"))
+		return
+	}
+	if err == errLineItemsPending {
+		apierror.Abort(c, apierror.Conflict("Some parts are still waiting for customer approval"))
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to complete service request %d: %v", serviceRequest.ID, err)
+		apierror.Abort(c, apierror.Internal("Failed to complete service request", nil))
+		return
+	}
+	publishRequestEvent("request_completed", serviceRequest)
+	completedText := "🏁 Work completed."
+	if history.FinalPrice != nil {
+		completedText = fmt.Sprintf("🏁 Work completed. Final price: %s.", requestMoney(serviceRequest, *history.FinalPrice))
+	}
+	postRequestSystemMessage(serviceRequest, systemEventCompleted, completedText)
+	
+	// The nightly jobs catch up if these fail
+	if _, err := services.NewLoyaltyService().Recompute(serviceRequest.CustomerID); err != nil {
+		log.Printf("⚠️ Failed to update loyalty for customer %d: %v", serviceRequest.CustomerID, err)
+	}
+	if err := services.NewCustomerAnalyticsService().AggregateCustomer(serviceRequest.CustomerID); err != nil {
+		log.Printf("⚠️ Failed to aggregate spend for customer %d: %v", serviceRequest.CustomerID, err)
 	}
 	
 	log.Printf("✅ Worker %d (profile %d) completed service request %d", userID, workerProfile.ID, serviceRequest.ID)
@@ -1171,6 +1703,133 @@ func completeServiceRequest(c *gin.Context) {
 	})
 }
 
+// enqueueRequestConfirmationEmail emails the customer a confirmation of their new request
+func enqueueRequestConfirmationEmail(request models.CustomerServiceRequest) {
+	data := map[string]interface{}{
+		"request_id": request.ID,
+		"title":      request.Title,
+		"address":    request.LocationAddress,
+		"currency":   services.NewRegionService().ForRequest(request).Currency,
+	}
+	if request.Budget != nil {
+		data["budget"] = *request.Budget
+	}
+	if err := services.EnqueueOutboxEvent(database.DB, models.OutboxEventEmail, request.ID, models.EmailPayload{
+		UserID:   request.CustomerID,
+		Template: services.EmailTemplateRequestConfirmation,
+		Data:     data,
+	}); err != nil {
+		log.Printf("⚠️ Failed to queue confirmation email for request %d: %v", request.ID, err)
+	}
+}
+
+// errRequestNotInProgress aborts a completion whose request is no longer in progress
+var errRequestNotInProgress = errors.New("service request is not in progress")
+
+// errLineItemsPending aborts a completion while parts still await the customer's decision
+var errLineItemsPending = errors.New("line items pending approval")
+
+// enqueueCompletionEvents records the analytics and notification side effects of a completion
+func enqueueCompletionEvents(tx *gorm.DB, request models.CustomerServiceRequest, worker models.WorkerProfile, workerUserID uint, earnings money.Amount, workHours float64, parts []models.RequestLineItem) error {
+	// Track analytics for worker performance
+	if err := services.EnqueueOutboxEvent(tx, models.OutboxEventJobCompletionAnalytics, request.ID, models.JobCompletionAnalyticsPayload{
+		WorkerID:         worker.ID,
+		ServiceRequestID: request.ID,
+		Earnings:         earnings,
+		WorkHours:        workHours,
+	}); err != nil {
+		return err
+	}
+	
+	// Notify customer about completion
+	if err := services.EnqueueOutboxEvent(tx, models.OutboxEventServiceStatusNotify, request.ID, models.ServiceStatusNotifyPayload{
+		CustomerID:       request.CustomerID,
+		ServiceRequestID: request.ID,
+		Status:           "completed",
+	}); err != nil {
+		return err
+	}
+	
+	// Email the customer a receipt
+	var workerUser models.User
+	if err := tx.Select("id", "full_name").First(&workerUser, workerUserID).Error; err != nil {
+		return err
+	}
+	receipt := map[string]interface{}{
+		"request_id":   request.ID,
+		"title":        request.Title,
+		"worker_name":  workerUser.FullName,
+		"completed_at": request.CompletedAt,
+		"currency":     services.NewRegionService().ForRequest(request).Currency,
+	}
+	if request.Price() != nil || len(parts) > 0 {
+		receipt["amount"] = earnings
+	}
+	if len(parts) > 0 {
+		items := make([]map[string]interface{}, 0, len(parts))
+		for _, part := range parts {
+			items = append(items, map[string]interface{}{
+				"description": part.Description,
+				"quantity":    part.Quantity,
+				"unit_price":  part.UnitPrice,
+				"total":       part.Total(),
+			})
+		}
+		receipt["items"] = items
+		if price := request.Price(); price != nil {
+			receipt["labour"] = *price
+		}
+	}
+	if err := services.EnqueueOutboxEvent(tx, models.OutboxEventEmail, request.ID, models.EmailPayload{
+		UserID:   request.CustomerID,
+		Template: services.EmailTemplateCompletionReceipt,
+		Data:     receipt,
+	}); err != nil {
+		return err
+	}
+	
+	// Ask the customer for feedback after their first completed service
+	var customerCompleted int64
+	if err := tx.Model(&models.ServiceHistory{}).Where("customer_id = ?", request.CustomerID).Count(&customerCompleted).Error; err != nil {
+		return err
+	}
+	if customerCompleted == 1 {
+		if err := services.EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, request.ID, models.PushNotificationPayload{
+			UserID:  request.CustomerID,
+			Message: "notification.feedback_customer",
+			Type:    "feedback_request",
+			Data: map[string]interface{}{
+				"action":             "feedback_request",
+				"role":               "customer",
+				"service_request_id": request.ID,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	
+	// Ask the worker for feedback after their first completed job
+	var workerCompleted int64
+	if err := tx.Model(&models.ServiceHistory{}).Where("worker_id = ?", worker.ID).Count(&workerCompleted).Error; err != nil {
+		return err
+	}
+	if workerCompleted == 1 {
+		if err := services.EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, request.ID, models.PushNotificationPayload{
+			UserID:  workerUserID,
+			Message: "notification.feedback_worker",
+			Type:    "feedback_request",
+			Data: map[string]interface{}{
+				"action":             "feedback_request",
+				"worker_id":          worker.ID,
+				"service_request_id": request.ID,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetScheduledServiceRequests - Get scheduled service requests for workers
 func GetScheduledServiceRequests(c *gin.Context) {
 	userID := c.GetUint("user_id")
@@ -1178,32 +1837,37 @@ func GetScheduledServiceRequests(c *gin.Context) {
 	// Get worker profile
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": "Worker profile not found",
-		})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
-	// Get scheduled requests for this worker's category
+	// Get scheduled requests in the worker's categories and their subcategories
+	categoryIDs, err := services.NewCategoryService().WorkerCategoryIDs(workerProfile)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load worker categories", err))
+		return
+	}
 	var scheduledRequests []models.CustomerServiceRequest
-	query := database.DB.Where("category_id = ? AND status = ? AND scheduled_for IS NOT NULL", 
-		workerProfile.CategoryID, "scheduled").
-		Where("scheduled_for > NOW()"). // Only future scheduled requests
+	query := database.DB.Scopes(services.RequestInCategoriesScope(categoryIDs)).
+		Where("status = ? AND scheduled_for IS NOT NULL", "scheduled").
+		Where("scheduled_for > ?", time.Now().UTC()). // Only future scheduled requests
 		Order("scheduled_for ASC")
 	
 	if err := query.Find(&scheduledRequests).Error; err != nil {
 		log.Printf("❌ Error fetching scheduled requests: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch scheduled requests",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to fetch scheduled requests", nil))
 		return
 	}
 	
 	// Get customer names for each request
+	scheduleService := services.NewWorkerScheduleService()
 	var responseData []gin.H
 	for _, request := range scheduledRequests {
+		// Only show requests the worker could actually claim given their schedule
+		if onShift, err := scheduleService.IsWorkerAvailableAt(workerProfile.ID, *request.ScheduledFor); err == nil && !onShift {
+			continue
+		}
+		
 		var customer models.User
 		if err := database.DB.Where("id = ?", request.CustomerID).First(&customer).Error; err != nil {
 			log.Printf("⚠️ Failed to fetch customer for request %d: %v", request.ID, err)
@@ -1226,6 +1890,7 @@ func GetScheduledServiceRequests(c *gin.Context) {
 			"created_at": request.CreatedAt,
 			"status": request.Status,
 			"scheduled_for": request.ScheduledFor,
+			"scheduled_for_local": request.ScheduledForLocal(),
 		})
 	}
 	
@@ -0,0 +1,225 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/models"
+	"repair-service-server/testdb"
+)
+
+// benchRequests is how many requests each benchmark reads, spread over benchCustomers so the
+// customer join and default address lookup see many rows
+const (
+	benchRequests  = 500
+	benchCustomers = 50
+)
+
+// requestFeeds are the workers the feed benchmarks read as: one free to take new requests and
+// one already busy with benchRequests active jobs
+type requestFeeds struct {
+	router   *gin.Engine
+	freeUser models.User
+	busyUser models.User
+}
+
+// seedRequestFeeds fills a new database with benchRequests broadcast requests in the free
+// worker's category, all within its broadcast radius, and benchRequests active requests assigned
+// to the busy worker
+func seedRequestFeeds(b *testing.B) requestFeeds {
+	b.Helper()
+	db := testdb.Install(b)
+
+	// The handlers log every call, which would be most of what is measured
+	previous := log.Writer()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(previous) })
+
+	category := models.ServiceCategory{Name: "Plomberie bench", IsActive: true}
+	if err := db.Create(&category).Error; err != nil {
+		b.Fatal(err)
+	}
+
+	now := time.Now()
+	customers := make([]models.User, benchCustomers)
+	for i := range customers {
+		customers[i] = models.User{
+			FullName:        fmt.Sprintf("Customer %d", i),
+			PhoneNumber:     fmt.Sprintf("+2223%07d", i),
+			PasswordHash:    "unused",
+			Role:            models.RoleCustomer,
+			IsActive:        true,
+			PhoneVerifiedAt: &now,
+		}
+	}
+	mustCreate(b, db, &customers)
+
+	// Half the customers have a default address, the others fall back to the request location
+	lat, lng := 18.0810, -15.9660
+	var addresses []models.Address
+	for i, customer := range customers {
+		if i%2 == 0 {
+			addresses = append(addresses, models.Address{
+				UserID:         customer.ID,
+				AddressDetails: fmt.Sprintf("Ilot %d, Tevragh Zeina", i),
+				City:           "Nouakchott",
+				Latitude:       lat,
+				Longitude:      lng,
+				IsDefault:      true,
+			})
+		}
+	}
+	mustCreate(b, db, &addresses)
+
+	freeUser := benchWorkerUser(b, db, "+22229000001")
+	busyUser := benchWorkerUser(b, db, "+22229000002")
+	benchWorkerProfile(b, db, freeUser, category, &lat, &lng)
+	busy := benchWorkerProfile(b, db, busyUser, category, nil, nil)
+
+	requests := make([]models.CustomerServiceRequest, 0, 2*benchRequests)
+	for i := 0; i < 2*benchRequests; i++ {
+		requestLat, requestLng := lat+float64(i%20)*0.001, lng-float64(i%20)*0.001
+		request := models.CustomerServiceRequest{
+			CustomerID:      customers[i%benchCustomers].ID,
+			CategoryID:      category.ID,
+			Title:           fmt.Sprintf("Fuite d'eau %d", i),
+			Description:     "Fuite sous l'évier de la cuisine",
+			Priority:        "medium",
+			LocationAddress: "Tevragh Zeina",
+			LocationCity:    "Nouakchott",
+			LocationLat:     &requestLat,
+			LocationLng:     &requestLng,
+			Status:          models.RequestStatusBroadcast,
+		}
+		if i%10 == 0 {
+			request.Priority = "urgent"
+		}
+		if i >= benchRequests {
+			request.Status = models.ActiveRequestStatuses[i%len(models.ActiveRequestStatuses)]
+			request.AssignedWorkerID = &busy.ID
+		}
+		requests = append(requests, request)
+	}
+	mustCreate(b, db, &requests)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authenticated := router.Group("/", func(c *gin.Context) {
+		var userID uint
+		fmt.Sscan(c.GetHeader("X-Bench-User"), &userID)
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	authenticated.GET("/worker/available-requests", getAvailableServiceRequests)
+	authenticated.GET("/worker/active-requests", getWorkerActiveRequests)
+
+	return requestFeeds{router: router, freeUser: freeUser, busyUser: busyUser}
+}
+
+// benchWorkerUser creates a verified worker account
+func benchWorkerUser(b *testing.B, db *gorm.DB, phone string) models.User {
+	b.Helper()
+	now := time.Now()
+	user := models.User{
+		FullName:        "Worker " + phone,
+		PhoneNumber:     phone,
+		PasswordHash:    "unused",
+		Role:            models.RoleWorker,
+		IsActive:        true,
+		PhoneVerifiedAt: &now,
+	}
+	mustCreate(b, db, &user)
+	return user
+}
+
+// benchWorkerProfile creates an available worker in category, located at lat, lng when given
+func benchWorkerProfile(b *testing.B, db *gorm.DB, user models.User, category models.ServiceCategory, lat, lng *float64) models.WorkerProfile {
+	b.Helper()
+	worker := models.WorkerProfile{
+		UserID:            user.ID,
+		CategoryID:        category.ID,
+		PhoneNumber:       user.PhoneNumber,
+		Country:           "Mauritanie",
+		State:             "Nouakchott",
+		City:              "Nouakchott",
+		PostalCode:        "00000",
+		IsAvailable:       true,
+		MaxConcurrentJobs: 3,
+		IsVerified:        true,
+		OnboardingStatus:  models.OnboardingActive,
+	}
+	if lat != nil {
+		now := time.Now()
+		worker.CurrentLat, worker.CurrentLng, worker.LastLocationUpdate = lat, lng, &now
+	}
+	mustCreate(b, db, &worker)
+	return worker
+}
+
+// mustCreate inserts records in batches, failing the benchmark on error
+func mustCreate(b *testing.B, db *gorm.DB, records interface{}) {
+	b.Helper()
+	if err := db.CreateInBatches(records, 100).Error; err != nil {
+		b.Fatal(err)
+	}
+}
+
+// serve answers path as user, failing the benchmark unless it succeeds
+func (f requestFeeds) serve(b *testing.B, user models.User, path string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Bench-User", fmt.Sprint(user.ID))
+	f.router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusOK {
+		b.Fatalf("GET %s: status %d: %s", path, recorder.Code, recorder.Body)
+	}
+	return recorder
+}
+
+// count returns how many requests path lists under key for user
+func (f requestFeeds) count(b *testing.B, user models.User, path, key string) int {
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(f.serve(b, user, path).Body.Bytes(), &body); err != nil {
+		b.Fatal(err)
+	}
+	var listed []json.RawMessage
+	if err := json.Unmarshal(body[key], &listed); err != nil {
+		b.Fatal(err)
+	}
+	return len(listed)
+}
+
+func BenchmarkGetAvailableServiceRequests(b *testing.B) {
+	feeds := seedRequestFeeds(b)
+	if n := feeds.count(b, feeds.freeUser, "/worker/available-requests", "available_requests"); n != benchRequests {
+		b.Fatalf("listed %d available requests, want %d", n, benchRequests)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		feeds.serve(b, feeds.freeUser, "/worker/available-requests")
+	}
+}
+
+func BenchmarkGetWorkerActiveRequests(b *testing.B) {
+	feeds := seedRequestFeeds(b)
+	if n := feeds.count(b, feeds.busyUser, "/worker/active-requests", "active_requests"); n != benchRequests {
+		b.Fatalf("listed %d active requests, want %d", n, benchRequests)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		feeds.serve(b, feeds.busyUser, "/worker/active-requests")
+	}
+}
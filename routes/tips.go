@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// errAlreadyTipped aborts a second tip on the same request
+var errAlreadyTipped = errors.New("service request already tipped")
+
+// tipServiceRequest lets the customer tip the worker once a completed job has been rated. The
+// tip is stored apart from the price and booked to the worker's analytics through the outbox.
+func tipServiceRequest(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req struct {
+		Amount money.Amount `json:"amount" binding:"required,gt=0"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Preload("AssignedWorker").Where("id = ? AND customer_id = ?", c.Param("id"), userID).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	if serviceRequest.Status != models.RequestStatusCompleted || serviceRequest.AssignedWorker == nil {
+		apierror.Abort(c, apierror.Validation("Only completed service requests can be tipped"))
+		return
+	}
+
+	var rated int64
+	database.DB.Model(&models.WorkerRating{}).Where("service_request_id = ? AND customer_id = ?", serviceRequest.ID, userID).Count(&rated)
+	if rated == 0 {
+		apierror.Abort(c, apierror.Validation("Please rate the service before adding a tip"))
+		return
+	}
+
+	settings := services.NewSettingsService()
+	minAmount, maxAmount := settings.Float(services.SettingTipMinAmount), settings.Float(services.SettingTipMaxAmount)
+	if req.Amount < money.FromFloat(minAmount) {
+		validation.Fail(c, "amount", "gte", fmt.Sprintf("%g", minAmount))
+		return
+	}
+	if req.Amount > money.FromFloat(maxAmount) {
+		validation.Fail(c, "amount", "lte", fmt.Sprintf("%g", maxAmount))
+		return
+	}
+
+	worker := *serviceRequest.AssignedWorker
+	tip := models.Tip{
+		ServiceRequestID: serviceRequest.ID,
+		CustomerID:       userID,
+		WorkerID:         worker.ID,
+		Amount:           req.Amount,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.Tip{}).Where("service_request_id = ?", serviceRequest.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errAlreadyTipped
+		}
+		if err := tx.Create(&tip).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ServiceHistory{}).Where("service_request_id = ?", serviceRequest.ID).
+			Update("tip_amount", tip.Amount).Error; err != nil {
+			return err
+		}
+
+		if err := services.EnqueueOutboxEvent(tx, models.OutboxEventTipAnalytics, serviceRequest.ID, models.TipAnalyticsPayload{
+			WorkerID:         worker.ID,
+			ServiceRequestID: serviceRequest.ID,
+			Amount:           tip.Amount,
+		}); err != nil {
+			return err
+		}
+		return services.EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, serviceRequest.ID, models.PushNotificationPayload{
+			UserID:  worker.UserID,
+			Message: "notification.tip_received",
+			Vars: map[string]interface{}{
+				"amount":   tip.Amount,
+				"title":    serviceRequest.Title,
+				"currency": services.NewRegionService().ForRequest(serviceRequest).Currency,
+			},
+			Type:    "tip_received",
+			Data: map[string]interface{}{
+				"service_request_id": serviceRequest.ID,
+				"amount":             tip.Amount,
+			},
+		})
+	})
+	if errors.Is(err, errAlreadyTipped) {
+		apierror.Abort(c, apierror.Conflict("This service has already been tipped"))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to add tip", err))
+		return
+	}
+
+	log.Printf("💸 Customer %d tipped worker %d %s on service request %d", userID, worker.ID, tip.Amount, serviceRequest.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Thank you! Your tip has been added.",
+		"data":    tip,
+	})
+}
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RegisterUploadRoutes registers the routes for resumable uploads straight to Cloudinary
+func RegisterUploadRoutes(router *gin.RouterGroup) {
+	router.POST("/uploads", startUpload)
+	router.POST("/uploads/:id/sign", resignUpload)
+	router.POST("/uploads/:id/complete", completeUpload)
+}
+
+// startUpload opens an upload session and returns the signed parameters the client sends its
+// chunks to Cloudinary with
+func startUpload(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var req models.UploadSessionCreateRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	session, ticket, err := services.NewUploadService().Start(user, req)
+	if err != nil {
+		abortUploadError(c, req.Purpose, err)
+		return
+	}
+	log.Printf("📤 User %d started %s upload session %d", user.ID, req.Purpose, session.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data": gin.H{
+			"session": session,
+			"upload":  ticket,
+		},
+	})
+}
+
+// resignUpload signs the upload again so a client can resume after its signature expired
+func resignUpload(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid upload session ID"))
+		return
+	}
+
+	session, ticket, err := services.NewUploadService().Resign(sessionID, user.ID)
+	if err != nil {
+		abortUploadError(c, "", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"session": session,
+			"upload":  ticket,
+		},
+	})
+}
+
+// completeUpload verifies the uploaded file and attaches it to the portfolio or the request
+func completeUpload(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid upload session ID"))
+		return
+	}
+
+	session, err := services.NewUploadService().Complete(sessionID, user.ID)
+	if err != nil {
+		abortUploadError(c, "", err)
+		return
+	}
+	log.Printf("✅ Upload session %d completed", session.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    session,
+	})
+}
+
+// listRequestAttachments returns the videos attached to a request, for its customer and assigned
+// worker
+func listRequestAttachments(c *gin.Context) {
+	user := c.MustGet("user").(models.User)
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	if serviceRequest.CustomerID != user.ID {
+		workerProfile, ok := currentWorkerProfile(c)
+		if !ok {
+			return
+		}
+		if serviceRequest.AssignedWorkerID == nil || *serviceRequest.AssignedWorkerID != workerProfile.ID {
+			apierror.Abort(c, apierror.NotFound("Service request not found"))
+			return
+		}
+	}
+
+	attachments, err := services.NewUploadService().Attachments(serviceRequest.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch attachments", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    serializers.RequestAttachments(attachments),
+	})
+}
+
+// CloudinaryWebhook completes upload sessions when Cloudinary reports the upload finished
+func CloudinaryWebhook(c *gin.Context) {
+	err := services.NewUploadService().HandleNotification(c.Request)
+	if errors.Is(err, services.ErrInvalidUploadWebhook) {
+		apierror.Abort(c, apierror.Forbidden(err.Error()))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to process upload notification", err))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// abortUploadError maps upload service errors to API errors
+func abortUploadError(c *gin.Context, purpose models.MediaPurpose, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("Upload session not found"))
+	case errors.Is(err, services.ErrMediaTooLarge):
+		message := services.MediaLimitMessage(purpose)
+		apierror.Abort(c, apierror.Validation(message).WithDetails([]validation.FieldError{{Field: "size", Rule: "max", Message: message}}))
+	case errors.Is(err, services.ErrUploadNotAllowed):
+		apierror.Abort(c, apierror.Forbidden(err.Error()))
+	case errors.Is(err, services.ErrUploadLimit):
+		apierror.Abort(c, apierror.Unprocessable(err.Error()))
+	case errors.Is(err, services.ErrUploadSessionClosed), errors.Is(err, services.ErrUploadIncomplete):
+		apierror.Abort(c, apierror.Conflict(err.Error()))
+	case errors.Is(err, services.ErrUploadRejected):
+		apierror.Abort(c, apierror.Unprocessable(err.Error()))
+	default:
+		apierror.Abort(c, apierror.Unavailable("Upload service is unavailable. Please try again.", err))
+	}
+}
@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	ws "repair-service-server/websocket"
+)
+
+// transcribeVoiceMessage stores the transcript of a voice message, filtered like a text message,
+// and tells the room it is ready. It runs after the upload has been answered.
+func transcribeVoiceMessage(transcriber services.Transcriber, message models.ChatMessage, audio []byte, filename, language string) {
+	transcript, err := transcriber.Transcribe(audio, filename, language)
+	if err != nil {
+		log.Printf("⚠️ Failed to transcribe voice message %d with %s: %v", message.ID, transcriber.Name(), err)
+		database.DB.Model(&message).Update("transcript_status", models.TranscriptFailed)
+		return
+	}
+
+	moderation := services.NewContentModerationService()
+	filtered := moderation.Filter(transcript)
+	message.Transcript = filtered.Text
+	message.TranscriptStatus = models.TranscriptCompleted
+	if err := database.DB.Model(&message).Updates(map[string]interface{}{
+		"transcript":        message.Transcript,
+		"transcript_status": message.TranscriptStatus,
+	}).Error; err != nil {
+		log.Printf("❌ Failed to save transcript of voice message %d: %v", message.ID, err)
+		return
+	}
+	moderation.Record(message.SenderID, "chat_message", message.ID, transcript, filtered)
+
+	log.Printf("🎤 Transcribed voice message %d with %s", message.ID, transcriber.Name())
+
+	if chatHub != nil {
+		// Sent to the sender too, whose app shows the transcript under their own message
+		chatHub.SendToChatRoom(message.ChatRoomID, &ws.Message{
+			Type:       "voice_transcribed",
+			ChatRoomID: message.ChatRoomID,
+			SenderID:   message.SenderID,
+			SenderType: message.SenderType,
+			Content:    message.Transcript,
+			Timestamp:  time.Now(),
+			Data: gin.H{
+				"message": serializers.ChatMessage(message),
+			},
+		}, 0)
+	}
+}
@@ -0,0 +1,91 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+func StartWorkTimer(c *gin.Context) {
+	workerProfile, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+	request, err := services.NewWorkTimerService().Start(serviceRequest.ID, workerProfile.ID)
+	respondWorkTimer(c, request, err, "Work timer started")
+}
+
+func PauseWorkTimer(c *gin.Context) {
+	var req struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	_, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+	request, err := services.NewWorkTimerService().Pause(serviceRequest.ID, req.Reason)
+	respondWorkTimer(c, request, err, "Work timer paused")
+}
+
+func ResumeWorkTimer(c *gin.Context) {
+	workerProfile, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+	request, err := services.NewWorkTimerService().Resume(serviceRequest.ID, workerProfile.ID)
+	respondWorkTimer(c, request, err, "Work timer resumed")
+}
+
+func StopWorkTimer(c *gin.Context) {
+	_, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+	request, err := services.NewWorkTimerService().Stop(serviceRequest.ID)
+	respondWorkTimer(c, request, err, "Work timer stopped")
+}
+
+// respondWorkTimer returns the timer after a transition or the reason it was refused
+func respondWorkTimer(c *gin.Context, request *models.CustomerServiceRequest, err error, message string) {
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrTimerNotInProgress):
+		apierror.Abort(c, apierror.Validation("Service request is not in progress"))
+		return
+	case errors.Is(err, services.ErrTimerAlreadyStarted):
+		apierror.Abort(c, apierror.Conflict("Work timer has already been started"))
+		return
+	case errors.Is(err, services.ErrTimerNotRunning):
+		apierror.Abort(c, apierror.Conflict("Work timer is not running"))
+		return
+	case errors.Is(err, services.ErrTimerNotPaused):
+		apierror.Abort(c, apierror.Conflict("Work timer is not paused"))
+		return
+	case errors.Is(err, services.ErrTimerStopped):
+		apierror.Abort(c, apierror.Conflict("Work timer has been stopped"))
+		return
+	default:
+		apierror.Abort(c, apierror.Internal("Failed to update work timer", err))
+		return
+	}
+
+	log.Printf("⏱️ %s on service request %d (%s, %ds worked)", message, request.ID, request.TimerState, request.WorkedSeconds)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data":    serializers.WorkTimer(*request),
+	})
+}
@@ -9,9 +9,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/middleware"
 	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
 )
 
 // RegisterWorkerRoutes registers worker profile routes
@@ -59,26 +63,15 @@ func getAvailableWorkers(c *gin.Context) {
 	}
 
 	var workers []models.WorkerProfile
-	if err := query.Limit(limit).Find(&workers).Error; err != nil {
+	if err := query.Preload("User").Preload("Category").Limit(limit).Find(&workers).Error; err != nil {
 		log.Printf("Error fetching workers: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch workers",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to fetch workers", nil))
 		return
 	}
 
-	// Load user data separately to avoid constraint issues
-	for i := range workers {
-		var user models.User
-		if err := database.DB.First(&user, workers[i].UserID).Error; err == nil {
-			workers[i].User = user
-		}
-	}
-
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"workers": workers,
+		"workers": serializers.WorkersPublic(workers),
 	})
 }
 
@@ -86,27 +79,18 @@ func getWorkerProfile(c *gin.Context) {
 	workerID := c.Param("id")
 	id, err := strconv.ParseUint(workerID, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid worker ID",
-		})
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
 		return
 	}
 
 	var worker models.WorkerProfile
-	if err := database.DB.First(&worker, id).Error; err != nil {
+	if err := database.DB.Preload("Category").Preload("Categories.Category").First(&worker, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"success": false,
-				"message": "Worker not found",
-			})
+			apierror.Abort(c, apierror.NotFound("Worker not found"))
 			return
 		}
 		log.Printf("Error fetching worker profile: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch worker profile",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to fetch worker profile", nil))
 		return
 	}
 
@@ -116,9 +100,16 @@ func getWorkerProfile(c *gin.Context) {
 		worker.User = user
 	}
 
+	// Remaining job capacity for the worker dashboard
+	capacity, err := services.NewWorkerCapacityService().GetCapacity(&worker)
+	if err != nil {
+		log.Printf("⚠️ Failed to compute capacity for worker %d: %v", worker.ID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"worker": worker,
+		"worker": serializers.WorkerPublic(worker),
+		"capacity": capacity,
 	})
 }
 
@@ -128,19 +119,14 @@ func getMyWorkerProfile(c *gin.Context) {
 	var worker models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).
 		Preload("Category"). // Preload category information
+		Preload("Categories.Category").
 		First(&worker).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"success": false,
-				"message": "Worker profile not found",
-			})
+			apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 			return
 		}
 		log.Printf("Error fetching my worker profile: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to fetch worker profile",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to fetch worker profile", nil))
 		return
 	}
 
@@ -156,7 +142,7 @@ func getMyWorkerProfile(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"worker": worker,
+		"worker": serializers.WorkerProfile(worker),
 	})
 }
 
@@ -166,20 +152,12 @@ func createWorkerProfile(c *gin.Context) {
 	// Check if user already has a worker profile
 	var existingWorker models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&existingWorker).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{
-			"success": false,
-			"message": "Worker profile already exists",
-		})
+		apierror.Abort(c, apierror.Conflict("Worker profile already exists"))
 		return
 	}
 
 	var request models.WorkerProfileRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request data",
-			"error":   err.Error(),
-		})
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 
@@ -189,7 +167,7 @@ func createWorkerProfile(c *gin.Context) {
 	worker := models.WorkerProfile{
 		UserID:       userID,
 		CategoryID:   request.CategoryID,
-		PhoneNumber:  request.PhoneNumber,
+		PhoneNumber:  validation.NormalizePhone(request.PhoneNumber),
 		Country:      request.Country,
 		State:        request.State,
 		City:         request.City,
@@ -201,29 +179,26 @@ func createWorkerProfile(c *gin.Context) {
 		ProfilePhoto: request.ProfilePhoto,
 		IDCardPhoto:  request.IDCardPhoto,
 	}
+	services.AdvanceOnboarding(&worker)
 
 	if err := database.DB.Create(&worker).Error; err != nil {
 		log.Printf("❌ Database error creating worker profile: %v", err)
 		log.Printf("❌ Worker data: %+v", worker)
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to create worker profile",
-			"error":   err.Error(),
-		})
+		apierror.Abort(c, apierror.Internal("Failed to create worker profile", err))
 		return
 	}
 
 	log.Printf("✅ Worker profile created successfully - ID: %d, CategoryID: %d", worker.ID, worker.CategoryID)
 
 	// Load the user data and category
-	database.DB.Preload("User").Preload("Category").First(&worker, worker.ID)
+	database.DB.Preload("User").Preload("Category").Preload("Categories.Category").First(&worker, worker.ID)
 
 	log.Printf("✅ Worker profile loaded with category: %+v", worker.Category)
 
 	c.JSON(http.StatusCreated, gin.H{
 		"success": true,
 		"message": "Worker profile created successfully",
-		"worker":  worker,
+		"worker":  serializers.WorkerProfile(worker),
 	})
 }
 
@@ -231,27 +206,19 @@ func updateWorkerProfile(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var request models.WorkerProfileRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request data",
-			"error":   err.Error(),
-		})
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 
 	var worker models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&worker).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"message": "Worker profile not found",
-		})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 
 	// Update fields
 	worker.CategoryID = request.CategoryID
-	worker.PhoneNumber = request.PhoneNumber
+	worker.PhoneNumber = validation.NormalizePhone(request.PhoneNumber)
 	worker.Country = request.Country
 	worker.State = request.State
 	worker.PostalCode = request.PostalCode
@@ -262,22 +229,20 @@ func updateWorkerProfile(c *gin.Context) {
 	worker.HourlyRate = request.HourlyRate
 	worker.ProfilePhoto = request.ProfilePhoto
 	worker.IDCardPhoto = request.IDCardPhoto
+	services.AdvanceOnboarding(&worker)
 
 	if err := database.DB.Save(&worker).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to update worker profile",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to update worker profile", err))
 		return
 	}
 
 	// Load the user data and category
-	database.DB.Preload("User").Preload("Category").First(&worker, worker.ID)
+	database.DB.Preload("User").Preload("Category").Preload("Categories.Category").First(&worker, worker.ID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Worker profile updated successfully",
-		"worker":  worker,
+		"worker":  serializers.WorkerProfile(worker),
 	})
 }
 
@@ -288,19 +253,20 @@ func updateAvailability(c *gin.Context) {
 		IsAvailable bool `json:"is_available" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request data",
-		})
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 
+	if request.IsAvailable {
+		var worker models.WorkerProfile
+		if err := database.DB.Where("user_id = ?", userID).First(&worker).Error; err == nil && worker.IsSuspended(time.Now()) {
+			apierror.Abort(c, apierror.Forbidden("Your account is suspended until "+worker.SuspendedUntil.Format("2006-01-02")))
+			return
+		}
+	}
+
 	if err := database.DB.Model(&models.WorkerProfile{}).Where("user_id = ?", userID).Update("is_available", request.IsAvailable).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to update availability",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to update availability", err))
 		return
 	}
 
@@ -319,11 +285,7 @@ func uploadWorkerPhotos(c *gin.Context) {
 		ProfilePhoto string `json:"profile_photo"`
 	}
 
-	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"message": "Invalid request data",
-		})
+	if !validation.BindJSON(c, &request) {
 		return
 	}
 
@@ -336,10 +298,7 @@ func uploadWorkerPhotos(c *gin.Context) {
 	}
 
 	if err := database.DB.Model(&models.WorkerProfile{}).Where("user_id = ?", userID).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"message": "Failed to update photos",
-		})
+		apierror.Abort(c, apierror.Internal("Failed to update photos", err))
 		return
 	}
 
@@ -360,7 +319,7 @@ func getWorkerLocation(c *gin.Context) {
 	workerIDUint, err := strconv.ParseUint(workerID, 10, 32)
 	if err != nil {
 		log.Printf("❌ Invalid worker ID: %s", workerID)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid worker ID"})
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
 		return
 	}
 
@@ -368,14 +327,14 @@ func getWorkerLocation(c *gin.Context) {
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("id = ?", workerIDUint).First(&workerProfile).Error; err != nil {
 		log.Printf("❌ Worker profile not found: %v", err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker not found"})
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
 		return
 	}
 
 	// Check if worker has location data
 	if workerProfile.CurrentLat == nil || workerProfile.CurrentLng == nil {
 		log.Printf("❌ Worker %d has no location data", workerIDUint)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker location not available"})
+		apierror.Abort(c, apierror.NotFound("Worker location not available"))
 		return
 	}
 
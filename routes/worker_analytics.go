@@ -8,13 +8,29 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/repository"
 	"repair-service-server/services"
+	"repair-service-server/validation"
 )
 
+// WorkerAnalyticsHandler serves the worker trend and leaderboard endpoints from injected repositories
+type WorkerAnalyticsHandler struct {
+	users     repository.UserRepo
+	analytics repository.AnalyticsRepo
+}
+
+// NewWorkerAnalyticsHandler creates a worker analytics handler backed by the given repositories
+func NewWorkerAnalyticsHandler(users repository.UserRepo, analytics repository.AnalyticsRepo) *WorkerAnalyticsHandler {
+	return &WorkerAnalyticsHandler{users: users, analytics: analytics}
+}
+
 // RegisterWorkerAnalyticsRoutes registers all worker analytics routes
-func RegisterWorkerAnalyticsRoutes(router *gin.RouterGroup) {
+func RegisterWorkerAnalyticsRoutes(router *gin.RouterGroup, repos *repository.Repositories) {
+	h := NewWorkerAnalyticsHandler(repos.Users, repos.Analytics)
 	analyticsRoutes := router.Group("/analytics")
 	{
 		// Get comprehensive worker performance summary
@@ -24,13 +40,13 @@ func RegisterWorkerAnalyticsRoutes(router *gin.RouterGroup) {
 		analyticsRoutes.GET("/stats", getWorkerStats)
 		
 		// Get daily performance trends
-		analyticsRoutes.GET("/trends/daily", getWorkerDailyTrends)
+		analyticsRoutes.GET("/trends/daily", h.getWorkerDailyTrends)
 		
 		// Get monthly performance trends
-		analyticsRoutes.GET("/trends/monthly", getWorkerMonthlyTrends)
+		analyticsRoutes.GET("/trends/monthly", h.getWorkerMonthlyTrends)
 		
 		// Get worker leaderboard in their category
-		analyticsRoutes.GET("/leaderboard", getWorkerLeaderboard)
+		analyticsRoutes.GET("/leaderboard", h.getWorkerLeaderboard)
 		
 		// Get earnings breakdown
 		analyticsRoutes.GET("/earnings", getWorkerEarningsBreakdown)
@@ -44,6 +60,12 @@ func RegisterWorkerAnalyticsRoutes(router *gin.RouterGroup) {
 		// Get productivity insights
 		analyticsRoutes.GET("/productivity", getWorkerProductivityInsights)
 		
+		// Get earned badges and progress towards the others
+		analyticsRoutes.GET("/badges", getWorkerBadges)
+		
+		// Get progress on this week's challenges
+		analyticsRoutes.GET("/challenges", getWorkerChallenges)
+		
 		// Backfill historical analytics data
 		analyticsRoutes.POST("/backfill", backfillWorkerAnalytics)
 	}
@@ -56,14 +78,14 @@ func getWorkerPerformanceSummary(c *gin.Context) {
 	// Get worker profile first
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
 	analyticsService := services.NewWorkerAnalyticsService()
 	summary, err := analyticsService.GetWorkerPerformanceSummary(workerProfile.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch performance summary"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch performance summary", err))
 		return
 	}
 	
@@ -83,7 +105,7 @@ func getWorkerStats(c *gin.Context) {
 	// Get worker profile first
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -109,7 +131,7 @@ func getWorkerStats(c *gin.Context) {
 	}
 	
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch statistics"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch statistics", err))
 		return
 	}
 	
@@ -123,7 +145,7 @@ func getWorkerStats(c *gin.Context) {
 }
 
 // getWorkerDailyTrends provides daily performance trends
-func getWorkerDailyTrends(c *gin.Context) {
+func (h *WorkerAnalyticsHandler) getWorkerDailyTrends(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	daysStr := c.DefaultQuery("days", "30")
 	
@@ -133,16 +155,15 @@ func getWorkerDailyTrends(c *gin.Context) {
 	}
 	
 	// Get worker profile first
-	var workerProfile models.WorkerProfile
-	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+	workerProfile, err := h.users.FindWorkerByUserID(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
-	analyticsService := services.NewWorkerAnalyticsService()
-	trends, err := analyticsService.GetWorkerTrends(workerProfile.ID, days)
+	trends, err := h.analytics.DailyStats(workerProfile.ID, time.Now().AddDate(0, 0, -days))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch daily trends"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch daily trends", err))
 		return
 	}
 	
@@ -153,8 +174,10 @@ func getWorkerDailyTrends(c *gin.Context) {
 	})
 }
 
-// getWorkerMonthlyTrends provides monthly performance trends
-func getWorkerMonthlyTrends(c *gin.Context) {
+// getWorkerMonthlyTrends provides monthly performance trends with month-over-month changes, one
+// entry per month even when the worker had no activity
+func (h *WorkerAnalyticsHandler) getWorkerMonthlyTrends(c *gin.Context) {
+	userID := c.GetUint("user_id")
 	monthsStr := c.DefaultQuery("months", "12")
 	
 	months, err := strconv.Atoi(monthsStr)
@@ -162,49 +185,98 @@ func getWorkerMonthlyTrends(c *gin.Context) {
 		months = 12
 	}
 	
-	// This would need to be implemented in the analytics service
-	// For now, we'll return a placeholder
+	// Get worker profile first
+	workerProfile, err := h.users.FindWorkerByUserID(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+	
+	// Include the current month, so go back months-1 from its first day. The month before is
+	// loaded too, as the baseline of the first month's change.
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -(months - 1), 0)
+	stats, err := h.analytics.MonthlyStats(workerProfile.ID, since.AddDate(0, -1, 0))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch monthly trends", err))
+		return
+	}
+	trends, series := services.BuildMonthlyTrends(stats, since, months)
+	
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"months":  months,
-		"message": "Monthly trends endpoint - implementation in progress",
+		"trends":  trends,
+		"series":  series,
 	})
 }
 
-// getWorkerLeaderboard provides worker ranking in their category
-func getWorkerLeaderboard(c *gin.Context) {
+// getWorkerLeaderboard ranks workers in the current worker's category by a metric over a period,
+// optionally within a city or zone, along with the worker's own rank
+func (h *WorkerAnalyticsHandler) getWorkerLeaderboard(c *gin.Context) {
 	userID := c.GetUint("user_id")
-	limitStr := c.DefaultQuery("limit", "10")
 	
-	limit, err := strconv.Atoi(limitStr)
+	var req struct {
+		Period string `form:"period" binding:"omitempty,oneof=all week month"`
+		Metric string `form:"metric" binding:"omitempty,oneof=earnings jobs rating response_time"`
+		City   string `form:"city" binding:"max=100"`
+		ZoneID *uint  `form:"zone_id"`
+		Limit  string `form:"limit"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	
+	limit, err := strconv.Atoi(req.Limit)
 	if err != nil || limit <= 0 || limit > 50 {
 		limit = 10
 	}
 	
 	// Get worker's category first
-	var categoryID uint
-	err = database.DB.Model(&models.WorkerProfile{}).
-		Where("user_id = ?", userID).
-		Select("category_id").
-		Scan(&categoryID).Error
-	
+	workerProfile, err := h.users.FindWorkerByUserID(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get worker category"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
-	analyticsService := services.NewWorkerAnalyticsService()
-	leaderboard, err := analyticsService.GetWorkerLeaderboard(categoryID, limit)
+	query := repository.LeaderboardQuery{
+		CategoryID: workerProfile.CategoryID,
+		Period:     req.Period,
+		Metric:     req.Metric,
+		City:       req.City,
+		ZoneID:     req.ZoneID,
+		Limit:      limit,
+	}
+	if query.Period == "" {
+		query.Period = repository.LeaderboardAllTime
+	}
+	if query.Metric == "" {
+		query.Metric = repository.LeaderboardEarnings
+	}
+	now := time.Now()
+	switch query.Period {
+	case repository.LeaderboardWeek:
+		query.Since = services.WeekStart(now)
+	case repository.LeaderboardMonth:
+		query.Since = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+	
+	leaderboard, myRank, err := h.analytics.Leaderboard(query, workerProfile.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch leaderboard"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch leaderboard", err))
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{
 		"success":     true,
-		"category_id": categoryID,
+		"category_id": query.CategoryID,
+		"period":      query.Period,
+		"metric":      query.Metric,
+		"city":        query.City,
+		"zone_id":     query.ZoneID,
 		"limit":       limit,
 		"leaderboard": leaderboard,
+		"my_rank":     myRank,
 	})
 }
 
@@ -216,19 +288,20 @@ func getWorkerEarningsBreakdown(c *gin.Context) {
 	// Get worker profile first
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
 	// Get earnings data from service history
 	var earnings []struct {
-		Date   time.Time `json:"date"`
-		Amount float64   `json:"amount"`
-		Jobs   int       `json:"jobs"`
+		Date   time.Time    `json:"date"`
+		Amount money.Amount `json:"amount"`
+		Tips   money.Amount `json:"tips"`
+		Jobs   int          `json:"jobs"`
 	}
 	
 	query := database.DB.Table("service_histories").
-		Select("DATE(completed_at) as date, SUM(final_price) as amount, COUNT(*) as jobs").
+		Select("DATE(completed_at) as date, SUM(final_price) as amount, COALESCE(SUM(tip_amount), 0) as tips, COUNT(*) as jobs").
 		Where("worker_id = ?", workerProfile.ID).
 		Group("DATE(completed_at)").
 		Order("date DESC")
@@ -246,15 +319,16 @@ func getWorkerEarningsBreakdown(c *gin.Context) {
 	
 	err := query.Find(&earnings).Error
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch earnings data"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch earnings data", err))
 		return
 	}
 	
 	// Calculate totals
-	var totalEarnings float64
+	var totalEarnings, totalTips money.Amount
 	var totalJobs int
 	for _, e := range earnings {
 		totalEarnings += e.Amount
+		totalTips += e.Tips
 		totalJobs += e.Jobs
 	}
 	
@@ -263,15 +337,15 @@ func getWorkerEarningsBreakdown(c *gin.Context) {
 		"data": gin.H{
 			"period":         period,
 			"total_earnings": totalEarnings,
+			"total_tips":     totalTips, // Paid on top of earnings, by job completion date
 			"total_jobs":     totalJobs,
-			"average_per_job": func() float64 {
-				if totalJobs > 0 {
-					return totalEarnings / float64(totalJobs)
-				}
-				return 0
-			}(),
+			"average_per_job": totalEarnings.Div(int64(totalJobs)),
 			"breakdown": earnings,
 		},
+		"formatted": formatAmounts(c, services.NewRegionService().ForCity(workerProfile.City).Currency, map[string]money.Amount{
+			"total_earnings": totalEarnings,
+			"total_tips":     totalTips,
+		}),
 	})
 }
 
@@ -282,7 +356,7 @@ func getWorkerJobMetrics(c *gin.Context) {
 	// Get worker profile first
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -299,8 +373,9 @@ func getWorkerJobMetrics(c *gin.Context) {
 	}
 	
 	// Get counts from different sources
+	categoryIDs := workerAnalyticsCategories(workerProfile)
 	database.DB.Model(&models.CustomerServiceRequest{}).
-		Where("category_id = ?", workerProfile.CategoryID).
+		Where("category_id IN ?", categoryIDs).
 		Count(&metrics.TotalReceived)
 	
 	database.DB.Model(&models.CustomerServiceRequest{}).
@@ -353,7 +428,7 @@ func getWorkerSatisfactionMetrics(c *gin.Context) {
 	// Get worker profile first
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -415,7 +490,7 @@ func getWorkerProductivityInsights(c *gin.Context) {
 	// Get worker profile first
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 	
@@ -466,19 +541,20 @@ func getWorkerProductivityInsights(c *gin.Context) {
 	}
 	
 	// Calculate efficiency score (combination of response rate, completion rate, and rating)
+	categoryIDs := workerAnalyticsCategories(workerProfile)
 	var responseRate, completionRate, avgRating float64
 	database.DB.Raw(`
 		SELECT 
 			(COUNT(CASE WHEN assigned_worker_id IS NOT NULL THEN 1 END) * 100.0 / COUNT(*)) as response_rate
 		FROM customer_service_requests 
-		WHERE category_id = ?
-	`, workerProfile.CategoryID).Scan(&responseRate)
+		WHERE category_id IN ?
+	`, categoryIDs).Scan(&responseRate)
 	database.DB.Raw(`
 		SELECT 
 			(COUNT(CASE WHEN status = 'completed' THEN 1 END) * 100.0 / COUNT(CASE WHEN assigned_worker_id IS NOT NULL THEN 1 END)) as completion_rate
 		FROM customer_service_requests 
-		WHERE category_id = ?
-	`, workerProfile.CategoryID).Scan(&completionRate)
+		WHERE category_id IN ?
+	`, categoryIDs).Scan(&completionRate)
 	
 	database.DB.Raw(`
 		SELECT COALESCE(AVG(stars), 0) as avg_rating
@@ -511,13 +587,24 @@ func getWorkerProductivityInsights(c *gin.Context) {
 }
 
 // backfillWorkerAnalytics populates analytics tables with historical data
+// workerAnalyticsCategories returns the categories a worker receives requests in, falling back to
+// their main category if they cannot be loaded
+func workerAnalyticsCategories(workerProfile models.WorkerProfile) []uint {
+	categoryIDs, err := services.NewCategoryService().WorkerCategoryIDs(workerProfile)
+	if err != nil {
+		fmt.Printf("⚠️ Failed to load categories for worker %d: %v\n", workerProfile.ID, err)
+		return []uint{workerProfile.CategoryID}
+	}
+	return categoryIDs
+}
+
 func backfillWorkerAnalytics(c *gin.Context) {
 	userID := c.GetUint("user_id")
 	
 	// Get worker profile first
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Worker profile not found"})
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
 		return
 	}
 
@@ -549,7 +636,7 @@ func backfillWorkerAnalytics(c *gin.Context) {
 	// Get all completed service histories for this worker
 	var serviceHistories []models.ServiceHistory
 	if err := database.DB.Where("worker_id = ?", workerProfile.ID).Find(&serviceHistories).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch service histories"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch service histories", err))
 		return
 	}
 
@@ -564,19 +651,14 @@ func backfillWorkerAnalytics(c *gin.Context) {
 		var workHours float64
 		if service.ActualDuration != nil {
 			workHours = float64(*service.ActualDuration) / 60.0 // Convert minutes to hours
-		} else if service.EstimatedDuration != "" {
-			// Parse estimated duration string to float
-			if duration, err := strconv.ParseFloat(service.EstimatedDuration, 64); err == nil {
-				workHours = duration / 60.0
-			} else {
-				workHours = 1.0 // Default to 1 hour
-			}
+		} else if service.EstimatedDuration > 0 {
+			workHours = service.EstimatedDuration.Hours()
 		} else {
 			workHours = 1.0 // Default to 1 hour
 		}
 
 		// Get earnings from final price
-		earnings := 0.0
+		var earnings money.Amount
 		if service.FinalPrice != nil {
 			earnings = *service.FinalPrice
 		}
@@ -0,0 +1,172 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RegisterWorkerCategoryRoutes registers the routes workers use to manage the categories they serve
+func RegisterWorkerCategoryRoutes(router *gin.RouterGroup) {
+	categories := router.Group("/worker/categories")
+	{
+		categories.GET("", getWorkerCategories)
+		categories.POST("", addWorkerCategory)
+		categories.DELETE("/:categoryId", removeWorkerCategory)
+	}
+}
+
+// getWorkerCategories returns the current worker's main category and extra categories
+func getWorkerCategories(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	categories, err := services.NewCategoryService().ListWorkerCategories(worker.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch worker categories", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"main_category": serializers.Category(worker.Category),
+			"categories":    serializers.WorkerCategories(categories, false),
+		},
+	})
+}
+
+// addWorkerCategory adds a category for the current worker, pending admin verification
+func addWorkerCategory(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CategoryID uint `json:"category_id" binding:"required"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	workerCategory, err := services.NewCategoryService().AddWorkerCategory(worker, req.CategoryID)
+	switch {
+	case errors.Is(err, services.ErrUnknownCategory):
+		apierror.Abort(c, apierror.NotFound("Category not found"))
+		return
+	case errors.Is(err, services.ErrWorkerCategoryExists):
+		apierror.Abort(c, apierror.Conflict("You already serve this category"))
+		return
+	case errors.Is(err, services.ErrTooManyWorkerCategories):
+		apierror.Abort(c, apierror.Validation("You can serve at most "+strconv.Itoa(services.MaxWorkerCategories)+" extra categories"))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to add category", err))
+		return
+	}
+
+	log.Printf("🧰 Worker %d added category %d, pending verification", worker.ID, req.CategoryID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Category added; you will receive its requests once it is verified",
+		"data":    serializers.WorkerCategories([]models.WorkerServiceCategory{*workerCategory}, false)[0],
+	})
+}
+
+// removeWorkerCategory stops the current worker serving one of their extra categories
+func removeWorkerCategory(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	categoryID, err := strconv.ParseUint(c.Param("categoryId"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid category ID"))
+		return
+	}
+
+	if err := services.NewCategoryService().RemoveWorkerCategory(worker.ID, uint(categoryID)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.NotFound("You do not serve this category"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to remove category", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Category removed",
+	})
+}
+
+// VerifyWorkerCategory approves or revokes one of a worker's extra categories (admin only)
+func VerifyWorkerCategory(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+	workerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
+		return
+	}
+	categoryID, err := strconv.ParseUint(c.Param("categoryId"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid category ID"))
+		return
+	}
+
+	var req struct {
+		IsVerified *bool `json:"is_verified" binding:"required"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var before models.WorkerServiceCategory
+	if err := database.DB.Where("worker_id = ? AND category_id = ?", workerID, categoryID).First(&before).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker does not serve this category"))
+		return
+	}
+
+	workerCategory, err := services.NewCategoryService().VerifyWorkerCategory(uint(workerID), uint(categoryID), adminID, *req.IsVerified)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to update category verification", err))
+		return
+	}
+	middleware.RecordAuditChange(c, "worker_categories", workerCategory.ID, before, *workerCategory)
+
+	log.Printf("✅ Worker %d category %d verification set to %v by admin %d", workerID, categoryID, *req.IsVerified, adminID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Category verification updated",
+		"data":    serializers.WorkerCategories([]models.WorkerServiceCategory{*workerCategory}, false)[0],
+	})
+}
+
+// currentWorkerProfile loads the signed-in user's worker profile with its main category; it aborts
+// and returns false when there is none
+func currentWorkerProfile(c *gin.Context) (models.WorkerProfile, bool) {
+	var worker models.WorkerProfile
+	if err := database.DB.Preload("Category").Where("user_id = ?", c.GetUint("user_id")).First(&worker).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return worker, false
+	}
+	return worker, true
+}
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/services"
+)
+
+// GetDemandHeatmap returns request density per geohash cell and hour of day for the worker's category
+func GetDemandHeatmap(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+
+	var hour *int
+	if hourStr := c.Query("hour"); hourStr != "" {
+		h, err := strconv.Atoi(hourStr)
+		if err != nil || h < 0 || h > 23 {
+			apierror.Abort(c, apierror.Validation("hour must be between 0 and 23"))
+			return
+		}
+		hour = &h
+	}
+
+	cells, err := services.NewDemandService().GetHeatmap(workerProfile.CategoryID, hour)
+	if err != nil {
+		log.Printf("❌ Failed to fetch demand heatmap for category %d: %v", workerProfile.CategoryID, err)
+		apierror.Abort(c, apierror.Internal("Failed to fetch demand heatmap", nil))
+		return
+	}
+
+	var heatmap []gin.H
+	for _, cell := range cells {
+		heatmap = append(heatmap, gin.H{
+			"geohash":       cell.Geohash,
+			"hour_of_day":   cell.HourOfDay,
+			"request_count": cell.RequestCount,
+			"avg_per_week":  float64(cell.RequestCount) / float64(cell.WeeksCovered),
+			"center": gin.H{
+				"lat": cell.CenterLat,
+				"lng": cell.CenterLng,
+			},
+		})
+	}
+
+	response := gin.H{
+		"success":     true,
+		"category_id": workerProfile.CategoryID,
+		"weeks":       services.HeatmapWeeks(),
+		"cells":       heatmap,
+		"total_cells": len(heatmap),
+	}
+	if len(cells) > 0 {
+		response["computed_at"] = cells[0].ComputedAt
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,176 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/services"
+	"repair-service-server/utils"
+	"repair-service-server/validation"
+)
+
+// RegisterWorkerEarningsRoutes registers the routes workers use to download their earnings
+// statements and yearly summaries
+func RegisterWorkerEarningsRoutes(router *gin.RouterGroup) {
+	router.GET("/worker/earnings/statement", getEarningsStatement)
+	router.GET("/worker/earnings/yearly", getEarningsYearSummary)
+}
+
+// getEarningsStatement returns the current worker's statement for a month (YYYY-MM, the current
+// month by default) as JSON, or as a CSV or PDF download
+func getEarningsStatement(c *gin.Context) {
+	var req struct {
+		Month  string `form:"month"`
+		Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	month := time.Now()
+	if req.Month != "" {
+		parsed, err := time.ParseInLocation("2006-01", req.Month, time.Local)
+		if err != nil {
+			validation.Fail(c, "month", "default", "")
+			return
+		}
+		month = parsed
+	}
+
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	statement, err := services.NewEarningsStatementService().Statement(worker, month)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to build earnings statement", err))
+		return
+	}
+
+	switch req.Format {
+	case "csv":
+		w := startCSV(c, "earnings-statement-"+statement.Month, []string{
+			"completed_at", "service_request_id", "title", "category", "gross", "parts",
+			"commission_percent", "commission", "refunded", "tips", "net", "payment_status",
+		})
+		for _, line := range statement.Lines {
+			w.Write([]string{
+				csvTime(&line.CompletedAt), csvUint(line.ServiceRequestID), line.Title, line.CategoryName,
+				line.Gross.String(), line.Parts.String(), csvFloat(line.CommissionPercent), line.Commission.String(),
+				line.Refunded.String(), line.Tips.String(), line.Net.String(), line.PaymentStatus,
+			})
+		}
+		t := statement.Totals
+		w.Write([]string{
+			"total", strconv.Itoa(t.Jobs), "", "", t.Gross.String(), t.Parts.String(), "", t.Commission.String(),
+			t.Refunded.String(), t.Tips.String(), t.Net.String(), "",
+		})
+		w.Flush()
+	case "pdf":
+		pdf := utils.NewTextPDF()
+		pdf.Heading("Earnings statement " + statement.Month)
+		pdf.Text(fmt.Sprintf("%s (worker #%d)", statement.WorkerName, statement.WorkerID))
+		pdf.Text("Generated " + statement.GeneratedAt.Format("2006-01-02 15:04") + ", amounts in " + statement.Currency)
+		pdf.Space()
+		pdf.Row(fmt.Sprintf("%-10s %-8s %-26s %10s %10s %10s %8s %10s", "Date", "Request", "Service", "Gross", "Commission", "Refunded", "Tips", "Net"))
+		for _, line := range statement.Lines {
+			pdf.Row(fmt.Sprintf("%-10s %-8d %-26.26s %10s %10s %10s %8s %10s",
+				line.CompletedAt.Format("2006-01-02"), line.ServiceRequestID, line.Title,
+				line.Gross, line.Commission, line.Refunded, line.Tips, line.Net))
+		}
+		pdf.Space()
+		writeEarningsTotalsPDF(pdf, statement.Totals)
+		sendPDF(c, "earnings-statement-"+statement.Month, pdf)
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"success":   true,
+			"data":      statement,
+			"formatted": formatTotals(c, statement.Currency, statement.Totals),
+		})
+	}
+}
+
+// getEarningsYearSummary returns the current worker's monthly totals for a year, the current one by
+// default, as JSON, or as a CSV or PDF download for their tax return
+func getEarningsYearSummary(c *gin.Context) {
+	var req struct {
+		Year   int    `form:"year" binding:"omitempty,min=2000,max=2100"`
+		Format string `form:"format" binding:"omitempty,oneof=json csv pdf"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	if req.Year == 0 {
+		req.Year = time.Now().Year()
+	}
+
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	summary, err := services.NewEarningsStatementService().YearSummary(worker, req.Year)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to build earnings summary", err))
+		return
+	}
+	name := "earnings-summary-" + strconv.Itoa(summary.Year)
+
+	switch req.Format {
+	case "csv":
+		w := startCSV(c, name, []string{"month", "jobs", "gross", "parts", "commission", "refunded", "tips", "net"})
+		for _, m := range summary.Months {
+			w.Write([]string{
+				m.Month, strconv.Itoa(m.Jobs), m.Gross.String(), m.Parts.String(), m.Commission.String(),
+				m.Refunded.String(), m.Tips.String(), m.Net.String(),
+			})
+		}
+		t := summary.Totals
+		w.Write([]string{
+			"total", strconv.Itoa(t.Jobs), t.Gross.String(), t.Parts.String(), t.Commission.String(),
+			t.Refunded.String(), t.Tips.String(), t.Net.String(),
+		})
+		w.Flush()
+	case "pdf":
+		pdf := utils.NewTextPDF()
+		pdf.Heading(fmt.Sprintf("Earnings summary %d", summary.Year))
+		pdf.Text(fmt.Sprintf("%s (worker #%d)", summary.WorkerName, summary.WorkerID))
+		pdf.Text("Generated " + summary.GeneratedAt.Format("2006-01-02 15:04") + ", amounts in " + summary.Currency)
+		pdf.Space()
+		pdf.Row(fmt.Sprintf("%-8s %5s %12s %12s %12s %10s %12s", "Month", "Jobs", "Gross", "Commission", "Refunded", "Tips", "Net"))
+		for _, m := range summary.Months {
+			pdf.Row(fmt.Sprintf("%-8s %5d %12s %12s %12s %10s %12s", m.Month, m.Jobs, m.Gross, m.Commission, m.Refunded, m.Tips, m.Net))
+		}
+		pdf.Space()
+		writeEarningsTotalsPDF(pdf, summary.Totals)
+		sendPDF(c, name, pdf)
+	default:
+		c.JSON(http.StatusOK, gin.H{
+			"success":   true,
+			"data":      summary,
+			"formatted": formatTotals(c, summary.Currency, summary.Totals),
+		})
+	}
+}
+
+// writeEarningsTotalsPDF adds the totals block of a statement or summary
+func writeEarningsTotalsPDF(pdf *utils.TextPDF, t services.EarningsTotals) {
+	pdf.Subheading("Totals")
+	pdf.Text(fmt.Sprintf("Jobs: %d", t.Jobs))
+	pdf.Text(fmt.Sprintf("Gross: %s (of which parts %s)", t.Gross, t.Parts))
+	pdf.Text(fmt.Sprintf("Commission: %s", t.Commission))
+	pdf.Text(fmt.Sprintf("Refunded: %s", t.Refunded))
+	pdf.Text(fmt.Sprintf("Tips: %s", t.Tips))
+	pdf.Subheading(fmt.Sprintf("Net: %s", t.Net))
+}
+
+// sendPDF sends the document as a download named after name
+func sendPDF(c *gin.Context, name string, pdf *utils.TextPDF) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".pdf"))
+	c.Data(http.StatusOK, "application/pdf", pdf.Bytes())
+}
@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/services"
+)
+
+// getWorkerBadges returns every badge with whether the current worker earned it and their progress
+func getWorkerBadges(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	badges, err := services.NewWorkerAnalyticsService().BadgeProgress(worker.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch badges", err))
+		return
+	}
+
+	earned := 0
+	for _, badge := range badges {
+		if badge.Earned {
+			earned++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"earned": earned,
+			"badges": badges,
+		},
+	})
+}
+
+// getWorkerChallenges returns the current worker's progress on this week's challenges
+func getWorkerChallenges(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	challenges, err := services.NewWorkerAnalyticsService().WeeklyChallenges(worker.ID, time.Now())
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch weekly challenges", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    challenges,
+	})
+}
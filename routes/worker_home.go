@@ -0,0 +1,213 @@
+package routes
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/services"
+)
+
+// homeNearbyLimit is how many available requests the home feed previews, nearest first
+const homeNearbyLimit = 5
+
+// Why the home feed shows no nearby requests
+const (
+	homeNotAvailable = "not_available"
+	homeSuspended    = "suspended"
+	homeOffShift     = "off_shift"
+	homeAtCapacity   = "at_capacity"
+)
+
+// homeLoader runs the home feed's queries concurrently and keeps the first error
+type homeLoader struct {
+	wg  sync.WaitGroup
+	mu  sync.Mutex
+	err error
+}
+
+func (l *homeLoader) run(name string, load func() error) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		if err := load(); err != nil {
+			l.mu.Lock()
+			if l.err == nil {
+				l.err = fmt.Errorf("%s: %w", name, err)
+			}
+			l.mu.Unlock()
+		}
+	}()
+}
+
+func (l *homeLoader) wait() error {
+	l.wg.Wait()
+	return l.err
+}
+
+// GetWorkerHome returns the worker app's dashboard in one call: job counts, capacity, the next
+// scheduled job, the nearest available requests, today's earnings and unread notifications
+func GetWorkerHome(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+
+	categoryIDs, err := services.NewCategoryService().WorkerCategoryIDs(workerProfile)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load worker categories", err))
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	suspended := workerProfile.IsSuspended(now)
+
+	var (
+		activeRequests    []models.CustomerServiceRequest
+		nextScheduled     []models.CustomerServiceRequest
+		openScheduled     int64
+		availableRequests []models.AvailableServiceRequestResponse
+		onShift           = true
+		todayStats        models.WorkerDailyStats
+		unread            services.UnreadNotificationCounts
+	)
+
+	loader := &homeLoader{}
+	loader.run("active requests", func() error {
+		var err error
+		activeRequests, err = workerActiveRequests(workerProfile.ID)
+		return err
+	})
+	loader.run("next scheduled job", func() error {
+		return database.DB.Joins("Customer").
+			Where("customer_service_requests.assigned_worker_id = ? AND customer_service_requests.status IN ? AND customer_service_requests.scheduled_for > ?",
+				workerProfile.ID, models.ActiveRequestStatuses, now.UTC()).
+			Order("customer_service_requests.scheduled_for ASC").
+			Limit(1).
+			Find(&nextScheduled).Error
+	})
+	loader.run("scheduled requests", func() error {
+		return database.DB.Model(&models.CustomerServiceRequest{}).
+			Scopes(services.RequestInCategoriesScope(categoryIDs)).
+			Where("status = ? AND scheduled_for > ?", models.RequestStatusScheduled, now.UTC()).
+			Count(&openScheduled).Error
+	})
+	// Only looked up when the worker could be shown requests at all
+	if workerProfile.IsAvailable && !suspended {
+		loader.run("available requests", func() error {
+			var err error
+			availableRequests, err = availableRequestsFor(workerProfile, categoryIDs)
+			return err
+		})
+		loader.run("schedule", func() error {
+			var err error
+			onShift, err = services.NewWorkerScheduleService().IsWorkerAvailableAt(workerProfile.ID, now)
+			return err
+		})
+	}
+	loader.run("today's stats", func() error {
+		return database.DB.Where("worker_id = ? AND date = ?", workerProfile.ID, today).Limit(1).Find(&todayStats).Error
+	})
+	loader.run("notifications", func() error {
+		var err error
+		unread, err = services.NewNotificationInboxService().UnreadCounts(userID)
+		return err
+	})
+	if err := loader.wait(); err != nil {
+		log.Printf("❌ Failed to load home feed for worker %d: %v", workerProfile.ID, err)
+		apierror.Abort(c, apierror.Internal("Failed to load home feed", nil))
+		return
+	}
+
+	capacity := services.CalculateWorkerCapacity(workerProfile.MaxConcurrentJobs, len(activeRequests))
+
+	// Same checks as GET /worker/available-requests, reported instead of failing the feed
+	var unavailableReason *string
+	for _, check := range []struct {
+		failed bool
+		reason string
+	}{
+		{!workerProfile.IsAvailable, homeNotAvailable},
+		{suspended, homeSuspended},
+		{!onShift, homeOffShift},
+		{!capacity.HasCapacity, homeAtCapacity},
+	} {
+		if check.failed {
+			reason := check.reason
+			unavailableReason = &reason
+			availableRequests = nil
+			break
+		}
+	}
+
+	// Nearest first; without a recent location there are no distances and the order is kept
+	sort.SliceStable(availableRequests, func(i, j int) bool {
+		a, b := availableRequests[i].Distance, availableRequests[j].Distance
+		return a != nil && (b == nil || *a < *b)
+	})
+	nearby := availableRequests
+	if len(nearby) > homeNearbyLimit {
+		nearby = nearby[:homeNearbyLimit]
+	}
+	if nearby == nil {
+		nearby = []models.AvailableServiceRequestResponse{}
+	}
+
+	active := make([]models.ActiveServiceRequestResponse, 0, len(activeRequests))
+	for _, request := range activeRequests {
+		active = append(active, activeRequestData(request))
+	}
+
+	var next gin.H
+	if len(nextScheduled) > 0 {
+		request := nextScheduled[0]
+		next = gin.H{
+			"id":                  request.ID,
+			"title":               request.Title,
+			"location_address":    request.LocationAddress,
+			"location_city":       request.LocationCity,
+			"location_lat":        request.LocationLat,
+			"location_lng":        request.LocationLng,
+			"status":              request.Status,
+			"customer_name":       request.Customer.FullName,
+			"scheduled_for":       request.ScheduledFor,
+			"scheduled_for_local": request.ScheduledForLocal(),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"counts": gin.H{
+			"available":            len(availableRequests),
+			"active":               len(activeRequests),
+			"open_scheduled":       openScheduled,
+			"unread_notifications": unread.Total,
+		},
+		"is_available":       workerProfile.IsAvailable,
+		"suspended_until":    workerProfile.SuspendedUntil,
+		"capacity":           capacity,
+		"unavailable_reason": unavailableReason,
+		"active_requests":    active,
+		"next_scheduled_job": next,
+		"nearby_requests":    nearby,
+		"today": gin.H{
+			"jobs_completed": todayStats.JobsCompleted,
+			"earnings":       todayStats.Earnings,
+			"tips":           todayStats.Tips,
+			"work_hours":     todayStats.WorkHours,
+		},
+		"unread_notifications": unread,
+	})
+}
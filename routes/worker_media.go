@@ -1,39 +1,20 @@
 package routes
 
 import (
-	"context"
-	"fmt"
 	"log"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/gin-gonic/gin"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
+	"repair-service-server/services"
 )
 
-// validateImageFile validates mimetype and size (<= 5MB)
-func validateImageFile(h *multipart.FileHeader) bool {
-    if h == nil || h.Size <= 0 || h.Size > 5*1024*1024 {
-        return false
-    }
-    ext := strings.ToLower(filepath.Ext(h.Filename))
-    switch ext {
-    case ".jpg", ".jpeg", ".png", ".webp":
-        return true
-    default:
-        return false
-    }
-}
-
 // RegisterWorkerMediaRoutes adds media upload endpoints under protected group
 func RegisterWorkerMediaRoutes(rg *gin.RouterGroup) {
     rg.POST("/workers/profile/photos", func(c *gin.Context) {
@@ -41,7 +22,7 @@ func RegisterWorkerMediaRoutes(rg *gin.RouterGroup) {
 
         // Multipart form
         if err := c.Request.ParseMultipartForm(10 << 20); err != nil { // 10MB
-            c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid form data"})
+            apierror.Abort(c, apierror.Validation("Invalid form data"))
             return
         }
 
@@ -63,121 +44,73 @@ func RegisterWorkerMediaRoutes(rg *gin.RouterGroup) {
         }
 
         if profileHeader == nil && idHeader == nil && idBackHeader == nil {
-            c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "No files provided"})
-            return
-        }
-
-        if profileHeader != nil && !validateImageFile(profileHeader) {
-            c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid profile photo"})
-            return
-        }
-        if idHeader != nil && !validateImageFile(idHeader) {
-            c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid ID card photo"})
-            return
-        }
-        if idBackHeader != nil && !validateImageFile(idBackHeader) {
-            c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid ID card back photo"})
+            apierror.Abort(c, apierror.Validation("No files provided"))
             return
         }
 
         // Ensure worker profile exists
         var wp models.WorkerProfile
         if err := database.DB.Where("user_id = ?", userID).First(&wp).Error; err != nil {
-            c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Worker profile not found"})
+            apierror.Abort(c, apierror.NotFound("Worker profile not found"))
             return
         }
 
-        // Get Cloudinary configuration from environment
-        cloudName := os.Getenv("CLOUDINARY_CLOUD_NAME")
-        apiKey := os.Getenv("CLOUDINARY_API_KEY")
-        apiSecret := os.Getenv("CLOUDINARY_API_SECRET")
-        
-        if cloudName == "" || apiKey == "" || apiSecret == "" {
-            log.Printf("❌ Cloudinary environment variables not set: cloudName=%s, apiKey=%s, apiSecret=%s", cloudName, apiKey, apiSecret)
-            c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Cloudinary not configured"})
-            return
-        }
-        
-        // Construct Cloudinary URL
-        cloudinaryURL := fmt.Sprintf("cloudinary://%s:%s@%s", apiKey, apiSecret, cloudName)
-        log.Printf("🔧 Using Cloudinary URL: cloudinary://%s:***@%s", apiKey, cloudName)
-        
-        cld, err := cloudinary.NewFromURL(cloudinaryURL)
-        if err != nil {
-            log.Printf("❌ Failed to initialize Cloudinary: %v", err)
-            c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Cloudinary initialization failed"})
-            return
+        // Check every file before uploading any
+        media := services.NewMediaService()
+        uploads := []struct {
+            field   string
+            purpose models.MediaPurpose
+            header  *multipart.FileHeader
+            folder  string
+            target  **string
+            mediaID **uint // For private documents, which are referenced instead of linked
+            media   *services.PreparedMedia
+        }{
+            {"profile_photo", models.MediaPurposeProfilePhoto, profileHeader, "workers/profile_photos/" + strconv.Itoa(int(userID)), &wp.ProfilePhoto, nil, nil},
+            {"id_card_photo", models.MediaPurposeIDCard, idHeader, "workers/id_cards/" + strconv.Itoa(int(userID)) + "/front", &wp.IDCardPhoto, &wp.IDCardMediaID, nil},
+            {"id_card_photo_back", models.MediaPurposeIDCard, idBackHeader, "workers/id_cards/" + strconv.Itoa(int(userID)) + "/back", &wp.IDCardBackPhoto, &wp.IDCardBackMediaID, nil},
+        }
+        for i := range uploads {
+            if uploads[i].header == nil {
+                continue
+            }
+            prepared, ok := prepareMedia(c, media, uploads[i].purpose, uploads[i].field, uploads[i].header)
+            if !ok {
+                return
+            }
+            uploads[i].media = prepared
         }
 
-        ctx := context.Background()
         data := gin.H{}
-
-        // Upload helper
-        upload := func(field string, header *multipart.FileHeader, folder string) (string, error) {
-            if header == nil { return "", nil }
-            file, err := header.Open()
-            if err != nil { return "", err }
-            defer file.Close()
-            ow := true
-            uf := true
-            up, err := cld.Upload.Upload(ctx, file, uploader.UploadParams{
-                Folder:   folder,
-                PublicID: strings.TrimSuffix(header.Filename, filepath.Ext(header.Filename)),
-                Overwrite: &ow,
-                UniqueFilename: &uf,
-                ResourceType: "image",
-            })
-            if err != nil { return "", err }
-            return up.SecureURL, nil
-        }
-
-        // Build folders
-        base := "workers"
-        profileFolder := base + "/profile_photos/" +  strconv.Itoa(int(userID))
-        idFolder := base + "/id_cards/" +  strconv.Itoa(int(userID)) + "/front"
-        idBackFolder := base + "/id_cards/" +  strconv.Itoa(int(userID)) + "/back"
-
-        // Perform uploads
-        if profileHeader != nil {
-            log.Printf("📸 Uploading profile photo to folder: %s", profileFolder)
-            if url, err := upload("profile_photo", profileHeader, profileFolder); err == nil {
-                wp.ProfilePhoto = &url
-                data["profile_photo_url"] = url
-                log.Printf("✅ Profile photo uploaded successfully: %s", url)
-            } else {
-                log.Printf("❌ Profile photo upload failed: %v", err)
-                c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Profile upload failed"})
-                return
+        for _, upload := range uploads {
+            if upload.media == nil {
+                continue
             }
-        }
-        if idHeader != nil {
-            log.Printf("📸 Uploading ID card photo to folder: %s", idFolder)
-            if url, err := upload("id_card_photo", idHeader, idFolder); err == nil {
-                wp.IDCardPhoto = &url
-                data["id_card_photo_url"] = url
-                log.Printf("✅ ID card photo uploaded successfully: %s", url)
-            } else {
-                log.Printf("❌ ID card photo upload failed: %v", err)
-                c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "ID card upload failed"})
+            log.Printf("📸 Uploading %s to folder: %s", upload.field, upload.folder)
+            stored, err := media.Store(userID, upload.media, upload.folder)
+            if err != nil {
+                log.Printf("❌ %s upload failed: %v", upload.field, err)
+                apierror.Abort(c, apierror.Unavailable("Photo upload failed", err))
                 return
             }
-        }
-        if idBackHeader != nil {
-            log.Printf("📸 Uploading ID card back photo to folder: %s", idBackFolder)
-            if url, err := upload("id_card_photo_back", idBackHeader, idBackFolder); err == nil {
-                wp.IDCardBackPhoto = &url
-                data["id_card_photo_back_url"] = url
-                log.Printf("✅ ID card back photo uploaded successfully: %s", url)
-            } else {
-                log.Printf("❌ ID card back photo upload failed: %v", err)
-                c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "ID card back upload failed"})
-                return
+            if upload.mediaID != nil {
+                mediaID := stored.ID
+                *upload.mediaID = &mediaID
+                *upload.target = nil
+                data[upload.field+"_media_id"] = mediaID
+                log.Printf("✅ %s uploaded privately as media %d", upload.field, mediaID)
+                continue
             }
+            url := stored.URL
+            *upload.target = &url
+            data[upload.field+"_url"] = url
+            log.Printf("✅ %s uploaded successfully: %s", upload.field, url)
         }
 
         wp.UpdatedAt = time.Now()
+        services.AdvanceOnboarding(&wp)
         if err := database.DB.Save(&wp).Error; err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to save profile"})
+            apierror.Abort(c, apierror.Internal("Failed to save profile", err))
             return
         }
 
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+)
+
+// GetRequestNavigation returns the destination of an active request, the customer's proxy number
+// and directions links, for the assigned worker to start navigating
+func GetRequestNavigation(c *gin.Context) {
+	workerProfile, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	var serviceRequest models.CustomerServiceRequest
+	if err := database.DB.Preload("Customer").
+		Where("id = ? AND assigned_worker_id = ?", c.Param("id"), workerProfile.ID).
+		First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return
+	}
+	if !serviceRequest.Status.IsActive() {
+		apierror.Abort(c, apierror.Unprocessable("Navigation is only available while the request is active"))
+		return
+	}
+	if serviceRequest.LocationLat == nil || serviceRequest.LocationLng == nil {
+		apierror.Abort(c, apierror.Unprocessable("Service request has no coordinates"))
+		return
+	}
+
+	// Navigation still works without a way to call; the proxy number is then left out
+	call, err := services.NewCallMaskingService().Session(serviceRequest.ID)
+	if err != nil && !errors.Is(err, services.ErrCallNotAvailable) {
+		log.Printf("⚠️ Failed to open call session for service request %d: %v", serviceRequest.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    serializers.Navigation(serviceRequest, call),
+	})
+}
@@ -0,0 +1,83 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// RegisterWorkerOnboardingRoutes registers the route workers use to follow their onboarding
+func RegisterWorkerOnboardingRoutes(router *gin.RouterGroup) {
+	router.GET("/worker/onboarding", getWorkerOnboarding)
+}
+
+// getWorkerOnboarding returns the current worker's onboarding checklist and next required step
+func getWorkerOnboarding(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    services.Checklist(worker),
+	})
+}
+
+// TransitionWorkerOnboarding moves a worker to the next onboarding status, or back, on an admin's
+// decision (admin only)
+func TransitionWorkerOnboarding(c *gin.Context) {
+	adminID := c.GetUint("user_id")
+
+	var req struct {
+		Status models.WorkerOnboardingStatus `json:"status" binding:"required"`
+		Note   string                        `json:"note" binding:"max=500"`
+	}
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	var worker models.WorkerProfile
+	if err := database.DB.Preload("User").Preload("Category").Preload("Categories.Category").First(&worker, c.Param("id")).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
+		return
+	}
+
+	before := worker
+	if err := services.NewOnboardingService().Transition(&worker, req.Status, req.Note); err != nil {
+		if errors.Is(err, services.ErrInvalidOnboardingTransition) {
+			apierror.Abort(c, apierror.Conflict("Worker cannot move from "+string(before.OnboardingStatus)+" to "+string(req.Status)).WithDetails(gin.H{
+				"allowed": services.AllowedOnboardingTransitions(before.OnboardingStatus),
+			}))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to update onboarding", err))
+		return
+	}
+	middleware.RecordAuditChange(c, "workers", worker.ID, before, worker)
+
+	log.Printf("🧭 Worker %d onboarding moved from %s to %s by admin %d", worker.ID, before.OnboardingStatus, worker.OnboardingStatus, adminID)
+
+	if err := SendLocalizedPushNotification(worker.UserID, "notification.onboarding."+string(worker.OnboardingStatus), i18n.Vars{"note": worker.OnboardingNote}, "onboarding", map[string]interface{}{
+		"onboarding_status": worker.OnboardingStatus,
+	}); err != nil {
+		log.Printf("⚠️ Failed to send onboarding notification: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Onboarding updated",
+		"data":    serializers.WorkerProfile(worker),
+	})
+}
@@ -0,0 +1,171 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// publicProfileReviews is how many recent reviews a public worker profile shows
+const publicProfileReviews = 5
+
+// RegisterPublicWorkerRoutes registers the worker profile anyone can browse
+func RegisterPublicWorkerRoutes(router *gin.RouterGroup) {
+	router.GET("/workers/:id/public", getPublicWorkerProfile)
+}
+
+// RegisterWorkerPortfolioRoutes registers the routes workers use to manage their portfolio
+func RegisterWorkerPortfolioRoutes(router *gin.RouterGroup) {
+	router.POST("/workers/portfolio", uploadPortfolioPhoto)
+	router.DELETE("/workers/portfolio/:photoId", deletePortfolioPhoto)
+}
+
+// getPublicWorkerProfile returns a worker's public profile with their rating breakdown, recent
+// reviews, portfolio and badges
+func getPublicWorkerProfile(c *gin.Context) {
+	workerID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
+		return
+	}
+
+	var worker models.WorkerProfile
+	if err := database.DB.Preload("User").Preload("Category").Preload("Categories.Category").Preload("Badges").
+		Joins("JOIN users ON users.id = worker_profiles.user_id AND users.is_active = ?", true).
+		First(&worker, workerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.NotFound("Worker not found"))
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to fetch worker profile", err))
+		return
+	}
+
+	summary, err := workerRatingSummary(worker.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch rating summary", err))
+		return
+	}
+
+	var reviews []models.WorkerRating
+	if err := database.DB.Preload("Customer").
+		Where("worker_id = ? AND moderation_status <> ?", worker.ID, models.ReviewHidden).
+		Order("created_at DESC").
+		Limit(publicProfileReviews).
+		Find(&reviews).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch reviews", err))
+		return
+	}
+
+	var portfolio []models.WorkerPortfolioPhoto
+	if err := database.DB.Where("worker_id = ?", worker.ID).Order("created_at DESC").Find(&portfolio).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch portfolio", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    serializers.WorkerPublicProfile(worker, summary, reviews, portfolio),
+	})
+}
+
+// uploadPortfolioPhoto adds a photo of past work, with an optional caption, to the current
+// worker's portfolio
+func uploadPortfolioPhoto(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Caption string `form:"caption" binding:"max=200"`
+	}
+	if !validation.BindForm(c, &req) {
+		return
+	}
+	header, _ := c.FormFile("photo")
+	if header == nil {
+		validation.Fail(c, "photo", "required", "")
+		return
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.WorkerPortfolioPhoto{}).Where("worker_id = ? AND kind = ?", worker.ID, models.PortfolioPhoto).Count(&count).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to check portfolio", err))
+		return
+	}
+	if count >= models.MaxPortfolioPhotos {
+		apierror.Abort(c, apierror.Validation("Your portfolio can hold at most "+strconv.Itoa(models.MaxPortfolioPhotos)+" photos"))
+		return
+	}
+
+	media := services.NewMediaService()
+	prepared, ok := prepareMedia(c, media, models.MediaPurposePortfolioPhoto, "photo", header)
+	if !ok {
+		return
+	}
+	uploaded, err := media.Store(worker.UserID, prepared, "workers/portfolio/"+strconv.Itoa(int(worker.ID)))
+	if err != nil {
+		log.Printf("❌ Portfolio photo upload failed for worker %d: %v", worker.ID, err)
+		apierror.Abort(c, apierror.Unavailable("Photo upload failed", err))
+		return
+	}
+
+	photo := models.WorkerPortfolioPhoto{
+		WorkerID: worker.ID,
+		URL:      uploaded.URL,
+		PublicID: uploaded.PublicID,
+		Caption:  req.Caption,
+	}
+	if err := database.DB.Create(&photo).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to save portfolio photo", err))
+		return
+	}
+	log.Printf("🖼️ Worker %d added portfolio photo %d", worker.ID, photo.ID)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    photo,
+	})
+}
+
+// deletePortfolioPhoto removes a photo from the current worker's portfolio and from Cloudinary
+func deletePortfolioPhoto(c *gin.Context) {
+	worker, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+
+	var photo models.WorkerPortfolioPhoto
+	if err := database.DB.Where("id = ? AND worker_id = ?", c.Param("photoId"), worker.ID).First(&photo).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Portfolio photo not found"))
+		return
+	}
+	if err := database.DB.Delete(&photo).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to delete portfolio photo", err))
+		return
+	}
+
+	// The photo is already off the profile; a leftover upload only costs storage
+	if photo.PublicID != "" {
+		if err := services.NewMediaService().Delete(photo.PublicID); err != nil {
+			log.Printf("⚠️ Failed to delete portfolio upload %s: %v", photo.PublicID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Portfolio photo deleted",
+	})
+}
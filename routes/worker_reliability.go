@@ -0,0 +1,193 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/middleware"
+	"repair-service-server/models"
+	"repair-service-server/serializers"
+	"repair-service-server/services"
+	"repair-service-server/validation"
+)
+
+// CancelAssignedRequest lets the assigned worker give a job back before starting it. It counts
+// against their reliability score and the request is offered to other workers.
+func CancelAssignedRequest(c *gin.Context) {
+	var req models.WorkerCancelRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+	workerProfile, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+
+	request, err := services.NewReliabilityService().CancelAssignedRequest(serviceRequest.ID, workerProfile.ID, req.Reason)
+	switch {
+	case errors.Is(err, services.ErrCannotCancelRequest):
+		apierror.Abort(c, apierror.Validation(err.Error()))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to cancel service request", err))
+		return
+	}
+
+	log.Printf("↩️ Worker %d gave back service request %d: %s", workerProfile.ID, request.ID, req.Reason)
+	publishRequestEvent("request_worker_cancelled", *request)
+	startDispatch(*request)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":        true,
+		"message":        "Service request given back",
+		"request_status": request.Status,
+	})
+}
+
+// GetWorkerReliability returns the calling worker's reliability score, tier and recent events
+func GetWorkerReliability(c *gin.Context) {
+	workerProfile, ok := currentWorkerProfile(c)
+	if !ok {
+		return
+	}
+	reliability, err := services.NewReliabilityService().Get(workerProfile.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch reliability", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reliability,
+	})
+}
+
+// GetWorkerReliabilityForAdmin returns a worker's reliability score, tier, suspension and recent events
+func GetWorkerReliabilityForAdmin(c *gin.Context) {
+	workerID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
+		return
+	}
+	reliability, err := services.NewReliabilityService().Get(workerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch reliability", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    reliability,
+	})
+}
+
+// SuspendWorker suspends a worker for a number of days whatever their reliability score
+func SuspendWorker(c *gin.Context) {
+	workerID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
+		return
+	}
+	var req models.ReliabilitySuspendRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	worker, err := services.NewReliabilityService().Suspend(workerID, req.Days, req.Reason)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to suspend worker", err))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "worker", worker.ID, gin.H{"suspended_until": nil},
+		gin.H{"suspended_until": worker.SuspendedUntil, "reason": req.Reason})
+	log.Printf("⛔ Worker %d suspended for %d days by admin %d", worker.ID, req.Days, c.GetUint("user_id"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Worker suspended",
+		"data":    serializers.WorkerProfile(*worker),
+	})
+}
+
+// ReinstateWorker ends a worker's suspension early
+func ReinstateWorker(c *gin.Context) {
+	workerID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
+		return
+	}
+
+	until, err := services.NewReliabilityService().Reinstate(workerID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("Worker not found"))
+		return
+	case errors.Is(err, services.ErrNotSuspended):
+		apierror.Abort(c, apierror.Conflict(err.Error()))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to reinstate worker", err))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "worker", workerID, gin.H{"suspended_until": until}, gin.H{"suspended_until": nil})
+	log.Printf("✅ Worker %d reinstated by admin %d", workerID, c.GetUint("user_id"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Worker reinstated",
+	})
+}
+
+// WaiveReliabilityEvent excuses one of a worker's reliability events and rescores them
+func WaiveReliabilityEvent(c *gin.Context) {
+	workerID, ok := parseIDParam(c, "id")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid worker ID"))
+		return
+	}
+	eventID, ok := parseIDParam(c, "eventId")
+	if !ok {
+		apierror.Abort(c, apierror.Validation("Invalid reliability event ID"))
+		return
+	}
+	var req models.ReliabilityWaiveRequest
+	if !validation.BindJSON(c, &req) {
+		return
+	}
+
+	event, err := services.NewReliabilityService().Waive(workerID, eventID, c.GetUint("user_id"), req.Note)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		apierror.Abort(c, apierror.NotFound("Reliability event not found"))
+		return
+	case errors.Is(err, services.ErrEventWaived):
+		apierror.Abort(c, apierror.Conflict(err.Error()))
+		return
+	case err != nil:
+		apierror.Abort(c, apierror.Internal("Failed to waive reliability event", err))
+		return
+	}
+
+	middleware.RecordAuditChange(c, "worker_reliability_event", event.ID, gin.H{"waived": false}, gin.H{"waived": true, "note": req.Note})
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Reliability event waived",
+		"data":    event,
+	})
+}
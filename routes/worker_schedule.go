@@ -0,0 +1,212 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/services"
+)
+
+// RegisterWorkerScheduleRoutes registers worker schedule and time-off routes
+func RegisterWorkerScheduleRoutes(router *gin.RouterGroup) {
+	schedule := router.Group("/worker")
+	{
+		schedule.GET("/schedule", getWorkerSchedule)
+		schedule.PUT("/schedule", updateWorkerSchedule)
+		schedule.GET("/time-off", getWorkerTimeOff)
+		schedule.POST("/time-off", createWorkerTimeOff)
+		schedule.DELETE("/time-off/:id", deleteWorkerTimeOff)
+	}
+}
+
+// getWorkerSchedule returns the current worker's weekly schedule
+func getWorkerSchedule(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+
+	var slots []models.WorkerScheduleSlot
+	if err := database.DB.Where("worker_id = ?", workerProfile.ID).
+		Order("day_of_week ASC, start_time ASC").Find(&slots).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch schedule", nil))
+		return
+	}
+
+	available, err := services.NewWorkerScheduleService().IsWorkerAvailableAt(workerProfile.ID, time.Now())
+	if err != nil {
+		log.Printf("⚠️ Failed to compute schedule availability for worker %d: %v", workerProfile.ID, err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"slots":        slots,
+			"on_shift_now": available,
+			"is_available": workerProfile.IsAvailable,
+		},
+	})
+}
+
+// updateWorkerSchedule replaces the current worker's weekly schedule
+func updateWorkerSchedule(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.WorkerScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation(err.Error()))
+		return
+	}
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+
+	slots := make([]models.WorkerScheduleSlot, 0, len(req.Slots))
+	for _, s := range req.Slots {
+		start, err := services.ParseClock(s.StartTime)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation(err.Error()))
+			return
+		}
+		end, err := services.ParseClock(s.EndTime)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation(err.Error()))
+			return
+		}
+		if end <= start {
+			apierror.Abort(c, apierror.Validation("end_time must be after start_time"))
+			return
+		}
+		slots = append(slots, models.WorkerScheduleSlot{
+			WorkerID:  workerProfile.ID,
+			DayOfWeek: s.DayOfWeek,
+			StartTime: s.StartTime,
+			EndTime:   s.EndTime,
+		})
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("worker_id = ?", workerProfile.ID).Delete(&models.WorkerScheduleSlot{}).Error; err != nil {
+			return err
+		}
+		if len(slots) == 0 {
+			return nil
+		}
+		return tx.Create(&slots).Error
+	})
+	if err != nil {
+		log.Printf("❌ Failed to update schedule for worker %d: %v", workerProfile.ID, err)
+		apierror.Abort(c, apierror.Internal("Failed to update schedule", nil))
+		return
+	}
+
+	log.Printf("✅ Schedule updated for worker %d (%d slots)", workerProfile.ID, len(slots))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Schedule updated successfully",
+		"data":    slots,
+	})
+}
+
+// getWorkerTimeOff returns the current worker's upcoming time-off blocks
+func getWorkerTimeOff(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+
+	var blocks []models.WorkerTimeOff
+	if err := database.DB.Where("worker_id = ? AND ends_at > ?", workerProfile.ID, time.Now()).
+		Order("starts_at ASC").Find(&blocks).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch time off", nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    blocks,
+	})
+}
+
+// createWorkerTimeOff adds a one-off time-off block for the current worker
+func createWorkerTimeOff(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req models.WorkerTimeOffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.Validation(err.Error()))
+		return
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		apierror.Abort(c, apierror.Validation("ends_at must be after starts_at"))
+		return
+	}
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+
+	block := models.WorkerTimeOff{
+		WorkerID: workerProfile.ID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+	}
+
+	if err := database.DB.Create(&block).Error; err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create time off", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Time off created successfully",
+		"data":    block,
+	})
+}
+
+// deleteWorkerTimeOff removes a time-off block owned by the current worker
+func deleteWorkerTimeOff(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var workerProfile models.WorkerProfile
+	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return
+	}
+
+	result := database.DB.Where("id = ? AND worker_id = ?", c.Param("id"), workerProfile.ID).Delete(&models.WorkerTimeOff{})
+	if result.Error != nil {
+		apierror.Abort(c, apierror.Internal("Failed to delete time off", result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		apierror.Abort(c, apierror.NotFound("Time off not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Time off deleted successfully",
+	})
+}
@@ -0,0 +1,233 @@
+package routes
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"repair-service-server/apierror"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/services"
+	"repair-service-server/utils"
+	ws "repair-service-server/websocket"
+)
+
+// travelSpeedKmh is the average speed assumed when estimating a worker's arrival time
+const travelSpeedKmh = 30.0
+
+func MarkEnRoute(c *gin.Context) {
+	markEnRoute(c)
+}
+
+func MarkArrived(c *gin.Context) {
+	markArrived(c)
+}
+
+// markEnRoute records that the assigned worker has set off and shares their ETA with the customer
+func markEnRoute(c *gin.Context) {
+	// The worker's position is optional; without it the last reported location is used
+	var body struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+	_ = c.ShouldBindJSON(&body)
+
+	workerProfile, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+
+	if serviceRequest.Status != models.RequestStatusAccepted {
+		apierror.Abort(c, apierror.Validation("Service request is not in accepted status"))
+		return
+	}
+
+	now := time.Now()
+	if body.Latitude != nil && body.Longitude != nil && utils.IsLocationValid(*body.Latitude, *body.Longitude) {
+		workerProfile.CurrentLat = body.Latitude
+		workerProfile.CurrentLng = body.Longitude
+		workerProfile.LastLocationUpdate = &now
+		if err := database.DB.Model(&workerProfile).Updates(map[string]interface{}{
+			"current_lat":          body.Latitude,
+			"current_lng":          body.Longitude,
+			"last_location_update": &now,
+		}).Error; err != nil {
+			log.Printf("⚠️ Failed to update location for worker %d: %v", workerProfile.ID, err)
+		}
+	}
+
+	serviceRequest.Status = models.RequestStatusEnRoute
+	serviceRequest.EnRouteAt = &now
+	serviceRequest.EtaMinutes = estimateArrivalMinutes(workerProfile, serviceRequest)
+	serviceRequest.EtaUpdatedAt = &now
+
+	if err := database.DB.Save(&serviceRequest).Error; err != nil {
+		log.Printf("❌ Failed to update service request %d: %v", serviceRequest.ID, err)
+		apierror.Abort(c, apierror.Internal("Failed to update service request", nil))
+		return
+	}
+
+	if err := SendServiceStatusNotification(serviceRequest.CustomerID, serviceRequest.ID, "en_route"); err != nil {
+		log.Printf("⚠️ Failed to send en route notification: %v", err)
+	}
+	pushWorkerETA(workerProfile, serviceRequest)
+	publishRequestEvent("request_en_route", serviceRequest)
+
+	log.Printf("🚗 Worker %d is en route to service request %d (ETA %v min)", workerProfile.ID, serviceRequest.ID, formatETA(serviceRequest.EtaMinutes))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":        true,
+		"message":        "Customer notified that you are on your way",
+		"request_status": serviceRequest.Status,
+		"en_route_at":    serviceRequest.EnRouteAt,
+		"eta_minutes":    serviceRequest.EtaMinutes,
+	})
+}
+
+// markArrived records that the assigned worker is at the customer's location
+func markArrived(c *gin.Context) {
+	workerProfile, serviceRequest, ok := loadAssignedRequest(c)
+	if !ok {
+		return
+	}
+
+	// Workers may skip the en route step, in which case no travel time is recorded
+	if serviceRequest.Status != models.RequestStatusEnRoute && serviceRequest.Status != models.RequestStatusAccepted {
+		apierror.Abort(c, apierror.Validation("Service request is not in accepted or en route status"))
+		return
+	}
+
+	now := time.Now()
+	serviceRequest.Status = models.RequestStatusArrived
+	serviceRequest.ArrivedAt = &now
+	serviceRequest.EtaMinutes = nil
+	serviceRequest.EtaUpdatedAt = &now
+
+	if err := database.DB.Save(&serviceRequest).Error; err != nil {
+		log.Printf("❌ Failed to update service request %d: %v", serviceRequest.ID, err)
+		apierror.Abort(c, apierror.Internal("Failed to update service request", nil))
+		return
+	}
+
+	if err := SendServiceStatusNotification(serviceRequest.CustomerID, serviceRequest.ID, "arrived"); err != nil {
+		log.Printf("⚠️ Failed to send arrival notification: %v", err)
+	}
+	pushWorkerETA(workerProfile, serviceRequest)
+	publishRequestEvent("request_arrived", serviceRequest)
+	if err := services.NewReliabilityService().CheckArrival(serviceRequest, workerProfile.ID); err != nil {
+		log.Printf("⚠️ Failed to check late arrival on request %d: %v", serviceRequest.ID, err)
+	}
+
+	var travelMinutes *float64
+	if serviceRequest.EnRouteAt != nil {
+		minutes := now.Sub(*serviceRequest.EnRouteAt).Minutes()
+		travelMinutes = &minutes
+
+		if err := services.NewWorkerAnalyticsService().TrackJobTravel(workerProfile.ID, serviceRequest.ID, minutes); err != nil {
+			log.Printf("⚠️ Failed to track travel time analytics: %v", err)
+		}
+	}
+
+	log.Printf("📍 Worker %d arrived at service request %d", workerProfile.ID, serviceRequest.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":        true,
+		"message":        "Customer notified that you have arrived",
+		"request_status": serviceRequest.Status,
+		"arrived_at":     serviceRequest.ArrivedAt,
+		"travel_minutes": travelMinutes,
+	})
+}
+
+// loadAssignedRequest loads the calling worker's profile and the request in the :id param,
+// aborting unless the request is assigned to that worker
+func loadAssignedRequest(c *gin.Context) (models.WorkerProfile, models.CustomerServiceRequest, bool) {
+	var workerProfile models.WorkerProfile
+	var serviceRequest models.CustomerServiceRequest
+
+	if err := database.DB.Where("user_id = ?", c.GetUint("user_id")).First(&workerProfile).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Worker profile not found"))
+		return workerProfile, serviceRequest, false
+	}
+	if err := database.DB.Where("id = ?", c.Param("id")).First(&serviceRequest).Error; err != nil {
+		apierror.Abort(c, apierror.NotFound("Service request not found"))
+		return workerProfile, serviceRequest, false
+	}
+	if serviceRequest.AssignedWorkerID == nil || *serviceRequest.AssignedWorkerID != workerProfile.ID {
+		apierror.Abort(c, apierror.Forbidden("You are not assigned to this request"))
+		return workerProfile, serviceRequest, false
+	}
+	return workerProfile, serviceRequest, true
+}
+
+// estimateArrivalMinutes estimates how long the worker needs to reach the request, or nil when
+// either location is unknown
+func estimateArrivalMinutes(worker models.WorkerProfile, request models.CustomerServiceRequest) *int {
+	if worker.CurrentLat == nil || worker.CurrentLng == nil || request.LocationLat == nil || request.LocationLng == nil {
+		return nil
+	}
+	eta := utils.CalculateETA(
+		utils.Location{Latitude: *worker.CurrentLat, Longitude: *worker.CurrentLng},
+		utils.Location{Latitude: *request.LocationLat, Longitude: *request.LocationLng},
+		travelSpeedKmh,
+	)
+	minutes := int(eta.Minutes())
+	return &minutes
+}
+
+// refreshWorkerETAs recomputes the ETA of every request the worker is travelling to and pushes
+// it to the customers. Called whenever the worker reports a new location.
+func refreshWorkerETAs(worker models.WorkerProfile) {
+	var requests []models.CustomerServiceRequest
+	if err := database.DB.Where("assigned_worker_id = ? AND status = ?", worker.ID, models.RequestStatusEnRoute).
+		Find(&requests).Error; err != nil {
+		log.Printf("⚠️ Failed to load en route requests for worker %d: %v", worker.ID, err)
+		return
+	}
+
+	now := time.Now()
+	for _, request := range requests {
+		request.EtaMinutes = estimateArrivalMinutes(worker, request)
+		request.EtaUpdatedAt = &now
+		if err := database.DB.Model(&request).Updates(map[string]interface{}{
+			"eta_minutes":    request.EtaMinutes,
+			"eta_updated_at": request.EtaUpdatedAt,
+		}).Error; err != nil {
+			log.Printf("⚠️ Failed to update ETA for service request %d: %v", request.ID, err)
+			continue
+		}
+		pushWorkerETA(worker, request)
+	}
+}
+
+// pushWorkerETA sends the customer the worker's live position and arrival estimate
+func pushWorkerETA(worker models.WorkerProfile, request models.CustomerServiceRequest) {
+	data := map[string]interface{}{
+		"request_id":     request.ID,
+		"status":         request.Status,
+		"eta_minutes":    request.EtaMinutes,
+		"eta_updated_at": request.EtaUpdatedAt,
+		"latitude":       worker.CurrentLat,
+		"longitude":      worker.CurrentLng,
+	}
+	pushRequestUpdate(request.ID, "worker_eta", data)
+	if chatHub == nil {
+		return
+	}
+	chatHub.SendToUser(request.CustomerID, &ws.Message{
+		Type:      "worker_eta",
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+// formatETA renders an optional ETA for logs
+func formatETA(minutes *int) interface{} {
+	if minutes == nil {
+		return "unknown"
+	}
+	return *minutes
+}
@@ -0,0 +1,53 @@
+package seed
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+)
+
+// Usage describes the seed subcommand
+const Usage = `usage: server seed [--scale N] [--city NAME] [--months N] [--random-seed N]
+
+Fills the database with synthetic data for load testing. Each unit of scale adds
+100 customers, 25 workers and about 1,015 requests with their chats, ratings and
+worker analytics. Runs add to the data already there; use a disposable database.
+
+flags:
+  --scale N          units of data to generate (default 1)
+  --city NAME        supported service area to place workers and requests in (default Nouakchott)
+  --months N         months of request history to generate (default 6)
+  --random-seed N    seed for the generator; the same seed generates the same data (default 1)`
+
+// RunCLI parses the seed flags and generates the data
+func RunCLI(db *gorm.DB, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	opts := Options{}
+	flags.IntVar(&opts.Scale, "scale", 1, "")
+	flags.StringVar(&opts.City, "city", "Nouakchott", "")
+	flags.IntVar(&opts.Months, "months", 6, "")
+	flags.Int64Var(&opts.RandomSeed, "random-seed", 1, "")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return errors.New(Usage)
+		}
+		return fmt.Errorf("%v\n\n%s", err, Usage)
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q\n\n%s", flags.Arg(0), Usage)
+	}
+
+	summary, err := Run(db, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("seeded %s: %d customers, %d workers, %d requests (%d completed, %d rated), %d chat rooms with %d messages\n",
+		opts.City, summary.Customers, summary.Workers, summary.Requests, summary.Histories, summary.Ratings,
+		summary.ChatRooms, summary.Messages)
+	fmt.Printf("synthetic accounts have phone numbers starting with %s and the password %q\n", PhonePrefix, Password)
+	return nil
+}
@@ -0,0 +1,66 @@
+package seed
+
+var firstNames = []string{
+	"Mohamed", "Ahmed", "Sidi", "Cheikh", "Abdallahi", "Brahim", "Moussa", "Oumar", "Mamadou",
+	"Yahya", "El Hacen", "Mohamed Lemine", "Fatimetou", "Mariem", "Aminetou", "Khadijetou",
+	"Aichetou", "Zeinabou", "Coumba", "Vatimetou", "Salka", "Mounina", "Lalla", "Hawa",
+}
+
+var lastNames = []string{
+	"Ould Ahmed", "Ould Mohamed", "Ould Sidi", "Ould Cheikh", "Ould Brahim", "Mint Ahmed",
+	"Mint Mohamed", "Mint Sidi", "Ba", "Sy", "Diallo", "Kane", "Sow", "Ndiaye", "Camara", "Diop",
+}
+
+// neighbourhoods of the supported service areas, used in addresses
+var neighbourhoods = map[string][]string{
+	"Nouakchott": {
+		"Tevragh Zeina", "Ksar", "Teyarett", "Dar Naim", "Toujounine", "Arafat", "El Mina",
+		"Sebkha", "Riyad", "Capitale", "Socogim", "Carrefour Madrid",
+	},
+	"Nouadhibou": {
+		"Numerowatt", "Cansado", "Dubaï", "Tarhil", "Centre-ville", "Ghairan", "Boulenoir",
+	},
+}
+
+var requestIssues = []string{
+	"intervention urgente", "réparation", "installation", "entretien", "diagnostic", "devis sur place",
+	"panne", "remplacement",
+}
+
+var requestDescriptions = []string{
+	"Besoin d'une intervention rapide, merci de me contacter avant de venir.",
+	"Le problème est apparu hier soir, je suis disponible toute la journée.",
+	"Appartement au deuxième étage, pas d'ascenseur.",
+	"Merci d'apporter le matériel nécessaire.",
+	"Travail à faire dans une villa, le gardien vous ouvrira.",
+	"Déjà réparé une fois mais le problème revient.",
+}
+
+// ratingComments by stars
+var ratingComments = map[int][]string{
+	1: {"Travail non terminé.", "Très en retard et pas professionnel."},
+	2: {"Le problème est revenu le lendemain.", "Travail moyen, trop cher."},
+	3: {"Correct, sans plus.", "Bon travail mais arrivé en retard."},
+	4: {"Bon travail, je recommande.", "Rapide et efficace.", "Très correct."},
+	5: {"Excellent travail, merci !", "Très professionnel, je recommande vivement.", "Parfait, rapide et propre.", ""},
+}
+
+// chatLines by sender type
+var chatLines = map[string][]string{
+	"customer": {
+		"Bonjour, vous arrivez dans combien de temps ?",
+		"Je suis à la maison, sonnez à la porte bleue.",
+		"D'accord, merci.",
+		"Vous avez besoin que j'achète une pièce ?",
+		"C'est bon, ça marche maintenant. Merci !",
+		"Le gardien va vous ouvrir.",
+	},
+	"worker": {
+		"Bonjour, j'ai bien reçu votre demande.",
+		"Je suis en route, j'arrive dans 20 minutes.",
+		"Pouvez-vous m'envoyer la localisation exacte ?",
+		"Je suis devant chez vous.",
+		"Il faudra changer la pièce, je l'ai avec moi.",
+		"Le travail est terminé, merci pour votre confiance.",
+	},
+}
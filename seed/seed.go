@@ -0,0 +1,727 @@
+// Package seed fills a database with synthetic customers, workers, requests, chats and history for
+// load and soak testing. Synthetic accounts use phone numbers starting with PhonePrefix, which no
+// real Mauritanian number does, and all share Password so load scripts can sign in as them.
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/duration"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/services"
+	"repair-service-server/utils"
+)
+
+// PhonePrefix starts the phone number of every synthetic account
+const PhonePrefix = "+2220"
+
+// Password is the password of every synthetic account
+const Password = "loadtest-password"
+
+// Rows generated per unit of scale
+const (
+	customersPerScale       = 100
+	workersPerScale         = 25
+	historyRequestsPerScale = 1000 // Completed, cancelled and expired, spread over the history window
+	openRequestsPerScale    = 10   // Still broadcast, for matching
+	activeRequestsPerScale  = 5    // Accepted, en route or in progress
+)
+
+const batchSize = 500
+
+// Options control how much data is generated and where
+type Options struct {
+	Scale      int
+	City       string // A supported service area; requests and workers are placed inside it
+	Months     int    // How far back request history goes
+	RandomSeed int64  // The same seed generates the same data
+}
+
+// Summary counts the rows a run created
+type Summary struct {
+	Customers int
+	Workers   int
+	Requests  int
+	Histories int
+	Ratings   int
+	ChatRooms int
+	Messages  int
+}
+
+// Run generates the data in one transaction, then rebuilds the platform reports and demand
+// heatmap over the generated range
+func Run(db *gorm.DB, opts Options) (*Summary, error) {
+	s, err := newSeeder(db, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Transaction(s.generate); err != nil {
+		return nil, err
+	}
+	if err := services.NewReportService().AggregateRange(s.since, s.now); err != nil {
+		return nil, fmt.Errorf("rebuilding reports: %w", err)
+	}
+	if err := services.NewDemandService().RebuildHeatmap(); err != nil {
+		return nil, fmt.Errorf("rebuilding demand heatmap: %w", err)
+	}
+	return &s.summary, nil
+}
+
+type seeder struct {
+	db         *gorm.DB
+	opts       Options
+	rng        *rand.Rand
+	area       services.ServiceArea
+	now        time.Time
+	since      time.Time
+	categories []models.ServiceCategory
+	commission float64
+	nextPhone  int64
+
+	customers         []models.User
+	workers           []models.WorkerProfile
+	workersByCategory map[uint][]int // Indexes into workers
+	workerUserIDs     map[uint]uint  // Worker profile ID to user ID
+
+	stats   *statsBuilder
+	summary Summary
+}
+
+func newSeeder(db *gorm.DB, opts Options) (*seeder, error) {
+	if opts.Scale < 1 {
+		return nil, fmt.Errorf("scale must be at least 1, got %d", opts.Scale)
+	}
+	if opts.Months < 1 {
+		return nil, fmt.Errorf("months must be at least 1, got %d", opts.Months)
+	}
+	city := services.NormalizeCity(opts.City)
+	var area *services.ServiceArea
+	names := []string{}
+	for _, a := range services.GetSupportedServiceAreas() {
+		names = append(names, a.City)
+		if a.City == city {
+			found := a
+			area = &found
+		}
+	}
+	if area == nil {
+		return nil, fmt.Errorf("%q is not a supported service area (%s)", opts.City, strings.Join(names, ", "))
+	}
+
+	s := &seeder{
+		db:                db,
+		opts:              opts,
+		rng:               rand.New(rand.NewSource(opts.RandomSeed)),
+		area:              *area,
+		now:               time.Now(),
+		workersByCategory: map[uint][]int{},
+		workerUserIDs:     map[uint]uint{},
+	}
+	s.since = s.now.AddDate(0, -opts.Months, 0)
+	s.stats = newStatsBuilder(s.now)
+
+	if err := db.Where("is_active = ?", true).Order("sort_order").Find(&s.categories).Error; err != nil {
+		return nil, err
+	}
+	if len(s.categories) == 0 {
+		return nil, fmt.Errorf("no active service categories; set up the catalog before seeding")
+	}
+	var existing int64
+	if err := db.Model(&models.User{}).Where("phone_number LIKE ?", PhonePrefix+"%").Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	s.nextPhone = existing + 1
+	s.commission = services.NewRegionService().CommissionPercentForRequest(models.CustomerServiceRequest{LocationCity: area.City})
+	return s, nil
+}
+
+func (s *seeder) generate(tx *gorm.DB) error {
+	hash, err := utils.HashPassword(Password)
+	if err != nil {
+		return err
+	}
+	if err := s.createCustomers(tx, hash); err != nil {
+		return fmt.Errorf("creating customers: %w", err)
+	}
+	if err := s.createWorkers(tx, hash); err != nil {
+		return fmt.Errorf("creating workers: %w", err)
+	}
+	if err := s.createRequests(tx); err != nil {
+		return fmt.Errorf("creating requests: %w", err)
+	}
+	if err := s.updateWorkerCounters(tx); err != nil {
+		return fmt.Errorf("updating worker counters: %w", err)
+	}
+	if err := s.stats.save(tx); err != nil {
+		return fmt.Errorf("saving worker stats: %w", err)
+	}
+	return nil
+}
+
+// phone returns the next unused synthetic phone number
+func (s *seeder) phone() string {
+	number := fmt.Sprintf("%s%07d", PhonePrefix, s.nextPhone)
+	s.nextPhone++
+	return number
+}
+
+func (s *seeder) createCustomers(tx *gorm.DB, passwordHash string) error {
+	count := customersPerScale * s.opts.Scale
+	s.customers = make([]models.User, 0, count)
+	for i := 0; i < count; i++ {
+		joined := s.randomTime(s.since.AddDate(0, -3, 0), s.since)
+		s.customers = append(s.customers, models.User{
+			FullName:        s.fullName(),
+			PhoneNumber:     s.phone(),
+			PasswordHash:    passwordHash,
+			Role:            models.RoleCustomer,
+			IsActive:        true,
+			PhoneVerifiedAt: &joined,
+			CreatedAt:       joined,
+			UpdatedAt:       joined,
+		})
+	}
+	if err := tx.CreateInBatches(&s.customers, batchSize).Error; err != nil {
+		return err
+	}
+	s.summary.Customers = len(s.customers)
+	return nil
+}
+
+func (s *seeder) createWorkers(tx *gorm.DB, passwordHash string) error {
+	count := workersPerScale * s.opts.Scale
+	users := make([]models.User, 0, count)
+	for i := 0; i < count; i++ {
+		joined := s.randomTime(s.since.AddDate(0, -3, 0), s.since)
+		users = append(users, models.User{
+			FullName:        s.fullName(),
+			PhoneNumber:     s.phone(),
+			PasswordHash:    passwordHash,
+			Role:            models.RoleWorker,
+			IsActive:        true,
+			PhoneVerifiedAt: &joined,
+			CreatedAt:       joined,
+			UpdatedAt:       joined,
+		})
+	}
+	if err := tx.CreateInBatches(&users, batchSize).Error; err != nil {
+		return err
+	}
+
+	s.workers = make([]models.WorkerProfile, 0, count)
+	for i, user := range users {
+		// Round robin so every category has workers once there are enough of them
+		category := s.categories[i%len(s.categories)]
+		lat, lng := s.randomPoint()
+		seen := s.now.Add(-time.Duration(s.rng.Intn(120)) * time.Minute)
+		accuracy := 5 + s.rng.Float64()*25
+		s.workers = append(s.workers, models.WorkerProfile{
+			UserID:              user.ID,
+			CategoryID:          category.ID,
+			PhoneNumber:         user.PhoneNumber,
+			Country:             "Mauritanie",
+			State:               s.area.City,
+			City:                s.area.City,
+			PostalCode:          "00000",
+			Address:             s.neighbourhood() + ", " + s.area.City,
+			Experience:          fmt.Sprintf("%d ans d'expérience", 1+s.rng.Intn(15)),
+			Skills:              category.Name,
+			HourlyRate:          float64(15+s.rng.Intn(36)) * 100,
+			IsAvailable:         s.rng.Float64() < 0.6,
+			CurrentLat:          &lat,
+			CurrentLng:          &lng,
+			LastLocationUpdate:  &seen,
+			LocationAccuracy:    &accuracy,
+			MaxConcurrentJobs:   2,
+			IsVerified:          true,
+			ReliabilityScore:    100,
+			ReliabilityTier:     models.ReliabilityTierGood,
+			OnboardingStatus:    models.OnboardingActive,
+			OnboardingUpdatedAt: &user.CreatedAt,
+			CreatedAt:           user.CreatedAt,
+			UpdatedAt:           user.CreatedAt,
+		})
+	}
+	if err := tx.CreateInBatches(&s.workers, batchSize).Error; err != nil {
+		return err
+	}
+	for i, w := range s.workers {
+		s.workersByCategory[w.CategoryID] = append(s.workersByCategory[w.CategoryID], i)
+		s.workerUserIDs[w.ID] = w.UserID
+	}
+	s.summary.Workers = len(s.workers)
+	return nil
+}
+
+// requestPlan is a generated request with the rows that hang off it
+type requestPlan struct {
+	request  models.CustomerServiceRequest
+	worker   int // Index into workers, -1 when no worker took it
+	stars    int // 0 when not rated
+	messages int
+}
+
+func (s *seeder) createRequests(tx *gorm.DB) error {
+	plans := make([]*requestPlan, 0, (historyRequestsPerScale+openRequestsPerScale+activeRequestsPerScale)*s.opts.Scale)
+	historyEnd := s.now.Add(-6 * time.Hour) // Leaves time for the last jobs to finish
+	for i := 0; i < historyRequestsPerScale*s.opts.Scale; i++ {
+		plans = append(plans, s.historyRequest(s.workingHoursTime(s.since, historyEnd)))
+	}
+	for i := 0; i < openRequestsPerScale*s.opts.Scale; i++ {
+		plans = append(plans, s.openRequest())
+	}
+	for i := 0; i < activeRequestsPerScale*s.opts.Scale; i++ {
+		plans = append(plans, s.activeRequest())
+	}
+
+	requests := make([]models.CustomerServiceRequest, len(plans))
+	for i, p := range plans {
+		requests[i] = p.request
+	}
+	if err := tx.CreateInBatches(&requests, batchSize).Error; err != nil {
+		return err
+	}
+	for i := range plans {
+		plans[i].request.ID = requests[i].ID
+	}
+	s.summary.Requests = len(requests)
+
+	if err := s.createHistories(tx, plans); err != nil {
+		return err
+	}
+	if err := s.createRatings(tx, plans); err != nil {
+		return err
+	}
+	return s.createChats(tx, plans)
+}
+
+// historyRequest is a finished request: mostly completed, the rest cancelled or expired
+func (s *seeder) historyRequest(createdAt time.Time) *requestPlan {
+	r := s.baseRequest(createdAt)
+	p := &requestPlan{worker: -1}
+	roll := s.rng.Float64()
+	switch {
+	case roll < 0.78:
+		p.worker = s.pickWorker(r.CategoryID, false)
+		s.fillTimeline(&r, models.RequestStatusCompleted)
+		if s.rng.Float64() < 0.7 {
+			p.stars = s.stars()
+		}
+		p.messages = 3 + s.rng.Intn(8)
+	case roll < 0.9:
+		r.Status = models.RequestStatusCancelled
+		if s.rng.Float64() < 0.5 {
+			// Cancelled after a worker accepted
+			p.worker = s.pickWorker(r.CategoryID, false)
+			accepted := r.CreatedAt.Add(s.minutes(2, 20))
+			r.AcceptedAt = &accepted
+			p.messages = 1 + s.rng.Intn(4)
+		}
+	default:
+		r.Status = models.RequestStatusExpired
+	}
+	r.UpdatedAt = r.CreatedAt
+	if r.CompletedAt != nil {
+		r.UpdatedAt = *r.CompletedAt
+	}
+	s.recordStats(r, p)
+	p.request = r
+	return p
+}
+
+// openRequest is still waiting for a worker. It expires a day out rather than after the usual
+// few minutes so matching has a backlog to search for the length of a test run.
+func (s *seeder) openRequest() *requestPlan {
+	r := s.baseRequest(s.now.Add(-s.minutes(0, 20)))
+	r.Status = models.RequestStatusBroadcast
+	expires := s.now.Add(24 * time.Hour)
+	r.ExpiresAt = &expires
+	r.UpdatedAt = r.CreatedAt
+	return &requestPlan{request: r, worker: -1}
+}
+
+// activeRequest is being worked on by a worker with room for it
+func (s *seeder) activeRequest() *requestPlan {
+	r := s.baseRequest(s.now.Add(-s.minutes(60, 180)))
+	statuses := []models.CustomerServiceRequestStatus{
+		models.RequestStatusAccepted, models.RequestStatusEnRoute, models.RequestStatusInProgress,
+	}
+	p := &requestPlan{worker: s.pickWorker(r.CategoryID, true), messages: 2 + s.rng.Intn(4)}
+	if p.worker < 0 {
+		// Every worker is at capacity; leave it open instead
+		return s.openRequest()
+	}
+	s.fillTimeline(&r, statuses[s.rng.Intn(len(statuses))])
+	s.workers[p.worker].ActiveRequests++
+	r.UpdatedAt = s.now
+	p.request = r
+	return p
+}
+
+func (s *seeder) baseRequest(createdAt time.Time) models.CustomerServiceRequest {
+	customer := s.customers[s.rng.Intn(len(s.customers))]
+	category := s.categories[s.rng.Intn(len(s.categories))]
+	lat, lng := s.randomPoint()
+	budget := money.FromFloat(float64(15+s.rng.Intn(136)) * 100)
+	hood := s.neighbourhood()
+	return models.CustomerServiceRequest{
+		CustomerID:        customer.ID,
+		CategoryID:        category.ID,
+		Title:             category.Name + " - " + requestIssues[s.rng.Intn(len(requestIssues))],
+		Description:       requestDescriptions[s.rng.Intn(len(requestDescriptions))],
+		Priority:          s.priority(),
+		Budget:            &budget,
+		EstimatedDuration: []duration.Minutes{60, 120, 180, 240}[s.rng.Intn(4)],
+		LocationAddress:   fmt.Sprintf("%s, %s", hood, s.area.City),
+		LocationCity:      s.area.City,
+		GeocodedAddress:   fmt.Sprintf("%s, %s, Mauritanie", hood, s.area.City),
+		SurgeMultiplier:   1,
+		LocationLat:       &lat,
+		LocationLng:       &lng,
+		DispatchMode:      models.DispatchModeBroadcast,
+		CreatedAt:         createdAt,
+	}
+}
+
+// fillTimeline sets the timestamps a request has on reaching status
+func (s *seeder) fillTimeline(r *models.CustomerServiceRequest, status models.CustomerServiceRequestStatus) {
+	r.Status = status
+	accepted := r.CreatedAt.Add(s.minutes(1, 30))
+	r.AcceptedAt = &accepted
+	if status == models.RequestStatusAccepted {
+		return
+	}
+	enRoute := accepted.Add(s.minutes(1, 10))
+	r.EnRouteAt = &enRoute
+	if status == models.RequestStatusEnRoute {
+		eta := 5 + s.rng.Intn(25)
+		r.EtaMinutes = &eta
+		r.EtaUpdatedAt = &s.now
+		return
+	}
+	arrived := enRoute.Add(s.minutes(10, 45))
+	started := arrived.Add(s.minutes(0, 10))
+	r.ArrivedAt = &arrived
+	r.StartedAt = &started
+	if status == models.RequestStatusInProgress {
+		r.TimerState = models.WorkTimerRunning
+		r.TimerStartedAt = &started
+		return
+	}
+	worked := int(s.minutes(30, 240).Seconds())
+	completed := started.Add(time.Duration(worked) * time.Second)
+	r.TimerState = models.WorkTimerStopped
+	r.WorkedSeconds = worked
+	r.CompletedAt = &completed
+}
+
+// pickWorker returns a worker in the category, or any worker when the category has none; with
+// needsCapacity only workers below their concurrent job limit qualify. -1 when none does.
+func (s *seeder) pickWorker(categoryID uint, needsCapacity bool) int {
+	candidates := s.workersByCategory[categoryID]
+	if len(candidates) == 0 {
+		candidates = make([]int, len(s.workers))
+		for i := range s.workers {
+			candidates[i] = i
+		}
+	}
+	start := s.rng.Intn(len(candidates))
+	for n := 0; n < len(candidates); n++ {
+		i := candidates[(start+n)%len(candidates)]
+		if !needsCapacity || s.workers[i].ActiveRequests < s.workers[i].MaxConcurrentJobs {
+			return i
+		}
+	}
+	return -1
+}
+
+// recordStats adds a finished request to the workers' analytics
+func (s *seeder) recordStats(r models.CustomerServiceRequest, p *requestPlan) {
+	switch r.Status {
+	case models.RequestStatusCompleted:
+		w := s.workers[p.worker]
+		s.stats.received(w.ID, r.CreatedAt)
+		s.stats.responded(w.ID, *r.AcceptedAt, r.AcceptedAt.Sub(r.CreatedAt).Minutes())
+		s.stats.travelled(w.ID, *r.ArrivedAt, r.ArrivedAt.Sub(*r.EnRouteAt).Minutes())
+		s.stats.completed(w.ID, *r.CompletedAt, *r.Budget, float64(r.WorkedSeconds)/3600, p.stars)
+	case models.RequestStatusCancelled:
+		if p.worker >= 0 {
+			w := s.workers[p.worker]
+			s.stats.received(w.ID, r.CreatedAt)
+			s.stats.responded(w.ID, *r.AcceptedAt, r.AcceptedAt.Sub(r.CreatedAt).Minutes())
+		}
+	case models.RequestStatusExpired:
+		// Seen and passed on by a worker in the category
+		w := s.workers[s.pickWorker(r.CategoryID, false)]
+		s.stats.received(w.ID, r.CreatedAt)
+		s.stats.declined(w.ID, r.CreatedAt)
+	}
+}
+
+func (s *seeder) createHistories(tx *gorm.DB, plans []*requestPlan) error {
+	histories := []models.ServiceHistory{}
+	for _, p := range plans {
+		r := p.request
+		if r.Status != models.RequestStatusCompleted {
+			continue
+		}
+		minutes := int(math.Ceil(float64(r.WorkedSeconds) / 60))
+		h := models.ServiceHistory{
+			ServiceRequestID:  r.ID,
+			WorkerID:          s.workers[p.worker].ID,
+			CustomerID:        r.CustomerID,
+			CategoryID:        r.CategoryID,
+			Title:             r.Title,
+			Description:       r.Description,
+			Priority:          r.Priority,
+			Budget:            r.Budget,
+			EstimatedDuration: r.EstimatedDuration,
+			ActualDuration:    &minutes,
+			LocationAddress:   r.LocationAddress,
+			LocationCity:      r.LocationCity,
+			LocationLat:       r.LocationLat,
+			LocationLng:       r.LocationLng,
+			RequestCreatedAt:  r.CreatedAt,
+			AssignedAt:        r.AcceptedAt,
+			StartedAt:         r.StartedAt,
+			CompletedAt:       *r.CompletedAt,
+			AgreedPrice:       r.Budget,
+			FinalPrice:        r.Budget,
+			PaymentStatus:     models.PaymentStatusPaid,
+			CommissionPercent: s.commission,
+			CreatedAt:         *r.CompletedAt,
+			UpdatedAt:         *r.CompletedAt,
+		}
+		if p.stars > 0 {
+			stars := p.stars
+			h.CustomerSatisfaction = &stars
+			h.WorkQuality = &stars
+		}
+		histories = append(histories, h)
+	}
+	if err := tx.CreateInBatches(&histories, batchSize).Error; err != nil {
+		return err
+	}
+	s.summary.Histories = len(histories)
+	return nil
+}
+
+func (s *seeder) createRatings(tx *gorm.DB, plans []*requestPlan) error {
+	ratings := []models.WorkerRating{}
+	for _, p := range plans {
+		if p.stars == 0 {
+			continue
+		}
+		r := p.request
+		rated := r.CompletedAt.Add(s.minutes(5, 24*60))
+		ratings = append(ratings, models.WorkerRating{
+			CustomerID:       r.CustomerID,
+			WorkerID:         s.workers[p.worker].ID,
+			ServiceRequestID: r.ID,
+			Stars:            p.stars,
+			Comment:          ratingComments[p.stars][s.rng.Intn(len(ratingComments[p.stars]))],
+			ServiceQuality:   s.nearStars(p.stars),
+			Professionalism:  s.nearStars(p.stars),
+			Punctuality:      s.nearStars(p.stars),
+			Communication:    s.nearStars(p.stars),
+			IsAnonymous:      s.rng.Float64() < 0.1,
+			IsVerified:       true,
+			ModerationStatus: models.ReviewVisible,
+			CreatedAt:        rated,
+			UpdatedAt:        rated,
+		})
+	}
+	if err := tx.CreateInBatches(&ratings, batchSize).Error; err != nil {
+		return err
+	}
+	s.summary.Ratings = len(ratings)
+	return nil
+}
+
+func (s *seeder) createChats(tx *gorm.DB, plans []*requestPlan) error {
+	rooms := []models.ChatRoom{}
+	roomPlans := []*requestPlan{}
+	for _, p := range plans {
+		if p.worker < 0 || p.messages == 0 {
+			continue
+		}
+		r := p.request
+		rooms = append(rooms, models.ChatRoom{
+			CustomerID:       r.CustomerID,
+			WorkerID:         s.workerUserIDs[s.workers[p.worker].ID],
+			ServiceRequestID: r.ID,
+			IsActive:         r.Status.IsActive(),
+			CreatedAt:        *r.AcceptedAt,
+			UpdatedAt:        *r.AcceptedAt,
+		})
+		roomPlans = append(roomPlans, p)
+	}
+
+	// Messages run from acceptance to completion, or to now for live jobs
+	messagesByRoom := make([][]models.ChatMessage, len(rooms))
+	for i, p := range roomPlans {
+		r := p.request
+		end := s.now
+		if r.CompletedAt != nil {
+			end = *r.CompletedAt
+		} else if r.Status == models.RequestStatusCancelled {
+			end = r.AcceptedAt.Add(s.minutes(5, 60))
+		}
+		at := *r.AcceptedAt
+		step := end.Sub(at) / time.Duration(p.messages+1)
+		finished := !r.Status.IsActive()
+		for n := 0; n < p.messages; n++ {
+			at = at.Add(step)
+			sender, senderID := "customer", r.CustomerID
+			if n%2 == 0 {
+				sender, senderID = "worker", rooms[i].WorkerID
+			}
+			text := chatLines[sender][s.rng.Intn(len(chatLines[sender]))]
+			sent := at
+			messagesByRoom[i] = append(messagesByRoom[i], models.ChatMessage{
+				SenderID:    senderID,
+				SenderType:  sender,
+				Content:     text,
+				MessageType: "text",
+				DeliveredAt: readAt(finished || n < p.messages-1, sent.Add(time.Minute)),
+				ReadAt:      readAt(finished || n < p.messages-1, sent.Add(time.Minute)),
+				CreatedAt:   sent,
+				UpdatedAt:   sent,
+			})
+		}
+		last := messagesByRoom[i][len(messagesByRoom[i])-1]
+		rooms[i].LastMessageAt = &last.CreatedAt
+		rooms[i].LastMessageText = last.Content
+		rooms[i].UpdatedAt = last.CreatedAt
+		if last.ReadAt == nil {
+			rooms[i].UnreadCount = 1
+		}
+	}
+	if err := tx.CreateInBatches(&rooms, batchSize).Error; err != nil {
+		return err
+	}
+
+	messages := []models.ChatMessage{}
+	for i, room := range rooms {
+		for _, m := range messagesByRoom[i] {
+			m.ChatRoomID = room.ID
+			messages = append(messages, m)
+		}
+	}
+	if err := tx.CreateInBatches(&messages, batchSize).Error; err != nil {
+		return err
+	}
+	s.summary.ChatRooms = len(rooms)
+	s.summary.Messages = len(messages)
+	return nil
+}
+
+func readAt(read bool, at time.Time) *time.Time {
+	if !read {
+		return nil
+	}
+	return &at
+}
+
+// updateWorkerCounters stores the job counts and rating each worker's history adds up to
+func (s *seeder) updateWorkerCounters(tx *gorm.DB) error {
+	for _, w := range s.workers {
+		totals := s.stats.lifetime(w.ID)
+		if err := tx.Model(&models.WorkerProfile{}).Where("id = ?", w.ID).Updates(map[string]interface{}{
+			"completed_jobs":  totals.TotalJobsCompleted,
+			"rating":          totals.AverageRating,
+			"total_reviews":   totals.TotalRatings,
+			"active_requests": w.ActiveRequests,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// randomPoint returns a location inside the service area, uniformly over its area
+func (s *seeder) randomPoint() (float64, float64) {
+	distance := s.area.RadiusKm * 0.9 * math.Sqrt(s.rng.Float64())
+	bearing := s.rng.Float64() * 2 * math.Pi
+	lat := s.area.Lat + distance/111.32*math.Cos(bearing)
+	lng := s.area.Lng + distance/(111.32*math.Cos(s.area.Lat*math.Pi/180))*math.Sin(bearing)
+	return lat, lng
+}
+
+func (s *seeder) randomTime(from, to time.Time) time.Time {
+	return from.Add(time.Duration(s.rng.Int63n(int64(to.Sub(from)))))
+}
+
+// workingHoursTime is a random time between 8:00 and 21:00, when most requests are made
+func (s *seeder) workingHoursTime(from, to time.Time) time.Time {
+	for {
+		t := s.randomTime(from, to)
+		if t.Hour() >= 8 && t.Hour() < 21 {
+			return t
+		}
+	}
+}
+
+func (s *seeder) minutes(min, max int) time.Duration {
+	return time.Duration(min+s.rng.Intn(max-min+1)) * time.Minute
+}
+
+func (s *seeder) priority() string {
+	roll := s.rng.Float64()
+	switch {
+	case roll < 0.25:
+		return "low"
+	case roll < 0.75:
+		return "medium"
+	case roll < 0.93:
+		return "high"
+	}
+	return "urgent"
+}
+
+// stars follows the usual skew of marketplace reviews towards 5
+func (s *seeder) stars() int {
+	roll := s.rng.Float64()
+	switch {
+	case roll < 0.55:
+		return 5
+	case roll < 0.83:
+		return 4
+	case roll < 0.93:
+		return 3
+	case roll < 0.97:
+		return 2
+	}
+	return 1
+}
+
+// nearStars is a detailed score within one star of the overall one
+func (s *seeder) nearStars(stars int) int {
+	score := stars + s.rng.Intn(3) - 1
+	if score < 1 {
+		return 1
+	}
+	if score > 5 {
+		return 5
+	}
+	return score
+}
+
+func (s *seeder) fullName() string {
+	return firstNames[s.rng.Intn(len(firstNames))] + " " + lastNames[s.rng.Intn(len(lastNames))]
+}
+
+func (s *seeder) neighbourhood() string {
+	hoods, ok := neighbourhoods[s.area.City]
+	if !ok {
+		return "Centre"
+	}
+	return hoods[s.rng.Intn(len(hoods))]
+}
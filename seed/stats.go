@@ -0,0 +1,239 @@
+package seed
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// statsBuilder adds up the daily, monthly and lifetime worker analytics that the tracking calls
+// in the request handlers would have written for the generated history
+type statsBuilder struct {
+	now       time.Time
+	daily     map[dayKey]*models.WorkerDailyStats
+	ratingSum map[dayKey]int // Stars given for jobs completed that day
+	rated     map[dayKey]int
+	lastDone  map[uint]time.Time
+}
+
+type dayKey struct {
+	workerID uint
+	day      time.Time
+}
+
+func newStatsBuilder(now time.Time) *statsBuilder {
+	return &statsBuilder{
+		now:       now,
+		daily:     map[dayKey]*models.WorkerDailyStats{},
+		ratingSum: map[dayKey]int{},
+		rated:     map[dayKey]int{},
+		lastDone:  map[uint]time.Time{},
+	}
+}
+
+func (b *statsBuilder) day(workerID uint, at time.Time) (dayKey, *models.WorkerDailyStats) {
+	key := dayKey{workerID, time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())}
+	stats, ok := b.daily[key]
+	if !ok {
+		stats = &models.WorkerDailyStats{WorkerID: workerID, Date: key.day, CreatedAt: at, UpdatedAt: at}
+		b.daily[key] = stats
+	}
+	return key, stats
+}
+
+func (b *statsBuilder) received(workerID uint, at time.Time) {
+	_, d := b.day(workerID, at)
+	d.JobsReceived++
+}
+
+func (b *statsBuilder) responded(workerID uint, at time.Time, responseMinutes float64) {
+	_, d := b.day(workerID, at)
+	d.JobsResponded++
+	d.TotalResponseTime += responseMinutes
+	d.JobsWithResponse++
+}
+
+func (b *statsBuilder) declined(workerID uint, at time.Time) {
+	_, d := b.day(workerID, at)
+	d.JobsDeclined++
+}
+
+func (b *statsBuilder) travelled(workerID uint, at time.Time, travelMinutes float64) {
+	_, d := b.day(workerID, at)
+	d.TotalTravelTime += travelMinutes
+	d.JobsWithTravel++
+}
+
+func (b *statsBuilder) completed(workerID uint, at time.Time, earnings money.Amount, workHours float64, stars int) {
+	key, d := b.day(workerID, at)
+	d.JobsCompleted++
+	d.Earnings += earnings
+	d.WorkHours += workHours
+	if stars > 0 {
+		b.ratingSum[key] += stars
+		b.rated[key]++
+		d.AverageRating = float64(b.ratingSum[key]) / float64(b.rated[key])
+	}
+	if at.After(b.lastDone[workerID]) {
+		b.lastDone[workerID] = at
+	}
+}
+
+// lifetime totals a worker's daily stats into their lifetime row
+func (b *statsBuilder) lifetime(workerID uint) models.WorkerStats {
+	stats := models.WorkerStats{WorkerID: workerID, CreatedAt: b.now, UpdatedAt: b.now}
+	var responseTime, travelTime float64
+	var withResponse, ratingSum int
+	year, month, _ := b.now.Date()
+	for key, d := range b.daily {
+		if key.workerID != workerID {
+			continue
+		}
+		stats.TotalJobsReceived += d.JobsReceived
+		stats.TotalJobsResponded += d.JobsResponded
+		stats.TotalJobsCompleted += d.JobsCompleted
+		stats.TotalJobsDeclined += d.JobsDeclined
+		stats.TotalEarnings += d.Earnings
+		stats.TotalWorkHours += d.WorkHours
+		responseTime += d.TotalResponseTime
+		withResponse += d.JobsWithResponse
+		travelTime += d.TotalTravelTime
+		stats.TotalTravels += d.JobsWithTravel
+		ratingSum += b.ratingSum[key]
+		stats.TotalRatings += b.rated[key]
+		if y, m, _ := key.day.Date(); y == year && m == month {
+			stats.MonthlyJobsReceived += d.JobsReceived
+			stats.MonthlyJobsResponded += d.JobsResponded
+			stats.MonthlyJobsCompleted += d.JobsCompleted
+			stats.MonthlyJobsDeclined += d.JobsDeclined
+			stats.MonthlyEarnings += d.Earnings
+			stats.MonthlyWorkHours += d.WorkHours
+		}
+		if key.day.Equal(time.Date(year, month, b.now.Day(), 0, 0, 0, 0, b.now.Location())) {
+			stats.DailyJobsReceived = d.JobsReceived
+			stats.DailyJobsResponded = d.JobsResponded
+			stats.DailyJobsCompleted = d.JobsCompleted
+			stats.DailyJobsDeclined = d.JobsDeclined
+			stats.DailyEarnings = d.Earnings
+			stats.DailyWorkHours = d.WorkHours
+		}
+	}
+	if stats.TotalJobsReceived > 0 {
+		stats.ResponseRate = float64(stats.TotalJobsResponded) / float64(stats.TotalJobsReceived) * 100
+	}
+	if stats.TotalJobsResponded > 0 {
+		stats.CompletionRate = float64(stats.TotalJobsCompleted) / float64(stats.TotalJobsResponded) * 100
+	}
+	if withResponse > 0 {
+		stats.AverageResponseTime = responseTime / float64(withResponse)
+	}
+	if stats.TotalTravels > 0 {
+		stats.AverageTravelTime = travelTime / float64(stats.TotalTravels)
+	}
+	if stats.TotalJobsCompleted > 0 {
+		stats.AverageJobDuration = stats.TotalWorkHours / float64(stats.TotalJobsCompleted)
+		stats.AverageEarningsPerJob = stats.TotalEarnings.Div(int64(stats.TotalJobsCompleted))
+	}
+	if stats.TotalRatings > 0 {
+		stats.AverageRating = float64(ratingSum) / float64(stats.TotalRatings)
+	}
+	if last, ok := b.lastDone[workerID]; ok {
+		stats.LastJobCompleted = &last
+		stats.LastEarning = &last
+	}
+	return stats
+}
+
+// monthly totals the daily stats by worker and month
+func (b *statsBuilder) monthly() []models.WorkerMonthlyStats {
+	type monthKey struct {
+		workerID    uint
+		year, month int
+	}
+	months := map[monthKey]*models.WorkerMonthlyStats{}
+	ratingSums := map[monthKey]int{}
+	rated := map[monthKey]int{}
+	responseTimes := map[monthKey]float64{}
+	withResponse := map[monthKey]int{}
+	for key, d := range b.daily {
+		mk := monthKey{key.workerID, key.day.Year(), int(key.day.Month())}
+		m, ok := months[mk]
+		if !ok {
+			m = &models.WorkerMonthlyStats{WorkerID: mk.workerID, Year: mk.year, Month: mk.month, CreatedAt: b.now, UpdatedAt: b.now}
+			months[mk] = m
+		}
+		m.JobsReceived += d.JobsReceived
+		m.JobsResponded += d.JobsResponded
+		m.JobsCompleted += d.JobsCompleted
+		m.JobsDeclined += d.JobsDeclined
+		m.Earnings += d.Earnings
+		m.WorkHours += d.WorkHours
+		ratingSums[mk] += b.ratingSum[key]
+		rated[mk] += b.rated[key]
+		responseTimes[mk] += d.TotalResponseTime
+		withResponse[mk] += d.JobsWithResponse
+	}
+
+	out := make([]models.WorkerMonthlyStats, 0, len(months))
+	for mk, m := range months {
+		if m.JobsReceived > 0 {
+			m.ResponseRate = float64(m.JobsResponded) / float64(m.JobsReceived) * 100
+		}
+		if m.JobsResponded > 0 {
+			m.CompletionRate = float64(m.JobsCompleted) / float64(m.JobsResponded) * 100
+		}
+		if withResponse[mk] > 0 {
+			m.AverageResponseTime = responseTimes[mk] / float64(withResponse[mk])
+		}
+		if m.JobsCompleted > 0 {
+			m.AverageJobDuration = m.WorkHours / float64(m.JobsCompleted)
+			m.AverageEarningsPerJob = m.Earnings.Div(int64(m.JobsCompleted))
+		}
+		if rated[mk] > 0 {
+			m.AverageRating = float64(ratingSums[mk]) / float64(rated[mk])
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].WorkerID != out[j].WorkerID {
+			return out[i].WorkerID < out[j].WorkerID
+		}
+		return out[i].Year*12+out[i].Month < out[j].Year*12+out[j].Month
+	})
+	return out
+}
+
+// save writes the daily, monthly and lifetime rows
+func (b *statsBuilder) save(tx *gorm.DB) error {
+	daily := make([]models.WorkerDailyStats, 0, len(b.daily))
+	workers := map[uint]bool{}
+	for _, d := range b.daily {
+		daily = append(daily, *d)
+		workers[d.WorkerID] = true
+	}
+	sort.Slice(daily, func(i, j int) bool {
+		if daily[i].WorkerID != daily[j].WorkerID {
+			return daily[i].WorkerID < daily[j].WorkerID
+		}
+		return daily[i].Date.Before(daily[j].Date)
+	})
+	if err := tx.CreateInBatches(&daily, batchSize).Error; err != nil {
+		return err
+	}
+
+	monthly := b.monthly()
+	if err := tx.CreateInBatches(&monthly, batchSize).Error; err != nil {
+		return err
+	}
+
+	lifetime := make([]models.WorkerStats, 0, len(workers))
+	for workerID := range workers {
+		lifetime = append(lifetime, b.lifetime(workerID))
+	}
+	sort.Slice(lifetime, func(i, j int) bool { return lifetime[i].WorkerID < lifetime[j].WorkerID })
+	return tx.CreateInBatches(&lifetime, batchSize).Error
+}
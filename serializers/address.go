@@ -0,0 +1,111 @@
+package serializers
+
+import (
+	"fmt"
+	"time"
+
+	"repair-service-server/models"
+)
+
+// AddressResponse is a saved customer address
+type AddressResponse struct {
+	ID             uint      `json:"id"`
+	UserID         uint      `json:"user_id"`
+	Label          string    `json:"label"`
+	AddressDetails string    `json:"address_details"`
+	City           string    `json:"city"`
+	EntranceNotes  string    `json:"entrance_notes"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	IsDefault      bool      `json:"is_default"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Address serializes an address
+func Address(a models.Address) AddressResponse {
+	return AddressResponse{
+		ID:             a.ID,
+		UserID:         a.UserID,
+		Label:          a.Label,
+		AddressDetails: a.AddressDetails,
+		City:           a.City,
+		EntranceNotes:  a.EntranceNotes,
+		Latitude:       a.Latitude,
+		Longitude:      a.Longitude,
+		IsDefault:      a.IsDefault,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+}
+
+// Addresses serializes a list of addresses
+func Addresses(addresses []models.Address) []AddressResponse {
+	out := make([]AddressResponse, 0, len(addresses))
+	for _, a := range addresses {
+		out = append(out, Address(a))
+	}
+	return out
+}
+
+// AddressShareResponse is a link to an address as its owner sees it
+type AddressShareResponse struct {
+	ID               uint       `json:"id"`
+	AddressID        uint       `json:"address_id"`
+	ServiceRequestID *uint      `json:"service_request_id"`
+	URL              string     `json:"url,omitempty"` // Only returned when the link is created
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	AccessCount      int        `json:"access_count"`
+	LastAccessedAt   *time.Time `json:"last_accessed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// AddressShare serializes an address link for its owner
+func AddressShare(s models.AddressShare, url string) AddressShareResponse {
+	return AddressShareResponse{
+		ID:               s.ID,
+		AddressID:        s.AddressID,
+		ServiceRequestID: s.ServiceRequestID,
+		URL:              url,
+		ExpiresAt:        s.ExpiresAt,
+		RevokedAt:        s.RevokedAt,
+		AccessCount:      s.AccessCount,
+		LastAccessedAt:   s.LastAccessedAt,
+		CreatedAt:        s.CreatedAt,
+	}
+}
+
+// AddressShares serializes the links of an address
+func AddressShares(shares []models.AddressShare) []AddressShareResponse {
+	out := make([]AddressShareResponse, 0, len(shares))
+	for _, s := range shares {
+		out = append(out, AddressShare(s, ""))
+	}
+	return out
+}
+
+// SharedLocationResponse is the location behind an address link, as the worker opening it sees it
+type SharedLocationResponse struct {
+	Label          string    `json:"label"`
+	AddressDetails string    `json:"address_details"`
+	City           string    `json:"city"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	MapsURL        string    `json:"maps_url"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// SharedLocation serializes the address behind a link, without its owner
+func SharedLocation(s models.AddressShare) SharedLocationResponse {
+	a := s.Address
+	return SharedLocationResponse{
+		Label:          a.Label,
+		AddressDetails: a.AddressDetails,
+		City:           a.City,
+		Latitude:       a.Latitude,
+		Longitude:      a.Longitude,
+		MapsURL:        fmt.Sprintf("https://maps.google.com/?q=%.6f,%.6f", a.Latitude, a.Longitude),
+		ExpiresAt:      s.ExpiresAt,
+	}
+}
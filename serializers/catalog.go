@@ -0,0 +1,54 @@
+package serializers
+
+import (
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// CategoryResponse is a service category
+type CategoryResponse struct {
+	ID          uint   `json:"id"`
+	ParentID    *uint  `json:"parent_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Color       string `json:"color"`
+	IsActive    bool   `json:"is_active"`
+	IsNew       bool   `json:"is_new"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+// Category serializes a category; nil when it is not loaded
+func Category(c models.ServiceCategory) *CategoryResponse {
+	if c.ID == 0 {
+		return nil
+	}
+	return &CategoryResponse{
+		ID:          c.ID,
+		ParentID:    c.ParentID,
+		Name:        c.Name,
+		Description: c.Description,
+		Icon:        c.Icon,
+		Color:       c.Color,
+		IsActive:    c.IsActive,
+		IsNew:       c.IsNew,
+		SortOrder:   c.SortOrder,
+	}
+}
+
+// ServiceOptionSummary is the option a customer picked for a request
+type ServiceOptionSummary struct {
+	ID       uint         `json:"id"`
+	Title    string       `json:"title"`
+	Price    money.Amount `json:"price"`
+	Duration int          `json:"duration"`
+	ImageURL string       `json:"image_url"`
+}
+
+// ServiceOptionSummaryOf serializes a selected option; nil when there is none
+func ServiceOptionSummaryOf(o *models.ServiceOption) *ServiceOptionSummary {
+	if o == nil || o.ID == 0 {
+		return nil
+	}
+	return &ServiceOptionSummary{ID: o.ID, Title: o.Title, Price: o.Price, Duration: o.Duration, ImageURL: o.ImageURL}
+}
@@ -0,0 +1,147 @@
+package serializers
+
+import (
+	"time"
+
+	"repair-service-server/mediaurl"
+	"repair-service-server/models"
+)
+
+// ChatRoomResponse is a chat room between a customer and the worker on a request
+type ChatRoomResponse struct {
+	ID               uint         `json:"id"`
+	CustomerID       uint         `json:"customer_id"`
+	WorkerID         uint         `json:"worker_id"`
+	ServiceRequestID uint         `json:"service_request_id"`
+	Customer         *UserSummary `json:"customer,omitempty"`
+	Worker           *UserSummary `json:"worker,omitempty"`
+	ServiceRequest   *RequestRef  `json:"service_request,omitempty"`
+	LastMessageAt    *time.Time   `json:"last_message_at"`
+	LastMessageText  string       `json:"last_message_text"`
+	UnreadCount      int          `json:"unread_count"`
+	IsActive         bool         `json:"is_active"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+	// PinnedMessages is only filled in a room's details
+	PinnedMessages []ChatMessageResponse `json:"pinned_messages,omitempty"`
+}
+
+// RequestRef points at the service request a chat room belongs to
+type RequestRef struct {
+	ID     uint                                `json:"id"`
+	Title  string                              `json:"title"`
+	Status models.CustomerServiceRequestStatus `json:"status"`
+}
+
+// RequestRefOf references a request; nil when it is not loaded
+func RequestRefOf(r models.CustomerServiceRequest) *RequestRef {
+	if r.ID == 0 {
+		return nil
+	}
+	return &RequestRef{ID: r.ID, Title: r.Title, Status: r.Status}
+}
+
+// ChatMessageResponse is a single chat message
+type ChatMessageResponse struct {
+	ID               uint               `json:"id"`
+	ChatRoomID       uint               `json:"chat_room_id"`
+	SenderID         uint               `json:"sender_id"`
+	SenderType       string             `json:"sender_type"`
+	Content          string             `json:"content"`
+	MessageText      string             `json:"message_text"` // Deprecated: copy of Content for clients that read the old field
+	MessageType      string             `json:"message_type"`
+	AudioURL         string             `json:"audio_url"`
+	Image            *mediaurl.Variants `json:"image_variants,omitempty"` // Sizes of the image an image message links to
+	Duration         int                `json:"duration"`
+	Transcript       string             `json:"transcript,omitempty"`
+	TranscriptStatus string             `json:"transcript_status,omitempty"`
+	Status           string             `json:"status"`  // sent, delivered or read
+	IsRead           bool               `json:"is_read"` // Deprecated: use status
+	DeliveredAt      *time.Time         `json:"delivered_at"`
+	ReadAt           *time.Time         `json:"read_at"`
+	PinnedAt         *time.Time         `json:"pinned_at,omitempty"`
+	PinnedBy         *uint              `json:"pinned_by,omitempty"`
+	Latitude         *float64           `json:"latitude,omitempty"`
+	Longitude        *float64           `json:"longitude,omitempty"`
+	LiveUntil        *time.Time         `json:"live_until,omitempty"`
+	LiveEndedAt      *time.Time         `json:"live_ended_at,omitempty"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// ChatRoom serializes a chat room
+func ChatRoom(r models.ChatRoom) ChatRoomResponse {
+	return ChatRoomResponse{
+		ID:               r.ID,
+		CustomerID:       r.CustomerID,
+		WorkerID:         r.WorkerID,
+		ServiceRequestID: r.ServiceRequestID,
+		Customer:         UserSummaryOf(r.Customer),
+		Worker:           UserSummaryOf(r.Worker),
+		ServiceRequest:   RequestRefOf(r.ServiceRequest),
+		LastMessageAt:    r.LastMessageAt,
+		LastMessageText:  r.LastMessageText,
+		UnreadCount:      r.UnreadCount,
+		IsActive:         r.IsActive,
+		CreatedAt:        r.CreatedAt,
+		UpdatedAt:        r.UpdatedAt,
+	}
+}
+
+// ChatRooms serializes a list of chat rooms
+func ChatRooms(rooms []models.ChatRoom) []ChatRoomResponse {
+	out := make([]ChatRoomResponse, 0, len(rooms))
+	for _, r := range rooms {
+		out = append(out, ChatRoom(r))
+	}
+	return out
+}
+
+// ChatMessage serializes a chat message
+func ChatMessage(m models.ChatMessage) ChatMessageResponse {
+	resp := ChatMessageResponse{
+		ID:               m.ID,
+		ChatRoomID:       m.ChatRoomID,
+		SenderID:         m.SenderID,
+		SenderType:       m.SenderType,
+		Content:          m.Content,
+		MessageText:      m.Content,
+		MessageType:      m.MessageType,
+		AudioURL:         m.AudioURL,
+		Duration:         m.Duration,
+		Transcript:       m.Transcript,
+		TranscriptStatus: m.TranscriptStatus,
+		Status:           m.DeliveryStatus(),
+		IsRead:           m.ReadAt != nil,
+		DeliveredAt:      m.DeliveredAt,
+		ReadAt:           m.ReadAt,
+		PinnedAt:         m.PinnedAt,
+		PinnedBy:         m.PinnedBy,
+		Latitude:         m.Latitude,
+		Longitude:        m.Longitude,
+		LiveUntil:        m.LiveUntil,
+		LiveEndedAt:      m.LiveEndedAt,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+	if m.MessageType == "image" {
+		resp.Image = mediaurl.Image(m.Content)
+	}
+	return resp
+}
+
+// ChatSearchResult is a message matching a search, with the messages around it in its room
+type ChatSearchResult struct {
+	Message ChatMessageResponse   `json:"message"`
+	Before  []ChatMessageResponse `json:"before,omitempty"`
+	After   []ChatMessageResponse `json:"after,omitempty"`
+}
+
+// ChatMessages serializes a list of chat messages
+func ChatMessages(messages []models.ChatMessage) []ChatMessageResponse {
+	out := make([]ChatMessageResponse, 0, len(messages))
+	for _, m := range messages {
+		out = append(out, ChatMessage(m))
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package serializers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Fieldset is a sparse fieldset: the fields a client asked for as a tree of JSON keys. A nil
+// subtree keeps everything below it.
+type Fieldset map[string]Fieldset
+
+// NewFieldset builds a fieldset from dot-separated paths such as "customer.full_name". It
+// returns nil, meaning every field, when there are no paths.
+func NewFieldset(paths []string) Fieldset {
+	if len(paths) == 0 {
+		return nil
+	}
+	root := Fieldset{}
+	for _, path := range paths {
+		node := root
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			child, ok := node[part]
+			if i == len(parts)-1 {
+				// A shorter path keeps the whole subtree, even when a longer one was also given
+				node[part] = nil
+				break
+			}
+			if !ok {
+				child = Fieldset{}
+				node[part] = child
+			} else if child == nil {
+				break
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+// ParseFields reads a comma-separated fields parameter such as "id,status,customer.full_name"
+func ParseFields(raw string) Fieldset {
+	var paths []string
+	for _, path := range strings.Split(raw, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return NewFieldset(paths)
+}
+
+// Sparse trims the JSON form of value to fields, applying them to each element of a list.
+// Value is returned unchanged when fields is nil.
+func Sparse(value interface{}, fields Fieldset) interface{} {
+	if fields == nil {
+		return value
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	// Numbers are kept as written so large IDs and amounts are not rounded through float64
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return value
+	}
+	return Select(decoded, fields)
+}
+
+// Select keeps the keys of fields in a decoded JSON value, applying them to each element of arrays
+func Select(value interface{}, fields Fieldset) interface{} {
+	if fields == nil {
+		return value
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(fields))
+		for key, subtree := range fields {
+			if child, ok := v[key]; ok {
+				out[key] = Select(child, subtree)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, element := range v {
+			out[i] = Select(element, fields)
+		}
+		return out
+	}
+	return value
+}
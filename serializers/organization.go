@@ -0,0 +1,64 @@
+package serializers
+
+import (
+	"time"
+
+	"repair-service-server/models"
+)
+
+// OrganizationMemberResponse is a member of an organization, or a user invited to it
+type OrganizationMemberResponse struct {
+	ID             uint                            `json:"id"`
+	OrganizationID uint                            `json:"organization_id"`
+	User           *UserSummary                    `json:"user"`
+	Role           models.OrganizationRole         `json:"role"`
+	Status         models.OrganizationMemberStatus `json:"status"`
+	InvitedByID    *uint                           `json:"invited_by_id"`
+	JoinedAt       *time.Time                      `json:"joined_at"`
+	CreatedAt      time.Time                       `json:"created_at"`
+}
+
+// OrganizationMembershipResponse is one of the current user's organizations with their place in it
+type OrganizationMembershipResponse struct {
+	Organization *models.Organization            `json:"organization"`
+	Role         models.OrganizationRole         `json:"role"`
+	Status       models.OrganizationMemberStatus `json:"status"`
+	JoinedAt     *time.Time                      `json:"joined_at"`
+}
+
+// OrganizationMember serializes a member with their contact details, for the other members
+func OrganizationMember(m models.OrganizationMember) OrganizationMemberResponse {
+	return OrganizationMemberResponse{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		User:           UserContactOf(m.User),
+		Role:           m.Role,
+		Status:         m.Status,
+		InvitedByID:    m.InvitedByID,
+		JoinedAt:       m.JoinedAt,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+// OrganizationMembers serializes a list of members
+func OrganizationMembers(members []models.OrganizationMember) []OrganizationMemberResponse {
+	out := make([]OrganizationMemberResponse, 0, len(members))
+	for _, m := range members {
+		out = append(out, OrganizationMember(m))
+	}
+	return out
+}
+
+// OrganizationMemberships serializes the current user's memberships and pending invitations
+func OrganizationMemberships(members []models.OrganizationMember) []OrganizationMembershipResponse {
+	out := make([]OrganizationMembershipResponse, 0, len(members))
+	for _, m := range members {
+		out = append(out, OrganizationMembershipResponse{
+			Organization: m.Organization,
+			Role:         m.Role,
+			Status:       m.Status,
+			JoinedAt:     m.JoinedAt,
+		})
+	}
+	return out
+}
@@ -0,0 +1,243 @@
+package serializers
+
+import (
+	"fmt"
+	"time"
+
+	"repair-service-server/duration"
+	"repair-service-server/mediaurl"
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// ServiceZoneSummary names the zone a request falls in
+type ServiceZoneSummary struct {
+	ID              uint    `json:"id"`
+	Name            string  `json:"name"`
+	City            string  `json:"city"`
+	SurgeMultiplier float64 `json:"surge_multiplier"`
+}
+
+// AssignedWorkerResponse is the worker on a request, with the contact details the customer needs
+type AssignedWorkerResponse struct {
+	WorkerPublicResponse
+	PhoneNumber string       `json:"phone_number"`
+	User        *UserSummary `json:"user,omitempty"`
+}
+
+// WorkTimerResponse is the live state of the worker's job timer. Clients tick worked_seconds up
+// locally while state is running.
+type WorkTimerResponse struct {
+	State         models.WorkTimerState `json:"state"`
+	WorkedSeconds int                   `json:"worked_seconds"`
+	RunningSince  *time.Time            `json:"running_since"`
+}
+
+// WorkTimer serializes a request's work timer as of now
+func WorkTimer(r models.CustomerServiceRequest) WorkTimerResponse {
+	return WorkTimerResponse{
+		State:         r.TimerState,
+		WorkedSeconds: r.WorkedSecondsAt(time.Now()),
+		RunningSince:  r.TimerStartedAt,
+	}
+}
+
+// ServiceRequestResponse is a customer service request
+type ServiceRequestResponse struct {
+	ID                uint                                `json:"id"`
+	CustomerID        uint                                `json:"customer_id"`
+	OrganizationID    *uint                               `json:"organization_id"`
+	Customer          *UserSummary                        `json:"customer,omitempty"`
+	CategoryID        uint                                `json:"category_id"`
+	Category          *CategoryResponse                   `json:"category,omitempty"`
+	ServiceOptionID   *uint                               `json:"service_option_id"`
+	ServiceOption     *ServiceOptionSummary               `json:"service_option,omitempty"`
+	Title             string                              `json:"title"`
+	Description       string                              `json:"description"`
+	Priority          string                              `json:"priority"`
+	Budget            *money.Amount                       `json:"budget"`
+	AgreedPrice       *money.Amount                       `json:"agreed_price"`
+	Price             *money.Amount                       `json:"price"` // Charged for the work, before parts
+	EstimatedDuration duration.Minutes                    `json:"estimated_duration"`
+	AddressID         *uint                               `json:"address_id"`
+	LocationAddress   string                              `json:"location_address"`
+	LocationCity      string                              `json:"location_city"`
+	EntranceNotes     string                              `json:"entrance_notes"`
+	GeocodedAddress   string                              `json:"geocoded_address"`
+	LocationLat       *float64                            `json:"location_lat"`
+	LocationLng       *float64                            `json:"location_lng"`
+	ServiceZoneID     *uint                               `json:"service_zone_id"`
+	ServiceZone       *ServiceZoneSummary                 `json:"service_zone,omitempty"`
+	SurgeMultiplier   float64                             `json:"surge_multiplier"`
+	Status            models.CustomerServiceRequestStatus `json:"status"`
+	DispatchMode      models.DispatchMode                 `json:"dispatch_mode"`
+	AssignedWorkerID  *uint                               `json:"assigned_worker_id"`
+	AssignedWorker    *AssignedWorkerResponse             `json:"assigned_worker,omitempty"`
+	EnRouteAt         *time.Time                          `json:"en_route_at"`
+	ArrivedAt         *time.Time                          `json:"arrived_at"`
+	EtaMinutes        *int                                `json:"eta_minutes"`
+	EtaUpdatedAt      *time.Time                          `json:"eta_updated_at"`
+	StartedAt         *time.Time                          `json:"started_at"`
+	WorkTimer         WorkTimerResponse                   `json:"work_timer"`
+	CompletedAt       *time.Time                          `json:"completed_at"`
+	ExpiresAt         *time.Time                          `json:"expires_at"`
+	ScheduledFor      *time.Time                          `json:"scheduled_for"`
+	ScheduledForLocal *time.Time                          `json:"scheduled_for_local"` // At the customer's UTC offset
+	ScheduledTzOffset *int                                `json:"scheduled_tz_offset"`
+	CreatedAt         time.Time                           `json:"created_at"`
+	UpdatedAt         time.Time                           `json:"updated_at"`
+	PriceBreakdown    *models.PriceBreakdown              `json:"price_breakdown,omitempty"`
+
+	// Categories the request was also broadcast to, and which one the worker who took it serves
+	ExtraCategoryIDs    []uint `json:"extra_category_ids"`
+	FulfilledCategoryID *uint  `json:"fulfilled_category_id"`
+}
+
+// ServiceRequest serializes a request for its customer, the assigned worker or an admin
+func ServiceRequest(r models.CustomerServiceRequest) ServiceRequestResponse {
+	resp := ServiceRequestResponse{
+		ID:                r.ID,
+		CustomerID:        r.CustomerID,
+		OrganizationID:    r.OrganizationID,
+		Customer:          UserContactOf(r.Customer),
+		CategoryID:        r.CategoryID,
+		Category:          Category(r.Category),
+		ServiceOptionID:   r.ServiceOptionID,
+		ServiceOption:     ServiceOptionSummaryOf(r.ServiceOption),
+		Title:             r.Title,
+		Description:       r.Description,
+		Priority:          r.Priority,
+		Budget:            r.Budget,
+		AgreedPrice:       r.AgreedPrice,
+		Price:             r.Price(),
+		EstimatedDuration: r.EstimatedDuration,
+		AddressID:         r.AddressID,
+		LocationAddress:   r.LocationAddress,
+		LocationCity:      r.LocationCity,
+		EntranceNotes:     r.EntranceNotes,
+		GeocodedAddress:   r.GeocodedAddress,
+		LocationLat:       r.LocationLat,
+		LocationLng:       r.LocationLng,
+		ServiceZoneID:     r.ServiceZoneID,
+		SurgeMultiplier:   r.SurgeMultiplier,
+		Status:            r.Status,
+		DispatchMode:      r.DispatchMode,
+		AssignedWorkerID:  r.AssignedWorkerID,
+		EnRouteAt:         r.EnRouteAt,
+		ArrivedAt:         r.ArrivedAt,
+		EtaMinutes:        r.EtaMinutes,
+		EtaUpdatedAt:      r.EtaUpdatedAt,
+		StartedAt:         r.StartedAt,
+		WorkTimer:         WorkTimer(r),
+		CompletedAt:       r.CompletedAt,
+		ExpiresAt:         r.ExpiresAt,
+		ScheduledFor:      r.ScheduledFor,
+		ScheduledForLocal: r.ScheduledForLocal(),
+		ScheduledTzOffset: r.ScheduledTzOffset,
+		CreatedAt:         r.CreatedAt,
+		UpdatedAt:         r.UpdatedAt,
+		PriceBreakdown:    r.PriceBreakdown,
+
+		FulfilledCategoryID: r.FulfilledCategoryID,
+	}
+	for _, extra := range r.ExtraCategories {
+		resp.ExtraCategoryIDs = append(resp.ExtraCategoryIDs, extra.CategoryID)
+	}
+	if z := r.ServiceZone; z != nil && z.ID != 0 {
+		resp.ServiceZone = &ServiceZoneSummary{ID: z.ID, Name: z.Name, City: z.City, SurgeMultiplier: z.SurgeMultiplier}
+	}
+	if w := r.AssignedWorker; w != nil && w.ID != 0 {
+		resp.AssignedWorker = &AssignedWorkerResponse{
+			WorkerPublicResponse: WorkerPublic(*w),
+			PhoneNumber:          w.PhoneNumber,
+			User:                 UserContactOf(w.User),
+		}
+	}
+	return resp
+}
+
+// ServiceRequests serializes a list of requests
+func ServiceRequests(requests []models.CustomerServiceRequest) []ServiceRequestResponse {
+	out := make([]ServiceRequestResponse, 0, len(requests))
+	for _, r := range requests {
+		out = append(out, ServiceRequest(r))
+	}
+	return out
+}
+
+// RequestAttachmentResponse is a video attached to a request, with poster images for lists
+type RequestAttachmentResponse struct {
+	models.RequestAttachment
+	Posters *mediaurl.Variants `json:"poster_variants,omitempty"`
+}
+
+// RequestAttachments serializes a request's attached videos
+func RequestAttachments(attachments []models.RequestAttachment) []RequestAttachmentResponse {
+	out := make([]RequestAttachmentResponse, 0, len(attachments))
+	for _, a := range attachments {
+		out = append(out, RequestAttachmentResponse{RequestAttachment: a, Posters: mediaurl.VideoPoster(a.URL)})
+	}
+	return out
+}
+
+// NavigationResponse is what a worker's app needs to hand a job's destination to a maps app
+type NavigationResponse struct {
+	ServiceRequestID uint                                `json:"service_request_id"`
+	Status           models.CustomerServiceRequestStatus `json:"status"`
+	Destination      NavigationDestination               `json:"destination"`
+	Customer         NavigationContact                   `json:"customer"`
+	DeepLinks        NavigationLinks                     `json:"deep_links"`
+}
+
+// NavigationDestination is where a job takes place
+type NavigationDestination struct {
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+	Address       string  `json:"address"`
+	City          string  `json:"city"`
+	EntranceNotes string  `json:"entrance_notes"`
+}
+
+// NavigationContact is how the worker reaches the customer on the way, without their real number
+type NavigationContact struct {
+	Name           string     `json:"name"`
+	ProxyNumber    string     `json:"proxy_number,omitempty"` // Empty when calls are not available
+	ProxyExpiresAt *time.Time `json:"proxy_expires_at,omitempty"`
+}
+
+// NavigationLinks open turn-by-turn directions to the destination
+type NavigationLinks struct {
+	GoogleMaps string `json:"google_maps"`
+	Waze       string `json:"waze"`
+}
+
+// Navigation serializes a request with coordinates for its assigned worker's navigation; call
+// is the request's call session, nil when calls are not available
+func Navigation(r models.CustomerServiceRequest, call *models.CallSession) NavigationResponse {
+	lat, lng := *r.LocationLat, *r.LocationLng
+	address := r.LocationAddress
+	if address == "" {
+		address = r.GeocodedAddress
+	}
+	resp := NavigationResponse{
+		ServiceRequestID: r.ID,
+		Status:           r.Status,
+		Destination: NavigationDestination{
+			Latitude:      lat,
+			Longitude:     lng,
+			Address:       address,
+			City:          r.LocationCity,
+			EntranceNotes: r.EntranceNotes,
+		},
+		Customer: NavigationContact{Name: r.Customer.FullName},
+		DeepLinks: NavigationLinks{
+			GoogleMaps: fmt.Sprintf("https://www.google.com/maps/dir/?api=1&destination=%.6f,%.6f&travelmode=driving", lat, lng),
+			Waze:       fmt.Sprintf("https://waze.com/ul?ll=%.6f,%.6f&navigate=yes", lat, lng),
+		},
+	}
+	if call != nil {
+		resp.Customer.ProxyNumber = call.WorkerProxyNumber
+		resp.Customer.ProxyExpiresAt = &call.ExpiresAt
+	}
+	return resp
+}
@@ -0,0 +1,37 @@
+package serializers
+
+import (
+	"time"
+
+	"repair-service-server/models"
+)
+
+// SessionResponse is a signed-in device; the refresh token itself is never exposed
+type SessionResponse struct {
+	ID         uint       `json:"id"`
+	DeviceID   string     `json:"device_id"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	Current    bool       `json:"current"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+// Sessions serializes refresh tokens, flagging those issued to currentDeviceID
+func Sessions(tokens []models.RefreshToken, currentDeviceID string) []SessionResponse {
+	out := make([]SessionResponse, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, SessionResponse{
+			ID:         t.ID,
+			DeviceID:   t.DeviceID,
+			UserAgent:  t.UserAgent,
+			IPAddress:  t.IPAddress,
+			Current:    currentDeviceID != "" && t.DeviceID == currentDeviceID,
+			CreatedAt:  t.CreatedAt,
+			LastUsedAt: t.LastUsedAt,
+			ExpiresAt:  t.ExpiresAt,
+		})
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+// Package serializers converts GORM models into the response structs the API returns, so
+// handlers never expose database columns, soft-delete markers or unloaded relations directly.
+package serializers
+
+import (
+	"time"
+
+	"repair-service-server/mediaurl"
+	"repair-service-server/models"
+)
+
+// UserResponse is a user as seen by themselves or an admin
+type UserResponse struct {
+	ID                   uint               `json:"id"`
+	FullName             string             `json:"full_name"`
+	PhoneNumber          string             `json:"phone_number"`
+	Role                 models.UserRole    `json:"role"`
+	ProfilePictureURL    *string            `json:"profile_picture_url"`
+	ProfilePicture       *mediaurl.Variants `json:"profile_picture_variants,omitempty"`
+	IsActive             bool               `json:"is_active"`
+	PhoneVerifiedAt      *time.Time         `json:"phone_verified_at"`
+	Email                *string            `json:"email"`
+	EmailVerifiedAt      *time.Time         `json:"email_verified_at"`
+	DeletionScheduledFor *time.Time         `json:"deletion_scheduled_for,omitempty"`
+	PreferredLanguage    string             `json:"preferred_language"`
+	EmergencyContact     *EmergencyContact  `json:"emergency_contact"`
+	CreatedAt            time.Time          `json:"created_at"`
+	UpdatedAt            time.Time          `json:"updated_at"`
+}
+
+// EmergencyContact is who is texted when the user raises an SOS and asks for them to be told
+type EmergencyContact struct {
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+}
+
+// EmergencyContactOf returns the user's emergency contact, or nil when none is set
+func EmergencyContactOf(u models.User) *EmergencyContact {
+	if u.EmergencyContactPhone == nil || *u.EmergencyContactPhone == "" {
+		return nil
+	}
+	contact := &EmergencyContact{PhoneNumber: *u.EmergencyContactPhone}
+	if u.EmergencyContactName != nil {
+		contact.Name = *u.EmergencyContactName
+	}
+	return contact
+}
+
+// UserSummary identifies another party, such as the customer on a request or a chat peer
+type UserSummary struct {
+	ID                uint               `json:"id"`
+	FullName          string             `json:"full_name"`
+	PhoneNumber       string             `json:"phone_number,omitempty"`
+	ProfilePictureURL *string            `json:"profile_picture_url"`
+	ProfilePicture    *mediaurl.Variants `json:"profile_picture_variants,omitempty"`
+}
+
+// User serializes a user's own or admin view
+func User(u models.User) UserResponse {
+	return UserResponse{
+		ID:                   u.ID,
+		FullName:             u.FullName,
+		PhoneNumber:          u.PhoneNumber,
+		Role:                 u.Role,
+		ProfilePictureURL:    u.ProfilePictureURL,
+		ProfilePicture:       mediaurl.ImagePtr(u.ProfilePictureURL),
+		IsActive:             u.IsActive,
+		PhoneVerifiedAt:      u.PhoneVerifiedAt,
+		Email:                u.Email,
+		EmailVerifiedAt:      u.EmailVerifiedAt,
+		DeletionScheduledFor: u.DeletionScheduledFor,
+		PreferredLanguage:    u.PreferredLanguage,
+		EmergencyContact:     EmergencyContactOf(u),
+		CreatedAt:            u.CreatedAt,
+		UpdatedAt:            u.UpdatedAt,
+	}
+}
+
+// Users serializes a list of users
+func Users(users []models.User) []UserResponse {
+	out := make([]UserResponse, 0, len(users))
+	for _, u := range users {
+		out = append(out, User(u))
+	}
+	return out
+}
+
+// UserSummaryOf serializes another party without contact details; nil when the user is not loaded
+func UserSummaryOf(u models.User) *UserSummary {
+	if u.ID == 0 {
+		return nil
+	}
+	return &UserSummary{ID: u.ID, FullName: u.FullName, ProfilePictureURL: u.ProfilePictureURL, ProfilePicture: mediaurl.ImagePtr(u.ProfilePictureURL)}
+}
+
+// UserContactOf is UserSummaryOf plus the phone number, for parties on a shared job
+func UserContactOf(u models.User) *UserSummary {
+	summary := UserSummaryOf(u)
+	if summary != nil {
+		summary.PhoneNumber = u.PhoneNumber
+	}
+	return summary
+}
@@ -0,0 +1,244 @@
+package serializers
+
+import (
+	"strings"
+	"time"
+
+	"repair-service-server/mediaurl"
+	"repair-service-server/models"
+)
+
+// WorkerPublicResponse is a worker profile as shown to customers. Identity documents, contact
+// details and internal capacity counters are left out.
+type WorkerPublicResponse struct {
+	ID            uint                     `json:"id"`
+	UserID        uint                     `json:"user_id"`
+	CategoryID    uint                     `json:"category_id"`
+	Category      *CategoryResponse        `json:"category,omitempty"`
+	City          string                   `json:"city"`
+	Experience    string                   `json:"experience"`
+	Skills        string                   `json:"skills"`
+	HourlyRate    float64                  `json:"hourly_rate"`
+	ProfilePhoto  *string                  `json:"profile_photo"`
+	ProfilePhotos *mediaurl.Variants       `json:"profile_photo_variants,omitempty"`
+	IsAvailable   bool                     `json:"is_available"`
+	CurrentLat    *float64                 `json:"current_lat"`
+	CurrentLng    *float64                 `json:"current_lng"`
+	CompletedJobs int                      `json:"completed_jobs"`
+	Rating        float64                  `json:"rating"`
+	TotalReviews  int                      `json:"total_reviews"`
+	IsVerified    bool                     `json:"is_verified"`
+	Categories    []WorkerCategoryResponse `json:"categories,omitempty"`
+	User          *UserSummary             `json:"user,omitempty"`
+}
+
+// WorkerProfileResponse is the full profile, returned to the worker themselves and to admins
+type WorkerProfileResponse struct {
+	WorkerPublicResponse
+	PhoneNumber        string                 `json:"phone_number"`
+	Country            string                 `json:"country"`
+	State              string                 `json:"state"`
+	PostalCode         string                 `json:"postal_code"`
+	Address            string                 `json:"address"`
+	IDCardPhoto        *string                `json:"id_card_photo"`
+	IDCardBackPhoto    *string                `json:"id_card_photo_back"`
+	IDCardMediaID      *uint                  `json:"id_card_media_id"` // Private ID card photos, shown through GET /media/:id/url
+	IDCardBackMediaID  *uint                  `json:"id_card_back_media_id"`
+	LastLocationUpdate *time.Time             `json:"last_location_update"`
+	LocationAccuracy   *float64               `json:"location_accuracy"`
+	ActiveRequests     int                    `json:"active_requests"`
+	MaxConcurrentJobs  int                    `json:"max_concurrent_jobs"`
+	ReliabilityScore   float64                `json:"reliability_score"`
+	ReliabilityTier    models.ReliabilityTier `json:"reliability_tier"`
+	NoShowCount        int                    `json:"no_show_count"`
+	SuspendedUntil     *time.Time             `json:"suspended_until"`
+	CreatedAt          time.Time              `json:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at"`
+	User               *UserResponse          `json:"user,omitempty"`
+	// Categories includes those still awaiting verification
+	Categories       []WorkerCategoryResponse      `json:"categories,omitempty"`
+	OnboardingStatus models.WorkerOnboardingStatus `json:"onboarding_status"`
+}
+
+// WorkerPublic serializes a worker for customers
+func WorkerPublic(w models.WorkerProfile) WorkerPublicResponse {
+	return WorkerPublicResponse{
+		ID:            w.ID,
+		UserID:        w.UserID,
+		CategoryID:    w.CategoryID,
+		Category:      Category(w.Category),
+		City:          w.City,
+		Experience:    w.Experience,
+		Skills:        w.Skills,
+		HourlyRate:    w.HourlyRate,
+		ProfilePhoto:  w.ProfilePhoto,
+		ProfilePhotos: mediaurl.ImagePtr(w.ProfilePhoto),
+		IsAvailable:   w.IsAvailable,
+		CurrentLat:    w.CurrentLat,
+		CurrentLng:    w.CurrentLng,
+		CompletedJobs: w.CompletedJobs,
+		Rating:        w.Rating,
+		TotalReviews:  w.TotalReviews,
+		IsVerified:    w.IsVerified,
+		Categories:    WorkerCategories(w.Categories, true),
+		User:          UserSummaryOf(w.User),
+	}
+}
+
+// WorkerCategoryResponse is a category a worker serves besides their main one
+type WorkerCategoryResponse struct {
+	CategoryResponse
+	VerifiedAt *time.Time `json:"verified_at"`
+}
+
+// WorkerCategories serializes a worker's extra categories; with verifiedOnly, pending ones are
+// left out
+func WorkerCategories(categories []models.WorkerServiceCategory, verifiedOnly bool) []WorkerCategoryResponse {
+	out := make([]WorkerCategoryResponse, 0, len(categories))
+	for _, wc := range categories {
+		category := Category(wc.Category)
+		if category == nil || (verifiedOnly && wc.VerifiedAt == nil) {
+			continue
+		}
+		out = append(out, WorkerCategoryResponse{CategoryResponse: *category, VerifiedAt: wc.VerifiedAt})
+	}
+	return out
+}
+
+// WorkersPublic serializes a list of workers for customers
+func WorkersPublic(workers []models.WorkerProfile) []WorkerPublicResponse {
+	out := make([]WorkerPublicResponse, 0, len(workers))
+	for _, w := range workers {
+		out = append(out, WorkerPublic(w))
+	}
+	return out
+}
+
+// WorkerProfile serializes the full profile
+func WorkerProfile(w models.WorkerProfile) WorkerProfileResponse {
+	resp := WorkerProfileResponse{
+		WorkerPublicResponse: WorkerPublic(w),
+		PhoneNumber:          w.PhoneNumber,
+		Country:              w.Country,
+		State:                w.State,
+		PostalCode:           w.PostalCode,
+		Address:              w.Address,
+		IDCardPhoto:          w.IDCardPhoto,
+		IDCardBackPhoto:      w.IDCardBackPhoto,
+		IDCardMediaID:        w.IDCardMediaID,
+		IDCardBackMediaID:    w.IDCardBackMediaID,
+		LastLocationUpdate:   w.LastLocationUpdate,
+		LocationAccuracy:     w.LocationAccuracy,
+		ActiveRequests:       w.ActiveRequests,
+		MaxConcurrentJobs:    w.MaxConcurrentJobs,
+		ReliabilityScore:     w.ReliabilityScore,
+		ReliabilityTier:      w.ReliabilityTier,
+		NoShowCount:          w.NoShowCount,
+		SuspendedUntil:       w.SuspendedUntil,
+		CreatedAt:            w.CreatedAt,
+		UpdatedAt:            w.UpdatedAt,
+		Categories:           WorkerCategories(w.Categories, false),
+		OnboardingStatus:     w.OnboardingStatus,
+	}
+	if w.User.ID != 0 {
+		user := User(w.User)
+		resp.User = &user
+	}
+	return resp
+}
+
+// WorkerProfiles serializes a list of full profiles
+func WorkerProfiles(workers []models.WorkerProfile) []WorkerProfileResponse {
+	out := make([]WorkerProfileResponse, 0, len(workers))
+	for _, w := range workers {
+		out = append(out, WorkerProfile(w))
+	}
+	return out
+}
+
+// WorkerPublicProfileResponse is the profile anyone can browse: no location, contact details or
+// account IDs
+type WorkerPublicProfileResponse struct {
+	ID              uint                          `json:"id"`
+	FullName        string                        `json:"full_name"`
+	ProfilePhoto    *string                       `json:"profile_photo"`
+	ProfilePhotos   *mediaurl.Variants            `json:"profile_photo_variants,omitempty"`
+	Category        *CategoryResponse             `json:"category,omitempty"`
+	Categories      []WorkerCategoryResponse      `json:"categories"`
+	City            string                        `json:"city"`
+	Experience      string                        `json:"experience"`
+	Skills          string                        `json:"skills"`
+	HourlyRate      float64                       `json:"hourly_rate"`
+	IsAvailable     bool                          `json:"is_available"`
+	IsVerified      bool                          `json:"is_verified"`
+	CompletedJobs   int                           `json:"completed_jobs"`
+	Rating          float64                       `json:"rating"`
+	TotalReviews    int                           `json:"total_reviews"`
+	RatingBreakdown models.WorkerRatingSummary    `json:"rating_breakdown"`
+	RecentReviews   []PublicReviewResponse        `json:"recent_reviews"`
+	Portfolio       []models.WorkerPortfolioPhoto `json:"portfolio"`
+	Badges          []models.WorkerBadge          `json:"badges"`
+	MemberSince     time.Time                     `json:"member_since"`
+}
+
+// PublicReviewResponse is a rating as shown on a worker's public profile, signed with the
+// customer's first name unless they chose to stay anonymous
+type PublicReviewResponse struct {
+	ID              uint       `json:"id"`
+	Stars           int        `json:"stars"`
+	Comment         string     `json:"comment"`
+	CustomerName    string     `json:"customer_name"`
+	WorkerReply     string     `json:"worker_reply"`
+	WorkerRepliedAt *time.Time `json:"worker_replied_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// WorkerPublicProfile serializes a worker's public profile with their rating breakdown, recent
+// reviews, portfolio and the badges preloaded on w
+func WorkerPublicProfile(w models.WorkerProfile, summary models.WorkerRatingSummary, reviews []models.WorkerRating, portfolio []models.WorkerPortfolioPhoto) WorkerPublicProfileResponse {
+	resp := WorkerPublicProfileResponse{
+		ID:              w.ID,
+		FullName:        w.User.FullName,
+		ProfilePhoto:    w.ProfilePhoto,
+		ProfilePhotos:   mediaurl.ImagePtr(w.ProfilePhoto),
+		Category:        Category(w.Category),
+		Categories:      WorkerCategories(w.Categories, true),
+		City:            w.City,
+		Experience:      w.Experience,
+		Skills:          w.Skills,
+		HourlyRate:      w.HourlyRate,
+		IsAvailable:     w.IsAvailable,
+		IsVerified:      w.IsVerified,
+		CompletedJobs:   w.CompletedJobs,
+		Rating:          w.Rating,
+		TotalReviews:    w.TotalReviews,
+		RatingBreakdown: summary,
+		RecentReviews:   make([]PublicReviewResponse, 0, len(reviews)),
+		Portfolio:       portfolio,
+		Badges:          w.Badges,
+		MemberSince:     w.CreatedAt,
+	}
+	if resp.Portfolio == nil {
+		resp.Portfolio = []models.WorkerPortfolioPhoto{}
+	}
+	if resp.Badges == nil {
+		resp.Badges = []models.WorkerBadge{}
+	}
+	for _, r := range reviews {
+		review := PublicReviewResponse{
+			ID:              r.ID,
+			Stars:           r.Stars,
+			Comment:         r.Comment,
+			WorkerReply:     r.WorkerReply,
+			WorkerRepliedAt: r.WorkerRepliedAt,
+			CreatedAt:       r.CreatedAt,
+		}
+		if !r.IsAnonymous {
+			if names := strings.Fields(r.Customer.FullName); len(names) > 0 {
+				review.CustomerName = names[0]
+			}
+		}
+		resp.RecentReviews = append(resp.RecentReviews, review)
+	}
+	return resp
+}
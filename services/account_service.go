@@ -0,0 +1,460 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/serializers"
+)
+
+// ErrDeletionAlreadyScheduled is returned when a deletion is requested twice
+var ErrDeletionAlreadyScheduled = errors.New("account deletion is already scheduled")
+
+// ErrNoDeletionScheduled is returned when cancelling a deletion that was never requested
+var ErrNoDeletionScheduled = errors.New("no account deletion is scheduled")
+
+// deletedPlaceholder replaces free text written by an anonymized user
+const deletedPlaceholder = "[deleted]"
+
+// anonymizedMessage clears what a chat message says and where its sender was, in chat_messages
+// and archived_chat_messages alike
+func anonymizedMessage() map[string]interface{} {
+	return map[string]interface{}{
+		"content":    deletedPlaceholder,
+		"audio_url":  "",
+		"transcript": "",
+		"latitude":   nil,
+		"longitude":  nil,
+		"live_until": nil,
+	}
+}
+
+// AccountService handles account deletion, anonymization and personal data export
+type AccountService struct{}
+
+// NewAccountService creates a new account service
+func NewAccountService() *AccountService {
+	return &AccountService{}
+}
+
+// AccountDeletionGraceDays is how long a deletion can still be cancelled (ACCOUNT_DELETION_GRACE_DAYS)
+func AccountDeletionGraceDays() int {
+	if days, err := strconv.Atoi(os.Getenv("ACCOUNT_DELETION_GRACE_DAYS")); err == nil && days >= 0 {
+		return days
+	}
+	return 30
+}
+
+// RequestDeletion schedules the user's anonymization after the grace period and signs them out
+// everywhere. The account stays usable until then so the request can be cancelled.
+func (s *AccountService) RequestDeletion(user *models.User) error {
+	if user.DeletionScheduledFor != nil {
+		return ErrDeletionAlreadyScheduled
+	}
+
+	now := time.Now()
+	scheduledFor := now.AddDate(0, 0, AccountDeletionGraceDays())
+	if err := database.DB.Model(user).Updates(map[string]interface{}{
+		"deletion_requested_at":  &now,
+		"deletion_scheduled_for": &scheduledFor,
+	}).Error; err != nil {
+		return err
+	}
+	user.DeletionRequestedAt = &now
+	user.DeletionScheduledFor = &scheduledFor
+
+	if err := NewJWTService().RevokeAllUserTokens(user.ID); err != nil {
+		log.Printf("⚠️ Failed to revoke tokens for user %d after deletion request: %v", user.ID, err)
+	}
+	return nil
+}
+
+// CancelDeletion clears a pending deletion
+func (s *AccountService) CancelDeletion(user *models.User) error {
+	if user.DeletionScheduledFor == nil {
+		return ErrNoDeletionScheduled
+	}
+	if err := database.DB.Model(user).Updates(map[string]interface{}{
+		"deletion_requested_at":  nil,
+		"deletion_scheduled_for": nil,
+	}).Error; err != nil {
+		return err
+	}
+	user.DeletionRequestedAt = nil
+	user.DeletionScheduledFor = nil
+	return nil
+}
+
+// DueForAnonymization returns the users whose grace period has ended
+func (s *AccountService) DueForAnonymization(limit int) ([]uint, error) {
+	var ids []uint
+	err := database.DB.Model(&models.User{}).
+		Where("deletion_scheduled_for <= ? AND anonymized_at IS NULL", time.Now()).
+		Order("deletion_scheduled_for").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Anonymize strips the user's personal data in one transaction. Rows other users depend on
+// (requests, chats, ratings, service histories) are kept with their free text and contact
+// details removed; rows only the user needs (addresses, tokens, notifications) are deleted.
+func (s *AccountService) Anonymize(userID uint) error {
+	now := time.Now()
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		steps := []struct {
+			name string
+			run  func() error
+		}{
+			{"user", func() error {
+				return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+					"full_name":               "Deleted user",
+					"phone_number":            fmt.Sprintf("deleted-%d", userID),
+					"password_hash":           "!",
+					"profile_picture_url":     nil,
+					"is_active":               false,
+					"phone_verified_at":       nil,
+					"email":                   nil,
+					"email_verified_at":       nil,
+					"emergency_contact_name":  nil,
+					"emergency_contact_phone": nil,
+					"anonymized_at":           &now,
+				}).Error
+			}},
+			{"worker profile", func() error {
+				return tx.Model(&models.WorkerProfile{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+					"phone_number":          "",
+					"postal_code":           "",
+					"address":               "",
+					"profile_photo":         nil,
+					"id_card_photo":         nil,
+					"id_card_back_photo":    nil,
+					"id_card_media_id":      nil,
+					"id_card_back_media_id": nil,
+					"current_lat":           nil,
+					"current_lng":           nil,
+					"location_accuracy":     nil,
+					"is_available":          false,
+				}).Error
+			}},
+			{"service requests", func() error {
+				return tx.Model(&models.CustomerServiceRequest{}).Where("customer_id = ?", userID).Updates(map[string]interface{}{
+					"description":      deletedPlaceholder,
+					"location_address": deletedPlaceholder,
+					"geocoded_address": "",
+					"location_lat":     nil,
+					"location_lng":     nil,
+				}).Error
+			}},
+			{"chat messages", func() error {
+				return tx.Model(&models.ChatMessage{}).Where("sender_id = ?", userID).Updates(anonymizedMessage()).Error
+			}},
+			{"archived chat messages", func() error {
+				return tx.Table("archived_chat_messages").Where("sender_id = ?", userID).Updates(anonymizedMessage()).Error
+			}},
+			{"AI conversations", func() error {
+				return tx.Model(&models.AIConversation{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+					"transcript": "[]",
+					"draft":      "{}",
+				}).Error
+			}},
+			{"chat rooms", func() error {
+				return tx.Model(&models.ChatRoom{}).Where("customer_id = ? OR worker_id = ?", userID, userID).
+					Update("last_message_text", "").Error
+			}},
+			{"ratings", func() error {
+				return tx.Model(&models.WorkerRating{}).Where("customer_id = ?", userID).Updates(map[string]interface{}{
+					"comment":      "",
+					"is_anonymous": true,
+				}).Error
+			}},
+			{"service histories", func() error {
+				return tx.Model(&models.ServiceHistory{}).Where("customer_id = ?", userID).Updates(map[string]interface{}{
+					"description":      deletedPlaceholder,
+					"location_address": deletedPlaceholder,
+					"location_lat":     nil,
+					"location_lng":     nil,
+					"customer_notes":   "",
+				}).Error
+			}},
+			{"SOS reports", func() error {
+				return tx.Model(&models.SafetyIncident{}).Where("reported_by_id = ?", userID).Updates(map[string]interface{}{
+					"lat":     nil,
+					"lng":     nil,
+					"message": deletedPlaceholder,
+				}).Error
+			}},
+			{"SOS locations", func() error {
+				return tx.Model(&models.SafetyIncident{}).
+					Where("service_request_id IN (?)", tx.Model(&models.CustomerServiceRequest{}).Select("id").Where("customer_id = ?", userID)).
+					Updates(map[string]interface{}{"lat": nil, "lng": nil}).Error
+			}},
+			{"customer call sessions", func() error {
+				return tx.Model(&models.CallSession{}).
+					Where("service_request_id IN (?)", tx.Model(&models.CustomerServiceRequest{}).Select("id").Where("customer_id = ?", userID)).
+					Updates(map[string]interface{}{"customer_participant_id": "", "customer_proxy_number": ""}).Error
+			}},
+			{"worker call sessions", func() error {
+				return tx.Model(&models.CallSession{}).
+					Where("worker_id IN (?)", tx.Model(&models.WorkerProfile{}).Select("id").Where("user_id = ?", userID)).
+					Updates(map[string]interface{}{"worker_participant_id": "", "worker_proxy_number": ""}).Error
+			}},
+			{"worker service histories", func() error {
+				return tx.Model(&models.ServiceHistory{}).
+					Where("worker_id IN (?)", tx.Model(&models.WorkerProfile{}).Select("id").Where("user_id = ?", userID)).
+					Update("worker_notes", "").Error
+			}},
+			{"portfolio photos", func() error {
+				return tx.Where("worker_id IN (?)", tx.Model(&models.WorkerProfile{}).Select("id").Where("user_id = ?", userID)).
+					Delete(&models.WorkerPortfolioPhoto{}).Error
+			}},
+			{"loyalty", func() error {
+				return tx.Where("user_id = ?", userID).Delete(&models.CustomerLoyalty{}).Error
+			}},
+			{"feedback", func() error {
+				return tx.Model(&models.Feedback{}).Where("user_id = ?", userID).Update("comment", "").Error
+			}},
+			{"address share accesses", func() error {
+				return tx.Where("share_id IN (?)", tx.Model(&models.AddressShare{}).Select("id").Where("user_id = ?", userID)).
+					Delete(&models.AddressShareAccess{}).Error
+			}},
+			{"address shares", func() error {
+				return tx.Where("user_id = ?", userID).Delete(&models.AddressShare{}).Error
+			}},
+			{"addresses", func() error {
+				return tx.Where("user_id = ?", userID).Delete(&models.Address{}).Error
+			}},
+			{"notifications", func() error {
+				return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.Notification{}).Error
+			}},
+			{"push tokens", func() error {
+				return tx.Unscoped().Where("user_id = ?", userID).Delete(&models.PushToken{}).Error
+			}},
+			{"device tokens", func() error {
+				return tx.Where("user_id = ?", userID).Delete(&models.UserDeviceToken{}).Error
+			}},
+			{"refresh tokens", func() error {
+				return tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error
+			}},
+			{"phone verifications", func() error {
+				return tx.Where("user_id = ?", userID).Delete(&models.PhoneVerification{}).Error
+			}},
+			{"email verifications", func() error {
+				return tx.Where("user_id = ?", userID).Delete(&models.EmailVerification{}).Error
+			}},
+			{"sms log", func() error {
+				return tx.Model(&models.SMSMessage{}).Where("user_id = ?", userID).Update("phone_number", "").Error
+			}},
+		}
+
+		for _, step := range steps {
+			if err := step.run(); err != nil {
+				return fmt.Errorf("anonymize %s: %w", step.name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// AccountExport is everything the platform stores about a user, as returned by GET /auth/export
+type AccountExport struct {
+	ExportedAt      time.Time                            `json:"exported_at"`
+	User            serializers.UserResponse             `json:"user"`
+	WorkerProfile   *serializers.WorkerProfileResponse   `json:"worker_profile,omitempty"`
+	Addresses       []serializers.AddressResponse        `json:"addresses"`
+	ServiceRequests []serializers.ServiceRequestResponse `json:"service_requests"`
+	ChatRooms       []serializers.ChatRoomResponse       `json:"chat_rooms"`
+	ChatMessages    []serializers.ChatMessageResponse    `json:"chat_messages"`
+	RatingsGiven    []ExportedRating                     `json:"ratings_given"`
+	RatingsReceived []ExportedRating                     `json:"ratings_received,omitempty"`
+	ServiceHistory  []ExportedServiceHistory             `json:"service_history"`
+	Notifications   []ExportedNotification               `json:"notifications"`
+	Feedback        []ExportedFeedback                   `json:"feedback"`
+}
+
+// ExportedRating is a rating in an account export
+type ExportedRating struct {
+	ID               uint      `json:"id"`
+	ServiceRequestID uint      `json:"service_request_id"`
+	Stars            int       `json:"stars"`
+	Comment          string    `json:"comment"`
+	ServiceQuality   int       `json:"service_quality"`
+	Professionalism  int       `json:"professionalism"`
+	Punctuality      int       `json:"punctuality"`
+	Communication    int       `json:"communication"`
+	IsAnonymous      bool      `json:"is_anonymous"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ExportedServiceHistory is a completed job in an account export
+type ExportedServiceHistory struct {
+	ID               uint          `json:"id"`
+	ServiceRequestID uint          `json:"service_request_id"`
+	Title            string        `json:"title"`
+	Description      string        `json:"description"`
+	LocationAddress  string        `json:"location_address"`
+	LocationCity     string        `json:"location_city"`
+	AgreedPrice      *money.Amount `json:"agreed_price"`
+	FinalPrice       *money.Amount `json:"final_price"`
+	PaymentStatus    string        `json:"payment_status"`
+	RefundedAmount   money.Amount  `json:"refunded_amount"`
+	WorkerNotes      string        `json:"worker_notes,omitempty"`
+	CustomerNotes    string        `json:"customer_notes,omitempty"`
+	CompletedAt      time.Time     `json:"completed_at"`
+}
+
+// ExportedNotification is a notification in an account export
+type ExportedNotification struct {
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	Type      string    `json:"type"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportedFeedback is an app feedback entry in an account export
+type ExportedFeedback struct {
+	ID         uint      `json:"id"`
+	Rating     int       `json:"rating"`
+	Comment    string    `json:"comment"`
+	AppVersion string    `json:"app_version"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Export collects the user's data
+func (s *AccountService) Export(user models.User) (*AccountExport, error) {
+	export := &AccountExport{ExportedAt: time.Now(), User: serializers.User(user)}
+
+	var worker models.WorkerProfile
+	err := database.DB.Preload("Category").Where("user_id = ?", user.ID).First(&worker).Error
+	switch {
+	case err == nil:
+		worker.User = user
+		profile := serializers.WorkerProfile(worker)
+		export.WorkerProfile = &profile
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	var addresses []models.Address
+	if err := database.DB.Where("user_id = ?", user.ID).Find(&addresses).Error; err != nil {
+		return nil, err
+	}
+	export.Addresses = serializers.Addresses(addresses)
+
+	var requests []models.CustomerServiceRequest
+	if err := database.DB.Preload("Category").Preload("ServiceOption").Preload("ServiceZone").
+		Where("customer_id = ?", user.ID).Order("created_at").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	export.ServiceRequests = serializers.ServiceRequests(requests)
+
+	var rooms []models.ChatRoom
+	if err := database.DB.Preload("ServiceRequest").
+		Where("customer_id = ? OR worker_id = ?", user.ID, user.ID).Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	export.ChatRooms = serializers.ChatRooms(rooms)
+
+	var messages []models.ChatMessage
+	if err := database.DB.Where("sender_id = ?", user.ID).Order("created_at").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	export.ChatMessages = serializers.ChatMessages(messages)
+
+	var given []models.WorkerRating
+	if err := database.DB.Where("customer_id = ?", user.ID).Find(&given).Error; err != nil {
+		return nil, err
+	}
+	export.RatingsGiven = exportRatings(given)
+
+	if worker.ID != 0 {
+		var received []models.WorkerRating
+		if err := database.DB.Where("worker_id = ?", worker.ID).Find(&received).Error; err != nil {
+			return nil, err
+		}
+		export.RatingsReceived = exportRatings(received)
+	}
+
+	var histories []models.ServiceHistory
+	query := database.DB.Where("customer_id = ?", user.ID)
+	if worker.ID != 0 {
+		query = query.Or("worker_id = ?", worker.ID)
+	}
+	if err := query.Order("completed_at").Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	export.ServiceHistory = make([]ExportedServiceHistory, 0, len(histories))
+	for _, h := range histories {
+		export.ServiceHistory = append(export.ServiceHistory, ExportedServiceHistory{
+			ID:               h.ID,
+			ServiceRequestID: h.ServiceRequestID,
+			Title:            h.Title,
+			Description:      h.Description,
+			LocationAddress:  h.LocationAddress,
+			LocationCity:     h.LocationCity,
+			AgreedPrice:      h.AgreedPrice,
+			FinalPrice:       h.FinalPrice,
+			PaymentStatus:    h.PaymentStatus,
+			RefundedAmount:   h.RefundedAmount,
+			WorkerNotes:      h.WorkerNotes,
+			CustomerNotes:    h.CustomerNotes,
+			CompletedAt:      h.CompletedAt,
+		})
+	}
+
+	var notifications []models.Notification
+	if err := database.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	export.Notifications = make([]ExportedNotification, 0, len(notifications))
+	for _, n := range notifications {
+		export.Notifications = append(export.Notifications, ExportedNotification{
+			ID: n.ID, Title: n.Title, Body: n.Body, Type: n.Type, Read: n.Read, CreatedAt: n.CreatedAt,
+		})
+	}
+
+	var feedback []models.Feedback
+	if err := database.DB.Where("user_id = ?", user.ID).Order("created_at").Find(&feedback).Error; err != nil {
+		return nil, err
+	}
+	export.Feedback = make([]ExportedFeedback, 0, len(feedback))
+	for _, f := range feedback {
+		export.Feedback = append(export.Feedback, ExportedFeedback{
+			ID: f.ID, Rating: f.Rating, Comment: f.Comment, AppVersion: f.AppVersion, CreatedAt: f.CreatedAt,
+		})
+	}
+
+	return export, nil
+}
+
+// exportRatings converts ratings for an account export
+func exportRatings(ratings []models.WorkerRating) []ExportedRating {
+	out := make([]ExportedRating, 0, len(ratings))
+	for _, r := range ratings {
+		out = append(out, ExportedRating{
+			ID:               r.ID,
+			ServiceRequestID: r.ServiceRequestID,
+			Stars:            r.Stars,
+			Comment:          r.Comment,
+			ServiceQuality:   r.ServiceQuality,
+			Professionalism:  r.Professionalism,
+			Punctuality:      r.Punctuality,
+			Communication:    r.Communication,
+			IsAnonymous:      r.IsAnonymous,
+			CreatedAt:        r.CreatedAt,
+		})
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"repair-service-server/models"
+	"repair-service-server/testdb"
+)
+
+func TestAnonymizeClearsPersonalData(t *testing.T) {
+	db := testdb.Install(t)
+	now := time.Now()
+	lat, lng := 18.0799, -15.9653
+	live := now.Add(time.Hour)
+	create := func(records ...interface{}) {
+		t.Helper()
+		for _, record := range records {
+			if err := db.Create(record).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	category := models.ServiceCategory{Name: "Plomberie", IsActive: true}
+	customer := models.User{FullName: "Aminetou", PhoneNumber: "+22222000001", PasswordHash: "unused", Role: models.RoleCustomer, IsActive: true}
+	workerUser := models.User{FullName: "Sidi", PhoneNumber: "+22222000002", PasswordHash: "unused", Role: models.RoleWorker, IsActive: true}
+	create(&category, &customer, &workerUser)
+	worker := models.WorkerProfile{
+		UserID:      workerUser.ID,
+		CategoryID:  category.ID,
+		PhoneNumber: workerUser.PhoneNumber,
+		Country:     "Mauritanie",
+		State:       "Nouakchott",
+		City:        "Nouakchott",
+		PostalCode:  "00000",
+	}
+	create(&worker)
+	request := models.CustomerServiceRequest{
+		CustomerID:       customer.ID,
+		CategoryID:       category.ID,
+		AssignedWorkerID: &worker.ID,
+		Title:            "Fuite",
+		Description:      "Sous l'évier",
+		Priority:         "medium",
+		LocationAddress:  "Tevragh Zeina, rue 42-150",
+		LocationCity:     "Nouakchott",
+		LocationLat:      &lat,
+		LocationLng:      &lng,
+		Status:           models.RequestStatusInProgress,
+	}
+	create(&request)
+	room := models.ChatRoom{CustomerID: customer.ID, WorkerID: workerUser.ID, ServiceRequestID: request.ID}
+	create(&room)
+	message := models.ChatMessage{
+		ChatRoomID:  room.ID,
+		SenderID:    customer.ID,
+		SenderType:  "customer",
+		Content:     "Je suis au portail bleu",
+		MessageType: "location",
+		Transcript:  "Je suis au portail bleu",
+		Latitude:    &lat,
+		Longitude:   &lng,
+		LiveUntil:   &live,
+	}
+	create(&message)
+	archivedID := message.ID + 1000
+	if err := db.Exec(`INSERT INTO archived_chat_messages (id, chat_room_id, sender_id, sender_type, content, transcript, latitude, longitude, live_until, archived_at)
+		VALUES (?, ?, ?, 'customer', 'Ancien message', 'Ancien message', ?, ?, ?, ?)`, archivedID, room.ID, customer.ID, lat, lng, live, now).Error; err != nil {
+		t.Fatal(err)
+	}
+	conversation := models.AIConversation{UserID: customer.ID, ServiceRequestID: request.ID, Transcript: `[{"role":"user","content":"J'ai une fuite"}]`, Draft: `{"title":"Fuite"}`}
+	address := models.Address{UserID: customer.ID, AddressDetails: "Ilot K", City: "Nouakchott", Latitude: lat, Longitude: lng}
+	create(&conversation, &address)
+	share := models.AddressShare{AddressID: address.ID, UserID: customer.ID, TokenHash: "hash", ExpiresAt: live}
+	create(&share)
+	access := models.AddressShareAccess{ShareID: share.ID, IPAddress: "196.200.1.1", UserAgent: "Mozilla/5.0", AccessedAt: now}
+	incident := models.SafetyIncident{
+		ServiceRequestID: request.ID,
+		ReportedByID:     customer.ID,
+		ReporterRole:     models.RoleCustomer,
+		Lat:              &lat,
+		Lng:              &lng,
+		Message:          "Le travailleur est agressif",
+		PreviousStatus:   models.RequestStatusInProgress,
+	}
+	workerIncident := incident
+	workerIncident.ReportedByID, workerIncident.ReporterRole, workerIncident.Message = workerUser.ID, models.RoleWorker, "Chien méchant"
+	session := models.CallSession{
+		ServiceRequestID:      request.ID,
+		WorkerID:              worker.ID,
+		Provider:              "test",
+		ProviderSessionID:     "session",
+		CustomerParticipantID: "customer-participant",
+		CustomerProxyNumber:   "+22240000001",
+		WorkerParticipantID:   "worker-participant",
+		WorkerProxyNumber:     "+22240000002",
+		ExpiresAt:             live,
+	}
+	create(&access, &incident, &workerIncident, &session)
+
+	service := NewAccountService()
+	if err := service.Anonymize(customer.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	reload := func(table string, out interface{}, id uint) {
+		t.Helper()
+		query := db
+		if table != "" {
+			query = db.Table(table)
+		}
+		if err := query.Where("id = ?", id).Take(out).Error; err != nil {
+			t.Fatalf("reloading %T %d: %v", out, id, err)
+		}
+	}
+
+	for table, id := range map[string]uint{"chat_messages": message.ID, "archived_chat_messages": archivedID} {
+		var got models.ChatMessage
+		reload(table, &got, id)
+		if got.Content != deletedPlaceholder || got.Transcript != "" || got.Latitude != nil || got.Longitude != nil || got.LiveUntil != nil {
+			t.Errorf("%s: content %q transcript %q at %v,%v live until %v, want all cleared",
+				table, got.Content, got.Transcript, got.Latitude, got.Longitude, got.LiveUntil)
+		}
+	}
+
+	reload("", &conversation, conversation.ID)
+	if conversation.Transcript != "[]" || conversation.Draft != "{}" {
+		t.Errorf("ai_conversations: transcript %s draft %s, want both emptied", conversation.Transcript, conversation.Draft)
+	}
+
+	for _, model := range []interface{}{&models.Address{}, &models.AddressShare{}, &models.AddressShareAccess{}} {
+		var count int64
+		if err := db.Model(model).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%d %T rows left, want none", count, model)
+		}
+	}
+
+	reload("", &incident, incident.ID)
+	if incident.Lat != nil || incident.Lng != nil || incident.Message != deletedPlaceholder {
+		t.Errorf("safety_incidents: customer's SOS at %v,%v saying %q, want location and message cleared", incident.Lat, incident.Lng, incident.Message)
+	}
+	reload("", &workerIncident, workerIncident.ID)
+	if workerIncident.Lat != nil || workerIncident.Lng != nil || workerIncident.Message != "Chien méchant" {
+		t.Errorf("safety_incidents: worker's SOS at %v,%v saying %q, want only the location cleared", workerIncident.Lat, workerIncident.Lng, workerIncident.Message)
+	}
+
+	reload("", &session, session.ID)
+	if session.CustomerParticipantID != "" || session.CustomerProxyNumber != "" || session.WorkerProxyNumber != "+22240000002" {
+		t.Errorf("call_sessions: customer %q %q worker %q, want only the customer's side cleared",
+			session.CustomerParticipantID, session.CustomerProxyNumber, session.WorkerProxyNumber)
+	}
+
+	if err := service.Anonymize(workerUser.ID); err != nil {
+		t.Fatal(err)
+	}
+	reload("", &session, session.ID)
+	if session.WorkerParticipantID != "" || session.WorkerProxyNumber != "" {
+		t.Errorf("call_sessions: worker %q %q, want cleared", session.WorkerParticipantID, session.WorkerProxyNumber)
+	}
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// Lifetime of address share links
+const (
+	DefaultAddressShareTTL = 24 * time.Hour
+	MaxAddressShareTTL     = 72 * time.Hour
+)
+
+var (
+	// ErrAddressShareNotFound is returned for a link token that was never issued or was revoked
+	ErrAddressShareNotFound = errors.New("address share not found")
+	// ErrAddressShareExpired is returned for a link past its expiry or whose request is finished
+	ErrAddressShareExpired = errors.New("address share expired")
+	// ErrShareRequestNotOpen is returned when a link is tied to a request that is not the
+	// customer's or is already finished
+	ErrShareRequestNotOpen = errors.New("service request is not open")
+)
+
+// AddressShareService issues and opens links to saved addresses. The token is only returned
+// when the link is created; the database keeps its SHA-256 hash.
+type AddressShareService struct {
+	db *gorm.DB
+}
+
+// NewAddressShareService creates a new address share service
+func NewAddressShareService() *AddressShareService {
+	return &AddressShareService{
+		db: database.DB,
+	}
+}
+
+// Create issues a link to one of the user's addresses valid for ttl, and until the request
+// finishes when serviceRequestID is set. It returns the share and its token.
+func (s *AddressShareService) Create(userID, addressID uint, serviceRequestID *uint, ttl time.Duration) (*models.AddressShare, string, error) {
+	var address models.Address
+	if err := s.db.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+		return nil, "", err
+	}
+	if serviceRequestID != nil {
+		var request models.CustomerServiceRequest
+		if err := s.db.Select("id, status").Where("id = ? AND customer_id = ?", *serviceRequestID, userID).First(&request).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, "", ErrShareRequestNotOpen
+			}
+			return nil, "", err
+		}
+		if request.Status.IsFinished() {
+			return nil, "", ErrShareRequestNotOpen
+		}
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	share := models.AddressShare{
+		AddressID:        addressID,
+		UserID:           userID,
+		ServiceRequestID: serviceRequestID,
+		TokenHash:        hashShareToken(token),
+		ExpiresAt:        time.Now().Add(ttl),
+	}
+	if err := s.db.Create(&share).Error; err != nil {
+		return nil, "", err
+	}
+	share.Address = address
+	return &share, token, nil
+}
+
+// List returns the links issued for one of the user's addresses, newest first
+func (s *AddressShareService) List(userID, addressID uint) ([]models.AddressShare, error) {
+	var shares []models.AddressShare
+	err := s.db.Where("address_id = ? AND user_id = ?", addressID, userID).Order("created_at DESC").Find(&shares).Error
+	return shares, err
+}
+
+// Revoke stops a link working before it expires
+func (s *AddressShareService) Revoke(userID, addressID, shareID uint) error {
+	result := s.db.Model(&models.AddressShare{}).
+		Where("id = ? AND address_id = ? AND user_id = ? AND revoked_at IS NULL", shareID, addressID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Open returns the address behind a link token and records who opened it
+func (s *AddressShareService) Open(token, ipAddress, userAgent string) (*models.AddressShare, error) {
+	var share models.AddressShare
+	if err := s.db.Preload("Address").Where("token_hash = ? AND revoked_at IS NULL", hashShareToken(token)).First(&share).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddressShareNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if now.After(share.ExpiresAt) {
+		return nil, ErrAddressShareExpired
+	}
+	if share.ServiceRequestID != nil {
+		var request models.CustomerServiceRequest
+		if err := s.db.Unscoped().Select("id, status").First(&request, *share.ServiceRequestID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if request.ID == 0 || request.Status.IsFinished() {
+			return nil, ErrAddressShareExpired
+		}
+	}
+
+	if len(userAgent) > 500 {
+		userAgent = userAgent[:500]
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		access := models.AddressShareAccess{ShareID: share.ID, IPAddress: ipAddress, UserAgent: userAgent, AccessedAt: now}
+		if err := tx.Create(&access).Error; err != nil {
+			return err
+		}
+		return tx.Model(&share).Updates(map[string]interface{}{
+			"access_count":     gorm.Expr("access_count + 1"),
+			"last_accessed_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	share.AccessCount++
+	share.LastAccessedAt = &now
+	return &share, nil
+}
+
+// hashShareToken is the form a link token is stored and looked up in
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
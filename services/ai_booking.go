@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/validation"
+)
+
+// bookingTool lets Gemini propose a service request instead of only suggesting a worker
+var bookingTool = Tool{FunctionDeclarations: []FunctionDeclaration{{
+	Name:        "create_service_request",
+	Description: "Propose booking a repair for the user. The user must accept before it is created.",
+	Parameters: map[string]interface{}{
+		"type": "OBJECT",
+		"properties": map[string]interface{}{
+			"category_id":   map[string]interface{}{"type": "INTEGER", "description": "Id of the matching service category from the context"},
+			"title":         map[string]interface{}{"type": "STRING", "description": "Short title of the repair, in the user's language"},
+			"description":   map[string]interface{}{"type": "STRING", "description": "What is broken and any details the user gave"},
+			"priority":      map[string]interface{}{"type": "STRING", "enum": validation.Priorities},
+			"budget":        map[string]interface{}{"type": "NUMBER", "description": "Budget in MRU, only if the user gave one"},
+			"scheduled_for": map[string]interface{}{"type": "STRING", "description": "RFC3339 time with the user's UTC offset from Local Time in the context, only if the user wants the repair later rather than now"},
+		},
+		"required": []string{"category_id", "title"},
+	},
+}}}
+
+// draftFromFunctionCall validates a create_service_request call against the active categories
+func draftFromFunctionCall(call *FunctionCall, categories []models.ServiceCategory) (*ServiceRequestDraft, error) {
+	draft := &ServiceRequestDraft{Priority: "medium"}
+
+	id, ok := call.Args["category_id"].(float64)
+	if !ok || id <= 0 {
+		return nil, fmt.Errorf("missing category_id")
+	}
+	draft.CategoryID = uint(id)
+	if categoryByID(categories, draft.CategoryID) == nil {
+		return nil, fmt.Errorf("unknown category %d", draft.CategoryID)
+	}
+
+	draft.Title, _ = call.Args["title"].(string)
+	draft.Title = strings.TrimSpace(draft.Title)
+	if draft.Title == "" {
+		return nil, fmt.Errorf("missing title")
+	}
+	if len(draft.Title) > 200 {
+		draft.Title = draft.Title[:200]
+	}
+	draft.Description, _ = call.Args["description"].(string)
+	if len(draft.Description) > 2000 {
+		draft.Description = draft.Description[:2000]
+	}
+
+	if priority, _ := call.Args["priority"].(string); validation.IsPriority(priority) {
+		draft.Priority = priority
+	}
+	if budget, ok := call.Args["budget"].(float64); ok && budget > 0 {
+		draft.Budget = money.FromPtr(&budget)
+	}
+	if scheduled, _ := call.Args["scheduled_for"].(string); scheduled != "" {
+		at, err := time.Parse(time.RFC3339, scheduled)
+		if err != nil || at.Before(time.Now()) {
+			return nil, fmt.Errorf("invalid scheduled_for %q", scheduled)
+		}
+		draft.ScheduledFor = &at
+	}
+	return draft, nil
+}
+
+// draftResponse shows a proposed booking as a task card with a worker from its category, if any
+func draftResponse(draft *ServiceRequestDraft, workers []WorkerCard, categories []models.ServiceCategory, language string) *AIResponse {
+	category := categoryByID(categories, draft.CategoryID)
+
+	task := &TaskCard{Description: draft.Title, Time: "now"}
+	if draft.Budget != nil {
+		task.Price = int(draft.Budget.Float())
+	}
+	if draft.ScheduledFor != nil {
+		task.Time = draft.ScheduledFor.Format(time.RFC3339)
+	}
+
+	card := &AICard{Task: task, Buttons: []string{"Accept", "Decline"}}
+	for i := range workers {
+		if workers[i].Serves(category.Name) {
+			card.Worker = &workers[i]
+			if task.Price == 0 {
+				task.Price = workers[i].Price
+			}
+			break
+		}
+	}
+
+	return &AIResponse{
+		Text:  i18n.T(aiLanguage(language), "ai.accept_draft", i18n.Vars{"title": draft.Title, "category": category.Name}),
+		Card:  card,
+		Draft: draft,
+	}
+}
+
+func categoryByID(categories []models.ServiceCategory, id uint) *models.ServiceCategory {
+	for i := range categories {
+		if categories[i].ID == id {
+			return &categories[i]
+		}
+	}
+	return nil
+}
+
+// Errors returned when the assistant cannot book an accepted proposal
+var (
+	// ErrNoDefaultAddress is returned when the assistant books for a user without a default address
+	ErrNoDefaultAddress = errors.New("add a default address to book a repair through the assistant")
+	// ErrPhoneUnverified is returned for users who have not confirmed their phone number
+	ErrPhoneUnverified = errors.New("verify your phone number to book a repair")
+	// ErrRequestBudgetExceeded is returned when the user created too many requests recently
+	ErrRequestBudgetExceeded = errors.New("too many requests created recently")
+	// ErrDraftAlreadyBooked is returned when the same proposal is accepted again
+	ErrDraftAlreadyBooked = errors.New("this proposal was already booked")
+)
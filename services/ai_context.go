@@ -0,0 +1,174 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// AIContextRefreshInterval is how often the AI context job reloads available workers and
+// categories. Prompts reload the snapshot themselves once it is two intervals old, so the
+// assistant keeps working if the job is not running.
+const AIContextRefreshInterval = time.Minute
+
+// aiContextWorkers caps how many workers a snapshot holds; the nearest are picked per prompt
+const aiContextWorkers = 500
+
+// aiWorker is an available worker as kept in the AI context snapshot, before the distance to a
+// particular user is known
+type aiWorker struct {
+	ID       uint     `gorm:"column:id"`
+	Name     string   `gorm:"column:full_name"`
+	PhotoURL *string  `gorm:"column:profile_photo"`
+	Rating   float64  `gorm:"column:rating"`
+	Price    int      `gorm:"column:hourly_rate"`
+	Category string   `gorm:"column:category_name"`
+	Lat      *float64 `gorm:"column:current_lat"`
+	Lng      *float64 `gorm:"column:current_lng"`
+
+	Categories []string `gorm:"-"` // Verified categories served besides Category
+}
+
+// aiContextSnapshot is the worker and catalog data every prompt's context is built from
+type aiContextSnapshot struct {
+	workers    []aiWorker
+	categories []models.ServiceCategory
+	options    []models.ServiceOption // Active options of active categories
+	loadedAt   time.Time
+}
+
+var (
+	aiContextMu sync.RWMutex
+	aiContext   *aiContextSnapshot
+)
+
+// RefreshAIContext reloads the available workers, active categories and service options used in
+// AI prompts
+func RefreshAIContext() error {
+	_, err := loadAIContext()
+	return err
+}
+
+func loadAIContext() (*aiContextSnapshot, error) {
+	snapshot := &aiContextSnapshot{loadedAt: time.Now()}
+
+	if err := database.DB.Table("worker_profiles").
+		Select("worker_profiles.id, users.full_name, worker_profiles.profile_photo, worker_profiles.rating, worker_profiles.hourly_rate, service_categories.name as category_name, worker_profiles.current_lat, worker_profiles.current_lng").
+		Joins("JOIN users ON worker_profiles.user_id = users.id").
+		Joins("JOIN service_categories ON worker_profiles.category_id = service_categories.id").
+		Where("worker_profiles.is_available = ?", true).
+		Order("worker_profiles.rating DESC").
+		Limit(aiContextWorkers).
+		Find(&snapshot.workers).Error; err != nil {
+		return nil, err
+	}
+	if err := loadAIWorkerCategories(snapshot.workers); err != nil {
+		return nil, err
+	}
+	if err := database.DB.Where("is_active = ?", true).Order("sort_order, id").Find(&snapshot.categories).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.
+		Joins("JOIN service_categories ON service_categories.id = service_options.category_id AND service_categories.is_active = ? AND service_categories.deleted_at IS NULL", true).
+		Where("service_options.is_active = ?", true).
+		Order("service_options.category_id, service_options.sort_order, service_options.id").
+		Find(&snapshot.options).Error; err != nil {
+		return nil, err
+	}
+
+	aiContextMu.Lock()
+	aiContext = snapshot
+	aiContextMu.Unlock()
+	return snapshot, nil
+}
+
+// loadAIWorkerCategories fills in the verified extra categories of the snapshot workers
+func loadAIWorkerCategories(workers []aiWorker) error {
+	if len(workers) == 0 {
+		return nil
+	}
+	index := make(map[uint]int, len(workers))
+	ids := make([]uint, 0, len(workers))
+	for i, worker := range workers {
+		index[worker.ID] = i
+		ids = append(ids, worker.ID)
+	}
+
+	var rows []struct {
+		WorkerID     uint
+		CategoryName string
+	}
+	if err := database.DB.Table("worker_categories").
+		Select("worker_categories.worker_id, service_categories.name as category_name").
+		Joins("JOIN service_categories ON worker_categories.category_id = service_categories.id").
+		Where("worker_categories.worker_id IN ? AND worker_categories.verified_at IS NOT NULL", ids).
+		Order("worker_categories.worker_id, service_categories.sort_order").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		i := index[row.WorkerID]
+		workers[i].Categories = append(workers[i].Categories, row.CategoryName)
+	}
+	return nil
+}
+
+// currentAIContext returns the latest snapshot, reloading it when it is missing or stale
+func currentAIContext() (*aiContextSnapshot, error) {
+	aiContextMu.RLock()
+	snapshot := aiContext
+	aiContextMu.RUnlock()
+
+	if snapshot != nil && time.Since(snapshot.loadedAt) < 2*AIContextRefreshInterval {
+		return snapshot, nil
+	}
+	fresh, err := loadAIContext()
+	if err != nil && snapshot != nil {
+		// A stale snapshot beats no context at all
+		log.Printf("⚠️ Failed to refresh AI context, using snapshot from %s: %v", snapshot.loadedAt.Format(time.RFC3339), err)
+		return snapshot, nil
+	}
+	return fresh, err
+}
+
+// nearestWorkers returns up to limit snapshot workers as cards, nearest to userLocation first.
+// Without a location the best rated workers are returned at a nominal distance.
+func (ai *AIService) nearestWorkers(snapshot *aiContextSnapshot, userLocation *models.Address, limit int) []WorkerCard {
+	workers := make([]WorkerCard, 0, len(snapshot.workers))
+	for _, worker := range snapshot.workers {
+		distance := 2.5 // Default distance
+		if userLocation != nil {
+			if worker.Lat == nil || worker.Lng == nil {
+				continue
+			}
+			distance = ai.calculateDistance(userLocation.Latitude, userLocation.Longitude, *worker.Lat, *worker.Lng)
+		}
+
+		photoURL := ""
+		if worker.PhotoURL != nil {
+			photoURL = *worker.PhotoURL
+		}
+		workers = append(workers, WorkerCard{
+			ID:       int(worker.ID),
+			Name:     worker.Name,
+			PhotoURL: photoURL,
+			Rating:   worker.Rating,
+			Distance: distance,
+			Category: worker.Category,
+			Price:    worker.Price,
+			Time:     "now",
+
+			Categories: worker.Categories,
+		})
+	}
+
+	sort.SliceStable(workers, func(i, j int) bool { return workers[i].Distance < workers[j].Distance })
+	if len(workers) > limit {
+		workers = workers[:limit]
+	}
+	return workers
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/validation"
+)
+
+// Diagnosis errors
+var (
+	ErrAIUnavailable        = errors.New("AI service is not configured")
+	ErrDiagnosisUnavailable = errors.New("diagnosis not found or already linked to a service request")
+)
+
+// diagnosisAnswer is the JSON Gemini is asked to return for a photo
+type diagnosisAnswer struct {
+	Problem    string       `json:"problem"`
+	Severity   string       `json:"severity"`
+	CategoryID uint         `json:"category_id"`
+	PriceMin   money.Amount `json:"price_min"`
+	PriceMax   money.Amount `json:"price_max"`
+	Advice     string       `json:"advice"`
+}
+
+// DiagnoseImage sends a photo prepared by PrepareDiagnosisImage to Gemini with the customer's
+// note and stores the structured diagnosis it returns for userID
+func (ai *AIService) DiagnoseImage(userID uint, photo []byte, note, language string) (*models.AIDiagnosis, error) {
+	if ai.apiKey == "" {
+		return nil, ErrAIUnavailable
+	}
+
+	categories, err := ai.getServiceCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	var categoryList strings.Builder
+	for _, category := range categories {
+		fmt.Fprintf(&categoryList, "- [id %d] %s: %s\n", category.ID, category.Name, category.Description)
+	}
+	if language == "" {
+		language = "fr"
+	}
+
+	prompt := fmt.Sprintf(`You are a home repair expert in Nouakchott, Mauritania. Look at the photo and diagnose the problem.
+The customer says: %q
+
+Service categories:
+%s
+Reply with JSON only, writing problem and advice in language %q:
+{"problem": "what is wrong, in one or two sentences",
+ "severity": "low" | "medium" | "high" | "urgent",
+ "category_id": id of the category that should fix it, or 0 if none fits,
+ "price_min": lowest likely cost in %[4]s,
+ "price_max": highest likely cost in %[4]s,
+ "advice": "what the customer should do until a worker arrives"}
+Use "urgent" only for safety hazards such as exposed wires, gas smells or flooding.
+If the photo does not show a repair problem, say so in problem and set category_id to 0.`, note, categoryList.String(), language, NewRegionService().Default().Currency)
+
+	content, err := ai.send(GeminiRequest{
+		Contents: []Content{{Parts: []Part{
+			{Text: prompt},
+			{InlineData: &InlineData{MimeType: "image/jpeg", Data: base64.StdEncoding.EncodeToString(photo)}},
+		}}},
+		GenerationConfig: GenerationConfig{
+			Temperature:      0.2,
+			TopK:             40,
+			TopP:             0.95,
+			MaxOutputTokens:  1024,
+			ResponseMimeType: "application/json",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call gemini API: %w", err)
+	}
+
+	var response string
+	for _, part := range content.Parts {
+		response += part.Text
+	}
+	var answer diagnosisAnswer
+	cleaned := strings.TrimPrefix(strings.Trim(strings.TrimSpace(response), "`"), "json")
+	if err := json.Unmarshal([]byte(strings.TrimSpace(cleaned)), &answer); err != nil {
+		return nil, fmt.Errorf("unexpected diagnosis response: %s", response)
+	}
+
+	diagnosis := diagnosisFromAnswer(answer, categories)
+	diagnosis.UserID = userID
+	diagnosis.Note = note
+	if err := database.DB.Create(diagnosis).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("🩺 Diagnosed photo for user %d: %s (%s)", userID, diagnosis.Problem, diagnosis.Severity)
+	return diagnosis, nil
+}
+
+// diagnosisFromAnswer keeps what is usable from Gemini's answer: unknown categories are dropped,
+// unknown severities become medium and the price range is put in order
+func diagnosisFromAnswer(answer diagnosisAnswer, categories []models.ServiceCategory) *models.AIDiagnosis {
+	diagnosis := &models.AIDiagnosis{
+		Problem:  strings.TrimSpace(answer.Problem),
+		Severity: answer.Severity,
+		PriceMin: max(answer.PriceMin, 0),
+		PriceMax: max(answer.PriceMax, 0),
+		Advice:   strings.TrimSpace(answer.Advice),
+	}
+	if diagnosis.Problem == "" {
+		diagnosis.Problem = "Unable to identify the problem from the photo"
+	}
+	if !validation.IsPriority(diagnosis.Severity) {
+		diagnosis.Severity = "medium"
+	}
+	if diagnosis.PriceMin > diagnosis.PriceMax {
+		diagnosis.PriceMin, diagnosis.PriceMax = diagnosis.PriceMax, diagnosis.PriceMin
+	}
+	if category := categoryByID(categories, answer.CategoryID); category != nil {
+		diagnosis.SuggestedCategoryID = &category.ID
+	}
+	return diagnosis
+}
+
+// diagnosisResponse diagnoses a photo sent over the AI WebSocket. When a category fits, the
+// diagnosis is proposed as a booking the user can accept.
+func (ai *AIService) diagnosisResponse(userID uint, note, imageData, language string, categories []models.ServiceCategory) (*AIResponse, error) {
+	photo, err := DecodeBase64Image(imageData)
+	if err == nil {
+		photo, err = PrepareDiagnosisImage(photo)
+	}
+	if errors.Is(err, ErrInvalidImage) {
+		return &AIResponse{Text: i18n.T(aiLanguage(language), "ai.photo_unreadable", nil)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	diagnosis, err := ai.DiagnoseImage(userID, photo, note, language)
+	if err != nil {
+		return nil, err
+	}
+
+	text := diagnosis.Problem
+	if diagnosis.PriceMax > 0 {
+		text += " " + i18n.T(aiLanguage(language), "ai.estimated_cost", i18n.Vars{
+			"price_min": fmt.Sprintf("%.0f", diagnosis.PriceMin.Float()),
+			"price_max": fmt.Sprintf("%.0f", diagnosis.PriceMax.Float()),
+			"currency":  NewRegionService().Default().Currency,
+		})
+	}
+	if diagnosis.Advice != "" {
+		text += " " + diagnosis.Advice
+	}
+
+	task := &TaskCard{
+		Description: diagnosis.Problem,
+		Price:       int(diagnosis.PriceMin.Float()),
+		PriceMax:    int(diagnosis.PriceMax.Float()),
+		Severity:    diagnosis.Severity,
+		Time:        "now",
+	}
+	response := &AIResponse{Text: text, Card: &AICard{Task: task}, DiagnosisID: &diagnosis.ID}
+	if diagnosis.SuggestedCategoryID == nil {
+		return response, nil
+	}
+
+	title := diagnosis.Problem
+	if len(title) > 200 {
+		title = title[:200]
+	}
+	description := diagnosis.Problem
+	if note != "" {
+		description = note + "\n\n" + description
+	}
+	response.Card.Buttons = []string{"Accept", "Decline"}
+	response.Draft = &ServiceRequestDraft{
+		CategoryID:  *diagnosis.SuggestedCategoryID,
+		Title:       title,
+		Description: description,
+		Priority:    diagnosis.Severity,
+		DiagnosisID: &diagnosis.ID,
+	}
+	if category := categoryByID(categories, *diagnosis.SuggestedCategoryID); category != nil {
+		response.Text += " " + i18n.T(aiLanguage(language), "ai.accept_category", i18n.Vars{"category": category.Name})
+	}
+	return response, nil
+}
+
+// LinkDiagnosis attaches userID's diagnosis to the service request booked for it. A diagnosis
+// belongs to one request only.
+func LinkDiagnosis(userID, diagnosisID, requestID uint) error {
+	result := database.DB.Model(&models.AIDiagnosis{}).
+		Where("id = ? AND user_id = ? AND service_request_id IS NULL", diagnosisID, userID).
+		Update("service_request_id", requestID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDiagnosisUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+)
+
+// ErrAIQuotaExhausted is returned while Gemini is refusing calls for quota or overload, without
+// calling it again until its retry delay has passed
+var ErrAIQuotaExhausted = errors.New("AI quota exhausted")
+
+const (
+	aiQuotaCooldown = time.Minute      // Wait after a 429 that gives no retry delay
+	aiDedupWindow   = 30 * time.Second // How long a prompt's answer is reused for an identical prompt
+)
+
+// aiQuotaUntil is when Gemini may be called again, in Unix nanoseconds
+var aiQuotaUntil atomic.Int64
+
+// retryDelayPattern finds the retry delay in a Gemini RESOURCE_EXHAUSTED error body
+var retryDelayPattern = regexp.MustCompile(`"retryDelay":\s*"(\d+)(?:\.\d+)?s"`)
+
+// AIQuotaRetryAfter returns how long until Gemini is called again after a quota error, or zero
+func AIQuotaRetryAfter() time.Duration {
+	return max(time.Until(time.Unix(0, aiQuotaUntil.Load())), 0)
+}
+
+// markAIQuotaExhausted pauses Gemini calls for the delay given in retryAfter or body
+func markAIQuotaExhausted(retryAfter string, body []byte) {
+	cooldown := aiQuotaCooldown
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+		cooldown = time.Duration(seconds) * time.Second
+	} else if match := retryDelayPattern.FindSubmatch(body); match != nil {
+		if seconds, err := strconv.Atoi(string(match[1])); err == nil && seconds > 0 {
+			cooldown = time.Duration(seconds) * time.Second
+		}
+	}
+	aiQuotaUntil.Store(time.Now().Add(cooldown).UnixNano())
+	log.Printf("⚠️ Gemini quota exhausted, pausing AI calls for %s", cooldown)
+}
+
+// aiCall is a prompt being answered, or answered within the dedup window
+type aiCall struct {
+	done     chan struct{}
+	response *AIResponse
+	err      error
+	finished time.Time
+}
+
+var (
+	aiCallsMu sync.Mutex
+	aiCalls   = make(map[string]*aiCall)
+)
+
+// dedupAICall runs call once for concurrent identical prompts, such as a message the app resent
+// after a dropped connection, and reuses its answer for aiDedupWindow. Failed calls are not
+// reused.
+func dedupAICall(key string, call func() (*AIResponse, error)) (*AIResponse, error) {
+	now := time.Now()
+
+	aiCallsMu.Lock()
+	for k, c := range aiCalls {
+		if !c.finished.IsZero() && now.Sub(c.finished) > aiDedupWindow {
+			delete(aiCalls, k)
+		}
+	}
+	if c, ok := aiCalls[key]; ok {
+		aiCallsMu.Unlock()
+		<-c.done
+		return c.response, c.err
+	}
+	c := &aiCall{done: make(chan struct{})}
+	aiCalls[key] = c
+	aiCallsMu.Unlock()
+
+	c.response, c.err = call()
+
+	aiCallsMu.Lock()
+	c.finished = time.Now()
+	if c.err != nil {
+		delete(aiCalls, key)
+	}
+	aiCallsMu.Unlock()
+	close(c.done)
+
+	return c.response, c.err
+}
+
+// aiPromptKey identifies a prompt by everything its answer depends on
+func aiPromptKey(userID uint, messageType, userInput, imageData, voiceData, language string, history []map[string]interface{}) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%d\x00%s\x00%s\x00%s\x00%s\x00%s\x00", userID, messageType, userInput, imageData, voiceData, language)
+	json.NewEncoder(hash).Encode(history)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// degradedResponse answers without Gemini while its quota is exhausted, matching the message to
+// a category by name and suggesting the nearest worker in it
+func degradedResponse(userInput string, workers []WorkerCard, categories []models.ServiceCategory, language string) *AIResponse {
+	lang := aiLanguage(language)
+	if category := matchCategoryByName(userInput, categories); category != nil {
+		for i := range workers {
+			if workers[i].Serves(category.Name) {
+				return &AIResponse{
+					Text:     i18n.T(lang, "ai.busy_worker", i18n.Vars{"worker": workers[i].Name, "category": category.Name}),
+					Card:     &AICard{Worker: &workers[i], Buttons: []string{"Accept", "Decline"}},
+					Degraded: true,
+				}
+			}
+		}
+	}
+	return &AIResponse{
+		Text:     i18n.T(lang, "ai.busy", nil),
+		Degraded: true,
+	}
+}
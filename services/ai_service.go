@@ -3,25 +3,48 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math"
 	"net/http"
 	"os"
+	"strings"
 	"repair-service-server/database"
+	"repair-service-server/i18n"
 	"repair-service-server/models"
+	"repair-service-server/money"
 	"time"
 )
 
 type AIService struct {
 	apiKey string
 	client *http.Client
+	transcriber Transcriber
 }
 
 type GeminiRequest struct {
 	Contents []Content `json:"contents"`
 	GenerationConfig GenerationConfig `json:"generationConfig"`
+	Tools    []Tool    `json:"tools,omitempty"`
+}
+
+// Tool declares the functions Gemini may call instead of answering in text
+type Tool struct {
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations"`
+}
+
+type FunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// FunctionCall is a function Gemini asked the server to run, with its arguments
+type FunctionCall struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args"`
 }
 
 type Content struct {
@@ -31,6 +54,7 @@ type Content struct {
 type Part struct {
 	Text string `json:"text,omitempty"`
 	InlineData *InlineData `json:"inlineData,omitempty"`
+	FunctionCall *FunctionCall `json:"functionCall,omitempty"`
 }
 
 type InlineData struct {
@@ -43,6 +67,7 @@ type GenerationConfig struct {
 	TopK           int     `json:"topK"`
 	TopP           float64 `json:"topP"`
 	MaxOutputTokens int     `json:"maxOutputTokens"`
+	ResponseMimeType string `json:"responseMimeType,omitempty"` // "application/json" forces a JSON answer
 }
 
 type GeminiResponse struct {
@@ -56,6 +81,22 @@ type Candidate struct {
 type AIResponse struct {
 	Text string `json:"text"`
 	Card *AICard `json:"card,omitempty"`
+	Draft *ServiceRequestDraft `json:"draft,omitempty"` // Set when the assistant proposes a booking
+	DiagnosisID *uint `json:"diagnosis_id,omitempty"` // Set when the message was a diagnosed photo
+	Transcript string `json:"transcript,omitempty"` // What was heard in a voice message
+	Degraded bool `json:"degraded,omitempty"` // Answered without Gemini because its quota is exhausted
+}
+
+// ServiceRequestDraft is a service request the assistant proposes through the
+// create_service_request function. Nothing is booked until the user accepts it.
+type ServiceRequestDraft struct {
+	CategoryID   uint          `json:"category_id"`
+	Title        string        `json:"title"`
+	Description  string        `json:"description"`
+	Priority     string        `json:"priority"`
+	Budget       *money.Amount `json:"budget,omitempty"`
+	ScheduledFor *time.Time    `json:"scheduled_for,omitempty"` // Nil books the request now
+	DiagnosisID  *uint         `json:"diagnosis_id,omitempty"`  // Photo diagnosis the request was proposed from
 }
 
 type AICard struct {
@@ -73,11 +114,28 @@ type WorkerCard struct {
 	Category string  `json:"category"`
 	Price    int     `json:"price"`
 	Time     string  `json:"time"`
+
+	Categories []string `json:"categories,omitempty"` // Other verified categories the worker serves
+}
+
+// Serves reports whether the worker's main or one of their other categories is named category
+func (w WorkerCard) Serves(category string) bool {
+	if w.Category == category {
+		return true
+	}
+	for _, name := range w.Categories {
+		if name == category {
+			return true
+		}
+	}
+	return false
 }
 
 type TaskCard struct {
 	Description string `json:"description"`
 	Price       int    `json:"price"`
+	PriceMax    int    `json:"price_max,omitempty"` // Upper end of an estimated price range
+	Severity    string `json:"severity,omitempty"`
 	Time        string `json:"time"`
 }
 
@@ -92,20 +150,31 @@ func NewAIService() *AIService {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		transcriber: NewTranscriber(),
 	}
 }
 
 func (ai *AIService) ProcessUserInput(userInput string, messageType string, imageData string, voiceData string, userID uint, language string, conversationHistory []map[string]interface{}) (*AIResponse, error) {
 	if ai.apiKey == "" {
 		return &AIResponse{
-			Text: "AI service is currently unavailable. Please contact support.",
+			Text: i18n.T(aiLanguage(language), "ai.unavailable", nil),
 		}, nil
 	}
 
+	key := aiPromptKey(userID, messageType, userInput, imageData, voiceData, language, conversationHistory)
+	return dedupAICall(key, func() (*AIResponse, error) {
+		return ai.processUserInput(userInput, messageType, imageData, voiceData, userID, language, conversationHistory)
+	})
+}
+
+// processUserInput answers one prompt; ProcessUserInput deduplicates calls to it
+func (ai *AIService) processUserInput(userInput string, messageType string, imageData string, voiceData string, userID uint, language string, conversationHistory []map[string]interface{}) (*AIResponse, error) {
+
 	// Get user location for worker matching
 	userLocation, err := ai.getUserLocation(userID)
 	if err != nil {
 		log.Printf("⚠️ Failed to get user location: %v", err)
+		userLocation = nil
 	}
 
 	// Get available workers near user
@@ -127,29 +196,73 @@ func (ai *AIService) ProcessUserInput(userInput string, messageType string, imag
 
 	// Build conversation context
 	context := ai.buildConversationContext(conversationHistory, workers, categories, language)
+	// The customer's local time lets Gemini turn "tomorrow at 9" into a time at their UTC offset
+	city := ""
+	if userLocation != nil {
+		city = userLocation.City
+	}
+	region := NewRegionService().ForCity(city)
+	localNow := time.Now().In(region.Location())
+	context += fmt.Sprintf("\nLocal Time: %s (%s)\n", localNow.Format(time.RFC3339), localNow.Weekday())
 	log.Printf("🔍 AI Context built with %d workers: %s", len(workers), context)
 
-	// Create prompt based on input type
-	var prompt string
+	// Photos get a structured diagnosis instead of a chat reply
 	if messageType == "image" && imageData != "" {
-		prompt = ai.buildImagePrompt(userInput, imageData, context, language)
-	} else if messageType == "voice" && voiceData != "" {
-		prompt = ai.buildVoicePrompt(userInput, voiceData, context, language)
-	} else {
-		prompt = ai.buildTextPrompt(userInput, context, language)
+		response, err := ai.diagnosisResponse(userID, userInput, imageData, language, categories)
+		if errors.Is(err, ErrAIQuotaExhausted) {
+			return degradedResponse(userInput, workers, categories, language), nil
+		}
+		return response, err
 	}
 
-	// Call Gemini API
-	response, err := ai.callGeminiAPI(prompt, imageData, voiceData)
+	// Voice messages are answered from their transcript
+	var transcript string
+	if messageType == "voice" && voiceData != "" {
+		transcript, err = ai.transcribeVoice(voiceData, language)
+		if err != nil {
+			log.Printf("⚠️ Failed to transcribe voice input: %v", err)
+			return &AIResponse{Text: i18n.T(aiLanguage(language), "ai.voice_unreadable", nil)}, nil
+		}
+		userInput = strings.TrimSpace(userInput + " " + transcript)
+	}
+	prompt := ai.buildTextPrompt(userInput, context, language)
+
+	// Call Gemini API, letting it propose a booking through create_service_request
+	content, err := ai.generateContent(prompt, "", []Tool{bookingTool})
+	if errors.Is(err, ErrAIQuotaExhausted) {
+		response := degradedResponse(userInput, workers, categories, language)
+		response.Transcript = transcript
+		return response, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to call gemini API: %v", err)
 	}
 
+	for _, part := range content.Parts {
+		if part.FunctionCall == nil || part.FunctionCall.Name != "create_service_request" {
+			continue
+		}
+		draft, err := draftFromFunctionCall(part.FunctionCall, categories)
+		if err != nil {
+			log.Printf("⚠️ Ignoring invalid create_service_request call: %v", err)
+			break
+		}
+		response := draftResponse(draft, workers, categories, language)
+		response.Transcript = transcript
+		return response, nil
+	}
+
+	var response string
+	for _, part := range content.Parts {
+		response += part.Text
+	}
+
 	// Parse response and create worker card if applicable
 	aiResponse, err := ai.parseAIResponse(response, workers)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ai response: %v", err)
 	}
+	aiResponse.Transcript = transcript
 
 	return aiResponse, nil
 }
@@ -163,14 +276,15 @@ Service Categories: %d
 Workers Data:
 `, language, len(workers), len(categories))
 
+	currency := NewRegionService().Default().Currency
 	for _, worker := range workers {
-		context += fmt.Sprintf("- %s (%s): Rating %.1f, %dkm away, %d OMR\n", 
-			worker.Name, worker.Category, worker.Rating, int(worker.Distance), worker.Price)
+		context += fmt.Sprintf("- %s (%s): Rating %.1f, %dkm away, %d %s\n", 
+			worker.Name, strings.Join(append([]string{worker.Category}, worker.Categories...), ", "), worker.Rating, int(worker.Distance), worker.Price, currency)
 	}
 
 	context += "\nService Categories:\n"
 	for _, category := range categories {
-		context += fmt.Sprintf("- %s: %s\n", category.Name, category.Description)
+		context += fmt.Sprintf("- [id %d] %s: %s\n", category.ID, category.Name, category.Description)
 	}
 
 	context += "\nConversation History:\n"
@@ -198,6 +312,8 @@ IMPORTANT RULES:
 5. Be professional, helpful, and concise
 6. Respond in the user's language: %s
 7. Use ONLY the real worker data provided in the context below
+8. Once the user has described a repair clearly enough to book, call create_service_request
+   with the matching category id instead of answering in text. The user confirms before anything is booked.
 
 Context:
 %s
@@ -237,21 +353,37 @@ If off-topic or not repair-related, respond with:
 	return fmt.Sprintf(basePrompt, language, context, userInput)
 }
 
-func (ai *AIService) buildImagePrompt(userInput, imageData, context, language string) string {
-	// For image analysis, we'll use a simpler text prompt since Gemini 1.5 Flash
-	// doesn't support direct image input in this implementation
-	return ai.buildTextPrompt(fmt.Sprintf("User sent an image with description: %s", userInput), context, language)
-}
-
-func (ai *AIService) buildVoicePrompt(userInput, voiceData, context, language string) string {
-	// For voice analysis, we'll use a simpler text prompt since we need to
-	// implement voice-to-text conversion first
-	return ai.buildTextPrompt(fmt.Sprintf("User sent a voice message: %s", userInput), context, language)
+// transcribeVoice turns a base64 voice recording into text with the configured speech-to-text
+// provider
+func (ai *AIService) transcribeVoice(voiceData, language string) (string, error) {
+	audio, filename, err := DecodeBase64Audio(voiceData)
+	if err != nil {
+		return "", err
+	}
+	transcript, err := ai.transcriber.Transcribe(audio, filename, language)
+	if err != nil {
+		return "", err
+	}
+	if transcript == "" {
+		return "", fmt.Errorf("empty transcript")
+	}
+	return transcript, nil
 }
 
 func (ai *AIService) callGeminiAPI(prompt, imageData, voiceData string) (string, error) {
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-1.5-flash:generateContent?key=%s", ai.apiKey)
+	content, err := ai.generateContent(prompt, imageData, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(content.Parts) == 0 {
+		return "", fmt.Errorf("no response from gemini")
+	}
+	return content.Parts[0].Text, nil
+}
 
+// generateContent sends prompt, and the image if any, to Gemini with tools it may call and
+// returns the first candidate
+func (ai *AIService) generateContent(prompt, imageData string, tools []Tool) (*Content, error) {
 	var parts []Part
 	parts = append(parts, Part{Text: prompt})
 
@@ -275,38 +407,53 @@ func (ai *AIService) callGeminiAPI(prompt, imageData, voiceData string) (string,
 			TopP:           0.95,
 			MaxOutputTokens: 1024,
 		},
+		Tools: tools,
 	}
+	return ai.send(request)
+}
+
+// send posts request to Gemini and returns the first candidate
+func (ai *AIService) send(request GeminiRequest) (*Content, error) {
+	if AIQuotaRetryAfter() > 0 {
+		return nil, ErrAIQuotaExhausted
+	}
+
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/gemini-1.5-flash:generateContent?key=%s", ai.apiKey)
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resp, err := ai.client.Post(url, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		markAIQuotaExhausted(resp.Header.Get("Retry-After"), body)
+		return nil, ErrAIQuotaExhausted
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("gemini API error: %s", string(body))
+		return nil, fmt.Errorf("gemini API error: %s", string(body))
 	}
 
 	var geminiResp GeminiResponse
 	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if len(geminiResp.Candidates) == 0 {
-		return "", fmt.Errorf("no response from gemini")
+	if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+		return nil, fmt.Errorf("no response from gemini")
 	}
 
-	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
+	return &geminiResp.Candidates[0].Content, nil
 }
 
 func (ai *AIService) parseAIResponse(response string, workers []WorkerCard) (*AIResponse, error) {
@@ -382,84 +529,53 @@ func (ai *AIService) getUserLocation(userID uint) (*models.Address, error) {
 }
 
 func (ai *AIService) getAvailableWorkers(userLocation *models.Address) ([]WorkerCard, error) {
-	var workers []WorkerCard
-
-	query := database.DB.Table("worker_profiles").
-		Select("worker_profiles.id, users.full_name, worker_profiles.profile_photo, worker_profiles.rating, worker_profiles.hourly_rate, service_categories.name as category_name, worker_profiles.current_lat, worker_profiles.current_lng").
-		Joins("JOIN users ON worker_profiles.user_id = users.id").
-		Joins("JOIN service_categories ON worker_profiles.category_id = service_categories.id").
-		Where("worker_profiles.is_available = ?", true)
-
-	if userLocation != nil {
-		// Add distance calculation (simplified)
-		query = query.Where("worker_profiles.current_lat IS NOT NULL AND worker_profiles.current_lng IS NOT NULL")
-	}
-
-	var results []struct {
-		ID       uint     `gorm:"column:id"`
-		Name     string   `gorm:"column:full_name"`
-		PhotoURL *string  `gorm:"column:profile_photo"`
-		Rating   float64  `gorm:"column:rating"`
-		Price    int      `gorm:"column:hourly_rate"`
-		Category string   `gorm:"column:category_name"`
-		Lat      *float64 `gorm:"column:current_lat"`
-		Lng      *float64 `gorm:"column:current_lng"`
+	snapshot, err := currentAIContext()
+	if err != nil {
+		return nil, err
 	}
+	return ai.nearestWorkers(snapshot, userLocation, 5), nil
+}
 
-	if err := query.Limit(5).Find(&results).Error; err != nil {
-		log.Printf("⚠️ Failed to get available workers: %v", err)
-		return workers, err
+func (ai *AIService) getServiceCategories() ([]models.ServiceCategory, error) {
+	snapshot, err := currentAIContext()
+	if err != nil {
+		return nil, err
 	}
+	return snapshot.categories, nil
+}
 
-	log.Printf("🔍 Found %d available workers for AI matching", len(results))
-	for i, result := range results {
-		log.Printf("👷 Worker %d: %s (%s) - Rating: %.1f, Rate: %d", i+1, result.Name, result.Category, result.Rating, result.Price)
-		log.Printf("🔍 Raw result %d: %+v", i+1, result)
+// ModerateText asks Gemini whether text is abusive, harassing or tries to move a job or
+// payment off the platform. category is empty when the text is acceptable.
+func (ai *AIService) ModerateText(text string) (string, error) {
+	if ai.apiKey == "" {
+		return "", fmt.Errorf("GEMINI_API_KEY not set")
 	}
 
-	for _, result := range results {
-		photoURL := ""
-		if result.PhotoURL != nil {
-			photoURL = *result.PhotoURL
-			log.Printf("🔍 Worker %s profile photo: %s", result.Name, photoURL)
-		} else {
-			log.Printf("🔍 Worker %s has no profile photo", result.Name)
-		}
+	prompt := fmt.Sprintf(`You moderate messages and reviews on a home repair marketplace.
+Reply with JSON only: {"violation": true|false, "category": "harassment" | "hate" | "threat" | "sexual" | "off_platform" | ""}.
+Use "off_platform" when the text asks to pay or continue the job outside the app.
 
-		// Calculate distance
-		distance := 2.5 // Default distance
-		if userLocation != nil && result.Lat != nil && result.Lng != nil {
-			distance = ai.calculateDistance(
-				userLocation.Latitude, userLocation.Longitude,
-				*result.Lat, *result.Lng,
-			)
-			log.Printf("🔍 Calculated distance: %.1fkm (User: %.6f,%.6f -> Worker: %.6f,%.6f)", 
-				distance, userLocation.Latitude, userLocation.Longitude, *result.Lat, *result.Lng)
-		} else {
-			log.Printf("🔍 Using default distance: %.1fkm (User: %v, Worker Lat: %v, Lng: %v)", 
-				distance, userLocation != nil, result.Lat != nil, result.Lng != nil)
-		}
+Text: %q`, text)
 
-		workerCard := WorkerCard{
-			ID:       int(result.ID),
-			Name:     result.Name,
-			PhotoURL: photoURL,
-			Rating:   result.Rating,
-			Distance: distance,
-			Category: result.Category,
-			Price:    result.Price,
-			Time:     "now",
-		}
-		
-		log.Printf("🔍 Created worker card: %+v", workerCard)
-		workers = append(workers, workerCard)
+	response, err := ai.callGeminiAPI(prompt, "", "")
+	if err != nil {
+		return "", err
 	}
 
-	return workers, nil
-}
-
-func (ai *AIService) getServiceCategories() ([]models.ServiceCategory, error) {
-	var categories []models.ServiceCategory
-	err := database.DB.Where("is_active = ?", true).Find(&categories).Error
-	return categories, err
+	var verdict struct {
+		Violation bool   `json:"violation"`
+		Category  string `json:"category"`
+	}
+	cleaned := strings.TrimSpace(strings.Trim(strings.TrimSpace(response), "`"))
+	cleaned = strings.TrimPrefix(cleaned, "json")
+	if err := json.Unmarshal([]byte(cleaned), &verdict); err != nil {
+		return "", fmt.Errorf("unexpected moderation response: %s", response)
+	}
+	if !verdict.Violation {
+		return "", nil
+	}
+	if verdict.Category == "" {
+		verdict.Category = "other"
+	}
+	return verdict.Category, nil
 }
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// archiveBatch caps how many requests are archived per transaction
+const archiveBatch = 200
+
+// archivedMessageColumns are the chat_messages columns copied to and from archived_chat_messages
+var archivedMessageColumns = strings.Join([]string{
+	"id", "chat_room_id", "sender_id", "sender_type", "content", "message_type", "audio_url",
+	"duration", "transcript", "transcript_status", "delivered_at", "read_at", "pinned_at", "pinned_by",
+	"latitude", "longitude", "live_until", "live_ended_at", "created_at", "updated_at", "deleted_at",
+}, ", ")
+
+// ArchivableRequestStatuses are the final statuses of requests the archival job picks up
+var ArchivableRequestStatuses = []models.CustomerServiceRequestStatus{
+	models.RequestStatusCompleted, models.RequestStatusCancelled,
+}
+
+// ErrRequestNotArchived is returned when restoring a request that is not archived
+var ErrRequestNotArchived = errors.New("service request is not archived")
+
+// ArchiveFilter narrows an archived request listing. Zero fields match everything.
+type ArchiveFilter struct {
+	CustomerID uint
+	WorkerID   uint
+	Status     string
+	From       time.Time // Archived at or after
+	To         time.Time // Archived before
+}
+
+// ArchiveService archives old completed and cancelled requests and restores them on demand.
+// Archived requests and their chat rooms stay in place with archived_at set, since service
+// history and ratings reference them, while their chat messages move to archived_chat_messages.
+type ArchiveService struct {
+	db *gorm.DB
+}
+
+// NewArchiveService creates a new archive service
+func NewArchiveService() *ArchiveService {
+	return &ArchiveService{
+		db: database.DB,
+	}
+}
+
+// ArchiveRetentionDays is how long completed and cancelled requests stay live after their last
+// update, configurable through ARCHIVE_RETENTION_DAYS
+func ArchiveRetentionDays() int {
+	if days, err := strconv.Atoi(os.Getenv("ARCHIVE_RETENTION_DAYS")); err == nil && days > 0 {
+		return days
+	}
+	return 365
+}
+
+// ArchiveBefore archives every completed or cancelled request last updated before cutoff, with its
+// chat rooms and messages, and returns how many requests were archived
+func (s *ArchiveService) ArchiveBefore(cutoff time.Time) (int, error) {
+	archived := 0
+	for {
+		var ids []uint
+		if err := s.db.Model(&models.CustomerServiceRequest{}).
+			Where("archived_at IS NULL AND status IN ? AND updated_at < ?", ArchivableRequestStatuses, cutoff).
+			Order("id").Limit(archiveBatch).
+			Pluck("id", &ids).Error; err != nil {
+			return archived, err
+		}
+		if len(ids) == 0 {
+			return archived, nil
+		}
+		if err := s.archive(ids, time.Now()); err != nil {
+			return archived, err
+		}
+		archived += len(ids)
+		if len(ids) < archiveBatch {
+			return archived, nil
+		}
+	}
+}
+
+// archive flags the requests and their chat rooms as archived and moves their messages
+func (s *ArchiveService) archive(requestIDs []uint, now time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.CustomerServiceRequest{}).Where("id IN ?", requestIDs).
+			UpdateColumn("archived_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ChatRoom{}).Where("service_request_id IN ?", requestIDs).
+			UpdateColumn("archived_at", now).Error; err != nil {
+			return err
+		}
+		rooms := tx.Model(&models.ChatRoom{}).Select("id").Where("service_request_id IN ?", requestIDs)
+		if err := tx.Exec("INSERT INTO archived_chat_messages ("+archivedMessageColumns+", archived_at) SELECT "+archivedMessageColumns+", ? FROM chat_messages WHERE chat_room_id IN (?)", now, rooms).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM chat_messages WHERE chat_room_id IN (?)", rooms).Error
+	})
+}
+
+// Restore brings an archived request back with its chat rooms and messages. It returns
+// ErrRequestNotArchived when the request is live.
+func (s *ArchiveService) Restore(requestID uint) (*models.CustomerServiceRequest, error) {
+	var request models.CustomerServiceRequest
+	if err := s.db.First(&request, requestID).Error; err != nil {
+		return nil, err
+	}
+	if request.ArchivedAt == nil {
+		return nil, ErrRequestNotArchived
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		rooms := tx.Model(&models.ChatRoom{}).Select("id").Where("service_request_id = ?", requestID)
+		if err := tx.Exec("INSERT INTO chat_messages ("+archivedMessageColumns+") SELECT "+archivedMessageColumns+" FROM archived_chat_messages WHERE chat_room_id IN (?)", rooms).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM archived_chat_messages WHERE chat_room_id IN (?)", rooms).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ChatRoom{}).Where("service_request_id = ?", requestID).
+			UpdateColumn("archived_at", nil).Error; err != nil {
+			return err
+		}
+		// Updating the request also bumps updated_at, so the nightly job leaves it live for another
+		// retention window
+		return tx.Model(&request).Update("archived_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	request.ArchivedAt = nil
+	return &request, nil
+}
+
+// List returns one page of archived requests, most recently archived first, with the total
+// matching count
+func (s *ArchiveService) List(filter ArchiveFilter, offset, limit int) ([]models.CustomerServiceRequest, int64, error) {
+	query := s.db.Model(&models.CustomerServiceRequest{}).Where("archived_at IS NOT NULL")
+	if filter.CustomerID != 0 {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.WorkerID != 0 {
+		query = query.Where("assigned_worker_id = ?", filter.WorkerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("archived_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("archived_at < ?", filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	requests := []models.CustomerServiceRequest{}
+	err := query.Preload("Customer").Preload("AssignedWorker.User").Preload("Category").
+		Order("archived_at DESC, id DESC").Offset(offset).Limit(limit).Find(&requests).Error
+	return requests, total, err
+}
+
+// Find returns an archived request with its chat rooms' archived messages, oldest first
+func (s *ArchiveService) Find(requestID uint) (*models.CustomerServiceRequest, []models.ArchivedChatMessage, error) {
+	var request models.CustomerServiceRequest
+	if err := s.db.Preload("Customer").Preload("AssignedWorker.User").Preload("Category").
+		Where("archived_at IS NOT NULL").First(&request, requestID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	messages := []models.ArchivedChatMessage{}
+	err := s.db.Where("chat_room_id IN (?)", s.db.Model(&models.ChatRoom{}).Select("id").Where("service_request_id = ?", requestID)).
+		Order("created_at ASC, id ASC").Find(&messages).Error
+	return &request, messages, err
+}
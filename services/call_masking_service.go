@@ -0,0 +1,466 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// CallSessionTTL is how long a masked-call session lasts. Sessions are closed sooner once the job
+// is over; a job still running when its session expires gets a new one on the next call.
+const CallSessionTTL = 12 * time.Hour
+
+// callableStatuses are the request statuses in which the customer and the worker can call each other
+var callableStatuses = []models.CustomerServiceRequestStatus{
+	models.RequestStatusAccepted, models.RequestStatusEnRoute, models.RequestStatusArrived, models.RequestStatusInProgress,
+}
+
+var (
+	// ErrCallNotAvailable is returned when the request has no assigned worker or the job is not active
+	ErrCallNotAvailable = errors.New("calls are only available while a worker is assigned to an active job")
+	// ErrInvalidCallWebhook is returned when a call event does not come from the provider
+	ErrInvalidCallWebhook = errors.New("invalid call webhook signature")
+)
+
+// CallMaskingService connects customers and workers through proxy numbers so neither sees the
+// other's real number
+type CallMaskingService struct {
+	db       *gorm.DB
+	provider CallMaskingProvider
+}
+
+// NewCallMaskingService creates a call masking service using the configured provider
+func NewCallMaskingService() *CallMaskingService {
+	return &CallMaskingService{
+		db:       database.DB,
+		provider: NewCallMaskingProvider(),
+	}
+}
+
+// ProxyNumber returns the number the caller dials to reach the other party of the request, opening
+// a session for the request's current worker when there is none. Each request is logged as a call
+// event.
+func (s *CallMaskingService) ProxyNumber(requestID uint, role models.UserRole) (*models.CallSession, string, error) {
+	session, err := s.session(requestID, &role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if role == models.RoleWorker {
+		return session, session.WorkerProxyNumber, nil
+	}
+	return session, session.CustomerProxyNumber, nil
+}
+
+// Session returns the request's call session like ProxyNumber, for showing its numbers without
+// logging a call event
+func (s *CallMaskingService) Session(requestID uint) (*models.CallSession, error) {
+	return s.session(requestID, nil)
+}
+
+// session finds or opens the call session of the request's current worker, logging a call event
+// for callerRole when it is set
+func (s *CallMaskingService) session(requestID uint, callerRole *models.UserRole) (*models.CallSession, error) {
+	var session models.CallSession
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var request models.CustomerServiceRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, requestID).Error; err != nil {
+			return err
+		}
+		if request.AssignedWorkerID == nil || !isCallable(request.Status) {
+			return ErrCallNotAvailable
+		}
+
+		now := time.Now()
+		err := tx.Where("service_request_id = ? AND worker_id = ? AND status = ? AND expires_at > ?",
+			request.ID, *request.AssignedWorkerID, models.CallSessionActive, now).First(&session).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			session, err = s.openSession(tx, request, now)
+		}
+		if err != nil || callerRole == nil {
+			return err
+		}
+
+		return tx.Create(&models.CallEvent{
+			CallSessionID:    session.ID,
+			ServiceRequestID: request.ID,
+			CallerRole:       *callerRole,
+			Status:           "requested",
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// openSession creates a session at the provider for the request's customer and assigned worker
+func (s *CallMaskingService) openSession(tx *gorm.DB, request models.CustomerServiceRequest, now time.Time) (models.CallSession, error) {
+	var customer models.User
+	if err := tx.First(&customer, request.CustomerID).Error; err != nil {
+		return models.CallSession{}, err
+	}
+	var worker models.WorkerProfile
+	if err := tx.Preload("User").First(&worker, *request.AssignedWorkerID).Error; err != nil {
+		return models.CallSession{}, err
+	}
+
+	proxy, err := s.provider.CreateSession(fmt.Sprintf("request-%d-worker-%d-%d", request.ID, worker.ID, now.Unix()),
+		CallSessionTTL, customer.PhoneNumber, worker.User.PhoneNumber)
+	if err != nil {
+		return models.CallSession{}, fmt.Errorf("creating %s call session: %w", s.provider.Name(), err)
+	}
+
+	session := models.CallSession{
+		ServiceRequestID:      request.ID,
+		WorkerID:              worker.ID,
+		Provider:              s.provider.Name(),
+		ProviderSessionID:     proxy.ID,
+		CustomerParticipantID: proxy.CustomerParticipantID,
+		CustomerProxyNumber:   proxy.CustomerProxyNumber,
+		WorkerParticipantID:   proxy.WorkerParticipantID,
+		WorkerProxyNumber:     proxy.WorkerProxyNumber,
+		Status:                models.CallSessionActive,
+		ExpiresAt:             now.Add(CallSessionTTL),
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		// Don't hold numbers for a session nobody can use
+		if closeErr := s.provider.CloseSession(proxy.ID); closeErr != nil {
+			log.Printf("⚠️ Failed to close orphaned call session %s: %v", proxy.ID, closeErr)
+		}
+		return models.CallSession{}, err
+	}
+	log.Printf("📞 Opened %s call session %d for service request %d", session.Provider, session.ID, request.ID)
+	return session, nil
+}
+
+// RecordWebhook logs a call event reported by the provider. Events for unknown sessions are ignored.
+func (s *CallMaskingService) RecordWebhook(r *http.Request) error {
+	report, err := s.provider.ParseCallEvent(r)
+	if err != nil || report == nil {
+		return err
+	}
+
+	var session models.CallSession
+	if err := s.db.Where("provider = ? AND provider_session_id = ?", s.provider.Name(), report.SessionID).
+		First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("⚠️ Call event for unknown session %s", report.SessionID)
+			return nil
+		}
+		return err
+	}
+
+	event := models.CallEvent{
+		CallSessionID:    session.ID,
+		ServiceRequestID: session.ServiceRequestID,
+		ProviderCallID:   report.CallID,
+		Status:           report.Status,
+	}
+	switch report.ParticipantID {
+	case session.CustomerParticipantID:
+		event.CallerRole = models.RoleCustomer
+	case session.WorkerParticipantID:
+		event.CallerRole = models.RoleWorker
+	}
+	return s.db.Create(&event).Error
+}
+
+// CloseFinished closes active sessions whose job is over, whose worker changed or that expired,
+// so their proxy numbers go back to the provider's pool. It returns how many were closed.
+func (s *CallMaskingService) CloseFinished(now time.Time) (int, error) {
+	var sessions []models.CallSession
+	if err := s.db.Joins("JOIN customer_service_requests r ON r.id = call_sessions.service_request_id").
+		Where("call_sessions.status = ?", models.CallSessionActive).
+		Where("call_sessions.expires_at <= ? OR r.status NOT IN ? OR r.assigned_worker_id IS DISTINCT FROM call_sessions.worker_id",
+			now, callableStatuses).
+		Find(&sessions).Error; err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for _, session := range sessions {
+		if err := s.provider.CloseSession(session.ProviderSessionID); err != nil {
+			log.Printf("❌ Failed to close call session %d: %v", session.ID, err)
+			continue
+		}
+		if err := s.db.Model(&session).Updates(map[string]interface{}{
+			"status":    models.CallSessionClosed,
+			"closed_at": now,
+		}).Error; err != nil {
+			return closed, err
+		}
+		closed++
+	}
+	return closed, nil
+}
+
+// isCallable reports whether the parties of a request in status may call each other
+func isCallable(status models.CustomerServiceRequestStatus) bool {
+	for _, callable := range callableStatuses {
+		if status == callable {
+			return true
+		}
+	}
+	return false
+}
+
+// ProxySession is a session opened at the provider, with each party's participant and proxy number
+type ProxySession struct {
+	ID                    string
+	CustomerParticipantID string
+	CustomerProxyNumber   string
+	WorkerParticipantID   string
+	WorkerProxyNumber     string
+}
+
+// CallEventReport is a call status reported by the provider
+type CallEventReport struct {
+	SessionID     string
+	CallID        string
+	ParticipantID string // Participant who placed the call
+	Status        string
+}
+
+// CallMaskingProvider is implemented by every call masking backend
+type CallMaskingProvider interface {
+	Name() string
+	CreateSession(uniqueName string, ttl time.Duration, customerPhone, workerPhone string) (*ProxySession, error)
+	CloseSession(sessionID string) error
+	// ParseCallEvent authenticates a webhook and returns the call event it reports, or nil for
+	// events that are not about calls
+	ParseCallEvent(r *http.Request) (*CallEventReport, error)
+}
+
+// NewCallMaskingProvider returns the provider selected by CALL_MASKING_PROVIDER ("twilio"). Without
+// a configured provider sessions are only logged, which is what local development relies on.
+func NewCallMaskingProvider() CallMaskingProvider {
+	switch strings.ToLower(os.Getenv("CALL_MASKING_PROVIDER")) {
+	case "twilio":
+		sid, token, service := os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_PROXY_SERVICE_SID")
+		if sid == "" || token == "" || service == "" {
+			log.Printf("⚠️ Twilio Proxy credentials not set, call sessions will only be logged")
+			return &LogCallMaskingProvider{}
+		}
+		return &TwilioProxyProvider{
+			accountSID:  sid,
+			authToken:   token,
+			serviceSID:  service,
+			callbackURL: os.Getenv("CALL_WEBHOOK_URL"),
+			client:      &http.Client{Timeout: 10 * time.Second},
+		}
+	default:
+		return &LogCallMaskingProvider{}
+	}
+}
+
+// TwilioProxyProvider masks calls with Twilio Proxy sessions. Closing a session returns its proxy
+// numbers to the Proxy service's pool.
+type TwilioProxyProvider struct {
+	accountSID  string
+	authToken   string
+	serviceSID  string
+	callbackURL string // Public URL of the webhook, used to check Twilio's signature
+	client      *http.Client
+}
+
+// Name returns the provider name
+func (p *TwilioProxyProvider) Name() string {
+	return "twilio"
+}
+
+// CreateSession opens a voice-only session and adds the customer and the worker to it
+func (p *TwilioProxyProvider) CreateSession(uniqueName string, ttl time.Duration, customerPhone, workerPhone string) (*ProxySession, error) {
+	var created struct {
+		SID string `json:"sid"`
+	}
+	sessionsURL := fmt.Sprintf("https://proxy.twilio.com/v1/Services/%s/Sessions", p.serviceSID)
+	form := url.Values{"UniqueName": {uniqueName}, "Ttl": {fmt.Sprint(int(ttl.Seconds()))}, "Mode": {"voice-only"}}
+	if err := p.post(sessionsURL, form, &created); err != nil {
+		return nil, err
+	}
+
+	session := &ProxySession{ID: created.SID}
+	var err error
+	if session.CustomerParticipantID, session.CustomerProxyNumber, err = p.addParticipant(created.SID, customerPhone, "customer"); err == nil {
+		session.WorkerParticipantID, session.WorkerProxyNumber, err = p.addParticipant(created.SID, workerPhone, "worker")
+	}
+	if err != nil {
+		if closeErr := p.CloseSession(created.SID); closeErr != nil {
+			log.Printf("⚠️ Failed to close half-created Twilio Proxy session %s: %v", created.SID, closeErr)
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+// addParticipant adds phone to a session and returns the participant and the proxy number they use
+func (p *TwilioProxyProvider) addParticipant(sessionSID, phone, name string) (string, string, error) {
+	var participant struct {
+		SID             string `json:"sid"`
+		ProxyIdentifier string `json:"proxy_identifier"`
+	}
+	participantsURL := fmt.Sprintf("https://proxy.twilio.com/v1/Services/%s/Sessions/%s/Participants", p.serviceSID, sessionSID)
+	if err := p.post(participantsURL, url.Values{"Identifier": {phone}, "FriendlyName": {name}}, &participant); err != nil {
+		return "", "", err
+	}
+	return participant.SID, participant.ProxyIdentifier, nil
+}
+
+// CloseSession deletes the session; a session Twilio no longer knows is already closed
+func (p *TwilioProxyProvider) CloseSession(sessionID string) error {
+	req, err := http.NewRequest(http.MethodDelete,
+		fmt.Sprintf("https://proxy.twilio.com/v1/Services/%s/Sessions/%s", p.serviceSID, sessionID), nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || (resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("twilio proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// ParseCallEvent checks the X-Twilio-Signature of a Proxy interaction callback and reads the voice
+// interaction it reports
+func (p *TwilioProxyProvider) ParseCallEvent(r *http.Request) (*CallEventReport, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+	callbackURL := p.callbackURL
+	if callbackURL == "" {
+		callbackURL = "https://" + r.Host + r.URL.RequestURI()
+	}
+	if !p.validSignature(callbackURL, r.PostForm, r.Header.Get("X-Twilio-Signature")) {
+		return nil, ErrInvalidCallWebhook
+	}
+	if r.PostForm.Get("interactionType") != "Voice" {
+		return nil, nil
+	}
+
+	status := r.PostForm.Get("outboundResourceStatus")
+	if status == "" {
+		status = r.PostForm.Get("inboundResourceStatus")
+	}
+	return &CallEventReport{
+		SessionID:     r.PostForm.Get("interactionSessionSid"),
+		CallID:        r.PostForm.Get("interactionSid"),
+		ParticipantID: r.PostForm.Get("inboundParticipantSid"),
+		Status:        status,
+	}, nil
+}
+
+// validSignature checks signature against the HMAC-SHA1 of the URL followed by every form field
+// name and value sorted by name, keyed with the auth token
+func (p *TwilioProxyProvider) validSignature(callbackURL string, form url.Values, signature string) bool {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var payload strings.Builder
+	payload.WriteString(callbackURL)
+	for _, key := range keys {
+		for _, value := range form[key] {
+			payload.WriteString(key)
+			payload.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(p.authToken))
+	mac.Write([]byte(payload.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// post sends form to a Proxy API URL and decodes the JSON answer into out
+func (p *TwilioProxyProvider) post(apiURL string, form url.Values, out interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("twilio proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// LogCallMaskingProvider writes sessions to the server log instead of opening them. Both parties
+// get CALL_PROXY_DEV_NUMBER, or a placeholder, as their proxy number.
+type LogCallMaskingProvider struct{}
+
+// Name returns the provider name
+func (p *LogCallMaskingProvider) Name() string {
+	return "log"
+}
+
+// CreateSession logs the session
+func (p *LogCallMaskingProvider) CreateSession(uniqueName string, ttl time.Duration, customerPhone, workerPhone string) (*ProxySession, error) {
+	number := os.Getenv("CALL_PROXY_DEV_NUMBER")
+	if number == "" {
+		number = "+22200000000"
+	}
+	log.Printf("📞 Call session %s between %s and %s for %s", uniqueName, customerPhone, workerPhone, ttl)
+	return &ProxySession{
+		ID:                    uniqueName,
+		CustomerParticipantID: "customer",
+		CustomerProxyNumber:   number,
+		WorkerParticipantID:   "worker",
+		WorkerProxyNumber:     number,
+	}, nil
+}
+
+// CloseSession logs the closing
+func (p *LogCallMaskingProvider) CloseSession(sessionID string) error {
+	log.Printf("📞 Call session %s closed", sessionID)
+	return nil
+}
+
+// ParseCallEvent reads {"session_id", "call_id", "participant_id", "status"} as JSON, so call
+// events can be simulated locally
+func (p *LogCallMaskingProvider) ParseCallEvent(r *http.Request) (*CallEventReport, error) {
+	var body struct {
+		SessionID     string `json:"session_id"`
+		CallID        string `json:"call_id"`
+		ParticipantID string `json:"participant_id"`
+		Status        string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return &CallEventReport{SessionID: body.SessionID, CallID: body.CallID, ParticipantID: body.ParticipantID, Status: body.Status}, nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// MaxWorkerCategories is how many categories a worker can serve besides their main one
+const MaxWorkerCategories = 20
+
+var (
+	// ErrUnknownCategory is returned when a category does not exist or is inactive
+	ErrUnknownCategory = errors.New("unknown category")
+	// ErrCategoryDepth is returned when a category would be nested more than two levels deep
+	ErrCategoryDepth = errors.New("subcategories must have a top-level parent and cannot have subcategories of their own")
+	// ErrWorkerCategoryExists is returned when a worker already serves a category
+	ErrWorkerCategoryExists = errors.New("worker already serves this category")
+	// ErrTooManyWorkerCategories is returned when a worker already serves MaxWorkerCategories extra categories
+	ErrTooManyWorkerCategories = errors.New("worker serves too many categories")
+)
+
+// CategoryService resolves the category hierarchy and the categories workers serve, which match
+// workers with requests. A request in a subcategory reaches workers serving it or its parent; a
+// request in a top-level category reaches workers serving that category. Requests with extra
+// categories reach the workers of each of them the same way.
+type CategoryService struct {
+	db *gorm.DB
+}
+
+// NewCategoryService creates a new category service
+func NewCategoryService() *CategoryService {
+	return &CategoryService{
+		db: database.DB,
+	}
+}
+
+// RequestCategoryIDs returns the categories whose workers can take a request in categoryID: the
+// category itself and its parent
+func (s *CategoryService) RequestCategoryIDs(categoryID uint) ([]uint, error) {
+	var category models.ServiceCategory
+	if err := s.db.Select("id, parent_id").First(&category, categoryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return []uint{categoryID}, nil
+		}
+		return nil, err
+	}
+	if category.ParentID != nil {
+		return []uint{categoryID, *category.ParentID}, nil
+	}
+	return []uint{categoryID}, nil
+}
+
+// TargetCategoryIDs returns the categories whose workers can take a request: its main category
+// and extra categories, each with its parent
+func (s *CategoryService) TargetCategoryIDs(request models.CustomerServiceRequest) ([]uint, error) {
+	requestCategories, err := s.requestCategories(request)
+	if err != nil {
+		return nil, err
+	}
+	var parents []uint
+	if err := s.db.Model(&models.ServiceCategory{}).Where("id IN ? AND parent_id IS NOT NULL", requestCategories).Pluck("parent_id", &parents).Error; err != nil {
+		return nil, err
+	}
+	return uniqueIDs(append(requestCategories, parents...)), nil
+}
+
+// ServedRequestCategory returns the first of a request's categories, main category first, that a
+// worker serves, or 0 when they serve none of them
+func (s *CategoryService) ServedRequestCategory(worker models.WorkerProfile, request models.CustomerServiceRequest) (uint, error) {
+	served, err := s.WorkerCategoryIDs(worker)
+	if err != nil {
+		return 0, err
+	}
+	requestCategories, err := s.requestCategories(request)
+	if err != nil {
+		return 0, err
+	}
+	for _, categoryID := range requestCategories {
+		for _, id := range served {
+			if id == categoryID {
+				return categoryID, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// ExtraCategories checks the extra categories a customer picked for a new request and returns
+// them deduplicated, without the main category. They must exist and be active.
+func (s *CategoryService) ExtraCategories(mainCategoryID uint, categoryIDs []uint) ([]models.RequestCategory, error) {
+	var extra []uint
+	for _, id := range uniqueIDs(categoryIDs) {
+		if id != mainCategoryID {
+			extra = append(extra, id)
+		}
+	}
+	if len(extra) == 0 {
+		return nil, nil
+	}
+	var active int64
+	if err := s.db.Model(&models.ServiceCategory{}).Where("id IN ? AND is_active = ?", extra, true).Count(&active).Error; err != nil {
+		return nil, err
+	}
+	if int(active) != len(extra) {
+		return nil, ErrUnknownCategory
+	}
+	requestCategories := make([]models.RequestCategory, len(extra))
+	for i, id := range extra {
+		requestCategories[i] = models.RequestCategory{CategoryID: id}
+	}
+	return requestCategories, nil
+}
+
+// requestCategories returns a request's main category followed by its extra categories
+func (s *CategoryService) requestCategories(request models.CustomerServiceRequest) ([]uint, error) {
+	var extra []uint
+	if err := s.db.Model(&models.RequestCategory{}).Where("service_request_id = ?", request.ID).Order("id").Pluck("category_id", &extra).Error; err != nil {
+		return nil, err
+	}
+	return uniqueIDs(append([]uint{request.CategoryID}, extra...)), nil
+}
+
+// UrgentAllowed reports whether customers may ask for urgent service in a category: neither it
+// nor its parent has urgent requests turned off
+func (s *CategoryService) UrgentAllowed(categoryID uint) (bool, error) {
+	categoryIDs, err := s.RequestCategoryIDs(categoryID)
+	if err != nil {
+		return false, err
+	}
+	var disabled int64
+	if err := s.db.Model(&models.ServiceCategory{}).Where("id IN ? AND urgent_disabled = ?", categoryIDs, true).Count(&disabled).Error; err != nil {
+		return false, err
+	}
+	return disabled == 0, nil
+}
+
+// WorkerCategoryIDs returns the categories whose requests a worker receives: their main category
+// and verified extra categories, each with its subcategories
+func (s *CategoryService) WorkerCategoryIDs(worker models.WorkerProfile) ([]uint, error) {
+	served := []uint{worker.CategoryID}
+	var extra []uint
+	if err := s.db.Model(&models.WorkerServiceCategory{}).Where("worker_id = ? AND verified_at IS NOT NULL", worker.ID).Pluck("category_id", &extra).Error; err != nil {
+		return nil, err
+	}
+	served = append(served, extra...)
+
+	var children []uint
+	if err := s.db.Model(&models.ServiceCategory{}).Where("parent_id IN ?", served).Pluck("id", &children).Error; err != nil {
+		return nil, err
+	}
+	return uniqueIDs(append(served, children...)), nil
+}
+
+// ListWorkerCategories returns a worker's extra categories, verified or not
+func (s *CategoryService) ListWorkerCategories(workerID uint) ([]models.WorkerServiceCategory, error) {
+	var categories []models.WorkerServiceCategory
+	err := s.db.Preload("Category").Where("worker_id = ?", workerID).Order("created_at ASC").Find(&categories).Error
+	return categories, err
+}
+
+// AddWorkerCategory adds an active category to those a worker serves. It takes effect once an
+// admin verifies it.
+func (s *CategoryService) AddWorkerCategory(worker models.WorkerProfile, categoryID uint) (*models.WorkerServiceCategory, error) {
+	if categoryID == worker.CategoryID {
+		return nil, ErrWorkerCategoryExists
+	}
+	var category models.ServiceCategory
+	if err := s.db.Where("id = ? AND is_active = ?", categoryID, true).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnknownCategory
+		}
+		return nil, err
+	}
+
+	var count int64
+	if err := s.db.Model(&models.WorkerServiceCategory{}).Where("worker_id = ?", worker.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= MaxWorkerCategories {
+		return nil, ErrTooManyWorkerCategories
+	}
+
+	workerCategory := models.WorkerServiceCategory{WorkerID: worker.ID, CategoryID: categoryID}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&workerCategory)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrWorkerCategoryExists
+	}
+	workerCategory.Category = category
+	return &workerCategory, nil
+}
+
+// RemoveWorkerCategory stops a worker serving one of their extra categories
+func (s *CategoryService) RemoveWorkerCategory(workerID, categoryID uint) error {
+	result := s.db.Where("worker_id = ? AND category_id = ?", workerID, categoryID).Delete(&models.WorkerServiceCategory{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// VerifyWorkerCategory records an admin's decision on a worker's extra category; unverified
+// categories stay listed but bring no requests
+func (s *CategoryService) VerifyWorkerCategory(workerID, categoryID, adminID uint, verified bool) (*models.WorkerServiceCategory, error) {
+	var workerCategory models.WorkerServiceCategory
+	if err := s.db.Preload("Category").Where("worker_id = ? AND category_id = ?", workerID, categoryID).First(&workerCategory).Error; err != nil {
+		return nil, err
+	}
+	updates := map[string]interface{}{"verified_at": nil, "verified_by": nil}
+	if verified {
+		now := time.Now()
+		updates = map[string]interface{}{"verified_at": now, "verified_by": adminID}
+	}
+	if err := s.db.Model(&workerCategory).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Preload("Category").First(&workerCategory, workerCategory.ID).Error; err != nil {
+		return nil, err
+	}
+	return &workerCategory, nil
+}
+
+// ValidateParent checks that parentID can hold categoryID as a subcategory: it must be an existing
+// top-level category, and categoryID (0 for a new category) must not have subcategories itself
+func (s *CategoryService) ValidateParent(categoryID uint, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == categoryID {
+		return ErrCategoryDepth
+	}
+	var parent models.ServiceCategory
+	if err := s.db.Select("id, parent_id").First(&parent, *parentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnknownCategory
+		}
+		return err
+	}
+	if parent.ParentID != nil {
+		return ErrCategoryDepth
+	}
+	if categoryID != 0 {
+		var children int64
+		if err := s.db.Model(&models.ServiceCategory{}).Where("parent_id = ?", categoryID).Count(&children).Error; err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrCategoryDepth
+		}
+	}
+	return nil
+}
+
+// WorkerInCategoriesScope restricts a worker_profiles query to workers whose main category or a
+// verified extra category is one of categoryIDs
+func WorkerInCategoriesScope(categoryIDs []uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(workerServesSQL("worker_profiles"), categoryIDs, categoryIDs)
+	}
+}
+
+// RequestInCategoriesScope restricts a customer_service_requests query to requests whose main
+// category or an extra category is one of categoryIDs
+func RequestInCategoriesScope(categoryIDs []uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(customer_service_requests.category_id IN ? OR customer_service_requests.id IN (SELECT service_request_id FROM request_categories WHERE category_id IN ?))", categoryIDs, categoryIDs)
+	}
+}
+
+// workerServesSQL is the condition for a worker_profiles row, aliased as profiles, serving one of
+// the categories bound twice to it
+func workerServesSQL(profiles string) string {
+	return "(" + profiles + ".category_id IN ? OR " + profiles + ".id IN (SELECT worker_id FROM worker_categories WHERE category_id IN ? AND verified_at IS NOT NULL))"
+}
+
+// uniqueIDs drops duplicate and zero IDs, keeping the first occurrence of each
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// moderationTermsTTL is how long the word lists are cached before being reloaded
+const moderationTermsTTL = time.Minute
+
+var (
+	// phoneNumberPattern matches 8 or more digits, optionally after +222 or 00222 and split by
+	// spaces, dots or dashes, the way people spell out a number to get around filters
+	phoneNumberPattern = regexp.MustCompile(`(?:\+|00)?(?:\d[\s.\-]?){7,13}\d`)
+	emailPattern       = regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)
+	isoDatePattern     = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}$`) // Looks like a phone number to phoneNumberPattern
+
+	moderationTermsMu       sync.Mutex
+	moderationTermsLoadedAt time.Time
+	profanityTerms          map[string]bool
+	paymentBaitTerms        []string
+)
+
+// ModerationResult is user content after filtering, with every rule it broke
+type ModerationResult struct {
+	Text       string
+	Violations []ModerationViolation
+}
+
+// ModerationViolation is one broken rule in a piece of content
+type ModerationViolation struct {
+	Kind   models.ContentViolationKind
+	Detail string
+}
+
+// Flagged reports whether any rule was broken
+func (r ModerationResult) Flagged() bool {
+	return len(r.Violations) > 0
+}
+
+// ContentModerationService masks profanity and shared contact details in chat messages and
+// reviews, and logs every violation for admins. When CONTENT_MODERATION_AI is true, content is
+// also checked by Gemini in the background.
+type ContentModerationService struct{}
+
+// NewContentModerationService creates a new content moderation service
+func NewContentModerationService() *ContentModerationService {
+	return &ContentModerationService{}
+}
+
+// Filter masks profanity, phone numbers and email addresses in text and lists the violations.
+// Payment bait is detected but left as written.
+func (s *ContentModerationService) Filter(text string) ModerationResult {
+	result := ModerationResult{Text: text}
+	if strings.TrimSpace(text) == "" {
+		return result
+	}
+
+	profanity, paymentBait := loadModerationTerms()
+
+	if masked, matched := maskWords(result.Text, profanity); len(matched) > 0 {
+		result.Text = masked
+		result.Violations = append(result.Violations, ModerationViolation{Kind: models.ViolationProfanity, Detail: strings.Join(matched, ", ")})
+	}
+	phoneFound := false
+	result.Text = phoneNumberPattern.ReplaceAllStringFunc(result.Text, func(match string) string {
+		if isoDatePattern.MatchString(strings.TrimSpace(match)) {
+			return match
+		}
+		phoneFound = true
+		return "[phone number hidden]"
+	})
+	if phoneFound {
+		result.Violations = append(result.Violations, ModerationViolation{Kind: models.ViolationPhoneNumber})
+	}
+	if emailPattern.MatchString(result.Text) {
+		result.Text = emailPattern.ReplaceAllString(result.Text, "[email hidden]")
+		result.Violations = append(result.Violations, ModerationViolation{Kind: models.ViolationEmail})
+	}
+
+	lower := strings.ToLower(text)
+	var baits []string
+	for _, phrase := range paymentBait {
+		if strings.Contains(lower, phrase) {
+			baits = append(baits, phrase)
+		}
+	}
+	if len(baits) > 0 {
+		result.Violations = append(result.Violations, ModerationViolation{Kind: models.ViolationPaymentBait, Detail: strings.Join(baits, ", ")})
+	}
+
+	return result
+}
+
+// Record logs result's violations against the stored content and, when enabled, has the
+// original text reviewed by the AI check in the background
+func (s *ContentModerationService) Record(userID uint, sourceType string, sourceID uint, original string, result ModerationResult) {
+	for _, violation := range result.Violations {
+		logContentViolation(userID, sourceType, sourceID, violation, original, result.Text)
+	}
+	if result.Flagged() {
+		log.Printf("🛡️ Filtered %s %d from user %d (%d violations)", sourceType, sourceID, userID, len(result.Violations))
+	}
+
+	if os.Getenv("CONTENT_MODERATION_AI") == "true" && strings.TrimSpace(original) != "" {
+		go func() {
+			category, err := NewAIService().ModerateText(original)
+			if err != nil {
+				log.Printf("⚠️ AI moderation of %s %d failed: %v", sourceType, sourceID, err)
+				return
+			}
+			if category != "" {
+				logContentViolation(userID, sourceType, sourceID, ModerationViolation{Kind: models.ViolationAIFlagged, Detail: category}, original, result.Text)
+			}
+		}()
+	}
+}
+
+// InvalidateModerationTerms makes the next filter reload the word lists, after an admin change
+func InvalidateModerationTerms() {
+	moderationTermsMu.Lock()
+	moderationTermsLoadedAt = time.Time{}
+	moderationTermsMu.Unlock()
+}
+
+// logContentViolation stores one violation for admin review
+func logContentViolation(userID uint, sourceType string, sourceID uint, violation ModerationViolation, original, filtered string) {
+	record := models.ContentViolation{
+		UserID:       userID,
+		SourceType:   sourceType,
+		SourceID:     sourceID,
+		Kind:         violation.Kind,
+		Detail:       violation.Detail,
+		OriginalText: original,
+		FilteredText: filtered,
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("⚠️ Failed to log %s violation for %s %d: %v", violation.Kind, sourceType, sourceID, err)
+	}
+}
+
+// loadModerationTerms returns the cached word lists, reloading them once they are stale. The
+// previous lists are kept if the reload fails.
+func loadModerationTerms() (map[string]bool, []string) {
+	moderationTermsMu.Lock()
+	defer moderationTermsMu.Unlock()
+
+	if time.Since(moderationTermsLoadedAt) < moderationTermsTTL {
+		return profanityTerms, paymentBaitTerms
+	}
+
+	var terms []models.ModerationTerm
+	if err := database.DB.Find(&terms).Error; err != nil {
+		log.Printf("⚠️ Failed to load moderation terms: %v", err)
+		return profanityTerms, paymentBaitTerms
+	}
+
+	profanity := make(map[string]bool)
+	var paymentBait []string
+	for _, term := range terms {
+		value := strings.ToLower(strings.TrimSpace(term.Term))
+		switch term.Kind {
+		case models.ModerationTermProfanity:
+			profanity[value] = true
+		case models.ModerationTermPaymentBait:
+			paymentBait = append(paymentBait, value)
+		}
+	}
+	profanityTerms, paymentBaitTerms = profanity, paymentBait
+	moderationTermsLoadedAt = time.Now()
+	return profanityTerms, paymentBaitTerms
+}
+
+// maskWords replaces every word of text found in words with asterisks after its first letter,
+// returning the masked text and the distinct words matched. Words are runs of letters and
+// digits, so this works for Arabic and French as well as English.
+func maskWords(text string, words map[string]bool) (string, []string) {
+	if len(words) == 0 {
+		return text, nil
+	}
+
+	runes := []rune(text)
+	seen := make(map[string]bool)
+	var matched []string
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		word := strings.ToLower(string(runes[start:end]))
+		if words[word] {
+			for i := start + 1; i < end; i++ {
+				runes[i] = '*'
+			}
+			if !seen[word] {
+				seen[word] = true
+				matched = append(matched, word)
+			}
+		}
+		start = end
+	}
+	return string(runes), matched
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
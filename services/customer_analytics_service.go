@@ -0,0 +1,328 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// Dashboard list sizes
+const (
+	customerTopWorkers       = 5
+	customerUpcomingServices = 5
+)
+
+// CustomerMonthSpend is what a customer spent in one month of the year
+type CustomerMonthSpend struct {
+	Month    string       `json:"month"` // YYYY-MM
+	Services int          `json:"services"`
+	Spend    money.Amount `json:"spend"`
+	Tips     money.Amount `json:"tips"`
+}
+
+// CustomerCategorySpend is what a customer spent in one category over the year
+type CustomerCategorySpend struct {
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category_name"`
+	Services     int          `json:"services"`
+	Spend        money.Amount `json:"spend"`
+}
+
+// CustomerWorkerUsage is a worker the customer hired during the year
+type CustomerWorkerUsage struct {
+	WorkerID     uint         `json:"worker_id"`
+	FullName     string       `json:"full_name"`
+	ProfilePhoto *string      `json:"profile_photo"`
+	Services     int          `json:"services"`
+	Spend        money.Amount `json:"spend"`
+}
+
+// CustomerRatingsGiven summarizes the ratings a customer gave during the year
+type CustomerRatingsGiven struct {
+	Count        int     `json:"count"`
+	AverageStars float64 `json:"average_stars"`
+}
+
+// CustomerUpcomingService is a scheduled request that has not happened yet
+type CustomerUpcomingService struct {
+	ID                uint                                `json:"id"`
+	Title             string                              `json:"title"`
+	CategoryName      string                              `json:"category_name"`
+	Status            models.CustomerServiceRequestStatus `json:"status"`
+	ScheduledFor      time.Time                           `json:"scheduled_for"`
+	ScheduledForLocal *time.Time                          `json:"scheduled_for_local"` // At the customer's UTC offset
+	WorkerName        string                              `json:"worker_name"`         // Empty until a worker is assigned
+}
+
+// CustomerDashboard is a customer's year in review
+type CustomerDashboard struct {
+	Year          int                       `json:"year"`
+	Services      int                       `json:"services"`
+	Spend         money.Amount              `json:"spend"`
+	Tips          money.Amount              `json:"tips"`
+	Currency      string                    `json:"currency"`
+	PerMonth      []CustomerMonthSpend      `json:"spend_per_month"`
+	PerCategory   []CustomerCategorySpend   `json:"spend_per_category"`
+	TopWorkers    []CustomerWorkerUsage     `json:"top_workers"`
+	RatingsGiven  CustomerRatingsGiven      `json:"ratings_given"`
+	Upcoming      []CustomerUpcomingService `json:"upcoming"`
+	FavoriteMonth string                    `json:"favorite_month"` // Month with the most services, empty without any
+}
+
+// CustomerAnalyticsService pre-aggregates customers' service history into monthly spend and builds
+// their dashboard from it
+type CustomerAnalyticsService struct {
+	db *gorm.DB
+}
+
+// NewCustomerAnalyticsService creates a new customer analytics service
+func NewCustomerAnalyticsService() *CustomerAnalyticsService {
+	return &CustomerAnalyticsService{
+		db: database.DB,
+	}
+}
+
+// AggregateCustomer replaces the customer's monthly spend rows with fresh ones from their service
+// history
+func (s *CustomerAnalyticsService) AggregateCustomer(customerID uint) error {
+	var histories []models.ServiceHistory
+	if err := s.db.Select("category_id", "worker_id", "completed_at", "final_price", "refunded_amount", "tip_amount").
+		Where("customer_id = ?", customerID).Find(&histories).Error; err != nil {
+		return err
+	}
+
+	type spendKey struct {
+		year, month          int
+		categoryID, workerID uint
+	}
+	now := time.Now()
+	rows := make(map[spendKey]*models.CustomerMonthlySpend)
+	for _, h := range histories {
+		key := spendKey{h.CompletedAt.Year(), int(h.CompletedAt.Month()), h.CategoryID, h.WorkerID}
+		row, ok := rows[key]
+		if !ok {
+			row = &models.CustomerMonthlySpend{
+				CustomerID: customerID,
+				Year:       key.year,
+				Month:      key.month,
+				CategoryID: key.categoryID,
+				WorkerID:   key.workerID,
+				ComputedAt: now,
+			}
+			rows[key] = row
+		}
+		row.Services++
+		if h.FinalPrice != nil {
+			row.Spend += *h.FinalPrice
+		}
+		row.Spend -= h.RefundedAmount
+		row.Tips += h.TipAmount
+	}
+
+	spend := make([]models.CustomerMonthlySpend, 0, len(rows))
+	for _, row := range rows {
+		spend = append(spend, *row)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ?", customerID).Delete(&models.CustomerMonthlySpend{}).Error; err != nil {
+			return err
+		}
+		if len(spend) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(spend, 500).Error
+	})
+}
+
+// AggregateUpdatedSince re-aggregates every customer whose service history changed since the given
+// time, picking up refunds and tips added after completion. It returns how many were aggregated.
+func (s *CustomerAnalyticsService) AggregateUpdatedSince(since time.Time) (int, error) {
+	var customerIDs []uint
+	if err := s.db.Model(&models.ServiceHistory{}).Distinct("customer_id").
+		Where("updated_at >= ?", since).Pluck("customer_id", &customerIDs).Error; err != nil {
+		return 0, err
+	}
+	for i, customerID := range customerIDs {
+		if err := s.AggregateCustomer(customerID); err != nil {
+			return i, err
+		}
+	}
+	return len(customerIDs), nil
+}
+
+// Dashboard builds the customer's year in review. Customers whose history was never aggregated are
+// aggregated first.
+func (s *CustomerAnalyticsService) Dashboard(customerID uint, year int) (*CustomerDashboard, error) {
+	var aggregated int64
+	if err := s.db.Model(&models.CustomerMonthlySpend{}).Where("customer_id = ?", customerID).Count(&aggregated).Error; err != nil {
+		return nil, err
+	}
+	if aggregated == 0 {
+		if err := s.AggregateCustomer(customerID); err != nil {
+			return nil, err
+		}
+	}
+
+	var rows []models.CustomerMonthlySpend
+	if err := s.db.Where("customer_id = ? AND year = ?", customerID, year).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	dashboard := &CustomerDashboard{
+		Year:        year,
+		Currency:    NewRegionService().Default().Currency,
+		PerMonth:    make([]CustomerMonthSpend, 12),
+		PerCategory: []CustomerCategorySpend{},
+		TopWorkers:  []CustomerWorkerUsage{},
+		Upcoming:    []CustomerUpcomingService{},
+	}
+	for i := range dashboard.PerMonth {
+		dashboard.PerMonth[i].Month = fmt.Sprintf("%04d-%02d", year, i+1)
+	}
+	categories := map[uint]*CustomerCategorySpend{}
+	workers := map[uint]*CustomerWorkerUsage{}
+	for _, row := range rows {
+		dashboard.Services += row.Services
+		dashboard.Spend += row.Spend
+		dashboard.Tips += row.Tips
+
+		month := &dashboard.PerMonth[row.Month-1]
+		month.Services += row.Services
+		month.Spend += row.Spend
+		month.Tips += row.Tips
+
+		if categories[row.CategoryID] == nil {
+			categories[row.CategoryID] = &CustomerCategorySpend{CategoryID: row.CategoryID}
+		}
+		categories[row.CategoryID].Services += row.Services
+		categories[row.CategoryID].Spend += row.Spend
+
+		if workers[row.WorkerID] == nil {
+			workers[row.WorkerID] = &CustomerWorkerUsage{WorkerID: row.WorkerID}
+		}
+		workers[row.WorkerID].Services += row.Services
+		workers[row.WorkerID].Spend += row.Spend
+	}
+
+	busiest := 0
+	for i := range dashboard.PerMonth {
+		if dashboard.PerMonth[i].Services > busiest {
+			busiest = dashboard.PerMonth[i].Services
+			dashboard.FavoriteMonth = dashboard.PerMonth[i].Month
+		}
+	}
+
+	if err := s.fillCategories(dashboard, categories); err != nil {
+		return nil, err
+	}
+	if err := s.fillTopWorkers(dashboard, workers); err != nil {
+		return nil, err
+	}
+
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	if err := s.db.Model(&models.WorkerRating{}).
+		Select("COUNT(*) AS count, COALESCE(AVG(stars), 0) AS average_stars").
+		Where("customer_id = ? AND created_at >= ? AND created_at < ?", customerID, start, start.AddDate(1, 0, 0)).
+		Scan(&dashboard.RatingsGiven).Error; err != nil {
+		return nil, err
+	}
+	dashboard.RatingsGiven.AverageStars = roundPrice(dashboard.RatingsGiven.AverageStars)
+
+	var upcoming []models.CustomerServiceRequest
+	if err := s.db.Preload("Category").Preload("AssignedWorker.User").
+		Where("customer_id = ? AND scheduled_for > ? AND status NOT IN ?", customerID, time.Now().UTC(),
+			[]models.CustomerServiceRequestStatus{models.RequestStatusCompleted, models.RequestStatusCancelled, models.RequestStatusExpired}).
+		Order("scheduled_for ASC").
+		Limit(customerUpcomingServices).
+		Find(&upcoming).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range upcoming {
+		service := CustomerUpcomingService{
+			ID:                r.ID,
+			Title:             r.Title,
+			CategoryName:      r.Category.Name,
+			Status:            r.Status,
+			ScheduledFor:      *r.ScheduledFor,
+			ScheduledForLocal: r.ScheduledForLocal(),
+		}
+		if r.AssignedWorker != nil {
+			service.WorkerName = r.AssignedWorker.User.FullName
+		}
+		dashboard.Upcoming = append(dashboard.Upcoming, service)
+	}
+	return dashboard, nil
+}
+
+// fillCategories names the categories and sorts them by spend, highest first
+func (s *CustomerAnalyticsService) fillCategories(dashboard *CustomerDashboard, categories map[uint]*CustomerCategorySpend) error {
+	if len(categories) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(categories))
+	for id := range categories {
+		ids = append(ids, id)
+	}
+	var named []models.ServiceCategory
+	if err := s.db.Select("id", "name").Find(&named, ids).Error; err != nil {
+		return err
+	}
+	for _, c := range named {
+		categories[c.ID].CategoryName = c.Name
+	}
+	for _, c := range categories {
+		dashboard.PerCategory = append(dashboard.PerCategory, *c)
+	}
+	sort.Slice(dashboard.PerCategory, func(i, j int) bool {
+		if dashboard.PerCategory[i].Spend != dashboard.PerCategory[j].Spend {
+			return dashboard.PerCategory[i].Spend > dashboard.PerCategory[j].Spend
+		}
+		return dashboard.PerCategory[i].CategoryID < dashboard.PerCategory[j].CategoryID
+	})
+	return nil
+}
+
+// fillTopWorkers keeps the workers hired most often, with their names and photos
+func (s *CustomerAnalyticsService) fillTopWorkers(dashboard *CustomerDashboard, workers map[uint]*CustomerWorkerUsage) error {
+	usage := make([]CustomerWorkerUsage, 0, len(workers))
+	for _, w := range workers {
+		usage = append(usage, *w)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Services != usage[j].Services {
+			return usage[i].Services > usage[j].Services
+		}
+		return usage[i].Spend > usage[j].Spend
+	})
+	if len(usage) > customerTopWorkers {
+		usage = usage[:customerTopWorkers]
+	}
+	if len(usage) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(usage))
+	for _, w := range usage {
+		ids = append(ids, w.WorkerID)
+	}
+	var profiles []models.WorkerProfile
+	if err := s.db.Unscoped().Preload("User").Find(&profiles, ids).Error; err != nil {
+		return err
+	}
+	for i := range usage {
+		for _, p := range profiles {
+			if p.ID == usage[i].WorkerID {
+				usage[i].FullName = p.User.FullName
+				usage[i].ProfilePhoto = p.ProfilePhoto
+			}
+		}
+	}
+	dashboard.TopWorkers = usage
+	return nil
+}
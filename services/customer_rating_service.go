@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// Abuse escalation thresholds
+const (
+	AbuseLowStars         = 1   // A rating at or below this opens a report on its own
+	AbuseLowScore         = 2.0 // An average at or below this opens a report...
+	AbuseLowScoreMinCount = 3   // ...once the customer has at least this many ratings
+)
+
+// ErrCustomerAlreadyRated is returned when the worker has already rated this request's customer
+var ErrCustomerAlreadyRated = errors.New("customer already rated for this request")
+
+// CustomerRatingService records worker ratings of customers and escalates abusive customers
+type CustomerRatingService struct{}
+
+// NewCustomerRatingService creates a new customer rating service
+func NewCustomerRatingService() *CustomerRatingService {
+	return &CustomerRatingService{}
+}
+
+// Rate stores rating, refreshes the customer's score and opens or updates an abuse report when
+// the rating or the new score is very low. The report is nil when nothing was escalated.
+func (s *CustomerRatingService) Rate(rating *models.CustomerRating) (*models.AbuseReport, error) {
+	var report *models.AbuseReport
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(rating)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCustomerAlreadyRated
+		}
+
+		var summary struct {
+			Average float64
+			Count   int
+		}
+		if err := tx.Model(&models.CustomerRating{}).
+			Select("COALESCE(AVG(stars), 0) AS average, COUNT(*) AS count").
+			Where("customer_id = ?", rating.CustomerID).
+			Scan(&summary).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", rating.CustomerID).Updates(map[string]interface{}{
+			"customer_score":        summary.Average,
+			"customer_rating_count": summary.Count,
+		}).Error; err != nil {
+			return err
+		}
+
+		reason := abuseReason(rating.Stars, summary.Average, summary.Count)
+		if reason == "" {
+			return nil
+		}
+
+		var err error
+		report, err = escalateCustomer(tx, rating, reason, summary.Average)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// abuseReason explains why a rating should be escalated, or is empty when it should not
+func abuseReason(stars int, average float64, count int) string {
+	if stars <= AbuseLowStars {
+		return fmt.Sprintf("Rated %d star by a worker", stars)
+	}
+	if count >= AbuseLowScoreMinCount && average <= AbuseLowScore {
+		return fmt.Sprintf("Average score %.2f over %d ratings", average, count)
+	}
+	return ""
+}
+
+// escalateCustomer opens an abuse report for the rated customer, or adds the rating to the
+// report already open for them
+func escalateCustomer(tx *gorm.DB, rating *models.CustomerRating, reason string, score float64) (*models.AbuseReport, error) {
+	var report models.AbuseReport
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("customer_id = ? AND status = ?", rating.CustomerID, models.AbuseReportOpen).
+		First(&report).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		report = models.AbuseReport{
+			CustomerID:       rating.CustomerID,
+			CustomerRatingID: rating.ID,
+			Reason:           reason,
+			LowRatingCount:   1,
+			CustomerScore:    score,
+			Status:           models.AbuseReportOpen,
+		}
+		if err := tx.Create(&report).Error; err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		report.LowRatingCount++
+		report.CustomerScore = score
+		if err := tx.Model(&report).Updates(map[string]interface{}{
+			"low_rating_count": report.LowRatingCount,
+			"customer_score":   report.CustomerScore,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &report, nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import "time"
+
+// MonthWindow returns the start of the month and the start of the next one, in loc, so queries
+// can filter on a month with completed_at >= from AND completed_at < to and use the index
+func MonthWindow(year int, month time.Month, loc *time.Location) (from, to time.Time) {
+	from = time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return from, from.AddDate(0, 1, 0)
+}
+
+// YearWindow returns the start of the year and the start of the next one, in loc
+func YearWindow(year int, loc *time.Location) (from, to time.Time) {
+	from = time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	return from, from.AddDate(1, 0, 0)
+}
+
+// streakDays counts the consecutive days ending on today that have work, given those days as
+// local midnights, most recent first. Days after today are ignored.
+func streakDays(days []time.Time, today time.Time) int {
+	streak := 0
+	expected := today
+	for _, day := range days {
+		if day.After(expected) {
+			continue
+		}
+		if !day.Equal(expected) {
+			break
+		}
+		streak++
+		expected = expected.AddDate(0, 0, -1)
+	}
+	return streak
+}
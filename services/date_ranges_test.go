@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthWindow(t *testing.T) {
+	plusOne := time.FixedZone("UTC+1", 60*60)
+	tests := []struct {
+		name     string
+		year     int
+		month    time.Month
+		loc      *time.Location
+		from, to time.Time
+	}{
+		{"mid year", 2026, time.June, time.UTC,
+			time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"february of a leap year", 2028, time.February, time.UTC,
+			time.Date(2028, time.February, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, time.March, 1, 0, 0, 0, 0, time.UTC)},
+		{"december", 2026, time.December, time.UTC,
+			time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"other time zone", 2026, time.March, plusOne,
+			time.Date(2026, time.February, 28, 23, 0, 0, 0, time.UTC), time.Date(2026, time.March, 31, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		from, to := MonthWindow(tt.year, tt.month, tt.loc)
+		if !from.Equal(tt.from) || !to.Equal(tt.to) {
+			t.Errorf("%s: MonthWindow = [%s, %s), want [%s, %s)", tt.name, from, to, tt.from, tt.to)
+		}
+		if from.Location() != tt.loc || to.Location() != tt.loc {
+			t.Errorf("%s: MonthWindow is in %s, want %s", tt.name, from.Location(), tt.loc)
+		}
+	}
+}
+
+func TestYearWindow(t *testing.T) {
+	plusOne := time.FixedZone("UTC+1", 60*60)
+	tests := []struct {
+		year     int
+		loc      *time.Location
+		from, to time.Time
+	}{
+		{2026, time.UTC, time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{2028, time.UTC, time.Date(2028, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2029, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{2026, plusOne, time.Date(2025, time.December, 31, 23, 0, 0, 0, time.UTC), time.Date(2026, time.December, 31, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		from, to := YearWindow(tt.year, tt.loc)
+		if !from.Equal(tt.from) || !to.Equal(tt.to) {
+			t.Errorf("YearWindow(%d, %s) = [%s, %s), want [%s, %s)", tt.year, tt.loc, from, to, tt.from, tt.to)
+		}
+	}
+}
+
+func TestStreakDays(t *testing.T) {
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+	today := day(2026, time.October, 16)
+	tests := []struct {
+		name  string
+		days  []time.Time
+		today time.Time
+		want  int
+	}{
+		{"no work", nil, today, 0},
+		{"single day", []time.Time{today}, today, 1},
+		{"today missing", []time.Time{day(2026, time.October, 15), day(2026, time.October, 14)}, today, 0},
+		{"gap", []time.Time{today, day(2026, time.October, 15), day(2026, time.October, 13), day(2026, time.October, 12)}, today, 2},
+		{"days after today", []time.Time{day(2026, time.October, 18), day(2026, time.October, 17), today, day(2026, time.October, 15)}, today, 2},
+		{"month boundary", []time.Time{day(2026, time.November, 1), day(2026, time.October, 31), day(2026, time.October, 30)}, day(2026, time.November, 1), 3},
+		{"year boundary", []time.Time{day(2027, time.January, 2), day(2027, time.January, 1), day(2026, time.December, 31), day(2026, time.December, 29)}, day(2027, time.January, 2), 3},
+		{"leap day", []time.Time{day(2028, time.March, 1), day(2028, time.February, 29), day(2028, time.February, 28)}, day(2028, time.March, 1), 3},
+	}
+	for _, tt := range tests {
+		if got := streakDays(tt.days, tt.today); got != tt.want {
+			t.Errorf("%s: streakDays = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
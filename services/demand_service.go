@@ -0,0 +1,115 @@
+package services
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/utils"
+)
+
+// heatmapGeohashPrecision is the geohash length used for demand cells
+const heatmapGeohashPrecision = 6
+
+// DemandService aggregates historical request density for the worker demand heatmap
+type DemandService struct {
+	db *gorm.DB
+}
+
+// NewDemandService creates a new demand service
+func NewDemandService() *DemandService {
+	return &DemandService{
+		db: database.DB,
+	}
+}
+
+// HeatmapWeeks returns how many weeks of history the heatmap covers (DEMAND_HEATMAP_WEEKS, default 8)
+func HeatmapWeeks() int {
+	if weeks, err := strconv.Atoi(os.Getenv("DEMAND_HEATMAP_WEEKS")); err == nil && weeks > 0 {
+		return weeks
+	}
+	return 8
+}
+
+// RebuildHeatmap recomputes every heatmap cell from the last HeatmapWeeks of requests
+func (s *DemandService) RebuildHeatmap() error {
+	weeks := HeatmapWeeks()
+	since := time.Now().AddDate(0, 0, -7*weeks)
+
+	var requests []struct {
+		CategoryID  uint
+		LocationLat float64
+		LocationLng float64
+		CreatedAt   time.Time
+	}
+	if err := s.db.Model(&models.CustomerServiceRequest{}).
+		Select("category_id, location_lat, location_lng, created_at").
+		Where("created_at >= ? AND location_lat IS NOT NULL AND location_lng IS NOT NULL", since).
+		Scan(&requests).Error; err != nil {
+		return err
+	}
+
+	type cellKey struct {
+		categoryID uint
+		geohash    string
+		hour       int
+	}
+	counts := make(map[cellKey]int)
+	for _, r := range requests {
+		key := cellKey{
+			categoryID: r.CategoryID,
+			geohash:    utils.EncodeGeohash(r.LocationLat, r.LocationLng, heatmapGeohashPrecision),
+			hour:       r.CreatedAt.Hour(),
+		}
+		counts[key]++
+	}
+
+	now := time.Now()
+	cells := make([]models.DemandHeatmapCell, 0, len(counts))
+	for key, count := range counts {
+		lat, lng := utils.DecodeGeohashCenter(key.geohash)
+		cells = append(cells, models.DemandHeatmapCell{
+			CategoryID:   key.categoryID,
+			Geohash:      key.geohash,
+			HourOfDay:    key.hour,
+			RequestCount: count,
+			CenterLat:    lat,
+			CenterLng:    lng,
+			WeeksCovered: weeks,
+			ComputedAt:   now,
+		})
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.DemandHeatmapCell{}).Error; err != nil {
+			return err
+		}
+		if len(cells) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&cells, 500).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("🗺️ Demand heatmap rebuilt: %d cells from %d requests over %d weeks", len(cells), len(requests), weeks)
+	return nil
+}
+
+// GetHeatmap returns the pre-aggregated cells for a category, optionally for a single hour of day
+func (s *DemandService) GetHeatmap(categoryID uint, hour *int) ([]models.DemandHeatmapCell, error) {
+	query := s.db.Where("category_id = ?", categoryID)
+	if hour != nil {
+		query = query.Where("hour_of_day = ?", *hour)
+	}
+
+	var cells []models.DemandHeatmapCell
+	err := query.Order("request_count DESC").Find(&cells).Error
+	return cells, err
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/cache"
+	"repair-service-server/config"
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/validation"
+)
+
+const (
+	// minDurationSamples is how many completed jobs an estimate needs before it is trusted
+	minDurationSamples = 5
+	// durationHistoryWindow is how far back completed jobs are learned from
+	durationHistoryWindow = 365 * 24 * time.Hour
+	// durationEstimateTTL is how long estimates are cached; they move slowly
+	durationEstimateTTL = time.Hour
+	// defaultJobLength is assumed for jobs nothing is known about
+	defaultJobLength = 2 * time.Hour
+
+	durationEstimateCacheKey = "duration_estimate:"
+	// parentCategoryCacheKey sits under the catalog's category prefix so category edits drop it
+	parentCategoryCacheKey = "catalog:categories:parent:"
+)
+
+// DurationEstimateService predicts how long jobs take from the timed durations in service
+// history. It looks at jobs of the same service option first, then the same category, then the
+// category's parent, and uses the first with enough of them.
+type DurationEstimateService struct {
+	db *gorm.DB
+}
+
+// NewDurationEstimateService creates a new duration estimate service
+func NewDurationEstimateService() *DurationEstimateService {
+	return &DurationEstimateService{
+		db: database.DB,
+	}
+}
+
+// Estimate returns the duration range of a job in categoryID, with serviceOptionID when set, or
+// nil when too few similar jobs were timed
+func (s *DurationEstimateService) Estimate(categoryID uint, serviceOptionID *uint) (*models.DurationEstimate, error) {
+	if serviceOptionID != nil {
+		estimate, err := s.cached("service_option", *serviceOptionID, "service_option_id = ?", *serviceOptionID)
+		if err != nil || estimate != nil {
+			return estimate, err
+		}
+	}
+	if categoryID == 0 {
+		return nil, nil
+	}
+	estimate, err := s.cached("category", categoryID, "category_id = ?", categoryID)
+	if err != nil || estimate != nil {
+		return estimate, err
+	}
+
+	parentID, err := s.parentCategoryID(categoryID)
+	if err != nil || parentID == 0 {
+		return nil, nil
+	}
+	return s.cached("parent_category", parentID, "category_id IN (SELECT id FROM service_categories WHERE id = ? OR parent_id = ?)", parentID, parentID)
+}
+
+// ForRequest returns the duration range of a request's kind of job, or nil when unknown
+func (s *DurationEstimateService) ForRequest(request models.CustomerServiceRequest) (*models.DurationEstimate, error) {
+	return s.Estimate(request.CategoryID, request.ServiceOptionID)
+}
+
+// JobLength is how long to block out for a request when checking schedules: the high end of its
+// estimate, else the duration the customer typed, else two hours
+func (s *DurationEstimateService) JobLength(request models.CustomerServiceRequest) time.Duration {
+	if estimate, err := s.ForRequest(request); err == nil && estimate != nil {
+		return time.Duration(estimate.HighMinutes) * time.Minute
+	}
+	if d := request.EstimatedDuration.Duration(); d >= validation.MinDuration && d <= validation.MaxDuration {
+		return d
+	}
+	return defaultJobLength
+}
+
+// cached loads the estimate of the jobs matching the condition through the cache, keyed by basis
+// and the ID the condition is about
+func (s *DurationEstimateService) cached(basis string, id uint, condition string, args ...interface{}) (*models.DurationEstimate, error) {
+	key := fmt.Sprintf("%s%s:%d", durationEstimateCacheKey, basis, id)
+	return cache.GetOrLoad(key, durationEstimateTTL, func() (*models.DurationEstimate, error) {
+		return s.learn(basis, condition, args...)
+	})
+}
+
+// parentCategoryID returns the parent of a category, 0 for a top-level one. It is cached with the
+// catalog because listings ask for it once per request shown.
+func (s *DurationEstimateService) parentCategoryID(categoryID uint) (uint, error) {
+	key := fmt.Sprintf("%s%d:", parentCategoryCacheKey, categoryID)
+	return cache.GetOrLoad(key, config.Current().CatalogTTL, func() (uint, error) {
+		var category models.ServiceCategory
+		if err := s.db.Select("id, parent_id").First(&category, categoryID).Error; err != nil {
+			return 0, err
+		}
+		if category.ParentID == nil {
+			return 0, nil
+		}
+		return *category.ParentID, nil
+	})
+}
+
+// learn computes the quartiles of the actual durations of recent jobs matching the condition.
+// Durations outside what a request may ask for are left out as timer mistakes.
+func (s *DurationEstimateService) learn(basis, condition string, args ...interface{}) (*models.DurationEstimate, error) {
+	var row struct {
+		Samples int
+		Low     float64
+		Typical float64
+		High    float64
+	}
+	err := s.db.Raw(`
+		SELECT COUNT(*) AS samples,
+			COALESCE(percentile_cont(0.25) WITHIN GROUP (ORDER BY actual_duration), 0) AS low,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY actual_duration), 0) AS typical,
+			COALESCE(percentile_cont(0.75) WITHIN GROUP (ORDER BY actual_duration), 0) AS high
+		FROM service_histories
+		WHERE deleted_at IS NULL AND actual_duration BETWEEN ? AND ? AND completed_at >= ? AND `+condition,
+		append([]interface{}{int(validation.MinDuration.Minutes()), int(validation.MaxDuration.Minutes()), time.Now().Add(-durationHistoryWindow)}, args...)...,
+	).Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	if row.Samples < minDurationSamples {
+		return nil, nil
+	}
+	return &models.DurationEstimate{
+		LowMinutes:     int(row.Low + 0.5),
+		TypicalMinutes: int(row.Typical + 0.5),
+		HighMinutes:    int(row.High + 0.5),
+		SampleSize:     row.Samples,
+		Basis:          basis,
+	}, nil
+}
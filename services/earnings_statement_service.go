@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// EarningsStatementLine is one completed job on a worker's statement
+type EarningsStatementLine struct {
+	ServiceHistoryID  uint         `json:"service_history_id"`
+	ServiceRequestID  uint         `json:"service_request_id"`
+	CompletedAt       time.Time    `json:"completed_at"`
+	Title             string       `json:"title"`
+	CategoryName      string       `json:"category_name"`
+	Gross             money.Amount `json:"gross"` // Final price, parts included
+	Parts             money.Amount `json:"parts"`
+	CommissionPercent float64      `json:"commission_percent"`
+	Commission        money.Amount `json:"commission"`
+	Refunded          money.Amount `json:"refunded"`
+	Tips              money.Amount `json:"tips"`
+	Net               money.Amount `json:"net"` // Gross less commission and refunds, plus tips
+	PaymentStatus     string       `json:"payment_status"`
+}
+
+// EarningsTotals sums statement lines
+type EarningsTotals struct {
+	Jobs       int          `json:"jobs"`
+	Gross      money.Amount `json:"gross"`
+	Parts      money.Amount `json:"parts"`
+	Commission money.Amount `json:"commission"`
+	Refunded   money.Amount `json:"refunded"`
+	Tips       money.Amount `json:"tips"`
+	Net        money.Amount `json:"net"`
+}
+
+// EarningsStatement is a worker's detailed statement for one month
+type EarningsStatement struct {
+	WorkerID    uint                    `json:"worker_id"`
+	WorkerName  string                  `json:"worker_name"`
+	Month       string                  `json:"month"` // YYYY-MM
+	Currency    string                  `json:"currency"`
+	Lines       []EarningsStatementLine `json:"lines"`
+	Totals      EarningsTotals          `json:"totals"`
+	GeneratedAt time.Time               `json:"generated_at"`
+}
+
+// EarningsMonthTotals is one month of a yearly summary
+type EarningsMonthTotals struct {
+	Month string `json:"month"` // YYYY-MM
+	EarningsTotals
+}
+
+// EarningsYearSummary is a worker's yearly earnings by month, for their tax return
+type EarningsYearSummary struct {
+	WorkerID    uint                  `json:"worker_id"`
+	WorkerName  string                `json:"worker_name"`
+	Year        int                   `json:"year"`
+	Currency    string                `json:"currency"`
+	Months      []EarningsMonthTotals `json:"months"`
+	Totals      EarningsTotals        `json:"totals"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// EarningsStatementService builds worker earnings statements from their service history
+type EarningsStatementService struct {
+	db *gorm.DB
+}
+
+// NewEarningsStatementService creates a new earnings statement service
+func NewEarningsStatementService() *EarningsStatementService {
+	return &EarningsStatementService{
+		db: database.DB,
+	}
+}
+
+// Statement returns the worker's statement for the month starting at month, in month's time
+// zone, with every job completed in it at its current price, refunds and tips
+func (s *EarningsStatementService) Statement(worker models.WorkerProfile, month time.Time) (*EarningsStatement, error) {
+	start, end := MonthWindow(month.Year(), month.Month(), month.Location())
+	lines, err := s.lines(worker.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &EarningsStatement{
+		WorkerID:    worker.ID,
+		WorkerName:  s.workerName(worker),
+		Month:       start.Format("2006-01"),
+		Currency:    NewRegionService().ForCity(worker.City).Currency,
+		Lines:       lines,
+		GeneratedAt: time.Now(),
+	}
+	for _, line := range lines {
+		statement.Totals.add(line)
+	}
+	return statement, nil
+}
+
+// YearSummary returns the worker's monthly totals for year, with every month listed
+func (s *EarningsStatementService) YearSummary(worker models.WorkerProfile, year int) (*EarningsYearSummary, error) {
+	start, end := YearWindow(year, time.Local)
+	lines, err := s.lines(worker.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &EarningsYearSummary{
+		WorkerID:    worker.ID,
+		WorkerName:  s.workerName(worker),
+		Year:        year,
+		Currency:    NewRegionService().ForCity(worker.City).Currency,
+		Months:      make([]EarningsMonthTotals, 12),
+		GeneratedAt: time.Now(),
+	}
+	for i := range summary.Months {
+		summary.Months[i].Month = fmt.Sprintf("%04d-%02d", year, i+1)
+	}
+	for _, line := range lines {
+		summary.Months[line.CompletedAt.In(time.Local).Month()-1].add(line)
+		summary.Totals.add(line)
+	}
+	return summary, nil
+}
+
+// lines loads the statement lines of the jobs the worker completed in [from, to), oldest first
+func (s *EarningsStatementService) lines(workerID uint, from, to time.Time) ([]EarningsStatementLine, error) {
+	var histories []models.ServiceHistory
+	if err := s.db.Preload("Category").
+		Where("worker_id = ? AND completed_at >= ? AND completed_at < ?", workerID, from, to).
+		Order("completed_at ASC").
+		Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	lines := make([]EarningsStatementLine, 0, len(histories))
+	for _, h := range histories {
+		line := EarningsStatementLine{
+			ServiceHistoryID:  h.ID,
+			ServiceRequestID:  h.ServiceRequestID,
+			CompletedAt:       h.CompletedAt,
+			Title:             h.Title,
+			CategoryName:      h.Category.Name,
+			Parts:             h.PartsTotal,
+			CommissionPercent: h.CommissionPercent,
+			Refunded:          h.RefundedAmount,
+			Tips:              h.TipAmount,
+			PaymentStatus:     h.PaymentStatus,
+		}
+		if h.FinalPrice != nil {
+			line.Gross = *h.FinalPrice
+		}
+		line.Commission = line.Gross.Percent(h.CommissionPercent)
+		line.Net = line.Gross - line.Commission - line.Refunded + line.Tips
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// workerName returns the worker's name, loading their user when it was not preloaded
+func (s *EarningsStatementService) workerName(worker models.WorkerProfile) string {
+	if worker.User.ID != 0 {
+		return worker.User.FullName
+	}
+	var user models.User
+	s.db.Select("id", "full_name").First(&user, worker.UserID)
+	return user.FullName
+}
+
+// add counts line in the totals
+func (t *EarningsTotals) add(line EarningsStatementLine) {
+	t.Jobs++
+	t.Gross += line.Gross
+	t.Parts += line.Parts
+	t.Commission += line.Commission
+	t.Refunded += line.Refunded
+	t.Tips += line.Tips
+	t.Net += line.Net
+}
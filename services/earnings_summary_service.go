@@ -0,0 +1,78 @@
+package services
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// EarningsSummaryService emails workers a summary of the previous week's earnings
+type EarningsSummaryService struct{}
+
+// NewEarningsSummaryService creates a new earnings summary service
+func NewEarningsSummaryService() *EarningsSummaryService {
+	return &EarningsSummaryService{}
+}
+
+// LastWeek returns the Monday-to-Monday bounds of the last full week before now
+func LastWeek(now time.Time) (start, end time.Time) {
+	daysSinceMonday := (int(now.Weekday()) + 6) % 7
+	end = TruncateDay(now).AddDate(0, 0, -daysSinceMonday)
+	return end.AddDate(0, 0, -7), end
+}
+
+// DueWorkers returns up to limit workers with a verified email who have not been sent the
+// summary for the week ending at weekEnd
+func (s *EarningsSummaryService) DueWorkers(weekEnd time.Time, limit int) ([]models.WorkerProfile, error) {
+	var workers []models.WorkerProfile
+	err := database.DB.
+		Joins("JOIN users ON users.id = worker_profiles.user_id").
+		Where("users.email IS NOT NULL AND users.email_verified_at IS NOT NULL AND users.is_active = ?", true).
+		Where("worker_profiles.earnings_summary_sent_at IS NULL OR worker_profiles.earnings_summary_sent_at < ?", weekEnd).
+		Order("worker_profiles.id").
+		Limit(limit).
+		Find(&workers).Error
+	return workers, err
+}
+
+// Enqueue totals the worker's week from start to end and queues the summary email. Weeks without
+// any completed job, earnings or tips are only marked as done.
+func (s *EarningsSummaryService) Enqueue(worker models.WorkerProfile, start, end time.Time) error {
+	var totals struct {
+		JobsCompleted int
+		Earnings      float64
+		Tips          float64
+		WorkHours     float64
+	}
+	err := database.DB.Model(&models.WorkerDailyStats{}).
+		Select("COALESCE(SUM(jobs_completed), 0) AS jobs_completed, COALESCE(SUM(earnings), 0) AS earnings, COALESCE(SUM(tips), 0) AS tips, COALESCE(SUM(work_hours), 0) AS work_hours").
+		Where("worker_id = ? AND date >= ? AND date < ?", worker.ID, start, end).
+		Scan(&totals).Error
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if totals.JobsCompleted > 0 || totals.Earnings != 0 || totals.Tips != 0 {
+			if err := EnqueueOutboxEvent(tx, models.OutboxEventEmail, worker.ID, models.EmailPayload{
+				UserID:   worker.UserID,
+				Template: EmailTemplateWorkerEarningsSummary,
+				Data: map[string]interface{}{
+					"week_start":     start,
+					"week_end":       end.AddDate(0, 0, -1),
+					"jobs_completed": totals.JobsCompleted,
+					"earnings":       totals.Earnings,
+					"tips":           totals.Tips,
+					"work_hours":     totals.WorkHours,
+					"currency":       NewRegionService().ForCity(worker.City).Currency,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&worker).Update("earnings_summary_sent_at", time.Now()).Error
+	})
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"crypto/hmac"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// EmailCodeService sends and verifies emailed one-time codes. It applies the same lifetime,
+// resend and attempt limits as SMS codes and returns the same errors.
+type EmailCodeService struct {
+	email *EmailService
+}
+
+// NewEmailCodeService creates an email code service using the configured email provider
+func NewEmailCodeService() *EmailCodeService {
+	return &EmailCodeService{email: NewEmailService()}
+}
+
+// NormalizeEmail lowercases and trims an address so lookups and the unique index agree
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// SendCode generates a code for email and purpose on behalf of user, stores its hash and emails it
+// in the user's preferred language, or in lang when they have none. email must already be
+// normalized.
+func (s *EmailCodeService) SendCode(user models.User, email string, purpose models.EmailVerificationPurpose, lang string) error {
+	now := time.Now()
+
+	var recent []models.EmailVerification
+	if err := database.DB.Where("email = ? AND created_at > ?", email, now.Add(-time.Hour)).
+		Order("created_at DESC").Find(&recent).Error; err != nil {
+		return err
+	}
+	if len(recent) > 0 {
+		if wait := recent[0].CreatedAt.Add(OTPResendAfter).Sub(now); wait > 0 {
+			return &OTPCooldownError{RetryAfter: wait}
+		}
+	}
+	if len(recent) >= OTPHourlySends {
+		return &OTPCooldownError{RetryAfter: recent[OTPHourlySends-1].CreatedAt.Add(time.Hour).Sub(now)}
+	}
+
+	code, err := generateOTPCode()
+	if err != nil {
+		return err
+	}
+
+	verification := models.EmailVerification{
+		UserID:    user.ID,
+		Email:     email,
+		Purpose:   purpose,
+		CodeHash:  hashOTPCode(email, code),
+		ExpiresAt: now.Add(OTPTTL),
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the newest code for an address and purpose is usable
+		if err := tx.Model(&models.EmailVerification{}).
+			Where("email = ? AND purpose = ? AND verified_at IS NULL AND expires_at > ?", email, purpose, now).
+			Update("expires_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&verification).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	template := EmailTemplateVerification
+	if purpose == models.EmailVerificationPasswordReset {
+		template = EmailTemplatePasswordReset
+	}
+	if user.PreferredLanguage != "" {
+		lang = user.PreferredLanguage
+	}
+	return s.email.Send(email, template, lang, map[string]interface{}{
+		"name":               user.FullName,
+		"code":               code,
+		"expires_in_minutes": int(OTPTTL.Minutes()),
+	})
+}
+
+// VerifyCode checks code against the latest pending code for email and purpose, marks it used on
+// success and returns it so the caller knows which user requested it
+func (s *EmailCodeService) VerifyCode(email string, purpose models.EmailVerificationPurpose, code string) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	err := database.DB.Where("email = ? AND purpose = ? AND verified_at IS NULL AND expires_at > ?", email, purpose, time.Now()).
+		Order("created_at DESC").First(&verification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrOTPInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if verification.Attempts >= OTPMaxAttempts {
+		return nil, ErrOTPTooManyAttempts
+	}
+
+	if !hmac.Equal([]byte(verification.CodeHash), []byte(hashOTPCode(email, code))) {
+		database.DB.Model(&verification).Update("attempts", gorm.Expr("attempts + 1"))
+		if verification.Attempts+1 >= OTPMaxAttempts {
+			return nil, ErrOTPTooManyAttempts
+		}
+		return nil, ErrOTPInvalid
+	}
+
+	now := time.Now()
+	result := database.DB.Model(&models.EmailVerification{}).
+		Where("id = ? AND verified_at IS NULL", verification.ID).
+		Update("verified_at", &now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		// A concurrent request used the code first
+		return nil, ErrOTPInvalid
+	}
+	verification.VerifiedAt = &now
+	return &verification, nil
+}
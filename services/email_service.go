@@ -0,0 +1,237 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"time"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// EmailService renders templated emails and sends them through the configured provider
+type EmailService struct {
+	provider EmailProvider
+}
+
+// NewEmailService creates an email service using the configured email provider
+func NewEmailService() *EmailService {
+	return &EmailService{provider: NewEmailProvider()}
+}
+
+// Send renders template name in lang for the address to and sends it
+func (s *EmailService) Send(to, name, lang string, data map[string]interface{}) error {
+	msg, err := RenderEmail(name, lang, to, data)
+	if err != nil {
+		return err
+	}
+	if err := s.provider.Send(msg); err != nil {
+		log.Printf("❌ Failed to send %s email to %s via %s: %v", name, to, s.provider.Name(), err)
+		return err
+	}
+	log.Printf("📧 %s email sent to %s via %s", name, to, s.provider.Name())
+	return nil
+}
+
+// SendToUser sends template name to the user's verified address, in their preferred language.
+// Users without one are skipped; the returned bool reports whether an email went out.
+func (s *EmailService) SendToUser(userID uint, name string, data map[string]interface{}) (bool, error) {
+	var user models.User
+	if err := database.DB.Select("id", "full_name", "email", "email_verified_at", "preferred_language").First(&user, userID).Error; err != nil {
+		return false, err
+	}
+	if !user.IsEmailVerified() {
+		return false, nil
+	}
+
+	withName := map[string]interface{}{"name": user.FullName}
+	for k, v := range data {
+		withName[k] = v
+	}
+	return true, s.Send(*user.Email, name, user.PreferredLanguage, withName)
+}
+
+// EmailMessage is a rendered email ready to send
+type EmailMessage struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailProvider is implemented by every email backend
+type EmailProvider interface {
+	Name() string
+	Send(msg EmailMessage) error
+}
+
+// NewEmailProvider returns the provider selected by EMAIL_PROVIDER ("smtp" or "sendgrid").
+// Without a configured provider messages are only logged, which is what local development
+// relies on.
+func NewEmailProvider() EmailProvider {
+	from := os.Getenv("EMAIL_FROM")
+
+	switch strings.ToLower(os.Getenv("EMAIL_PROVIDER")) {
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" || from == "" {
+			log.Printf("⚠️ SMTP_HOST or EMAIL_FROM not set, email will only be logged")
+			return &LogEmailProvider{}
+		}
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return &SMTPProvider{
+			host:     host,
+			port:     port,
+			username: os.Getenv("SMTP_USERNAME"),
+			password: os.Getenv("SMTP_PASSWORD"),
+			from:     from,
+		}
+	case "sendgrid":
+		apiKey := os.Getenv("SENDGRID_API_KEY")
+		if apiKey == "" || from == "" {
+			log.Printf("⚠️ SENDGRID_API_KEY or EMAIL_FROM not set, email will only be logged")
+			return &LogEmailProvider{}
+		}
+		return &SendGridProvider{apiKey: apiKey, from: from, client: &http.Client{Timeout: 10 * time.Second}}
+	default:
+		return &LogEmailProvider{}
+	}
+}
+
+// SMTPProvider sends email through an SMTP relay, using STARTTLS when the server offers it
+type SMTPProvider struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// Name returns the provider name
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+// Send delivers msg as a multipart text and HTML message
+func (p *SMTPProvider) Send(msg EmailMessage) error {
+	body, err := buildMIMEMessage(p.from, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if p.username != "" {
+		auth = smtp.PlainAuth("", p.username, p.password, p.host)
+	}
+	return smtp.SendMail(p.host+":"+p.port, auth, p.from, []string{msg.To}, body)
+}
+
+// SendGridProvider sends email through the SendGrid v3 Mail Send API
+type SendGridProvider struct {
+	apiKey string
+	from   string
+	client *http.Client
+}
+
+// Name returns the provider name
+func (p *SendGridProvider) Name() string {
+	return "sendgrid"
+}
+
+// Send delivers msg with both its text and HTML parts
+func (p *SendGridProvider) Send(msg EmailMessage) error {
+	content := []map[string]string{{"type": "text/plain", "value": msg.Text}}
+	if msg.HTML != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": msg.HTML})
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": []map[string]string{{"email": msg.To}}}},
+		"from":             map[string]string{"email": p.from},
+		"subject":          msg.Subject,
+		"content":          content,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.sendgrid.com/v3/mail/send", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	// Same non-2xx handling as the SMS providers
+	return doSMSRequest(p.client, req)
+}
+
+// LogEmailProvider writes messages to the server log instead of sending them
+type LogEmailProvider struct{}
+
+// Name returns the provider name
+func (p *LogEmailProvider) Name() string {
+	return "log"
+}
+
+// Send logs the message
+func (p *LogEmailProvider) Send(msg EmailMessage) error {
+	log.Printf("📧 Email to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
+
+// buildMIMEMessage assembles the headers and a multipart/alternative body for SMTP
+func buildMIMEMessage(from string, msg EmailMessage) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct{ contentType, content string }{{"text/plain", msg.Text}}
+	if msg.HTML != "" {
+		parts = append(parts, struct{ contentType, content string }{"text/html", msg.HTML})
+	}
+	for _, part := range parts {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	messageID := make([]byte, 12)
+	if _, err := rand.Read(messageID); err != nil {
+		return nil, err
+	}
+	domain := from[strings.LastIndex(from, "@")+1:]
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "Message-ID: <%s@%s>\r\n", hex.EncodeToString(messageID), domain)
+	fmt.Fprintf(&out, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
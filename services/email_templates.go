@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"repair-service-server/i18n"
+)
+
+// Email templates. Their data is a map so it survives the JSON round trip through the outbox.
+const (
+	EmailTemplateVerification          = "email_verification"      // code, expires_in_minutes
+	EmailTemplatePasswordReset         = "password_reset"          // name, code, expires_in_minutes
+	EmailTemplateRequestConfirmation   = "request_confirmation"    // name, request_id, title, address, budget, currency
+	EmailTemplateCompletionReceipt     = "completion_receipt"      // name, request_id, title, worker_name, amount, completed_at, currency, optional labour and items
+	EmailTemplateWorkerEarningsSummary = "worker_earnings_summary" // name, week_start, week_end, jobs_completed, earnings, tips, work_hours, currency
+	EmailTemplateNotification          = "notification"            // name, title, body; used for the email notification channel
+)
+
+// emailTemplate holds the subject and the plain text and HTML bodies of one template
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// emailTemplateFuncs returns the template functions for lang, with amounts in currency. Text comes
+// from the i18n catalog: t renders a message key, filling its placeholders from the template data
+// when it is passed.
+func emailTemplateFuncs(lang, currency string) map[string]interface{} {
+	return map[string]interface{}{
+		"t": func(key string, data ...i18n.Vars) string {
+			var vars i18n.Vars
+			if len(data) > 0 {
+				vars = data[0]
+			}
+			return i18n.T(lang, key, vars)
+		},
+		// money formats an amount in the region's currency and lang's digit grouping; missing
+		// amounts render as a dash
+		"money": func(v interface{}) string { return i18n.Money(lang, v, currency) },
+		// date formats an RFC 3339 timestamp as a calendar date
+		"date": func(v interface{}) string { return i18n.Date(lang, v) },
+		// dir is the text direction of lang
+		"dir": func() string { return i18n.Dir(lang) },
+		"lang": func() string { return lang },
+	}
+}
+
+// newEmailTemplate parses a template with the default language's functions; RenderEmail swaps in
+// the recipient's
+func newEmailTemplate(name, subject, text, html string) emailTemplate {
+	funcs := emailTemplateFuncs(i18n.Default, "")
+	return emailTemplate{
+		subject: texttemplate.Must(texttemplate.New(name).Funcs(funcs).Parse(subject)),
+		text:    texttemplate.Must(texttemplate.New(name).Funcs(funcs).Parse(text)),
+		html:    htmltemplate.Must(htmltemplate.New(name).Funcs(funcs).Parse(emailLayoutStart + html + emailLayoutEnd)),
+	}
+}
+
+const emailLayoutStart = `<!DOCTYPE html><html lang="{{lang}}" dir="{{dir}}"><body style="font-family:Arial,sans-serif;color:#222;max-width:560px;margin:auto">`
+
+const emailLayoutEnd = `<p style="color:#888;font-size:12px">{{t "email.footer"}}</p></body></html>`
+
+var emailTemplates = map[string]emailTemplate{
+	EmailTemplateVerification: newEmailTemplate(EmailTemplateVerification,
+		`{{t "email.verification.subject"}}`,
+		`{{t "email.verification.code" .}}
+
+{{t "email.verification.ignore"}}`,
+		`<h2>{{t "email.verification.subject"}}</h2>
+<p>{{t "email.verification.code_label"}}</p>
+<p style="font-size:28px;letter-spacing:4px"><strong>{{.code}}</strong></p>
+<p>{{t "email.code_expires" .}} {{t "email.verification.ignore"}}</p>`),
+
+	EmailTemplatePasswordReset: newEmailTemplate(EmailTemplatePasswordReset,
+		`{{t "email.password_reset.subject"}}`,
+		`{{t "email.greeting" .}}
+
+{{t "email.password_reset.code" .}}
+
+{{t "email.password_reset.ignore"}}`,
+		`<h2>{{t "email.password_reset.subject"}}</h2>
+<p>{{t "email.greeting" .}}</p>
+<p>{{t "email.password_reset.code_label"}}</p>
+<p style="font-size:28px;letter-spacing:4px"><strong>{{.code}}</strong></p>
+<p>{{t "email.code_expires" .}} {{t "email.password_reset.ignore"}}</p>`),
+
+	EmailTemplateRequestConfirmation: newEmailTemplate(EmailTemplateRequestConfirmation,
+		`{{t "email.request_confirmation.subject" .}}`,
+		`{{t "email.greeting" .}}
+
+{{t "email.request_confirmation.body" .}}
+{{t "email.request_confirmation.budget" .}}
+
+{{t "email.request_confirmation.next"}}`,
+		`<h2>{{t "email.request_confirmation.heading"}}</h2>
+<p>{{t "email.greeting" .}}</p>
+<p>{{t "email.request_confirmation.body" .}}</p>
+<p>{{t "email.request_confirmation.budget" .}}</p>
+<p>{{t "email.request_confirmation.next"}}</p>`),
+
+	EmailTemplateCompletionReceipt: newEmailTemplate(EmailTemplateCompletionReceipt,
+		`{{t "email.receipt.subject" .}}`,
+		`{{t "email.greeting" .}}
+
+{{t "email.receipt.body" .}}
+{{- if .items}}
+{{t "email.receipt.labour"}}: {{money .labour}}
+{{- range .items}}
+{{.description}}: {{.quantity}} x {{money .unit_price}} = {{money .total}}
+{{- end}}
+{{- end}}
+{{t "email.receipt.amount"}}: {{money .amount}}
+
+{{t "email.receipt.thanks"}}`,
+		`<h2>{{t "email.receipt.subject" .}}</h2>
+<p>{{t "email.greeting" .}}</p>
+<table style="border-collapse:collapse">
+<tr><td style="padding:4px 12px">{{t "email.receipt.service"}}</td><td>{{.title}}</td></tr>
+<tr><td style="padding:4px 12px">{{t "email.receipt.worker"}}</td><td>{{.worker_name}}</td></tr>
+<tr><td style="padding:4px 12px">{{t "email.receipt.completed"}}</td><td>{{date .completed_at}}</td></tr>
+{{- if .items}}
+<tr><td style="padding:4px 12px">{{t "email.receipt.labour"}}</td><td>{{money .labour}}</td></tr>
+{{- range .items}}
+<tr><td style="padding:4px 12px">{{.description}} ({{.quantity}} x {{money .unit_price}})</td><td>{{money .total}}</td></tr>
+{{- end}}
+{{- end}}
+<tr><td style="padding:4px 12px"><strong>{{t "email.receipt.amount"}}</strong></td><td><strong>{{money .amount}}</strong></td></tr>
+</table>
+<p>{{t "email.receipt.thanks"}}</p>`),
+
+	EmailTemplateWorkerEarningsSummary: newEmailTemplate(EmailTemplateWorkerEarningsSummary,
+		`{{t "email.earnings.subject" .}}`,
+		`{{t "email.greeting" .}}
+
+{{t "email.earnings.intro" .}}
+{{t "email.earnings.jobs"}}: {{.jobs_completed}}
+{{t "email.earnings.hours"}}: {{printf "%.1f" .work_hours}}
+{{t "email.earnings.earnings"}}: {{money .earnings}}
+{{- if .tips}}
+{{t "email.earnings.tips"}}: {{money .tips}}
+{{- end}}`,
+		`<h2>{{t "email.earnings.heading"}}</h2>
+<p>{{t "email.greeting" .}}</p>
+<p>{{t "email.earnings.intro" .}}</p>
+<table style="border-collapse:collapse">
+<tr><td style="padding:4px 12px">{{t "email.earnings.jobs"}}</td><td>{{.jobs_completed}}</td></tr>
+<tr><td style="padding:4px 12px">{{t "email.earnings.hours"}}</td><td>{{printf "%.1f" .work_hours}}</td></tr>
+<tr><td style="padding:4px 12px"><strong>{{t "email.earnings.earnings"}}</strong></td><td><strong>{{money .earnings}}</strong></td></tr>
+{{- if .tips}}
+<tr><td style="padding:4px 12px">{{t "email.earnings.tips"}}</td><td>{{money .tips}}</td></tr>
+{{- end}}
+</table>`),
+
+	EmailTemplateNotification: newEmailTemplate(EmailTemplateNotification,
+		`{{.title}}`,
+		`{{t "email.greeting" .}}
+
+{{.body}}`,
+		`<h2>{{.title}}</h2>
+<p>{{t "email.greeting" .}}</p>
+<p>{{.body}}</p>`),
+}
+
+// RenderEmail renders template name in lang for to with data. Amounts are in data's "currency",
+// the ouguiya by default.
+func RenderEmail(name, lang, to string, data map[string]interface{}) (EmailMessage, error) {
+	tmpl, ok := emailTemplates[name]
+	if !ok {
+		return EmailMessage{}, fmt.Errorf("unknown email template %q", name)
+	}
+	currency, _ := data["currency"].(string)
+	funcs := emailTemplateFuncs(i18n.Language(lang), currency)
+	subjectTmpl, err := tmpl.subject.Clone()
+	if err != nil {
+		return EmailMessage{}, err
+	}
+	textTmpl, err := tmpl.text.Clone()
+	if err != nil {
+		return EmailMessage{}, err
+	}
+	htmlTmpl, err := tmpl.html.Clone()
+	if err != nil {
+		return EmailMessage{}, err
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := subjectTmpl.Funcs(funcs).Execute(&subject, data); err != nil {
+		return EmailMessage{}, err
+	}
+	if err := textTmpl.Funcs(funcs).Execute(&text, data); err != nil {
+		return EmailMessage{}, err
+	}
+	if err := htmlTmpl.Funcs(funcs).Execute(&html, data); err != nil {
+		return EmailMessage{}, err
+	}
+	return EmailMessage{To: to, Subject: subject.String(), Text: text.String(), HTML: html.String()}, nil
+}
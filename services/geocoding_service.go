@@ -0,0 +1,266 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"repair-service-server/utils"
+)
+
+// ErrOutsideServiceArea is returned when coordinates fall outside every supported service area
+var ErrOutsideServiceArea = errors.New("location is outside of supported service areas")
+
+// ReverseGeocodeResult represents the address resolved from a pair of coordinates
+type ReverseGeocodeResult struct {
+	FormattedAddress string `json:"formatted_address"`
+	City             string `json:"city"`
+	Country          string `json:"country"`
+}
+
+// GeocodingProvider is implemented by every reverse geocoding backend
+type GeocodingProvider interface {
+	Name() string
+	ReverseGeocode(lat, lng float64) (*ReverseGeocodeResult, error)
+}
+
+// ServiceArea represents a supported area, approximated by a center and a radius
+type ServiceArea struct {
+	City     string  `json:"city"`
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	RadiusKm float64 `json:"radius_km"`
+}
+
+// supportedServiceAreas lists the areas where requests are currently accepted
+var supportedServiceAreas = []ServiceArea{
+	{City: "Nouakchott", Lat: 18.0799, Lng: -15.9653, RadiusKm: 35},
+	{City: "Nouadhibou", Lat: 20.9310, Lng: -17.0347, RadiusKm: 20},
+}
+
+// cityAliases maps common spellings (French, Arabic, abbreviations) to a canonical city name
+var cityAliases = map[string]string{
+	"nouakchott": "Nouakchott",
+	"nktt":       "Nouakchott",
+	"nouakchot":  "Nouakchott",
+	"nwakshot":   "Nouakchott",
+	"نواكشوط":    "Nouakchott",
+	"nouadhibou": "Nouadhibou",
+	"nouadibou":  "Nouadhibou",
+	"ndb":        "Nouadhibou",
+	"نواذيبو":    "Nouadhibou",
+}
+
+// GeocodingService resolves request coordinates to normalized addresses
+type GeocodingService struct {
+	provider GeocodingProvider
+}
+
+// NewGeocodingService creates a geocoding service using the provider selected by GEOCODING_PROVIDER
+func NewGeocodingService() *GeocodingService {
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	var provider GeocodingProvider
+	switch strings.ToLower(os.Getenv("GEOCODING_PROVIDER")) {
+	case "google":
+		apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
+		if apiKey == "" {
+			log.Printf("⚠️ GOOGLE_MAPS_API_KEY not set, falling back to Nominatim geocoding")
+			provider = &NominatimProvider{client: client}
+		} else {
+			provider = &GoogleProvider{apiKey: apiKey, client: client}
+		}
+	default:
+		provider = &NominatimProvider{client: client}
+	}
+
+	return &GeocodingService{provider: provider}
+}
+
+// ResolveRequestLocation validates the coordinates against the supported service areas and
+// reverse-geocodes them. The customer's free-text address is kept when provided; the city is
+// always normalized. Provider failures are not fatal: the supplied text is used instead.
+func (s *GeocodingService) ResolveRequestLocation(lat, lng float64, address, city string) (string, string, *ReverseGeocodeResult, error) {
+	area, ok := FindServiceArea(lat, lng)
+	if !ok {
+		return "", "", nil, ErrOutsideServiceArea
+	}
+
+	result, err := s.provider.ReverseGeocode(lat, lng)
+	if err != nil {
+		log.Printf("⚠️ Reverse geocoding via %s failed for (%f, %f): %v", s.provider.Name(), lat, lng, err)
+	}
+
+	resolvedAddress := strings.TrimSpace(address)
+	if resolvedAddress == "" && result != nil {
+		resolvedAddress = result.FormattedAddress
+	}
+
+	resolvedCity := NormalizeCity(city)
+	if result != nil && result.City != "" {
+		resolvedCity = NormalizeCity(result.City)
+	}
+	if resolvedCity == "" {
+		resolvedCity = area.City
+	}
+
+	return resolvedAddress, resolvedCity, result, nil
+}
+
+// NormalizeCity returns the canonical spelling of a city name
+func NormalizeCity(city string) string {
+	trimmed := strings.TrimSpace(city)
+	if trimmed == "" {
+		return ""
+	}
+	if canonical, ok := cityAliases[strings.ToLower(trimmed)]; ok {
+		return canonical
+	}
+	runes := []rune(trimmed)
+	return strings.ToUpper(string(runes[0])) + string(runes[1:])
+}
+
+// FindServiceArea returns the supported area that contains the given coordinates
+func FindServiceArea(lat, lng float64) (*ServiceArea, bool) {
+	for i := range supportedServiceAreas {
+		area := &supportedServiceAreas[i]
+		if utils.HaversineDistance(lat, lng, area.Lat, area.Lng) <= area.RadiusKm {
+			return area, true
+		}
+	}
+	return nil, false
+}
+
+// GetSupportedServiceAreas returns the list of supported service areas
+func GetSupportedServiceAreas() []ServiceArea {
+	return supportedServiceAreas
+}
+
+// NominatimProvider reverse-geocodes using OpenStreetMap Nominatim
+type NominatimProvider struct {
+	client *http.Client
+}
+
+// Name returns the provider name
+func (p *NominatimProvider) Name() string {
+	return "nominatim"
+}
+
+// ReverseGeocode resolves coordinates using the Nominatim reverse endpoint
+func (p *NominatimProvider) ReverseGeocode(lat, lng float64) (*ReverseGeocodeResult, error) {
+	apiURL := fmt.Sprintf("https://nominatim.openstreetmap.org/reverse?lat=%f&lon=%f&format=json&accept-language=fr", lat, lng)
+
+	req, err := http.NewRequest(http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Nominatim usage policy requires an identifying User-Agent
+	req.Header.Set("User-Agent", "repair-service-server/1.0")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make reverse geocoding request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocoding service returned status: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		DisplayName string `json:"display_name"`
+		Address     struct {
+			City    string `json:"city"`
+			Town    string `json:"town"`
+			Village string `json:"village"`
+			State   string `json:"state"`
+			Country string `json:"country"`
+		} `json:"address"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode reverse geocoding response: %w", err)
+	}
+
+	city := body.Address.City
+	if city == "" {
+		city = body.Address.Town
+	}
+	if city == "" {
+		city = body.Address.Village
+	}
+	if city == "" {
+		city = body.Address.State
+	}
+
+	return &ReverseGeocodeResult{
+		FormattedAddress: body.DisplayName,
+		City:             city,
+		Country:          body.Address.Country,
+	}, nil
+}
+
+// GoogleProvider reverse-geocodes using the Google Maps Geocoding API
+type GoogleProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// Name returns the provider name
+func (p *GoogleProvider) Name() string {
+	return "google"
+}
+
+// ReverseGeocode resolves coordinates using the Google Maps Geocoding API
+func (p *GoogleProvider) ReverseGeocode(lat, lng float64) (*ReverseGeocodeResult, error) {
+	apiURL := fmt.Sprintf("https://maps.googleapis.com/maps/api/geocode/json?latlng=%f,%f&key=%s",
+		lat, lng, url.QueryEscape(p.apiKey))
+
+	resp, err := p.client.Get(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make reverse geocoding request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocoding service returned status: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Status  string `json:"status"`
+		Results []struct {
+			FormattedAddress  string `json:"formatted_address"`
+			AddressComponents []struct {
+				LongName string   `json:"long_name"`
+				Types    []string `json:"types"`
+			} `json:"address_components"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode reverse geocoding response: %w", err)
+	}
+
+	if body.Status != "OK" || len(body.Results) == 0 {
+		return nil, fmt.Errorf("geocoding service returned status: %s", body.Status)
+	}
+
+	first := body.Results[0]
+	result := &ReverseGeocodeResult{FormattedAddress: first.FormattedAddress}
+	for _, component := range first.AddressComponents {
+		for _, t := range component.Types {
+			switch t {
+			case "locality":
+				result.City = component.LongName
+			case "country":
+				result.Country = component.LongName
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // Registers PNG decoding for image.Decode
+	"strings"
+)
+
+const (
+	maxDiagnosisImageBytes = 8 << 20 // Largest photo accepted for diagnosis
+	maxDiagnosisImageSide  = 1024    // Longer side after resizing; enough detail for Gemini at a fraction of the upload
+	diagnosisJPEGQuality   = 85
+)
+
+// ErrInvalidImage is returned for photos that are empty, too large or not a JPEG or PNG
+var ErrInvalidImage = errors.New("image must be a JPEG or PNG of at most 8MB")
+
+// DecodeBase64Image decodes a base64 photo as sent over the AI WebSocket, with or without a
+// data: URL prefix
+func DecodeBase64Image(encoded string) ([]byte, error) {
+	data, _, err := decodeBase64Data(encoded, maxDiagnosisImageBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return data, nil
+}
+
+// decodeBase64Data decodes base64 content of at most maxBytes, returning the MIME type of its
+// data: URL prefix if it has one
+func decodeBase64Data(encoded string, maxBytes int) ([]byte, string, error) {
+	var mimeType string
+	if i := strings.Index(encoded, ","); strings.HasPrefix(encoded, "data:") && i >= 0 {
+		mimeType, _, _ = strings.Cut(strings.TrimPrefix(encoded[:i], "data:"), ";")
+		encoded = encoded[i+1:]
+	}
+	encoded = strings.TrimSpace(encoded)
+	if base64.StdEncoding.DecodedLen(len(encoded)) > maxBytes {
+		return nil, "", fmt.Errorf("larger than %d bytes", maxBytes)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, mimeType, nil
+}
+
+// PrepareDiagnosisImage checks a photo, shrinks it so its longer side is at most 1024 pixels
+// and re-encodes it as JPEG
+func PrepareDiagnosisImage(data []byte) ([]byte, error) {
+	if len(data) == 0 || len(data) > maxDiagnosisImageBytes {
+		return nil, ErrInvalidImage
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, shrinkImage(src, maxDiagnosisImageSide), &jpeg.Options{Quality: diagnosisJPEGQuality}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// shrinkImage scales src down so neither side exceeds maxSide, averaging the source pixels
+// behind each output pixel. Smaller images are returned as they are.
+func shrinkImage(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return src
+	}
+
+	scale := float64(maxSide) / float64(max(width, height))
+	dstW := max(1, int(float64(width)*scale))
+	dstH := max(1, int(float64(height)*scale))
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*height/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*height/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*width/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*width/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// JobCodeLength is the number of digits in start and completion codes
+const JobCodeLength = 4
+
+// ErrJobCodeRequired is returned when a stage still needs its code and none was entered
+var ErrJobCodeRequired = errors.New("job code required")
+
+// JobCodeService issues the codes customers read out to workers to confirm a job started and
+// completed on site. Wrong guesses share the OTP attempt limit; a burned code can only be
+// overridden by an admin.
+type JobCodeService struct{}
+
+// NewJobCodeService creates a new job code service
+func NewJobCodeService() *JobCodeService {
+	return &JobCodeService{}
+}
+
+// Get returns the request's code for stage, creating it on first use
+func (s *JobCodeService) Get(requestID uint, stage models.JobCodeStage) (*models.JobCode, error) {
+	code, err := generateNumericCode(JobCodeLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// Concurrent first reads race to create the code; the unique index keeps exactly one
+	jobCode := models.JobCode{ServiceRequestID: requestID, Stage: stage, Code: code}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&jobCode).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Where("service_request_id = ? AND stage = ?", requestID, stage).First(&jobCode).Error; err != nil {
+		return nil, err
+	}
+	return &jobCode, nil
+}
+
+// Verify checks code against the request's code for stage and marks it used. Stages already
+// confirmed, by the worker or by an admin override, pass without a code.
+func (s *JobCodeService) Verify(requestID uint, stage models.JobCodeStage, code string) (*models.JobCode, error) {
+	jobCode, err := s.Get(requestID, stage)
+	if err != nil {
+		return nil, err
+	}
+	if jobCode.VerifiedAt != nil {
+		return jobCode, nil
+	}
+	if code == "" {
+		return nil, ErrJobCodeRequired
+	}
+	if jobCode.Attempts >= OTPMaxAttempts {
+		return nil, ErrOTPTooManyAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(jobCode.Code), []byte(code)) != 1 {
+		database.DB.Model(jobCode).Update("attempts", gorm.Expr("attempts + 1"))
+		if jobCode.Attempts+1 >= OTPMaxAttempts {
+			return nil, ErrOTPTooManyAttempts
+		}
+		return nil, ErrOTPInvalid
+	}
+
+	now := time.Now()
+	if err := database.DB.Model(&models.JobCode{}).
+		Where("id = ? AND verified_at IS NULL", jobCode.ID).
+		Update("verified_at", &now).Error; err != nil {
+		return nil, err
+	}
+	jobCode.VerifiedAt = &now
+	return jobCode, nil
+}
+
+// Override confirms stage without its code on behalf of adminID, returning the code before and
+// after for the audit log
+func (s *JobCodeService) Override(requestID uint, stage models.JobCodeStage, adminID uint, reason string) (models.JobCode, models.JobCode, error) {
+	jobCode, err := s.Get(requestID, stage)
+	if err != nil {
+		return models.JobCode{}, models.JobCode{}, err
+	}
+	before := *jobCode
+
+	now := time.Now()
+	jobCode.VerifiedAt = &now
+	jobCode.OverriddenBy = &adminID
+	jobCode.OverrideReason = reason
+	if err := database.DB.Model(jobCode).Updates(map[string]interface{}{
+		"verified_at":     jobCode.VerifiedAt,
+		"overridden_by":   jobCode.OverriddenBy,
+		"override_reason": jobCode.OverrideReason,
+	}).Error; err != nil {
+		return before, before, err
+	}
+	return before, *jobCode, nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	"repair-service-server/config"
 	"repair-service-server/database"
@@ -34,14 +35,14 @@ type TokenPair struct {
 
 // GenerateTokenPair generates both access and refresh tokens
 func (js *JWTService) GenerateTokenPair(userID uint, deviceID, userAgent, ipAddress string) (*TokenPair, error) {
-	// Generate access token (short-lived)
-	accessToken, expiresIn, err := js.generateAccessToken(userID)
+	// Generate refresh token (long-lived); it is the session the access token belongs to
+	refreshToken, sessionID, err := js.generateRefreshToken(userID, deviceID, userAgent, ipAddress)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate refresh token (long-lived)
-	refreshToken, err := js.generateRefreshToken(userID, deviceID, userAgent, ipAddress)
+	// Generate access token (short-lived)
+	accessToken, expiresIn, err := js.generateAccessToken(userID, sessionID)
 	if err != nil {
 		return nil, err
 	}
@@ -54,11 +55,19 @@ func (js *JWTService) GenerateTokenPair(userID uint, deviceID, userAgent, ipAddr
 	}, nil
 }
 
-// generateAccessToken generates a short-lived access token
-func (js *JWTService) generateAccessToken(userID uint) (string, int64, error) {
+// generateAccessToken generates a short-lived access token for a session, at the user's current
+// token version
+func (js *JWTService) generateAccessToken(userID, sessionID uint) (string, int64, error) {
+	var user models.User
+	if err := database.DB.Select("id", "token_version").First(&user, userID).Error; err != nil {
+		return "", 0, err
+	}
+
 	// Create claims
 	claims := &types.Claims{
-		UserID: userID,
+		UserID:       userID,
+		SessionID:    sessionID,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.AppConfig.JWT.ExpiryHours) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -81,32 +90,34 @@ func (js *JWTService) generateAccessToken(userID uint) (string, int64, error) {
 	return tokenString, expiresIn, nil
 }
 
-// generateRefreshToken generates a long-lived refresh token
-func (js *JWTService) generateRefreshToken(userID uint, deviceID, userAgent, ipAddress string) (string, error) {
+// generateRefreshToken generates a long-lived refresh token and returns it with its session ID
+func (js *JWTService) generateRefreshToken(userID uint, deviceID, userAgent, ipAddress string) (string, uint, error) {
 	// Generate a secure random token
 	tokenBytes := make([]byte, 32)
 	if _, err := rand.Read(tokenBytes); err != nil {
-		return "", err
+		return "", 0, err
 	}
 	tokenString := hex.EncodeToString(tokenBytes)
 
 	// Create refresh token record
+	now := time.Now()
 	refreshToken := &models.RefreshToken{
-		Token:     tokenString,
-		UserID:    userID,
-		ExpiresAt: time.Now().Add(30 * 24 * time.Hour), // 30 days
-		DeviceID:  deviceID,
-		UserAgent: userAgent,
-		IPAddress: ipAddress,
+		Token:      tokenString,
+		UserID:     userID,
+		ExpiresAt:  now.Add(30 * 24 * time.Hour), // 30 days
+		DeviceID:   deviceID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		LastUsedAt: &now,
 	}
 
 	// Save to database
 	if err := database.DB.Create(refreshToken).Error; err != nil {
-		return "", err
+		return "", 0, err
 	}
 
 	log.Printf("✅ Refresh token generated for user %d", userID)
-	return tokenString, nil
+	return tokenString, refreshToken.ID, nil
 }
 
 // ValidateAccessToken validates an access token
@@ -159,13 +170,14 @@ func (js *JWTService) RefreshAccessToken(refreshTokenString string) (*TokenPair,
 	}
 
 	// Generate new access token
-	accessToken, expiresIn, err := js.generateAccessToken(refreshToken.UserID)
+	accessToken, expiresIn, err := js.generateAccessToken(refreshToken.UserID, refreshToken.ID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update refresh token's last used time
-	refreshToken.UpdatedAt = time.Now()
+	now := time.Now()
+	refreshToken.LastUsedAt = &now
 	database.DB.Save(refreshToken)
 
 	return &TokenPair{
@@ -193,12 +205,18 @@ func (js *JWTService) RevokeRefreshToken(tokenString string) error {
 	return nil
 }
 
-// RevokeAllUserTokens revokes all refresh tokens for a user
+// RevokeAllUserTokens revokes all refresh tokens for a user and rejects every access token issued
+// so far
 func (js *JWTService) RevokeAllUserTokens(userID uint) error {
-	// Revoke all tokens for user
-	if err := database.DB.Model(&models.RefreshToken{}).
-		Where("user_id = ? AND is_revoked = ?", userID, false).
-		Update("is_revoked", true).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND is_revoked = ?", userID, false).
+			Update("is_revoked", true).Error; err != nil {
+			return err
+		}
+		return BumpTokenVersion(tx, userID)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -206,6 +224,19 @@ func (js *JWTService) RevokeAllUserTokens(userID uint) error {
 	return nil
 }
 
+// RevokeDeviceTokens revokes the user's refresh tokens issued to deviceID
+func (js *JWTService) RevokeDeviceTokens(userID uint, deviceID string) error {
+	return database.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND device_id = ? AND is_revoked = ?", userID, deviceID, false).
+		Update("is_revoked", true).Error
+}
+
+// BumpTokenVersion moves the user to a new token version, so tokens issued before are rejected
+func BumpTokenVersion(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.User{}).Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
 // CleanupExpiredTokens removes expired refresh tokens
 func (js *JWTService) CleanupExpiredTokens() error {
 	// Delete expired tokens
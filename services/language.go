@@ -0,0 +1,37 @@
+package services
+
+import (
+	"log"
+
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+)
+
+// UserLanguage returns the language to write to userID in, for messages sent outside a request
+// such as notifications and emails: their preferred language, or the default language of their
+// region. Workers are in the region of their city and customers in the default region.
+func UserLanguage(userID uint) string {
+	var lang string
+	if err := database.DB.Model(&models.User{}).Select("preferred_language").Where("id = ?", userID).Scan(&lang).Error; err != nil {
+		log.Printf("⚠️ Could not read preferred language of user %d: %v", userID, err)
+	}
+	if lang := i18n.Normalize(lang); lang != "" {
+		return lang
+	}
+
+	var city string
+	if err := database.DB.Model(&models.WorkerProfile{}).Select("city").Where("user_id = ?", userID).Scan(&city).Error; err != nil {
+		log.Printf("⚠️ Could not read city of user %d: %v", userID, err)
+	}
+	return i18n.Language(NewRegionService().ForCity(city).DefaultLanguage)
+}
+
+// aiLanguage is the language of the assistant's own replies: the one the client asked for, or
+// French, which the assistant answers in by default
+func aiLanguage(language string) string {
+	if lang := i18n.Normalize(language); lang != "" {
+		return lang
+	}
+	return i18n.French
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"gorm.io/gorm"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// ApprovedLineItems returns the parts the customer approved on a request and their total
+func ApprovedLineItems(tx *gorm.DB, requestID uint) ([]models.RequestLineItem, money.Amount, error) {
+	var items []models.RequestLineItem
+	if err := tx.Where("service_request_id = ? AND status = ?", requestID, models.LineItemApproved).
+		Order("id").Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total money.Amount
+	for _, item := range items {
+		total += item.Total()
+	}
+	return items, total, nil
+}
+
+// CountPendingLineItems returns how many parts on a request still await the customer's decision
+func CountPendingLineItems(tx *gorm.DB, requestID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&models.RequestLineItem{}).
+		Where("service_request_id = ? AND status = ?", requestID, models.LineItemPending).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// Sign-in throttling limits. Failures are counted per phone number and per client IP; a
+// counter resets once LoginFailureWindow passes without a new failure.
+const (
+	LoginFailureWindow    = 15 * time.Minute
+	LoginMaxPhoneFailures = 5  // Failures before the phone number is locked
+	LoginMaxIPFailures    = 20 // Failures before the IP is locked, across all numbers
+	LoginLockoutDuration  = 15 * time.Minute
+	loginDelayBase        = time.Second // Delay after the second failure, doubling with each one
+	loginDelayMax         = 30 * time.Second
+)
+
+// LoginThrottledError is returned while a phone number or IP must wait before trying again
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	// Locked is true during a lockout and false during a progressive delay
+	Locked bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed sign-ins, locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("sign-in attempted too soon after a failure, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginGuardService applies progressive delays and temporary lockouts to password sign-ins
+type LoginGuardService struct{}
+
+// NewLoginGuardService creates a new login guard service
+func NewLoginGuardService() *LoginGuardService {
+	return &LoginGuardService{}
+}
+
+// Check returns a *LoginThrottledError if phone or ip may not attempt a sign-in right now.
+// It must run before the password is checked.
+func (s *LoginGuardService) Check(phone, ip string) error {
+	var throttles []models.LoginThrottle
+	if err := database.DB.Where("key IN ?", []string{phoneThrottleKey(phone), ipThrottleKey(ip)}).
+		Find(&throttles).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var worst *LoginThrottledError
+	for _, t := range throttles {
+		var wait time.Duration
+		locked := t.LockedUntil != nil && t.LockedUntil.After(now)
+		if locked {
+			wait = t.LockedUntil.Sub(now)
+		} else if now.Sub(t.LastFailureAt) < LoginFailureWindow {
+			wait = t.LastFailureAt.Add(loginDelay(t.Failures)).Sub(now)
+		}
+		if wait > 0 && (worst == nil || wait > worst.RetryAfter) {
+			worst = &LoginThrottledError{RetryAfter: wait, Locked: locked}
+		}
+	}
+	if worst != nil {
+		return worst
+	}
+	return nil
+}
+
+// RecordFailure counts a failed sign-in. user is the account the phone belongs to, if any, and
+// is notified when this failure locks it.
+func (s *LoginGuardService) RecordFailure(phone, ip string, user *models.User) {
+	if s.recordFailure(phoneThrottleKey(phone), LoginMaxPhoneFailures) && user != nil {
+		log.Printf("🔒 User %d locked out after %d failed sign-ins", user.ID, LoginMaxPhoneFailures)
+		s.notifyLockout(user)
+	}
+	if s.recordFailure(ipThrottleKey(ip), LoginMaxIPFailures) {
+		log.Printf("🔒 IP %s locked out after %d failed sign-ins", ip, LoginMaxIPFailures)
+	}
+}
+
+// RecordSuccess clears the phone number's failures. The IP counter is kept so one valid
+// account cannot be used to reset guessing against others.
+func (s *LoginGuardService) RecordSuccess(phone string) {
+	if err := database.DB.Where("key = ?", phoneThrottleKey(phone)).Delete(&models.LoginThrottle{}).Error; err != nil {
+		log.Printf("⚠️ Failed to reset sign-in failures for %s: %v", phone, err)
+	}
+}
+
+// Unlock lifts a lockout on phone and reports whether one was active
+func (s *LoginGuardService) Unlock(phone string) (bool, error) {
+	var throttle models.LoginThrottle
+	result := database.DB.Where("key = ?", phoneThrottleKey(phone)).Limit(1).Find(&throttle)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+
+	if err := database.DB.Delete(&throttle).Error; err != nil {
+		return false, err
+	}
+	return throttle.LockedUntil != nil && throttle.LockedUntil.After(time.Now()), nil
+}
+
+// LockedUntil returns when phone's lockout ends, or nil if it is not locked
+func (s *LoginGuardService) LockedUntil(phone string) *time.Time {
+	var throttle models.LoginThrottle
+	result := database.DB.Where("key = ? AND locked_until > ?", phoneThrottleKey(phone), time.Now()).Limit(1).Find(&throttle)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil
+	}
+	return throttle.LockedUntil
+}
+
+// PurgeStale deletes counters that are neither recent nor locked
+func (s *LoginGuardService) PurgeStale() (int64, error) {
+	now := time.Now()
+	result := database.DB.Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", now.Add(-LoginFailureWindow), now).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
+
+// recordFailure increments key's counter, restarting it if the window has passed, and locks
+// the key once it reaches max. It reports whether this failure started a lockout.
+func (s *LoginGuardService) recordFailure(key string, max int) bool {
+	now := time.Now()
+
+	var throttle models.LoginThrottle
+	err := database.DB.Raw(`
+		INSERT INTO login_throttles (key, failures, last_failure_at, created_at, updated_at)
+		VALUES (?, 1, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < ? THEN 1 ELSE login_throttles.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING *`, key, now, now, now, now.Add(-LoginFailureWindow)).Scan(&throttle).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to record sign-in failure for %s: %v", key, err)
+		return false
+	}
+
+	if throttle.Failures < max || (throttle.LockedUntil != nil && throttle.LockedUntil.After(now)) {
+		return false
+	}
+
+	lockedUntil := now.Add(LoginLockoutDuration)
+	if err := database.DB.Model(&models.LoginThrottle{}).Where("id = ?", throttle.ID).Updates(map[string]interface{}{
+		"locked_until": &lockedUntil,
+		"failures":     0,
+	}).Error; err != nil {
+		log.Printf("⚠️ Failed to lock %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// notifyLockout tells the account owner through the outbox so a push failure does not affect
+// the sign-in response
+func (s *LoginGuardService) notifyLockout(user *models.User) {
+	payload := models.PushNotificationPayload{
+		UserID:  user.ID,
+		Message: "notification.login_lockout",
+		Vars:    map[string]interface{}{"minutes": int(LoginLockoutDuration.Minutes())},
+		Type:    "security",
+		Data:    map[string]interface{}{"reason": "login_lockout"},
+	}
+	if err := EnqueueOutboxEvent(database.DB, models.OutboxEventPushNotification, user.ID, payload); err != nil {
+		log.Printf("⚠️ Failed to queue lockout notification for user %d: %v", user.ID, err)
+	}
+}
+
+// loginDelay is the wait required after failures consecutive failures
+func loginDelay(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+	delay := loginDelayBase << (failures - 2)
+	if delay > loginDelayMax || delay <= 0 {
+		return loginDelayMax
+	}
+	return delay
+}
+
+func phoneThrottleKey(phone string) string {
+	return "phone:" + phone
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
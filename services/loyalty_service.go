@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// loyaltyWindow is how far back completed requests count towards a customer's tier
+const loyaltyWindow = 365 * 24 * time.Hour
+
+// loyaltyRecomputeBatch is how many customers the nightly recompute loads at once
+const loyaltyRecomputeBatch = 500
+
+// LoyaltyBenefits are what a loyalty tier gives a customer
+type LoyaltyBenefits struct {
+	DiscountPercent  float64 `json:"discount_percent"`  // Off service option prices
+	PriorityDispatch bool    `json:"priority_dispatch"` // Requests are shown to workers first
+}
+
+// LoyaltyTierInfo is a tier with the points it needs and its benefits
+type LoyaltyTierInfo struct {
+	Tier      models.LoyaltyTier `json:"tier"`
+	MinPoints int                `json:"min_points"`
+	Benefits  LoyaltyBenefits    `json:"benefits"`
+}
+
+// LoyaltyNextTier is the tier a customer can reach next and the points still needed
+type LoyaltyNextTier struct {
+	Tier         models.LoyaltyTier `json:"tier"`
+	PointsNeeded int                `json:"points_needed"`
+}
+
+// LoyaltyStatus is what a customer sees of their loyalty
+type LoyaltyStatus struct {
+	models.CustomerLoyalty
+	Benefits LoyaltyBenefits   `json:"benefits"`
+	NextTier *LoyaltyNextTier  `json:"next_tier"` // Nil at the top tier
+	Tiers    []LoyaltyTierInfo `json:"tiers"`
+}
+
+// LoyaltyService computes customers' loyalty points, tiers and streaks from their completed requests
+type LoyaltyService struct {
+	db       *gorm.DB
+	settings *SettingsService
+}
+
+// NewLoyaltyService creates a new loyalty service
+func NewLoyaltyService() *LoyaltyService {
+	return &LoyaltyService{
+		db:       database.DB,
+		settings: NewSettingsService(),
+	}
+}
+
+// Tiers returns every tier with its current thresholds and benefits, from lowest to highest
+func (s *LoyaltyService) Tiers() []LoyaltyTierInfo {
+	return []LoyaltyTierInfo{
+		{Tier: models.LoyaltyBronze, MinPoints: 0, Benefits: s.Benefits(models.LoyaltyBronze)},
+		{Tier: models.LoyaltySilver, MinPoints: int(s.settings.Float(SettingLoyaltySilverPoints)), Benefits: s.Benefits(models.LoyaltySilver)},
+		{Tier: models.LoyaltyGold, MinPoints: int(s.settings.Float(SettingLoyaltyGoldPoints)), Benefits: s.Benefits(models.LoyaltyGold)},
+	}
+}
+
+// Benefits returns what tier gives a customer
+func (s *LoyaltyService) Benefits(tier models.LoyaltyTier) LoyaltyBenefits {
+	switch tier {
+	case models.LoyaltySilver:
+		return LoyaltyBenefits{DiscountPercent: s.settings.Float(SettingLoyaltySilverDiscountPercent)}
+	case models.LoyaltyGold:
+		return LoyaltyBenefits{DiscountPercent: s.settings.Float(SettingLoyaltyGoldDiscountPercent), PriorityDispatch: true}
+	}
+	return LoyaltyBenefits{}
+}
+
+// DiscountPercent returns the discount the customer's stored tier gives, without recomputing it
+func (s *LoyaltyService) DiscountPercent(customerID uint) float64 {
+	var loyalty models.CustomerLoyalty
+	if err := s.db.Where("user_id = ?", customerID).First(&loyalty).Error; err != nil {
+		return 0
+	}
+	return s.Benefits(loyalty.Tier).DiscountPercent
+}
+
+// Status returns the customer's loyalty, computing it the first time they ask
+func (s *LoyaltyService) Status(customerID uint) (LoyaltyStatus, error) {
+	var loyalty models.CustomerLoyalty
+	err := s.db.Where("user_id = ?", customerID).First(&loyalty).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var recomputed *models.CustomerLoyalty
+		recomputed, err = s.Recompute(customerID)
+		if recomputed != nil {
+			loyalty = *recomputed
+		}
+	}
+	if err != nil {
+		return LoyaltyStatus{}, err
+	}
+
+	tiers := s.Tiers()
+	status := LoyaltyStatus{
+		CustomerLoyalty: loyalty,
+		Benefits:        s.Benefits(loyalty.Tier),
+		Tiers:           tiers,
+	}
+	for _, tier := range tiers {
+		if tier.MinPoints > loyalty.Points {
+			status.NextTier = &LoyaltyNextTier{Tier: tier.Tier, PointsNeeded: tier.MinPoints - loyalty.Points}
+			break
+		}
+	}
+	return status, nil
+}
+
+// Recompute rebuilds the customer's points, tier and streak from their completed requests
+func (s *LoyaltyService) Recompute(customerID uint) (*models.CustomerLoyalty, error) {
+	var completedAt []time.Time
+	if err := s.db.Model(&models.CustomerServiceRequest{}).
+		Where("customer_id = ? AND status = ? AND completed_at IS NOT NULL", customerID, models.RequestStatusCompleted).
+		Pluck("completed_at", &completedAt).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	perRequest := int(s.settings.Float(SettingLoyaltyPointsPerRequest))
+	recent := 0
+	for _, at := range completedAt {
+		if now.Sub(at) <= loyaltyWindow {
+			recent++
+		}
+	}
+
+	loyalty := models.CustomerLoyalty{
+		UserID:            customerID,
+		Points:            recent * perRequest,
+		LifetimePoints:    len(completedAt) * perRequest,
+		CompletedRequests: len(completedAt),
+		StreakMonths:      loyaltyStreak(completedAt, now),
+		TierSince:         now,
+		ComputedAt:        now,
+	}
+	loyalty.Tier = models.LoyaltyBronze
+	if loyalty.Points >= int(s.settings.Float(SettingLoyaltyGoldPoints)) {
+		loyalty.Tier = models.LoyaltyGold
+	} else if loyalty.Points >= int(s.settings.Float(SettingLoyaltySilverPoints)) {
+		loyalty.Tier = models.LoyaltySilver
+	}
+
+	var previous models.CustomerLoyalty
+	if err := s.db.Where("user_id = ?", customerID).First(&previous).Error; err == nil && previous.Tier == loyalty.Tier {
+		loyalty.TierSince = previous.TierSince
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&loyalty).Error; err != nil {
+		return nil, err
+	}
+	return &loyalty, nil
+}
+
+// RecomputeAll recomputes every active customer who has completed a request or already has a tier,
+// so points older than 12 months stop counting. It returns how many customers were recomputed.
+func (s *LoyaltyService) RecomputeAll() (int, error) {
+	recomputed := 0
+	var lastID uint
+	for {
+		var customerIDs []uint
+		if err := s.db.Model(&models.User{}).
+			Where("is_active = ? AND id > ?", true, lastID).
+			Where("id IN (?) OR id IN (?)",
+				s.db.Model(&models.CustomerServiceRequest{}).Select("customer_id").Where("status = ?", models.RequestStatusCompleted),
+				s.db.Model(&models.CustomerLoyalty{}).Select("user_id")).
+			Order("id").
+			Limit(loyaltyRecomputeBatch).
+			Pluck("id", &customerIDs).Error; err != nil {
+			return recomputed, err
+		}
+		if len(customerIDs) == 0 {
+			return recomputed, nil
+		}
+
+		for _, customerID := range customerIDs {
+			if _, err := s.Recompute(customerID); err != nil {
+				return recomputed, err
+			}
+			recomputed++
+		}
+		lastID = customerIDs[len(customerIDs)-1]
+	}
+}
+
+// loyaltyStreak counts the consecutive calendar months with a completed request, ending this month
+// or, while this month has none yet, last month
+func loyaltyStreak(completedAt []time.Time, now time.Time) int {
+	months := make(map[int]bool, len(completedAt))
+	for _, at := range completedAt {
+		at = at.UTC()
+		months[at.Year()*12+int(at.Month())-1] = true
+	}
+
+	now = now.UTC()
+	month := now.Year()*12 + int(now.Month()) - 1
+	if !months[month] {
+		month--
+	}
+	streak := 0
+	for months[month] {
+		streak++
+		month--
+	}
+	return streak
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/utils"
+)
+
+// OfferAcceptWindow is how long a worker has to accept an auto-assignment offer
+const OfferAcceptWindow = 45 * time.Second
+
+// Scoring weights used to rank candidates for auto-assignment
+const (
+	weightDistance     = 0.35
+	weightRating       = 0.20
+	weightCompletion   = 0.15
+	weightResponseTime = 0.15
+	weightLoad         = 0.15
+)
+
+// MatchCandidate is a worker eligible for an auto-assigned request, with its score
+type MatchCandidate struct {
+	Worker   models.WorkerProfile `json:"-"`
+	WorkerID uint                 `json:"worker_id"`
+	Distance float64              `json:"distance"`
+	Score    float64              `json:"score"`
+}
+
+// MatchingService scores workers and manages the auto-assignment offer cascade
+type MatchingService struct {
+	db *gorm.DB
+}
+
+// NewMatchingService creates a new matching service
+func NewMatchingService() *MatchingService {
+	return &MatchingService{
+		db: database.DB,
+	}
+}
+
+// RankCandidates returns the eligible workers for a request ordered by score, best first.
+// Workers who already received an offer for this request are excluded.
+func (s *MatchingService) RankCandidates(request models.CustomerServiceRequest, radiusKm float64) ([]MatchCandidate, error) {
+	if request.LocationLat == nil || request.LocationLng == nil {
+		return nil, nil
+	}
+
+	categoryIDs, err := NewCategoryService().TargetCategoryIDs(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var workers []models.WorkerProfile
+	err = s.db.Where(
+		"is_available = ? AND current_lat IS NOT NULL AND current_lng IS NOT NULL AND id NOT IN (SELECT worker_id FROM dispatch_offers WHERE service_request_id = ?)",
+		true, request.ID,
+	).Scopes(WorkerInCategoriesScope(categoryIDs), HasCapacityScope, NotSuspendedScope).Find(&workers).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(workers) == 0 {
+		return nil, nil
+	}
+
+	workerIDs := make([]uint, len(workers))
+	for i, w := range workers {
+		workerIDs[i] = w.ID
+	}
+
+	onShift, err := NewWorkerScheduleService().FilterAvailableAt(workerIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []models.WorkerStats
+	if err := s.db.Where("worker_id IN ?", workerIDs).Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	statsByWorker := make(map[uint]models.WorkerStats, len(stats))
+	for _, st := range stats {
+		statsByWorker[st.WorkerID] = st
+	}
+
+	var loads []struct {
+		WorkerID uint
+		Active   int
+	}
+	if err := s.db.Model(&models.CustomerServiceRequest{}).
+		Select("assigned_worker_id AS worker_id, COUNT(*) AS active").
+		Where("assigned_worker_id IN ? AND status IN ?", workerIDs, models.ActiveRequestStatuses).
+		Group("assigned_worker_id").Scan(&loads).Error; err != nil {
+		return nil, err
+	}
+	activeByWorker := make(map[uint]int, len(loads))
+	for _, l := range loads {
+		activeByWorker[l.WorkerID] = l.Active
+	}
+
+	candidates := make([]MatchCandidate, 0, len(workers))
+	for _, w := range workers {
+		if !onShift[w.ID] {
+			continue
+		}
+		distance := utils.HaversineDistance(*w.CurrentLat, *w.CurrentLng, *request.LocationLat, *request.LocationLng)
+		if distance > radiusKm {
+			continue
+		}
+
+		var st *models.WorkerStats
+		if v, ok := statsByWorker[w.ID]; ok {
+			st = &v
+		}
+		capacity := CalculateWorkerCapacity(w.MaxConcurrentJobs, activeByWorker[w.ID])
+
+		candidates = append(candidates, MatchCandidate{
+			Worker:   w,
+			WorkerID: w.ID,
+			Distance: distance,
+			Score:    scoreCandidate(w, st, capacity, distance, radiusKm),
+		})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates, nil
+}
+
+// scoreCandidate combines distance, rating, completion rate, response time and load into a 0..1 score,
+// scaled by the worker's reliability
+func scoreCandidate(worker models.WorkerProfile, stats *models.WorkerStats, capacity *WorkerCapacity, distance, radiusKm float64) float64 {
+	distanceScore := 1 - math.Min(distance/radiusKm, 1)
+	ratingScore := worker.Rating / 5
+
+	// Workers without history get a neutral score so new workers still receive jobs
+	completionScore := 0.5
+	responseScore := 0.5
+	if stats != nil && stats.TotalJobsResponded > 0 {
+		completionScore = math.Min(stats.CompletionRate/100, 1)
+		responseScore = 1 / (1 + stats.AverageResponseTime/5)
+	}
+
+	loadScore := float64(capacity.Remaining) / float64(capacity.MaxConcurrentJobs)
+
+	score := weightDistance*distanceScore +
+		weightRating*ratingScore +
+		weightCompletion*completionScore +
+		weightResponseTime*responseScore +
+		weightLoad*loadScore
+
+	// No-shows scale the whole score down, so unreliable workers are offered jobs last
+	return score * worker.ReliabilityScore / 100
+}
+
+// OfferToNextCandidate creates a pending offer for the best worker not yet offered the request.
+// It returns nil when every eligible worker has been tried.
+func (s *MatchingService) OfferToNextCandidate(request models.CustomerServiceRequest, radiusKm float64) (*models.DispatchOffer, error) {
+	candidates, err := s.RankCandidates(request, radiusKm)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var previousOffers int64
+	if err := s.db.Model(&models.DispatchOffer{}).Where("service_request_id = ?", request.ID).Count(&previousOffers).Error; err != nil {
+		return nil, err
+	}
+
+	best := candidates[0]
+	offer := models.DispatchOffer{
+		ServiceRequestID: request.ID,
+		WorkerID:         best.WorkerID,
+		Rank:             int(previousOffers) + 1,
+		Score:            best.Score,
+		Distance:         best.Distance,
+		Status:           models.OfferStatusPending,
+		ExpiresAt:        time.Now().Add(OfferAcceptWindow),
+	}
+	if err := s.db.Create(&offer).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("🎯 Request %d offered to worker %d (rank %d, score %.3f, %.2f km)",
+		request.ID, best.WorkerID, offer.Rank, best.Score, best.Distance)
+
+	return &offer, nil
+}
+
+// GetPendingOffer returns the worker's open offer for a request, if any
+func (s *MatchingService) GetPendingOffer(requestID, workerID uint) (*models.DispatchOffer, error) {
+	var offer models.DispatchOffer
+	err := s.db.Where("service_request_id = ? AND worker_id = ? AND status = ? AND expires_at > ?",
+		requestID, workerID, models.OfferStatusPending, time.Now()).First(&offer).Error
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// ResolveOffer marks an offer as accepted or declined
+func (s *MatchingService) ResolveOffer(offer *models.DispatchOffer, status models.DispatchOfferStatus) error {
+	now := time.Now()
+	offer.Status = status
+	offer.RespondedAt = &now
+	return s.db.Save(offer).Error
+}
+
+// ExpireStaleOffers marks timed-out pending offers as expired and returns the affected request IDs
+func (s *MatchingService) ExpireStaleOffers() ([]uint, error) {
+	var stale []models.DispatchOffer
+	if err := s.db.Where("status = ? AND expires_at <= ?", models.OfferStatusPending, time.Now()).
+		Find(&stale).Error; err != nil {
+		return nil, err
+	}
+	if len(stale) == 0 {
+		return nil, nil
+	}
+
+	requestIDs := make([]uint, 0, len(stale))
+	offerIDs := make([]uint, 0, len(stale))
+	for _, offer := range stale {
+		offerIDs = append(offerIDs, offer.ID)
+		requestIDs = append(requestIDs, offer.ServiceRequestID)
+	}
+
+	if err := s.db.Model(&models.DispatchOffer{}).Where("id IN ?", offerIDs).
+		Update("status", models.OfferStatusExpired).Error; err != nil {
+		return nil, err
+	}
+
+	return requestIDs, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// gpsIFDTag is the EXIF tag pointing at the GPS IFD
+const gpsIFDTag = 0x8825
+
+var errMalformedEXIF = errors.New("malformed EXIF")
+
+// stripPhotoGPS removes the location from a photo's EXIF block, keeping the rest such as its
+// orientation. A malformed EXIF block is dropped whole rather than risk leaving a location behind.
+func stripPhotoGPS(mimeType string, data []byte) ([]byte, error) {
+	switch mimeType {
+	case "image/jpeg":
+		return stripJPEGGPS(data)
+	case "image/png":
+		return stripPNGGPS(data)
+	case "image/webp":
+		return stripWebPGPS(data)
+	}
+	return data, nil
+}
+
+// stripJPEGGPS clears the GPS IFD of every EXIF APP1 segment before the image data
+func stripJPEGGPS(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, errMalformedEXIF
+	}
+	var out bytes.Buffer
+	out.Write(data[:2])
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return nil, errMalformedEXIF
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 { // Start of scan or end of image: no more metadata
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return nil, errMalformedEXIF
+		}
+		segment := append([]byte(nil), data[pos:end]...)
+		if marker == 0xE1 && bytes.HasPrefix(segment[4:], []byte("Exif\x00\x00")) {
+			if err := clearGPSIFD(segment[10:]); err != nil {
+				pos = end // Drop the segment
+				continue
+			}
+		}
+		out.Write(segment)
+		pos = end
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), nil
+}
+
+// stripPNGGPS clears the GPS IFD of the eXIf chunk and recomputes its checksum
+func stripPNGGPS(data []byte) ([]byte, error) {
+	if len(data) < 8 || !bytes.Equal(data[:8], []byte("\x89PNG\r\n\x1a\n")) {
+		return nil, errMalformedEXIF
+	}
+	var out bytes.Buffer
+	out.Write(data[:8])
+	pos := 8
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, errMalformedEXIF
+		}
+		chunk := append([]byte(nil), data[pos:end]...)
+		if string(chunk[4:8]) == "eXIf" {
+			if err := clearGPSIFD(chunk[8 : 8+length]); err != nil {
+				pos = end
+				continue
+			}
+			binary.BigEndian.PutUint32(chunk[8+length:], crc32.ChecksumIEEE(chunk[4:8+length]))
+		}
+		out.Write(chunk)
+		pos = end
+	}
+	out.Write(data[pos:])
+	return out.Bytes(), nil
+}
+
+// stripWebPGPS clears the GPS IFD of the EXIF chunk. A malformed chunk is dropped, fixing the
+// RIFF size and the extended header's EXIF flag.
+func stripWebPGPS(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errMalformedEXIF
+	}
+	var out bytes.Buffer
+	out.Write(data[:12])
+	vp8x, dropped := -1, false
+	pos := 12
+	for pos+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2 // Chunks are padded to an even size
+		if size < 0 || end > len(data) {
+			return nil, errMalformedEXIF
+		}
+		chunk := append([]byte(nil), data[pos:end]...)
+		switch string(chunk[:4]) {
+		case "VP8X":
+			vp8x = out.Len()
+		case "EXIF":
+			payload := chunk[8 : 8+size]
+			payload = bytes.TrimPrefix(payload, []byte("Exif\x00\x00"))
+			if err := clearGPSIFD(payload); err != nil {
+				dropped = true
+				pos = end
+				continue
+			}
+		}
+		out.Write(chunk)
+		pos = end
+	}
+	out.Write(data[pos:])
+
+	result := out.Bytes()
+	if dropped {
+		binary.LittleEndian.PutUint32(result[4:], uint32(len(result)-8))
+		if vp8x >= 0 && vp8x+8 < len(result) {
+			result[vp8x+8] &^= 0x08 // EXIF present flag
+		}
+	}
+	return result, nil
+}
+
+// clearGPSIFD empties the GPS IFD of a TIFF-structured EXIF block in place, zeroing its entries and
+// the values they point to
+func clearGPSIFD(tiff []byte) error {
+	if len(tiff) < 8 {
+		return errMalformedEXIF
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return errMalformedEXIF
+	}
+	if order.Uint16(tiff[2:]) != 42 {
+		return errMalformedEXIF
+	}
+
+	ifd0 := int(order.Uint32(tiff[4:]))
+	entries, err := ifdEntries(tiff, order, ifd0)
+	if err != nil {
+		return err
+	}
+	gps := -1
+	for _, entry := range entries {
+		if order.Uint16(tiff[entry:]) == gpsIFDTag {
+			gps = int(order.Uint32(tiff[entry+8:]))
+		}
+	}
+	if gps < 0 {
+		return nil
+	}
+
+	gpsEntries, err := ifdEntries(tiff, order, gps)
+	if err != nil {
+		return err
+	}
+	for _, entry := range gpsEntries {
+		size := exifTypeSize(order.Uint16(tiff[entry+2:])) * int(order.Uint32(tiff[entry+4:]))
+		if size > 4 {
+			offset := int(order.Uint32(tiff[entry+8:]))
+			if offset < 0 || size < 0 || offset+size > len(tiff) {
+				return errMalformedEXIF
+			}
+			clear(tiff[offset : offset+size])
+		}
+	}
+	// No entries, and the zeroed bytes after the count read as "no next IFD"
+	clear(tiff[gps : gps+2+12*len(gpsEntries)])
+	return nil
+}
+
+// ifdEntries returns the offsets of the 12-byte entries of the IFD at offset
+func ifdEntries(tiff []byte, order binary.ByteOrder, offset int) ([]int, error) {
+	if offset < 8 || offset+2 > len(tiff) {
+		return nil, errMalformedEXIF
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	if offset+2+12*count+4 > len(tiff) {
+		return nil, errMalformedEXIF
+	}
+	entries := make([]int, count)
+	for i := range entries {
+		entries[i] = offset + 2 + 12*i
+	}
+	return entries, nil
+}
+
+// exifTypeSize returns the size in bytes of one value of an EXIF field type
+func exifTypeSize(fieldType uint16) int {
+	switch fieldType {
+	case 3, 8: // SHORT, SSHORT
+		return 2
+	case 4, 9, 11: // LONG, SLONG, FLOAT
+		return 4
+	case 5, 10, 12: // RATIONAL, SRATIONAL, DOUBLE
+		return 8
+	}
+	return 1 // BYTE, ASCII, SBYTE, UNDEFINED
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/gabriel-vasile/mimetype"
+	"gorm.io/gorm"
+
+	"repair-service-server/config"
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+var (
+	// ErrMediaTooLarge is returned for empty files and files over their type's size limit
+	ErrMediaTooLarge = errors.New("file is empty or too large")
+	// ErrMediaType is returned when a file's content is not one of the types its purpose accepts
+	ErrMediaType = errors.New("file type is not accepted")
+	// ErrMediaInfected is returned when the scanner finds malware in a file
+	ErrMediaInfected = errors.New("file was rejected by the malware scan")
+)
+
+// mediaKind groups the content types accepted for a purpose with their size limit
+type mediaKind struct {
+	name      string
+	mimeTypes []string // Accepted sniffed types
+	maxBytes  int64
+	resource  string // Cloudinary resource type
+}
+
+var (
+	photoKind = mediaKind{
+		name:      "photo",
+		mimeTypes: []string{"image/jpeg", "image/png", "image/webp"},
+		maxBytes:  5 << 20,
+		resource:  "image",
+	}
+	voiceKind = mediaKind{
+		name:      "voice message",
+		mimeTypes: []string{"audio/mpeg", "audio/mp4", "audio/x-m4a"},
+		maxBytes:  10 << 20,
+		resource:  "video", // Cloudinary stores audio as video
+	}
+	videoKind = mediaKind{
+		name:      "video",
+		mimeTypes: []string{"video/mp4", "video/quicktime", "video/webm"},
+		maxBytes:  200 << 20,
+		resource:  "video",
+	}
+)
+
+// mediaKinds maps each purpose to what it accepts
+var mediaKinds = map[models.MediaPurpose]mediaKind{
+	models.MediaPurposeProfilePhoto:   photoKind,
+	models.MediaPurposeIDCard:         photoKind,
+	models.MediaPurposePortfolioPhoto: photoKind,
+	models.MediaPurposeReceiptPhoto:   photoKind,
+	models.MediaPurposeVoiceMessage:   voiceKind,
+	models.MediaPurposePortfolioVideo: videoKind,
+	models.MediaPurposeRequestVideo:   videoKind,
+}
+
+// MediaLimitMessage describes what a purpose accepts, for error messages
+func MediaLimitMessage(purpose models.MediaPurpose) string {
+	kind := mediaKinds[purpose]
+	types := make([]string, len(kind.mimeTypes))
+	for i, mimeType := range kind.mimeTypes {
+		types[i] = mimeType[strings.Index(mimeType, "/")+1:]
+	}
+	return fmt.Sprintf("The %s must be %s, at most %dMB", kind.name, strings.Join(types, ", "), kind.maxBytes>>20)
+}
+
+// PreparedMedia is an uploaded file that passed validation and scanning, ready to be stored
+type PreparedMedia struct {
+	Purpose    models.MediaPurpose
+	MIMEType   string
+	Extension  string // Matches the sniffed type, e.g. ".m4a"
+	Data       []byte
+	ScanStatus models.MediaScanStatus
+}
+
+// MediaService validates, scans and stores every user upload, recording each in media_uploads
+type MediaService struct {
+	db      *gorm.DB
+	scanner MediaScanner
+}
+
+// NewMediaService creates a media service using the configured scanner
+func NewMediaService() *MediaService {
+	return &MediaService{
+		db:      database.DB,
+		scanner: NewMediaScanner(),
+	}
+}
+
+// Prepare reads an uploaded file and checks it for purpose. The type is sniffed from the content,
+// whatever the file name says. Photos lose their EXIF location, and the result is scanned.
+func (s *MediaService) Prepare(purpose models.MediaPurpose, header *multipart.FileHeader) (*PreparedMedia, error) {
+	kind, ok := mediaKinds[purpose]
+	if !ok {
+		return nil, fmt.Errorf("unknown media purpose %q", purpose)
+	}
+	if header.Size <= 0 || header.Size > kind.maxBytes {
+		return nil, ErrMediaTooLarge
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, kind.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || int64(len(data)) > kind.maxBytes {
+		return nil, ErrMediaTooLarge
+	}
+
+	detected := mimetype.Detect(data)
+	mimeType := detected.String()
+	if !slices.Contains(kind.mimeTypes, mimeType) {
+		return nil, fmt.Errorf("%w: %s", ErrMediaType, mimeType)
+	}
+	if kind.resource == "image" {
+		if data, err = stripPhotoGPS(mimeType, data); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMediaType, err)
+		}
+	}
+
+	media := &PreparedMedia{Purpose: purpose, MIMEType: mimeType, Extension: detected.Extension(), Data: data, ScanStatus: models.MediaScanSkipped}
+	if s.scanner != nil {
+		if err := s.scanner.Scan(data); err != nil {
+			return nil, err
+		}
+		media.ScanStatus = models.MediaScanClean
+	}
+	return media, nil
+}
+
+// Store uploads prepared media to folder in Cloudinary and records it as owned by ownerID
+func (s *MediaService) Store(ownerID uint, media *PreparedMedia, folder string) (*models.MediaUpload, error) {
+	cld, err := NewCloudinaryClient()
+	if err != nil {
+		return nil, err
+	}
+	kind := mediaKinds[media.Purpose]
+	uniqueFilename := true
+	params := uploader.UploadParams{
+		Folder:         folder,
+		UniqueFilename: &uniqueFilename,
+		ResourceType:   kind.resource,
+	}
+	if kind.resource == "video" {
+		params.Format = "mp3" // Every client can play MP3
+	}
+	if media.Purpose.IsPrivate() {
+		params.Type = api.DeliveryType(privateDeliveryType)
+	}
+	uploaded, err := cld.Upload.Upload(context.Background(), bytes.NewReader(media.Data), params)
+	if err != nil {
+		return nil, err
+	}
+	if uploaded.Error.Message != "" {
+		return nil, errors.New(uploaded.Error.Message)
+	}
+
+	upload := &models.MediaUpload{
+		OwnerID:    ownerID,
+		Purpose:    media.Purpose,
+		MIMEType:   media.MIMEType,
+		Size:       int64(len(media.Data)),
+		URL:        uploaded.SecureURL,
+		PublicID:   uploaded.PublicID,
+		Resource:   kind.resource,
+		ScanStatus: media.ScanStatus,
+		Private:    media.Purpose.IsPrivate(),
+	}
+	if err := s.db.Create(upload).Error; err != nil {
+		if upload.Private {
+			// Private files can only be reached through their record
+			return nil, fmt.Errorf("recording private upload %s: %w", uploaded.PublicID, err)
+		}
+		// The file is stored; a missing record only means it won't be cleaned up with its owner
+		log.Printf("⚠️ Failed to record media upload %s: %v", uploaded.PublicID, err)
+	}
+	return upload, nil
+}
+
+// Upload prepares and stores a file in one step
+func (s *MediaService) Upload(ownerID uint, purpose models.MediaPurpose, header *multipart.FileHeader, folder string) (*models.MediaUpload, error) {
+	media, err := s.Prepare(purpose, header)
+	if err != nil {
+		return nil, err
+	}
+	return s.Store(ownerID, media, folder)
+}
+
+// Delete removes an upload from Cloudinary by public ID and marks its record deleted
+func (s *MediaService) Delete(publicID string) error {
+	var upload models.MediaUpload
+	resource, deliveryType := "image", ""
+	if err := s.db.Where("public_id = ? AND deleted_at IS NULL", publicID).First(&upload).Error; err == nil {
+		resource = upload.Resource
+		if upload.Private {
+			deliveryType = privateDeliveryType
+		}
+	}
+
+	cld, err := NewCloudinaryClient()
+	if err != nil {
+		return err
+	}
+	if _, err := cld.Upload.Destroy(context.Background(), uploader.DestroyParams{PublicID: publicID, Type: deliveryType, ResourceType: resource}); err != nil {
+		return err
+	}
+	if upload.ID != 0 {
+		return s.db.Model(&upload).Update("deleted_at", time.Now()).Error
+	}
+	return nil
+}
+
+// NewCloudinaryClient connects to Cloudinary with the CLOUDINARY_* environment variables
+func NewCloudinaryClient() (*cloudinary.Cloudinary, error) {
+	cld := config.AppConfig.Cloudinary
+	if !cld.Configured() {
+		return nil, errors.New("CLOUDINARY_CLOUD_NAME, CLOUDINARY_API_KEY and CLOUDINARY_API_SECRET must be set")
+	}
+	return cloudinary.NewFromURL(fmt.Sprintf("cloudinary://%s:%s@%s", cld.APIKey, cld.APISecret, cld.CloudName))
+}
+
+// MediaScanner is implemented by every malware scanning backend. Scan returns ErrMediaInfected for
+// a file it rejects and any other error when it could not scan.
+type MediaScanner interface {
+	Name() string
+	Scan(data []byte) error
+}
+
+// NewMediaScanner returns the scanner selected by MEDIA_SCANNER ("clamav"), or nil when uploads are
+// not scanned
+func NewMediaScanner() MediaScanner {
+	switch strings.ToLower(os.Getenv("MEDIA_SCANNER")) {
+	case "clamav":
+		addr := os.Getenv("CLAMAV_ADDRESS")
+		if addr == "" {
+			addr = "localhost:3310"
+		}
+		return &ClamAVScanner{address: addr, timeout: 30 * time.Second}
+	default:
+		return nil
+	}
+}
+
+// ClamAVScanner streams files to a clamd daemon with the INSTREAM command
+type ClamAVScanner struct {
+	address string
+	timeout time.Duration
+}
+
+// Name returns the scanner name
+func (s *ClamAVScanner) Name() string {
+	return "clamav"
+}
+
+// Scan sends data to clamd in chunks and reads its verdict
+func (s *ClamAVScanner) Scan(data []byte) error {
+	conn, err := net.DialTimeout("tcp", s.address, s.timeout)
+	if err != nil {
+		return fmt.Errorf("connecting to clamd: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(s.timeout))
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+	const chunkSize = 64 << 10
+	size := make([]byte, 4)
+	for start := 0; start < len(data); start += chunkSize {
+		chunk := data[start:min(start+chunkSize, len(data))]
+		binary.BigEndian.PutUint32(size, uint32(len(chunk)))
+		if _, err := conn.Write(append(size, chunk...)); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return err
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return err
+	}
+	verdict := strings.TrimRight(string(reply), "\x00\n")
+	switch {
+	case strings.HasSuffix(verdict, "OK"):
+		return nil
+	case strings.HasSuffix(verdict, "FOUND"):
+		log.Printf("🦠 Upload rejected by clamd: %s", verdict)
+		return ErrMediaInfected
+	}
+	return fmt.Errorf("clamd: %s", verdict)
+}
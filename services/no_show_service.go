@@ -0,0 +1,152 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// NoShowService takes requests away from accepted workers who never set off or started the job
+type NoShowService struct {
+	db *gorm.DB
+}
+
+// NewNoShowService creates a new no-show service
+func NewNoShowService() *NoShowService {
+	return &NoShowService{
+		db: database.DB,
+	}
+}
+
+// noShowWindows are the no-show settings read once per sweep
+type noShowWindows struct {
+	enRoute time.Duration
+	start   time.Duration
+}
+
+// FlagDue flags every accepted worker who is past the en-route or start window. Each is taken off
+// the request and records a no-show reliability event, and the customer is told. The returned
+// requests are unassigned, back in broadcast or pending for auto-dispatch, and ready to be offered
+// to other workers.
+func (s *NoShowService) FlagDue(now time.Time) ([]models.CustomerServiceRequest, error) {
+	settings := NewSettingsService()
+	windows := noShowWindows{
+		enRoute: time.Duration(settings.Float(SettingNoShowEnRouteMinutes) * float64(time.Minute)),
+		start:   time.Duration(settings.Float(SettingNoShowStartMinutes) * float64(time.Minute)),
+	}
+	if windows.enRoute <= 0 && windows.start <= 0 {
+		return nil, nil
+	}
+
+	var candidates []models.CustomerServiceRequest
+	if err := s.db.Where("status IN ? AND assigned_worker_id IS NOT NULL AND accepted_at IS NOT NULL",
+		[]models.CustomerServiceRequestStatus{models.RequestStatusAccepted, models.RequestStatusEnRoute}).
+		Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	var flagged []models.CustomerServiceRequest
+	for _, candidate := range candidates {
+		if _, due := noShowStage(candidate, windows, now); !due {
+			continue
+		}
+		request, err := s.flag(candidate.ID, windows, now)
+		if err != nil {
+			log.Printf("❌ Failed to flag no-show on request %d: %v", candidate.ID, err)
+			continue
+		}
+		if request != nil {
+			flagged = append(flagged, *request)
+		}
+	}
+	return flagged, nil
+}
+
+// flag records the no-show on a locked request, rechecking it has not moved on since the sweep
+// read it. It returns nil when there was nothing left to flag.
+func (s *NoShowService) flag(requestID uint, windows noShowWindows, now time.Time) (*models.CustomerServiceRequest, error) {
+	var request models.CustomerServiceRequest
+	flagged := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, requestID).Error; err != nil {
+			return err
+		}
+		stage, due := noShowStage(request, windows, now)
+		if !due {
+			return nil
+		}
+
+		var worker models.WorkerProfile
+		if err := tx.First(&worker, *request.AssignedWorkerID).Error; err != nil {
+			return err
+		}
+		event, err := RecordReliabilityEvent(tx, worker.ID, &request.ID, models.ReliabilityNoShow, "", now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&models.WorkerNoShow{
+			ServiceRequestID: request.ID,
+			WorkerID:         worker.ID,
+			Stage:            stage,
+			AcceptedAt:       *request.AcceptedAt,
+			Penalty:          event.Points,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&worker).Update("no_show_count", gorm.Expr("no_show_count + 1")).Error; err != nil {
+			return err
+		}
+		if err := unassignRequest(tx, &request, now); err != nil {
+			return err
+		}
+
+		vars := map[string]interface{}{"title": request.Title}
+		data := map[string]interface{}{"action": "no_show", "service_request_id": request.ID}
+		if err := EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, request.ID, models.PushNotificationPayload{
+			UserID: request.CustomerID, Message: "notification.worker_no_show", Vars: vars, Type: "no_show", Data: data,
+		}); err != nil {
+			return err
+		}
+		if err := EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, request.ID, models.PushNotificationPayload{
+			UserID: worker.UserID, Message: "notification.no_show_penalty", Vars: vars, Type: "no_show", Data: data,
+		}); err != nil {
+			return err
+		}
+		flagged = true
+		return nil
+	})
+	if err != nil || !flagged {
+		return nil, err
+	}
+	log.Printf("🚫 Worker no-show on request %d, put back up for other workers", request.ID)
+	return &request, nil
+}
+
+// noShowStage returns the step the assigned worker missed, if any. Windows run from acceptance, or
+// from the scheduled time when that is later.
+func noShowStage(request models.CustomerServiceRequest, windows noShowWindows, now time.Time) (models.NoShowStage, bool) {
+	if request.AssignedWorkerID == nil || request.AcceptedAt == nil {
+		return "", false
+	}
+	from := *request.AcceptedAt
+	if request.ScheduledFor != nil && request.ScheduledFor.After(from) {
+		from = *request.ScheduledFor
+	}
+	switch request.Status {
+	case models.RequestStatusAccepted:
+		if windows.enRoute > 0 && !now.Before(from.Add(windows.enRoute)) {
+			return models.NoShowStageEnRoute, true
+		}
+		fallthrough
+	case models.RequestStatusEnRoute:
+		if windows.start > 0 && !now.Before(from.Add(windows.start)) {
+			return models.NoShowStageStart, true
+		}
+	}
+	return "", false
+}
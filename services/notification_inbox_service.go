@@ -0,0 +1,128 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// NotificationFilter narrows an in-app notification listing. Empty fields match everything.
+type NotificationFilter struct {
+	Types []string
+	Read  *bool
+}
+
+// UnreadNotificationCounts breaks a user's unread notifications down for badge rendering
+type UnreadNotificationCounts struct {
+	Total      int64                                 `json:"count"`
+	ByType     map[string]int64                      `json:"by_type"`
+	ByCategory map[models.NotificationCategory]int64 `json:"by_category"`
+}
+
+// NotificationInboxService lists, counts and deletes a user's in-app notifications
+type NotificationInboxService struct{}
+
+// NewNotificationInboxService creates a new notification inbox service
+func NewNotificationInboxService() *NotificationInboxService {
+	return &NotificationInboxService{}
+}
+
+// NotificationRetentionDays is how long in-app notifications are kept, configurable through
+// NOTIFICATION_RETENTION_DAYS
+func NotificationRetentionDays() int {
+	if days, err := strconv.Atoi(os.Getenv("NOTIFICATION_RETENTION_DAYS")); err == nil && days > 0 {
+		return days
+	}
+	return 90
+}
+
+// List returns one page of the user's notifications, newest first, with the total matching count
+func (s *NotificationInboxService) List(userID uint, filter NotificationFilter, offset, limit int) ([]models.Notification, int64, error) {
+	query := database.DB.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.Read != nil {
+		query = query.Where("read = ?", *filter.Read)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	notifications := []models.Notification{}
+	err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+// Version returns how many notifications the user has and when the latest change to them was
+// made. Creating, reading or deleting a notification changes at least one of the two, so they
+// tell whether a listing can still be current without loading it.
+func (s *NotificationInboxService) Version(userID uint) (int64, time.Time, error) {
+	var version struct {
+		Count       int64
+		LastUpdated *time.Time
+	}
+	err := database.DB.Model(&models.Notification{}).
+		Select("COUNT(*) AS count, MAX(updated_at) AS last_updated").
+		Where("user_id = ?", userID).
+		Scan(&version).Error
+	if err != nil || version.LastUpdated == nil {
+		return version.Count, time.Time{}, err
+	}
+	return version.Count, *version.LastUpdated, nil
+}
+
+// Delete removes the given notifications if they belong to the user and returns how many were removed
+func (s *NotificationInboxService) Delete(userID uint, ids []uint) (int64, error) {
+	result := database.DB.Where("user_id = ? AND id IN ?", userID, ids).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteRead removes every notification the user has already read
+func (s *NotificationInboxService) DeleteRead(userID uint) (int64, error) {
+	result := database.DB.Where("user_id = ? AND read = ?", userID, true).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
+
+// UnreadCounts returns the user's unread notifications counted in total, per type and per category
+func (s *NotificationInboxService) UnreadCounts(userID uint) (UnreadNotificationCounts, error) {
+	counts := UnreadNotificationCounts{
+		ByType:     map[string]int64{},
+		ByCategory: map[models.NotificationCategory]int64{},
+	}
+
+	var rows []struct {
+		Type  string
+		Count int64
+	}
+	err := database.DB.Model(&models.Notification{}).
+		Select("type, COUNT(*) AS count").
+		Where("user_id = ? AND read = ?", userID, false).
+		Group("type").
+		Scan(&rows).Error
+	if err != nil {
+		return counts, err
+	}
+
+	for _, row := range rows {
+		counts.Total += row.Count
+		counts.ByType[row.Type] = row.Count
+		counts.ByCategory[NotificationCategoryOf(row.Type)] += row.Count
+	}
+	return counts, nil
+}
+
+// PurgeExpired permanently deletes notifications past the retention period along with the ones
+// users already deleted
+func (s *NotificationInboxService) PurgeExpired() (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -NotificationRetentionDays())
+	result := database.DB.Unscoped().
+		Where("created_at < ? OR deleted_at IS NOT NULL", cutoff).
+		Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// NotificationDecision says whether and how a notification reaches the user
+type NotificationDecision struct {
+	Deliver bool
+	// DeferUntil is set when the notification must wait for the user's quiet hours to end
+	DeferUntil *time.Time
+	Channel    models.NotificationChannel
+	Category   models.NotificationCategory
+}
+
+// urgentNotificationTypes are delivered during quiet hours because waiting would make them useless
+var urgentNotificationTypes = map[string]bool{
+	"job_offer":           true,
+	"urgent_job":          true,
+	"booking_accepted":    true,
+	"booking_en_route":    true,
+	"booking_arrived":     true,
+	"booking_in_progress": true,
+	"booking_cancelled":   true,
+	"security":            true,
+}
+
+// NotificationPreferenceService loads user notification preferences and applies them
+type NotificationPreferenceService struct{}
+
+// NewNotificationPreferenceService creates a new notification preference service
+func NewNotificationPreferenceService() *NotificationPreferenceService {
+	return &NotificationPreferenceService{}
+}
+
+// Get returns the user's preferences, or the defaults if they never saved any
+func (s *NotificationPreferenceService) Get(userID uint) (models.NotificationPreference, error) {
+	var pref models.NotificationPreference
+	err := database.DB.Where("user_id = ?", userID).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultNotificationPreference(userID), nil
+	}
+	return pref, err
+}
+
+// Save creates or replaces the user's preferences
+func (s *NotificationPreferenceService) Save(pref *models.NotificationPreference) error {
+	if pref.ID != 0 {
+		return database.DB.Save(pref).Error
+	}
+	// A concurrent first save for the same user updates instead of failing on the unique index
+	return database.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"chat_enabled", "status_updates_enabled", "promotions_enabled", "quiet_hours_enabled",
+			"quiet_hours_start", "quiet_hours_end", "timezone", "preferred_channel", "updated_at",
+		}),
+	}).Create(pref).Error
+}
+
+// Decide applies the user's preferences to a notification of notificationType sent at now
+func (s *NotificationPreferenceService) Decide(userID uint, notificationType string, now time.Time) (NotificationDecision, error) {
+	category := NotificationCategoryOf(notificationType)
+	decision := NotificationDecision{Deliver: true, Channel: models.NotificationChannelPush, Category: category}
+	if category == models.NotificationCategorySecurity {
+		return decision, nil
+	}
+
+	pref, err := s.Get(userID)
+	if err != nil {
+		return decision, err
+	}
+
+	decision.Channel = pref.PreferredChannel
+	if !pref.CategoryEnabled(category) {
+		decision.Deliver = false
+		return decision, nil
+	}
+	if !urgentNotificationTypes[notificationType] {
+		decision.DeferUntil = pref.QuietUntil(now)
+	}
+	return decision, nil
+}
+
+// NotificationCategoryOf maps a notification type to the preference toggle that controls it
+func NotificationCategoryOf(notificationType string) models.NotificationCategory {
+	switch {
+	case notificationType == "security":
+		return models.NotificationCategorySecurity
+	case notificationType == "chat_message":
+		return models.NotificationCategoryChat
+	case notificationType == "promotion":
+		return models.NotificationCategoryPromotions
+	}
+	// Bookings, payments, job offers, feedback requests and operational "system" messages
+	return models.NotificationCategoryStatusUpdates
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// Next onboarding steps, as shown in the app's checklist
+const (
+	OnboardingStepCompleteProfile  = "complete_profile"
+	OnboardingStepUploadDocuments  = "upload_documents"
+	OnboardingStepWaitForReview    = "wait_for_review"
+	OnboardingStepCompleteTraining = "complete_training"
+)
+
+// ErrInvalidOnboardingTransition is returned when an admin moves a worker to a status that does not
+// follow from their current one
+var ErrInvalidOnboardingTransition = errors.New("invalid onboarding transition")
+
+// onboardingAdminTransitions lists the statuses admins can move a worker to from each status. The
+// first two statuses are left by the worker filling in their profile and documents.
+var onboardingAdminTransitions = map[models.WorkerOnboardingStatus][]models.WorkerOnboardingStatus{
+	models.OnboardingUnderReview:      {models.OnboardingTrainingRequired, models.OnboardingDocumentsPending},
+	models.OnboardingTrainingRequired: {models.OnboardingActive, models.OnboardingUnderReview},
+	models.OnboardingActive:           {models.OnboardingUnderReview},
+}
+
+// OnboardingStep is one entry of the onboarding checklist
+type OnboardingStep struct {
+	Status    models.WorkerOnboardingStatus `json:"status"`
+	Completed bool                          `json:"completed"`
+	Current   bool                          `json:"current"`
+}
+
+// OnboardingChecklist is where a worker stands in onboarding and what they have to do next
+type OnboardingChecklist struct {
+	Status    models.WorkerOnboardingStatus `json:"status"`
+	NextStep  string                        `json:"next_step"` // Empty once active
+	Missing   []string                      `json:"missing"`   // Fields or documents the next step needs
+	Note      string                        `json:"note"`
+	UpdatedAt *time.Time                    `json:"updated_at"`
+	Steps     []OnboardingStep              `json:"steps"`
+}
+
+// OnboardingService moves workers through onboarding
+type OnboardingService struct {
+	db *gorm.DB
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService() *OnboardingService {
+	return &OnboardingService{
+		db: database.DB,
+	}
+}
+
+// AdvanceOnboarding moves a worker who has not been submitted for review yet to the status their
+// profile and documents allow, without saving. It reports whether the status changed.
+func AdvanceOnboarding(worker *models.WorkerProfile) bool {
+	if worker.OnboardingStatus != "" && worker.OnboardingStatus != models.OnboardingProfileIncomplete && worker.OnboardingStatus != models.OnboardingDocumentsPending {
+		return false
+	}
+
+	status := models.OnboardingUnderReview
+	if len(worker.MissingProfileFields()) > 0 {
+		status = models.OnboardingProfileIncomplete
+	} else if len(worker.MissingDocuments()) > 0 {
+		status = models.OnboardingDocumentsPending
+	}
+	if status == worker.OnboardingStatus {
+		return false
+	}
+
+	now := time.Now()
+	worker.OnboardingStatus = status
+	worker.OnboardingUpdatedAt = &now
+	return true
+}
+
+// Checklist returns the worker's onboarding checklist
+func Checklist(worker models.WorkerProfile) OnboardingChecklist {
+	checklist := OnboardingChecklist{
+		Status:    worker.OnboardingStatus,
+		Missing:   []string{},
+		Note:      worker.OnboardingNote,
+		UpdatedAt: worker.OnboardingUpdatedAt,
+		Steps:     make([]OnboardingStep, 0, len(models.OnboardingSteps)),
+	}
+
+	switch worker.OnboardingStatus {
+	case models.OnboardingProfileIncomplete:
+		checklist.NextStep = OnboardingStepCompleteProfile
+		checklist.Missing = worker.MissingProfileFields()
+	case models.OnboardingDocumentsPending:
+		checklist.NextStep = OnboardingStepUploadDocuments
+		checklist.Missing = worker.MissingDocuments()
+	case models.OnboardingUnderReview:
+		checklist.NextStep = OnboardingStepWaitForReview
+	case models.OnboardingTrainingRequired:
+		checklist.NextStep = OnboardingStepCompleteTraining
+	}
+
+	current := len(models.OnboardingSteps)
+	for i, status := range models.OnboardingSteps {
+		if status == worker.OnboardingStatus {
+			current = i
+		}
+	}
+	for i, status := range models.OnboardingSteps {
+		checklist.Steps = append(checklist.Steps, OnboardingStep{
+			Status:    status,
+			Completed: i < current || (status == models.OnboardingActive && i == current),
+			Current:   i == current,
+		})
+	}
+	return checklist
+}
+
+// Transition moves a worker to status on an admin's decision, with an optional note for the
+// worker. Reaching active verifies the worker; leaving it unverifies them.
+func (s *OnboardingService) Transition(worker *models.WorkerProfile, status models.WorkerOnboardingStatus, note string) error {
+	allowed := false
+	for _, next := range onboardingAdminTransitions[worker.OnboardingStatus] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return ErrInvalidOnboardingTransition
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"onboarding_status":     status,
+		"onboarding_note":       note,
+		"onboarding_updated_at": now,
+		"is_verified":           status == models.OnboardingActive,
+	}
+	if err := s.db.Model(worker).Updates(updates).Error; err != nil {
+		return err
+	}
+	worker.OnboardingStatus = status
+	worker.OnboardingNote = note
+	worker.OnboardingUpdatedAt = &now
+	worker.IsVerified = status == models.OnboardingActive
+	return nil
+}
+
+// AllowedOnboardingTransitions lists the statuses an admin can move a worker in status to
+func AllowedOnboardingTransitions(status models.WorkerOnboardingStatus) []models.WorkerOnboardingStatus {
+	return append([]models.WorkerOnboardingStatus{}, onboardingAdminTransitions[status]...)
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// OrganizationInvoiceLine is one completed request on an organization's monthly invoice
+type OrganizationInvoiceLine struct {
+	ServiceHistoryID uint         `json:"service_history_id"`
+	ServiceRequestID uint         `json:"service_request_id"`
+	CompletedAt      time.Time    `json:"completed_at"`
+	Title            string       `json:"title"`
+	CategoryName     string       `json:"category_name"`
+	MemberID         uint         `json:"member_id"` // User who booked it
+	MemberName       string       `json:"member_name"`
+	Price            money.Amount `json:"price"` // Final price, parts included
+	Refunded         money.Amount `json:"refunded"`
+	Tips             money.Amount `json:"tips"`
+	Total            money.Amount `json:"total"` // Price less refunds, plus tips
+	PaymentStatus    string       `json:"payment_status"`
+}
+
+// OrganizationInvoiceTotals sums invoice lines
+type OrganizationInvoiceTotals struct {
+	Services int          `json:"services"`
+	Price    money.Amount `json:"price"`
+	Refunded money.Amount `json:"refunded"`
+	Tips     money.Amount `json:"tips"`
+	Total    money.Amount `json:"total"`
+}
+
+// OrganizationInvoice consolidates the requests an organization's members completed in one month
+type OrganizationInvoice struct {
+	Number         string                    `json:"number"` // ORG-<organization id>-YYYYMM
+	OrganizationID uint                      `json:"organization_id"`
+	Organization   string                    `json:"organization"`
+	BillingEmail   *string                   `json:"billing_email"`
+	TaxID          string                    `json:"tax_id"`
+	Month          string                    `json:"month"` // YYYY-MM
+	Currency       string                    `json:"currency"`
+	Lines          []OrganizationInvoiceLine `json:"lines"`
+	Totals         OrganizationInvoiceTotals `json:"totals"`
+	GeneratedAt    time.Time                 `json:"generated_at"`
+}
+
+// OrganizationMemberSpend is what one member booked for the organization over the year
+type OrganizationMemberSpend struct {
+	UserID   uint         `json:"user_id"`
+	FullName string       `json:"full_name"`
+	Services int          `json:"services"`
+	Spend    money.Amount `json:"spend"`
+}
+
+// OrganizationDashboard is an organization's spend over a year
+type OrganizationDashboard struct {
+	Year        int                       `json:"year"`
+	Services    int                       `json:"services"`
+	Spend       money.Amount              `json:"spend"` // Final prices less refunds
+	Tips        money.Amount              `json:"tips"`
+	Currency    string                    `json:"currency"`
+	PerMonth    []CustomerMonthSpend      `json:"spend_per_month"`
+	PerCategory []CustomerCategorySpend   `json:"spend_per_category"`
+	PerMember   []OrganizationMemberSpend `json:"spend_per_member"`
+	OpenCount   int64                     `json:"open_requests"` // Billed requests not completed, cancelled or expired yet
+}
+
+// OrganizationRequests scopes a query on customer_service_requests to those billed to the organization
+func OrganizationRequests(orgID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("organization_id = ?", orgID)
+	}
+}
+
+// organizationHistory scopes a query on service_history to requests billed to the organization
+func (s *OrganizationService) organizationHistory(orgID uint) func(*gorm.DB) *gorm.DB {
+	billed := s.db.Unscoped().Model(&models.CustomerServiceRequest{}).Select("id").Where("organization_id = ?", orgID)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("service_request_id IN (?)", billed)
+	}
+}
+
+// History returns the completed requests billed to the organization, newest first, optionally
+// only those booked by memberID
+func (s *OrganizationService) History(orgID, memberID uint, offset, limit int) ([]models.ServiceHistory, int64, error) {
+	query := s.db.Model(&models.ServiceHistory{}).Scopes(s.organizationHistory(orgID))
+	if memberID != 0 {
+		query = query.Where("customer_id = ?", memberID)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var history []models.ServiceHistory
+	err := query.Preload("Worker.User").Preload("Customer").Preload("Category").Preload("ServiceOption").
+		Order("completed_at DESC").Offset(offset).Limit(limit).Find(&history).Error
+	return history, total, err
+}
+
+// Invoice returns the organization's invoice for the month starting at month, with every billed
+// request completed in it at its current price, refunds and tips
+func (s *OrganizationService) Invoice(org models.Organization, month time.Time) (*OrganizationInvoice, error) {
+	start, end := MonthWindow(month.Year(), month.Month(), month.Location())
+	histories, err := s.completedBetween(org.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := &OrganizationInvoice{
+		Number:         fmt.Sprintf("ORG-%d-%s", org.ID, start.Format("200601")),
+		OrganizationID: org.ID,
+		Organization:   org.Name,
+		BillingEmail:   org.BillingEmail,
+		TaxID:          org.TaxID,
+		Month:          start.Format("2006-01"),
+		Currency:       NewRegionService().Default().Currency,
+		Lines:          make([]OrganizationInvoiceLine, 0, len(histories)),
+		GeneratedAt:    time.Now(),
+	}
+	for _, h := range histories {
+		line := OrganizationInvoiceLine{
+			ServiceHistoryID: h.ID,
+			ServiceRequestID: h.ServiceRequestID,
+			CompletedAt:      h.CompletedAt,
+			Title:            h.Title,
+			CategoryName:     h.Category.Name,
+			MemberID:         h.CustomerID,
+			MemberName:       h.Customer.FullName,
+			Refunded:         h.RefundedAmount,
+			Tips:             h.TipAmount,
+			PaymentStatus:    h.PaymentStatus,
+		}
+		if h.FinalPrice != nil {
+			line.Price = *h.FinalPrice
+		}
+		line.Total = line.Price - line.Refunded + line.Tips
+		invoice.Lines = append(invoice.Lines, line)
+
+		invoice.Totals.Services++
+		invoice.Totals.Price += line.Price
+		invoice.Totals.Refunded += line.Refunded
+		invoice.Totals.Tips += line.Tips
+		invoice.Totals.Total += line.Total
+	}
+	return invoice, nil
+}
+
+// Analytics returns the organization's spend per month, category and member for year
+func (s *OrganizationService) Analytics(orgID uint, year int) (*OrganizationDashboard, error) {
+	start, end := YearWindow(year, time.Local)
+	histories, err := s.completedBetween(orgID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := &OrganizationDashboard{
+		Year:        year,
+		Currency:    NewRegionService().Default().Currency,
+		PerMonth:    make([]CustomerMonthSpend, 12),
+		PerCategory: []CustomerCategorySpend{},
+		PerMember:   []OrganizationMemberSpend{},
+	}
+	for i := range dashboard.PerMonth {
+		dashboard.PerMonth[i].Month = fmt.Sprintf("%04d-%02d", year, i+1)
+	}
+	categories := map[uint]*CustomerCategorySpend{}
+	members := map[uint]*OrganizationMemberSpend{}
+	for _, h := range histories {
+		spend := -h.RefundedAmount
+		if h.FinalPrice != nil {
+			spend += *h.FinalPrice
+		}
+		dashboard.Services++
+		dashboard.Spend += spend
+		dashboard.Tips += h.TipAmount
+
+		month := &dashboard.PerMonth[h.CompletedAt.In(time.Local).Month()-1]
+		month.Services++
+		month.Spend += spend
+		month.Tips += h.TipAmount
+
+		if categories[h.CategoryID] == nil {
+			categories[h.CategoryID] = &CustomerCategorySpend{CategoryID: h.CategoryID, CategoryName: h.Category.Name}
+		}
+		categories[h.CategoryID].Services++
+		categories[h.CategoryID].Spend += spend
+
+		if members[h.CustomerID] == nil {
+			members[h.CustomerID] = &OrganizationMemberSpend{UserID: h.CustomerID, FullName: h.Customer.FullName}
+		}
+		members[h.CustomerID].Services++
+		members[h.CustomerID].Spend += spend
+	}
+	for _, category := range categories {
+		dashboard.PerCategory = append(dashboard.PerCategory, *category)
+	}
+	sort.Slice(dashboard.PerCategory, func(i, j int) bool {
+		return dashboard.PerCategory[i].Spend > dashboard.PerCategory[j].Spend
+	})
+	for _, member := range members {
+		dashboard.PerMember = append(dashboard.PerMember, *member)
+	}
+	sort.Slice(dashboard.PerMember, func(i, j int) bool {
+		return dashboard.PerMember[i].Spend > dashboard.PerMember[j].Spend
+	})
+
+	if err := s.db.Model(&models.CustomerServiceRequest{}).Scopes(OrganizationRequests(orgID)).
+		Where("status NOT IN ?", []models.CustomerServiceRequestStatus{models.RequestStatusCompleted, models.RequestStatusCancelled, models.RequestStatusExpired}).
+		Count(&dashboard.OpenCount).Error; err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+// completedBetween loads the billed requests completed in [from, to), oldest first
+func (s *OrganizationService) completedBetween(orgID uint, from, to time.Time) ([]models.ServiceHistory, error) {
+	var histories []models.ServiceHistory
+	err := s.db.Scopes(s.organizationHistory(orgID)).
+		Preload("Customer").Preload("Category").
+		Where("completed_at >= ? AND completed_at < ?", from, to).
+		Order("completed_at ASC").
+		Find(&histories).Error
+	return histories, err
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/validation"
+)
+
+var (
+	// ErrNotOrganizationMember is returned when the user is not an active member of the organization
+	ErrNotOrganizationMember = errors.New("not a member of this organization")
+	// ErrOrganizationOwnerRequired is returned when a member tries what only owners may do
+	ErrOrganizationOwnerRequired = errors.New("only organization owners can do this")
+	// ErrLastOrganizationOwner is returned when a change would leave the organization without an owner
+	ErrLastOrganizationOwner = errors.New("an organization needs at least one owner")
+	// ErrAlreadyOrganizationMember is returned when inviting a user who is already a member or invited
+	ErrAlreadyOrganizationMember = errors.New("user is already a member or invited")
+	// ErrInviteeNotFound is returned when no active user has the invited phone number or verified email
+	ErrInviteeNotFound = errors.New("no user with this phone number or email")
+	// ErrNoOrganizationInvitation is returned when accepting or declining an invitation that does not exist
+	ErrNoOrganizationInvitation = errors.New("no pending invitation to this organization")
+)
+
+// OrganizationService manages organizations, their members and invitations
+type OrganizationService struct {
+	db *gorm.DB
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService() *OrganizationService {
+	return &OrganizationService{
+		db: database.DB,
+	}
+}
+
+// Create creates an organization owned by the user who created it
+func (s *OrganizationService) Create(userID uint, req models.OrganizationRequest) (*models.Organization, error) {
+	now := time.Now()
+	org := &models.Organization{
+		Name:         req.Name,
+		BillingEmail: req.BillingEmail,
+		TaxID:        req.TaxID,
+		CreatedByID:  userID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(org).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: org.ID,
+			UserID:         userID,
+			Role:           models.OrganizationRoleOwner,
+			Status:         models.OrganizationMemberActive,
+			JoinedAt:       &now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// Update changes the organization's name and billing details
+func (s *OrganizationService) Update(org *models.Organization, req models.OrganizationRequest) error {
+	org.Name = req.Name
+	org.BillingEmail = req.BillingEmail
+	org.TaxID = req.TaxID
+	return s.db.Save(org).Error
+}
+
+// Find returns the organization if the user is an active member of it, with their membership
+func (s *OrganizationService) Find(orgID, userID uint) (*models.Organization, *models.OrganizationMember, error) {
+	member, err := s.Membership(orgID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var org models.Organization
+	if err := s.db.First(&org, orgID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &org, member, nil
+}
+
+// FindOwned is Find for what only owners may do
+func (s *OrganizationService) FindOwned(orgID, userID uint) (*models.Organization, error) {
+	org, member, err := s.Find(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !member.IsOwner() {
+		return nil, ErrOrganizationOwnerRequired
+	}
+	return org, nil
+}
+
+// Membership returns the user's active membership of the organization
+func (s *OrganizationService) Membership(orgID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := s.db.Where("organization_id = ? AND user_id = ? AND status = ?", orgID, userID, models.OrganizationMemberActive).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotOrganizationMember
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ForUser returns the user's memberships and pending invitations with their organizations
+func (s *OrganizationService) ForUser(userID uint) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := s.db.Joins("Organization").Where("organization_members.user_id = ?", userID).
+		Order("organization_members.created_at ASC").Find(&members).Error
+	return members, err
+}
+
+// OwnedIDs returns the organizations the user owns
+func (s *OrganizationService) OwnedIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := s.db.Model(&models.OrganizationMember{}).
+		Where("user_id = ? AND role = ? AND status = ?", userID, models.OrganizationRoleOwner, models.OrganizationMemberActive).
+		Pluck("organization_id", &ids).Error
+	return ids, err
+}
+
+// Members returns the organization's members and pending invitations, owners first
+func (s *OrganizationService) Members(orgID uint) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := s.db.Preload("User").Where("organization_id = ?", orgID).
+		Order("role DESC, created_at ASC").Find(&members).Error
+	return members, err
+}
+
+// Invite invites an existing user, found by phone number or verified email, and notifies them.
+// They become a member once they accept.
+func (s *OrganizationService) Invite(org *models.Organization, inviterID uint, req models.OrganizationInviteRequest) (*models.OrganizationMember, error) {
+	var user models.User
+	query := s.db.Where("is_active = ?", true)
+	if req.PhoneNumber != "" {
+		query = query.Where("phone_number = ?", validation.NormalizePhone(req.PhoneNumber))
+	} else {
+		query = query.Where("email = ? AND email_verified_at IS NOT NULL", NormalizeEmail(req.Email))
+	}
+	if err := query.First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInviteeNotFound
+		}
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	member := &models.OrganizationMember{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		User:           user,
+		Role:           role,
+		Status:         models.OrganizationMemberInvited,
+		InvitedByID:    &inviterID,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.OrganizationMember{}).
+			Where("organization_id = ? AND user_id = ?", org.ID, user.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAlreadyOrganizationMember
+		}
+		if err := tx.Omit("User").Create(member).Error; err != nil {
+			return err
+		}
+		return EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, org.ID, models.PushNotificationPayload{
+			UserID:  user.ID,
+			Message: "notification.organization_invite",
+			Vars:    map[string]interface{}{"organization": org.Name},
+			Type:    "organization_invite",
+			Data: map[string]interface{}{
+				"action":          "organization_invite",
+				"organization_id": org.ID,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// Accept makes the user an active member of an organization that invited them
+func (s *OrganizationService) Accept(orgID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := s.db.Where("organization_id = ? AND user_id = ? AND status = ?", orgID, userID, models.OrganizationMemberInvited).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoOrganizationInvitation
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	member.Status = models.OrganizationMemberActive
+	member.JoinedAt = &now
+	if err := s.db.Model(&member).Updates(map[string]interface{}{"status": member.Status, "joined_at": now}).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// Decline removes the user's pending invitation to the organization
+func (s *OrganizationService) Decline(orgID, userID uint) error {
+	result := s.db.Where("organization_id = ? AND user_id = ? AND status = ?", orgID, userID, models.OrganizationMemberInvited).
+		Delete(&models.OrganizationMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNoOrganizationInvitation
+	}
+	return nil
+}
+
+// SetRole changes a member's role. The last owner cannot be made a member.
+func (s *OrganizationService) SetRole(orgID, userID uint, role models.OrganizationRole) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("User").Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+			return err
+		}
+		if member.IsOwner() && role != models.OrganizationRoleOwner {
+			if err := s.checkOtherOwner(tx, orgID, userID); err != nil {
+				return err
+			}
+		}
+		member.Role = role
+		return tx.Model(&member).Update("role", role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveMember removes a member or cancels an invitation. Requests they already billed to the
+// organization stay on it. The last owner cannot be removed.
+func (s *OrganizationService) RemoveMember(orgID, userID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var member models.OrganizationMember
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).First(&member).Error; err != nil {
+			return err
+		}
+		if member.IsOwner() {
+			if err := s.checkOtherOwner(tx, orgID, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&member).Error
+	})
+}
+
+// checkOtherOwner returns ErrLastOrganizationOwner unless someone other than userID owns the organization
+func (s *OrganizationService) checkOtherOwner(tx *gorm.DB, orgID, userID uint) error {
+	var owners int64
+	if err := tx.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id <> ? AND role = ? AND status = ?", orgID, userID, models.OrganizationRoleOwner, models.OrganizationMemberActive).
+		Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastOrganizationOwner
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/config"
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// OTP limits
+const (
+	OTPCodeLength  = 6
+	OTPTTL         = 10 * time.Minute
+	OTPResendAfter = time.Minute // Minimum gap between two codes to the same number
+	OTPHourlySends = 5           // Codes per number per hour
+	OTPMaxAttempts = 5           // Wrong guesses before a code is burned
+)
+
+var (
+	// ErrOTPInvalid is returned for a wrong, expired or already used code
+	ErrOTPInvalid = errors.New("verification code is invalid or expired")
+	// ErrOTPTooManyAttempts is returned once a code has been guessed wrong too often
+	ErrOTPTooManyAttempts = errors.New("too many incorrect attempts, request a new code")
+)
+
+// OTPCooldownError is returned when a code is requested before the number may receive another
+type OTPCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OTPCooldownError) Error() string {
+	return fmt.Sprintf("a verification code was sent recently, retry in %s", e.RetryAfter.Round(time.Second))
+}
+
+// OTPService sends and verifies SMS one-time codes
+type OTPService struct {
+	sms *SMSService
+}
+
+// NewOTPService creates an OTP service using the configured SMS provider
+func NewOTPService() *OTPService {
+	return &OTPService{sms: NewSMSService()}
+}
+
+// SendCode generates a code for phone and purpose, stores its hash and sends it by SMS.
+// phone must already be normalized to E.164.
+func (s *OTPService) SendCode(phone string, purpose models.PhoneVerificationPurpose, userID *uint) error {
+	now := time.Now()
+
+	var recent []models.PhoneVerification
+	if err := database.DB.Where("phone_number = ? AND created_at > ?", phone, now.Add(-time.Hour)).
+		Order("created_at DESC").Find(&recent).Error; err != nil {
+		return err
+	}
+	if len(recent) > 0 {
+		if wait := recent[0].CreatedAt.Add(OTPResendAfter).Sub(now); wait > 0 {
+			return &OTPCooldownError{RetryAfter: wait}
+		}
+	}
+	if len(recent) >= OTPHourlySends {
+		return &OTPCooldownError{RetryAfter: recent[OTPHourlySends-1].CreatedAt.Add(time.Hour).Sub(now)}
+	}
+
+	code, err := generateOTPCode()
+	if err != nil {
+		return err
+	}
+
+	verification := models.PhoneVerification{
+		UserID:      userID,
+		PhoneNumber: phone,
+		Purpose:     purpose,
+		CodeHash:    hashOTPCode(phone, code),
+		ExpiresAt:   now.Add(OTPTTL),
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the newest code for a number and purpose is usable
+		if err := tx.Model(&models.PhoneVerification{}).
+			Where("phone_number = ? AND purpose = ? AND verified_at IS NULL AND expires_at > ?", phone, purpose, now).
+			Update("expires_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&verification).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(OTPTTL.Minutes()))
+	if err := s.sms.Send(userID, phone, body, models.SMSPurposeOTP, ""); err != nil {
+		log.Printf("❌ Failed to send verification code to %s via %s: %v", phone, s.sms.Name(), err)
+		return err
+	}
+
+	log.Printf("📱 Verification code for %s (%s) sent via %s", phone, purpose, s.sms.Name())
+	return nil
+}
+
+// VerifyCode checks code against the latest pending code for phone and purpose and marks it
+// used on success
+func (s *OTPService) VerifyCode(phone string, purpose models.PhoneVerificationPurpose, code string) error {
+	var verification models.PhoneVerification
+	err := database.DB.Where("phone_number = ? AND purpose = ? AND verified_at IS NULL AND expires_at > ?", phone, purpose, time.Now()).
+		Order("created_at DESC").First(&verification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOTPInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	if verification.Attempts >= OTPMaxAttempts {
+		return ErrOTPTooManyAttempts
+	}
+
+	if !hmac.Equal([]byte(verification.CodeHash), []byte(hashOTPCode(phone, code))) {
+		database.DB.Model(&verification).Update("attempts", gorm.Expr("attempts + 1"))
+		if verification.Attempts+1 >= OTPMaxAttempts {
+			return ErrOTPTooManyAttempts
+		}
+		return ErrOTPInvalid
+	}
+
+	now := time.Now()
+	result := database.DB.Model(&models.PhoneVerification{}).
+		Where("id = ? AND verified_at IS NULL", verification.ID).
+		Update("verified_at", &now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		// A concurrent request used the code first
+		return ErrOTPInvalid
+	}
+	return nil
+}
+
+// generateOTPCode returns a uniformly random numeric code
+func generateOTPCode() (string, error) {
+	return generateNumericCode(OTPCodeLength)
+}
+
+// generateNumericCode returns a uniformly random code of length digits
+func generateNumericCode(length int) (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < length; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
+// hashOTPCode keys the hash with the JWT secret so stored hashes cannot be brute-forced offline
+func hashOTPCode(phone, code string) string {
+	mac := hmac.New(sha256.New, []byte(config.AppConfig.JWT.Secret))
+	mac.Write([]byte(phone + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+const (
+	// outboxBatchSize is the number of events claimed per processing pass
+	outboxBatchSize = 50
+	// outboxMaxAttempts is how many deliveries are tried before an event is marked failed
+	outboxMaxAttempts = 8
+)
+
+// OutboxHandler delivers a single outbox event. Delivery is at-least-once, so handlers must
+// tolerate being called again for an event that already succeeded.
+type OutboxHandler func(event models.OutboxEvent) error
+
+// OutboxService records side effects transactionally and delivers them afterwards
+type OutboxService struct {
+	db *gorm.DB
+}
+
+// NewOutboxService creates a new outbox service
+func NewOutboxService() *OutboxService {
+	return &OutboxService{db: database.DB}
+}
+
+// EnqueueOutboxEvent records an event on tx; it is only delivered if tx commits
+func EnqueueOutboxEvent(tx *gorm.DB, eventType string, aggregateID uint, payload interface{}) error {
+	return EnqueueOutboxEventAt(tx, eventType, aggregateID, payload, time.Now())
+}
+
+// EnqueueOutboxEventAt is EnqueueOutboxEvent for an event that must not be delivered before availableAt
+func EnqueueOutboxEventAt(tx *gorm.DB, eventType string, aggregateID uint, payload interface{}, availableAt time.Time) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s payload: %w", eventType, err)
+	}
+	return tx.Create(&models.OutboxEvent{
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     string(data),
+		Status:      models.OutboxStatusPending,
+		AvailableAt: availableAt,
+	}).Error
+}
+
+// ProcessPending delivers due events with the matching handlers and returns how many succeeded.
+// Rows are claimed with SKIP LOCKED so several server instances can share the outbox.
+func (s *OutboxService) ProcessPending(handlers map[string]OutboxHandler) (int, error) {
+	delivered := 0
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var events []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND available_at <= ?", models.OutboxStatusPending, time.Now()).
+			Order("id ASC").
+			Limit(outboxBatchSize).
+			Find(&events).Error; err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			handler, ok := handlers[event.EventType]
+			var deliverErr error
+			if !ok {
+				deliverErr = fmt.Errorf("no handler for event type %s", event.EventType)
+			} else {
+				deliverErr = handler(event)
+			}
+
+			if err := tx.Model(&models.OutboxEvent{}).Where("id = ?", event.ID).
+				Updates(outboxResult(event, deliverErr, ok)).Error; err != nil {
+				return err
+			}
+			if deliverErr == nil {
+				delivered++
+			} else {
+				log.Printf("⚠️ Outbox event %d (%s) failed on attempt %d: %v", event.ID, event.EventType, event.Attempts+1, deliverErr)
+			}
+		}
+		return nil
+	})
+	return delivered, err
+}
+
+// outboxResult returns the column updates recording one delivery attempt
+func outboxResult(event models.OutboxEvent, deliverErr error, retryable bool) map[string]interface{} {
+	now := time.Now()
+	attempts := event.Attempts + 1
+	if deliverErr == nil {
+		return map[string]interface{}{
+			"status":       models.OutboxStatusProcessed,
+			"attempts":     attempts,
+			"last_error":   "",
+			"processed_at": &now,
+		}
+	}
+
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": deliverErr.Error(),
+	}
+	if !retryable || attempts >= outboxMaxAttempts {
+		updates["status"] = models.OutboxStatusFailed
+		return updates
+	}
+	// Exponential backoff: 30s, 1m, 2m, 4m ...
+	updates["available_at"] = now.Add(time.Duration(1<<(attempts-1)) * 30 * time.Second)
+	return updates
+}
+
+// PurgeProcessed deletes delivered events older than the given age
+func (s *OutboxService) PurgeProcessed(olderThan time.Duration) (int64, error) {
+	result := s.db.Where("status = ? AND processed_at < ?", models.OutboxStatusProcessed, time.Now().Add(-olderThan)).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/utils"
+)
+
+// ErrUnknownServiceOption is returned when a request's service option does not exist, is inactive
+// or belongs to another category
+var ErrUnknownServiceOption = errors.New("unknown service option")
+
+// PricingService computes what a request for a service option costs once the option's weekend,
+// urgent and distance modifiers apply
+type PricingService struct {
+	db *gorm.DB
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService() *PricingService {
+	return &PricingService{
+		db: database.DB,
+	}
+}
+
+// QuoteRequest prices a request from its service option, priority, time, location, service zone
+// and the customer's loyalty tier. Requests not yet placed in a zone are priced in the zone
+// containing their location, if any. Requests without a service option have no breakdown and
+// return nil.
+func (s *PricingService) QuoteRequest(r models.CustomerServiceRequest) (*models.PriceBreakdown, error) {
+	if r.ServiceOptionID == nil {
+		return nil, nil
+	}
+	var option models.ServiceOption
+	if err := s.db.Where("id = ? AND is_active = ?", *r.ServiceOptionID, true).First(&option).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnknownServiceOption
+		}
+		return nil, err
+	}
+	if r.CategoryID != 0 && option.CategoryID != r.CategoryID {
+		return nil, ErrUnknownServiceOption
+	}
+
+	at := time.Now()
+	if r.ScheduledFor != nil {
+		at = *r.ScheduledFor
+	}
+	var lat, lng float64
+	if r.LocationLat != nil && r.LocationLng != nil {
+		lat, lng = *r.LocationLat, *r.LocationLng
+	}
+	zone, err := s.requestZone(r, lat, lng)
+	if err != nil {
+		return nil, err
+	}
+	var discountPercent float64
+	if r.CustomerID != 0 {
+		discountPercent = NewLoyaltyService().DiscountPercent(r.CustomerID)
+	}
+	breakdown := Quote(option, NewRegionService().ForRequest(r), zone, r.Priority, at, lat, lng, discountPercent)
+	return &breakdown, nil
+}
+
+// requestZone returns the request's service zone, looking it up from the location when the request
+// has none yet. Locations outside every zone, or in a zone without the category, have no zone.
+func (s *PricingService) requestZone(r models.CustomerServiceRequest, lat, lng float64) (*models.ServiceZone, error) {
+	if r.ServiceZoneID != nil {
+		var zone models.ServiceZone
+		if err := s.db.First(&zone, *r.ServiceZoneID).Error; err != nil {
+			return nil, err
+		}
+		return &zone, nil
+	}
+	if r.LocationLat == nil || r.LocationLng == nil {
+		return nil, nil
+	}
+	zone, err := NewZoneService().FindZoneForRequest(lat, lng, r.CategoryID)
+	if errors.Is(err, ErrNoActiveZone) || errors.Is(err, ErrCategoryNotInZone) {
+		return nil, nil
+	}
+	return zone, err
+}
+
+// Quote applies option's modifiers to its price for a request of priority at the given time and
+// place, in the region's currency. The weekend is Saturday and Sunday in the region's time zone. The
+// zone's surge multiplier is charged as a surcharge on the base price. The distance fee is charged
+// per km beyond the option's free distance, from the center of the zone, or of the service area
+// when the request is in no zone. The loyalty discount is taken off the base price only.
+func Quote(option models.ServiceOption, region models.Region, zone *models.ServiceZone, priority string, at time.Time, lat, lng, discountPercent float64) models.PriceBreakdown {
+	breakdown := models.PriceBreakdown{
+		ServiceOptionID: option.ID,
+		BasePrice:       option.Price,
+		Modifiers:       []models.PriceModifier{},
+		Currency:        region.Currency,
+		ComputedAt:      time.Now(),
+	}
+
+	if weekday := at.In(region.Location()).Weekday(); option.WeekendSurchargePercent > 0 && (weekday == time.Saturday || weekday == time.Sunday) {
+		breakdown.Modifiers = append(breakdown.Modifiers, models.PriceModifier{
+			Type:    models.PriceModifierWeekend,
+			Percent: option.WeekendSurchargePercent,
+			Amount:  option.Price.Percent(option.WeekendSurchargePercent),
+		})
+	}
+	if option.UrgentSurchargePercent > 0 && priority == "urgent" {
+		breakdown.Modifiers = append(breakdown.Modifiers, models.PriceModifier{
+			Type:    models.PriceModifierUrgent,
+			Percent: option.UrgentSurchargePercent,
+			Amount:  option.Price.Percent(option.UrgentSurchargePercent),
+		})
+	}
+	if zone != nil && zone.SurgeMultiplier > 1 {
+		percent := math.Round((zone.SurgeMultiplier-1)*10000) / 100
+		breakdown.Modifiers = append(breakdown.Modifiers, models.PriceModifier{
+			Type:    models.PriceModifierZoneSurge,
+			Percent: percent,
+			Amount:  option.Price.Percent(percent),
+		})
+	}
+	if option.DistanceFeePerKm > 0 {
+		centerLat, centerLng, ok := distanceCenter(zone, lat, lng)
+		if ok {
+			billed := math.Round((utils.HaversineDistance(lat, lng, centerLat, centerLng)-option.FreeDistanceKm)*10) / 10
+			if billed > 0 {
+				breakdown.Modifiers = append(breakdown.Modifiers, models.PriceModifier{
+					Type:       models.PriceModifierDistance,
+					DistanceKm: billed,
+					Amount:     option.DistanceFeePerKm.Mul(billed),
+				})
+			}
+		}
+	}
+	if discountPercent > 0 {
+		breakdown.Modifiers = append(breakdown.Modifiers, models.PriceModifier{
+			Type:    models.PriceModifierLoyalty,
+			Percent: discountPercent,
+			Amount:  -option.Price.Percent(discountPercent),
+		})
+	}
+
+	breakdown.Total = option.Price
+	for _, modifier := range breakdown.Modifiers {
+		breakdown.Total += modifier.Amount
+	}
+	return breakdown
+}
+
+// distanceCenter is the point the distance fee is measured from: the zone's center, or the center
+// of the service area around lat, lng when there is no zone
+func distanceCenter(zone *models.ServiceZone, lat, lng float64) (float64, float64, bool) {
+	if zone != nil && len(zone.Polygon) > 0 {
+		centerLat, centerLng := ZoneCenter(zone)
+		return centerLat, centerLng, true
+	}
+	if area, ok := FindServiceArea(lat, lng); ok {
+		return area.Lat, area.Lng, true
+	}
+	return 0, 0, false
+}
+
+// roundPrice rounds an amount to the cent
+func roundPrice(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
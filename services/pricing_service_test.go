@@ -0,0 +1,70 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/utils"
+)
+
+func TestQuoteZone(t *testing.T) {
+	option := models.ServiceOption{
+		Price:            money.FromFloat(1000),
+		DistanceFeePerKm: money.FromFloat(100),
+		FreeDistanceKm:   2,
+	}
+	region := models.Region{Currency: "MRU", Timezone: "Africa/Nouakchott"}
+	wednesday := time.Date(2026, time.October, 14, 10, 0, 0, 0, time.UTC)
+
+	// A zone north of the Nouakchott service area center
+	zoneLat, zoneLng := 18.18, -15.9653
+	zone := func(surge float64) *models.ServiceZone {
+		return &models.ServiceZone{
+			SurgeMultiplier: surge,
+			Polygon: []models.ZonePoint{
+				{Lat: zoneLat - 0.05, Lng: zoneLng - 0.05},
+				{Lat: zoneLat - 0.05, Lng: zoneLng + 0.05},
+				{Lat: zoneLat + 0.05, Lng: zoneLng + 0.05},
+				{Lat: zoneLat + 0.05, Lng: zoneLng - 0.05},
+			},
+		}
+	}
+	areaLat, areaLng := 18.0799, -15.9653
+	fromZone := math.Round((utils.HaversineDistance(areaLat, areaLng, zoneLat, zoneLng)-option.FreeDistanceKm)*10) / 10
+
+	tests := []struct {
+		name      string
+		zone      *models.ServiceZone
+		lat, lng  float64
+		modifiers []models.PriceModifier
+	}{
+		{"surge zone", zone(1.5), zoneLat, zoneLng, []models.PriceModifier{
+			{Type: models.PriceModifierZoneSurge, Percent: 50, Amount: money.FromFloat(500)},
+		}},
+		{"zone without surge", zone(1), zoneLat, zoneLng, nil},
+		{"no zone", nil, areaLat, areaLng, nil},
+		{"distance from the zone center", zone(1), areaLat, areaLng, []models.PriceModifier{
+			{Type: models.PriceModifierDistance, DistanceKm: fromZone, Amount: option.DistanceFeePerKm.Mul(fromZone)},
+		}},
+	}
+	for _, tt := range tests {
+		breakdown := Quote(option, region, tt.zone, "medium", wednesday, tt.lat, tt.lng, 0)
+		if len(breakdown.Modifiers) != len(tt.modifiers) {
+			t.Errorf("%s: modifiers = %+v, want %+v", tt.name, breakdown.Modifiers, tt.modifiers)
+			continue
+		}
+		want := option.Price
+		for i, modifier := range tt.modifiers {
+			if breakdown.Modifiers[i] != modifier {
+				t.Errorf("%s: modifier %d = %+v, want %+v", tt.name, i, breakdown.Modifiers[i], modifier)
+			}
+			want += modifier.Amount
+		}
+		if breakdown.Total != want {
+			t.Errorf("%s: total = %s, want %s", tt.name, breakdown.Total, want)
+		}
+	}
+}
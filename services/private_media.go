@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"github.com/gabriel-vasile/mimetype"
+	"gorm.io/gorm"
+
+	"repair-service-server/models"
+)
+
+const (
+	// SignedMediaURLTTL is how long a signed URL to a private upload works
+	SignedMediaURLTTL = 5 * time.Minute
+	// privateDeliveryType makes Cloudinary refuse unsigned requests for an asset
+	privateDeliveryType = "private"
+)
+
+// cloudinaryVersion is the version segment Cloudinary puts before the public ID in delivery URLs
+var cloudinaryVersion = regexp.MustCompile(`^v\d+/`)
+
+// CanView reports whether user may see a private upload: its owner, admins, and the other party of
+// the job it belongs to. ID cards are shown to the customer of an active job with the worker;
+// receipts to the customer of the request they were added to.
+func (s *MediaService) CanView(user models.User, upload models.MediaUpload) (bool, error) {
+	if !upload.Private || upload.OwnerID == user.ID || user.IsAdmin() {
+		return true, nil
+	}
+
+	var count int64
+	switch upload.Purpose {
+	case models.MediaPurposeIDCard:
+		err := s.db.Model(&models.CustomerServiceRequest{}).
+			Joins("JOIN worker_profiles ON worker_profiles.id = customer_service_requests.assigned_worker_id").
+			Where("worker_profiles.user_id = ? AND customer_service_requests.customer_id = ? AND customer_service_requests.status IN ?",
+				upload.OwnerID, user.ID, models.ActiveRequestStatuses).
+			Count(&count).Error
+		if err != nil {
+			return false, err
+		}
+	case models.MediaPurposeReceiptPhoto:
+		err := s.db.Model(&models.RequestLineItem{}).
+			Joins("JOIN customer_service_requests ON customer_service_requests.id = request_line_items.service_request_id").
+			Where("request_line_items.receipt_media_id = ? AND customer_service_requests.customer_id = ?", upload.ID, user.ID).
+			Count(&count).Error
+		if err != nil {
+			return false, err
+		}
+	}
+	return count > 0, nil
+}
+
+// SignedURL returns a URL to a private upload that stops working after SignedMediaURLTTL. Public
+// uploads get their stored URL.
+func (s *MediaService) SignedURL(upload models.MediaUpload) (string, time.Time, error) {
+	if !upload.Private {
+		return upload.URL, time.Time{}, nil
+	}
+	cld, err := NewCloudinaryClient()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	format := ""
+	if detected := mimetype.Lookup(upload.MIMEType); detected != nil {
+		format = strings.TrimPrefix(detected.Extension(), ".")
+	}
+	expiresAt := time.Now().Add(SignedMediaURLTTL)
+	signed, err := cld.Upload.PrivateDownloadURL(uploader.PrivateDownloadURLParams{
+		PublicID:     upload.PublicID,
+		Format:       format,
+		DeliveryType: privateDeliveryType,
+		ExpiresAt:    &expiresAt,
+		ResourceType: api.AssetType(upload.Resource),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// MigratePrivateDocuments moves ID card photos and receipts uploaded as public files to private
+// storage, pointing their records at the private upload instead of the public URL. Files that
+// fail are logged and left for the next run. It returns how many files were moved.
+func (s *MediaService) MigratePrivateDocuments() (int, error) {
+	moved := 0
+
+	var workers []models.WorkerProfile
+	if err := s.db.Where("(id_card_photo IS NOT NULL AND id_card_photo <> '' AND id_card_media_id IS NULL) OR " +
+		"(id_card_back_photo IS NOT NULL AND id_card_back_photo <> '' AND id_card_back_media_id IS NULL)").
+		Find(&workers).Error; err != nil {
+		return moved, err
+	}
+	for _, worker := range workers {
+		sides := []struct {
+			url     *string
+			mediaID *uint
+			column  string
+		}{
+			{worker.IDCardPhoto, worker.IDCardMediaID, "id_card"},
+			{worker.IDCardBackPhoto, worker.IDCardBackMediaID, "id_card_back"},
+		}
+		for _, side := range sides {
+			if side.url == nil || *side.url == "" || side.mediaID != nil {
+				continue
+			}
+			upload, err := s.makePrivate(worker.UserID, models.MediaPurposeIDCard, *side.url)
+			if err != nil {
+				log.Printf("⚠️ Failed to make ID card of worker %d private: %v", worker.ID, err)
+				continue
+			}
+			if err := s.db.Model(&worker).Updates(map[string]interface{}{
+				side.column + "_media_id": upload.ID,
+				side.column + "_photo":    nil,
+			}).Error; err != nil {
+				return moved, err
+			}
+			moved++
+		}
+	}
+
+	var items []models.RequestLineItem
+	if err := s.db.Where("receipt_photo_url <> '' AND receipt_media_id IS NULL").Find(&items).Error; err != nil {
+		return moved, err
+	}
+	for _, item := range items {
+		var worker models.WorkerProfile
+		if err := s.db.First(&worker, item.WorkerID).Error; err != nil {
+			log.Printf("⚠️ Failed to find the worker of line item %d: %v", item.ID, err)
+			continue
+		}
+		upload, err := s.makePrivate(worker.UserID, models.MediaPurposeReceiptPhoto, item.ReceiptPhotoURL)
+		if err != nil {
+			log.Printf("⚠️ Failed to make receipt of line item %d private: %v", item.ID, err)
+			continue
+		}
+		if err := s.db.Model(&item).Updates(map[string]interface{}{
+			"receipt_media_id":  upload.ID,
+			"receipt_photo_url": "",
+		}).Error; err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// makePrivate switches the public Cloudinary image at url to private delivery and returns its
+// upload record, creating one for files uploaded before uploads were recorded
+func (s *MediaService) makePrivate(ownerID uint, purpose models.MediaPurpose, url string) (*models.MediaUpload, error) {
+	publicID, format, err := cloudinaryImagePublicID(url)
+	if err != nil {
+		return nil, err
+	}
+	cld, err := NewCloudinaryClient()
+	if err != nil {
+		return nil, err
+	}
+	renamed, err := cld.Upload.Rename(context.Background(), uploader.RenameParams{
+		FromPublicID: publicID,
+		ToPublicID:   publicID,
+		ToType:       privateDeliveryType,
+		ResourceType: "image",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if renamed.Error != nil {
+		return nil, fmt.Errorf("cloudinary rename: %v", renamed.Error)
+	}
+
+	var upload models.MediaUpload
+	err = s.db.Where("public_id = ? AND deleted_at IS NULL", publicID).First(&upload).Error
+	switch {
+	case err == nil:
+		upload.Private = true
+		upload.URL = renamed.SecureURL
+		return &upload, s.db.Model(&upload).Updates(map[string]interface{}{"private": true, "url": renamed.SecureURL}).Error
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, err
+	}
+
+	upload = models.MediaUpload{
+		OwnerID:    ownerID,
+		Purpose:    purpose,
+		MIMEType:   mime.TypeByExtension("." + format),
+		Size:       int64(renamed.Bytes),
+		URL:        renamed.SecureURL,
+		PublicID:   publicID,
+		Resource:   "image",
+		ScanStatus: models.MediaScanSkipped,
+		Private:    true,
+	}
+	return &upload, s.db.Create(&upload).Error
+}
+
+// cloudinaryImagePublicID extracts the public ID and format from a Cloudinary image delivery URL
+// such as https://res.cloudinary.com/<cloud>/image/upload/v123/workers/id_cards/7/front/abc.jpg
+func cloudinaryImagePublicID(url string) (string, string, error) {
+	_, rest, ok := strings.Cut(url, "/image/upload/")
+	if !ok {
+		return "", "", fmt.Errorf("not a public Cloudinary image URL: %s", url)
+	}
+	rest = cloudinaryVersion.ReplaceAllString(rest, "")
+	format := path.Ext(rest)
+	return strings.TrimSuffix(rest, format), strings.TrimPrefix(format, "."), nil
+}
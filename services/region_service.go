@@ -0,0 +1,194 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/cache"
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+)
+
+// regionCacheKey caches the active regions, which every priced or scheduled request looks up
+const regionCacheKey = "regions:active"
+
+// regionCacheTTL bounds how stale another instance's cached regions can be after a change
+const regionCacheTTL = 5 * time.Minute
+
+var (
+	// ErrDefaultRegionRequired is returned when a change would leave no active default region
+	ErrDefaultRegionRequired = errors.New("the default region cannot be deleted, deactivated or unset")
+	// ErrRegionCityTaken is returned when a city already belongs to another region
+	ErrRegionCityTaken = errors.New("city already belongs to another region")
+	// ErrInvalidTimezone is returned for time zones that are not IANA names
+	ErrInvalidTimezone = errors.New("unknown time zone")
+	// ErrCategoryNotInRegion is returned when the region does not offer the requested category
+	ErrCategoryNotInRegion = errors.New("category is not available in this region")
+)
+
+// FallbackRegion is used when no default region has been configured, and matches the seeded one
+var FallbackRegion = models.Region{
+	Code:            "MR",
+	Name:            "Mauritania",
+	Cities:          []string{"Nouakchott", "Nouadhibou"},
+	Currency:        i18n.DefaultCurrency,
+	Timezone:        "Africa/Nouakchott",
+	DefaultLanguage: i18n.Default,
+	IsDefault:       true,
+	IsActive:        true,
+}
+
+// RegionService resolves the region of a city, request or worker and manages regions
+type RegionService struct {
+	db *gorm.DB
+}
+
+// NewRegionService creates a new region service
+func NewRegionService() *RegionService {
+	return &RegionService{
+		db: database.DB,
+	}
+}
+
+// Active returns the active regions, cached
+func (s *RegionService) Active() ([]models.Region, error) {
+	return cache.GetOrLoad(regionCacheKey, regionCacheTTL, func() ([]models.Region, error) {
+		regions := []models.Region{}
+		err := s.db.Where("is_active = ?", true).Order("name ASC").Find(&regions).Error
+		return regions, err
+	})
+}
+
+// Default returns the default region
+func (s *RegionService) Default() models.Region {
+	return s.ForCity("")
+}
+
+// ForCity returns the active region listing city, or the default region
+func (s *RegionService) ForCity(city string) models.Region {
+	regions, err := s.Active()
+	if err != nil {
+		log.Printf("⚠️ Failed to load regions, using the fallback region: %v", err)
+		return FallbackRegion
+	}
+	if city = NormalizeCity(city); city != "" {
+		for _, region := range regions {
+			if region.HasCity(city) {
+				return region
+			}
+		}
+	}
+	for _, region := range regions {
+		if region.IsDefault {
+			return region
+		}
+	}
+	return FallbackRegion
+}
+
+// ForRequest returns the region of the request's city, or of the service area around its
+// location when the city is not known yet
+func (s *RegionService) ForRequest(r models.CustomerServiceRequest) models.Region {
+	city := r.LocationCity
+	if city == "" && r.LocationLat != nil && r.LocationLng != nil {
+		if area, ok := FindServiceArea(*r.LocationLat, *r.LocationLng); ok {
+			city = area.City
+		}
+	}
+	return s.ForCity(city)
+}
+
+// ForWorkers returns the region of each worker's city
+func (s *RegionService) ForWorkers(workerIDs []uint) (map[uint]models.Region, error) {
+	var workers []models.WorkerProfile
+	if err := s.db.Select("id", "city").Where("id IN ?", workerIDs).Find(&workers).Error; err != nil {
+		return nil, err
+	}
+	regions := make(map[uint]models.Region, len(workers))
+	for _, w := range workers {
+		regions[w.ID] = s.ForCity(w.City)
+	}
+	return regions, nil
+}
+
+// CommissionPercent returns the platform commission in the region: its own rate, or the
+// worker_commission_percent setting
+func (s *RegionService) CommissionPercent(region models.Region) float64 {
+	if region.CommissionPercent != nil {
+		return *region.CommissionPercent
+	}
+	return NewSettingsService().Float(SettingWorkerCommissionPercent)
+}
+
+// CommissionPercentForRequest returns the platform commission in the request's region
+func (s *RegionService) CommissionPercentForRequest(r models.CustomerServiceRequest) float64 {
+	return s.CommissionPercent(s.ForRequest(r))
+}
+
+// Save validates and creates or updates the region. Making it the default unsets the previous one.
+func (s *RegionService) Save(region *models.Region) error {
+	if _, err := time.LoadLocation(region.Timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	cities := make([]string, 0, len(region.Cities))
+	for _, city := range region.Cities {
+		if city = NormalizeCity(city); city != "" && !containsCity(cities, city) {
+			cities = append(cities, city)
+		}
+	}
+	region.Cities = cities
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var others []models.Region
+		if err := tx.Where("id <> ?", region.ID).Find(&others).Error; err != nil {
+			return err
+		}
+		hasDefault := false
+		for _, other := range others {
+			for _, city := range region.Cities {
+				if other.HasCity(city) {
+					return ErrRegionCityTaken
+				}
+			}
+			hasDefault = hasDefault || other.IsDefault
+		}
+		if region.IsDefault {
+			if !region.IsActive {
+				return ErrDefaultRegionRequired
+			}
+			if err := tx.Model(&models.Region{}).Where("id <> ? AND is_default = ?", region.ID, true).
+				Update("is_default", false).Error; err != nil {
+				return err
+			}
+		} else if !hasDefault {
+			return ErrDefaultRegionRequired
+		}
+		return tx.Save(region).Error
+	})
+	if err == nil {
+		cache.Invalidate(regionCacheKey)
+	}
+	return err
+}
+
+// Delete soft deletes a region other than the default one
+func (s *RegionService) Delete(region *models.Region) error {
+	if region.IsDefault {
+		return ErrDefaultRegionRequired
+	}
+	if err := s.db.Delete(region).Error; err != nil {
+		return err
+	}
+	cache.Invalidate(regionCacheKey)
+	return nil
+}
+
+// containsCity reports whether cities already lists city, ignoring case
+func containsCity(cities []string, city string) bool {
+	region := models.Region{Cities: cities}
+	return region.HasCity(city)
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// ReliabilityWindow is how long a reliability event counts against a worker's score
+const ReliabilityWindow = 90 * 24 * time.Hour
+
+// ReducedPriorityDelay is how long workers with reduced priority wait to see a new broadcast
+const ReducedPriorityDelay = time.Minute
+
+var (
+	// ErrCannotCancelRequest is returned when a worker gives back a job that is not theirs to give back
+	ErrCannotCancelRequest = errors.New("only accepted jobs that have not started can be given back")
+	// ErrEventWaived is returned when waiving a reliability event twice
+	ErrEventWaived = errors.New("reliability event has already been waived")
+	// ErrNotSuspended is returned when reinstating a worker who is not suspended
+	ErrNotSuspended = errors.New("worker is not suspended")
+)
+
+// WorkerReliability is a worker's score, tier, suspension and the events behind them
+type WorkerReliability struct {
+	WorkerID         uint                            `json:"worker_id"`
+	Score            float64                         `json:"score"`
+	Tier             models.ReliabilityTier          `json:"tier"`
+	SuspendedUntil   *time.Time                      `json:"suspended_until"`
+	SuspensionReason string                          `json:"suspension_reason,omitempty"`
+	NoShowCount      int                             `json:"no_show_count"`
+	Events           []models.WorkerReliabilityEvent `json:"events"` // Within the scoring window, newest first
+}
+
+// ReliabilityService scores workers on the jobs they let down and applies the graduated policy:
+// a warning, then reduced broadcast priority, then a temporary suspension
+type ReliabilityService struct {
+	db *gorm.DB
+}
+
+// NewReliabilityService creates a new reliability service
+func NewReliabilityService() *ReliabilityService {
+	return &ReliabilityService{
+		db: database.DB,
+	}
+}
+
+// NotSuspendedScope restricts a worker_profiles query to workers not serving a suspension
+func NotSuspendedScope(db *gorm.DB) *gorm.DB {
+	return db.Where("worker_profiles.suspended_until IS NULL OR worker_profiles.suspended_until <= ?", time.Now())
+}
+
+// HasReducedPriority reports whether the worker sees new broadcasts only after ReducedPriorityDelay
+func HasReducedPriority(worker models.WorkerProfile) bool {
+	return worker.ReliabilityTier.Rank() >= models.ReliabilityTierReducedPriority.Rank()
+}
+
+// RecordReliabilityEvent takes kind's points off the worker's score inside tx and applies the
+// consequences of the tier they land in
+func RecordReliabilityEvent(tx *gorm.DB, workerID uint, requestID *uint, kind models.ReliabilityEventKind, note string, now time.Time) (*models.WorkerReliabilityEvent, error) {
+	event := &models.WorkerReliabilityEvent{
+		WorkerID:         workerID,
+		ServiceRequestID: requestID,
+		Kind:             kind,
+		Points:           reliabilityPoints(kind),
+		Note:             note,
+		CreatedAt:        now,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return nil, err
+	}
+	return event, applyReliability(tx, workerID, true, now)
+}
+
+// CancelAssignedRequest gives a job back on behalf of its assigned worker. Giving it back before
+// setting off counts as a decline after accept, and after as a cancellation. The request is
+// unassigned and the customer notified; the caller offers it to other workers.
+func (s *ReliabilityService) CancelAssignedRequest(requestID, workerID uint, reason string) (*models.CustomerServiceRequest, error) {
+	var request models.CustomerServiceRequest
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, requestID).Error; err != nil {
+			return err
+		}
+		if request.AssignedWorkerID == nil || *request.AssignedWorkerID != workerID {
+			return ErrCannotCancelRequest
+		}
+
+		kind := models.ReliabilityCancellation
+		switch request.Status {
+		case models.RequestStatusAccepted:
+			kind = models.ReliabilityDeclineAfterAccept
+		case models.RequestStatusEnRoute, models.RequestStatusArrived:
+		default:
+			return ErrCannotCancelRequest
+		}
+
+		now := time.Now()
+		if _, err := RecordReliabilityEvent(tx, workerID, &request.ID, kind, reason, now); err != nil {
+			return err
+		}
+		if err := unassignRequest(tx, &request, now); err != nil {
+			return err
+		}
+		return EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, request.ID, models.PushNotificationPayload{
+			UserID:  request.CustomerID,
+			Message: "notification.worker_cancelled",
+			Vars:    map[string]interface{}{"title": request.Title},
+			Type:    "worker_cancelled",
+			Data:    map[string]interface{}{"action": "worker_cancelled", "service_request_id": request.ID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// CheckArrival records a late arrival when the worker reached the request after the late arrival
+// window, counted from acceptance or the scheduled time when that is later
+func (s *ReliabilityService) CheckArrival(request models.CustomerServiceRequest, workerID uint) error {
+	window := time.Duration(NewSettingsService().Float(SettingLateArrivalMinutes) * float64(time.Minute))
+	if window <= 0 || request.AcceptedAt == nil || request.ArrivedAt == nil {
+		return nil
+	}
+	from := *request.AcceptedAt
+	if request.ScheduledFor != nil && request.ScheduledFor.After(from) {
+		from = *request.ScheduledFor
+	}
+	if !request.ArrivedAt.After(from.Add(window)) {
+		return nil
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		_, err := RecordReliabilityEvent(tx, workerID, &request.ID, models.ReliabilityLateArrival, "", *request.ArrivedAt)
+		return err
+	})
+}
+
+// DroppedRequest reports whether the worker already gave up or missed the request, so they cannot
+// take it again
+func (s *ReliabilityService) DroppedRequest(requestID, workerID uint) bool {
+	var count int64
+	s.db.Model(&models.WorkerReliabilityEvent{}).
+		Where("service_request_id = ? AND worker_id = ? AND kind IN ?", requestID, workerID, []models.ReliabilityEventKind{
+			models.ReliabilityNoShow, models.ReliabilityCancellation, models.ReliabilityDeclineAfterAccept,
+		}).Count(&count)
+	return count > 0
+}
+
+// Get returns the worker's reliability with the events in the scoring window
+func (s *ReliabilityService) Get(workerID uint) (*WorkerReliability, error) {
+	var worker models.WorkerProfile
+	if err := s.db.First(&worker, workerID).Error; err != nil {
+		return nil, err
+	}
+	events := []models.WorkerReliabilityEvent{}
+	if err := s.db.Where("worker_id = ? AND created_at > ?", workerID, time.Now().Add(-ReliabilityWindow)).
+		Order("created_at DESC, id DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	reliability := &WorkerReliability{
+		WorkerID:       worker.ID,
+		Score:          worker.ReliabilityScore,
+		Tier:           worker.ReliabilityTier,
+		SuspendedUntil: worker.SuspendedUntil,
+		NoShowCount:    worker.NoShowCount,
+		Events:         events,
+	}
+	if worker.IsSuspended(time.Now()) {
+		reliability.SuspensionReason = worker.SuspensionReason
+	} else {
+		reliability.SuspendedUntil = nil
+	}
+	return reliability, nil
+}
+
+// Waive excuses an event, for example when the customer was at fault, and rescores the worker.
+// A suspension already served or in progress is left to Reinstate.
+func (s *ReliabilityService) Waive(workerID, eventID, adminID uint, note string) (*models.WorkerReliabilityEvent, error) {
+	var event models.WorkerReliabilityEvent
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("worker_id = ?", workerID).First(&event, eventID).Error; err != nil {
+			return err
+		}
+		if event.WaivedAt != nil {
+			return ErrEventWaived
+		}
+		now := time.Now()
+		event.WaivedAt = &now
+		event.WaivedByID = &adminID
+		event.WaiveNote = note
+		if err := tx.Save(&event).Error; err != nil {
+			return err
+		}
+		return applyReliability(tx, workerID, false, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Suspend suspends the worker by hand for days, whatever their score
+func (s *ReliabilityService) Suspend(workerID uint, days int, reason string) (*models.WorkerProfile, error) {
+	var worker models.WorkerProfile
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&worker, workerID).Error; err != nil {
+			return err
+		}
+		return suspendWorker(tx, &worker, time.Now().AddDate(0, 0, days), reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &worker, nil
+}
+
+// Reinstate ends the worker's suspension early and returns when it would have ended. They stay
+// unavailable until they go available again.
+func (s *ReliabilityService) Reinstate(workerID uint) (time.Time, error) {
+	var worker models.WorkerProfile
+	var until time.Time
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&worker, workerID).Error; err != nil {
+			return err
+		}
+		if !worker.IsSuspended(time.Now()) {
+			return ErrNotSuspended
+		}
+		until = *worker.SuspendedUntil
+		if err := tx.Model(&worker).Updates(map[string]interface{}{
+			"suspended_until":   nil,
+			"suspension_reason": "",
+		}).Error; err != nil {
+			return err
+		}
+		return enqueueReliabilityNotification(tx, worker, "notification.reliability_reinstated", nil)
+	})
+	return until, err
+}
+
+// RecomputeAll rescores every worker with events, so events leaving the window stop counting even
+// without a new one. Recomputing never warns or suspends.
+func (s *ReliabilityService) RecomputeAll() (int, error) {
+	var workerIDs []uint
+	if err := s.db.Model(&models.WorkerProfile{}).
+		Where("reliability_score < 100 OR id IN (?)", s.db.Model(&models.WorkerReliabilityEvent{}).Select("worker_id")).
+		Pluck("id", &workerIDs).Error; err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	for i, workerID := range workerIDs {
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			return applyReliability(tx, workerID, false, now)
+		}); err != nil {
+			return i, err
+		}
+	}
+	return len(workerIDs), nil
+}
+
+// applyReliability rescores the worker and stores their tier. After a new event, a worse tier
+// warns the worker, and the suspended tier suspends them unless they already are.
+func applyReliability(tx *gorm.DB, workerID uint, newEvent bool, now time.Time) error {
+	var worker models.WorkerProfile
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&worker, workerID).Error; err != nil {
+		return err
+	}
+
+	var lost float64
+	if err := tx.Model(&models.WorkerReliabilityEvent{}).
+		Where("worker_id = ? AND waived_at IS NULL AND created_at > ?", workerID, now.Add(-ReliabilityWindow)).
+		Select("COALESCE(SUM(points), 0)").Scan(&lost).Error; err != nil {
+		return err
+	}
+	score := 100 - lost
+	if score < 0 {
+		score = 0
+	}
+	tier := reliabilityTier(score)
+	previous := worker.ReliabilityTier
+	if err := tx.Model(&worker).Updates(map[string]interface{}{
+		"reliability_score": score,
+		"reliability_tier":  tier,
+	}).Error; err != nil {
+		return err
+	}
+	if !newEvent {
+		return nil
+	}
+
+	if tier == models.ReliabilityTierSuspended && !worker.IsSuspended(now) {
+		days := int(NewSettingsService().Float(SettingReliabilitySuspensionDays))
+		return suspendWorker(tx, &worker, now.AddDate(0, 0, days), fmt.Sprintf("Reliability score fell to %.0f", score))
+	}
+	switch {
+	case tier.Rank() <= previous.Rank():
+		return nil
+	case tier == models.ReliabilityTierWarning:
+		return enqueueReliabilityNotification(tx, worker, "notification.reliability_warning", map[string]interface{}{"score": fmt.Sprintf("%.0f", score)})
+	case tier == models.ReliabilityTierReducedPriority:
+		return enqueueReliabilityNotification(tx, worker, "notification.reliability_reduced", map[string]interface{}{"score": fmt.Sprintf("%.0f", score)})
+	}
+	return nil
+}
+
+// suspendWorker suspends the worker until until, takes them offline and tells them
+func suspendWorker(tx *gorm.DB, worker *models.WorkerProfile, until time.Time, reason string) error {
+	if err := tx.Model(worker).Updates(map[string]interface{}{
+		"suspended_until":   until,
+		"suspension_reason": reason,
+		"is_available":      false,
+	}).Error; err != nil {
+		return err
+	}
+	worker.SuspendedUntil, worker.SuspensionReason, worker.IsAvailable = &until, reason, false
+	return enqueueReliabilityNotification(tx, *worker, "notification.reliability_suspended", map[string]interface{}{
+		"until": until.Format("2006-01-02"),
+	})
+}
+
+// reliabilityPoints returns the points an event of kind takes off the score
+func reliabilityPoints(kind models.ReliabilityEventKind) float64 {
+	settings := NewSettingsService()
+	switch kind {
+	case models.ReliabilityNoShow:
+		return settings.Float(SettingNoShowReliabilityPenalty)
+	case models.ReliabilityCancellation:
+		return settings.Float(SettingReliabilityCancellationPoints)
+	case models.ReliabilityDeclineAfterAccept:
+		return settings.Float(SettingReliabilityDeclineAfterAcceptPoints)
+	case models.ReliabilityLateArrival:
+		return settings.Float(SettingReliabilityLateArrivalPoints)
+	}
+	return 0
+}
+
+// reliabilityTier returns the tier score falls in under the current thresholds
+func reliabilityTier(score float64) models.ReliabilityTier {
+	settings := NewSettingsService()
+	switch {
+	case score < settings.Float(SettingReliabilitySuspendScore):
+		return models.ReliabilityTierSuspended
+	case score < settings.Float(SettingReliabilityReducedScore):
+		return models.ReliabilityTierReducedPriority
+	case score < settings.Float(SettingReliabilityWarningScore):
+		return models.ReliabilityTierWarning
+	}
+	return models.ReliabilityTierGood
+}
+
+// enqueueReliabilityNotification pushes a reliability message to the worker
+func enqueueReliabilityNotification(tx *gorm.DB, worker models.WorkerProfile, message string, vars map[string]interface{}) error {
+	return EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, worker.ID, models.PushNotificationPayload{
+		UserID:  worker.UserID,
+		Message: message,
+		Vars:    vars,
+		Type:    "reliability",
+		Data:    map[string]interface{}{"action": "reliability"},
+	})
+}
+
+// unassignRequest takes the request away from its worker and puts it back up: in broadcast, or
+// pending for the auto-dispatch cascade
+func unassignRequest(tx *gorm.DB, request *models.CustomerServiceRequest, now time.Time) error {
+	status := models.RequestStatusBroadcast
+	if request.DispatchMode == models.DispatchModeAuto {
+		status = models.RequestStatusPending
+	}
+	expiresAt := now.Add(3 * time.Minute)
+	if err := tx.Model(request).Updates(map[string]interface{}{
+		"status":             status,
+		"assigned_worker_id": nil,
+		"accepted_at":        nil,
+		"en_route_at":        nil,
+		"arrived_at":         nil,
+		"eta_minutes":        nil,
+		"eta_updated_at":     nil,
+		"expires_at":         expiresAt,
+	}).Error; err != nil {
+		return err
+	}
+	request.Status = status
+	request.AssignedWorkerID = nil
+	request.AcceptedAt, request.EnRouteAt, request.ArrivedAt = nil, nil, nil
+	request.EtaMinutes, request.EtaUpdatedAt = nil, nil
+	request.ExpiresAt = &expiresAt
+	return nil
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// Report granularities supported by the time-series endpoints
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// ErrInvalidGranularity is returned for an unknown report granularity
+var ErrInvalidGranularity = errors.New("granularity must be day, week or month")
+
+// ReportFilter narrows a report to a date range and optionally a category or city
+type ReportFilter struct {
+	From       time.Time
+	To         time.Time // Inclusive day
+	CategoryID uint
+	City       string
+}
+
+// ReportPoint is one bucket of a platform time-series or breakdown
+type ReportPoint struct {
+	Period              string       `json:"period,omitempty"`
+	CategoryID          uint         `json:"category_id,omitempty"`
+	City                string       `json:"city,omitempty"`
+	RequestsCreated     int          `json:"requests_created"`
+	RequestsAccepted    int          `json:"requests_accepted"`
+	RequestsCompleted   int          `json:"requests_completed"`
+	RequestsCancelled   int          `json:"requests_cancelled"`
+	CompletionRate      float64      `json:"completion_rate"` // Percentage of created requests completed
+	AvgTimeToAcceptMins float64      `json:"avg_time_to_accept_mins"`
+	GMV                 money.Amount `json:"gmv"`
+	Refunds             money.Amount `json:"refunds"`
+	Earnings            money.Amount `json:"earnings"` // GMV net of refunds
+	totalAcceptMinutes  float64
+}
+
+// ReportService builds and queries the pre-aggregated platform metrics
+type ReportService struct {
+	db *gorm.DB
+}
+
+// NewReportService creates a new report service
+func NewReportService() *ReportService {
+	return &ReportService{
+		db: database.DB,
+	}
+}
+
+// ReportReaggregateDays returns how many trailing days the nightly job recomputes (REPORT_REAGGREGATE_DAYS, default 7).
+// Recent days are recomputed because requests created on them may still complete or be cancelled.
+func ReportReaggregateDays() int {
+	if days, err := strconv.Atoi(os.Getenv("REPORT_REAGGREGATE_DAYS")); err == nil && days > 0 {
+		return days
+	}
+	return 7
+}
+
+// TruncateDay returns midnight of the given time's day
+func TruncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// AggregateRange recomputes the daily metrics for every day from `from` to `to` inclusive
+func (s *ReportService) AggregateRange(from, to time.Time) error {
+	for day := TruncateDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		if err := s.AggregateDay(day); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AggregateDay replaces the metric rows for a single day
+func (s *ReportService) AggregateDay(day time.Time) error {
+	start := TruncateDay(day)
+	end := start.AddDate(0, 0, 1)
+
+	type metricKey struct {
+		categoryID uint
+		city       string
+	}
+	metrics := make(map[metricKey]*models.PlatformDailyMetric)
+	metricFor := func(categoryID uint, city string) *models.PlatformDailyMetric {
+		key := metricKey{categoryID, city}
+		if m, ok := metrics[key]; ok {
+			return m
+		}
+		m := &models.PlatformDailyMetric{Date: start, CategoryID: categoryID, City: city}
+		metrics[key] = m
+		return m
+	}
+
+	var requests []struct {
+		ID           uint
+		CategoryID   uint
+		LocationCity string
+		Status       models.CustomerServiceRequestStatus
+		CreatedAt    time.Time
+		AcceptedAt   *time.Time
+	}
+	if err := s.db.Model(&models.CustomerServiceRequest{}).
+		Select(`customer_service_requests.id, customer_service_requests.category_id, customer_service_requests.location_city,
+			customer_service_requests.status, customer_service_requests.created_at,
+			(SELECT MIN(responded_at) FROM worker_responses WHERE worker_responses.service_request_id = customer_service_requests.id AND worker_responses.response = 'accept') AS accepted_at`).
+		Where("customer_service_requests.created_at >= ? AND customer_service_requests.created_at < ?", start, end).
+		Scan(&requests).Error; err != nil {
+		return err
+	}
+
+	for _, r := range requests {
+		m := metricFor(r.CategoryID, r.LocationCity)
+		m.RequestsCreated++
+		switch r.Status {
+		case models.RequestStatusCompleted:
+			m.RequestsCompleted++
+		case models.RequestStatusCancelled:
+			m.RequestsCancelled++
+		}
+		if r.AcceptedAt != nil {
+			m.RequestsAccepted++
+			m.TotalAcceptMinutes += r.AcceptedAt.Sub(r.CreatedAt).Minutes()
+		}
+	}
+
+	var completed []struct {
+		CategoryID   uint
+		LocationCity string
+		FinalPrice   *money.Amount
+	}
+	if err := s.db.Model(&models.ServiceHistory{}).
+		Select("category_id, location_city, final_price").
+		Where("completed_at >= ? AND completed_at < ?", start, end).
+		Scan(&completed).Error; err != nil {
+		return err
+	}
+	for _, h := range completed {
+		if h.FinalPrice != nil {
+			metricFor(h.CategoryID, h.LocationCity).GMV += *h.FinalPrice
+		}
+	}
+
+	var refunds []struct {
+		CategoryID   uint
+		LocationCity string
+		Amount       money.Amount
+	}
+	if err := s.db.Model(&models.ServiceAdjustment{}).
+		Select("service_histories.category_id, service_histories.location_city, service_adjustments.amount").
+		Joins("JOIN service_histories ON service_histories.id = service_adjustments.service_history_id").
+		Where("service_adjustments.type IN ? AND service_adjustments.created_at >= ? AND service_adjustments.created_at < ?",
+			[]models.ServiceAdjustmentType{models.AdjustmentTypeRefund, models.AdjustmentTypePartialRefund}, start, end).
+		Scan(&refunds).Error; err != nil {
+		return err
+	}
+	for _, r := range refunds {
+		metricFor(r.CategoryID, r.LocationCity).Refunds += r.Amount
+	}
+
+	now := time.Now()
+	rows := make([]models.PlatformDailyMetric, 0, len(metrics))
+	for _, m := range metrics {
+		m.ComputedAt = now
+		rows = append(rows, *m)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("date = ?", start).Delete(&models.PlatformDailyMetric{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// GetTimeSeries returns platform metrics bucketed by day, week or month
+func (s *ReportService) GetTimeSeries(filter ReportFilter, granularity string) ([]ReportPoint, error) {
+	if granularity != GranularityDay && granularity != GranularityWeek && granularity != GranularityMonth {
+		return nil, ErrInvalidGranularity
+	}
+
+	rows, err := s.loadMetrics(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets := make(map[string]*ReportPoint)
+	for _, row := range rows {
+		period := periodKey(row.Date, granularity)
+		point, ok := buckets[period]
+		if !ok {
+			point = &ReportPoint{Period: period}
+			buckets[period] = point
+		}
+		point.add(row)
+	}
+
+	// Emit empty buckets too so charts have a continuous axis
+	points := make([]ReportPoint, 0, len(buckets))
+	seen := make(map[string]bool)
+	for day := TruncateDay(filter.From); !day.After(filter.To); day = day.AddDate(0, 0, 1) {
+		period := periodKey(day, granularity)
+		if seen[period] {
+			continue
+		}
+		seen[period] = true
+		point := ReportPoint{Period: period}
+		if b, ok := buckets[period]; ok {
+			point = *b
+		}
+		point.finalize()
+		points = append(points, point)
+	}
+
+	return points, nil
+}
+
+// GetBreakdown returns totals for the date range grouped by category or city
+func (s *ReportService) GetBreakdown(filter ReportFilter, groupBy string) ([]ReportPoint, error) {
+	rows, err := s.loadMetrics(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*ReportPoint)
+	for _, row := range rows {
+		key := row.City
+		point := &ReportPoint{City: row.City}
+		if groupBy == "category" {
+			key = strconv.FormatUint(uint64(row.CategoryID), 10)
+			point = &ReportPoint{CategoryID: row.CategoryID}
+		}
+		if existing, ok := groups[key]; ok {
+			point = existing
+		} else {
+			groups[key] = point
+		}
+		point.add(row)
+	}
+
+	points := make([]ReportPoint, 0, len(groups))
+	for _, point := range groups {
+		point.finalize()
+		points = append(points, *point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Earnings > points[j].Earnings
+	})
+
+	return points, nil
+}
+
+// DeclineReasonCount is how often workers gave one reason for turning down requests
+type DeclineReasonCount struct {
+	Reason  string  `json:"reason"` // "unspecified" for declines recorded before reasons were required
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"` // Share of all declines in the report
+}
+
+// declineReasonUnspecified groups declines that have no reason
+const declineReasonUnspecified = "unspecified"
+
+// GetDeclineReasons counts worker declines in the date range by reason, most common first
+func (s *ReportService) GetDeclineReasons(filter ReportFilter) ([]DeclineReasonCount, error) {
+	query := s.db.Table("worker_responses").
+		Joins("JOIN customer_service_requests ON customer_service_requests.id = worker_responses.service_request_id").
+		Where("worker_responses.response = ? AND worker_responses.responded_at >= ? AND worker_responses.responded_at < ?",
+			"decline", TruncateDay(filter.From), TruncateDay(filter.To).AddDate(0, 0, 1))
+	if filter.CategoryID != 0 {
+		query = query.Where("customer_service_requests.category_id = ?", filter.CategoryID)
+	}
+	if filter.City != "" {
+		query = query.Where("customer_service_requests.location_city = ?", filter.City)
+	}
+	return s.countDeclineReasons(query)
+}
+
+// GetWorkerDeclineReasons counts a worker's declines by reason, most common first
+func (s *ReportService) GetWorkerDeclineReasons(workerID uint) ([]DeclineReasonCount, error) {
+	return s.countDeclineReasons(s.db.Table("worker_responses").
+		Where("worker_responses.response = ? AND worker_responses.worker_id = ?", "decline", workerID))
+}
+
+// countDeclineReasons groups the declines selected by query by reason. Every known reason is
+// listed, with a zero count when no worker gave it.
+func (s *ReportService) countDeclineReasons(query *gorm.DB) ([]DeclineReasonCount, error) {
+	var rows []struct {
+		Reason string
+		Count  int
+	}
+	if err := query.
+		Select("COALESCE(NULLIF(worker_responses.decline_reason, ''), ?) AS reason, COUNT(*) AS count", declineReasonUnspecified).
+		Group("1").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	total := 0
+	for _, row := range rows {
+		counts[row.Reason] = row.Count
+		total += row.Count
+	}
+
+	reasons := make([]DeclineReasonCount, 0, len(models.DeclineReasons)+1)
+	for _, reason := range models.DeclineReasons {
+		reasons = append(reasons, DeclineReasonCount{Reason: string(reason), Count: counts[string(reason)]})
+	}
+	if counts[declineReasonUnspecified] > 0 {
+		reasons = append(reasons, DeclineReasonCount{Reason: declineReasonUnspecified, Count: counts[declineReasonUnspecified]})
+	}
+	for i := range reasons {
+		if total > 0 {
+			reasons[i].Percent = float64(reasons[i].Count) / float64(total) * 100
+		}
+	}
+	sort.SliceStable(reasons, func(i, j int) bool {
+		return reasons[i].Count > reasons[j].Count
+	})
+
+	return reasons, nil
+}
+
+// TotalEarnings returns the net value of completed services since the given time (zero time for all-time)
+func (s *ReportService) TotalEarnings(since time.Time) (money.Amount, error) {
+	var total money.Amount
+	query := s.db.Model(&models.ServiceHistory{}).
+		Select("COALESCE(SUM(COALESCE(final_price, 0) - refunded_amount), 0)")
+	if !since.IsZero() {
+		query = query.Where("completed_at >= ?", since)
+	}
+	err := query.Scan(&total).Error
+	return total, err
+}
+
+func (s *ReportService) loadMetrics(filter ReportFilter) ([]models.PlatformDailyMetric, error) {
+	query := s.db.Where("date >= ? AND date <= ?", TruncateDay(filter.From), TruncateDay(filter.To))
+	if filter.CategoryID != 0 {
+		query = query.Where("category_id = ?", filter.CategoryID)
+	}
+	if filter.City != "" {
+		query = query.Where("city = ?", filter.City)
+	}
+
+	var rows []models.PlatformDailyMetric
+	err := query.Order("date ASC").Find(&rows).Error
+	return rows, err
+}
+
+// periodKey labels the bucket a day falls in: 2006-01-02 for days and weeks (Monday start), 2006-01 for months
+func periodKey(day time.Time, granularity string) string {
+	switch granularity {
+	case GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset).Format("2006-01-02")
+	case GranularityMonth:
+		return day.Format("2006-01")
+	default:
+		return day.Format("2006-01-02")
+	}
+}
+
+func (p *ReportPoint) add(row models.PlatformDailyMetric) {
+	p.RequestsCreated += row.RequestsCreated
+	p.RequestsAccepted += row.RequestsAccepted
+	p.RequestsCompleted += row.RequestsCompleted
+	p.RequestsCancelled += row.RequestsCancelled
+	p.totalAcceptMinutes += row.TotalAcceptMinutes
+	p.GMV += row.GMV
+	p.Refunds += row.Refunds
+}
+
+func (p *ReportPoint) finalize() {
+	if p.RequestsCreated > 0 {
+		p.CompletionRate = float64(p.RequestsCompleted) / float64(p.RequestsCreated) * 100
+	}
+	if p.RequestsAccepted > 0 {
+		p.AvgTimeToAcceptMins = p.totalAcceptMinutes / float64(p.RequestsAccepted)
+	}
+	p.Earnings = p.GMV - p.Refunds
+}
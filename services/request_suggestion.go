@@ -0,0 +1,251 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/validation"
+)
+
+const (
+	suggestionHistoryWindow = 180 * 24 * time.Hour // Completed jobs priced from
+	suggestionMinSamples    = 5                    // Jobs needed before history sets the price range
+)
+
+// Where a suggested price range comes from
+const (
+	PriceSourceHistory       = "history"        // Prices of recent completed jobs
+	PriceSourceServiceOption = "service_option" // The option's list price
+	PriceSourceAI            = "ai"             // Gemini's estimate
+)
+
+// RequestSuggestion pre-fills the service request form from the customer's description
+type RequestSuggestion struct {
+	CategoryID      *uint         `json:"category_id"`
+	ServiceOptionID *uint         `json:"service_option_id"`
+	Priority        string        `json:"priority"`
+	Title           string        `json:"title"`
+	PriceMin        *money.Amount `json:"price_min"`
+	PriceMax        *money.Amount `json:"price_max"`
+	PriceMedian     *money.Amount `json:"price_median,omitempty"`
+	PriceSource     string        `json:"price_source,omitempty"`
+	SampleSize      int           `json:"sample_size"`        // Completed jobs behind a history price range
+	Degraded        bool          `json:"degraded,omitempty"` // Matched by keyword because Gemini was unavailable
+}
+
+// suggestionAnswer is the JSON Gemini is asked to return for a description
+type suggestionAnswer struct {
+	CategoryID      uint         `json:"category_id"`
+	ServiceOptionID uint         `json:"service_option_id"`
+	Priority        string       `json:"priority"`
+	Title           string       `json:"title"`
+	PriceMin        money.Amount `json:"price_min"`
+	PriceMax        money.Amount `json:"price_max"`
+}
+
+// SuggestRequest suggests a category, service option, priority and price range for a repair
+// described in description and, optionally, a photo prepared by PrepareDiagnosisImage. When
+// Gemini is not configured or out of quota the category is matched by keyword instead.
+func (ai *AIService) SuggestRequest(description string, photo []byte, language string) (*RequestSuggestion, error) {
+	snapshot, err := currentAIContext()
+	if err != nil {
+		return nil, err
+	}
+
+	suggestion := &RequestSuggestion{Priority: "medium"}
+	var answer suggestionAnswer
+	if ai.apiKey != "" {
+		answer, err = ai.askSuggestion(description, photo, language, snapshot)
+	} else {
+		err = ErrAIUnavailable
+	}
+	switch {
+	case err == nil:
+		applySuggestionAnswer(suggestion, answer, snapshot)
+	case errors.Is(err, ErrAIUnavailable), errors.Is(err, ErrAIQuotaExhausted):
+		suggestion.Degraded = true
+		if category := matchCategoryByName(description, snapshot.categories); category != nil {
+			suggestion.CategoryID = &category.ID
+		}
+	default:
+		return nil, err
+	}
+
+	if suggestion.CategoryID == nil {
+		return suggestion, nil
+	}
+	if err := suggestPrice(suggestion, answer, snapshot); err != nil {
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// askSuggestion asks Gemini to classify the description against the catalog
+func (ai *AIService) askSuggestion(description string, photo []byte, language string, snapshot *aiContextSnapshot) (suggestionAnswer, error) {
+	currency := NewRegionService().Default().Currency
+	var catalog strings.Builder
+	for _, category := range snapshot.categories {
+		fmt.Fprintf(&catalog, "- [category %d] %s: %s\n", category.ID, category.Name, category.Description)
+		for _, option := range snapshot.options {
+			if option.CategoryID == category.ID {
+				fmt.Fprintf(&catalog, "  - [option %d] %s (%s %s): %s\n", option.ID, option.Title, option.Price, currency, option.Description)
+			}
+		}
+	}
+	if language == "" {
+		language = "fr"
+	}
+
+	prompt := fmt.Sprintf(`You help customers of a home repair marketplace in Nouakchott, Mauritania fill in a repair request.
+The customer describes the problem as: %q
+
+Catalog:
+%s
+Reply with JSON only:
+{"category_id": id of the category that should fix it, or 0 if none fits,
+ "service_option_id": id of the matching option of that category, or 0 if none matches exactly,
+ "priority": "low" | "medium" | "high" | "urgent",
+ "title": "short request title in language %q",
+ "price_min": lowest likely cost in %[4]s,
+ "price_max": highest likely cost in %[4]s}
+Use "urgent" only for safety hazards such as exposed wires, gas smells or flooding.`, description, catalog.String(), language, currency)
+
+	parts := []Part{{Text: prompt}}
+	if len(photo) > 0 {
+		parts = append(parts, Part{InlineData: &InlineData{MimeType: "image/jpeg", Data: base64.StdEncoding.EncodeToString(photo)}})
+	}
+	content, err := ai.send(GeminiRequest{
+		Contents: []Content{{Parts: parts}},
+		GenerationConfig: GenerationConfig{
+			Temperature:      0.2,
+			TopK:             40,
+			TopP:             0.95,
+			MaxOutputTokens:  512,
+			ResponseMimeType: "application/json",
+		},
+	})
+	if err != nil {
+		return suggestionAnswer{}, fmt.Errorf("failed to call gemini API: %w", err)
+	}
+
+	var response string
+	for _, part := range content.Parts {
+		response += part.Text
+	}
+	var answer suggestionAnswer
+	cleaned := strings.TrimPrefix(strings.Trim(strings.TrimSpace(response), "`"), "json")
+	if err := json.Unmarshal([]byte(strings.TrimSpace(cleaned)), &answer); err != nil {
+		return suggestionAnswer{}, fmt.Errorf("unexpected suggestion response: %s", response)
+	}
+	return answer, nil
+}
+
+// applySuggestionAnswer keeps the parts of Gemini's answer that match the catalog
+func applySuggestionAnswer(suggestion *RequestSuggestion, answer suggestionAnswer, snapshot *aiContextSnapshot) {
+	if validation.IsPriority(answer.Priority) {
+		suggestion.Priority = answer.Priority
+	}
+	suggestion.Title = strings.TrimSpace(answer.Title)
+	if len(suggestion.Title) > 200 {
+		suggestion.Title = suggestion.Title[:200]
+	}
+
+	category := categoryByID(snapshot.categories, answer.CategoryID)
+	if category == nil {
+		return
+	}
+	suggestion.CategoryID = &category.ID
+	for i := range snapshot.options {
+		if snapshot.options[i].ID == answer.ServiceOptionID && snapshot.options[i].CategoryID == category.ID {
+			suggestion.ServiceOptionID = &snapshot.options[i].ID
+			break
+		}
+	}
+}
+
+// suggestPrice sets the price range from recent completed jobs like the suggested one, falling
+// back to the option's list price and then to Gemini's estimate
+func suggestPrice(suggestion *RequestSuggestion, answer suggestionAnswer, snapshot *aiContextSnapshot) error {
+	stats, err := historicalPrices(*suggestion.CategoryID, suggestion.ServiceOptionID)
+	if err != nil {
+		return err
+	}
+	if stats.Samples < suggestionMinSamples && suggestion.ServiceOptionID != nil {
+		// Too few jobs for this option; the whole category is a better guide than nothing
+		if stats, err = historicalPrices(*suggestion.CategoryID, nil); err != nil {
+			return err
+		}
+	}
+	if stats.Samples >= suggestionMinSamples {
+		suggestion.PriceMin, suggestion.PriceMax, suggestion.PriceMedian = &stats.P25, &stats.P75, &stats.Median
+		suggestion.PriceSource = PriceSourceHistory
+		suggestion.SampleSize = stats.Samples
+		return nil
+	}
+
+	if suggestion.ServiceOptionID != nil {
+		for _, option := range snapshot.options {
+			if option.ID == *suggestion.ServiceOptionID {
+				price := option.Price
+				suggestion.PriceMin, suggestion.PriceMax = &price, &price
+				suggestion.PriceSource = PriceSourceServiceOption
+				return nil
+			}
+		}
+	}
+
+	if answer.PriceMax > 0 {
+		low, high := max(min(answer.PriceMin, answer.PriceMax), 0), answer.PriceMax
+		suggestion.PriceMin, suggestion.PriceMax = &low, &high
+		suggestion.PriceSource = PriceSourceAI
+	}
+	return nil
+}
+
+// priceStats summarizes the final prices of completed jobs
+type priceStats struct {
+	Samples int
+	P25     money.Amount
+	Median  money.Amount
+	P75     money.Amount
+}
+
+// historicalPrices returns the quartiles of final prices of undisputed jobs in a category, or
+// one of its options, completed in the last 180 days
+func historicalPrices(categoryID uint, optionID *uint) (priceStats, error) {
+	query := database.DB.Model(&models.ServiceHistory{}).
+		Select(`COUNT(*) AS samples,
+			COALESCE(percentile_cont(0.25) WITHIN GROUP (ORDER BY final_price), 0) AS p25,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY final_price), 0) AS median,
+			COALESCE(percentile_cont(0.75) WITHIN GROUP (ORDER BY final_price), 0) AS p75`).
+		Where("category_id = ? AND final_price > 0 AND is_disputed = ? AND completed_at >= ?", categoryID, false, time.Now().Add(-suggestionHistoryWindow))
+	if optionID != nil {
+		query = query.Where("service_option_id = ?", *optionID)
+	}
+
+	var stats priceStats
+	if err := query.Scan(&stats).Error; err != nil {
+		log.Printf("❌ Failed to load price history for category %d: %v", categoryID, err)
+		return priceStats{}, err
+	}
+	return stats, nil
+}
+
+// matchCategoryByName finds the first category whose name appears in text
+func matchCategoryByName(text string, categories []models.ServiceCategory) *models.ServiceCategory {
+	text = strings.ToLower(text)
+	for i := range categories {
+		if categories[i].Name != "" && strings.Contains(text, strings.ToLower(categories[i].Name)) {
+			return &categories[i]
+		}
+	}
+	return nil
+}
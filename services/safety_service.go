@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/models"
+)
+
+var (
+	// ErrSOSNotAllowed is returned when an SOS is raised on a request that is not under way
+	ErrSOSNotAllowed = errors.New("an SOS can only be raised while the worker is on the way or on site")
+	// ErrIncidentResolved is returned when resolving an incident that was already resolved
+	ErrIncidentResolved = errors.New("incident has already been resolved")
+)
+
+// sosStatuses are the request statuses in which the customer and worker may meet, and so raise an SOS
+var sosStatuses = []models.CustomerServiceRequestStatus{
+	models.RequestStatusEnRoute, models.RequestStatusArrived, models.RequestStatusInProgress,
+}
+
+// SafetyService records SOS incidents, freezes their requests and applies the admin's review
+type SafetyService struct {
+	db *gorm.DB
+}
+
+// NewSafetyService creates a new safety service
+func NewSafetyService() *SafetyService {
+	return &SafetyService{
+		db: database.DB,
+	}
+}
+
+// RaiseSOS puts the request on hold and records an incident raised by reporter, who must be its
+// customer or the assigned worker's user. The running work timer is paused, the other party is
+// notified and, when asked and set, the reporter's emergency contact is texted. An SOS on a
+// request already on hold returns its open incident with created false.
+func (s *SafetyService) RaiseSOS(requestID uint, reporter models.User, role models.UserRole, req models.SOSRequest) (incident *models.SafetyIncident, created bool, err error) {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var request models.CustomerServiceRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, requestID).Error; err != nil {
+			return err
+		}
+
+		if request.Status == models.RequestStatusOnHold {
+			incident = &models.SafetyIncident{}
+			return tx.Where("service_request_id = ? AND status = ?", request.ID, models.SafetyIncidentOpen).
+				Order("created_at DESC").First(incident).Error
+		}
+		if !isSOSStatus(request.Status) {
+			return ErrSOSNotAllowed
+		}
+
+		now := time.Now()
+		incident = &models.SafetyIncident{
+			ServiceRequestID: request.ID,
+			ReportedByID:     reporter.ID,
+			ReporterRole:     role,
+			Lat:              req.Lat,
+			Lng:              req.Lng,
+			Message:          req.Message,
+			PreviousStatus:   request.Status,
+			Status:           models.SafetyIncidentOpen,
+		}
+		if incident.Lat == nil || incident.Lng == nil {
+			incident.Lat, incident.Lng = request.LocationLat, request.LocationLng
+		}
+		contactPhone := reporter.EmergencyContactPhone
+		incident.EmergencyContactNotified = req.NotifyEmergencyContact && contactPhone != nil && *contactPhone != ""
+		if err := tx.Create(incident).Error; err != nil {
+			return err
+		}
+
+		if request.TimerState == models.WorkTimerRunning {
+			if err := closeWorkSession(tx, &request, models.WorkTimerPaused, "sos", now); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&request).Update("status", models.RequestStatusOnHold).Error; err != nil {
+			return err
+		}
+
+		if otherID, ok := s.otherParty(tx, request, reporter.ID); ok {
+			if err := enqueueSafetyNotification(tx, otherID, request, "notification.sos_raised"); err != nil {
+				return err
+			}
+		}
+		if incident.EmergencyContactNotified {
+			if err := EnqueueOutboxEvent(tx, models.OutboxEventSMS, incident.ID, models.SMSPayload{
+				UserID:           &reporter.ID,
+				To:               *contactPhone,
+				Body:             sosContactText(reporter, request, incident),
+				Purpose:          models.SMSPurposeEmergency,
+				NotificationType: "sos",
+			}); err != nil {
+				return err
+			}
+		}
+		created = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return incident, created, nil
+}
+
+// Resolve closes an open incident. Resuming puts the request back in its status before the SOS,
+// with a paused timer left for the worker to resume; cancelling cancels it and stops the timer.
+// Both parties are notified.
+func (s *SafetyService) Resolve(incidentID, adminID uint, req models.SafetyResolveRequest) (*models.SafetyIncident, error) {
+	var incident models.SafetyIncident
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&incident, incidentID).Error; err != nil {
+			return err
+		}
+		if incident.Status != models.SafetyIncidentOpen {
+			return ErrIncidentResolved
+		}
+		var request models.CustomerServiceRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, incident.ServiceRequestID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		message := "notification.sos_resumed"
+		if request.Status == models.RequestStatusOnHold {
+			status := incident.PreviousStatus
+			if req.Resolution == models.SafetyResolutionCancel {
+				status = models.RequestStatusCancelled
+				message = "notification.sos_cancelled"
+				if err := StopWorkTimer(tx, &request, now); err != nil {
+					return err
+				}
+			}
+			if err := tx.Model(&request).Update("status", status).Error; err != nil {
+				return err
+			}
+		}
+
+		incident.Status = models.SafetyIncidentResolved
+		incident.Resolution = req.Resolution
+		incident.ResolutionNote = req.Note
+		incident.ResolvedByID = &adminID
+		incident.ResolvedAt = &now
+		if err := tx.Save(&incident).Error; err != nil {
+			return err
+		}
+
+		if err := enqueueSafetyNotification(tx, request.CustomerID, request, message); err != nil {
+			return err
+		}
+		if workerUserID, ok := s.otherParty(tx, request, request.CustomerID); ok {
+			return enqueueSafetyNotification(tx, workerUserID, request, message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// otherParty returns the user on the request other than userID: the assigned worker's user for the
+// customer, and the customer for the worker
+func (s *SafetyService) otherParty(tx *gorm.DB, request models.CustomerServiceRequest, userID uint) (uint, bool) {
+	if userID != request.CustomerID {
+		return request.CustomerID, true
+	}
+	if request.AssignedWorkerID == nil {
+		return 0, false
+	}
+	var worker models.WorkerProfile
+	if err := tx.Select("id", "user_id").First(&worker, *request.AssignedWorkerID).Error; err != nil {
+		return 0, false
+	}
+	return worker.UserID, true
+}
+
+// isSOSStatus reports whether an SOS may be raised on a request in status
+func isSOSStatus(status models.CustomerServiceRequestStatus) bool {
+	for _, s := range sosStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueueSafetyNotification pushes a safety message about request to userID
+func enqueueSafetyNotification(tx *gorm.DB, userID uint, request models.CustomerServiceRequest, message string) error {
+	return EnqueueOutboxEvent(tx, models.OutboxEventPushNotification, request.ID, models.PushNotificationPayload{
+		UserID:  userID,
+		Message: message,
+		Vars:    map[string]interface{}{"title": request.Title},
+		Type:    "safety",
+		Data: map[string]interface{}{
+			"action":             "safety",
+			"service_request_id": request.ID,
+		},
+	})
+}
+
+// sosContactText is the SMS to the reporter's emergency contact, in the reporter's language, with a
+// map link to where the SOS was raised
+func sosContactText(reporter models.User, request models.CustomerServiceRequest, incident *models.SafetyIncident) string {
+	location := ""
+	if incident.Lat != nil && incident.Lng != nil {
+		location = fmt.Sprintf("https://maps.google.com/?q=%.6f,%.6f", *incident.Lat, *incident.Lng)
+	}
+	address := request.LocationAddress
+	if address == "" {
+		address = request.LocationCity
+	}
+	return i18n.T(UserLanguage(reporter.ID), "sms.sos_contact", i18n.Vars{
+		"name":     reporter.FullName,
+		"address":  address,
+		"location": location,
+	})
+}
+
+// List returns incidents newest first, only those in status when set
+func (s *SafetyService) List(status models.SafetyIncidentStatus, offset, limit int) ([]models.SafetyIncident, int64, error) {
+	query := s.db.Model(&models.SafetyIncident{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	incidents := []models.SafetyIncident{}
+	err := query.Preload("ServiceRequest").Preload("ReportedBy").
+		Order("created_at DESC, id DESC").Offset(offset).Limit(limit).Find(&incidents).Error
+	return incidents, total, err
+}
+
+// Find loads an incident with its request, the request's parties and the reporter
+func (s *SafetyService) Find(incidentID uint) (*models.SafetyIncident, error) {
+	var incident models.SafetyIncident
+	err := s.db.Preload("ServiceRequest.Customer").Preload("ServiceRequest.AssignedWorker.User").Preload("ReportedBy").
+		First(&incident, incidentID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"repair-service-server/models"
+)
+
+// ErrScheduleInPast is returned for scheduled times that are not in the future
+var ErrScheduleInPast = errors.New("scheduled time is in the past")
+
+// localScheduleLayouts are the accepted wall clock times without a UTC offset
+var localScheduleLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04"}
+
+// ParseScheduledFor reads the time a customer scheduled a request for. An RFC3339 time keeps its
+// UTC offset; a wall clock time such as 2026-10-17T09:00 is read in timezone, an IANA name, or in
+// the region's time zone when timezone is empty. The time is returned at the customer's offset,
+// for CustomerServiceRequest.ScheduleAt, and must be in the future.
+func ParseScheduledFor(value, timezone string, region models.Region) (time.Time, error) {
+	loc := region.Location()
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return time.Time{}, ErrInvalidTimezone
+		}
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	for _, layout := range localScheduleLayouts {
+		if err == nil {
+			break
+		}
+		at, err = time.ParseInLocation(layout, value, loc)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !at.After(time.Now()) {
+		return time.Time{}, ErrScheduleInPast
+	}
+	return at, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+)
+
+// Kinds of search results
+const (
+	SearchTypeService = "service"
+	SearchTypeWorker  = "worker"
+)
+
+// searchSimilarityThreshold is how close a misspelled word must be to a name or skill to match.
+// pg_trgm's default of 0.6 misses most typos in short words.
+const searchSimilarityThreshold = 0.4
+
+// SearchHit is a ranked search match, loaded by the caller
+type SearchHit struct {
+	Type string  `json:"type"`
+	ID   uint    `json:"id"`
+	Rank float64 `json:"rank"`
+}
+
+// SearchService finds services and workers matching free text
+type SearchService struct{}
+
+// NewSearchService creates a new search service
+func NewSearchService() *SearchService {
+	return &SearchService{}
+}
+
+// searchQuery is the text query stemmed in French and in Arabic, matching either
+const searchQuery = `(websearch_to_tsquery('french', @term) || websearch_to_tsquery('arabic', @term))`
+
+// Search returns up to limit services and workers matching term, best first. Words are matched
+// after French or Arabic stemming, and names and skills also match misspelled words by trigram
+// similarity. types restricts the kinds of results; empty means all.
+func (s *SearchService) Search(term string, types []string, limit int) ([]SearchHit, error) {
+	wants := func(kind string) bool {
+		if len(types) == 0 {
+			return true
+		}
+		for _, t := range types {
+			if t == kind {
+				return true
+			}
+		}
+		return false
+	}
+	args := map[string]interface{}{"term": term, "limit": limit}
+
+	var hits []SearchHit
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", searchSimilarityThreshold).Error; err != nil {
+			return err
+		}
+
+		if wants(SearchTypeService) {
+			var services []SearchHit
+			if err := tx.Raw(`SELECT 'service' AS type, id,
+					ts_rank_cd(search_vector, `+searchQuery+`) + GREATEST(word_similarity(@term, name), word_similarity(@term, name_ar)) AS rank
+				FROM services
+				WHERE is_active AND deleted_at IS NULL
+					AND (search_vector @@ `+searchQuery+` OR @term <% name OR @term <% name_ar)
+				ORDER BY rank DESC, id
+				LIMIT @limit`, args).Scan(&services).Error; err != nil {
+				return err
+			}
+			hits = append(hits, services...)
+		}
+
+		if wants(SearchTypeWorker) {
+			var workers []SearchHit
+			if err := tx.Raw(`SELECT 'worker' AS type, worker_profiles.id,
+					ts_rank_cd(worker_profiles.search_vector, `+searchQuery+`) + GREATEST(word_similarity(@term, worker_profiles.skills), word_similarity(@term, users.full_name)) AS rank
+				FROM worker_profiles
+				JOIN users ON users.id = worker_profiles.user_id AND users.is_active
+				WHERE worker_profiles.deleted_at IS NULL
+					AND (worker_profiles.search_vector @@ `+searchQuery+` OR @term <% worker_profiles.skills OR @term <% users.full_name)
+				ORDER BY rank DESC, worker_profiles.id
+				LIMIT @limit`, args).Scan(&workers).Error; err != nil {
+				return err
+			}
+			hits = append(hits, workers...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Rank > hits[j].Rank })
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
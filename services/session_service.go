@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// ErrSessionNotFound is returned when a session does not exist or belongs to another user
+var ErrSessionNotFound = errors.New("session not found")
+
+// SessionService lists and revokes a user's signed-in devices. A session is an unrevoked,
+// unexpired refresh token; access tokens carry its ID and are rejected once it is revoked.
+type SessionService struct{}
+
+// NewSessionService creates a new session service
+func NewSessionService() *SessionService {
+	return &SessionService{}
+}
+
+// ActiveSessions returns the user's sessions, most recently used first
+func (s *SessionService) ActiveSessions(userID uint) ([]models.RefreshToken, error) {
+	var sessions []models.RefreshToken
+	err := database.DB.Where("user_id = ? AND is_revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("last_used_at DESC NULLS LAST, created_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke signs out one session and stops push notifications to its device
+func (s *SessionService) Revoke(userID, sessionID uint) (*models.RefreshToken, error) {
+	var session models.RefreshToken
+	err := database.DB.Where("id = ? AND user_id = ? AND is_revoked = ?", sessionID, userID, false).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&session).Update("is_revoked", true).Error; err != nil {
+			return err
+		}
+		if session.DeviceID == "" {
+			return nil
+		}
+		// Other sessions on the same device still receive the device's notifications
+		var others int64
+		if err := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND device_id = ? AND is_revoked = ? AND expires_at > ?", userID, session.DeviceID, false, time.Now()).
+			Count(&others).Error; err != nil {
+			return err
+		}
+		if others > 0 {
+			return nil
+		}
+		return tx.Model(&models.PushToken{}).
+			Where("user_id = ? AND device_id = ?", userID, session.DeviceID).
+			Update("active", false).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	session.IsRevoked = true
+	log.Printf("✅ Session %d revoked for user %d", session.ID, userID)
+	return &session, nil
+}
+
+// RevokeAll signs the user out on every device and deactivates all of their push tokens
+func (s *SessionService) RevokeAll(userID uint) (int64, error) {
+	var revoked int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND is_revoked = ?", userID, false).
+			Update("is_revoked", true)
+		if result.Error != nil {
+			return result.Error
+		}
+		revoked = result.RowsAffected
+
+		// Access tokens outlive their refresh token, so every one issued so far is cut off too
+		if err := BumpTokenVersion(tx, userID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.PushToken{}).Where("user_id = ?", userID).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.UserDeviceToken{}).Where("user_id = ?", userID).Update("is_active", false).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("✅ User %d signed out everywhere (%d sessions)", userID, revoked)
+	return revoked, nil
+}
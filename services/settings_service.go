@@ -0,0 +1,171 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// Platform setting keys
+const (
+	SettingTipMinAmount = "tip_min_amount" // Smallest tip a customer can give, in MRU
+	SettingTipMaxAmount = "tip_max_amount" // Largest tip a customer can give, in MRU
+
+	SettingLoyaltyPointsPerRequest      = "loyalty_points_per_request"      // Points a customer earns per completed request
+	SettingLoyaltySilverPoints          = "loyalty_silver_points"           // Points over the last 12 months needed for silver
+	SettingLoyaltyGoldPoints            = "loyalty_gold_points"             // Points over the last 12 months needed for gold
+	SettingLoyaltySilverDiscountPercent = "loyalty_silver_discount_percent" // Discount on service option prices for silver customers
+	SettingLoyaltyGoldDiscountPercent   = "loyalty_gold_discount_percent"   // Discount on service option prices for gold customers
+
+	SettingWorkerCommissionPercent = "worker_commission_percent" // Platform commission on each completed service's final price
+
+	SettingNoShowEnRouteMinutes     = "no_show_en_route_minutes"    // Time after acceptance, or the scheduled time, to set off; 0 disables
+	SettingNoShowStartMinutes       = "no_show_start_minutes"       // Time after acceptance, or the scheduled time, to start the job; 0 disables
+	SettingNoShowReliabilityPenalty = "no_show_reliability_penalty" // Points taken off the worker's 0-100 reliability score per no-show
+	SettingLateArrivalMinutes       = "late_arrival_minutes"        // Time after acceptance, or the scheduled time, to arrive before it counts as late; 0 disables
+
+	SettingReliabilityCancellationPoints       = "reliability_cancellation_points"         // Points off for giving up a job after setting off
+	SettingReliabilityDeclineAfterAcceptPoints = "reliability_decline_after_accept_points" // Points off for giving up a job before setting off
+	SettingReliabilityLateArrivalPoints        = "reliability_late_arrival_points"         // Points off for arriving late
+	SettingReliabilityWarningScore             = "reliability_warning_score"               // Below this the worker is warned
+	SettingReliabilityReducedScore             = "reliability_reduced_score"               // Below this the worker sees new broadcasts last
+	SettingReliabilitySuspendScore             = "reliability_suspend_score"               // Below this the worker is suspended
+	SettingReliabilitySuspensionDays           = "reliability_suspension_days"             // Length of an automatic suspension
+
+	SettingUrgentBroadcastRadiusMultiplier = "urgent_broadcast_radius_multiplier" // How much farther than the zone's radius urgent requests are broadcast
+)
+
+// settingDefaults lists every setting admins can change, with the value used until they do
+var settingDefaults = map[string]float64{
+	SettingTipMinAmount: 10,
+	SettingTipMaxAmount: 5000,
+
+	SettingLoyaltyPointsPerRequest:      100,
+	SettingLoyaltySilverPoints:          1000,
+	SettingLoyaltyGoldPoints:            3000,
+	SettingLoyaltySilverDiscountPercent: 5,
+	SettingLoyaltyGoldDiscountPercent:   10,
+
+	SettingWorkerCommissionPercent: 0,
+
+	SettingNoShowEnRouteMinutes:     30,
+	SettingNoShowStartMinutes:       90,
+	SettingNoShowReliabilityPenalty: 25,
+	SettingLateArrivalMinutes:       45,
+
+	SettingReliabilityCancellationPoints:       15,
+	SettingReliabilityDeclineAfterAcceptPoints: 10,
+	SettingReliabilityLateArrivalPoints:        5,
+	SettingReliabilityWarningScore:             80,
+	SettingReliabilityReducedScore:             60,
+	SettingReliabilitySuspendScore:             40,
+	SettingReliabilitySuspensionDays:           7,
+
+	SettingUrgentBroadcastRadiusMultiplier: 2,
+}
+
+// ErrInvalidSetting wraps every rejected settings update
+var ErrInvalidSetting = errors.New("invalid setting")
+
+// SettingsService reads and updates admin-editable platform settings
+type SettingsService struct{}
+
+// NewSettingsService creates a new settings service
+func NewSettingsService() *SettingsService {
+	return &SettingsService{}
+}
+
+// All returns every known setting with its current value
+func (s *SettingsService) All() (map[string]float64, error) {
+	var stored []models.PlatformSetting
+	if err := database.DB.Find(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]float64, len(settingDefaults))
+	for key, def := range settingDefaults {
+		values[key] = def
+	}
+	for _, setting := range stored {
+		if _, known := settingDefaults[setting.Key]; !known {
+			continue
+		}
+		if v, err := strconv.ParseFloat(setting.Value, 64); err == nil {
+			values[setting.Key] = v
+		}
+	}
+	return values, nil
+}
+
+// Float returns the current value of key, falling back to its default
+func (s *SettingsService) Float(key string) float64 {
+	var setting models.PlatformSetting
+	if err := database.DB.Where("key = ?", key).First(&setting).Error; err == nil {
+		if v, err := strconv.ParseFloat(setting.Value, 64); err == nil {
+			return v
+		}
+	}
+	return settingDefaults[key]
+}
+
+// Update stores values on behalf of adminID. Unknown keys are rejected and nothing is written
+// unless every value is valid.
+func (s *SettingsService) Update(values map[string]float64, adminID uint) error {
+	for key, v := range values {
+		if _, known := settingDefaults[key]; !known {
+			return fmt.Errorf("%w: unknown setting %q", ErrInvalidSetting, key)
+		}
+		if v < 0 {
+			return fmt.Errorf("%w: %s must not be negative", ErrInvalidSetting, key)
+		}
+	}
+
+	current, err := s.All()
+	if err != nil {
+		return err
+	}
+	for key, v := range values {
+		current[key] = v
+	}
+	if current[SettingTipMinAmount] > current[SettingTipMaxAmount] {
+		return fmt.Errorf("%w: %s must not exceed %s", ErrInvalidSetting, SettingTipMinAmount, SettingTipMaxAmount)
+	}
+	if current[SettingLoyaltySilverPoints] >= current[SettingLoyaltyGoldPoints] {
+		return fmt.Errorf("%w: %s must be below %s", ErrInvalidSetting, SettingLoyaltySilverPoints, SettingLoyaltyGoldPoints)
+	}
+	if current[SettingReliabilitySuspendScore] >= current[SettingReliabilityReducedScore] || current[SettingReliabilityReducedScore] >= current[SettingReliabilityWarningScore] {
+		return fmt.Errorf("%w: %s, %s and %s must be increasing", ErrInvalidSetting, SettingReliabilitySuspendScore, SettingReliabilityReducedScore, SettingReliabilityWarningScore)
+	}
+	if current[SettingUrgentBroadcastRadiusMultiplier] < 1 {
+		return fmt.Errorf("%w: %s must be at least 1", ErrInvalidSetting, SettingUrgentBroadcastRadiusMultiplier)
+	}
+	for _, key := range []string{SettingLoyaltySilverDiscountPercent, SettingLoyaltyGoldDiscountPercent, SettingWorkerCommissionPercent, SettingNoShowReliabilityPenalty,
+		SettingReliabilityCancellationPoints, SettingReliabilityDeclineAfterAcceptPoints, SettingReliabilityLateArrivalPoints, SettingReliabilityWarningScore} {
+		if current[key] > 100 {
+			return fmt.Errorf("%w: %s must not exceed 100", ErrInvalidSetting, key)
+		}
+	}
+
+	now := time.Now()
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for key, v := range values {
+			setting := models.PlatformSetting{
+				Key:       key,
+				Value:     strconv.FormatFloat(v, 'f', -1, 64),
+				UpdatedBy: &adminID,
+				UpdatedAt: now,
+			}
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&setting).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
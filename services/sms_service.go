@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// defaultSMSFallbackEvents are the notification types texted when they cannot be pushed: a worker
+// accepted the request, the worker arrived, or the request was cancelled
+var defaultSMSFallbackEvents = []string{"booking_accepted", "booking_arrived", "booking_cancelled"}
+
+// SMSService sends SMS through the configured provider and records every message with its cost
+type SMSService struct {
+	provider SMSProvider
+}
+
+// NewSMSService creates an SMS service using the configured SMS provider
+func NewSMSService() *SMSService {
+	return &SMSService{provider: NewSMSProvider()}
+}
+
+// Name returns the underlying provider's name
+func (s *SMSService) Name() string {
+	return s.provider.Name()
+}
+
+// Send texts body to the E.164 number to and records the message. userID is nil for numbers not
+// tied to an account yet. A failure to record is logged but does not fail the send.
+func (s *SMSService) Send(userID *uint, to, body string, purpose models.SMSPurpose, notificationType string) error {
+	sendErr := s.provider.Send(to, body)
+
+	segments := SMSSegments(body)
+	record := models.SMSMessage{
+		UserID:           userID,
+		PhoneNumber:      to,
+		Purpose:          purpose,
+		NotificationType: notificationType,
+		Provider:         s.provider.Name(),
+		Status:           models.SMSStatusSent,
+		Segments:         segments,
+		Cost:             float64(segments) * SMSCostPerSegment(),
+	}
+	if sendErr != nil {
+		record.Status = models.SMSStatusFailed
+		record.Cost = 0
+		record.Error = sendErr.Error()
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		log.Printf("⚠️ Failed to record SMS to %s: %v", to, err)
+	}
+	return sendErr
+}
+
+// SMSCostPerSegment is the provider price of one SMS segment, configurable through
+// SMS_COST_PER_SEGMENT
+func SMSCostPerSegment() float64 {
+	if cost, err := strconv.ParseFloat(os.Getenv("SMS_COST_PER_SEGMENT"), 64); err == nil && cost >= 0 {
+		return cost
+	}
+	return 0
+}
+
+// SMSSegments estimates how many segments body is billed as: 160 characters for plain ASCII
+// (153 per part when split) and 70 for anything else (67 per part)
+func SMSSegments(body string) int {
+	single, multi := 160, 153
+	length := len(body)
+	for _, r := range body {
+		if r > 127 {
+			single, multi = 70, 67
+			length = len(utf16.Encode([]rune(body)))
+			break
+		}
+	}
+	if length <= single {
+		return 1
+	}
+	return (length + multi - 1) / multi
+}
+
+// SMSFallbackEnabled reports whether notificationType is texted when push delivery is impossible.
+// SMS_FALLBACK_EVENTS overrides the default list with comma-separated types, or "none".
+func SMSFallbackEnabled(notificationType string) bool {
+	events := defaultSMSFallbackEvents
+	if configured := strings.TrimSpace(os.Getenv("SMS_FALLBACK_EVENTS")); configured != "" {
+		events = strings.Split(configured, ",")
+	}
+	for _, event := range events {
+		if strings.TrimSpace(event) == notificationType {
+			return true
+		}
+	}
+	return false
+}
+
+// SMSUsage is the SMS volume and cost for one purpose and notification type
+type SMSUsage struct {
+	Purpose          models.SMSPurpose `json:"purpose"`
+	NotificationType string            `json:"notification_type"`
+	Messages         int64             `json:"messages"`
+	Failed           int64             `json:"failed"`
+	Segments         int64             `json:"segments"`
+	Cost             float64           `json:"cost"`
+}
+
+// Usage totals the messages sent from from (inclusive) to to (exclusive)
+func (s *SMSService) Usage(from, to time.Time) ([]SMSUsage, error) {
+	usage := []SMSUsage{}
+	err := database.DB.Model(&models.SMSMessage{}).
+		Select("purpose, COALESCE(notification_type, '') AS notification_type, COUNT(*) AS messages, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS failed, COALESCE(SUM(segments), 0) AS segments, COALESCE(SUM(cost), 0) AS cost", models.SMSStatusFailed).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("purpose, notification_type").
+		Order("cost DESC").
+		Scan(&usage).Error
+	return usage, err
+}
+
+// SMSProvider is implemented by every SMS backend
+type SMSProvider interface {
+	Name() string
+	Send(to, body string) error
+}
+
+// NewSMSProvider returns the provider selected by SMS_PROVIDER ("twilio" or "gateway"). Without
+// a configured provider messages are only logged, which is what local development relies on.
+func NewSMSProvider() SMSProvider {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	switch strings.ToLower(os.Getenv("SMS_PROVIDER")) {
+	case "twilio":
+		sid, token, from := os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_FROM_NUMBER")
+		if sid == "" || token == "" || from == "" {
+			log.Printf("⚠️ Twilio credentials not set, SMS will only be logged")
+			return &LogSMSProvider{}
+		}
+		return &TwilioProvider{accountSID: sid, authToken: token, from: from, client: client}
+	case "gateway":
+		gatewayURL := os.Getenv("SMS_GATEWAY_URL")
+		if gatewayURL == "" {
+			log.Printf("⚠️ SMS_GATEWAY_URL not set, SMS will only be logged")
+			return &LogSMSProvider{}
+		}
+		return &GatewayProvider{
+			url:    gatewayURL,
+			apiKey: os.Getenv("SMS_GATEWAY_API_KEY"),
+			sender: os.Getenv("SMS_GATEWAY_SENDER"),
+			client: client,
+		}
+	default:
+		return &LogSMSProvider{}
+	}
+}
+
+// TwilioProvider sends SMS through the Twilio Messages API
+type TwilioProvider struct {
+	accountSID string
+	authToken  string
+	from       string
+	client     *http.Client
+}
+
+// Name returns the provider name
+func (p *TwilioProvider) Name() string {
+	return "twilio"
+}
+
+// Send delivers body to the E.164 number to
+func (p *TwilioProvider) Send(to, body string) error {
+	apiURL := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", p.accountSID)
+	form := url.Values{"To": {to}, "From": {p.from}, "Body": {body}}
+
+	req, err := http.NewRequest(http.MethodPost, apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return doSMSRequest(p.client, req)
+}
+
+// GatewayProvider sends SMS through a local Mauritanian operator gateway that accepts
+// {"to", "message", "sender"} as JSON and authenticates with a bearer API key
+type GatewayProvider struct {
+	url    string
+	apiKey string
+	sender string
+	client *http.Client
+}
+
+// Name returns the provider name
+func (p *GatewayProvider) Name() string {
+	return "gateway"
+}
+
+// Send delivers body to the E.164 number to
+func (p *GatewayProvider) Send(to, body string) error {
+	payload, err := json.Marshal(map[string]string{"to": to, "message": body, "sender": p.sender})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	return doSMSRequest(p.client, req)
+}
+
+// LogSMSProvider writes messages to the server log instead of sending them
+type LogSMSProvider struct{}
+
+// Name returns the provider name
+func (p *LogSMSProvider) Name() string {
+	return "log"
+}
+
+// Send logs the message
+func (p *LogSMSProvider) Send(to, body string) error {
+	log.Printf("📱 SMS to %s: %s", to, body)
+	return nil
+}
+
+// doSMSRequest performs req and treats any non-2xx status as a failure
+func doSMSRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("sms provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
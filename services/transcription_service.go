@@ -0,0 +1,276 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Transcription errors
+var (
+	ErrTranscriptionDisabled = errors.New("speech-to-text is not configured")
+	ErrUnsupportedAudio      = errors.New("audio format not supported by the speech-to-text provider")
+)
+
+// maxVoiceBytes is the largest voice recording accepted, the same limit as chat voice messages
+const maxVoiceBytes = 10 << 20
+
+// audioExtensions maps the MIME types apps record in to file extensions providers recognize
+var audioExtensions = map[string]string{
+	"audio/mp4":   ".m4a",
+	"audio/m4a":   ".m4a",
+	"audio/x-m4a": ".m4a",
+	"audio/aac":   ".m4a",
+	"audio/mpeg":  ".mp3",
+	"audio/mp3":   ".mp3",
+	"audio/wav":   ".wav",
+	"audio/x-wav": ".wav",
+	"audio/webm":  ".webm",
+	"audio/ogg":   ".ogg",
+	"audio/flac":  ".flac",
+}
+
+// DecodeBase64Audio decodes a base64 voice recording as sent over the AI WebSocket. The returned
+// filename carries the format from the data: URL prefix, defaulting to M4A which the mobile app
+// records in.
+func DecodeBase64Audio(encoded string) ([]byte, string, error) {
+	audio, mimeType, err := decodeBase64Data(encoded, maxVoiceBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid voice data: %v", err)
+	}
+	ext, ok := audioExtensions[mimeType]
+	if !ok {
+		ext = ".m4a"
+	}
+	return audio, "voice" + ext, nil
+}
+
+// Transcriber is implemented by every speech-to-text backend
+type Transcriber interface {
+	Name() string
+	// Transcribe returns the text spoken in audio. filename carries the format, e.g. "note.m4a",
+	// and language is a hint ("fr", "ar" or "en") that may be empty.
+	Transcribe(audio []byte, filename, language string) (string, error)
+}
+
+// NewTranscriber returns the provider selected by STT_PROVIDER ("whisper" or "google").
+// Without a configured provider voice messages are stored without a transcript.
+func NewTranscriber() Transcriber {
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	switch strings.ToLower(os.Getenv("STT_PROVIDER")) {
+	case "whisper":
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			log.Printf("⚠️ OPENAI_API_KEY not set, voice messages will not be transcribed")
+			return &NoTranscriber{}
+		}
+		return &WhisperTranscriber{apiKey: apiKey, client: client}
+	case "google":
+		apiKey := os.Getenv("GOOGLE_STT_API_KEY")
+		if apiKey == "" {
+			log.Printf("⚠️ GOOGLE_STT_API_KEY not set, voice messages will not be transcribed")
+			return &NoTranscriber{}
+		}
+		return &GoogleTranscriber{apiKey: apiKey, client: client}
+	default:
+		return &NoTranscriber{}
+	}
+}
+
+// WhisperTranscriber transcribes audio with the OpenAI Whisper API
+type WhisperTranscriber struct {
+	apiKey string
+	client *http.Client
+}
+
+// Name returns the provider name
+func (t *WhisperTranscriber) Name() string {
+	return "whisper"
+}
+
+// Transcribe uploads audio to the transcriptions endpoint
+func (t *WhisperTranscriber) Transcribe(audio []byte, filename, language string) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(filename))
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(audio); err != nil {
+		return "", err
+	}
+	writer.WriteField("model", "whisper-1")
+	if language != "" {
+		writer.WriteField("language", language)
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.openai.com/v1/audio/transcriptions", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := doTranscriptionRequest(t.client, req, &result); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// GoogleTranscriber transcribes audio with Google Cloud Speech-to-Text. Its synchronous API
+// takes MP3, WAV and FLAC but not the M4A files phones usually record.
+type GoogleTranscriber struct {
+	apiKey string
+	client *http.Client
+}
+
+// Name returns the provider name
+func (t *GoogleTranscriber) Name() string {
+	return "google"
+}
+
+// googleEncodings maps file extensions to Speech-to-Text encodings
+var googleEncodings = map[string]string{
+	".mp3":  "MP3",
+	".wav":  "LINEAR16",
+	".flac": "FLAC",
+}
+
+// googleLanguages maps the app's languages to Speech-to-Text language codes
+var googleLanguages = map[string]string{
+	"fr": "fr-FR",
+	"ar": "ar-MA", // Closest supported variant to Hassaniya
+	"en": "en-US",
+}
+
+// Transcribe sends audio to the recognize endpoint
+func (t *GoogleTranscriber) Transcribe(audio []byte, filename, language string) (string, error) {
+	encoding, ok := googleEncodings[strings.ToLower(filepath.Ext(filename))]
+	if !ok {
+		return "", ErrUnsupportedAudio
+	}
+	languageCode, ok := googleLanguages[language]
+	if !ok {
+		languageCode = "fr-FR"
+	}
+
+	config := map[string]interface{}{
+		"encoding":                   encoding,
+		"languageCode":               languageCode,
+		"alternativeLanguageCodes":   []string{"ar-MA", "en-US"},
+		"enableAutomaticPunctuation": true,
+	}
+	// WAV and FLAC carry their sample rate in a header Google reads; MP3 must be told
+	if encoding == "MP3" {
+		rate := mp3SampleRate(audio)
+		if rate == 0 {
+			return "", ErrUnsupportedAudio
+		}
+		config["sampleRateHertz"] = rate
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"config": config,
+		"audio":  map[string]string{"content": base64.StdEncoding.EncodeToString(audio)},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://speech.googleapis.com/v1p1beta1/speech:recognize?key="+t.apiKey, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		Results []struct {
+			Alternatives []struct {
+				Transcript string `json:"transcript"`
+			} `json:"alternatives"`
+		} `json:"results"`
+	}
+	if err := doTranscriptionRequest(t.client, req, &result); err != nil {
+		return "", err
+	}
+
+	var transcript []string
+	for _, r := range result.Results {
+		if len(r.Alternatives) > 0 {
+			transcript = append(transcript, strings.TrimSpace(r.Alternatives[0].Transcript))
+		}
+	}
+	return strings.Join(transcript, " "), nil
+}
+
+// mp3SampleRates lists the sample rates of each MPEG version by the header's rate index
+var mp3SampleRates = map[byte][3]int{
+	3: {44100, 48000, 32000}, // MPEG-1
+	2: {22050, 24000, 16000}, // MPEG-2
+	0: {11025, 12000, 8000},  // MPEG-2.5
+}
+
+// mp3SampleRate reads the sample rate from the first frame header of an MP3, skipping an ID3v2
+// tag if there is one. It returns 0 when no frame is found.
+func mp3SampleRate(data []byte) int {
+	start := 0
+	if len(data) >= 10 && string(data[:3]) == "ID3" {
+		// The tag size is stored in four 7-bit bytes
+		start = 10 + (int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9]))
+	}
+	for i := start; i+2 < len(data); i++ {
+		if data[i] != 0xFF || data[i+1]&0xE0 != 0xE0 {
+			continue
+		}
+		rates, ok := mp3SampleRates[(data[i+1]>>3)&0x03]
+		index := (data[i+2] >> 2) & 0x03
+		if ok && index < 3 {
+			return rates[index]
+		}
+	}
+	return 0
+}
+
+// NoTranscriber is used when no provider is configured
+type NoTranscriber struct{}
+
+// Name returns the provider name
+func (t *NoTranscriber) Name() string {
+	return "none"
+}
+
+// Transcribe always fails with ErrTranscriptionDisabled
+func (t *NoTranscriber) Transcribe(audio []byte, filename, language string) (string, error) {
+	return "", ErrTranscriptionDisabled
+}
+
+// doTranscriptionRequest performs req and decodes a 2xx JSON response into out
+func doTranscriptionRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("speech-to-text provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
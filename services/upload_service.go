@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/config"
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+const (
+	// UploadSessionTTL is how long a client has to finish a resumable upload
+	UploadSessionTTL = 24 * time.Hour
+	// UploadChunkSize is the chunk size clients should send; Cloudinary needs at least 5MB for
+	// every chunk but the last
+	UploadChunkSize = 6 << 20
+	// uploadSignatureTTL is how long Cloudinary accepts a signature; clients re-sign to resume later
+	uploadSignatureTTL = time.Hour
+)
+
+// videoFormats maps the container formats accepted for direct video uploads to their MIME type
+var videoFormats = map[string]string{
+	"mp4":  "video/mp4",
+	"mov":  "video/quicktime",
+	"webm": "video/webm",
+}
+
+var (
+	// ErrUploadNotAllowed is returned when the caller cannot attach a video where they asked to
+	ErrUploadNotAllowed = errors.New("you cannot upload a video here")
+	// ErrUploadLimit is returned when the portfolio or the request already holds as many videos as allowed
+	ErrUploadLimit = errors.New("no more videos can be added here")
+	// ErrUploadSessionClosed is returned for sessions that are no longer pending
+	ErrUploadSessionClosed = errors.New("upload session is no longer open")
+	// ErrUploadIncomplete is returned when Cloudinary does not have the whole file yet
+	ErrUploadIncomplete = errors.New("the upload has not finished yet")
+	// ErrUploadRejected is returned when the uploaded file failed verification and was deleted
+	ErrUploadRejected = errors.New("the uploaded file was rejected")
+	// ErrInvalidUploadWebhook is returned for notifications that are not signed by Cloudinary
+	ErrInvalidUploadWebhook = errors.New("invalid upload notification")
+)
+
+// UploadTicket is what a client needs to send chunks straight to Cloudinary. Every chunk is a
+// multipart POST of Params and the chunk as "file" to UploadURL, with the X-Unique-Upload-Id and
+// Content-Range headers; a failed chunk is sent again.
+type UploadTicket struct {
+	UploadURL      string            `json:"upload_url"`
+	UniqueUploadID string            `json:"unique_upload_id"`
+	ChunkSize      int               `json:"chunk_size"`
+	Params         map[string]string `json:"params"`
+	SignedUntil    time.Time         `json:"signed_until"`
+}
+
+// UploadService runs resumable uploads straight to Cloudinary and attaches the verified files
+type UploadService struct {
+	db *gorm.DB
+}
+
+// NewUploadService creates a new upload service
+func NewUploadService() *UploadService {
+	return &UploadService{
+		db: database.DB,
+	}
+}
+
+// Start opens an upload session for owner after checking they may add a video there
+func (s *UploadService) Start(owner models.User, req models.UploadSessionCreateRequest) (*models.UploadSession, *UploadTicket, error) {
+	kind := mediaKinds[req.Purpose]
+	if req.Size > kind.maxBytes {
+		return nil, nil, ErrMediaTooLarge
+	}
+
+	session := &models.UploadSession{
+		OwnerID:      owner.ID,
+		Purpose:      req.Purpose,
+		Caption:      req.Caption,
+		DeclaredSize: req.Size,
+		Status:       models.UploadSessionPending,
+		ExpiresAt:    time.Now().Add(UploadSessionTTL),
+	}
+	switch req.Purpose {
+	case models.MediaPurposePortfolioVideo:
+		worker, err := s.portfolioWorker(owner.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		session.PublicID = fmt.Sprintf("workers/portfolio/%d/videos/%s", worker.ID, randomUploadName())
+	case models.MediaPurposeRequestVideo:
+		if _, err := s.requestUploaderRole(owner.ID, req.ServiceRequestID); err != nil {
+			return nil, nil, err
+		}
+		session.ServiceRequestID = &req.ServiceRequestID
+		session.PublicID = fmt.Sprintf("requests/%d/videos/%s", req.ServiceRequestID, randomUploadName())
+	}
+
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, nil, err
+	}
+	ticket, err := s.ticket(session)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, ticket, nil
+}
+
+// Resign returns a fresh signature for a pending session, so a client can resume after the
+// previous one expired
+func (s *UploadService) Resign(sessionID, ownerID uint) (*models.UploadSession, *UploadTicket, error) {
+	var session models.UploadSession
+	if err := s.db.Where("id = ? AND owner_id = ?", sessionID, ownerID).First(&session).Error; err != nil {
+		return nil, nil, err
+	}
+	if session.Status != models.UploadSessionPending || !time.Now().Before(session.ExpiresAt) {
+		return nil, nil, ErrUploadSessionClosed
+	}
+	ticket, err := s.ticket(&session)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &session, ticket, nil
+}
+
+// Complete verifies the file Cloudinary holds for the caller's session and attaches it. Completing
+// a completed session returns it as it is.
+func (s *UploadService) Complete(sessionID, ownerID uint) (*models.UploadSession, error) {
+	return s.complete("id = ? AND owner_id = ?", sessionID, ownerID)
+}
+
+// CompleteByPublicID is Complete for Cloudinary's upload notification
+func (s *UploadService) CompleteByPublicID(publicID string) (*models.UploadSession, error) {
+	return s.complete("public_id = ?", publicID)
+}
+
+// HandleNotification checks the signature of a Cloudinary upload notification and completes the
+// session of the video it reports. Clients that never call complete still get their upload attached.
+func (s *UploadService) HandleNotification(r *http.Request) error {
+	cld, err := NewCloudinaryClient()
+	if err != nil {
+		return err
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return ErrInvalidUploadWebhook
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get("X-Cld-Timestamp"), 10, 64)
+	if err != nil || !cld.Upload.VerifyNotificationSignature(string(body), timestamp, r.Header.Get("X-Cld-Signature"), 0) {
+		return ErrInvalidUploadWebhook
+	}
+
+	var notification struct {
+		NotificationType string `json:"notification_type"`
+		ResourceType     string `json:"resource_type"`
+		PublicID         string `json:"public_id"`
+	}
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return ErrInvalidUploadWebhook
+	}
+	if notification.NotificationType != "upload" || notification.ResourceType != "video" {
+		return nil
+	}
+
+	_, err = s.CompleteByPublicID(notification.PublicID)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, ErrUploadSessionClosed):
+		return nil // Not a resumable upload, or already expired
+	case errors.Is(err, ErrUploadRejected):
+		log.Printf("🚫 Upload %s rejected: %v", notification.PublicID, err)
+		return nil
+	}
+	return err
+}
+
+// complete verifies and attaches the upload of the session matching the condition
+func (s *UploadService) complete(query string, args ...interface{}) (*models.UploadSession, error) {
+	cld, err := NewCloudinaryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.UploadSession
+	var rejected error
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(query, args...).First(&session).Error; err != nil {
+			return err
+		}
+		switch session.Status {
+		case models.UploadSessionCompleted:
+			return nil
+		case models.UploadSessionPending:
+		default:
+			return ErrUploadSessionClosed
+		}
+
+		asset, err := cld.Admin.Asset(context.Background(), admin.AssetParams{PublicID: session.PublicID, AssetType: api.Video})
+		if err != nil {
+			return err
+		}
+		if asset.Error.Message != "" || asset.PublicID == "" {
+			return ErrUploadIncomplete
+		}
+
+		mimeType, reason := verifyUploadedVideo(session, asset)
+		now := time.Now()
+		if reason != "" {
+			if _, err := cld.Upload.Destroy(context.Background(), uploader.DestroyParams{PublicID: session.PublicID, ResourceType: "video"}); err != nil {
+				log.Printf("⚠️ Failed to delete rejected upload %s: %v", session.PublicID, err)
+			}
+			rejected = fmt.Errorf("%w: %s", ErrUploadRejected, reason)
+			return tx.Model(&session).Updates(map[string]interface{}{
+				"status":        models.UploadSessionRejected,
+				"reject_reason": reason,
+			}).Error
+		}
+
+		upload := models.MediaUpload{
+			OwnerID:    session.OwnerID,
+			Purpose:    session.Purpose,
+			MIMEType:   mimeType,
+			Size:       int64(asset.Bytes),
+			URL:        asset.SecureURL,
+			PublicID:   asset.PublicID,
+			Resource:   "video",
+			ScanStatus: models.MediaScanSkipped,
+		}
+		if err := tx.Create(&upload).Error; err != nil {
+			return err
+		}
+		if err := s.attach(tx, session, upload); err != nil {
+			return err
+		}
+		session.MediaUpload = &upload
+		return tx.Model(&session).Updates(map[string]interface{}{
+			"status":          models.UploadSessionCompleted,
+			"media_upload_id": upload.ID,
+			"completed_at":    now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rejected != nil {
+		return &session, rejected
+	}
+	if session.MediaUpload == nil && session.MediaUploadID != nil {
+		s.db.First(&session.MediaUpload, *session.MediaUploadID)
+	}
+	return &session, nil
+}
+
+// attach adds a verified upload to the portfolio or the request it was made for
+func (s *UploadService) attach(tx *gorm.DB, session models.UploadSession, upload models.MediaUpload) error {
+	switch session.Purpose {
+	case models.MediaPurposePortfolioVideo:
+		worker, err := s.portfolioWorker(session.OwnerID)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.WorkerPortfolioPhoto{
+			WorkerID: worker.ID,
+			Kind:     models.PortfolioVideo,
+			URL:      upload.URL,
+			PublicID: upload.PublicID,
+			Caption:  session.Caption,
+		}).Error
+	case models.MediaPurposeRequestVideo:
+		role, err := s.requestUploaderRole(session.OwnerID, *session.ServiceRequestID)
+		if err != nil {
+			return err
+		}
+		return tx.Create(&models.RequestAttachment{
+			ServiceRequestID: *session.ServiceRequestID,
+			UploadedByID:     session.OwnerID,
+			UploaderRole:     role,
+			MediaUploadID:    upload.ID,
+			URL:              upload.URL,
+		}).Error
+	}
+	return fmt.Errorf("unknown upload purpose %q", session.Purpose)
+}
+
+// ExpireStale closes pending sessions past their deadline and deletes whatever was uploaded. It
+// returns how many were expired.
+func (s *UploadService) ExpireStale(now time.Time) (int, error) {
+	var sessions []models.UploadSession
+	if err := s.db.Where("status = ? AND expires_at <= ?", models.UploadSessionPending, now).Find(&sessions).Error; err != nil {
+		return 0, err
+	}
+	var cld *cloudinary.Cloudinary
+	if len(sessions) > 0 {
+		cld, _ = NewCloudinaryClient()
+	}
+
+	expired := 0
+	for _, session := range sessions {
+		result := s.db.Model(&models.UploadSession{}).
+			Where("id = ? AND status = ?", session.ID, models.UploadSessionPending).
+			Update("status", models.UploadSessionExpired)
+		if result.Error != nil {
+			return expired, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue // Completed meanwhile
+		}
+		expired++
+		if cld != nil {
+			// Also discards the chunks of an upload that never finished
+			if _, err := cld.Upload.Destroy(context.Background(), uploader.DestroyParams{PublicID: session.PublicID, ResourceType: "video"}); err != nil {
+				log.Printf("⚠️ Failed to delete expired upload %s: %v", session.PublicID, err)
+			}
+		}
+	}
+	return expired, nil
+}
+
+// Attachments lists the videos attached to a request, oldest first
+func (s *UploadService) Attachments(requestID uint) ([]models.RequestAttachment, error) {
+	attachments := []models.RequestAttachment{}
+	err := s.db.Where("service_request_id = ?", requestID).Order("created_at ASC").Find(&attachments).Error
+	return attachments, err
+}
+
+// ticket signs the upload parameters for session. Clients cannot change the public ID, the
+// accepted formats or the notification URL without breaking the signature.
+func (s *UploadService) ticket(session *models.UploadSession) (*UploadTicket, error) {
+	cld, err := NewCloudinaryClient()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	params := url.Values{
+		"public_id":       {session.PublicID},
+		"timestamp":       {strconv.FormatInt(now.Unix(), 10)},
+		"allowed_formats": {strings.Join(videoFormatNames(), ",")},
+	}
+	if notificationURL := config.AppConfig.Cloudinary.NotificationURL; notificationURL != "" {
+		params.Set("notification_url", notificationURL)
+	}
+	signature, err := api.SignParameters(params, cld.Config.Cloud.APISecret)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket := &UploadTicket{
+		UploadURL:      fmt.Sprintf("https://api.cloudinary.com/v1_1/%s/video/upload", cld.Config.Cloud.CloudName),
+		UniqueUploadID: fmt.Sprintf("upload-session-%d", session.ID),
+		ChunkSize:      UploadChunkSize,
+		Params:         map[string]string{"api_key": cld.Config.Cloud.APIKey, "signature": signature},
+		SignedUntil:    now.Add(uploadSignatureTTL),
+	}
+	for key := range params {
+		ticket.Params[key] = params.Get(key)
+	}
+	if ticket.SignedUntil.After(session.ExpiresAt) {
+		ticket.SignedUntil = session.ExpiresAt
+	}
+	return ticket, nil
+}
+
+// portfolioWorker returns the worker profile of userID if they can add another portfolio video
+func (s *UploadService) portfolioWorker(userID uint) (*models.WorkerProfile, error) {
+	var worker models.WorkerProfile
+	if err := s.db.Where("user_id = ?", userID).First(&worker).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUploadNotAllowed
+		}
+		return nil, err
+	}
+	var count int64
+	if err := s.db.Model(&models.WorkerPortfolioPhoto{}).
+		Where("worker_id = ? AND kind = ?", worker.ID, models.PortfolioVideo).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= models.MaxPortfolioVideos {
+		return nil, ErrUploadLimit
+	}
+	return &worker, nil
+}
+
+// requestUploaderRole returns whether userID is the customer or the assigned worker of an open
+// request that can take another video
+func (s *UploadService) requestUploaderRole(userID, requestID uint) (models.UserRole, error) {
+	var request models.CustomerServiceRequest
+	if err := s.db.First(&request, requestID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUploadNotAllowed
+		}
+		return "", err
+	}
+	switch request.Status {
+	case models.RequestStatusCompleted, models.RequestStatusCancelled, models.RequestStatusExpired:
+		return "", ErrUploadNotAllowed
+	}
+
+	role := models.RoleCustomer
+	if request.CustomerID != userID {
+		var worker models.WorkerProfile
+		if request.AssignedWorkerID == nil ||
+			s.db.Where("id = ? AND user_id = ?", *request.AssignedWorkerID, userID).First(&worker).Error != nil {
+			return "", ErrUploadNotAllowed
+		}
+		role = models.RoleWorker
+	}
+
+	var count int64
+	if err := s.db.Model(&models.RequestAttachment{}).Where("service_request_id = ?", requestID).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count >= models.MaxRequestAttachments {
+		return "", ErrUploadLimit
+	}
+	return role, nil
+}
+
+// verifyUploadedVideo checks the asset Cloudinary stored for session and returns its MIME type, or
+// why it is refused
+func verifyUploadedVideo(session models.UploadSession, asset *admin.AssetResult) (string, string) {
+	kind := mediaKinds[session.Purpose]
+	mimeType, ok := videoFormats[strings.ToLower(asset.Format)]
+	switch {
+	case asset.ResourceType != "video" || !ok:
+		return "", fmt.Sprintf("format %q is not accepted", asset.Format)
+	case int64(asset.Bytes) > kind.maxBytes:
+		return "", fmt.Sprintf("file is larger than %dMB", kind.maxBytes>>20)
+	}
+	return mimeType, ""
+}
+
+// videoFormatNames lists the accepted video formats in a stable order
+func videoFormatNames() []string {
+	return []string{"mp4", "mov", "webm"}
+}
+
+// randomUploadName returns a random name so public IDs cannot be guessed
+func randomUploadName() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// Work timer errors, each a transition the timer's current state does not allow
+var (
+	ErrTimerNotInProgress  = errors.New("service request is not in progress")
+	ErrTimerAlreadyStarted = errors.New("work timer has already been started")
+	ErrTimerNotRunning     = errors.New("work timer is not running")
+	ErrTimerNotPaused      = errors.New("work timer is not paused")
+	ErrTimerStopped        = errors.New("work timer has been stopped")
+)
+
+// WorkTimerService tracks how long a worker actually works on a request. Each start or resume
+// opens a work session and each pause or stop closes it, adding its length to the request.
+type WorkTimerService struct{}
+
+// NewWorkTimerService creates a new work timer service
+func NewWorkTimerService() *WorkTimerService {
+	return &WorkTimerService{}
+}
+
+// Start starts the timer on an in progress request for the first time
+func (s *WorkTimerService) Start(requestID, workerID uint) (*models.CustomerServiceRequest, error) {
+	return s.transition(requestID, func(tx *gorm.DB, request *models.CustomerServiceRequest, now time.Time) error {
+		if request.TimerState != models.WorkTimerIdle {
+			return ErrTimerAlreadyStarted
+		}
+		return openWorkSession(tx, request, workerID, now)
+	})
+}
+
+// Pause closes the running session, recording why work stopped
+func (s *WorkTimerService) Pause(requestID uint, reason string) (*models.CustomerServiceRequest, error) {
+	return s.transition(requestID, func(tx *gorm.DB, request *models.CustomerServiceRequest, now time.Time) error {
+		if request.TimerState != models.WorkTimerRunning {
+			return ErrTimerNotRunning
+		}
+		return closeWorkSession(tx, request, models.WorkTimerPaused, reason, now)
+	})
+}
+
+// Resume opens a new session on a paused timer
+func (s *WorkTimerService) Resume(requestID, workerID uint) (*models.CustomerServiceRequest, error) {
+	return s.transition(requestID, func(tx *gorm.DB, request *models.CustomerServiceRequest, now time.Time) error {
+		if request.TimerState != models.WorkTimerPaused {
+			return ErrTimerNotPaused
+		}
+		return openWorkSession(tx, request, workerID, now)
+	})
+}
+
+// Stop ends timing for good. A stopped timer cannot be resumed.
+func (s *WorkTimerService) Stop(requestID uint) (*models.CustomerServiceRequest, error) {
+	return s.transition(requestID, func(tx *gorm.DB, request *models.CustomerServiceRequest, now time.Time) error {
+		switch request.TimerState {
+		case models.WorkTimerStopped:
+			return ErrTimerStopped
+		case models.WorkTimerIdle:
+			return ErrTimerNotRunning
+		}
+		return StopWorkTimer(tx, request, now)
+	})
+}
+
+// StopWorkTimer stops the timer of a request already loaded in tx, closing the running session
+// if there is one. Idle and stopped timers are left as they are.
+func StopWorkTimer(tx *gorm.DB, request *models.CustomerServiceRequest, now time.Time) error {
+	switch request.TimerState {
+	case models.WorkTimerRunning:
+		return closeWorkSession(tx, request, models.WorkTimerStopped, "", now)
+	case models.WorkTimerPaused:
+		request.TimerState = models.WorkTimerStopped
+		return tx.Model(request).Update("timer_state", request.TimerState).Error
+	}
+	return nil
+}
+
+// transition loads the request under lock, checks it is in progress and applies apply
+func (s *WorkTimerService) transition(requestID uint, apply func(tx *gorm.DB, request *models.CustomerServiceRequest, now time.Time) error) (*models.CustomerServiceRequest, error) {
+	var request models.CustomerServiceRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, requestID).Error; err != nil {
+			return err
+		}
+		if request.Status != models.RequestStatusInProgress {
+			return ErrTimerNotInProgress
+		}
+		return apply(tx, &request, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// openWorkSession starts a new running session on request
+func openWorkSession(tx *gorm.DB, request *models.CustomerServiceRequest, workerID uint, now time.Time) error {
+	session := models.WorkSession{
+		ServiceRequestID: request.ID,
+		WorkerID:         workerID,
+		StartedAt:        now,
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return err
+	}
+
+	request.TimerState = models.WorkTimerRunning
+	request.TimerStartedAt = &now
+	return tx.Model(request).Updates(map[string]interface{}{
+		"timer_state":      request.TimerState,
+		"timer_started_at": request.TimerStartedAt,
+	}).Error
+}
+
+// closeWorkSession ends the running session and adds its length to the request's worked time
+func closeWorkSession(tx *gorm.DB, request *models.CustomerServiceRequest, state models.WorkTimerState, reason string, now time.Time) error {
+	if err := tx.Model(&models.WorkSession{}).
+		Where("service_request_id = ? AND ended_at IS NULL", request.ID).
+		Updates(map[string]interface{}{"ended_at": now, "pause_reason": reason}).Error; err != nil {
+		return err
+	}
+
+	request.WorkedSeconds = request.WorkedSecondsAt(now)
+	request.TimerState = state
+	request.TimerStartedAt = nil
+	return tx.Model(request).Updates(map[string]interface{}{
+		"timer_state":      request.TimerState,
+		"timer_started_at": nil,
+		"worked_seconds":   request.WorkedSeconds,
+	}).Error
+}
@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"repair-service-server/database"
 	"repair-service-server/models"
+	"repair-service-server/money"
 )
 
 // WorkerAnalyticsService handles all worker performance tracking and analytics
@@ -247,18 +249,23 @@ func (s *WorkerAnalyticsService) TrackJobResponse(workerID uint, serviceRequestI
 		UpdatedAt:       now,
 	}
 	
-	return s.db.Create(&tracking).Error
+	if err := s.db.Create(&tracking).Error; err != nil {
+		return err
+	}
+	
+	s.awardBadgesAfter(workerID, "job response")
+	return nil
 }
 
-// TrackJobCompletion records when a worker completes a job
-func (s *WorkerAnalyticsService) TrackJobCompletion(workerID uint, serviceRequestID uint, earnings float64, workHours float64) error {
-	// Check if this job completion has already been tracked
+// TrackJobTravel records how long a worker took from setting off to arriving at the customer
+func (s *WorkerAnalyticsService) TrackJobTravel(workerID uint, serviceRequestID uint, travelMinutes float64) error {
+	// Check if this trip has already been tracked
 	var existingTracking models.WorkerJobTracking
 	err := s.db.Where("worker_id = ? AND service_request_id = ? AND job_type = ?", 
-		workerID, serviceRequestID, "completion").First(&existingTracking).Error
+		workerID, serviceRequestID, "travel").First(&existingTracking).Error
 	
 	if err == nil {
-		// Job completion already tracked, skip to prevent duplicates
+		// Trip already tracked, skip to prevent duplicates
 		return nil
 	}
 	
@@ -269,16 +276,14 @@ func (s *WorkerAnalyticsService) TrackJobCompletion(workerID uint, serviceReques
 	var dailyStats models.WorkerDailyStats
 	err = s.db.Where("worker_id = ? AND date = ?", workerID, today).First(&dailyStats).Error
 	if err == gorm.ErrRecordNotFound {
-		// Create new daily stats if they don't exist
 		dailyStats = models.WorkerDailyStats{
 			WorkerID: workerID,
 			Date:     today,
 		}
 	}
 	
-	dailyStats.JobsCompleted++
-	dailyStats.Earnings += earnings
-	dailyStats.WorkHours += workHours
+	dailyStats.TotalTravelTime += travelMinutes
+	dailyStats.JobsWithTravel++
 	dailyStats.UpdatedAt = now
 	
 	if dailyStats.ID == 0 {
@@ -291,94 +296,323 @@ func (s *WorkerAnalyticsService) TrackJobCompletion(workerID uint, serviceReques
 		return err
 	}
 	
-	// Update or create monthly stats
-	year, month, _ := now.Date()
-	var monthlyStats models.WorkerMonthlyStats
-	err = s.db.Where("worker_id = ? AND year = ? AND month = ?", workerID, year, month).First(&monthlyStats).Error
+	// Update or create lifetime stats
+	var lifetimeStats models.WorkerStats
+	err = s.db.Where("worker_id = ?", workerID).First(&lifetimeStats).Error
 	if err == gorm.ErrRecordNotFound {
-		// Create new monthly stats if they don't exist
-		monthlyStats = models.WorkerMonthlyStats{
+		lifetimeStats = models.WorkerStats{
 			WorkerID: workerID,
-			Year:     year,
-			Month:    int(month),
 		}
 	}
 	
-	monthlyStats.JobsCompleted++
-	monthlyStats.Earnings += earnings
-	monthlyStats.WorkHours += workHours
-	monthlyStats.UpdatedAt = now
+	lifetimeStats.TotalTravels++
+	lifetimeStats.AverageTravelTime = (lifetimeStats.AverageTravelTime*float64(lifetimeStats.TotalTravels-1) + travelMinutes) / float64(lifetimeStats.TotalTravels)
+	lifetimeStats.UpdatedAt = now
 	
-	if monthlyStats.ID == 0 {
-		monthlyStats.CreatedAt = now
-		err = s.db.Create(&monthlyStats).Error
+	if lifetimeStats.ID == 0 {
+		lifetimeStats.CreatedAt = now
+		err = s.db.Create(&lifetimeStats).Error
 	} else {
-		err = s.db.Save(&monthlyStats).Error
+		err = s.db.Save(&lifetimeStats).Error
 	}
 	if err != nil {
 		return err
 	}
 	
-	// Update or create lifetime stats
-	var lifetimeStats models.WorkerStats
-	err = s.db.Where("worker_id = ?", workerID).First(&lifetimeStats).Error
-	if err == gorm.ErrRecordNotFound {
-		// Create new lifetime stats if they don't exist
-		lifetimeStats = models.WorkerStats{
-			WorkerID: workerID,
-		}
+	// Create tracking record to prevent duplicate processing
+	tracking := models.WorkerJobTracking{
+		WorkerID:         workerID,
+		ServiceRequestID: serviceRequestID,
+		JobType:          "travel",
+		ProcessedAt:      now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
 	}
 	
-	lifetimeStats.TotalJobsCompleted++
-	lifetimeStats.TotalEarnings += earnings
-	lifetimeStats.TotalWorkHours += workHours
-	lifetimeStats.DailyJobsCompleted = dailyStats.JobsCompleted
-	lifetimeStats.MonthlyJobsCompleted = monthlyStats.JobsCompleted
-	lifetimeStats.DailyEarnings = dailyStats.Earnings
-	lifetimeStats.MonthlyEarnings = monthlyStats.Earnings
-	lifetimeStats.DailyWorkHours = dailyStats.WorkHours
-	lifetimeStats.MonthlyWorkHours = monthlyStats.WorkHours
-	lifetimeStats.LastJobCompleted = &now
-	lifetimeStats.LastEarning = &now
-	lifetimeStats.UpdatedAt = now
+	return s.db.Create(&tracking).Error
+}
+
+// TrackJobCompletion records when a worker completes a job. A completion already tracked for the
+// request is ignored, so outbox redelivery cannot count it twice
+func (s *WorkerAnalyticsService) TrackJobCompletion(workerID uint, serviceRequestID uint, earnings money.Amount, workHours float64) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	
-	// Calculate completion rate
-	if lifetimeStats.TotalJobsResponded > 0 {
-		lifetimeStats.CompletionRate = float64(lifetimeStats.TotalJobsCompleted) / float64(lifetimeStats.TotalJobsResponded) * 100
+	applied := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// The tracking row goes in first so a redelivered event hits its unique key and changes nothing
+		claimed, err := claimJobTracking(tx, workerID, serviceRequestID, "completion", now)
+		if err != nil || !claimed {
+			return err
+		}
+	
+		// Update or create daily stats
+		var dailyStats models.WorkerDailyStats
+		err = tx.Where("worker_id = ? AND date = ?", workerID, today).First(&dailyStats).Error
+		if err == gorm.ErrRecordNotFound {
+			// Create new daily stats if they don't exist
+			dailyStats = models.WorkerDailyStats{
+				WorkerID: workerID,
+				Date:     today,
+			}
+		}
+	
+		dailyStats.JobsCompleted++
+		dailyStats.Earnings += earnings
+		dailyStats.WorkHours += workHours
+		dailyStats.UpdatedAt = now
+	
+		if dailyStats.ID == 0 {
+			dailyStats.CreatedAt = now
+			err = tx.Create(&dailyStats).Error
+		} else {
+			err = tx.Save(&dailyStats).Error
+		}
+		if err != nil {
+			return err
+		}
+	
+		// Update or create monthly stats
+		year, month, _ := now.Date()
+		var monthlyStats models.WorkerMonthlyStats
+		err = tx.Where("worker_id = ? AND year = ? AND month = ?", workerID, year, month).First(&monthlyStats).Error
+		if err == gorm.ErrRecordNotFound {
+			// Create new monthly stats if they don't exist
+			monthlyStats = models.WorkerMonthlyStats{
+				WorkerID: workerID,
+				Year:     year,
+				Month:    int(month),
+			}
+		}
+	
+		monthlyStats.JobsCompleted++
+		monthlyStats.Earnings += earnings
+		monthlyStats.WorkHours += workHours
+		monthlyStats.UpdatedAt = now
+	
+		if monthlyStats.ID == 0 {
+			monthlyStats.CreatedAt = now
+			err = tx.Create(&monthlyStats).Error
+		} else {
+			err = tx.Save(&monthlyStats).Error
+		}
+		if err != nil {
+			return err
+		}
+	
+		// Update or create lifetime stats
+		var lifetimeStats models.WorkerStats
+		err = tx.Where("worker_id = ?", workerID).First(&lifetimeStats).Error
+		if err == gorm.ErrRecordNotFound {
+			// Create new lifetime stats if they don't exist
+			lifetimeStats = models.WorkerStats{
+				WorkerID: workerID,
+			}
+		}
+	
+		lifetimeStats.TotalJobsCompleted++
+		lifetimeStats.TotalEarnings += earnings
+		lifetimeStats.TotalWorkHours += workHours
+		lifetimeStats.DailyJobsCompleted = dailyStats.JobsCompleted
+		lifetimeStats.MonthlyJobsCompleted = monthlyStats.JobsCompleted
+		lifetimeStats.DailyEarnings = dailyStats.Earnings
+		lifetimeStats.MonthlyEarnings = monthlyStats.Earnings
+		lifetimeStats.DailyWorkHours = dailyStats.WorkHours
+		lifetimeStats.MonthlyWorkHours = monthlyStats.WorkHours
+		lifetimeStats.LastJobCompleted = &now
+		lifetimeStats.LastEarning = &now
+		lifetimeStats.UpdatedAt = now
+	
+		// Calculate completion rate
+		if lifetimeStats.TotalJobsResponded > 0 {
+			lifetimeStats.CompletionRate = float64(lifetimeStats.TotalJobsCompleted) / float64(lifetimeStats.TotalJobsResponded) * 100
+		}
+	
+		// Calculate average earnings per job
+		if lifetimeStats.TotalJobsCompleted > 0 {
+			lifetimeStats.AverageEarningsPerJob = lifetimeStats.TotalEarnings.Div(int64(lifetimeStats.TotalJobsCompleted))
+		}
+	
+		// Calculate average job duration
+		if lifetimeStats.TotalJobsCompleted > 0 {
+			lifetimeStats.AverageJobDuration = lifetimeStats.TotalWorkHours / float64(lifetimeStats.TotalJobsCompleted)
+		}
+	
+		if lifetimeStats.ID == 0 {
+			lifetimeStats.CreatedAt = now
+			err = tx.Create(&lifetimeStats).Error
+		} else {
+			err = tx.Save(&lifetimeStats).Error
+		}
+	
+		if err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	if err != nil || !applied {
+		return err
 	}
 	
-	// Calculate average earnings per job
-	if lifetimeStats.TotalJobsCompleted > 0 {
-		lifetimeStats.AverageEarningsPerJob = lifetimeStats.TotalEarnings / float64(lifetimeStats.TotalJobsCompleted)
+	s.awardBadgesAfter(workerID, "job completion")
+	return nil
+}
+
+// RecordEarningsAdjustment books an earnings correction (positive or negative) on the day it is made,
+// inside tx so it commits together with the adjustment that caused it.
+// Past daily and monthly entries are left untouched so the adjustment shows up as a reversal.
+func RecordEarningsAdjustment(tx *gorm.DB, workerID uint, delta money.Amount) error {
+	if delta == 0 {
+		return nil
 	}
 	
-	// Calculate average job duration
-	if lifetimeStats.TotalJobsCompleted > 0 {
-		lifetimeStats.AverageJobDuration = lifetimeStats.TotalWorkHours / float64(lifetimeStats.TotalJobsCompleted)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	
+	var dailyStats models.WorkerDailyStats
+	err := tx.Where("worker_id = ? AND date = ?", workerID, today).First(&dailyStats).Error
+	if err == gorm.ErrRecordNotFound {
+		dailyStats = models.WorkerDailyStats{
+			WorkerID:  workerID,
+			Date:      today,
+			CreatedAt: now,
+		}
+	} else if err != nil {
+		return err
+	}
+	dailyStats.Earnings += delta
+	dailyStats.UpdatedAt = now
+	if err := tx.Save(&dailyStats).Error; err != nil {
+		return err
 	}
 	
-	if lifetimeStats.ID == 0 {
-		lifetimeStats.CreatedAt = now
-		err = s.db.Create(&lifetimeStats).Error
-	} else {
-		err = s.db.Save(&lifetimeStats).Error
+	year, month, _ := now.Date()
+	var monthlyStats models.WorkerMonthlyStats
+	err = tx.Where("worker_id = ? AND year = ? AND month = ?", workerID, year, month).First(&monthlyStats).Error
+	if err == gorm.ErrRecordNotFound {
+		monthlyStats = models.WorkerMonthlyStats{
+			WorkerID:  workerID,
+			Year:      year,
+			Month:     int(month),
+			CreatedAt: now,
+		}
+	} else if err != nil {
+		return err
+	}
+	monthlyStats.Earnings += delta
+	if monthlyStats.JobsCompleted > 0 {
+		monthlyStats.AverageEarningsPerJob = monthlyStats.Earnings.Div(int64(monthlyStats.JobsCompleted))
+	}
+	monthlyStats.UpdatedAt = now
+	if err := tx.Save(&monthlyStats).Error; err != nil {
+		return err
 	}
 	
-	if err != nil {
+	var lifetimeStats models.WorkerStats
+	err = tx.Where("worker_id = ?", workerID).First(&lifetimeStats).Error
+	if err == gorm.ErrRecordNotFound {
+		lifetimeStats = models.WorkerStats{
+			WorkerID:  workerID,
+			CreatedAt: now,
+		}
+	} else if err != nil {
 		return err
 	}
+	lifetimeStats.TotalEarnings += delta
+	lifetimeStats.DailyEarnings = dailyStats.Earnings
+	lifetimeStats.MonthlyEarnings = monthlyStats.Earnings
+	if lifetimeStats.TotalJobsCompleted > 0 {
+		lifetimeStats.AverageEarningsPerJob = lifetimeStats.TotalEarnings.Div(int64(lifetimeStats.TotalJobsCompleted))
+	}
+	lifetimeStats.UpdatedAt = now
 	
-	// Create tracking record to prevent duplicate processing
+	return tx.Save(&lifetimeStats).Error
+}
+
+// TrackTip books a customer's tip on the day it is given. Tips are kept apart from earnings.
+// A tip already tracked for the request is ignored
+func (s *WorkerAnalyticsService) TrackTip(workerID uint, serviceRequestID uint, amount money.Amount) error {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// The tracking row goes in first so a redelivered event hits its unique key and changes nothing
+		claimed, err := claimJobTracking(tx, workerID, serviceRequestID, "tip", now)
+		if err != nil || !claimed {
+			return err
+		}
+	
+		var dailyStats models.WorkerDailyStats
+		err = tx.Where("worker_id = ? AND date = ?", workerID, today).First(&dailyStats).Error
+		if err == gorm.ErrRecordNotFound {
+			dailyStats = models.WorkerDailyStats{
+				WorkerID:  workerID,
+				Date:      today,
+				CreatedAt: now,
+			}
+		} else if err != nil {
+			return err
+		}
+		dailyStats.Tips += amount
+		dailyStats.UpdatedAt = now
+		if err := tx.Save(&dailyStats).Error; err != nil {
+			return err
+		}
+	
+		year, month, _ := now.Date()
+		var monthlyStats models.WorkerMonthlyStats
+		err = tx.Where("worker_id = ? AND year = ? AND month = ?", workerID, year, month).First(&monthlyStats).Error
+		if err == gorm.ErrRecordNotFound {
+			monthlyStats = models.WorkerMonthlyStats{
+				WorkerID:  workerID,
+				Year:      year,
+				Month:     int(month),
+				CreatedAt: now,
+			}
+		} else if err != nil {
+			return err
+		}
+		monthlyStats.Tips += amount
+		monthlyStats.UpdatedAt = now
+		if err := tx.Save(&monthlyStats).Error; err != nil {
+			return err
+		}
+	
+		var lifetimeStats models.WorkerStats
+		err = tx.Where("worker_id = ?", workerID).First(&lifetimeStats).Error
+		if err == gorm.ErrRecordNotFound {
+			lifetimeStats = models.WorkerStats{
+				WorkerID:  workerID,
+				CreatedAt: now,
+			}
+		} else if err != nil {
+			return err
+		}
+		lifetimeStats.TotalTips += amount
+		lifetimeStats.DailyTips = dailyStats.Tips
+		lifetimeStats.MonthlyTips = monthlyStats.Tips
+		lifetimeStats.UpdatedAt = now
+		return tx.Save(&lifetimeStats).Error
+	})
+}
+
+// claimJobTracking inserts the tracking row for a job event, reporting false when the event was
+// already applied
+func claimJobTracking(tx *gorm.DB, workerID uint, serviceRequestID uint, jobType string, now time.Time) (bool, error) {
 	tracking := models.WorkerJobTracking{
-		WorkerID:        workerID,
+		WorkerID:         workerID,
 		ServiceRequestID: serviceRequestID,
-		JobType:         "completion",
-		ProcessedAt:     now,
-		CreatedAt:       now,
-		UpdatedAt:       now,
+		JobType:          jobType,
+		ProcessedAt:      now,
+		CreatedAt:        now,
+		UpdatedAt:        now,
 	}
-	
-	return s.db.Create(&tracking).Error
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tracking)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
 }
 
 // TrackJobDecline records when a worker declines or ignores a job
@@ -525,11 +759,12 @@ func (s *WorkerAnalyticsService) GetWorkerPerformanceSummary(workerID uint) (*mo
 		log.Printf("Error fetching last 6 months stats: %v", err)
 	}
 	
-	// Calculate performance rankings among workers in same category
-	summary.ResponseRateRank = s.calculateResponseRateRank(workerID, workerProfile.CategoryID)
-	summary.CompletionRateRank = s.calculateCompletionRateRank(workerID, workerProfile.CategoryID)
-	summary.EarningsRank = s.calculateEarningsRank(workerID, workerProfile.CategoryID)
-	summary.RatingRank = s.calculateRatingRank(workerID, workerProfile.CategoryID)
+	// Calculate performance rankings among workers serving the same main category
+	ranks := s.workerRanks(workerID, workerProfile.CategoryID)
+	summary.ResponseRateRank = ranks.ResponseRateRank
+	summary.CompletionRateRank = ranks.CompletionRateRank
+	summary.EarningsRank = ranks.EarningsRank
+	summary.RatingRank = ranks.RatingRank
 	
 	// Calculate goal progress (assuming monthly goal of 20 jobs)
 	summary.MonthlyGoal = 20
@@ -547,97 +782,21 @@ func (s *WorkerAnalyticsService) GetWorkerPerformanceSummary(workerID uint) (*mo
 	return summary, nil
 }
 
-// calculateResponseRateRank calculates worker's rank based on response rate
-func (s *WorkerAnalyticsService) calculateResponseRateRank(workerID uint, categoryID uint) int {
-	var rank int
-	s.db.Raw(`
-		SELECT COUNT(*) + 1 as rank
-		FROM worker_stats ws1
-		JOIN worker_profiles wp1 ON ws1.worker_id = wp1.id
-		WHERE wp1.category_id = ? 
-		AND ws1.response_rate > (
-			SELECT response_rate 
-			FROM worker_stats ws2 
-			WHERE ws2.worker_id = ?
-		)
-	`, categoryID, workerID).Scan(&rank)
-	return rank
-}
-
-// calculateCompletionRateRank calculates worker's rank based on completion rate
-func (s *WorkerAnalyticsService) calculateCompletionRateRank(workerID uint, categoryID uint) int {
-	var rank int
-	s.db.Raw(`
-		SELECT COUNT(*) + 1 as rank
-		FROM worker_stats ws1
-		JOIN worker_profiles wp1 ON ws1.worker_id = wp1.id
-		WHERE wp1.category_id = ? 
-		AND ws1.completion_rate > (
-			SELECT completion_rate 
-			FROM worker_stats ws2 
-			WHERE ws2.worker_id = ?
-		)
-	`, categoryID, workerID).Scan(&rank)
-	return rank
-}
-
-// calculateEarningsRank calculates worker's rank based on total earnings
-func (s *WorkerAnalyticsService) calculateEarningsRank(workerID uint, categoryID uint) int {
-	var rank int
-	s.db.Raw(`
-		SELECT COUNT(*) + 1 as rank
-		FROM worker_stats ws1
-		JOIN worker_profiles wp1 ON ws1.worker_id = wp1.id
-		WHERE wp1.category_id = ? 
-		AND ws1.total_earnings > (
-			SELECT total_earnings 
-			FROM worker_stats ws2 
-			WHERE ws2.worker_id = ?
-		)
-	`, categoryID, workerID).Scan(&rank)
-	return rank
-}
-
-// calculateRatingRank calculates worker's rank based on average rating
-func (s *WorkerAnalyticsService) calculateRatingRank(workerID uint, categoryID uint) int {
-	var rank int
-	s.db.Raw(`
-		SELECT COUNT(*) + 1 as rank
-		FROM worker_stats ws1
-		JOIN worker_profiles wp1 ON ws1.worker_id = wp1.id
-		WHERE wp1.category_id = ? 
-		AND ws1.average_rating > (
-			SELECT average_rating 
-			FROM worker_stats ws2 
-			WHERE ws2.worker_id = ?
-		)
-	`, categoryID, workerID).Scan(&rank)
-	return rank
-}
-
-// calculateStreakDays calculates consecutive days with completed jobs
+// calculateStreakDays counts the consecutive days, ending today, on which the worker completed a job,
+// from their daily stats
 func (s *WorkerAnalyticsService) calculateStreakDays(workerID uint) int {
-	var streak int
-	s.db.Raw(`
-		WITH RECURSIVE dates AS (
-			SELECT DATE(completed_at) as work_date, 1 as streak
-			FROM service_histories 
-			WHERE worker_id = ? 
-			AND DATE(completed_at) = CURDATE()
-			
-			UNION ALL
-			
-			SELECT DATE(d.work_date - INTERVAL 1 DAY), d.streak + 1
-			FROM dates d
-			WHERE EXISTS (
-				SELECT 1 FROM service_histories 
-				WHERE worker_id = ? 
-				AND DATE(completed_at) = d.work_date - INTERVAL 1 DAY
-			)
-		)
-		SELECT MAX(streak) FROM dates
-	`, workerID, workerID).Scan(&streak)
-	return streak
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var days []time.Time
+	if err := s.db.Model(&models.WorkerDailyStats{}).
+		Where("worker_id = ? AND jobs_completed > 0 AND date <= ?", workerID, today).
+		Order("date DESC").
+		Pluck("date", &days).Error; err != nil {
+		log.Printf("Error fetching streak days: %v", err)
+		return 0
+	}
+	return streakDays(days, today)
 }
 
 // getBestDay returns the day with highest earnings
@@ -658,12 +817,12 @@ func (s *WorkerAnalyticsService) getBestMonth(workerID uint) models.WorkerMonthl
 	return bestMonth
 }
 
-// GetWorkerLeaderboard returns top workers in a category
+// GetWorkerLeaderboard returns top workers serving a category
 func (s *WorkerAnalyticsService) GetWorkerLeaderboard(categoryID uint, limit int) ([]models.WorkerStats, error) {
 	var leaderboard []models.WorkerStats
 	
 	err := s.db.Joins("JOIN worker_profiles wp ON worker_stats.worker_id = wp.id").
-		Where("wp.category_id = ?", categoryID).
+		Where(workerServesSQL("wp"), []uint{categoryID}, []uint{categoryID}).
 		Order("total_earnings DESC").
 		Limit(limit).
 		Preload("Worker.User").
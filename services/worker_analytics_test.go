@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+	"repair-service-server/testdb"
+)
+
+func TestCalculateStreakDays(t *testing.T) {
+	db := testdb.Install(t)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	daysAgo := func(n int) time.Time { return today.AddDate(0, 0, -n) }
+
+	// Worker 1 worked the last three days, then skipped a day; the idle day and the day off do not
+	// count. Worker 2 did not work today. Worker 3 only worked today.
+	stats := []models.WorkerDailyStats{
+		{WorkerID: 1, Date: today, JobsCompleted: 2},
+		{WorkerID: 1, Date: daysAgo(1), JobsCompleted: 1},
+		{WorkerID: 1, Date: daysAgo(2), JobsCompleted: 3},
+		{WorkerID: 1, Date: daysAgo(4), JobsCompleted: 1},
+		{WorkerID: 1, Date: daysAgo(5), JobsReceived: 4},
+		{WorkerID: 2, Date: daysAgo(1), JobsCompleted: 1},
+		{WorkerID: 2, Date: daysAgo(2), JobsCompleted: 1},
+		{WorkerID: 3, Date: today, JobsCompleted: 1},
+		{WorkerID: 3, Date: daysAgo(1), JobsReceived: 2},
+	}
+	if err := db.Create(&stats).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewWorkerAnalyticsService()
+	for workerID, want := range map[uint]int{1: 3, 2: 0, 3: 1, 4: 0} {
+		if got := service.calculateStreakDays(workerID); got != want {
+			t.Errorf("worker %d: streak of %d days, want %d", workerID, got, want)
+		}
+	}
+}
+
+func TestTrackedEventsCountOnce(t *testing.T) {
+	db := testdb.Install(t)
+	service := NewWorkerAnalyticsService()
+
+	// The outbox may deliver the same completion and tip more than once
+	for i := 0; i < 2; i++ {
+		if err := service.TrackJobCompletion(1, 10, money.FromFloat(150), 2); err != nil {
+			t.Fatal(err)
+		}
+		if err := service.TrackTip(1, 10, money.FromFloat(20)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.TrackJobCompletion(1, 11, money.FromFloat(50), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	var stats models.WorkerStats
+	if err := db.Where("worker_id = ?", 1).First(&stats).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalJobsCompleted != 2 || stats.TotalEarnings != money.FromFloat(200) || stats.TotalTips != money.FromFloat(20) {
+		t.Errorf("lifetime stats: %d jobs, %s earned, %s in tips, want 2 jobs, 200.00 and 20.00",
+			stats.TotalJobsCompleted, stats.TotalEarnings, stats.TotalTips)
+	}
+	var tracked int64
+	if err := db.Model(&models.WorkerJobTracking{}).Where("worker_id = ?", 1).Count(&tracked).Error; err != nil {
+		t.Fatal(err)
+	}
+	if tracked != 3 {
+		t.Errorf("%d tracked events, want 3", tracked)
+	}
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+)
+
+// WorkerCapacity describes how many more jobs a worker can take on
+type WorkerCapacity struct {
+	MaxConcurrentJobs int  `json:"max_concurrent_jobs"`
+	ActiveJobs        int  `json:"active_jobs"`
+	Remaining         int  `json:"remaining"`
+	HasCapacity       bool `json:"has_capacity"`
+}
+
+// WorkerCapacityService computes worker load against their concurrent job limit
+type WorkerCapacityService struct {
+	db *gorm.DB
+}
+
+// NewWorkerCapacityService creates a new worker capacity service
+func NewWorkerCapacityService() *WorkerCapacityService {
+	return &WorkerCapacityService{
+		db: database.DB,
+	}
+}
+
+// GetCapacity counts the worker's active jobs against their limit
+func (s *WorkerCapacityService) GetCapacity(worker *models.WorkerProfile) (*WorkerCapacity, error) {
+	var active int64
+	if err := s.db.Model(&models.CustomerServiceRequest{}).
+		Where("assigned_worker_id = ? AND status IN ?", worker.ID, models.ActiveRequestStatuses).
+		Count(&active).Error; err != nil {
+		return nil, err
+	}
+
+	return CalculateWorkerCapacity(worker.MaxConcurrentJobs, int(active)), nil
+}
+
+// CalculateWorkerCapacity builds a capacity summary, treating limits below 1 as 1
+func CalculateWorkerCapacity(limit, active int) *WorkerCapacity {
+	if limit < 1 {
+		limit = 1
+	}
+	remaining := limit - active
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &WorkerCapacity{
+		MaxConcurrentJobs: limit,
+		ActiveJobs:        active,
+		Remaining:         remaining,
+		HasCapacity:       remaining > 0,
+	}
+}
+
+// HasCapacityScope restricts a worker_profiles query to workers below their concurrent job limit
+func HasCapacityScope(db *gorm.DB) *gorm.DB {
+	return db.Where(
+		"(SELECT COUNT(*) FROM customer_service_requests csr WHERE csr.assigned_worker_id = worker_profiles.id AND csr.deleted_at IS NULL AND csr.status IN ?) < GREATEST(worker_profiles.max_concurrent_jobs, 1)",
+		models.ActiveRequestStatuses,
+	)
+}
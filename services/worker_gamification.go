@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"repair-service-server/models"
+)
+
+// Thresholds of the rating and response badges
+const (
+	fiveStarWeekMinRatings    = 5
+	fastResponderMaxMinutes   = 5.0
+	fastResponderMinResponses = 20
+	topRatedMinRating         = 4.8
+	topRatedMinReviews        = 20
+)
+
+// BadgeProgress is a badge with whether the worker earned it and how far along they are
+type BadgeProgress struct {
+	models.BadgeDefinition
+	Earned    bool       `json:"earned"`
+	AwardedAt *time.Time `json:"awarded_at"`
+	Progress  float64    `json:"progress"` // Towards Target; a badge can need more than reaching it
+}
+
+// ChallengeProgress is a weekly challenge with the worker's progress this week
+type ChallengeProgress struct {
+	models.ChallengeDefinition
+	Progress    float64    `json:"progress"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
+
+// WeeklyChallengeProgress is the worker's progress on the current week's challenges
+type WeeklyChallengeProgress struct {
+	WeekStart  time.Time           `json:"week_start"`
+	WeekEnd    time.Time           `json:"week_end"`
+	Challenges []ChallengeProgress `json:"challenges"`
+	Completed  int                 `json:"completed"`
+}
+
+// WeeklyChallenges returns the worker's progress on the challenges of the week containing now, from
+// their daily stats
+func (s *WorkerAnalyticsService) WeeklyChallenges(workerID uint, now time.Time) (*WeeklyChallengeProgress, error) {
+	weekStart := WeekStart(now)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	var days []models.WorkerDailyStats
+	if err := s.db.Where("worker_id = ? AND date >= ? AND date < ?", workerID, weekStart, weekEnd).Find(&days).Error; err != nil {
+		return nil, err
+	}
+	var completions []models.WorkerChallengeCompletion
+	if err := s.db.Where("worker_id = ? AND week_start = ?", workerID, weekStart).Find(&completions).Error; err != nil {
+		return nil, err
+	}
+
+	measures := map[string]float64{}
+	for _, day := range days {
+		measures[models.ChallengeCompleteJobs] += float64(day.JobsCompleted)
+		measures[models.ChallengeEarn] += day.Earnings.Float()
+		measures[models.ChallengeRespondJobs] += float64(day.JobsResponded)
+		if day.JobsCompleted > 0 {
+			measures[models.ChallengeActiveDays]++
+		}
+	}
+
+	progress := &WeeklyChallengeProgress{
+		WeekStart:  weekStart,
+		WeekEnd:    weekEnd,
+		Challenges: make([]ChallengeProgress, 0, len(models.WeeklyChallenges)),
+	}
+	for _, def := range models.WeeklyChallenges {
+		challenge := ChallengeProgress{
+			ChallengeDefinition: def,
+			Progress:            measures[def.Challenge],
+			Completed:           measures[def.Challenge] >= def.Target,
+		}
+		for _, completion := range completions {
+			if completion.Challenge == def.Challenge {
+				completedAt := completion.CompletedAt
+				challenge.CompletedAt = &completedAt
+				challenge.Completed = true
+			}
+		}
+		if challenge.Completed {
+			progress.Completed++
+		}
+		progress.Challenges = append(progress.Challenges, challenge)
+	}
+	return progress, nil
+}
+
+// BadgeProgress returns every badge with whether the worker earned it and their progress
+func (s *WorkerAnalyticsService) BadgeProgress(workerID uint) ([]BadgeProgress, error) {
+	badges, _, err := s.badgeProgress(workerID, time.Now())
+	return badges, err
+}
+
+// AwardBadges records the weekly challenges the worker just completed and awards the badges they
+// now qualify for. It returns the newly awarded badges.
+func (s *WorkerAnalyticsService) AwardBadges(workerID uint) ([]models.WorkerBadge, error) {
+	now := time.Now()
+	badges, challenges, err := s.badgeProgress(workerID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, challenge := range challenges.Challenges {
+		if !challenge.Completed || challenge.CompletedAt != nil {
+			continue
+		}
+		completion := models.WorkerChallengeCompletion{
+			WorkerID:    workerID,
+			Challenge:   challenge.Challenge,
+			WeekStart:   challenges.WeekStart,
+			CompletedAt: now,
+		}
+		if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&completion).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var awarded []models.WorkerBadge
+	for _, badge := range badges {
+		if !badge.Earned || badge.AwardedAt != nil {
+			continue
+		}
+		workerBadge := models.WorkerBadge{
+			WorkerID:  workerID,
+			Badge:     badge.Badge,
+			Name:      badge.Name,
+			Icon:      badge.Icon,
+			AwardedAt: now,
+		}
+		result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&workerBadge)
+		if result.Error != nil {
+			return awarded, result.Error
+		}
+		if result.RowsAffected > 0 {
+			awarded = append(awarded, workerBadge)
+		}
+	}
+	return awarded, nil
+}
+
+// awardBadgesAfter awards badges after a tracked event without failing the tracking
+func (s *WorkerAnalyticsService) awardBadgesAfter(workerID uint, event string) {
+	if _, err := s.AwardBadges(workerID); err != nil {
+		log.Printf("⚠️ Failed to award badges to worker %d after %s: %v", workerID, event, err)
+	}
+}
+
+// badgeProgress computes every badge's progress and the current week's challenges. Badges already
+// awarded stay earned even if the worker no longer meets them.
+func (s *WorkerAnalyticsService) badgeProgress(workerID uint, now time.Time) ([]BadgeProgress, *WeeklyChallengeProgress, error) {
+	var stats models.WorkerStats
+	if err := s.db.Where("worker_id = ?", workerID).First(&stats).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	var worker models.WorkerProfile
+	if err := s.db.Select("id", "rating", "total_reviews").First(&worker, workerID).Error; err != nil {
+		return nil, nil, err
+	}
+	var awarded []models.WorkerBadge
+	if err := s.db.Where("worker_id = ?", workerID).Find(&awarded).Error; err != nil {
+		return nil, nil, err
+	}
+	challenges, err := s.WeeklyChallenges(workerID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A week with any rating below 5 stars does not count towards the 5-star week
+	weekStart := WeekStart(now)
+	var week struct {
+		FiveStars int
+		BelowFive int
+	}
+	if err := s.db.Model(&models.WorkerRating{}).
+		Select("COUNT(*) FILTER (WHERE stars = 5) AS five_stars, COUNT(*) FILTER (WHERE stars < 5) AS below_five").
+		Where("worker_id = ? AND created_at >= ? AND moderation_status <> ?", workerID, weekStart, models.ReviewHidden).
+		Scan(&week).Error; err != nil {
+		return nil, nil, err
+	}
+	fiveStarWeek := 0
+	if week.BelowFive == 0 {
+		fiveStarWeek = week.FiveStars
+	}
+
+	badges := make([]BadgeProgress, 0, len(models.BadgeDefinitions))
+	for _, def := range models.BadgeDefinitions {
+		badge := BadgeProgress{BadgeDefinition: def}
+		switch def.Badge {
+		case models.BadgeFirstJob, models.BadgeJobs100, models.BadgeJobs500:
+			badge.Progress = float64(stats.TotalJobsCompleted)
+			badge.Earned = badge.Progress >= def.Target
+		case models.BadgeFiveStarWeek:
+			badge.Progress = float64(fiveStarWeek)
+			badge.Earned = fiveStarWeek >= fiveStarWeekMinRatings
+		case models.BadgeFastResponder:
+			badge.Progress = float64(stats.TotalJobsResponded)
+			badge.Earned = stats.TotalJobsResponded >= fastResponderMinResponses && stats.AverageResponseTime <= fastResponderMaxMinutes
+		case models.BadgeTopRated:
+			badge.Progress = float64(worker.TotalReviews)
+			badge.Earned = worker.TotalReviews >= topRatedMinReviews && worker.Rating >= topRatedMinRating
+		case models.BadgeWeeklyChampion:
+			badge.Progress = float64(challenges.Completed)
+			badge.Earned = challenges.Completed == len(challenges.Challenges)
+		}
+		if badge.Progress > def.Target {
+			badge.Progress = def.Target
+		}
+		for _, a := range awarded {
+			if a.Badge == def.Badge {
+				awardedAt := a.AwardedAt
+				badge.AwardedAt = &awardedAt
+				badge.Earned = true
+			}
+		}
+		badges = append(badges, badge)
+	}
+	return badges, challenges, nil
+}
+
+// WeekStart returns midnight on the Monday of the week containing now, in the same time
+// zone as the daily stats
+func WeekStart(now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+}
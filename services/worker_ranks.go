@@ -0,0 +1,65 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"repair-service-server/cache"
+	"repair-service-server/config"
+)
+
+// workerRankCacheKey prefixes the cached ranks of each category's workers
+const workerRankCacheKey = "analytics:ranks:"
+
+// WorkerRanks is a worker's rank on each performance metric among the workers serving a category.
+// Tied workers share a rank, and the next one skips ahead.
+type WorkerRanks struct {
+	ResponseRateRank   int `json:"response_rate_rank"`
+	CompletionRateRank int `json:"completion_rate_rank"`
+	EarningsRank       int `json:"earnings_rank"`
+	RatingRank         int `json:"rating_rank"`
+}
+
+// workerRankCacheTTL returns how long category ranks are cached (WORKER_RANK_CACHE_TTL_SECONDS,
+// default 300)
+func workerRankCacheTTL() time.Duration {
+	return config.Current().WorkerRankTTL
+}
+
+// workerRanks returns the worker's ranks among the workers serving the category. Workers without
+// stats are unranked and get zeros.
+func (s *WorkerAnalyticsService) workerRanks(workerID, categoryID uint) WorkerRanks {
+	ranks, err := cache.GetOrLoad(fmt.Sprintf("%s%d", workerRankCacheKey, categoryID), workerRankCacheTTL(), func() (map[uint]WorkerRanks, error) {
+		return s.categoryRanks(categoryID)
+	})
+	if err != nil {
+		log.Printf("Error ranking workers of category %d: %v", categoryID, err)
+	}
+	return ranks[workerID]
+}
+
+// categoryRanks ranks every worker serving the category on all four metrics in a single query
+func (s *WorkerAnalyticsService) categoryRanks(categoryID uint) (map[uint]WorkerRanks, error) {
+	var rows []struct {
+		WorkerID uint
+		WorkerRanks
+	}
+	if err := s.db.Raw(`
+		SELECT ws.worker_id,
+			RANK() OVER (ORDER BY ws.response_rate DESC NULLS LAST) AS response_rate_rank,
+			RANK() OVER (ORDER BY ws.completion_rate DESC NULLS LAST) AS completion_rate_rank,
+			RANK() OVER (ORDER BY ws.total_earnings DESC NULLS LAST) AS earnings_rank,
+			RANK() OVER (ORDER BY ws.average_rating DESC NULLS LAST) AS rating_rank
+		FROM worker_stats ws
+		JOIN worker_profiles wp ON ws.worker_id = wp.id
+		WHERE `+workerServesSQL("wp"), []uint{categoryID}, []uint{categoryID}).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ranks := make(map[uint]WorkerRanks, len(rows))
+	for _, row := range rows {
+		ranks[row.WorkerID] = row.WorkerRanks
+	}
+	return ranks, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/validation"
+)
+
+// WorkerScheduleService checks worker availability against weekly schedules and time-off blocks
+type WorkerScheduleService struct {
+	db *gorm.DB
+}
+
+// NewWorkerScheduleService creates a new worker schedule service
+func NewWorkerScheduleService() *WorkerScheduleService {
+	return &WorkerScheduleService{
+		db: database.DB,
+	}
+}
+
+// ParseClock parses an HH:MM string into minutes since midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// slotCovers reports whether the slot covers the given time of day
+func slotCovers(slot models.WorkerScheduleSlot, at time.Time) bool {
+	if int(at.Weekday()) != slot.DayOfWeek {
+		return false
+	}
+	start, err := ParseClock(slot.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(slot.EndTime)
+	if err != nil {
+		return false
+	}
+	minute := at.Hour()*60 + at.Minute()
+	return minute >= start && minute < end
+}
+
+// IsWorkerAvailableAt checks whether the worker is scheduled to work at the given time.
+// Workers without a weekly schedule are considered available at any time outside time-off.
+func (s *WorkerScheduleService) IsWorkerAvailableAt(workerID uint, at time.Time) (bool, error) {
+	available, err := s.FilterAvailableAt([]uint{workerID}, at)
+	if err != nil {
+		return false, err
+	}
+	return available[workerID], nil
+}
+
+// FilterAvailableAt returns the subset of workers scheduled to work at the given time. Weekly slots
+// are in the time zone of each worker's region.
+func (s *WorkerScheduleService) FilterAvailableAt(workerIDs []uint, at time.Time) (map[uint]bool, error) {
+	result := make(map[uint]bool, len(workerIDs))
+	if len(workerIDs) == 0 {
+		return result, nil
+	}
+
+	var timeOff []models.WorkerTimeOff
+	if err := s.db.Where("worker_id IN ? AND starts_at <= ? AND ends_at > ?", workerIDs, at, at).
+		Find(&timeOff).Error; err != nil {
+		return nil, err
+	}
+	onLeave := make(map[uint]bool, len(timeOff))
+	for _, block := range timeOff {
+		onLeave[block.WorkerID] = true
+	}
+
+	var slots []models.WorkerScheduleSlot
+	if err := s.db.Where("worker_id IN ?", workerIDs).Find(&slots).Error; err != nil {
+		return nil, err
+	}
+	regions, err := NewRegionService().ForWorkers(workerIDs)
+	if err != nil {
+		return nil, err
+	}
+	hasSchedule := make(map[uint]bool)
+	inSlot := make(map[uint]bool)
+	for _, slot := range slots {
+		hasSchedule[slot.WorkerID] = true
+		region, ok := regions[slot.WorkerID]
+		if !ok {
+			region = NewRegionService().Default()
+		}
+		if slotCovers(slot, at.In(region.Location())) {
+			inSlot[slot.WorkerID] = true
+		}
+	}
+
+	for _, id := range workerIDs {
+		if onLeave[id] {
+			continue
+		}
+		result[id] = !hasSchedule[id] || inSlot[id]
+	}
+
+	return result, nil
+}
+
+// ConflictingJob returns the worker's scheduled job that a scheduled request would overlap, or nil
+// when none does. Each job is taken to last its JobLength.
+func (s *WorkerScheduleService) ConflictingJob(workerID uint, request models.CustomerServiceRequest) (*models.CustomerServiceRequest, error) {
+	if request.ScheduledFor == nil {
+		return nil, nil
+	}
+	durations := NewDurationEstimateService()
+	start := *request.ScheduledFor
+	end := start.Add(durations.JobLength(request))
+
+	// Jobs are never longer than validation.MaxDuration, so earlier ones cannot reach start
+	var jobs []models.CustomerServiceRequest
+	if err := s.db.Where("assigned_worker_id = ? AND id <> ? AND status IN ? AND scheduled_for > ? AND scheduled_for < ?",
+		workerID, request.ID, models.ActiveRequestStatuses, start.Add(-validation.MaxDuration), end).
+		Order("scheduled_for").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	for i, job := range jobs {
+		if job.ScheduledFor.Add(durations.JobLength(job)).After(start) {
+			return &jobs[i], nil
+		}
+	}
+	return nil, nil
+}
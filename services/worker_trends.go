@@ -0,0 +1,104 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+// MonthlyTrend is one month of a worker's trend, zeroed for months without stats
+type MonthlyTrend struct {
+	Year           int                `json:"year"`
+	Month          int                `json:"month"`
+	Label          string             `json:"label"` // YYYY-MM
+	HasData        bool               `json:"has_data"`
+	JobsCompleted  int                `json:"jobs_completed"`
+	Earnings       money.Amount       `json:"earnings"`
+	Tips           money.Amount       `json:"tips"`
+	AverageRating  float64            `json:"average_rating"` // 0 when no rating that month
+	ResponseRate   float64            `json:"response_rate"`
+	CompletionRate float64            `json:"completion_rate"`
+	Change         MonthlyTrendChange `json:"change"`
+}
+
+// MonthlyTrendChange is how a month compares with the one before it
+type MonthlyTrendChange struct {
+	Jobs            int          `json:"jobs"`
+	Earnings        money.Amount `json:"earnings"`
+	EarningsPercent *float64     `json:"earnings_percent"` // Nil when the previous month earned nothing
+	Rating          *float64     `json:"rating"`           // Nil unless both months were rated
+}
+
+// MonthlyTrendSeries holds the trend as parallel arrays, for charts
+type MonthlyTrendSeries struct {
+	Labels   []string       `json:"labels"`
+	Earnings []money.Amount `json:"earnings"`
+	Jobs     []int          `json:"jobs"`
+	Ratings  []float64      `json:"ratings"`
+}
+
+// BuildMonthlyTrends lays stats out over the months months starting at since, filling months
+// without stats with zeros. Stats for the month before since are used only as the baseline of the
+// first month's change.
+func BuildMonthlyTrends(stats []models.WorkerMonthlyStats, since time.Time, months int) ([]MonthlyTrend, MonthlyTrendSeries) {
+	byMonth := make(map[int]models.WorkerMonthlyStats, len(stats))
+	for _, s := range stats {
+		byMonth[s.Year*12+s.Month-1] = s
+	}
+
+	first := since.Year()*12 + int(since.Month()) - 1
+	previous := monthlyTrend(first-1, byMonth)
+	trends := make([]MonthlyTrend, 0, months)
+	series := MonthlyTrendSeries{
+		Labels:   make([]string, 0, months),
+		Earnings: make([]money.Amount, 0, months),
+		Jobs:     make([]int, 0, months),
+		Ratings:  make([]float64, 0, months),
+	}
+	for key := first; key < first+months; key++ {
+		trend := monthlyTrend(key, byMonth)
+		trend.Change = MonthlyTrendChange{
+			Jobs:     trend.JobsCompleted - previous.JobsCompleted,
+			Earnings: trend.Earnings - previous.Earnings,
+		}
+		if previous.Earnings > 0 {
+			percent := math.Round(float64(trend.Earnings-previous.Earnings)/float64(previous.Earnings)*1000) / 10
+			trend.Change.EarningsPercent = &percent
+		}
+		if trend.AverageRating > 0 && previous.AverageRating > 0 {
+			rating := math.Round((trend.AverageRating-previous.AverageRating)*100) / 100
+			trend.Change.Rating = &rating
+		}
+
+		trends = append(trends, trend)
+		series.Labels = append(series.Labels, trend.Label)
+		series.Earnings = append(series.Earnings, trend.Earnings)
+		series.Jobs = append(series.Jobs, trend.JobsCompleted)
+		series.Ratings = append(series.Ratings, trend.AverageRating)
+		previous = trend
+	}
+	return trends, series
+}
+
+// monthlyTrend returns the trend of the month key (year*12 + month-1) without its change
+func monthlyTrend(key int, byMonth map[int]models.WorkerMonthlyStats) MonthlyTrend {
+	year, month := key/12, key%12+1
+	trend := MonthlyTrend{
+		Year:  year,
+		Month: month,
+		Label: fmt.Sprintf("%04d-%02d", year, month),
+	}
+	if s, ok := byMonth[key]; ok {
+		trend.HasData = true
+		trend.JobsCompleted = s.JobsCompleted
+		trend.Earnings = s.Earnings
+		trend.Tips = s.Tips
+		trend.AverageRating = s.AverageRating
+		trend.ResponseRate = s.ResponseRate
+		trend.CompletionRate = s.CompletionRate
+	}
+	return trend
+}
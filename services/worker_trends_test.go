@@ -0,0 +1,143 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"repair-service-server/models"
+	"repair-service-server/money"
+)
+
+func monthStats(year, month, jobs int, earnings, rating float64) models.WorkerMonthlyStats {
+	return models.WorkerMonthlyStats{
+		Year:          year,
+		Month:         month,
+		JobsCompleted: jobs,
+		Earnings:      money.FromFloat(earnings),
+		AverageRating: rating,
+	}
+}
+
+func TestBuildMonthlyTrendsFillsGaps(t *testing.T) {
+	since := time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	stats := []models.WorkerMonthlyStats{
+		monthStats(2026, 12, 4, 2000, 4.5),
+		monthStats(2027, 2, 2, 1000, 4),
+	}
+
+	trends, _ := BuildMonthlyTrends(stats, since, 4)
+
+	wantLabels := []string{"2026-11", "2026-12", "2027-01", "2027-02"}
+	wantHasData := []bool{false, true, false, true}
+	wantJobs := []int{0, 4, 0, 2}
+	if len(trends) != len(wantLabels) {
+		t.Fatalf("got %d months, want %d", len(trends), len(wantLabels))
+	}
+	for i, trend := range trends {
+		if trend.Label != wantLabels[i] || trend.HasData != wantHasData[i] || trend.JobsCompleted != wantJobs[i] {
+			t.Errorf("month %d = %s has_data=%v jobs=%d, want %s has_data=%v jobs=%d",
+				i, trend.Label, trend.HasData, trend.JobsCompleted, wantLabels[i], wantHasData[i], wantJobs[i])
+		}
+	}
+
+	// The first month has no stats and nothing before it, so it is all zeros
+	first := trends[0]
+	if first.Earnings != 0 || first.AverageRating != 0 || first.Change.Jobs != 0 || first.Change.Earnings != 0 {
+		t.Errorf("empty first month = %+v, want zeros", first)
+	}
+	if first.Change.EarningsPercent != nil || first.Change.Rating != nil {
+		t.Errorf("empty first month change = %+v, want no percent or rating", first.Change)
+	}
+
+	// A month after a gap compares with the empty month, not the last one with data
+	if change := trends[3].Change; change.Jobs != 2 || change.Earnings != money.FromFloat(1000) || change.EarningsPercent != nil {
+		t.Errorf("month after a gap change = %+v, want +2 jobs, +1000 and no percent", change)
+	}
+}
+
+func TestBuildMonthlyTrendsUsesMonthBeforeAsBaseline(t *testing.T) {
+	since := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	stats := []models.WorkerMonthlyStats{
+		monthStats(2026, 12, 4, 2000, 4.5),
+		monthStats(2027, 1, 5, 2500, 4.75),
+	}
+
+	trends, series := BuildMonthlyTrends(stats, since, 1)
+
+	if len(trends) != 1 || trends[0].Label != "2027-01" {
+		t.Fatalf("trends = %+v, want only 2027-01", trends)
+	}
+	change := trends[0].Change
+	if change.Jobs != 1 || change.Earnings != money.FromFloat(500) {
+		t.Errorf("change = %+v, want +1 job and +500", change)
+	}
+	if change.EarningsPercent == nil || *change.EarningsPercent != 25 {
+		t.Errorf("earnings percent = %v, want 25", change.EarningsPercent)
+	}
+	if change.Rating == nil || *change.Rating != 0.25 {
+		t.Errorf("rating change = %v, want 0.25", change.Rating)
+	}
+	if !reflect.DeepEqual(series.Labels, []string{"2027-01"}) {
+		t.Errorf("series labels = %v, want the requested month only", series.Labels)
+	}
+}
+
+func TestBuildMonthlyTrendsChangeFromZero(t *testing.T) {
+	since := time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	stats := []models.WorkerMonthlyStats{
+		monthStats(2026, 6, 0, 0, 0),
+		monthStats(2026, 7, 3, 1500, 5),
+		monthStats(2026, 8, 0, 0, 0),
+	}
+
+	trends, _ := BuildMonthlyTrends(stats, since, 3)
+
+	july := trends[1].Change
+	if july.Jobs != 3 || july.Earnings != money.FromFloat(1500) {
+		t.Errorf("July change = %+v, want +3 jobs and +1500", july)
+	}
+	if july.EarningsPercent != nil {
+		t.Errorf("July earnings percent = %v, want nil after a month without earnings", *july.EarningsPercent)
+	}
+	if july.Rating != nil {
+		t.Errorf("July rating change = %v, want nil after an unrated month", *july.Rating)
+	}
+
+	august := trends[2].Change
+	if august.EarningsPercent == nil || *august.EarningsPercent != -100 {
+		t.Errorf("August earnings percent = %v, want -100", august.EarningsPercent)
+	}
+	if august.Rating != nil {
+		t.Errorf("August rating change = %v, want nil for an unrated month", *august.Rating)
+	}
+}
+
+func TestBuildMonthlyTrendsSeries(t *testing.T) {
+	since := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	stats := []models.WorkerMonthlyStats{
+		monthStats(2026, 2, 1, 300, 4),
+		monthStats(2026, 12, 6, 4200, 4.8),
+		monthStats(2027, 1, 9, 9000, 5), // After the range
+	}
+
+	for _, months := range []int{1, 6, 12} {
+		trends, series := BuildMonthlyTrends(stats, since, months)
+		if len(trends) != months || len(series.Labels) != months || len(series.Earnings) != months ||
+			len(series.Jobs) != months || len(series.Ratings) != months {
+			t.Errorf("%d months: got %d trends and series of %d/%d/%d/%d", months, len(trends),
+				len(series.Labels), len(series.Earnings), len(series.Jobs), len(series.Ratings))
+		}
+	}
+
+	trends, series := BuildMonthlyTrends(stats, since, 12)
+	for i, trend := range trends {
+		if series.Labels[i] != trend.Label || series.Earnings[i] != trend.Earnings ||
+			series.Jobs[i] != trend.JobsCompleted || series.Ratings[i] != trend.AverageRating {
+			t.Errorf("series entry %d does not match month %s", i, trend.Label)
+		}
+	}
+	if series.Labels[11] != "2026-12" || series.Jobs[11] != 6 || series.Ratings[1] != 4 {
+		t.Errorf("series = %+v, want December last with 6 jobs and February rated 4", series)
+	}
+}
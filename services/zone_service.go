@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"repair-service-server/database"
+	"repair-service-server/models"
+	"repair-service-server/utils"
+)
+
+var (
+	// ErrNoActiveZone is returned when a location is not covered by any active service zone
+	ErrNoActiveZone = errors.New("location is not inside an active service zone")
+	// ErrCategoryNotInZone is returned when the zone does not support the requested category
+	ErrCategoryNotInZone = errors.New("category is not available in this service zone")
+)
+
+// ZoneService handles service zone lookups
+type ZoneService struct {
+	db *gorm.DB
+}
+
+// NewZoneService creates a new zone service
+func NewZoneService() *ZoneService {
+	return &ZoneService{
+		db: database.DB,
+	}
+}
+
+// FindZoneForRequest returns the active zone containing the location that supports the category.
+// When no zones have been configured yet, it returns (nil, nil) so requests are not blocked.
+func (s *ZoneService) FindZoneForRequest(lat, lng float64, categoryID uint) (*models.ServiceZone, error) {
+	var zones []models.ServiceZone
+	if err := s.db.Where("is_active = ?", true).Find(&zones).Error; err != nil {
+		return nil, err
+	}
+
+	if len(zones) == 0 {
+		return nil, nil
+	}
+
+	var matched *models.ServiceZone
+	for i := range zones {
+		if ZoneContains(&zones[i], lat, lng) {
+			if zones[i].SupportsCategory(categoryID) {
+				return &zones[i], nil
+			}
+			matched = &zones[i]
+		}
+	}
+
+	if matched != nil {
+		return nil, ErrCategoryNotInZone
+	}
+	return nil, ErrNoActiveZone
+}
+
+// ZoneContains checks whether the coordinates lie inside the zone polygon
+func ZoneContains(zone *models.ServiceZone, lat, lng float64) bool {
+	polygon := make([]utils.Location, len(zone.Polygon))
+	for i, p := range zone.Polygon {
+		polygon[i] = utils.Location{Latitude: p.Lat, Longitude: p.Lng}
+	}
+	return utils.PointInPolygon(utils.Location{Latitude: lat, Longitude: lng}, polygon)
+}
+
+// ZoneCenter returns the average of the zone's polygon vertices, the point zone distances are
+// measured from
+func ZoneCenter(zone *models.ServiceZone) (lat, lng float64) {
+	if len(zone.Polygon) == 0 {
+		return 0, 0
+	}
+	for _, p := range zone.Polygon {
+		lat += p.Lat
+		lng += p.Lng
+	}
+	return lat / float64(len(zone.Polygon)), lng / float64(len(zone.Polygon))
+}
@@ -5,5 +5,13 @@ import "github.com/golang-jwt/jwt/v5"
 // Claims represents the JWT claims
 type Claims struct {
 	UserID uint `json:"user_id"`
+	// ImpersonatorID is the admin acting as UserID; it is only set on support impersonation tokens
+	ImpersonatorID uint `json:"impersonator_id,omitempty"`
+	// SessionID is the refresh token the access token was issued under, so revoking that session
+	// cuts it off; tokens issued outside a session leave it unset
+	SessionID uint `json:"session_id,omitempty"`
+	// TokenVersion is the user's token version at issue time; signing out everywhere bumps the
+	// version and so rejects every token issued before
+	TokenVersion int `json:"token_version,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -23,11 +23,12 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
-// GenerateToken generates a JWT token for a user
-func GenerateToken(userID uint, role string) (string, error) {
+// GenerateToken generates a JWT token for a user at their current token version
+func GenerateToken(userID uint, role string, tokenVersion int) (string, error) {
 	// Create claims
 	claims := &types.Claims{
-		UserID: userID,
+		UserID:       userID,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(config.AppConfig.JWT.ExpiryHours) * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -47,11 +48,36 @@ func GenerateToken(userID uint, role string) (string, error) {
 	return tokenString, nil
 }
 
-// GenerateRefreshToken generates a refresh token for a user
-func GenerateRefreshToken(userID uint) (string, error) {
+// GenerateImpersonationToken generates a short-lived, non-refreshable token that lets an admin act as a user
+func GenerateImpersonationToken(userID, adminID uint, tokenVersion int, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	claims := &types.Claims{
+		UserID:         userID,
+		ImpersonatorID: adminID,
+		TokenVersion:   tokenVersion,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Subject:   "impersonation",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(config.AppConfig.JWT.Secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenString, expiresAt, nil
+}
+
+// GenerateRefreshToken generates a refresh token for a user at their current token version
+func GenerateRefreshToken(userID uint, tokenVersion int) (string, error) {
 	// Create claims for refresh token (longer expiry)
 	claims := &types.Claims{
-		UserID: userID,
+		UserID:       userID,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(30 * 24 * time.Hour)), // 30 days
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
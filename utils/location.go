@@ -2,6 +2,7 @@ package utils
 
 import (
 	"math"
+	"repair-service-server/config"
 	"repair-service-server/models"
 	"time"
 
@@ -104,15 +105,36 @@ func IsLocationRecent(lastUpdate *time.Time) bool {
 
 // GetDefaultBroadcastRadius returns the default broadcast radius in kilometers
 func GetDefaultBroadcastRadius() float64 {
-	return 10.0 // 10 kilometers
+	return config.Current().Dispatch.DefaultBroadcastRadiusKm
 }
 
 // GetMaxBroadcastRadius returns the maximum allowed broadcast radius in kilometers
 func GetMaxBroadcastRadius() float64 {
-	return 50.0 // 50 kilometers
+	return config.Current().Dispatch.MaxBroadcastRadiusKm
 }
 
 // ValidateBroadcastRadius checks if the broadcast radius is within acceptable limits
 func ValidateBroadcastRadius(radius float64) bool {
 	return radius > 0 && radius <= GetMaxBroadcastRadius()
 }
+
+// PointInPolygon checks if a point lies inside a polygon using the ray casting algorithm
+// The polygon is a list of vertices and does not need to be explicitly closed
+func PointInPolygon(point Location, polygon []Location) bool {
+	if len(polygon) < 3 {
+		return false
+	}
+
+	inside := false
+	j := len(polygon) - 1
+	for i := 0; i < len(polygon); i++ {
+		vi, vj := polygon[i], polygon[j]
+		if (vi.Latitude > point.Latitude) != (vj.Latitude > point.Latitude) &&
+			point.Longitude < (vj.Longitude-vi.Longitude)*(point.Latitude-vi.Latitude)/(vj.Latitude-vi.Latitude)+vi.Longitude {
+			inside = !inside
+		}
+		j = i
+	}
+
+	return inside
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and margin, in points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// PDF fonts, as named in the page resources
+const (
+	pdfFontRegular = "F1" // Helvetica
+	pdfFontBold    = "F2" // Helvetica-Bold
+	pdfFontMono    = "F3" // Courier, for aligned table rows
+)
+
+type pdfLine struct {
+	font string
+	size float64
+	y    float64
+	text string
+}
+
+// TextPDF builds a PDF of left-aligned lines of text on A4 pages, enough for statements and
+// summaries without a PDF library. The standard fonts only cover Latin-1, so other characters are
+// written as "?".
+type TextPDF struct {
+	pages [][]pdfLine
+	y     float64
+}
+
+// NewTextPDF creates an empty document
+func NewTextPDF() *TextPDF {
+	return &TextPDF{y: -1}
+}
+
+// Heading adds a bold title line
+func (p *TextPDF) Heading(text string) {
+	p.add(pdfFontBold, 16, text)
+}
+
+// Subheading adds a bold line in body size
+func (p *TextPDF) Subheading(text string) {
+	p.add(pdfFontBold, 11, text)
+}
+
+// Text adds a line of body text
+func (p *TextPDF) Text(text string) {
+	p.add(pdfFontRegular, 10, text)
+}
+
+// Row adds a line in a fixed-width font, so rows padded with fmt line up as a table
+func (p *TextPDF) Row(text string) {
+	p.add(pdfFontMono, 8, text)
+}
+
+// Space adds an empty line
+func (p *TextPDF) Space() {
+	p.add(pdfFontRegular, 10, "")
+}
+
+// add places a line below the previous one, starting a new page when the current one is full
+func (p *TextPDF) add(font string, size float64, text string) {
+	leading := size * 1.4
+	if p.y < 0 || p.y-leading < pdfMargin {
+		p.pages = append(p.pages, nil)
+		p.y = pdfPageHeight - pdfMargin
+	}
+	p.y -= leading
+	last := len(p.pages) - 1
+	p.pages[last] = append(p.pages[last], pdfLine{font: font, size: size, y: p.y, text: text})
+}
+
+// Bytes renders the document
+func (p *TextPDF) Bytes() []byte {
+	pages := p.pages
+	if len(pages) == 0 {
+		pages = [][]pdfLine{nil}
+	}
+
+	// Objects 1-5 are the catalog, page tree and fonts; each page then takes a page and a content object
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	)
+	for i, lines := range pages {
+		var content bytes.Buffer
+		for _, line := range lines {
+			if line.text == "" {
+				continue
+			}
+			fmt.Fprintf(&content, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", line.font, line.size, pdfMargin, line.y, pdfEscape(line.text))
+		}
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /%s 3 0 R /%s 4 0 R /%s 5 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, pdfFontRegular, pdfFontBold, pdfFontMono, 7+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfEscape encodes text as a Latin-1 PDF string literal body
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r < 32:
+			b.WriteByte(' ')
+		case r > 255:
+			b.WriteByte('?')
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,184 @@
+// Package validation binds request bodies and turns binding failures into field-level messages
+// in the caller's language. It also registers the domain rules shared by request creation, auth
+// and worker profile endpoints:
+//
+//	phone     Mauritanian number, with or without the +222 prefix (see NormalizePhone)
+//	priority  one of low, medium, high, urgent
+//	duration  a duration.Minutes between 15 minutes and 7 days
+//
+// Register the rules once at startup with Init; handlers then call BindJSON.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"repair-service-server/apierror"
+	"repair-service-server/duration"
+	"repair-service-server/i18n"
+	"repair-service-server/utils"
+)
+
+// Limits applied by the duration rule
+const (
+	MinDuration = 15 * time.Minute
+	MaxDuration = 7 * 24 * time.Hour
+)
+
+// Priorities accepted for service requests
+var Priorities = []string{"low", "medium", "high", "urgent"}
+
+// FieldError is one failed rule, rendered under "details" in the error envelope
+type FieldError struct {
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+var initOnce sync.Once
+
+// Init registers the custom rules and reports fields by their JSON names. It is safe to call
+// more than once.
+func Init() {
+	initOnce.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return f.Name
+			}
+			return name
+		})
+		_ = v.RegisterValidation("phone", func(fl validator.FieldLevel) bool {
+			return IsPhoneNumber(fl.Field().String())
+		})
+		_ = v.RegisterValidation("priority", func(fl validator.FieldLevel) bool {
+			return IsPriority(fl.Field().String())
+		})
+		_ = v.RegisterValidation("duration", func(fl validator.FieldLevel) bool {
+			d := duration.Minutes(fl.Field().Int()).Duration()
+			return d >= MinDuration && d <= MaxDuration
+		})
+	})
+}
+
+// BindJSON binds the request body into obj. On failure it writes a localized validation error
+// with one entry per failed field and returns false.
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	Init()
+	if err := c.ShouldBindJSON(obj); err != nil {
+		lang := i18n.Locale(c)
+		apierror.Abort(c, apierror.Validation(translate(lang, "invalid_request", "", "")).WithDetails(FieldErrors(lang, err)))
+		return false
+	}
+	return true
+}
+
+// BindForm is BindJSON for multipart and URL-encoded form bodies, binding fields by their form tags
+func BindForm(c *gin.Context, obj interface{}) bool {
+	Init()
+	if err := c.ShouldBind(obj); err != nil {
+		lang := i18n.Locale(c)
+		apierror.Abort(c, apierror.Validation(translate(lang, "invalid_request", "", "")).WithDetails(FieldErrors(lang, err)))
+		return false
+	}
+	return true
+}
+
+// Fail writes a localized validation error for a single field, for rules checked in handlers
+func Fail(c *gin.Context, field, rule, param string) {
+	lang := i18n.Locale(c)
+	message := translate(lang, rule, field, param)
+	apierror.Abort(c, apierror.Validation(message).WithDetails([]FieldError{{Field: field, Rule: rule, Message: message}}))
+}
+
+// FieldErrors maps a binding error to field-level messages in lang
+func FieldErrors(lang string, err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if errors.As(err, &verrs) {
+		out := make([]FieldError, 0, len(verrs))
+		for _, fe := range verrs {
+			out = append(out, FieldError{
+				Field:   fe.Field(),
+				Rule:    fe.Tag(),
+				Message: translate(lang, fe.Tag(), fe.Field(), fe.Param()),
+			})
+		}
+		return out
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []FieldError{{Field: typeErr.Field, Rule: "type", Message: translate(lang, "type", typeErr.Field, typeErr.Type.String())}}
+	}
+
+	if errors.Is(err, io.EOF) {
+		return []FieldError{{Rule: "body", Message: translate(lang, "empty_body", "", "")}}
+	}
+	return []FieldError{{Rule: "body", Message: translate(lang, "malformed_body", "", "")}}
+}
+
+// translate renders the message for rule in lang, falling back to the generic "default" message
+// for rules without one
+func translate(lang, rule, field, param string) string {
+	key := "validation." + rule
+	if !i18n.Has(key) {
+		key = "validation.default"
+	}
+	return i18n.T(lang, key, i18n.Vars{"field": field, "param": param})
+}
+
+// NormalizePhone returns s in E.164 form (+222XXXXXXXX): separators are stripped, a 00 prefix
+// becomes +, and the +222 country code is added when it is missing
+func NormalizePhone(s string) string {
+	s = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(strings.TrimSpace(s))
+	if s == "" {
+		return ""
+	}
+	if strings.HasPrefix(s, "00") {
+		s = "+" + s[2:]
+	}
+	if !strings.HasPrefix(s, "+") && strings.HasPrefix(s, "222") && len(s) == 11 {
+		s = "+" + s
+	}
+	return utils.FormatPhoneNumber(s)
+}
+
+// IsPhoneNumber reports whether s is a Mauritanian number once normalized
+func IsPhoneNumber(s string) bool {
+	digits := strings.TrimPrefix(NormalizePhone(s), "+222")
+	if len(digits) < 8 || len(digits) > 11 {
+		return false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPriority reports whether s is a known request priority
+func IsPriority(s string) bool {
+	for _, p := range Priorities {
+		if s == p {
+			return true
+		}
+	}
+	return false
+}
@@ -1,13 +1,20 @@
 package websocket
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"repair-service-server/database"
+	"repair-service-server/i18n"
+	"repair-service-server/middleware"
 	"repair-service-server/models"
 	"repair-service-server/services"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,57 +27,107 @@ var aiUpgrader = websocket.Upgrader{
 	},
 }
 
+// ServiceRequestCreator books a proposal the user accepted. Request creation lives in the
+// routes package, which imports this one, so main wires it in with SetRequestCreator.
+type ServiceRequestCreator func(customerID uint, draft services.ServiceRequestDraft) (*models.CustomerServiceRequest, error)
+
 type AIChatHandler struct {
-	aiService *services.AIService
-	clients   map[*websocket.Conn]bool
-	broadcast chan []byte
+	aiService     *services.AIService
+	clients       map[*websocket.Conn]*aiClient
+	broadcast     chan []byte
+	mu            sync.Mutex // Guards clients and their pending proposals
+	createRequest ServiceRequestCreator
+}
+
+// aiClient is one assistant connection
+type aiClient struct {
+	userID   uint                          // Zero when connected without a token; such clients cannot book
+	language string                        // Language of the server's own replies, updated by each message that names one
+	draft    *services.ServiceRequestDraft // Latest proposal, booked when the user accepts it
+	history  []map[string]interface{}      // Conversation up to the latest proposal
 }
 
 func NewAIChatHandler() *AIChatHandler {
 	return &AIChatHandler{
 		aiService: services.NewAIService(),
-		clients:   make(map[*websocket.Conn]bool),
+		clients:   make(map[*websocket.Conn]*aiClient),
 		broadcast: make(chan []byte),
 	}
 }
 
+// SetRequestCreator sets how accepted proposals are turned into service requests
+func (h *AIChatHandler) SetRequestCreator(create ServiceRequestCreator) {
+	h.createRequest = create
+}
+
 func (h *AIChatHandler) HandleAIChat(c *gin.Context) {
+	// Each prompt calls the AI provider, so prompts are budgeted per user or IP, not per connection
+	identity := middleware.RateLimitIdentity(c)
+
 	conn, err := aiUpgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("❌ WebSocket upgrade failed: %v", err)
 		return
 	}
-	defer conn.Close()
-
-	h.clients[conn] = true
+	client := &aiClient{userID: c.GetUint("user_id"), language: i18n.LocaleOr(c, i18n.French)}
+	h.mu.Lock()
+	h.clients[conn] = client
+	h.mu.Unlock()
+	trackOpen(EndpointAIChat)
 	log.Printf("🔌 AI Chat WebSocket connected")
 
+	stopHeartbeat := keepAlive(conn)
+	var readErr error
+	defer func() {
+		stopHeartbeat()
+		h.removeClient(conn)
+		conn.Close()
+		trackClose(EndpointAIChat, readErr)
+	}()
+
 	// Handle messages
 	for {
 		var msg map[string]interface{}
-		err := conn.ReadJSON(&msg)
-		if err != nil {
+		if err := conn.ReadJSON(&msg); err != nil {
+			readErr = err
 			log.Printf("❌ WebSocket read error: %v", err)
-			delete(h.clients, conn)
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
 
-		h.handleMessage(conn, msg)
+		h.handleMessage(conn, client, msg, identity)
 	}
 }
 
-func (h *AIChatHandler) handleMessage(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *AIChatHandler) handleMessage(conn *websocket.Conn, client *aiClient, msg map[string]interface{}, identity string) {
 	msgType, ok := msg["type"].(string)
 	if !ok {
 		log.Printf("⚠️ Invalid message type")
 		return
 	}
 
+	language, _ := msg["language"].(string)
+	h.mu.Lock()
+	if lang := i18n.Normalize(language); lang != "" {
+		client.language = lang
+	}
+	lang := client.language
+	h.mu.Unlock()
+
 	switch msgType {
 	case "user_input":
-		h.handleUserInput(conn, msg)
+		if result := middleware.AllowAIPrompt(identity); !result.Allowed {
+			log.Printf("🚫 AI chat rate limit exceeded by %s", identity)
+			h.sendMessage(conn, map[string]interface{}{
+				"type":        "ai_error",
+				"error":       i18n.T(lang, "ai.rate_limited", nil),
+				"retry_after": int(math.Ceil(result.Reset.Seconds())),
+			})
+			return
+		}
+		h.handleUserInput(conn, client, msg, lang)
 	case "card_action":
-		h.handleCardAction(conn, msg)
+		h.handleCardAction(conn, client, msg, lang)
 	case "ping":
 		h.handlePing(conn)
 	default:
@@ -78,14 +135,19 @@ func (h *AIChatHandler) handleMessage(conn *websocket.Conn, msg map[string]inter
 	}
 }
 
-func (h *AIChatHandler) handleUserInput(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *AIChatHandler) handleUserInput(conn *websocket.Conn, client *aiClient, msg map[string]interface{}, lang string) {
 	// Extract message data
 	message, _ := msg["message"].(string)
 	messageType, _ := msg["messageType"].(string)
 	imageUri, _ := msg["imageUri"].(string)
+	if imageData, _ := msg["imageData"].(string); imageData != "" {
+		imageUri = imageData // Base64 photo, with or without a data: URL prefix
+	}
 	voiceUri, _ := msg["voiceUri"].(string)
+	if voiceData, _ := msg["voiceData"].(string); voiceData != "" {
+		voiceUri = voiceData // Base64 recording, with or without a data: URL prefix
+	}
 	userID, _ := msg["userId"].(float64)
-	language, _ := msg["language"].(string)
 	conversationHistory, _ := msg["conversationHistory"].([]interface{})
 
 	// Convert conversation history
@@ -96,6 +158,11 @@ func (h *AIChatHandler) handleUserInput(conn *websocket.Conn, msg map[string]int
 		}
 	}
 
+	// Signed-in connections always act for their own user
+	if client.userID != 0 {
+		userID = float64(client.userID)
+	}
+
 	// Process with AI service
 	response, err := h.aiService.ProcessUserInput(
 		message,
@@ -103,16 +170,30 @@ func (h *AIChatHandler) handleUserInput(conn *websocket.Conn, msg map[string]int
 		imageUri,
 		voiceUri,
 		uint(userID),
-		language,
+		lang,
 		history,
 	)
 
 	if err != nil {
 		log.Printf("❌ AI processing error: %v", err)
-		h.sendError(conn, "Failed to process your request. Please try again.")
+		h.sendError(conn, i18n.T(lang, "ai.failed", nil))
 		return
 	}
 
+	// Voice messages are remembered by what was said
+	if response.Transcript != "" {
+		message = strings.TrimSpace(message + " " + response.Transcript)
+	}
+
+	// Keep the proposal, if any, until the user accepts or declines it
+	h.mu.Lock()
+	client.draft = response.Draft
+	client.history = append(history,
+		map[string]interface{}{"type": "user", "content": message},
+		map[string]interface{}{"type": "ai", "content": response.Text},
+	)
+	h.mu.Unlock()
+
 	// Send response back to client
 	h.sendResponse(conn, response)
 }
@@ -133,6 +214,18 @@ func (h *AIChatHandler) sendResponse(conn *websocket.Conn, response *services.AI
 	if response.Card != nil {
 		msg["card"] = response.Card
 	}
+	if response.Draft != nil {
+		msg["draft"] = response.Draft
+	}
+	if response.DiagnosisID != nil {
+		msg["diagnosisId"] = *response.DiagnosisID
+	}
+	if response.Transcript != "" {
+		msg["transcript"] = response.Transcript
+	}
+	if response.Degraded {
+		msg["degraded"] = true
+	}
 
 	h.sendMessage(conn, msg)
 }
@@ -144,7 +237,7 @@ func (h *AIChatHandler) sendError(conn *websocket.Conn, errorMsg string) {
 	})
 }
 
-func (h *AIChatHandler) handleCardAction(conn *websocket.Conn, msg map[string]interface{}) {
+func (h *AIChatHandler) handleCardAction(conn *websocket.Conn, client *aiClient, msg map[string]interface{}, lang string) {
 	// Extract card action data
 	action, _ := msg["action"].(string)
 	// Accept workerId as number or string; also capture workerName as fallback
@@ -157,12 +250,27 @@ func (h *AIChatHandler) handleCardAction(conn *websocket.Conn, msg map[string]in
 		}
 	}
 	workerName, _ := msg["workerName"].(string)
-	userID, _ := msg["userId"].(float64)
+	userID := client.userID
 
 	log.Printf("🔍 Card action received: %s for workerId=%v workerName=%s by user %v", action, workerIDNum, workerName, userID)
 
+	h.mu.Lock()
+	draft, history := client.draft, client.history
+	client.draft = nil
+	h.mu.Unlock()
+
 	if action == "Accept" {
-		// Check if worker is still available
+		// Booking acts on the user's behalf, so it needs a signed-in connection
+		if userID == 0 {
+			h.sendError(conn, i18n.T(lang, "ai.sign_in_to_book", nil))
+			return
+		}
+		if draft != nil {
+			h.bookDraft(conn, userID, *draft, history, lang)
+			return
+		}
+
+		// Cards without a proposal book the suggested worker's category, if they are still available
 		var worker models.WorkerProfile
 		var err error
 		if workerIDNum > 0 {
@@ -178,7 +286,7 @@ func (h *AIChatHandler) handleCardAction(conn *websocket.Conn, msg map[string]in
 			log.Printf("⚠️ Worker not available: %v", err)
 			h.sendMessage(conn, map[string]interface{}{
 				"type": "ai_response",
-				"text": "Désolé, ce professionnel n'est plus disponible. Je vais vous trouver un autre professionnel.",
+				"text": i18n.T(lang, "ai.worker_unavailable", nil),
 				"card": nil,
 			})
 			return
@@ -186,100 +294,149 @@ func (h *AIChatHandler) handleCardAction(conn *websocket.Conn, msg map[string]in
 
 		// Check if worker has no active requests
 		var activeCount int64
-		database.DB.Model(&models.CustomerServiceRequest{}).Where("assigned_worker_id = ? AND status IN (?)", worker.ID, []string{"accepted", "in_progress"}).Count(&activeCount)
+		database.DB.Model(&models.CustomerServiceRequest{}).Where("assigned_worker_id = ? AND status IN (?)", worker.ID, models.ActiveRequestStatuses).Count(&activeCount)
 		if activeCount > 0 {
 			log.Printf("⚠️ Worker %v is busy with %d active requests", worker.ID, activeCount)
 			h.sendMessage(conn, map[string]interface{}{
 				"type": "ai_response",
-				"text": "Désolé, ce professionnel est actuellement occupé. Je vais vous trouver un autre professionnel disponible.",
+				"text": i18n.T(lang, "ai.worker_busy", nil),
 				"card": nil,
 			})
 			return
 		}
 
-		// Create service request as broadcast (unassigned) so the worker can choose to accept
-		lat := 18.117001
-		lng := -15.949912
-		expiresAt := time.Now().Add(15 * time.Minute)
-		serviceRequest := models.CustomerServiceRequest{
-			CustomerID:      uint(userID),
-			CategoryID:      worker.CategoryID,
-			Title:           "Demande via IA",
-			Description:     "Service demandé via l'assistant IA",
-			Status:          "broadcast",
-			Priority:        "normal",
-			LocationAddress: "Adresse par défaut", // You might want to get this from user's default address
-			LocationCity:    "Nouakchott",
-			LocationLat:     &lat, // You might want to get this from user's location
-			LocationLng:     &lng,
-			ExpiresAt:       &expiresAt,
-		}
-
-		err = database.DB.Create(&serviceRequest).Error
-		if err != nil {
-			log.Printf("❌ Failed to create service request: %v", err)
-			h.sendMessage(conn, map[string]interface{}{
-				"type": "ai_error",
-				"error": "Erreur lors de la création de la demande de service",
-			})
-			return
-		}
-
-		log.Printf("✅ Service request created in broadcast: %v for category %v", serviceRequest.ID, worker.CategoryID)
-
-		// Watch for status changes and notify client
-		go func(requestID uint, client *websocket.Conn) {
-			deadline := time.Now().Add(15 * time.Minute)
-			for time.Now().Before(deadline) {
-				var req models.CustomerServiceRequest
-				if err := database.DB.Where("id = ?", requestID).First(&req).Error; err != nil {
-					log.Printf("⚠️ Watcher: failed to load request %v: %v", requestID, err)
-					return
-				}
-				if req.Status == "accepted" && req.AssignedWorkerID != nil {
-					h.sendMessage(client, map[string]interface{}{
-						"type": "ai_response",
-						"text": "Le professionnel a accepté votre demande et est en route.",
-						"card": nil,
-					})
-					return
-				}
-				if req.Status == "declined" || req.Status == "cancelled" || req.Status == "expired" {
-					h.sendMessage(client, map[string]interface{}{
-						"type": "ai_response",
-						"text": "Le professionnel a refusé ou la demande a expiré. Je cherche d'autres options pour vous.",
-						"card": nil,
-					})
-					return
-				}
-				time.Sleep(2 * time.Second)
-			}
-		}(serviceRequest.ID, conn)
-		h.sendMessage(conn, map[string]interface{}{
-			"type": "ai_response",
-			"text": "Parfait ! Votre demande a été envoyée au professionnel. Nous attendons sa confirmation.",
-			"card": nil,
-		})
+		h.bookDraft(conn, userID, services.ServiceRequestDraft{
+			CategoryID:  worker.CategoryID,
+			Title:       i18n.T(lang, "ai.request_title", nil),
+			Description: i18n.T(lang, "ai.request_description", nil),
+			Priority:    "medium",
+		}, history, lang)
 
 	} else if action == "Decline" {
 		h.sendMessage(conn, map[string]interface{}{
 			"type": "ai_response",
-			"text": "D'accord, je vais vous trouver d'autres options.",
+			"text": i18n.T(lang, "ai.declined", nil),
 			"card": nil,
 		})
 	}
 }
 
+// bookDraft creates the accepted request, links the conversation to it and tells the client
+// its ID. Broadcast requests are then watched until a worker accepts or they end.
+func (h *AIChatHandler) bookDraft(conn *websocket.Conn, userID uint, draft services.ServiceRequestDraft, history []map[string]interface{}, lang string) {
+	if h.createRequest == nil {
+		h.sendError(conn, i18n.T(lang, "ai.booking_failed", nil))
+		return
+	}
+
+	serviceRequest, err := h.createRequest(userID, draft)
+	switch {
+	case err == nil:
+	case errors.Is(err, services.ErrNoDefaultAddress):
+		h.sendError(conn, i18n.T(lang, "ai.no_default_address", nil))
+		return
+	case errors.Is(err, services.ErrOutsideServiceArea), errors.Is(err, services.ErrNoActiveZone), errors.Is(err, services.ErrCategoryNotInZone):
+		h.sendError(conn, i18n.T(lang, "ai.outside_area", nil))
+		return
+	case errors.Is(err, services.ErrPhoneUnverified):
+		h.sendError(conn, i18n.T(lang, "ai.verify_phone", nil))
+		return
+	case errors.Is(err, services.ErrRequestBudgetExceeded):
+		h.sendError(conn, i18n.T(lang, "ai.too_many_requests", nil))
+		return
+	case errors.Is(err, services.ErrDraftAlreadyBooked):
+		h.sendError(conn, i18n.T(lang, "ai.already_booked", nil))
+		return
+	default:
+		log.Printf("❌ Failed to create service request: %v", err)
+		h.sendError(conn, i18n.T(lang, "ai.booking_failed", nil))
+		return
+	}
+
+	transcript, _ := json.Marshal(history)
+	proposal, _ := json.Marshal(draft)
+	conversation := models.AIConversation{
+		UserID:           userID,
+		ServiceRequestID: serviceRequest.ID,
+		Transcript:       string(transcript),
+		Draft:            string(proposal),
+	}
+	if err := database.DB.Create(&conversation).Error; err != nil {
+		log.Printf("⚠️ Failed to link AI conversation to service request %d: %v", serviceRequest.ID, err)
+	}
+
+	log.Printf("✅ AI assistant booked service request %d (%s) for user %d", serviceRequest.ID, serviceRequest.Status, userID)
+
+	text := i18n.T(lang, "ai.booked", nil)
+	if serviceRequest.ScheduledFor != nil {
+		text = i18n.T(lang, "ai.booked_scheduled", i18n.Vars{"scheduled_for": serviceRequest.ScheduledForLocal().Format("02/01/2006 15:04")})
+	} else {
+		go h.watchRequest(serviceRequest.ID, conn, lang)
+	}
+	h.sendMessage(conn, map[string]interface{}{
+		"type":           "ai_response",
+		"text":           text,
+		"card":           nil,
+		"request_id":     serviceRequest.ID,
+		"request_status": serviceRequest.Status,
+	})
+}
+
+// watchRequest tells the client when a worker accepts its broadcast request, or when it ends
+func (h *AIChatHandler) watchRequest(requestID uint, client *websocket.Conn, lang string) {
+	deadline := time.Now().Add(15 * time.Minute)
+	for time.Now().Before(deadline) {
+		var req models.CustomerServiceRequest
+		if err := database.DB.Where("id = ?", requestID).First(&req).Error; err != nil {
+			log.Printf("⚠️ Watcher: failed to load request %v: %v", requestID, err)
+			return
+		}
+		if req.Status.IsActive() && req.AssignedWorkerID != nil {
+			h.sendMessage(client, map[string]interface{}{
+				"type":       "ai_response",
+				"text":       i18n.T(lang, "ai.worker_accepted", nil),
+				"card":       nil,
+				"request_id": requestID,
+			})
+			return
+		}
+		if req.Status == "declined" || req.Status == "cancelled" || req.Status == "expired" {
+			h.sendMessage(client, map[string]interface{}{
+				"type":       "ai_response",
+				"text":       i18n.T(lang, "ai.request_ended", nil),
+				"card":       nil,
+				"request_id": requestID,
+			})
+			return
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
+
 func (h *AIChatHandler) sendMessage(conn *websocket.Conn, msg map[string]interface{}) {
 	err := conn.WriteJSON(msg)
 	if err != nil {
 		log.Printf("❌ WebSocket write error: %v", err)
-		delete(h.clients, conn)
+		h.removeClient(conn)
 	}
 }
 
+// removeClient forgets conn
+func (h *AIChatHandler) removeClient(conn *websocket.Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, conn)
+}
+
 func (h *AIChatHandler) BroadcastToAll(msg map[string]interface{}) {
+	h.mu.Lock()
+	clients := make([]*websocket.Conn, 0, len(h.clients))
 	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.Unlock()
+
+	for _, client := range clients {
 		h.sendMessage(client, msg)
 	}
 }
@@ -0,0 +1,184 @@
+package websocket
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// Events kept per topic for clients resuming a stream
+	brokerHistorySize = 100
+
+	// Topics with no new events and no subscribers for this long are forgotten
+	brokerRetention = 10 * time.Minute
+
+	// Events a subscriber may fall behind by before it is dropped and has to resume
+	brokerSubscriberBuffer = 64
+)
+
+// BrokerEvent is one message published on a topic, encoded exactly as the WebSocket hubs send it
+type BrokerEvent struct {
+	ID      uint64
+	Payload []byte
+}
+
+type brokerTopic struct {
+	history     []BrokerEvent
+	trimmed     uint64 // Highest event ID no longer in history
+	closed      bool
+	updatedAt   time.Time
+	subscribers map[chan BrokerEvent]struct{}
+}
+
+// Broker records the events the hubs deliver, per user and per service request, so transports
+// other than WebSocket can follow them and clients can resume after a dropped connection.
+// Event IDs start from the clock at startup, so they keep increasing across restarts.
+type Broker struct {
+	mu        sync.Mutex
+	lastID    uint64
+	topics    map[string]*brokerTopic
+	lastSweep time.Time
+}
+
+// NewBroker creates an event broker
+func NewBroker() *Broker {
+	return &Broker{
+		lastID:    uint64(time.Now().UnixMicro()),
+		topics:    make(map[string]*brokerTopic),
+		lastSweep: time.Now(),
+	}
+}
+
+// UserTopic is the topic of everything sent to one user
+func UserTopic(userID uint) string {
+	return "user:" + strconv.FormatUint(uint64(userID), 10)
+}
+
+// RequestTopic is the topic of one service request's live updates
+func RequestTopic(requestID uint) string {
+	return "request:" + strconv.FormatUint(uint64(requestID), 10)
+}
+
+func (b *Broker) topicLocked(name string) *brokerTopic {
+	topic, ok := b.topics[name]
+	if !ok {
+		// Whatever the topic had before it was created or last forgotten is unknown
+		topic = &brokerTopic{trimmed: b.lastID, updatedAt: time.Now(), subscribers: make(map[chan BrokerEvent]struct{})}
+		b.topics[name] = topic
+	}
+	return topic
+}
+
+// Publish records payload on the topic and hands it to its subscribers. A subscriber that has
+// fallen too far behind is dropped; it resumes from its last event ID when it reconnects.
+func (b *Broker) Publish(topicName string, payload []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sweepLocked()
+	topic := b.topicLocked(topicName)
+	if topic.closed {
+		return
+	}
+	b.lastID++
+	event := BrokerEvent{ID: b.lastID, Payload: payload}
+	topic.history = append(topic.history, event)
+	if len(topic.history) > brokerHistorySize {
+		topic.trimmed = topic.history[0].ID
+		topic.history = topic.history[1:]
+	}
+	topic.updatedAt = time.Now()
+
+	for ch := range topic.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(topic.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close ends every subscription to the topic. Its history is kept until it expires, so a client
+// that missed the last events can still replay them.
+func (b *Broker) Close(topicName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	topic := b.topicLocked(topicName)
+	topic.closed = true
+	topic.updatedAt = time.Now()
+	for ch := range topic.subscribers {
+		delete(topic.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Subscription is a live feed of one topic
+type Subscription struct {
+	// Events is closed when the topic is closed or the subscriber falls behind
+	Events <-chan BrokerEvent
+	// LastID is the newest event ID at the time of subscribing
+	LastID uint64
+
+	broker *Broker
+	topic  string
+	ch     chan BrokerEvent
+}
+
+// Cancel stops the subscription
+func (s *Subscription) Cancel() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+
+	if topic, ok := s.broker.topics[s.topic]; ok {
+		if _, subscribed := topic.subscribers[s.ch]; subscribed {
+			delete(topic.subscribers, s.ch)
+			close(s.ch)
+		}
+	}
+}
+
+// Subscribe starts following the topic. With a non-zero after, it also returns the topic's
+// events newer than that ID and whether they are all of them; false means some were trimmed or
+// the ID is unknown, and the client should reload its state.
+func (b *Broker) Subscribe(topicName string, after uint64) (*Subscription, []BrokerEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	topic := b.topicLocked(topicName)
+	ch := make(chan BrokerEvent, brokerSubscriberBuffer)
+	if topic.closed {
+		close(ch)
+	} else {
+		topic.subscribers[ch] = struct{}{}
+	}
+	sub := &Subscription{Events: ch, LastID: b.lastID, broker: b, topic: topicName, ch: ch}
+
+	if after == 0 {
+		return sub, nil, true
+	}
+	var replay []BrokerEvent
+	for _, event := range topic.history {
+		if event.ID > after {
+			replay = append(replay, event)
+		}
+	}
+	complete := after >= topic.trimmed && after <= b.lastID
+	return sub, replay, complete
+}
+
+// sweepLocked forgets idle topics, at most once a minute
+func (b *Broker) sweepLocked() {
+	now := time.Now()
+	if now.Sub(b.lastSweep) < time.Minute {
+		return
+	}
+	b.lastSweep = now
+	for name, topic := range b.topics {
+		if len(topic.subscribers) == 0 && now.Sub(topic.updatedAt) > brokerRetention {
+			delete(b.topics, name)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -22,6 +23,9 @@ const (
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 512
+
+	// A client silent for this long is closed by the hub's sweep even if its read pump is stuck
+	staleAfter = pongWait + writeWait
 )
 
 // Error constants
@@ -52,6 +56,8 @@ func ServeWebSocket(hub *Hub, w http.ResponseWriter, r *http.Request, userID uin
 		Conn:     conn,
 		Send:     make(chan []byte, 256),
 	}
+	client.touch()
+	trackOpen(EndpointChat)
 
 	client.Hub.Register <- client
 
@@ -62,14 +68,17 @@ func ServeWebSocket(hub *Hub, w http.ResponseWriter, r *http.Request, userID uin
 
 // readPump pumps messages from the WebSocket connection to the hub
 func (c *Client) readPump() {
+	var readErr error
 	defer func() {
 		c.Hub.Unregister <- c
 		c.Conn.Close()
+		trackClose(EndpointChat, readErr)
 	}()
 
 	c.Conn.SetReadLimit(maxMessageSize)
 	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.Conn.SetPongHandler(func(string) error {
+		c.touch()
 		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
 		return nil
 	})
@@ -77,12 +86,19 @@ func (c *Client) readPump() {
 	for {
 		_, messageBytes, err := c.Conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			readErr = err
+			if isTimeout(err) {
+				log.Printf("💤 WebSocket client %d missed its heartbeat, disconnecting", c.ID)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("❌ WebSocket read error: %v", err)
 			}
 			break
 		}
 
+		// Any message proves the peer is alive, not only pongs
+		c.touch()
+		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+
 		// Parse the incoming message
 		var message Message
 		if err := json.Unmarshal(messageBytes, &message); err != nil {
@@ -236,21 +252,66 @@ func (c *Client) SendError(errorType string, message string) error {
 	return c.SendMessage(errorMessage)
 }
 
-// Close closes the client connection
+// Close closes the client connection. The read pump then unregisters the client, and the hub
+// closes Send.
 func (c *Client) Close() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	
+
 	if c.Conn != nil {
 		c.Conn.Close()
 	}
-	
-	close(c.Send)
 }
 
-// IsConnected checks if the client is still connected
+// IsConnected checks if the client is still connected and has answered a recent heartbeat
 func (c *Client) IsConnected() bool {
-	return c.Conn != nil
+	return c.Conn != nil && !c.isStale(time.Now())
+}
+
+// touch records that the peer was heard from
+func (c *Client) touch() {
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+// LastSeen returns when the peer last sent a message or pong
+func (c *Client) LastSeen() time.Time {
+	return time.Unix(0, c.lastSeen.Load())
+}
+
+// isStale reports whether the peer has been silent past its read deadline, which means the
+// read pump should already have dropped it
+func (c *Client) isStale(now time.Time) bool {
+	return now.Sub(c.LastSeen()) > staleAfter
+}
+
+// keepAlive arms conn's read deadline, extends it on every pong and pings the peer every
+// pingPeriod until the returned stop function is called. It suits handlers that read on their
+// own goroutine; WriteControl is safe to call alongside their writes.
+func keepAlive(conn *websocket.Conn) (stop func()) {
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
 }
 
 // GetConnectionInfo returns connection information
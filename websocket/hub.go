@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -17,6 +18,7 @@ type Client struct {
 	Conn     *websocket.Conn
 	Send     chan []byte
 	mu       sync.Mutex
+	lastSeen atomic.Int64 // Unix nanoseconds of the last message or pong
 }
 
 // Hub manages all WebSocket connections
@@ -39,6 +41,9 @@ type Hub struct {
 	// Message handlers
 	MessageHandlers map[string]MessageHandler
 
+	// Events, when set, also records every message sent to a user for their event stream
+	Events *Broker
+
 	mu sync.RWMutex
 }
 
@@ -83,41 +88,82 @@ func (h *Hub) registerDefaultHandlers() {
 
 // Run starts the hub's main loop
 func (h *Hub) Run() {
+	sweep := time.NewTicker(pingPeriod)
+	defer sweep.Stop()
+
 	for {
 		select {
 		case client := <-h.Register:
 			h.mu.Lock()
+			if previous, ok := h.Clients[client.ID]; ok && previous != client {
+				// The user reconnected (e.g. after a network switch); keep only the new socket.
+				// Room membership is per user, so it carries over.
+				delete(h.Clients, previous.ID)
+				close(previous.Send)
+				metrics[EndpointChat].replaced.Add(1)
+				log.Printf("🔁 Client %d reconnected, closing previous connection", client.ID)
+			}
 			h.Clients[client.ID] = client
 			h.mu.Unlock()
 			log.Printf("🔌 Client registered: ID=%d, Type=%s", client.ID, client.UserType)
 
 		case client := <-h.Unregister:
 			h.mu.Lock()
-			if _, ok := h.Clients[client.ID]; ok {
-				// Remove user from all chat rooms
-				for chatRoomID := range h.ChatRoomMembers {
-					if h.ChatRoomMembers[chatRoomID][client.ID] {
-						delete(h.ChatRoomMembers[chatRoomID], client.ID)
-						log.Printf("👥 User %d removed from chat room %d on disconnect", client.ID, chatRoomID)
-					}
-				}
-				
-				delete(h.Clients, client.ID)
-				close(client.Send)
+			// A replaced connection unregisters after its successor registered; leave the successor alone
+			if current, ok := h.Clients[client.ID]; ok && current == client {
+				h.removeClientLocked(client)
 			}
 			h.mu.Unlock()
 			log.Printf("🔌 Client unregistered: ID=%d, Type=%s", client.ID, client.UserType)
 
 		case message := <-h.Broadcast:
 			h.broadcastMessage(message)
+
+		case now := <-sweep.C:
+			h.reapStale(now)
+		}
+	}
+}
+
+// removeClientLocked forgets client and its chat room memberships and closes its send channel,
+// which makes the write pump close the socket. h.mu must be held for writing.
+func (h *Hub) removeClientLocked(client *Client) {
+	for chatRoomID, members := range h.ChatRoomMembers {
+		if members[client.ID] {
+			delete(members, client.ID)
+			log.Printf("👥 User %d removed from chat room %d on disconnect", client.ID, chatRoomID)
+		}
+		if len(members) == 0 {
+			delete(h.ChatRoomMembers, chatRoomID)
+		}
+	}
+
+	delete(h.Clients, client.ID)
+	close(client.Send)
+}
+
+// reapStale drops clients that have been silent past their heartbeat deadline. The read
+// deadline normally catches them first; this covers read pumps stuck in a message handler.
+func (h *Hub) reapStale(now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, client := range h.Clients {
+		if !client.isStale(now) {
+			continue
 		}
+		log.Printf("💤 Reaping stale client %d (last seen %s ago)", client.ID, now.Sub(client.LastSeen()).Round(time.Second))
+		h.removeClientLocked(client)
+		client.Conn.Close()
+		metrics[EndpointChat].reaped.Add(1)
 	}
 }
 
-// broadcastMessage sends a message to all connected clients
+// broadcastMessage sends a message to all connected clients, dropping those whose send
+// buffer is full
 func (h *Hub) broadcastMessage(message *Message) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
 	data, err := json.Marshal(message)
 	if err != nil {
@@ -129,29 +175,35 @@ func (h *Hub) broadcastMessage(message *Message) {
 		select {
 		case client.Send <- data:
 		default:
-			close(client.Send)
-			delete(h.Clients, client.ID)
+			log.Printf("⚠️ Dropping client %d: send buffer is full", client.ID)
+			h.removeClientLocked(client)
+			metrics[EndpointChat].dropped.Add(1)
 		}
 	}
 }
 
 // SendToUser sends a message to a specific user
 func (h *Hub) SendToUser(userID uint, message *Message) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("❌ Error marshaling message: %v", err)
+		return
+	}
+
+	if h.Events != nil {
+		h.Events.Publish(UserTopic(userID), data)
+	}
+
+	// Hold the lock while sending so the hub cannot close Send in between
 	h.mu.RLock()
-	client, exists := h.Clients[userID]
-	h.mu.RUnlock()
+	defer h.mu.RUnlock()
 
+	client, exists := h.Clients[userID]
 	if !exists {
 		log.Printf("⚠️ User %d not connected, message will be sent via push notification", userID)
 		return
 	}
 
-	data, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("❌ Error marshaling message: %v", err)
-		return
-	}
-
 	select {
 	case client.Send <- data:
 		log.Printf("✅ Message sent to user %d", userID)
@@ -207,6 +259,9 @@ func (h *Hub) SendToChatRoom(chatRoomID uint, message *Message, excludeUserID ui
 		if userID == excludeUserID {
 			continue // Skip the sender
 		}
+		if h.Events != nil {
+			h.Events.Publish(UserTopic(userID), data)
+		}
 
 		client, exists := h.Clients[userID]
 		if !exists {
@@ -235,12 +290,37 @@ func (h *Hub) GetConnectedUsers() []uint {
 	return users
 }
 
-// IsUserConnected checks if a user is currently connected
+// IsUserConnected checks if a user is currently connected with a live heartbeat
 func (h *Hub) IsUserConnected(userID uint) bool {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	_, exists := h.Clients[userID]
-	return exists
+	client, exists := h.Clients[userID]
+	return exists && !client.isStale(time.Now())
+}
+
+// IsUserInChatRoom checks if a user is connected with a live heartbeat and follows the chat room
+func (h *Hub) IsUserInChatRoom(userID uint, chatRoomID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	client, exists := h.Clients[userID]
+	return exists && !client.isStale(time.Now()) && h.ChatRoomMembers[chatRoomID][userID]
+}
+
+// CountConnectedByType returns the number of connected clients per user type, skipping
+// connections that have missed their heartbeat but are not reaped yet
+func (h *Hub) CountConnectedByType() map[string]int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	now := time.Now()
+	counts := make(map[string]int)
+	for _, client := range h.Clients {
+		if client.isStale(now) {
+			continue
+		}
+		counts[client.UserType]++
+	}
+	return counts
 }
 
 // handleChatMessage handles incoming chat messages
@@ -364,8 +444,9 @@ func (h *Hub) broadcastToWorkersInCategory(categoryID uint, message *Message) {
 
 	// This would need to be enhanced to check worker categories
 	// For now, broadcast to all workers
+	now := time.Now()
 	for userID, client := range h.Clients {
-		if client.UserType == "worker" {
+		if client.UserType == "worker" && !client.isStale(now) {
 			select {
 			case client.Send <- data:
 				log.Printf("✅ Service request sent to worker %d", userID)
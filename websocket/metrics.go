@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+)
+
+// WebSocket endpoints tracked in connection metrics
+const (
+	EndpointChat    = "chat"
+	EndpointWorker  = "worker"
+	EndpointAIChat  = "ai_chat"
+	EndpointOps     = "ops"
+	EndpointRequest = "request"
+	EndpointSSE     = "sse"
+)
+
+// ConnectionStats is a snapshot of one endpoint's connection churn since startup
+type ConnectionStats struct {
+	Active   int64  `json:"active"`
+	Opened   uint64 `json:"opened"`
+	Closed   uint64 `json:"closed"`
+	TimedOut uint64 `json:"timed_out"` // No pong or message within pongWait
+	Reaped   uint64 `json:"reaped"`    // Closed by the hub's stale-connection sweep
+	Replaced uint64 `json:"replaced"`  // Closed because the same user connected again
+	Dropped  uint64 `json:"dropped"`   // Closed because the client could not keep up with its messages
+}
+
+type connectionMetrics struct {
+	active   atomic.Int64
+	opened   atomic.Uint64
+	closed   atomic.Uint64
+	timedOut atomic.Uint64
+	reaped   atomic.Uint64
+	replaced atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+var metrics = map[string]*connectionMetrics{
+	EndpointChat:    {},
+	EndpointWorker:  {},
+	EndpointAIChat:  {},
+	EndpointOps:     {},
+	EndpointRequest: {},
+	EndpointSSE:     {},
+}
+
+// Stats returns connection metrics for every WebSocket endpoint
+func Stats() map[string]ConnectionStats {
+	stats := make(map[string]ConnectionStats, len(metrics))
+	for endpoint, m := range metrics {
+		stats[endpoint] = ConnectionStats{
+			Active:   m.active.Load(),
+			Opened:   m.opened.Load(),
+			Closed:   m.closed.Load(),
+			TimedOut: m.timedOut.Load(),
+			Reaped:   m.reaped.Load(),
+			Replaced: m.replaced.Load(),
+			Dropped:  m.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// trackOpen counts a new connection on endpoint
+func trackOpen(endpoint string) {
+	metrics[endpoint].opened.Add(1)
+	metrics[endpoint].active.Add(1)
+}
+
+// trackClose counts a closed connection on endpoint; readErr is the error that ended its read
+// loop and tells a missed heartbeat apart from a normal close
+func trackClose(endpoint string, readErr error) {
+	metrics[endpoint].closed.Add(1)
+	metrics[endpoint].active.Add(-1)
+	if isTimeout(readErr) {
+		metrics[endpoint].timedOut.Add(1)
+	}
+}
+
+// isTimeout reports whether err is a read deadline expiring
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,108 @@
+package websocket
+
+import (
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var opsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true // Admin dashboard may be served from another origin
+	},
+}
+
+// OpsEvent is a live operations event streamed to admin dashboards
+type OpsEvent struct {
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// OpsHub fans out live operations events to connected admin dashboards
+type OpsHub struct {
+	clients map[*websocket.Conn]uint
+	mu      sync.Mutex
+}
+
+// NewOpsHub creates a new operations hub
+func NewOpsHub() *OpsHub {
+	return &OpsHub{
+		clients: make(map[*websocket.Conn]uint),
+	}
+}
+
+// Publish sends an event to every connected admin, dropping clients that cannot keep up
+func (h *OpsHub) Publish(eventType string, data interface{}) {
+	event := OpsEvent{Type: eventType, Data: data, Timestamp: time.Now()}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for conn, adminID := range h.clients {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteJSON(event); err != nil {
+			log.Printf("⚠️ Dropping ops dashboard client for admin %d: %v", adminID, err)
+			conn.Close()
+			delete(h.clients, conn)
+		}
+	}
+}
+
+// ClientCount returns the number of connected admin dashboards
+func (h *OpsHub) ClientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Serve upgrades the request and keeps the admin connection open until it closes
+func (h *OpsHub) Serve(w http.ResponseWriter, r *http.Request, adminID uint) {
+	conn, err := opsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("❌ Ops WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	h.clients[conn] = adminID
+	h.mu.Unlock()
+	trackOpen(EndpointOps)
+	log.Printf("📊 Admin %d connected to ops dashboard", adminID)
+
+	// The stream is server-to-client only; reading keeps control frames flowing and detects closes
+	stopHeartbeat := keepAlive(conn)
+	var readErr error
+	defer func() {
+		stopHeartbeat()
+		h.mu.Lock()
+		delete(h.clients, conn)
+		h.mu.Unlock()
+		conn.Close()
+		trackClose(EndpointOps, readErr)
+		log.Printf("📊 Admin %d disconnected from ops dashboard", adminID)
+	}()
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			readErr = err
+			return
+		}
+	}
+}
+
+// RunPresence periodically publishes how many users of each type are connected to the hub
+func (h *OpsHub) RunPresence(hub *Hub, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if h.ClientCount() == 0 {
+			continue
+		}
+		h.Publish("online_counts", hub.CountConnectedByType())
+	}
+}
@@ -0,0 +1,154 @@
+package websocket
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// RequestEvent is a live update about one service request streamed to its customer
+type RequestEvent struct {
+	Type      string      `json:"type"`
+	RequestID uint        `json:"request_id"`
+	Data      interface{} `json:"data,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// RequestHub fans out live updates about service requests to the customers watching them. Each
+// connection follows one request and is closed by the server once the request is finished.
+type RequestHub struct {
+	subscribers map[uint]map[*websocket.Conn]uint
+	events      *Broker
+	mu          sync.Mutex
+}
+
+// NewRequestHub creates a new request hub that also records its events in the broker, for
+// clients following requests over event streams
+func NewRequestHub(events *Broker) *RequestHub {
+	return &RequestHub{
+		subscribers: make(map[uint]map[*websocket.Conn]uint),
+		events:      events,
+	}
+}
+
+// Publish sends an event to everyone watching the request, dropping clients that cannot keep up
+func (h *RequestHub) Publish(requestID uint, eventType string, data interface{}) {
+	payload, err := json.Marshal(RequestEvent{Type: eventType, RequestID: requestID, Data: data, Timestamp: time.Now()})
+	if err != nil {
+		log.Printf("❌ Error marshaling request %d event: %v", requestID, err)
+		return
+	}
+	if h.events != nil {
+		h.events.Publish(RequestTopic(requestID), payload)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for conn, userID := range h.subscribers[requestID] {
+		conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+			log.Printf("⚠️ Dropping request %d watcher for user %d: %v", requestID, userID, err)
+			conn.Close()
+			h.removeLocked(requestID, conn)
+		}
+	}
+}
+
+// Close ends every stream following the request with a normal close frame
+func (h *RequestHub) Close(requestID uint, reason string) {
+	if h.events != nil {
+		h.events.Close(RequestTopic(requestID))
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	message := websocket.FormatCloseMessage(websocket.CloseNormalClosure, reason)
+	for conn := range h.subscribers[requestID] {
+		conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(writeWait))
+		conn.Close()
+	}
+	delete(h.subscribers, requestID)
+}
+
+// WatcherCount returns how many connections follow the request
+func (h *RequestHub) WatcherCount(requestID uint) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[requestID])
+}
+
+func (h *RequestHub) removeLocked(requestID uint, conn *websocket.Conn) {
+	delete(h.subscribers[requestID], conn)
+	if len(h.subscribers[requestID]) == 0 {
+		delete(h.subscribers, requestID)
+	}
+}
+
+// Serve upgrades the request, sends snapshot as the first event and keeps the connection open
+// until the client leaves or the request is finished
+func (h *RequestHub) Serve(w http.ResponseWriter, r *http.Request, requestID, userID uint, snapshot interface{}) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("❌ Request stream WebSocket upgrade failed: %v", err)
+		return
+	}
+
+	// Registering and sending the snapshot under the lock keeps published events after it
+	h.mu.Lock()
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	err = conn.WriteJSON(RequestEvent{Type: "snapshot", RequestID: requestID, Data: snapshot, Timestamp: time.Now()})
+	if err == nil {
+		if h.subscribers[requestID] == nil {
+			h.subscribers[requestID] = make(map[*websocket.Conn]uint)
+		}
+		h.subscribers[requestID][conn] = userID
+	}
+	h.mu.Unlock()
+	if err != nil {
+		log.Printf("⚠️ Failed to send request %d snapshot to user %d: %v", requestID, userID, err)
+		conn.Close()
+		return
+	}
+	trackOpen(EndpointRequest)
+
+	// The stream is server-to-client only; reading keeps control frames flowing and detects closes
+	stopHeartbeat := keepAlive(conn)
+	var readErr error
+	defer func() {
+		stopHeartbeat()
+		h.mu.Lock()
+		h.removeLocked(requestID, conn)
+		h.mu.Unlock()
+		conn.Close()
+		trackClose(EndpointRequest, readErr)
+	}()
+
+	conn.SetReadLimit(maxMessageSize)
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			readErr = err
+			return
+		}
+	}
+}
+
+// ServeSSE streams the request's events as Server-Sent Events, starting with snapshot unless the
+// client resumed without missing anything. For a finished request it only replays what the
+// client missed.
+func (h *RequestHub) ServeSSE(w http.ResponseWriter, r *http.Request, requestID uint, snapshot interface{}, finished bool) {
+	intro := func() interface{} {
+		return RequestEvent{Type: "snapshot", RequestID: requestID, Data: snapshot, Timestamp: time.Now()}
+	}
+	h.events.ServeSSE(w, r, SSEStream{
+		Topic:      RequestTopic(requestID),
+		Intro:      intro,
+		Resync:     intro,
+		ReplayOnly: finished,
+	})
+}
@@ -0,0 +1,105 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Comment lines sent this often keep proxies from closing an idle event stream
+const sseKeepAlive = 25 * time.Second
+
+// How long a browser waits before reconnecting a dropped event stream, in milliseconds
+const sseRetryMillis = 3000
+
+// SSEStream describes a Server-Sent Events stream of one broker topic
+type SSEStream struct {
+	Topic string
+	// Intro, when set, is sent before any other event unless the client resumed without missing
+	// anything, so it starts from the current state
+	Intro func() interface{}
+	// Resync, when set, is sent instead of Intro when the client resumed but some events were lost
+	Resync func() interface{}
+	// ReplayOnly ends the stream once missed events are replayed, for topics that are finished.
+	// With nothing left to replay it answers 204, which stops browsers from reconnecting.
+	ReplayOnly bool
+}
+
+// ServeSSE streams a broker topic as Server-Sent Events. Each event's data is the same JSON a
+// WebSocket client receives, and its id lets a reconnecting client resume with Last-Event-ID.
+func (b *Broker) ServeSSE(w http.ResponseWriter, r *http.Request, stream SSEStream) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	after, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	sub, replay, complete := b.Subscribe(stream.Topic, after)
+	defer sub.Cancel()
+	if stream.ReplayOnly && len(replay) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+
+	// The id gives clients a position to resume from even before the first event
+	fmt.Fprintf(w, "retry: %d\nid: %d\n\n", sseRetryMillis, sub.LastID)
+	intro := stream.Intro
+	if after > 0 {
+		intro = nil
+		if !complete {
+			intro = stream.Resync
+		}
+	}
+	if intro != nil {
+		if payload, err := json.Marshal(intro()); err == nil {
+			writeSSEEvent(w, sub.LastID, payload)
+		}
+	}
+	for _, event := range replay {
+		writeSSEEvent(w, event.ID, event.Payload)
+	}
+	flusher.Flush()
+	if stream.ReplayOnly {
+		return
+	}
+
+	trackOpen(EndpointSSE)
+	defer trackClose(EndpointSSE, nil)
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				// The topic closed or the client fell behind; it resumes from its last event ID
+				return
+			}
+			writeSSEEvent(w, event.ID, event.Payload)
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				log.Printf("⚠️ Event stream %s write failed: %v", stream.Topic, err)
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// writeSSEEvent writes one event; JSON payloads have no newlines, so one data line carries it
+func writeSSEEvent(w http.ResponseWriter, id uint64, payload []byte) {
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", id, payload)
+}
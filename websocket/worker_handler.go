@@ -3,8 +3,10 @@ package websocket
 import (
 	"log"
 	"net/http"
+	"sync"
 	"time"
 
+	"repair-service-server/apierror"
 	"repair-service-server/database"
 	"repair-service-server/models"
 
@@ -18,22 +20,39 @@ var workerUpgrader = websocket.Upgrader{
 	},
 }
 
+// workerConn is one worker's socket. writeMu serializes writes from the read loop and from
+// broadcasts, which gorilla/websocket does not allow to run concurrently.
+type workerConn struct {
+	conn    *websocket.Conn
+	userID  uint
+	writeMu sync.Mutex
+}
+
+// writeJSON writes v with a deadline so a stalled peer cannot block broadcasts
+func (w *workerConn) writeJSON(v interface{}) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	w.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return w.conn.WriteJSON(v)
+}
+
 type WorkerHandler struct {
-	clients map[*websocket.Conn]bool
+	clients map[uint]*workerConn // By user ID; a reconnect replaces the previous socket
+	mu      sync.RWMutex
 }
 
 func NewWorkerHandler() *WorkerHandler {
 	return &WorkerHandler{
-		clients: make(map[*websocket.Conn]bool),
+		clients: make(map[uint]*workerConn),
 	}
 }
 
 func (h *WorkerHandler) HandleWorker(c *gin.Context) {
 	// Authenticate the user
-	userID, exists := c.Get("user_id")
-	if !exists {
+	userID := c.GetUint("user_id")
+	if userID == 0 {
 		log.Printf("❌ No user ID found for worker WebSocket")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		apierror.Abort(c, apierror.Unauthorized("Unauthorized"))
 		return
 	}
 
@@ -41,7 +60,7 @@ func (h *WorkerHandler) HandleWorker(c *gin.Context) {
 	var workerProfile models.WorkerProfile
 	if err := database.DB.Where("user_id = ?", userID).First(&workerProfile).Error; err != nil {
 		log.Printf("❌ Worker profile not found for user %v", userID)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Worker profile required"})
+		apierror.Abort(c, apierror.Forbidden("Worker profile required"))
 		return
 	}
 
@@ -51,28 +70,43 @@ func (h *WorkerHandler) HandleWorker(c *gin.Context) {
 		log.Printf("❌ Worker WebSocket upgrade failed: %v", err)
 		return
 	}
-	defer conn.Close()
 
-	// Add client to the map
-	h.clients[conn] = true
+	client := &workerConn{conn: conn, userID: userID}
+	h.register(client)
+	trackOpen(EndpointWorker)
 	log.Printf("👷 Worker connected: %v", userID)
 
+	stopHeartbeat := keepAlive(conn)
+	var readErr error
+	defer func() {
+		stopHeartbeat()
+		h.unregister(client)
+		conn.Close()
+		trackClose(EndpointWorker, readErr)
+		log.Printf("👷 Worker disconnected: %v", userID)
+	}()
+
 	// Send welcome message
 	welcomeMsg := map[string]interface{}{
 		"type":      "connected",
 		"message":   "Worker WebSocket connected successfully",
 		"timestamp": time.Now().UTC(),
 	}
-	conn.WriteJSON(welcomeMsg)
+	client.writeJSON(welcomeMsg)
 
 	// Handle incoming messages
 	for {
 		var msg map[string]interface{}
-		err := conn.ReadJSON(&msg)
-		if err != nil {
-			log.Printf("❌ Worker WebSocket read error: %v", err)
+		if err := conn.ReadJSON(&msg); err != nil {
+			readErr = err
+			if isTimeout(err) {
+				log.Printf("💤 Worker %d missed its heartbeat, disconnecting", userID)
+			} else {
+				log.Printf("❌ Worker WebSocket read error: %v", err)
+			}
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
 
 		log.Printf("📱 Worker WebSocket message: %v", msg)
 		
@@ -85,19 +119,46 @@ func (h *WorkerHandler) HandleWorker(c *gin.Context) {
 					"type":      "pong",
 					"timestamp": time.Now().UTC(),
 				}
-				conn.WriteJSON(pongMsg)
+				client.writeJSON(pongMsg)
 			default:
 				log.Printf("📱 Unknown worker message type: %s", msgType)
 			}
 		}
 	}
+}
+
+// register adds client, closing any previous socket of the same worker
+func (h *WorkerHandler) register(client *workerConn) {
+	h.mu.Lock()
+	previous := h.clients[client.userID]
+	h.clients[client.userID] = client
+	h.mu.Unlock()
+
+	if previous != nil {
+		log.Printf("🔁 Worker %d reconnected, closing previous connection", client.userID)
+		previous.conn.Close()
+		metrics[EndpointWorker].replaced.Add(1)
+	}
+}
 
-	// Remove client from the map
-	delete(h.clients, conn)
-	log.Printf("👷 Worker disconnected: %v", userID)
+// unregister removes client unless a newer socket of the same worker has replaced it
+func (h *WorkerHandler) unregister(client *workerConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[client.userID] == client {
+		delete(h.clients, client.userID)
+	}
 }
 
-// BroadcastToWorkers sends a message to all connected workers
+// IsWorkerConnected reports whether the worker with userID has a live socket
+func (h *WorkerHandler) IsWorkerConnected(userID uint) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.clients[userID]
+	return ok
+}
+
+// BroadcastToWorkers sends a message to all connected workers, dropping sockets that fail
 func (h *WorkerHandler) BroadcastToWorkers(messageType string, data interface{}) {
 	message := map[string]interface{}{
 		"type":      messageType,
@@ -105,10 +166,19 @@ func (h *WorkerHandler) BroadcastToWorkers(messageType string, data interface{})
 		"timestamp": time.Now().UTC(),
 	}
 
-	for conn := range h.clients {
-		if err := conn.WriteJSON(message); err != nil {
-			log.Printf("❌ Failed to send message to worker: %v", err)
-			delete(h.clients, conn)
+	h.mu.RLock()
+	clients := make([]*workerConn, 0, len(h.clients))
+	for _, client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		if err := client.writeJSON(message); err != nil {
+			log.Printf("❌ Failed to send message to worker %d: %v", client.userID, err)
+			h.unregister(client)
+			client.conn.Close()
+			metrics[EndpointWorker].dropped.Add(1)
 		}
 	}
 }